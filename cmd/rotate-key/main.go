@@ -0,0 +1,72 @@
+// Команда rotate-key управляет ключом шифрования телефонов, аллергий и телефонов для экстренной связи контактов.
+//
+// Генерация нового ключа:
+//
+//	go run ./cmd/rotate-key -generate
+//
+// Ротация ключа:
+//  1. Перенести текущий ENCRYPTION_KEY в ENCRYPTION_PREVIOUS_KEYS, а в ENCRYPTION_KEY записать новый ключ.
+//  2. Выполнить go run ./cmd/rotate-key: все контакты и история их изменений перешифровываются новым ключом
+//     (то же происходит при запуске сервера).
+//  3. Удалить прежний ключ из ENCRYPTION_PREVIOUS_KEYS.
+//
+// Чтобы отключить шифрование, ключ переносится в ENCRYPTION_PREVIOUS_KEYS, а ENCRYPTION_KEY очищается.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"rim/internal/config"
+	"rim/pkg/crypto"
+	"rim/pkg/database"
+	"rim/pkg/logger"
+)
+
+func main() {
+	generate := flag.Bool("generate", false, "вывести новый ключ для ENCRYPTION_KEY и выйти")
+	flag.Parse()
+
+	log := logger.NewLogger()
+
+	if *generate {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			log.Error("Failed to generate encryption key", slog.Any("error", err))
+			os.Exit(1)
+		}
+		fmt.Println(key)
+		return
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Error("Failed to load config", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	cipher, err := crypto.NewCipherFromBase64(cfg.EncryptionKey, cfg.EncryptionPreviousKeys)
+	if err != nil {
+		log.Error("Invalid encryption key", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	db, err := database.NewSQLiteConnection(cfg, cipher, log)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	updated, err := database.EncryptContacts(db, cipher, log)
+	if err != nil {
+		log.Error("Key rotation failed, check ENCRYPTION_PREVIOUS_KEYS", slog.Any("error", err))
+		os.Exit(1)
+	}
+	updatedHistory, err := database.EncryptContactHistory(db, cipher, log)
+	if err != nil {
+		log.Error("Key rotation of contact history failed, check ENCRYPTION_PREVIOUS_KEYS", slog.Any("error", err))
+		os.Exit(1)
+	}
+	log.Info("Key rotation completed", slog.Int("updated", updated), slog.Int("updatedHistory", updatedHistory), slog.Bool("encryptionEnabled", cipher.Enabled()))
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"rim/internal/config"
+	"rim/pkg/crypto"
+	"rim/pkg/database"
+)
+
+// runAnonymize выполняет режим "rim anonymize -yes": необратимо заменяет персональные данные в БД SQLITE_PATH
+// поддельными значениями, чтобы копию рабочей базы можно было отдать на стенд разработки или для демонстрации.
+// Возвращает код завершения: 0 - база обезличена, 1 - запуск без подтверждения, 2 - ошибка.
+func runAnonymize(cfg *config.Config, cipher *crypto.Cipher, args []string, log *slog.Logger) int {
+	flags := flag.NewFlagSet("anonymize", flag.ExitOnError)
+	yes := flags.Bool("yes", false, "подтвердить перезапись персональных данных; запускать только на копии рабочей базы")
+	flags.Parse(args)
+
+	if !*yes {
+		fmt.Fprintf(os.Stderr, "rim anonymize irreversibly rewrites personal data in %s.\nRun it on a copy of the production database with -yes to confirm.\n", cfg.SQLitePath)
+		return 1
+	}
+
+	db, err := database.NewSQLiteConnection(cfg, cipher, log)
+	if err != nil {
+		return 2
+	}
+
+	report, err := database.Anonymize(db, cipher, log)
+	if err != nil {
+		return 2
+	}
+	report.Write(os.Stdout)
+	return 0
+}
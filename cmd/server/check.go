@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"os"
+
+	"rim/internal/config"
+	"rim/pkg/crypto"
+	"rim/pkg/database"
+)
+
+// runCheck выполняет режим "rim check [-fix]": проверяет целостность БД без миграций и выводит отчет.
+// Возвращает код завершения: 0 - проблем нет или они исправлены, 1 - проблемы остались, 2 - ошибка проверки.
+func runCheck(cfg *config.Config, cipher *crypto.Cipher, args []string, log *slog.Logger) int {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	fix := flags.Bool("fix", false, "удалить висячие строки contact_groups и отвязать пользователей от несуществующих контактов")
+	flags.Parse(args)
+
+	db, err := database.OpenSQLite(cfg, cipher, log)
+	if err != nil {
+		return 2
+	}
+
+	report, err := database.CheckIntegrity(db, cipher, *fix, log)
+	if err != nil {
+		return 2
+	}
+	report.Write(os.Stdout)
+
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
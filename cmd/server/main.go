@@ -2,28 +2,146 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"rim/internal/config"
+	"rim/internal/domain"
+	"rim/pkg/circuitbreaker"
+	"rim/pkg/crypto"
 	"rim/pkg/database"
+	"rim/pkg/eventbus"
+	"rim/pkg/health"
+	"rim/pkg/leader"
+	"rim/pkg/listener"
 	"rim/pkg/logger"
+	"rim/pkg/mailer"
+	"rim/pkg/metrics"
+	"rim/pkg/notify"
+	"rim/pkg/pdf"
+	"rim/pkg/photocache"
+	"rim/pkg/securityheaders"
+	"rim/pkg/sheets"
+	"rim/pkg/sms"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
+	"rim/pkg/validation"
+	"rim/pkg/webpush"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/middleware/cache"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/etag"
+	"github.com/gofiber/fiber/v2/middleware/pprof"
+	"github.com/redis/go-redis/v9"
 
+	analyticsDelivery "rim/internal/analytics/delivery"
+	analyticsRepo "rim/internal/analytics/repository"
+	analyticsUseCase "rim/internal/analytics/usecase"
+	auditDelivery "rim/internal/audit/delivery"
+	auditRepo "rim/internal/audit/repository"
+	auditUseCase "rim/internal/audit/usecase"
 	authDelivery "rim/internal/auth/delivery"
 	authRepo "rim/internal/auth/repository"
 	authUseCase "rim/internal/auth/usecase"
+	avatarDelivery "rim/internal/avatar/delivery"
+	avatarRepo "rim/internal/avatar/repository"
+	avatarUseCase "rim/internal/avatar/usecase"
+
+	batchDelivery "rim/internal/batch/delivery"
+	batchRepo "rim/internal/batch/repository"
+	batchUseCase "rim/internal/batch/usecase"
+
+	digestRepo "rim/internal/digest/repository"
+	digestUseCase "rim/internal/digest/usecase"
+
+	botDelivery "rim/internal/bot/delivery"
+	botUseCase "rim/internal/bot/usecase"
 
 	contactDelivery "rim/internal/contact/delivery"
 	contactRepo "rim/internal/contact/repository"
 	contactUseCase "rim/internal/contact/usecase"
+	contactFieldDelivery "rim/internal/contactfield/delivery"
+	contactFieldRepo "rim/internal/contactfield/repository"
+	contactFieldUseCase "rim/internal/contactfield/usecase"
+	duplicateDelivery "rim/internal/duplicate/delivery"
+	duplicateRepo "rim/internal/duplicate/repository"
+	duplicateUseCase "rim/internal/duplicate/usecase"
+
+	emergencyDelivery "rim/internal/emergency/delivery"
+	emergencyRepo "rim/internal/emergency/repository"
+	emergencyUseCase "rim/internal/emergency/usecase"
+
+	exportDelivery "rim/internal/export/delivery"
+	exportRepo "rim/internal/export/repository"
+	exportUseCase "rim/internal/export/usecase"
 
 	groupDelivery "rim/internal/group/delivery"
 	groupRepo "rim/internal/group/repository"
 	groupUseCase "rim/internal/group/usecase"
 
+	graphqlDelivery "rim/internal/graphql/delivery"
+	graphqlUseCase "rim/internal/graphql/usecase"
+
+	hrSyncDelivery "rim/internal/hrsync/delivery"
+	hrSyncRepo "rim/internal/hrsync/repository"
+	hrSyncUseCase "rim/internal/hrsync/usecase"
+	importDelivery "rim/internal/importer/delivery"
+	importRepo "rim/internal/importer/repository"
+	importUseCase "rim/internal/importer/usecase"
+
+	locationDelivery "rim/internal/location/delivery"
+	locationRepo "rim/internal/location/repository"
+	locationUseCase "rim/internal/location/usecase"
+	metricsDelivery "rim/internal/metrics/delivery"
+
+	moderationDelivery "rim/internal/moderation/delivery"
+	moderationRepo "rim/internal/moderation/repository"
+	moderationUseCase "rim/internal/moderation/usecase"
+
+	organizationDelivery "rim/internal/organization/delivery"
+	organizationRepo "rim/internal/organization/repository"
+	organizationUseCase "rim/internal/organization/usecase"
+
+	notificationDelivery "rim/internal/notification/delivery"
+	notificationRepo "rim/internal/notification/repository"
+	notificationUseCase "rim/internal/notification/usecase"
+	outboxRepo "rim/internal/outbox/repository"
+	outboxUseCase "rim/internal/outbox/usecase"
+
+	printoutDelivery "rim/internal/printout/delivery"
+	printoutUseCase "rim/internal/printout/usecase"
+
+	printJobDelivery "rim/internal/printjob/delivery"
+	printJobRepo "rim/internal/printjob/repository"
+	printJobUseCase "rim/internal/printjob/usecase"
+	shortLinkDelivery "rim/internal/shortlink/delivery"
+	shortLinkRepo "rim/internal/shortlink/repository"
+	shortLinkUseCase "rim/internal/shortlink/usecase"
+
+	policyDelivery "rim/internal/policy/delivery"
+	policyRepo "rim/internal/policy/repository"
+	policyUseCase "rim/internal/policy/usecase"
+
+	skillDelivery "rim/internal/skill/delivery"
+	skillRepo "rim/internal/skill/repository"
+	skillUseCase "rim/internal/skill/usecase"
+	tagDelivery "rim/internal/tag/delivery"
+	tagRepo "rim/internal/tag/repository"
+	tagUseCase "rim/internal/tag/usecase"
+
+	tokenDelivery "rim/internal/token/delivery"
+	tokenRepo "rim/internal/token/repository"
+	tokenUseCase "rim/internal/token/usecase"
+
 	systemDelivery "rim/internal/system/delivery"
 	systemRepo "rim/internal/system/repository"
 	systemUseCase "rim/internal/system/usecase"
@@ -38,7 +156,7 @@ func initSystemSettings(sysUseCase systemUseCase.UseCase, log *slog.Logger) {
 	if err != nil {
 		// Если настройка не найдена, создаем её со значением false
 		log.Info("Debug mode setting not found, creating with default value (false)")
-		err = sysUseCase.SetDebugMode(ctx, false)
+		err = sysUseCase.SetDebugMode(ctx, false, nil)
 		if err != nil {
 			log.Error("Failed to initialize debug_mode setting", slog.Any("error", err))
 		} else {
@@ -49,6 +167,13 @@ func initSystemSettings(sysUseCase systemUseCase.UseCase, log *slog.Logger) {
 	}
 }
 
+// initPolicyRules создает правила политики доступа по умолчанию при первом запуске
+func initPolicyRules(polUseCase policyUseCase.UseCase, log *slog.Logger) {
+	if err := polUseCase.SeedDefaultRules(context.Background()); err != nil {
+		log.Error("Failed to initialize default policy rules", slog.Any("error", err))
+	}
+}
+
 // @title RIM API
 // @version 1.0
 // @description Корпоративный портал RIM для управления контактами, группами и ресурсами.
@@ -69,123 +194,499 @@ func main() {
 	}
 
 	log.Info("Config loaded successfully")
+	// SIGTERM (остановка при выкладке) и Ctrl+C завершают сервер плавно: ctx отменяет фоновые задачи,
+	// а HTTP-запросы дожидаются в конце main
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Секреты из файлов (Docker secrets) и Vault перечитываются, чтобы ротация не требовала перезапуска
+	go cfg.Secrets.Watch(ctx, cfg.SecretsRefreshInterval, log)
+	if len(cfg.AdminTelegramIDs) > 0 {
+		log.Info("Bootstrap admins configured", slog.Any("admin_telegram_ids", cfg.AdminTelegramIDs))
+	}
+
+	// Телефоны и аллергии контактов шифруются ключом ENCRYPTION_KEY
+	cipher, err := crypto.NewCipherFromBase64(cfg.EncryptionKey, cfg.EncryptionPreviousKeys)
+	if err != nil {
+		log.Error("Invalid encryption key", slog.Any("error", err))
+		return
+	}
+	if !cipher.Enabled() {
+		log.Warn("ENCRYPTION_KEY is not set, contact phones and allergies are stored unencrypted")
+	}
+
+	// rim check [-fix] - проверка целостности БД вместо запуска сервера
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		os.Exit(runCheck(cfg, cipher, os.Args[2:], log))
+	}
+	// rim anonymize -yes - обезличивание копии рабочей базы для стенда разработки
+	if len(os.Args) > 1 && os.Args[1] == "anonymize" {
+		os.Exit(runAnonymize(cfg, cipher, os.Args[2:], log))
+	}
 
 	// Подключаемся к SQLite
-	sqliteDB, err := database.NewSQLiteConnection(cfg, log)
+	sqliteDB, err := database.NewSQLiteConnection(cfg, cipher, log)
 	if err != nil {
 		// Ошибка уже залогирована в NewSQLiteConnection
 		return
 	}
+	// Данные, записанные до включения шифрования или прежним ключом, приводятся к текущему ключу
+	if _, err := database.EncryptContacts(sqliteDB, cipher, log); err != nil {
+		log.Error("Failed to encrypt contacts, check ENCRYPTION_PREVIOUS_KEYS", slog.Any("error", err))
+		return
+	}
+	if _, err := database.EncryptContactHistory(sqliteDB, cipher, log); err != nil {
+		log.Error("Failed to encrypt contact history, check ENCRYPTION_PREVIOUS_KEYS", slog.Any("error", err))
+		return
+	}
 	// Пока не используем sqliteDB, но он готов
 	_ = sqliteDB // Это чтобы компилятор не ругался на неиспользуемую переменную
 
-	// Подключаемся к Redis
-	redisClient, err := database.NewRedisClient(cfg, log)
-	if err != nil {
-		// Ошибка уже залогирована в NewRedisClient
-		return
+	// Выбираем хранилище сессий: Redis (по умолчанию) или SQLite для установок без Redis
+	var sessionStore authRepo.SessionStore
+	var redisClient *redis.Client
+	switch cfg.SessionStore {
+	case config.SessionStoreSQLite:
+		log.Info("Using SQLite session store")
+		sessionStore = authRepo.NewSQLiteSessionStore(sqliteDB, log)
+	default:
+		// Подключаемся к Redis
+		redisClient, err = database.NewRedisClient(cfg, log)
+		if err != nil {
+			// Ошибка уже залогирована в NewRedisClient
+			return
+		}
+		log.Info("Using Redis session store")
+		// При сбоях Redis запросы с сессией получают 503 сразу, а не после таймаута каждой операции
+		sessionBreaker := circuitbreaker.New("redis_sessions", cfg.RedisBreakerFailures, cfg.RedisBreakerCooldown, authRepo.IsStoreFailure, log)
+		sessionStore = authRepo.NewBreakerSessionStore(authRepo.NewRedisSessionStore(redisClient, log), cfg.RedisTimeout, sessionBreaker)
+		// Сессии, сохраненные под самим токеном, переносятся под его хеш в фоне
+		go authRepo.MigrateRedisSessions(ctx, redisClient, log)
 	}
-	// Пока не используем redisClient, но он готов
-	_ = redisClient // Это чтобы компилятор не ругался на неиспользуемую переменную
 
-	app := fiber.New()
+	// Лимит тела запроса рассчитан на файлы заявок на печать (до 20 МБ)
+	app := fiber.New(fiber.Config{BodyLimit: 21 << 20})
 
-	// Добавляем middleware безопасности в начале
-	app.Use(authDelivery.SecurityMiddleware())
+	// Метрики запросов собираются первыми, чтобы учитывать и отклоненные middleware запросы
+	metricsRegistry := metrics.NewRegistry()
+	mtrHandler := metricsDelivery.NewHandler(metricsRegistry, cfg.MetricsToken.Get, log)
+	app.Use(mtrHandler.Middleware())
+	app.Get("/metrics", mtrHandler.Metrics)
+	if cfg.MetricsAlertChatID != 0 && cfg.BotToken.Get() != "" {
+		alertChannel := notify.NewTelegramChannel(cfg.BotToken.Get, log)
+		sendAlert := func(ctx context.Context, text string) error {
+			return alertChannel.Send(ctx, notify.Recipient{TelegramID: cfg.MetricsAlertChatID}, "RIM: SLO", text)
+		}
+		alerter := metrics.NewAlerter(metricsRegistry, metrics.AlertThresholds{
+			P95:         cfg.MetricsAlertP95,
+			ErrorRate:   cfg.MetricsAlertErrorRate,
+			MinRequests: cfg.MetricsAlertMinRequests,
+		}, sendAlert, log)
+		log.Info("SLO alerts enabled", slog.Int64("chat_id", cfg.MetricsAlertChatID), slog.Duration("p95", cfg.MetricsAlertP95), slog.Float64("error_rate", cfg.MetricsAlertErrorRate))
+		go alerter.Run(ctx, cfg.MetricsAlertInterval)
+	}
 
-	// Настройка CORS с поддержкой cookies
-	app.Use(cors.New(cors.Config{
-		AllowOrigins:     "http://localhost, http://localhost:80, http://localhost.local, http://localhost.local:80",
-		AllowMethods:     "GET, POST, PUT, DELETE, OPTIONS",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-CSRF-Token",
-		AllowCredentials: true, // Важно для cookies
-	}))
+	// Системные настройки нужны middleware безопасности (CSP и переопределения заголовков), поэтому создаются до него
+	sysRepo := systemRepo.NewSQLiteRepository(sqliteDB, log)
+	sysUseCase := systemUseCase.NewSystemUseCase(sysRepo, log)
+	// Допустимые значения transport/printer и длина имени контакта берутся из настройки contact_field_rules
+	validation.SetRules(systemUseCase.NewValidationRules(sysUseCase))
+
+	// Добавляем middleware безопасности в начале; заголовки и CORS зависят от профиля окружения APP_ENV
+	log.Info("Using security headers profile", slog.String("env", cfg.Security.Env), slog.Any("cors_origins", cfg.Security.CORSAllowOrigins), slog.Bool("secure_cookies", cfg.Security.Cookies.Secure), slog.String("cookie_samesite", cfg.Security.Cookies.SameSite))
+	app.Use(securityheaders.New(cfg.Security, sysUseCase, "/api/v1/csp-report"))
+
+	// Настройка CORS с поддержкой cookies; у публичного справочника свои правила CORS
+	app.Use(securityheaders.CORS(cfg.Security, func(c *fiber.Ctx) bool { return strings.HasPrefix(c.Path(), "/api/public/") }))
+
+	// Задачи по расписанию выполняет один экземпляр сервера, см. leader.Elector ниже
+	var scheduledJobs []leader.Job
+
+	// Транзакции для UseCase, которые изменяют данные нескольких репозиториев
+	txManager := transaction.NewManager(sqliteDB)
+	// Доменные события: UseCase публикуют их, а аудит, уведомления и кэши подписываются ниже
+	bus := eventbus.New()
 
 	// Инициализация зависимостей для модуля Group
+	// groupUseCase использует уведомления и создается после них
 	grpRepo := groupRepo.NewSQLiteRepository(sqliteDB, log)
-	grpUseCase := groupUseCase.NewGroupUseCase(grpRepo, log)
-	grpHandler := groupDelivery.NewHandler(grpUseCase, log)
+
+	// Инициализация зависимостей для справочника навыков
+	sklRepo := skillRepo.NewSQLiteRepository(sqliteDB, log)
+	sklUseCase := skillUseCase.NewSkillUseCase(sklRepo, log)
+	sklHandler := skillDelivery.NewHandler(sklUseCase, log)
+
+	// Инициализация зависимостей для справочника местоположений
+	locRepo := locationRepo.NewSQLiteRepository(sqliteDB, log)
+	locUseCase := locationUseCase.NewLocationUseCase(locRepo, log)
+	locHandler := locationDelivery.NewHandler(locUseCase, log)
 
 	// Инициализация зависимостей для модуля Contact
 	// contactRepo используется в auth, поэтому создается раньше
-	cntRepo := contactRepo.NewSQLiteRepository(sqliteDB, log)
+	cntRepo := contactRepo.NewSQLiteRepository(sqliteDB, cipher, log)
 
-	// Инициализация зависимостей для модуля Auth
-	authRepository := authRepo.NewAuthRepository(sqliteDB, redisClient, log)
-	authUseCaseInstance := authUseCase.NewAuthUseCase(authRepository, cntRepo, log)
+	// Уведомления администраторов об изменениях контактов (используются в contact и auth)
+	var notifyChannels []notify.Channel
+	if cfg.BotToken.Get() != "" {
+		notifyChannels = append(notifyChannels, notify.NewTelegramChannel(cfg.BotToken.Get, log))
+	}
+	if cfg.SMTPAddr != "" {
+		notifyChannels = append(notifyChannels, notify.NewEmailChannel(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword.Get, cfg.SMTPFrom, log))
+	}
+	ntfRepo := notificationRepo.NewSQLiteRepository(sqliteDB, log)
+	var vapidPublicKey string
+	if cfg.VAPIDPublicKey != "" {
+		sender, err := webpush.NewSender(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey.Get(), cfg.VAPIDSubject)
+		if err != nil {
+			log.Error("Invalid VAPID configuration, Web Push disabled", slog.Any("error", err))
+		} else {
+			vapidPublicKey = sender.PublicKey()
+			notifyChannels = append(notifyChannels, notify.NewPushChannel(sender, notificationUseCase.NewPushStore(ntfRepo), log))
+		}
+	}
+	if len(notifyChannels) == 0 {
+		notifyChannels = append(notifyChannels, notify.NewLogChannel(log))
+	}
+	// Исходящие события (outbox) записываются вместе с изменением данных; обработчики тем подписываются при создании UseCase
+	outUseCase := outboxUseCase.NewOutboxUseCase(outboxRepo.NewSQLiteRepository(sqliteDB, log), log)
+	ntfUseCase := notificationUseCase.NewNotificationUseCase(cntRepo, ntfRepo, notifyChannels, cfg.NotifyAdminGroupID, vapidPublicKey, outUseCase, log)
+	notificationUseCase.SubscribeEvents(bus, ntfUseCase)
+	ntfHandler := notificationDelivery.NewHandler(ntfUseCase, log)
+	if cfg.NotifyAdminGroupID != 0 {
+		log.Info("Contact change notifications enabled", slog.Uint64("group_id", uint64(cfg.NotifyAdminGroupID)), slog.Duration("digest_interval", cfg.NotifyDigestInterval))
+	}
+	// Дайджест изменений контактов отправляется при передаче событий outbox
+	scheduledJobs = append(scheduledJobs, func(ctx context.Context) { outUseCase.Run(ctx, cfg.NotifyDigestInterval) })
+
+	grpUseCase := groupUseCase.NewGroupUseCase(grpRepo, ntfUseCase, bus, txManager, log)
+	grpHandler := groupDelivery.NewHandler(grpUseCase, log)
+	// Исключение контактов из групп по истечении срока членства
+	scheduledJobs = append(scheduledJobs, func(ctx context.Context) { grpUseCase.RunMembershipExpiry(ctx, cfg.MembershipExpiryInterval) })
 
 	// Инициализация зависимостей для модуля System
-	sysRepo := systemRepo.NewSQLiteRepository(sqliteDB, log)
-	sysUseCase := systemUseCase.NewSystemUseCase(sysRepo, log)
-	sysHandler := systemDelivery.NewHandler(sysUseCase, log)
+	// systemUseCase используется в auth (лимит сессий), поэтому создается раньше
+	// Проверки внешних зависимостей для /system/status; история хранится в Redis, если он используется
+	healthChecks := []health.Check{health.SQLiteCheck(sqliteDB)}
+	healthStore := health.NewMemoryStore()
+	if redisClient != nil {
+		healthChecks = append(healthChecks, health.RedisCheck(redisClient))
+		healthStore = health.NewRedisStore(redisClient)
+	}
+	if cfg.BotToken.Get() != "" {
+		healthChecks = append(healthChecks, health.TelegramCheck(cfg.BotToken.Get))
+	}
+	if cfg.SMTPAddr != "" {
+		healthChecks = append(healthChecks, health.SMTPCheck(cfg.SMTPAddr))
+	}
+	healthMonitor := health.NewMonitor(healthChecks, healthStore, log)
+	go healthMonitor.Run(ctx, cfg.HealthCheckInterval)
+	if redisClient != nil {
+		// Пока Redis не прошел последнюю проверку, вход и маршруты с сессией сразу отвечают 503,
+		// а публичные маршруты без сессии продолжают работать с SQLite
+		sessionStore = authRepo.NewReadySessionStore(sessionStore, func() bool {
+			return healthMonitor.Available(health.DependencyRedis)
+		})
+	}
+
+	sysHandler := systemDelivery.NewHandler(sysUseCase, healthMonitor, cfg.Security.Cookies.SessionName, log)
+
+	// Шлюз SMS для входа по телефону
+	var smsSender sms.Sender
+	if cfg.SMSGatewayURL != "" {
+		smsSender = sms.NewHTTPSender(cfg.SMSGatewayURL, cfg.SMSGatewayToken.Get, log)
+	} else {
+		log.Warn("SMS_GATEWAY_URL is not set, SMS are written to the log with codes masked, phone login is unavailable")
+		smsSender = sms.NewLogSender(log)
+	}
+
+	// Письма со ссылками подтверждения email контактов
+	var mailSender mailer.Sender
+	if cfg.SMTPAddr != "" {
+		mailSender = mailer.NewSMTPSender(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword.Get, cfg.SMTPFrom, log)
+	} else {
+		log.Warn("SMTP_ADDR is not set, email verification links will be written to the log")
+		mailSender = mailer.NewLogSender(log)
+	}
+
+	// Заявки на изменение контактов создаются в auth, а рассматриваются в moderation
+	chrRepo := moderationRepo.NewSQLiteRepository(sqliteDB, log)
+
+	// Инициализация зависимостей для модуля Auth
+	authRepository := authRepo.NewAuthRepository(sqliteDB, sessionStore, log)
+	// Журнал аудита: блокировки, отклоненные входы и другие действия, связанные с безопасностью
+	audUseCase := auditUseCase.NewAuditUseCase(auditRepo.NewSQLiteRepository(sqliteDB, log), log)
+	auditUseCase.SubscribeEvents(bus, audUseCase)
+	audHandler := auditDelivery.NewHandler(audUseCase, log)
+
+	authUseCaseInstance := authUseCase.NewAuthUseCase(authRepository, cntRepo, chrRepo, sysUseCase, bus, txManager, smsSender, mailSender, cfg.PortalURL, audUseCase, cfg.AdminTelegramIDs, log)
 
 	// Инициализация системных настроек при первом запуске
 	initSystemSettings(sysUseCase, log)
 
+	// Организации: auth выбирает организацию запроса после аутентификации пользователя
+	orgRepo := organizationRepo.NewSQLiteRepository(sqliteDB, log)
+	orgUseCase := organizationUseCase.NewOrganizationUseCase(orgRepo, log)
+	orgHandler := organizationDelivery.NewHandler(orgUseCase, authUseCaseInstance.IsUserSuperAdmin, log)
+
 	// Завершение инициализации Auth с systemUseCase
-	authHandler := authDelivery.NewHandler(authUseCaseInstance, sysUseCase, cfg.BotToken, cfg.ForceDebugMode, log)
+	photoCache := photocache.New(cfg.PhotoCacheDir, 24*time.Hour, log)
+	authHandler := authDelivery.NewHandler(authUseCaseInstance, sysUseCase, orgHandler.Resolve, photoCache, cfg.BotToken.Get, cfg.ForceDebugMode, cfg.Security.Cookies, log)
+
+	// Инициализация зависимостей для модуля Policy
+	polRepo := policyRepo.NewSQLiteRepository(sqliteDB, log)
+	polUseCase := policyUseCase.NewPolicyUseCase(polRepo, log)
+	initPolicyRules(polUseCase, log)
+	polHandler := policyDelivery.NewHandler(polUseCase, authHandler.ResolveRole, log)
 
 	// Завершение инициализации Contact с authUseCase
-	cntUseCase := contactUseCase.NewContactUseCase(cntRepo, grpRepo, log)
+	cntUseCase := contactUseCase.NewContactUseCase(cntRepo, grpRepo, sklRepo, locRepo, polUseCase, bus, txManager, log)
 	cntHandler := contactDelivery.NewHandler(cntUseCase, authUseCaseInstance, log)
+	cntHandler.SubscribeEvents(bus)
 
-	// Группа маршрутов API v1
-	api := app.Group("/api")
-	v1 := api.Group("/v1")
+	// Инициализация зависимостей для дополнительных полей контактов
+	cfdUseCase := contactFieldUseCase.NewContactFieldUseCase(contactFieldRepo.NewSQLiteRepository(sqliteDB, log), cntRepo, log)
+	cfdHandler := contactFieldDelivery.NewHandler(cfdUseCase, log)
 
-	// Middleware для проверки админских прав с учетом отладочного режима
-	requireAdminOrDebug := func(c *fiber.Ctx) error {
-		// Сначала проверяем принудительный отладочный режим из переменной окружения
-		if cfg.ForceDebugMode {
-			log.InfoContext(c.Context(), "Force debug mode is enabled via environment variable, allowing access to authenticated user")
-			return c.Next()
-		}
+	// Инициализация зависимостей для меток контактов
+	tagUC := tagUseCase.NewTagUseCase(tagRepo.NewSQLiteRepository(sqliteDB, log), cntRepo, log)
+	tagHandler := tagDelivery.NewHandler(tagUC, log)
+
+	// Инициализация зависимостей для поиска и объединения дубликатов контактов
+	dupUseCase := duplicateUseCase.NewDuplicateUseCase(duplicateRepo.NewSQLiteRepository(sqliteDB, log), cntRepo, authRepository, bus, txManager, log)
+	dupHandler := duplicateDelivery.NewHandler(dupUseCase, cntUseCase, log)
+
+	// Инициализация зависимостей для модуля Moderation
+	modUseCase := moderationUseCase.NewModerationUseCase(chrRepo, cntUseCase, log)
+	modHandler := moderationDelivery.NewHandler(modUseCase, log)
 
-		// Затем проверяем отладочный режим из базы данных
-		debugMode, err := sysUseCase.GetDebugMode(c.Context())
+	// Инициализация зависимостей для выгрузки в Google Sheets
+	var sheetsWriter sheets.Writer
+	if cfg.GoogleSheetsCredentialsFile != "" {
+		credentials, err := os.ReadFile(cfg.GoogleSheetsCredentialsFile)
 		if err != nil {
-			log.WarnContext(c.Context(), "Failed to get debug mode status", slog.Any("error", err))
-		} else if debugMode {
-			log.InfoContext(c.Context(), "Debug mode is enabled, allowing access to authenticated user")
-			return c.Next()
+			log.Error("Failed to read Google Sheets credentials", slog.Any("error", err))
+			return
 		}
-
-		// Получаем user_id из контекста (должен быть установлен RequireAuth middleware)
-		userID, ok := c.Locals("user_id").(uint)
-		if !ok {
-			log.WarnContext(c.Context(), "User ID not found in context")
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Unauthorized",
-			})
+		sheetsWriter, err = sheets.NewServiceAccountWriter(credentials, log)
+		if err != nil {
+			log.Error("Failed to load Google Sheets credentials", slog.Any("error", err))
+			return
 		}
+	}
+	expRepo := exportRepo.NewSQLiteRepository(sqliteDB, log)
+	expUseCase := exportUseCase.NewExportUseCase(expRepo, cntUseCase, sheetsWriter, exportUseCase.SheetTarget{
+		SpreadsheetID: cfg.GoogleSheetsSpreadsheetID,
+		Sheet:         cfg.GoogleSheetsSheet,
+	}, log)
+	expHandler := exportDelivery.NewHandler(expUseCase, log)
+
+	// Печатные формы в PDF; без шрифта с кириллицей сервер работает, но формы отвечают 503
+	pdfFont, err := pdf.LoadFont(cfg.PDFFontPath)
+	if err != nil {
+		log.Warn("Failed to load PDF font, printable cards are disabled", slog.String("path", cfg.PDFFontPath), slog.Any("error", err))
+	}
+	prtUseCase := printoutUseCase.NewPrintoutUseCase(cntUseCase, grpUseCase, authUseCaseInstance, photoCache, pdfFont, log)
+	prtHandler := printoutDelivery.NewHandler(prtUseCase, log)
+
+	// Фото профилей Telegram контактов: сохраняются фоновой задачей и отдаются по постоянным адресам
+	avtUseCase := avatarUseCase.NewAvatarUseCase(avatarRepo.NewSQLiteRepository(sqliteDB, log), cntUseCase, cfg.BotToken.Get, photoCache, log)
+	avtHandler := avatarDelivery.NewHandler(avtUseCase, log)
+	if cfg.AvatarSyncInterval > 0 {
+		scheduledJobs = append(scheduledJobs, func(ctx context.Context) { avtUseCase.Run(ctx, cfg.AvatarSyncInterval) })
+	}
 
-		// Проверяем права администратора
-		isAdmin, err := authUseCaseInstance.IsUserAdmin(c.Context(), userID)
+	// Экстренные контакты (ICE): доступ только по правилам ресурса emergency_contacts, каждое обращение в журнале аудита
+	emgUseCase := emergencyUseCase.NewEmergencyUseCase(emergencyRepo.NewSQLiteRepository(sqliteDB, log), grpUseCase, audUseCase, log)
+	emgHandler := emergencyDelivery.NewHandler(emgUseCase, log)
+
+	// Заявки на печать направляются владельцам принтеров (поле контакта printer)
+	pjUseCase := printJobUseCase.NewPrintJobUseCase(printJobRepo.NewSQLiteRepository(sqliteDB, log), cntUseCase, ntfUseCase, cfg.PrintJobDir, log)
+	pjHandler := printJobDelivery.NewHandler(pjUseCase, log)
+	if sheetsWriter != nil && cfg.GoogleSheetsSpreadsheetID != "" && cfg.GoogleSheetsSyncInterval > 0 {
+		syncQuery, err := url.ParseQuery(cfg.GoogleSheetsSyncFilter)
+		if err != nil {
+			log.Error("Invalid GOOGLE_SHEETS_SYNC_FILTER", slog.Any("error", err))
+			return
+		}
+		syncFilter, err := contactUseCase.ParseContactFilter(syncQuery.Get)
 		if err != nil {
-			log.ErrorContext(c.Context(), "Failed to check admin status", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Internal server error",
-			})
+			log.Error("Invalid GOOGLE_SHEETS_SYNC_FILTER", slog.Any("error", err))
+			return
 		}
+		log.Info("Scheduled Google Sheets export enabled", slog.Duration("interval", cfg.GoogleSheetsSyncInterval))
+		// Плановая выгрузка относится к организации по умолчанию
+		scheduledJobs = append(scheduledJobs, func(ctx context.Context) {
+			expUseCase.RunSchedule(tenant.With(ctx, domain.DefaultOrganizationID), cfg.GoogleSheetsSyncInterval, syncFilter, cfg.GoogleSheetsSyncTemplateID)
+		})
+	}
+
+	// Инициализация зависимостей для модуля Import
+	importRollbackWindow := time.Duration(cfg.ImportRollbackDays) * 24 * time.Hour
+	impRepo := importRepo.NewSQLiteRepository(sqliteDB, log)
+	impUseCase := importUseCase.NewImportUseCase(impRepo, cntRepo, grpRepo, cntUseCase, txManager, importRollbackWindow, log)
+	impHandler := importDelivery.NewHandler(impUseCase, importRollbackWindow, log)
 
-		if !isAdmin {
-			log.WarnContext(c.Context(), "User is not admin and debug mode is off", slog.Uint64("user_id", uint64(userID)))
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Admin rights required",
-			})
+	// Синхронизация с кадровой системой; сопоставление полей проверяется при запуске, чтобы ошибка не всплыла в задаче
+	hrSyncConfig := hrSyncUseCase.Config{URL: cfg.HRSyncURL, Token: cfg.HRSyncToken.Get, RecordsKey: cfg.HRSyncRecordsKey}
+	if cfg.HRSyncURL != "" {
+		if hrSyncConfig.Mapping, err = hrSyncUseCase.ParseMapping(cfg.HRSyncMapping); err != nil {
+			log.Error("Invalid HR_SYNC_MAPPING", slog.Any("error", err))
+			return
 		}
+		if hrSyncConfig.PushFields, err = hrSyncUseCase.ParsePushFields(cfg.HRSyncPushFields, hrSyncConfig.Mapping); err != nil {
+			log.Error("Invalid HR_SYNC_PUSH_FIELDS", slog.Any("error", err))
+			return
+		}
+	}
+	hrsUseCase := hrSyncUseCase.NewHRSyncUseCase(hrSyncRepo.NewSQLiteRepository(sqliteDB, log), cntRepo, cntUseCase, hrSyncConfig, log)
+	hrsHandler := hrSyncDelivery.NewHandler(hrsUseCase, log)
+	if cfg.HRSyncURL != "" && cfg.HRSyncInterval > 0 {
+		log.Info("Scheduled HR sync enabled", slog.Duration("interval", cfg.HRSyncInterval))
+		// Кадровая система относится к организации по умолчанию
+		scheduledJobs = append(scheduledJobs, func(ctx context.Context) {
+			hrsUseCase.RunSchedule(tenant.With(ctx, domain.DefaultOrganizationID), cfg.HRSyncInterval)
+		})
+	}
+
+	batRepo := batchRepo.NewSQLiteRepository(sqliteDB, log)
+	batUseCase := batchUseCase.NewBatchUseCase(batRepo, cntUseCase, grpUseCase, polUseCase, log)
+	batHandler := batchDelivery.NewHandler(batUseCase, log)
+
+	gqlUseCase, err := graphqlUseCase.NewGraphQLUseCase(cntUseCase, grpUseCase, polUseCase, log)
+	if err != nil {
+		log.Error("Failed to build GraphQL schema", slog.Any("error", err))
+		return
+	}
+	gqlHandler := graphqlDelivery.NewHandler(gqlUseCase, log)
+
+	// Короткие ссылки /l/{code} для сообщений бота и ручной раздачи
+	slUseCase := shortLinkUseCase.NewShortLinkUseCase(shortLinkRepo.NewSQLiteRepository(sqliteDB, log), log)
+	slHandler := shortLinkDelivery.NewHandler(slUseCase, cfg.PortalURL, log)
+
+	// Инициализация зависимостей для бота Telegram
+	btUseCase := botUseCase.NewBotUseCase(authUseCaseInstance, cntUseCase, orgUseCase, polUseCase, log)
+	btHandler := botDelivery.NewHandler(btUseCase, slUseCase, cfg.TelegramWebhookSecret.Get(), cfg.PortalURL, log)
+
+	// Еженедельная сводка изменений справочника; проверяется чаще раза в час, чтобы учесть часовые пояса пользователей
+	if cfg.WeeklyDigestWeekday != 0 {
+		dgstRepo := digestRepo.NewSQLiteRepository(sqliteDB, log)
+		schedule := digestUseCase.Schedule{Weekday: time.Weekday(cfg.WeeklyDigestWeekday % 7), Hour: cfg.WeeklyDigestHour}
+		dgstUseCase := digestUseCase.NewDigestUseCase(dgstRepo, authUseCaseInstance, polUseCase, ntfUseCase, schedule, log)
+		log.Info("Weekly digest enabled", slog.String("weekday", schedule.Weekday.String()), slog.Int("hour", schedule.Hour))
+		scheduledJobs = append(scheduledJobs, func(ctx context.Context) { dgstUseCase.Run(ctx, 15*time.Minute) })
+	}
 
-		log.InfoContext(c.Context(), "User has admin rights", slog.Uint64("user_id", uint64(userID)))
-		return c.Next()
+	// Анонимные счетчики использования функций; события копятся в памяти и сохраняются раз в минуту
+	anlRepo := analyticsRepo.NewSQLiteRepository(sqliteDB, log)
+	anlUseCase := analyticsUseCase.NewAnalyticsUseCase(anlRepo, log)
+	anlHandler := analyticsDelivery.NewHandler(anlUseCase, log)
+	// Накопленные события сохраняются при остановке, когда HTTP-запросы уже завершены
+	analyticsCtx, stopAnalytics := context.WithCancel(context.Background())
+	analyticsDone := make(chan struct{})
+	go func() {
+		anlUseCase.Run(analyticsCtx, time.Minute)
+		close(analyticsDone)
+	}()
+	// Список контактов считается поиском, только если заданы фильтры
+	trackContactSearch := anlHandler.TrackIf(analyticsUseCase.EventContactSearch, func(c *fiber.Ctx) bool {
+		return len(c.Request().URI().QueryString()) > 0
+	})
+
+	// Инициализация зависимостей для модуля Token
+	tknRepo := tokenRepo.NewSQLiteRepository(sqliteDB, log)
+	tknUseCase := tokenUseCase.NewTokenUseCase(tknRepo, grpRepo, log)
+	tknHandler := tokenDelivery.NewHandler(tknUseCase, log)
+
+	// Группа маршрутов API v1
+	api := app.Group("/api")
+	v1 := api.Group("/v1")
+	apiRouteGroups := map[string]string{
+		"/api/v1/auth":     systemUseCase.RateLimitGroupAuth,
+		"/api/v1/contacts": systemUseCase.RateLimitGroupContacts,
+		"/api/v1/exports":  systemUseCase.RateLimitGroupExports,
+		"/api/v1/graphql":  systemUseCase.RateLimitGroupContacts,
+		"/api/v1/imports":  systemUseCase.RateLimitGroupImports,
+		// Импорт и выгрузка контактов нагружают БД, как /imports и /exports
+		"/api/v1/contacts/import": systemUseCase.RateLimitGroupImports,
+		"/api/v1/contacts/export": systemUseCase.RateLimitGroupExports,
+	}
+	// Ограничение частоты запросов с одного IP; лимиты групп меняются в /system/rate-limits
+	v1.Use(sysHandler.RateLimit(apiRouteGroups))
+	// Ограничение одновременных запросов экземпляра: при перегрузке запросы сразу получают 503,
+	// лимиты меняются в /system/concurrency-limits
+	v1.Use(sysHandler.ConcurrencyLimit(apiRouteGroups))
+	// Организация запросов без авторизации; middleware авторизации уточняют ее по членству
+	v1.Use(orgHandler.DefaultOrganization())
+
+	// Middleware проверки правил политики доступа
+	authorize := polHandler.Authorize
+	// Middleware, дополнительно пропускающий модераторов групп; проверка конкретной группы - в usecase
+	authorizeScoped := func(resource, action string) fiber.Handler {
+		return polHandler.AuthorizeScoped(resource, action, grpHandler.ModeratorScope)
 	}
 
 	// Маршруты для Group
 	groupRoutes := v1.Group("/groups")
-	groupRoutes.Post("/", grpHandler.CreateGroup)
+	// Необязательная авторизация: список групп зависит от организации пользователя
+	groupRoutes.Use(authHandler.CookieAuthMiddleware())
+	groupRoutes.Post("/", authHandler.RequireAuthCookie(), authHandler.CSRFMiddleware(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionManageGroups), grpHandler.CreateGroup)
 	groupRoutes.Get("/", grpHandler.GetAllGroups)
+	// Заявки на вступление; объявлены до /:id
+	groupRoutes.Get("/join-requests", authHandler.RequireAuthCookie(), authorizeScoped(policyUseCase.ResourceContacts, policyUseCase.ActionManageGroups), grpHandler.GetJoinRequests)
+	groupRoutes.Get("/join-requests/mine", authHandler.RequireAuthCookie(), grpHandler.GetMyJoinRequests)
+	groupRoutes.Post("/:id/join-requests", authHandler.RequireAuthCookie(), authHandler.CSRFMiddleware(), grpHandler.RequestJoin)
+	groupRoutes.Get("/:id/join-requests", authHandler.RequireAuthCookie(), authorizeScoped(policyUseCase.ResourceContacts, policyUseCase.ActionManageGroups), grpHandler.GetGroupJoinRequests)
+	groupRoutes.Post("/:id/join-requests/:request_id/approve", authHandler.RequireAuthCookie(), authHandler.CSRFMiddleware(), authorizeScoped(policyUseCase.ResourceContacts, policyUseCase.ActionManageGroups), grpHandler.ApproveJoinRequest)
+	groupRoutes.Post("/:id/join-requests/:request_id/reject", authHandler.RequireAuthCookie(), authHandler.CSRFMiddleware(), authorizeScoped(policyUseCase.ResourceContacts, policyUseCase.ActionManageGroups), grpHandler.RejectJoinRequest)
+	// Модераторы групп назначаются администраторами пользователей
+	groupRoutes.Get("/moderated", authHandler.RequireAuthCookie(), grpHandler.GetModeratedGroups)
+	groupRoutes.Get("/:id/moderators", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceUsers, policyUseCase.ActionManage), grpHandler.GetModerators)
+	groupRoutes.Post("/:id/moderators", authHandler.RequireAuthCookie(), authHandler.CSRFMiddleware(), authorize(policyUseCase.ResourceUsers, policyUseCase.ActionManage), grpHandler.AddModerator)
+	groupRoutes.Delete("/:id/moderators/:user_id", authHandler.RequireAuthCookie(), authHandler.CSRFMiddleware(), authorize(policyUseCase.ResourceUsers, policyUseCase.ActionManage), grpHandler.RemoveModerator)
+	groupRoutes.Get("/:id/roster.pdf", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionRead), prtHandler.GetGroupRoster)
+	groupRoutes.Get("/:id/emergency-contacts.csv", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceEmergencyContacts, policyUseCase.ActionList), emgHandler.ExportGroupEmergencyContacts)
 	groupRoutes.Get("/:id", grpHandler.GetGroupByID)
-	groupRoutes.Put("/:id", grpHandler.UpdateGroup)
-	groupRoutes.Delete("/:id", grpHandler.DeleteGroup)
+	groupRoutes.Put("/:id", authHandler.RequireAuthCookie(), authHandler.CSRFMiddleware(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionManageGroups), grpHandler.UpdateGroup)
+	groupRoutes.Delete("/:id", authHandler.RequireAuthCookie(), authHandler.CSRFMiddleware(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionManageGroups), grpHandler.DeleteGroup)
+
+	// Маршруты справочника навыков
+	skillRoutes := v1.Group("/skills")
+	skillRoutes.Use(authHandler.CSRFMiddleware())
+	skillRoutes.Get("/", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSkills, policyUseCase.ActionList), sklHandler.GetAllSkills)
+	skillRoutes.Post("/", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSkills, policyUseCase.ActionManage), sklHandler.CreateSkill)
+	skillRoutes.Put("/:id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSkills, policyUseCase.ActionManage), sklHandler.UpdateSkill)
+	skillRoutes.Delete("/:id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSkills, policyUseCase.ActionManage), sklHandler.DeleteSkill)
+
+	// Маршруты меток контактов
+	tagRoutes := v1.Group("/tags")
+	tagRoutes.Use(authHandler.CSRFMiddleware())
+	tagRoutes.Get("/", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceTags, policyUseCase.ActionList), tagHandler.GetAllTags)
+	tagRoutes.Post("/", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceTags, policyUseCase.ActionManage), tagHandler.CreateTag)
+	tagRoutes.Put("/:id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceTags, policyUseCase.ActionManage), tagHandler.UpdateTag)
+	tagRoutes.Delete("/:id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceTags, policyUseCase.ActionManage), tagHandler.DeleteTag)
+
+	// Маршруты справочника местоположений
+	locationRoutes := v1.Group("/locations")
+	locationRoutes.Use(authHandler.CSRFMiddleware())
+	locationRoutes.Get("/", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceLocations, policyUseCase.ActionList), locHandler.GetOptions)
+	locationRoutes.Post("/", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceLocations, policyUseCase.ActionManage), locHandler.CreateOption)
+	locationRoutes.Delete("/:id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceLocations, policyUseCase.ActionManage), locHandler.DeleteOption)
+
+	// Маршруты дополнительных полей контактов
+	contactFieldRoutes := v1.Group("/contact-fields")
+	contactFieldRoutes.Use(authHandler.CSRFMiddleware())
+	contactFieldRoutes.Get("/", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContactFields, policyUseCase.ActionList), cfdHandler.GetContactFields)
+	contactFieldRoutes.Post("/", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContactFields, policyUseCase.ActionManage), cfdHandler.CreateContactField)
+	contactFieldRoutes.Put("/:id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContactFields, policyUseCase.ActionManage), cfdHandler.UpdateContactField)
+	contactFieldRoutes.Delete("/:id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContactFields, policyUseCase.ActionManage), cfdHandler.DeleteContactField)
+
+	// Маршруты заявок на печать: доступны любому пользователю с контактом, права проверяются в UseCase
+	printJobRoutes := v1.Group("/print-jobs")
+	printJobRoutes.Use(authHandler.RequireAuthCookie(), authHandler.CSRFMiddleware())
+	printJobRoutes.Post("/", pjHandler.SubmitPrintJob)
+	printJobRoutes.Get("/mine", pjHandler.GetMyPrintJobs)
+	printJobRoutes.Get("/queue", pjHandler.GetPrintQueue)
+	printJobRoutes.Put("/:id/status", pjHandler.ChangePrintJobStatus)
+	printJobRoutes.Get("/:id/file", pjHandler.GetPrintJobFile)
 
 	// Маршруты для Contact
 	contactRoutes := v1.Group("/contacts")
@@ -194,35 +695,297 @@ func main() {
 	// Добавляем CSRF защиту для всех изменяющих операций
 	contactRoutes.Use(authHandler.CSRFMiddleware())
 
-	contactRoutes.Get("/", cntHandler.GetAllContacts) // Доступно без авторизации (ограниченные данные)
+	contactRoutes.Get("/", authorize(policyUseCase.ResourceContacts, policyUseCase.ActionList), sysHandler.AnonymousDirectoryRateLimit(cntHandler.GetCachedGuestContacts), trackContactSearch, cntHandler.GetAllContacts) // Гостям - ограниченные данные
 
 	// Защищенные роуты (требуют авторизации)
-	contactRoutes.Post("/", authHandler.RequireAuthCookie(), requireAdminOrDebug, cntHandler.CreateContact)
-	contactRoutes.Get("/:id", authHandler.RequireAuthCookie(), cntHandler.GetContactByID)
-	contactRoutes.Put("/:id", authHandler.RequireAuthCookie(), requireAdminOrDebug, cntHandler.UpdateContact)
-	contactRoutes.Delete("/:id", authHandler.RequireAuthCookie(), requireAdminOrDebug, cntHandler.DeleteContact)
-	// Маршруты для управления связями контактов и групп (только админ)
-	contactRoutes.Post("/:contact_id/groups/:group_id", authHandler.RequireAuthCookie(), requireAdminOrDebug, cntHandler.AddContactToGroup)        // Добавить контакт в группу
-	contactRoutes.Delete("/:contact_id/groups/:group_id", authHandler.RequireAuthCookie(), requireAdminOrDebug, cntHandler.RemoveContactFromGroup) // Удалить контакт из группы
+	contactRoutes.Post("/", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionCreate), cntHandler.CreateContact)
+	contactRoutes.Post("/import", authHandler.RequireAuthCookie(), orgHandler.RequireDefaultOrganization(), authorize(policyUseCase.ResourceImports, policyUseCase.ActionManage), anlHandler.Track(analyticsUseCase.EventContactImport), impHandler.ImportContacts)
+	// Права на каждую операцию пакета проверяет usecase; авторизация нужна, чтобы определить роль
+	contactRoutes.Post("/batch", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionList), batHandler.ExecuteBatch)
+	// Полнотекстовый поиск, выгрузка, избранное и поиск дубликатов, как и /deleted, должны быть объявлены до /:id
+	contactRoutes.Get("/search", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionList), anlHandler.Track(analyticsUseCase.EventContactSearch), cntHandler.SearchContacts)
+	contactRoutes.Get("/export", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceExports, policyUseCase.ActionManage), anlHandler.Track(analyticsUseCase.EventContactExport), expHandler.ExportContactsCSV)
+	contactRoutes.Get("/favorites", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionList), cntHandler.GetFavoriteContacts)
+	contactRoutes.Get("/duplicates", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionMerge), dupHandler.GetDuplicates)
+	contactRoutes.Get("/deleted", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionRestore), cntHandler.GetDeletedContacts) // Должен быть объявлен до /:id
+	contactRoutes.Get("/:id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionRead), cntHandler.GetContactByID)
+	contactRoutes.Put("/:id", authHandler.RequireAuthCookie(), authorizeScoped(policyUseCase.ResourceContacts, policyUseCase.ActionUpdate), cntHandler.UpdateContact)
+	contactRoutes.Patch("/:id", authHandler.RequireAuthCookie(), authorizeScoped(policyUseCase.ResourceContacts, policyUseCase.ActionUpdate), cntHandler.PatchContact)
+	contactRoutes.Delete("/:id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionDelete), cntHandler.DeleteContact)
+	contactRoutes.Patch("/:id/archive", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionUpdate), cntHandler.ArchiveContact)
+	contactRoutes.Put("/:id/status", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionUpdate), cntHandler.ChangeContactStatus)
+	contactRoutes.Put("/:id/fields", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionUpdate), cfdHandler.SetContactFieldValues)
+	contactRoutes.Put("/:id/block", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceUsers, policyUseCase.ActionManage), authHandler.SetContactBlocked)
+	contactRoutes.Get("/:id/emergency", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceEmergencyContacts, policyUseCase.ActionRead), emgHandler.GetEmergencyContact)
+	contactRoutes.Put("/:id/emergency", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceEmergencyContacts, policyUseCase.ActionUpdate), emgHandler.UpdateEmergencyContact)
+	contactRoutes.Post("/:id/merge/:other_id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionMerge), dupHandler.MergeContacts)
+	contactRoutes.Post("/:id/restore", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionRestore), cntHandler.RestoreContact)
+	// Маршруты для управления связями контактов и групп
+	contactRoutes.Post("/:contact_id/groups/:group_id", authHandler.RequireAuthCookie(), authorizeScoped(policyUseCase.ResourceContacts, policyUseCase.ActionManageGroups), cntHandler.AddContactToGroup)        // Добавить контакт в группу
+	contactRoutes.Delete("/:contact_id/groups/:group_id", authHandler.RequireAuthCookie(), authorizeScoped(policyUseCase.ResourceContacts, policyUseCase.ActionManageGroups), cntHandler.RemoveContactFromGroup) // Удалить контакт из группы
+	// Навыки контакта
+	contactRoutes.Post("/:id/skills/:skill_id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionUpdate), cntHandler.AddContactSkill)
+	contactRoutes.Delete("/:id/skills/:skill_id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionUpdate), cntHandler.RemoveContactSkill)
+	contactRoutes.Post("/:id/tags/:tag_id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionUpdate), tagHandler.AddContactTag)
+	contactRoutes.Delete("/:id/tags/:tag_id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionUpdate), tagHandler.RemoveContactTag)
+	// Карточка контакта для печати
+	contactRoutes.Get("/:id/pdf", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionRead), prtHandler.GetContactCard)
+
+	// Личное избранное текущего пользователя
+	contactRoutes.Post("/:id/favorite", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionRead), cntHandler.AddFavoriteContact)
+	contactRoutes.Delete("/:id/favorite", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionRead), cntHandler.RemoveFavoriteContact)
+
+	contactRoutes.Get("/:id/avatar", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionRead), avtHandler.GetContactAvatar)
+	contactRoutes.Get("/:id/group-history", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionRead), cntHandler.GetContactGroupHistory)
+	contactRoutes.Get("/:id/visibility", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionUpdate), cntHandler.GetContactFieldVisibility)
+	contactRoutes.Put("/:id/visibility", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionUpdate), cntHandler.SetContactFieldVisibility)
+	contactRoutes.Get("/:id/history", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionHistory), cntHandler.GetContactHistory)
+	// Связи между контактами (наставник, руководитель, экстренный контакт)
+	contactRoutes.Get("/:id/relations", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionRead), cntHandler.GetContactRelations)
+	contactRoutes.Post("/:id/relations", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionUpdate), cntHandler.AddContactRelation)
+	contactRoutes.Delete("/:id/relations/:relation_id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionUpdate), cntHandler.DeleteContactRelation)
 
 	// Маршруты для Auth
 	authRoutes := v1.Group("/auth")
 	authRoutes.Post("/telegram", authHandler.AuthWithTelegram)
+	authRoutes.Post("/phone/code", authHandler.RequestPhoneCode) // Запросить код входа по SMS
+	authRoutes.Post("/phone", authHandler.AuthWithPhone)         // Войти по телефону и коду
 	authRoutes.Get("/me", authHandler.GetMe)
 	authRoutes.Get("/csrf-token", authHandler.GetCSRFToken) // Получить CSRF токен
+	// Подтверждение email по ссылке из письма, без входа
+	authRoutes.Get("/email/verify", authHandler.VerifyEmail)
 
 	// Защищенные auth роуты с CSRF защитой
 	authRoutes.Use(authHandler.CSRFMiddleware())
-	authRoutes.Put("/contact", authHandler.RequireAuthCookie(), authHandler.UpdateMyContact) // Обновить свой контакт
+	authRoutes.Put("/contact", authHandler.RequireAuthCookie(), anlHandler.Track(analyticsUseCase.EventProfileEdit), authHandler.UpdateMyContact) // Обновить свой контакт
+	authRoutes.Put("/timezone", authHandler.RequireAuthCookie(), authHandler.UpdateTimezone)                                                      // Установить свой часовой пояс
+	// Видимость полей своего контакта
+	authRoutes.Get("/contact/visibility", authHandler.RequireAuthCookie(), cntHandler.GetMyFieldVisibility)
+	authRoutes.Put("/contact/visibility", authHandler.RequireAuthCookie(), cntHandler.SetMyFieldVisibility)
+	// Повторно отправить ссылку подтверждения email своего контакта
+	authRoutes.Post("/email/verification", authHandler.RequireAuthCookie(), authHandler.ResendEmailVerification)
 	authRoutes.Post("/logout", authHandler.Logout)
+	// Принять действующую версию соглашения; единственный маршрут с авторизацией, доступный до его принятия
+	authRoutes.Post("/accept-terms", authHandler.AllowPendingTerms(), authHandler.RequireAuthCookie(), authHandler.AcceptTerms)
+	authRoutes.Get("/devices", authHandler.RequireAuthCookie(), authHandler.GetDevices)          // Свои устройства
+	authRoutes.Put("/devices/:id", authHandler.RequireAuthCookie(), authHandler.UpdateDevice)    // Переименовать или подтвердить устройство
+	authRoutes.Delete("/devices/:id", authHandler.RequireAuthCookie(), authHandler.RevokeDevice) // Отозвать устройство
+
+	// Подписки Web Push и выбор каналов уведомлений текущего пользователя
+	notificationRoutes := v1.Group("/notifications")
+	notificationRoutes.Get("/push/key", ntfHandler.GetPushKey)
+	notificationRoutes.Use(authHandler.CSRFMiddleware(), authHandler.RequireAuthCookie())
+	notificationRoutes.Get("/push/subscriptions", ntfHandler.GetPushSubscriptions)
+	notificationRoutes.Post("/push/subscriptions", ntfHandler.SubscribePush)
+	notificationRoutes.Delete("/push/subscriptions", ntfHandler.UnsubscribePush)
+	notificationRoutes.Get("/channels", ntfHandler.GetNotificationChannels)
+	notificationRoutes.Put("/channels", ntfHandler.SetNotificationChannels)
+	notificationRoutes.Get("/digest", ntfHandler.GetWeeklyDigest)
+	notificationRoutes.Put("/digest", ntfHandler.SetWeeklyDigest)
 
 	// Маршруты для System (публичные для получения, только админ для установки)
 	systemRoutes := v1.Group("/system")
-	systemRoutes.Get("/debug-mode", sysHandler.GetDebugMode) // Получить состояние отладочного режима
+	systemRoutes.Get("/debug-mode", sysHandler.GetDebugMode)                  // Получить состояние отладочного режима
+	systemRoutes.Get("/status", sysHandler.GetStatus)                         // Состояние внешних зависимостей для виджета
+	systemRoutes.Get("/contact-field-rules", sysHandler.GetContactFieldRules) // Варианты полей для форм контакта и профиля
+	// Отчеты браузеров о нарушениях Content-Security-Policy; адрес указан в заголовке политики
+	v1.Post("/csp-report", sysHandler.ReportCSPViolation)
 
 	// Защищенные system роуты с CSRF защитой
 	systemRoutes.Use(authHandler.CSRFMiddleware())
-	systemRoutes.Put("/debug-mode", authHandler.RequireAuthCookie(), requireAdminOrDebug, sysHandler.SetDebugMode) // Установить отладочный режим (только админ)
+	systemRoutes.Put("/debug-mode", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), sysHandler.SetDebugMode) // Установить отладочный режим (только админ)
+	systemRoutes.Get("/max-sessions", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), sysHandler.GetMaxSessions)
+	systemRoutes.Put("/max-sessions", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), sysHandler.SetMaxSessions)
+	systemRoutes.Get("/admin-device-approval", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), sysHandler.GetAdminDeviceApproval)
+	systemRoutes.Put("/admin-device-approval", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), sysHandler.SetAdminDeviceApproval)
+	systemRoutes.Get("/moderated-field-groups", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), sysHandler.GetModeratedFieldGroups)
+	systemRoutes.Put("/moderated-field-groups", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), sysHandler.SetModeratedFieldGroups)
+	// Все настройки времени выполнения для административного интерфейса
+	systemRoutes.Get("/settings", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), sysHandler.GetSettings)
+	systemRoutes.Put("/settings/:key", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), sysHandler.UpdateSetting)
+	// Лимиты частоты запросов общие для всего развертывания
+	systemRoutes.Get("/rate-limits", authHandler.RequireAuthCookie(), orgHandler.RequireDefaultOrganization(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), sysHandler.GetRateLimits)
+	systemRoutes.Put("/rate-limits", authHandler.RequireAuthCookie(), orgHandler.RequireDefaultOrganization(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), sysHandler.SetRateLimits)
+	systemRoutes.Get("/concurrency-limits", authHandler.RequireAuthCookie(), orgHandler.RequireDefaultOrganization(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), sysHandler.GetConcurrencyLimits)
+	systemRoutes.Put("/concurrency-limits", authHandler.RequireAuthCookie(), orgHandler.RequireDefaultOrganization(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), sysHandler.SetConcurrencyLimits)
+	systemRoutes.Get("/csp-reports", authHandler.RequireAuthCookie(), orgHandler.RequireDefaultOrganization(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), sysHandler.GetCSPViolations)
+
+	// Диагностика процесса для разбора задержек в продакшене без пересборки: профили pprof
+	// (/debug/pprof/profile?seconds=30, /debug/pprof/heap), счетчики expvar и статистика GC.
+	// Профили раскрывают внутреннее устройство всего развертывания, поэтому только для администраторов основной организации.
+	debugRoutes := v1.Group("/debug")
+	debugRoutes.Use(authHandler.RequireAuthCookie(), orgHandler.RequireDefaultOrganization(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage))
+	debugRoutes.Use(pprof.New(pprof.Config{Prefix: "/api/v1"}))
+	debugRoutes.Get("/vars", adaptor.HTTPHandler(expvar.Handler()))
+	debugRoutes.Get("/runtime", sysHandler.GetRuntimeStats)
+
+	// Маршруты для заявок на изменение контактов
+	changeRequestRoutes := v1.Group("/change-requests")
+	changeRequestRoutes.Use(authHandler.CSRFMiddleware())
+	changeRequestRoutes.Use(authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceChangeRequests, policyUseCase.ActionManage))
+	changeRequestRoutes.Get("/", modHandler.GetChangeRequests)
+	changeRequestRoutes.Get("/:id", modHandler.GetChangeRequest)
+	changeRequestRoutes.Post("/:id/approve", modHandler.ApproveChangeRequest)
+	changeRequestRoutes.Post("/:id/reject", modHandler.RejectChangeRequest)
+
+	// Маршруты для импорта контактов из CSV
+	importRoutes := v1.Group("/imports")
+	importRoutes.Use(authHandler.CSRFMiddleware())
+	importRoutes.Use(authHandler.RequireAuthCookie(), orgHandler.RequireDefaultOrganization(), authorize(policyUseCase.ResourceImports, policyUseCase.ActionManage))
+	importRoutes.Get("/", impHandler.GetImports)
+	importRoutes.Post("/", anlHandler.Track(analyticsUseCase.EventContactImport), impHandler.UploadImport)
+	importRoutes.Get("/:id", impHandler.GetImport)
+	importRoutes.Post("/:id/confirm", impHandler.ConfirmImport)
+	importRoutes.Post("/:id/rollback", impHandler.RollbackImport)
+	importRoutes.Delete("/:id", impHandler.DiscardImport)
+
+	// Пакетные запросы: несколько операций с контактами и группами в одной транзакции.
+	// Права на каждую операцию проверяются отдельно в usecase.
+	v1.Post("/batch", authHandler.CSRFMiddleware(), authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceContacts, policyUseCase.ActionCreate), batHandler.ExecuteBatch)
+
+	// GraphQL для чтения справочника; права на поля контактов те же, что у списка контактов
+	graphqlRoutes := v1.Group("/graphql")
+	graphqlRoutes.Use(authHandler.CookieAuthMiddleware())
+	graphqlRoutes.Use(authHandler.CSRFMiddleware())
+	graphqlRoutes.Get("/schema", gqlHandler.Schema)
+	graphqlRoutes.Get("/", authorize(policyUseCase.ResourceContacts, policyUseCase.ActionList), anlHandler.Track(analyticsUseCase.EventGraphQLQuery), gqlHandler.Query)
+	graphqlRoutes.Post("/", authorize(policyUseCase.ResourceContacts, policyUseCase.ActionList), anlHandler.Track(analyticsUseCase.EventGraphQLQuery), gqlHandler.Query)
+
+	// Webhook бота Telegram; запрос подтверждается секретом, а не cookie, поэтому CSRF не проверяется
+	if cfg.TelegramWebhookSecret.Get() != "" {
+		v1.Post("/telegram/webhook", btHandler.Webhook)
+	} else {
+		log.Info("TELEGRAM_WEBHOOK_SECRET is not set, Telegram webhook is disabled")
+	}
+
+	// Маршруты для выгрузки контактов во внешние таблицы
+	exportRoutes := v1.Group("/exports")
+	exportRoutes.Use(authHandler.CSRFMiddleware())
+	exportRoutes.Use(authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceExports, policyUseCase.ActionManage))
+	exportRoutes.Post("/google-sheets", anlHandler.Track(analyticsUseCase.EventContactExport), expHandler.ExportToGoogleSheets)
+	exportRoutes.Get("/csv", anlHandler.Track(analyticsUseCase.EventContactExport), expHandler.ExportCSV)
+	exportRoutes.Get("/columns", expHandler.GetColumns)
+	exportRoutes.Get("/templates", expHandler.GetTemplates)
+	exportRoutes.Post("/templates", expHandler.CreateTemplate)
+	exportRoutes.Put("/templates/:id", expHandler.UpdateTemplate)
+	exportRoutes.Delete("/templates/:id", expHandler.DeleteTemplate)
+
+	// Маршруты для Policy (просмотр по правилам, изменение только суперадминистраторами)
+	policyRoutes := v1.Group("/policies")
+	policyRoutes.Use(authHandler.CSRFMiddleware())
+	policyRoutes.Get("/", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourcePolicies, policyUseCase.ActionRead), polHandler.GetAllRules)
+	policyRoutes.Post("/", authHandler.RequireAuthCookie(), authHandler.RequireSuperAdmin(), polHandler.CreateRule)
+	policyRoutes.Put("/:id", authHandler.RequireAuthCookie(), authHandler.RequireSuperAdmin(), polHandler.UpdateRule)
+	policyRoutes.Delete("/:id", authHandler.RequireAuthCookie(), authHandler.RequireSuperAdmin(), polHandler.DeleteRule)
+
+	// Фото профилей пользователей (кэш фото Telegram на сервере)
+	v1.Get("/users/:id/photo", authHandler.RequireAuthCookie(), authHandler.GetUserPhoto)
+
+	// Список пользователей для администраторов (поиск неактивных)
+	v1.Get("/users", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceUsers, policyUseCase.ActionList), authHandler.GetUsers)
+
+	// Журнал аудита организации для администраторов
+	v1.Get("/audit", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), audHandler.GetAuditEvents)
+
+	// Маршруты для сервисных аккаунтов ботов и интеграций
+	serviceAccountRoutes := v1.Group("/service-accounts")
+	serviceAccountRoutes.Use(authHandler.CSRFMiddleware())
+	serviceAccountRoutes.Use(authHandler.RequireAuthCookie(), orgHandler.RequireDefaultOrganization(), authorize(policyUseCase.ResourceServiceAccounts, policyUseCase.ActionManage))
+	serviceAccountRoutes.Get("/", authHandler.GetServiceAccounts)
+	serviceAccountRoutes.Post("/", authHandler.CreateServiceAccount)
+	serviceAccountRoutes.Post("/:id/rotate-key", authHandler.RotateServiceAccountKey)
+	serviceAccountRoutes.Put("/:id/status", authHandler.SetServiceAccountStatus)
+
+	// Синхронизация с кадровой системой: настроена на уровне развертывания, поэтому доступна в организации по умолчанию
+	hrSyncRoutes := v1.Group("/hr-sync")
+	hrSyncRoutes.Use(authHandler.CSRFMiddleware())
+	hrSyncRoutes.Use(authHandler.RequireAuthCookie(), orgHandler.RequireDefaultOrganization(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage))
+	hrSyncRoutes.Get("/runs", hrsHandler.GetHRSyncRuns)
+	hrSyncRoutes.Get("/runs/:id", hrsHandler.GetHRSyncRun)
+	hrSyncRoutes.Post("/runs", hrsHandler.StartHRSync)
+
+	// Управление короткими ссылками
+	shortLinkRoutes := v1.Group("/short-links")
+	shortLinkRoutes.Use(authHandler.CSRFMiddleware())
+	shortLinkRoutes.Use(authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceShortLinks, policyUseCase.ActionManage))
+	shortLinkRoutes.Get("/", slHandler.GetShortLinks)
+	shortLinkRoutes.Post("/", slHandler.CreateShortLink)
+	shortLinkRoutes.Put("/:id", slHandler.UpdateShortLink)
+	shortLinkRoutes.Delete("/:id", slHandler.DeleteShortLink)
+
+	// Организации: список и создание - суперадминистраторам, участники - администраторам текущей организации
+	organizationRoutes := v1.Group("/organizations")
+	organizationRoutes.Use(authHandler.CSRFMiddleware())
+	organizationRoutes.Get("/mine", authHandler.RequireAuthCookie(), orgHandler.GetMyOrganizations)
+	organizationRoutes.Get("/current/members", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceUsers, policyUseCase.ActionManage), orgHandler.GetMembers)
+	organizationRoutes.Put("/current/members/:user_id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceUsers, policyUseCase.ActionManage), orgHandler.SetMember)
+	organizationRoutes.Delete("/current/members/:user_id", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceUsers, policyUseCase.ActionManage), orgHandler.RemoveMember)
+	organizationRoutes.Get("/", authHandler.RequireAuthCookie(), authHandler.RequireSuperAdmin(), orgHandler.GetOrganizations)
+	organizationRoutes.Post("/", authHandler.RequireAuthCookie(), authHandler.RequireSuperAdmin(), orgHandler.CreateOrganization)
+	organizationRoutes.Put("/:id", authHandler.RequireAuthCookie(), authHandler.RequireSuperAdmin(), orgHandler.UpdateOrganization)
+
+	// Статистика использования функций для администраторов
+	v1.Get("/analytics/usage", authHandler.RequireAuthCookie(), authorize(policyUseCase.ResourceSystem, policyUseCase.ActionManage), anlHandler.GetUsage)
+
+	// Маршруты для управления токенами доступа
+	tokenRoutes := v1.Group("/tokens")
+	tokenRoutes.Use(authHandler.CSRFMiddleware())
+	// Дашборды работают без авторизации, то есть в организации по умолчанию
+	tokenRoutes.Get("/", authHandler.RequireAuthCookie(), orgHandler.RequireDefaultOrganization(), authorize(policyUseCase.ResourceTokens, policyUseCase.ActionManage), tknHandler.GetAllTokens)
+	tokenRoutes.Post("/", authHandler.RequireAuthCookie(), orgHandler.RequireDefaultOrganization(), authorize(policyUseCase.ResourceTokens, policyUseCase.ActionManage), tknHandler.CreateToken)
+	tokenRoutes.Delete("/:id", authHandler.RequireAuthCookie(), orgHandler.RequireDefaultOrganization(), authorize(policyUseCase.ResourceTokens, policyUseCase.ActionManage), tknHandler.RevokeToken)
+
+	// Маршруты только для чтения по токену доступа (экраны-дашборды)
+	dashboardRoutes := v1.Group("/dashboard")
+	dashboardRoutes.Get("/contacts", tknHandler.RequireScope(domain.ScopeContactsRead), cntHandler.GetDashboardContacts)
+	dashboardRoutes.Get("/groups", tknHandler.RequireScope(domain.ScopeGroupsRead), grpHandler.GetAllGroups)
+
+	// Публичный справочник для встраивания на сайт: отдельный от основного API префикс, только токены
+	// с областью directory:read, запросы с любого источника без cookies и кэширование ответов
+	publicRoutes := app.Group("/api/public/v1")
+	publicRoutes.Use(cors.New(cors.Config{
+		AllowOrigins: "*",
+		AllowMethods: "GET, OPTIONS",
+		AllowHeaders: "Authorization, X-API-Token",
+	}))
+	publicRouteGroups := map[string]string{
+		"/api/public/v1/contacts": systemUseCase.RateLimitGroupContacts,
+	}
+	publicRoutes.Use(sysHandler.RateLimit(publicRouteGroups))
+	publicRoutes.Use(sysHandler.ConcurrencyLimit(publicRouteGroups))
+	publicRoutes.Use(orgHandler.DefaultOrganization())
+	publicCacheControl := fmt.Sprintf("public, max-age=%d", int(cfg.PublicDirectoryCacheTTL.Seconds()))
+	publicRoutes.Get("/contacts",
+		tknHandler.RequireScope(domain.ScopeDirectoryRead),
+		etag.New(),
+		cache.New(cache.Config{
+			Expiration:   cfg.PublicDirectoryCacheTTL,
+			CacheControl: true,
+			MaxBytes:     32 << 20,
+			// Ответ зависит только от группы токена и параметров запроса
+			KeyGenerator: func(c *fiber.Ctx) string {
+				var groupID uint
+				if token, ok := c.Locals("api_token").(*domain.APIToken); ok && token.GroupID != nil {
+					groupID = *token.GroupID
+				}
+				return fmt.Sprintf("%d|%s", groupID, c.OriginalURL())
+			},
+			// Ошибки не кэшируются
+			Next: func(c *fiber.Ctx) bool { return c.Response().StatusCode() != fiber.StatusOK },
+		}),
+		func(c *fiber.Ctx) error {
+			// При попадании в кэш Cache-Control выставляет middleware кэша
+			err := c.Next()
+			if err == nil && c.Response().StatusCode() == fiber.StatusOK {
+				c.Set(fiber.HeaderCacheControl, publicCacheControl)
+			}
+			return err
+		},
+		cntHandler.GetPublicContacts,
+	)
+
+	// Переход по короткой ссылке доступен без входа; лимит защищает от перебора кодов
+	app.Get("/l/:code", sysHandler.RateLimit(nil), slHandler.Redirect)
 
 	app.Get("/", func(c *fiber.Ctx) error {
 		log.Info("Received request for /", slog.String("ip", c.IP()))
@@ -230,9 +993,48 @@ func main() {
 	})
 
 	listenAddr := fmt.Sprintf(":%s", cfg.AppPort)
-	log.Info("Starting server", slog.String("address", listenAddr))
+	ln, inherited, err := listener.Listen(app.Config().Network, listenAddr, cfg.ListenReusePort)
+	if err != nil {
+		log.Error("Failed to listen", slog.String("address", listenAddr), slog.Any("error", err))
+		return
+	}
+	log.Info("Starting server", slog.String("address", ln.Addr().String()), slog.Bool("inherited_socket", inherited), slog.Bool("reuseport", cfg.ListenReusePort))
 
-	if err := app.Listen(listenAddr); err != nil {
+	// Без Redis экземпляр считается единственным и выполняет задачи без блокировки
+	schedulerDone := make(chan struct{})
+	go func() {
+		leader.New(redisClient, "scheduler:leader", cfg.SchedulerLockTTL, log).Run(ctx, scheduledJobs...)
+		close(schedulerDone)
+	}()
+
+	// После сигнала сервер закрывает сокет (новые соединения принимает следующий процесс)
+	// и ждет ответов на начатые запросы; keep-alive соединения закрываются после текущего ответа
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		<-ctx.Done()
+		log.Info("Shutting down, draining in-flight requests", slog.Duration("timeout", cfg.ShutdownTimeout))
+		if err := app.ShutdownWithTimeout(cfg.ShutdownTimeout); err != nil {
+			log.Warn("In-flight requests did not finish before shutdown timeout", slog.Any("error", err))
+		}
+	}()
+
+	if err := app.Listener(ln); err != nil {
 		log.Error("Failed to start server", slog.Any("error", err))
+		stop()
+	}
+	<-drained
+	stopAnalytics()
+
+	// Задачи по расписанию останавливаются вместе с ctx; блокировка освобождается после их завершения
+	timeout := time.After(cfg.ShutdownTimeout)
+	for _, done := range []chan struct{}{schedulerDone, analyticsDone} {
+		select {
+		case <-done:
+		case <-timeout:
+			log.Warn("Background jobs did not stop before shutdown timeout")
+			return
+		}
 	}
+	log.Info("Server stopped")
 }
@@ -0,0 +1,24 @@
+// Команда vapid-keys создает пару ключей VAPID для уведомлений Web Push:
+//
+//	go run ./cmd/vapid-keys
+//
+// Значения записываются в VAPID_PUBLIC_KEY и VAPID_PRIVATE_KEY. После смены ключей браузеры
+// нужно подписать заново: прежние подписки привязаны к старому открытому ключу.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"rim/pkg/webpush"
+)
+
+func main() {
+	publicKey, privateKey, err := webpush.GenerateKeys()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to generate VAPID keys:", err)
+		os.Exit(1)
+	}
+	fmt.Println("VAPID_PUBLIC_KEY=" + publicKey)
+	fmt.Println("VAPID_PRIVATE_KEY=" + privateKey)
+}
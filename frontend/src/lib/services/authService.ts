@@ -1,4 +1,4 @@
-import type { TelegramAuthData, SessionResponse, User } from '../types.js';
+import type { TelegramAuthData, SessionResponse, User, ContactFieldRules, TermsOfService } from '../types.js';
 import { config } from '../config.js';
 
 const API_BASE_URL = `http://localhost:3000/api/v1/auth`; // Базовый URL для эндпоинтов аутентификации
@@ -174,6 +174,27 @@ const AuthService = {
     return handleResponse(response);
   },
 
+  // Принять действующую версию пользовательского соглашения
+  acceptTerms: async (version: string): Promise<TermsOfService> => {
+    const headers: Record<string, string> = await createSecureHeaders() as Record<string, string>;
+    const response = await fetch(`${API_BASE_URL}/accept-terms`, {
+      method: 'POST',
+      headers,
+      credentials: 'include',
+      body: JSON.stringify({ version }),
+    });
+    return handleResponse(response);
+  },
+
+  // Получить допустимые значения полей контакта для форм
+  getContactFieldRules: async (): Promise<ContactFieldRules> => {
+    const response = await fetch('http://localhost:3000/api/v1/system/contact-field-rules', {
+      method: 'GET',
+      credentials: 'include',
+    });
+    return handleResponse(response);
+  },
+
   // Установить состояние отладочного режима
   setDebugMode: async (token: string, enabled: boolean): Promise<{ enabled: boolean }> => {
     console.log('AuthService.setDebugMode called', enabled);
@@ -30,6 +30,14 @@ export interface ContactPayload { // Данные для создания/обн
   // groupIds?: string[]; // Если при обновлении контакта можно менять группы
 }
 
+// Допустимые значения полей контакта; администраторы меняют их в настройке contact_field_rules
+export interface ContactFieldRules {
+  transport: string[];
+  printer: string[];
+  name_min_length: number;
+  name_max_length: number;
+}
+
 export interface Group {
   id: string;
   name: string;
@@ -74,6 +82,13 @@ export interface User {
   is_admin: boolean;
   contact?: Contact;
   created_at: string;
+  terms_required?: TermsOfService; // Соглашение, которое нужно принять до работы с порталом
+}
+
+// Версия пользовательского соглашения
+export interface TermsOfService {
+  version: string;
+  url?: string;
 }
 
 // Общий тип для элементов, имеющих ID, полезно для списков
@@ -14,6 +14,11 @@ export default defineConfig({
         changeOrigin: true,
         secure: false,
         rewrite: (path) => path // Убираем перезапись пути, чтобы /api/v1/contacts попадал на backend как есть
+      },
+      // Короткие ссылки /l/{code} обрабатывает backend
+      '/l/': {
+        target: 'http://localhost:3000',
+        changeOrigin: true
       }
     }
   }
@@ -0,0 +1,103 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"rim/internal/analytics/usecase"
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/pkg/timeutil"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultRangeDays - период по умолчанию, если from не указан
+const defaultRangeDays = 30
+
+// Handler собирает события использования и отдает счетчики администраторам.
+type Handler struct {
+	analyticsUseCase usecase.UseCase
+	logger           *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для аналитики.
+func NewHandler(analyticsUseCase usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		analyticsUseCase: analyticsUseCase,
+		logger:           logger,
+	}
+}
+
+// Track возвращает middleware, учитывающее event после успешного ответа обработчика.
+// Записывается только имя события; пользователь и параметры запроса не сохраняются.
+func (h *Handler) Track(event string) fiber.Handler {
+	return h.TrackIf(event, nil)
+}
+
+// TrackIf как Track, но учитывает событие только если match возвращает true.
+func (h *Handler) TrackIf(event string, match func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		status := c.Response().StatusCode()
+		if err == nil && status >= fiber.StatusOK && status < fiber.StatusMultipleChoices && (match == nil || match(c)) {
+			h.analyticsUseCase.Record(event)
+		}
+		return err
+	}
+}
+
+// GetUsage возвращает дневные счетчики использования функций.
+// @Summary Статистика использования функций
+// @Description Дневные (UTC) счетчики анонимных событий: поиск контактов, экспорт, импорт, редактирование профиля, запросы GraphQL. По умолчанию - последние 30 дней.
+// @Tags analytics
+// @Produce json
+// @Param from query string false "Первый день (YYYY-MM-DD)"
+// @Param to query string false "Последний день (YYYY-MM-DD), по умолчанию сегодня"
+// @Param event query string false "Событие" Enums(contact_search, contact_export, contact_import, profile_edit, graphql_query)
+// @Success 200 {object} UsageResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Неверный период или событие"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Недостаточно прав"
+// @Router /analytics/usage [get]
+func (h *Handler) GetUsage(c *fiber.Ctx) error {
+	to := timeutil.Now().UTC()
+	if raw := c.Query("to"); raw != "" {
+		parsed, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid 'to' date, expected YYYY-MM-DD"})
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -(defaultRangeDays - 1))
+	if raw := c.Query("from"); raw != "" {
+		parsed, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid 'from' date, expected YYYY-MM-DD"})
+		}
+		from = parsed
+	}
+
+	counters, err := h.analyticsUseCase.GetUsage(c.Context(), from, to, c.Query("event"))
+	if err != nil {
+		if errors.Is(err, usecase.ErrUnknownEvent) || errors.Is(err, usecase.ErrInvalidRange) {
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to get usage counters", slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+
+	totals := make(map[string]int64)
+	for _, counter := range counters {
+		totals[counter.Event] += counter.Count
+	}
+	if counters == nil {
+		counters = []domain.UsageCounter{}
+	}
+	return c.JSON(UsageResponse{
+		From:     from.Format(time.DateOnly),
+		To:       to.Format(time.DateOnly),
+		Totals:   totals,
+		Counters: counters,
+	})
+}
@@ -0,0 +1,11 @@
+package delivery
+
+import "rim/internal/domain"
+
+// UsageResponse - счетчики использования функций за период
+type UsageResponse struct {
+	From     string                `json:"from"` // YYYY-MM-DD, UTC
+	To       string                `json:"to"`
+	Totals   map[string]int64      `json:"totals"` // Сумма за период по событиям
+	Counters []domain.UsageCounter `json:"counters"`
+}
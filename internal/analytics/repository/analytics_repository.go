@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository определяет интерфейс для счетчиков использования
+type Repository interface {
+	// Add прибавляет значения counters к сохраненным счетчикам
+	Add(ctx context.Context, counters []domain.UsageCounter) error
+	// GetCounters возвращает счетчики за дни from..to включительно (YYYY-MM-DD); пустой event - все события
+	GetCounters(ctx context.Context, from, to, event string) ([]domain.UsageCounter, error)
+}
+
+type analyticsRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр репозитория счетчиков использования
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &analyticsRepository{db: db, logger: logger}
+}
+
+func (r *analyticsRepository) Add(ctx context.Context, counters []domain.UsageCounter) error {
+	if len(counters) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}, {Name: "event"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("usage_counters.count + excluded.count")}),
+	}).Create(&counters).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to save usage counters", slog.Int("counters", len(counters)), slog.Any("error", err))
+	}
+	return err
+}
+
+func (r *analyticsRepository) GetCounters(ctx context.Context, from, to, event string) ([]domain.UsageCounter, error) {
+	query := r.db.WithContext(ctx).Where("date >= ? AND date <= ?", from, to)
+	if event != "" {
+		query = query.Where("event = ?", event)
+	}
+	var counters []domain.UsageCounter
+	if err := query.Order("date, event").Find(&counters).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Failed to get usage counters", slog.Any("error", err))
+		return nil, err
+	}
+	return counters, nil
+}
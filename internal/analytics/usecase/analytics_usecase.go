@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	analyticsRepo "rim/internal/analytics/repository"
+	"rim/internal/domain"
+	"rim/pkg/timeutil"
+)
+
+// События использования функций
+const (
+	EventContactSearch = "contact_search" // Список контактов с фильтрами
+	EventContactExport = "contact_export" // Выгрузка в CSV или Google Sheets
+	EventContactImport = "contact_import" // Загрузка файла импорта
+	EventProfileEdit   = "profile_edit"   // Пользователь изменил свой контакт
+	EventGraphQLQuery  = "graphql_query"
+)
+
+// Events - все собираемые события
+var Events = []string{EventContactSearch, EventContactExport, EventContactImport, EventProfileEdit, EventGraphQLQuery}
+
+// dateLayout - формат дня счетчика
+const dateLayout = time.DateOnly
+
+// maxRangeDays - наибольший запрашиваемый период
+const maxRangeDays = 366
+
+var (
+	ErrUnknownEvent = errors.New("unknown analytics event")
+	ErrInvalidRange = errors.New("invalid date range")
+)
+
+// UseCase определяет интерфейс сбора счетчиков использования.
+// События копятся в памяти и периодически прибавляются к дневным счетчикам в БД.
+type UseCase interface {
+	// Record учитывает событие; не обращается к БД и не блокирует запрос
+	Record(event string)
+	// Flush сохраняет накопленные события
+	Flush(ctx context.Context) error
+	// Run вызывает Flush каждые interval до отмены ctx
+	Run(ctx context.Context, interval time.Duration)
+	// GetUsage возвращает дневные счетчики за from..to включительно; пустой event - все события
+	GetUsage(ctx context.Context, from, to time.Time, event string) ([]domain.UsageCounter, error)
+}
+
+type counterKey struct {
+	date  string
+	event string
+}
+
+type analyticsUseCase struct {
+	repo   analyticsRepo.Repository
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	pending map[counterKey]int64
+}
+
+// NewAnalyticsUseCase создает новый экземпляр analyticsUseCase.
+func NewAnalyticsUseCase(repo analyticsRepo.Repository, logger *slog.Logger) UseCase {
+	return &analyticsUseCase{
+		repo:    repo,
+		logger:  logger,
+		pending: make(map[counterKey]int64),
+	}
+}
+
+func (uc *analyticsUseCase) Record(event string) {
+	key := counterKey{date: timeutil.Now().UTC().Format(dateLayout), event: event}
+	uc.mu.Lock()
+	uc.pending[key]++
+	uc.mu.Unlock()
+}
+
+func (uc *analyticsUseCase) Flush(ctx context.Context) error {
+	uc.mu.Lock()
+	pending := uc.pending
+	uc.pending = make(map[counterKey]int64)
+	uc.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	counters := make([]domain.UsageCounter, 0, len(pending))
+	for key, count := range pending {
+		counters = append(counters, domain.UsageCounter{Date: key.date, Event: key.event, Count: count})
+	}
+	if err := uc.repo.Add(ctx, counters); err != nil {
+		// Несохраненные события возвращаются в очередь до следующей попытки
+		uc.mu.Lock()
+		for key, count := range pending {
+			uc.pending[key] += count
+		}
+		uc.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (uc *analyticsUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			_ = uc.Flush(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			_ = uc.Flush(ctx)
+		}
+	}
+}
+
+func (uc *analyticsUseCase) GetUsage(ctx context.Context, from, to time.Time, event string) ([]domain.UsageCounter, error) {
+	if event != "" && !slices.Contains(Events, event) {
+		return nil, ErrUnknownEvent
+	}
+	if to.Before(from) || to.Sub(from) > maxRangeDays*24*time.Hour {
+		return nil, ErrInvalidRange
+	}
+	// Администратор видит и события, еще не сохраненные в БД
+	if err := uc.Flush(ctx); err != nil {
+		uc.logger.WarnContext(ctx, "Failed to flush usage counters before query", slog.Any("error", err))
+	}
+	return uc.repo.GetCounters(ctx, from.UTC().Format(dateLayout), to.UTC().Format(dateLayout), event)
+}
@@ -0,0 +1,80 @@
+package delivery
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"rim/internal/audit/repository"
+	"rim/internal/audit/usecase"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/pkg/requestctx"
+	"rim/pkg/timeutil"
+)
+
+// Handler отвечает за HTTP-запросы журнала аудита.
+type Handler struct {
+	auditUseCase usecase.UseCase
+	logger       *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для журнала аудита.
+func NewHandler(au usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		auditUseCase: au,
+		logger:       logger,
+	}
+}
+
+// GetAuditEvents возвращает журнал аудита организации.
+// @Summary Получить журнал аудита
+// @Description Возвращает записи о блокировках контактов, отклоненных входах и других действиях, связанных с безопасностью, начиная с последней.
+// @Tags system
+// @Produce json
+// @Param action query string false "Только записи с этим действием, например contact.blocked или auth.login_blocked"
+// @Param contact_id query int false "Только записи, относящиеся к контакту"
+// @Param limit query int false "Количество записей (по умолчанию 100, не больше 1000)"
+// @Success 200 {array} AuditEventResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректные параметры"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /audit [get]
+func (h *Handler) GetAuditEvents(c *fiber.Ctx) error {
+	filter := repository.ListFilter{Action: c.Query("action")}
+	if raw := c.Query("contact_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact_id"})
+		}
+		filter.ContactID = uint(id)
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid limit"})
+		}
+		filter.Limit = limit
+	}
+
+	events, err := h.auditUseCase.List(c.Context(), filter)
+	if err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to get audit events", slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+
+	loc := requestctx.Location(c)
+	resp := make([]AuditEventResponse, len(events))
+	for i, ev := range events {
+		resp[i] = AuditEventResponse{
+			ID:         ev.ID,
+			Action:     ev.Action,
+			ActorID:    ev.ActorID,
+			ContactID:  ev.ContactID,
+			TelegramID: ev.TelegramID,
+			IP:         ev.IP,
+			Details:    ev.Details,
+			CreatedAt:  timeutil.Format(ev.CreatedAt, loc),
+		}
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
@@ -0,0 +1,13 @@
+package delivery
+
+// AuditEventResponse определяет структуру записи журнала аудита в ответе.
+type AuditEventResponse struct {
+	ID         uint   `json:"id"`
+	Action     string `json:"action"`
+	ActorID    *uint  `json:"actor_id,omitempty"`
+	ContactID  *uint  `json:"contact_id,omitempty"`
+	TelegramID int64  `json:"telegram_id,omitempty"`
+	IP         string `json:"ip,omitempty"`
+	Details    string `json:"details,omitempty"`
+	CreatedAt  string `json:"created_at"` // RFC3339 в часовом поясе пользователя
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+// ListFilter определяет условия отбора записей журнала аудита
+type ListFilter struct {
+	Action    string // Только записи с этим действием
+	ContactID uint   // Только записи, относящиеся к контакту
+	Limit     int
+}
+
+// Repository определяет интерфейс хранения журнала аудита
+type Repository interface {
+	Create(ctx context.Context, event *domain.AuditEvent) error
+	// List возвращает записи организации запроса, начиная с последней
+	List(ctx context.Context, filter ListFilter) ([]domain.AuditEvent, error)
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр репозитория журнала аудита
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{db: db, logger: logger}
+}
+
+func (r *sqliteRepository) Create(ctx context.Context, event *domain.AuditEvent) error {
+	if orgID := tenant.FromContext(ctx); orgID != 0 && event.OrganizationID == 0 {
+		event.OrganizationID = orgID
+	}
+	if err := transaction.DB(ctx, r.db).Create(event).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error creating audit event in DB", slog.String("action", event.Action), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) List(ctx context.Context, filter ListFilter) ([]domain.AuditEvent, error) {
+	query := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "audit_events"))
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ContactID != 0 {
+		query = query.Where("contact_id = ?", filter.ContactID)
+	}
+	var events []domain.AuditEvent
+	if err := query.Order("created_at DESC, id DESC").Limit(filter.Limit).Find(&events).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting audit events from DB", slog.Any("error", err))
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/audit/repository"
+	"rim/internal/domain"
+)
+
+// Ограничения размера страницы журнала
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// UseCase определяет интерфейс журнала аудита
+type UseCase interface {
+	// Record сохраняет запись журнала. Ошибка записи логируется и не прерывает действие, которое записывается.
+	Record(ctx context.Context, event domain.AuditEvent)
+	// List возвращает записи организации запроса, начиная с последней; limit 0 - значение по умолчанию
+	List(ctx context.Context, filter repository.ListFilter) ([]domain.AuditEvent, error)
+}
+
+type auditUseCase struct {
+	repo   repository.Repository
+	logger *slog.Logger
+}
+
+// NewAuditUseCase создает новый экземпляр UseCase для журнала аудита
+func NewAuditUseCase(repo repository.Repository, logger *slog.Logger) UseCase {
+	return &auditUseCase{repo: repo, logger: logger}
+}
+
+func (uc *auditUseCase) Record(ctx context.Context, event domain.AuditEvent) {
+	if err := uc.repo.Create(ctx, &event); err != nil {
+		// Запись остается хотя бы в логе сервера
+		uc.logger.ErrorContext(ctx, "Failed to record audit event", slog.String("action", event.Action), slog.Any("contact_id", event.ContactID), slog.Any("actor_id", event.ActorID), slog.Any("error", err))
+	}
+}
+
+func (uc *auditUseCase) List(ctx context.Context, filter repository.ListFilter) ([]domain.AuditEvent, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	}
+	filter.Limit = min(filter.Limit, maxListLimit)
+	return uc.repo.List(ctx, filter)
+}
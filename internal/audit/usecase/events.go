@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"context"
+
+	"rim/internal/domain"
+	"rim/pkg/eventbus"
+)
+
+// SubscribeEvents записывает в журнал аудита входы пользователей
+func SubscribeEvents(bus *eventbus.Bus, uc UseCase) {
+	eventbus.On(bus, func(ctx context.Context, e domain.UserLoggedInEvent) error {
+		uc.Record(ctx, domain.AuditEvent{
+			Action:     domain.AuditUserLoggedIn,
+			ActorID:    &e.User.ID,
+			ContactID:  e.User.ContactID,
+			TelegramID: e.User.TelegramID,
+			IP:         e.IP,
+			Details:    e.UserAgent,
+		})
+		return nil
+	})
+}
@@ -1,12 +1,21 @@
 package delivery
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
 	"rim/internal/auth/usecase"
+	contactRepo "rim/internal/contact/repository"
+	"rim/internal/domain"
 	systemUseCase "rim/internal/system/usecase"
+	"rim/pkg/photocache"
+	"rim/pkg/securityheaders"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,19 +24,28 @@ import (
 type Handler struct {
 	authUseCase    usecase.UseCase
 	systemUseCase  systemUseCase.UseCase
+	photoCache     *photocache.Cache
 	logger         *slog.Logger
-	botToken       string
+	botToken       func() string // Токен бота читается при каждой проверке, чтобы учитывать ротацию
 	forceDebugMode bool
+	cookies        securityheaders.CookiePolicy
+
+	resolveOrganization OrganizationResolver
 }
 
-// NewHandler создает новый экземпляр auth handler
-func NewHandler(authUseCase usecase.UseCase, systemUseCase systemUseCase.UseCase, botToken string, forceDebugMode bool, logger *slog.Logger) *Handler {
+// NewHandler создает новый экземпляр auth handler.
+// resolveOrganization выбирает организацию запроса после аутентификации пользователя.
+// cookies задает имя и атрибуты cookies сессии и устройства для окружения.
+func NewHandler(authUseCase usecase.UseCase, systemUseCase systemUseCase.UseCase, resolveOrganization OrganizationResolver, photoCache *photocache.Cache, botToken func() string, forceDebugMode bool, cookies securityheaders.CookiePolicy, logger *slog.Logger) *Handler {
 	return &Handler{
-		authUseCase:    authUseCase,
-		systemUseCase:  systemUseCase,
-		logger:         logger,
-		botToken:       botToken,
-		forceDebugMode: forceDebugMode,
+		authUseCase:         authUseCase,
+		systemUseCase:       systemUseCase,
+		photoCache:          photoCache,
+		logger:              logger,
+		botToken:            botToken,
+		forceDebugMode:      forceDebugMode,
+		cookies:             cookies,
+		resolveOrganization: resolveOrganization,
 	}
 }
 
@@ -42,6 +60,17 @@ type TelegramAuthRequest struct {
 	Hash      string `json:"hash" validate:"required"`
 }
 
+// PhoneCodeRequest представляет запрос одноразового кода входа по телефону
+type PhoneCodeRequest struct {
+	Phone string `json:"phone" validate:"required"`
+}
+
+// PhoneAuthRequest представляет запрос входа по телефону и одноразовому коду
+type PhoneAuthRequest struct {
+	Phone string `json:"phone" validate:"required"`
+	Code  string `json:"code" validate:"required"`
+}
+
 // SessionResponse представляет ответ с токеном сессии
 type SessionResponse struct {
 	SessionToken string `json:"session_token"`
@@ -53,9 +82,20 @@ type UserResponse struct {
 	ID         uint             `json:"id"`
 	TelegramID int64            `json:"telegram_id"`
 	IsActive   bool             `json:"is_active"`
-	IsAdmin    bool             `json:"is_admin"` // Флаг администратора
+	IsAdmin    bool             `json:"is_admin"`            // Флаг администратора
+	Timezone   string           `json:"timezone"`            // Часовой пояс IANA
+	PhotoURL   string           `json:"photo_url,omitempty"` // Адрес фото через сервер: /api/v1/users/{id}/photo
 	Contact    *ContactResponse `json:"contact,omitempty"`
-	CreatedAt  string           `json:"created_at"`
+	CreatedAt  string           `json:"created_at"` // RFC3339 в часовом поясе пользователя
+	// LastLoginAt - время последнего входа в часовом поясе пользователя
+	LastLoginAt string `json:"last_login_at,omitempty"`
+	// TermsRequired - соглашение, которое нужно принять через /auth/accept-terms до работы с остальными маршрутами
+	TermsRequired *TermsResponse `json:"terms_required,omitempty"`
+}
+
+// TimezoneRequest представляет запрос на изменение часового пояса пользователя
+type TimezoneRequest struct {
+	Timezone string `json:"timezone" validate:"required"`
 }
 
 // ContactResponse представляет информацию о контакте
@@ -70,18 +110,29 @@ type ContactResponse struct {
 	VK         string `json:"vk"`
 	Telegram   string `json:"telegram"`
 	TelegramID int64  `json:"telegram_id,omitempty"`
+
+	// EmailVerified - email подтвержден по ссылке из письма (POST /auth/email/verification отправляет ее повторно)
+	EmailVerified bool `json:"email_verified"`
+	// PendingChanges - изменения, ожидающие одобрения администратора
+	PendingChanges *PendingChangesResponse `json:"pending_changes,omitempty"`
+}
+
+// PendingChangesResponse представляет заявку на изменение контакта, ожидающую одобрения
+type PendingChangesResponse struct {
+	RequestID uint              `json:"request_id"`
+	Fields    map[string]string `json:"fields"`
 }
 
 // UpdateContactRequest представляет запрос на обновление контакта пользователя
 type UpdateContactRequest struct {
-	Name       *string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Name       *string `json:"name,omitempty" validate:"omitempty,length=contact_name"`
 	Phone      *string `json:"phone,omitempty" validate:"omitempty,e164"`
 	Email      *string `json:"email,omitempty" validate:"omitempty,email"`
-	Transport  *string `json:"transport,omitempty" validate:"omitempty,oneof='есть машина' 'есть права' 'нет ничего'"`
-	Printer    *string `json:"printer,omitempty" validate:"omitempty,oneof='цветной' 'обычный' 'нет'"`
+	Transport  *string `json:"transport,omitempty" validate:"omitempty,enum=transport"`
+	Printer    *string `json:"printer,omitempty" validate:"omitempty,enum=printer"`
 	Allergies  *string `json:"allergies,omitempty" validate:"omitempty,max=255"`
-	VK         *string `json:"vk,omitempty" validate:"omitempty,url"`
-	Telegram   *string `json:"telegram,omitempty" validate:"omitempty,alphanum"`
+	VK         *string `json:"vk,omitempty" validate:"omitempty,vk_url"`
+	Telegram   *string `json:"telegram,omitempty" validate:"omitempty,telegram_username"`
 	TelegramID *int64  `json:"telegram_id,omitempty"`
 }
 
@@ -95,15 +146,13 @@ type UpdateContactRequest struct {
 // @Success 200 {object} SessionResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string "Контакт заблокирован или устройство не подтверждено"
 // @Failure 500 {object} map[string]string
 // @Router /auth/telegram [post]
 func (h *Handler) AuthWithTelegram(c *fiber.Ctx) error {
-	var req TelegramAuthRequest
-	if err := c.BodyParser(&req); err != nil {
-		h.logger.WarnContext(c.Context(), "Invalid request body", slog.Any("error", err))
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, ok, err := validation.BindAndValidate[TelegramAuthRequest](c)
+	if !ok {
+		return err
 	}
 
 	// Преобразуем в структуру usecase
@@ -117,14 +166,21 @@ func (h *Handler) AuthWithTelegram(c *fiber.Ctx) error {
 		Hash:      req.Hash,
 	}
 
-	session, err := h.authUseCase.AuthenticateWithTelegram(c.Context(), authData, h.botToken)
+	session, err := h.authUseCase.AuthenticateWithTelegram(c.Context(), authData, h.botToken(), h.deviceInfo(c))
 	if err != nil {
+		if errors.Is(err, usecase.ErrSessionStoreUnavailable) {
+			return h.sessionStoreUnavailable(c)
+		}
 		switch err {
 		case usecase.ErrInvalidTelegramAuth:
 			h.logger.WarnContext(c.Context(), "Invalid telegram authentication", slog.Int64("telegram_id", req.ID))
 			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Invalid telegram authentication",
 			})
+		case usecase.ErrDeviceNotTrusted, usecase.ErrUserBlocked:
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
 		default:
 			h.logger.ErrorContext(c.Context(), "Failed to authenticate with telegram", slog.Any("error", err))
 			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
@@ -133,24 +189,101 @@ func (h *Handler) AuthWithTelegram(c *fiber.Ctx) error {
 		}
 	}
 
-	// Устанавливаем httpOnly cookie для защиты от XSS
-	c.Cookie(&fiber.Cookie{
-		Name:     "session_token",
-		Value:    session.SessionToken,
-		Expires:  session.ExpiredAt,
-		HTTPOnly: true,
-		Secure:   true,     // Только по HTTPS в продакшене
-		SameSite: "Strict", // Защита от CSRF
-		Path:     "/",
+	h.logger.InfoContext(c.Context(), "User authenticated successfully", slog.Uint64("user_id", uint64(session.UserID)))
+	return h.sessionResponse(c, session)
+}
+
+// RequestPhoneCode отправляет одноразовый код входа по SMS
+// @Summary Запросить код входа по телефону
+// @Description Отправляет одноразовый код на телефон, указанный в контакте. Ответ не зависит от наличия телефона в базе:
+// @Description повторный запрос раньше чем через минуту тоже получает 202, но новый код не отправляется. 429 - лимит запросов с адреса.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body PhoneCodeRequest true "Телефон"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/phone/code [post]
+func (h *Handler) RequestPhoneCode(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[PhoneCodeRequest](c)
+	if !ok {
+		return err
+	}
+
+	if err := h.authUseCase.RequestPhoneLoginCode(c.Context(), req.Phone); err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to send login code", slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusAccepted).JSON(fiber.Map{
+		"message": "If the phone is registered, a login code has been sent",
 	})
+}
 
-	response := SessionResponse{
-		SessionToken: session.SessionToken,
-		ExpiresAt:    session.ExpiredAt.Format("2006-01-02T15:04:05Z07:00"),
+// AuthWithPhone обрабатывает вход по телефону и одноразовому коду
+// @Summary Авторизация по телефону
+// @Description Проверяет одноразовый код из SMS и создает сессию
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body PhoneAuthRequest true "Телефон и код"
+// @Success 200 {object} SessionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string "Контакт заблокирован или устройство не подтверждено"
+// @Failure 500 {object} map[string]string
+// @Router /auth/phone [post]
+func (h *Handler) AuthWithPhone(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[PhoneAuthRequest](c)
+	if !ok {
+		return err
 	}
 
-	h.logger.InfoContext(c.Context(), "User authenticated successfully", slog.Uint64("user_id", uint64(session.UserID)))
-	return c.JSON(response)
+	session, err := h.authUseCase.AuthenticateWithPhone(c.Context(), req.Phone, req.Code, h.deviceInfo(c))
+	if err != nil {
+		if errors.Is(err, usecase.ErrSessionStoreUnavailable) {
+			return h.sessionStoreUnavailable(c)
+		}
+		switch err {
+		case usecase.ErrInvalidLoginCode:
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid login code",
+			})
+		case usecase.ErrLoginCodeExpired:
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Login code expired, request a new one",
+			})
+		case usecase.ErrContactNotFound, usecase.ErrUserNotFound:
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"error": "User not found",
+			})
+		case usecase.ErrDeviceNotTrusted, usecase.ErrUserBlocked:
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		default:
+			h.logger.ErrorContext(c.Context(), "Failed to authenticate with phone", slog.Any("error", err))
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Internal server error",
+			})
+		}
+	}
+
+	h.logger.InfoContext(c.Context(), "User authenticated with phone", slog.Uint64("user_id", uint64(session.UserID)))
+	return h.sessionResponse(c, session)
+}
+
+// sessionResponse устанавливает cookie сессии и возвращает токен в ответе
+func (h *Handler) sessionResponse(c *fiber.Ctx, session *domain.UserSession) error {
+	h.setSessionCookie(c, session)
+	return c.JSON(SessionResponse{
+		SessionToken: session.SessionToken,
+		ExpiresAt:    timeutil.Format(session.ExpiredAt, time.UTC),
+	})
 }
 
 // GetMe возвращает информацию о текущем пользователе
@@ -173,6 +306,9 @@ func (h *Handler) GetMe(c *fiber.Ctx) error {
 
 	user, err := h.authUseCase.GetUserBySession(c.Context(), sessionToken)
 	if err != nil {
+		if errors.Is(err, usecase.ErrSessionStoreUnavailable) {
+			return h.sessionStoreUnavailable(c)
+		}
 		switch err {
 		case usecase.ErrSessionNotFound, usecase.ErrSessionExpired:
 			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
@@ -198,21 +334,8 @@ func (h *Handler) GetMe(c *fiber.Ctx) error {
 	}
 
 	// Если не администратор, проверяем отладочный режим
-	if !isAdmin {
-		// Сначала проверяем принудительный отладочный режим из переменной окружения
-		if h.forceDebugMode {
-			h.logger.InfoContext(c.Context(), "Force debug mode is enabled, user gets admin rights", slog.Uint64("user_id", uint64(user.ID)))
-			isAdmin = true
-		} else {
-			// Затем проверяем отладочный режим из базы данных
-			debugMode, err := h.systemUseCase.GetDebugMode(c.Context())
-			if err != nil {
-				h.logger.WarnContext(c.Context(), "Failed to get debug mode status", slog.Any("error", err))
-			} else if debugMode {
-				h.logger.InfoContext(c.Context(), "Debug mode is enabled, user gets admin rights", slog.Uint64("user_id", uint64(user.ID)))
-				isAdmin = true
-			}
-		}
+	if !isAdmin && h.isDebugModeEnabled(c.Context()) {
+		isAdmin = true
 	}
 
 	response := UserResponse{
@@ -220,44 +343,102 @@ func (h *Handler) GetMe(c *fiber.Ctx) error {
 		TelegramID: user.TelegramID,
 		IsActive:   user.IsActive,
 		IsAdmin:    isAdmin,
-		CreatedAt:  user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Timezone:   user.Timezone,
+		CreatedAt:  timeutil.Format(user.CreatedAt, timeutil.LocationOrUTC(user.Timezone)),
+	}
+	if user.LastLoginAt != nil {
+		response.LastLoginAt = timeutil.Format(*user.LastLoginAt, timeutil.LocationOrUTC(user.Timezone))
+	}
+	if user.PhotoURL != "" {
+		response.PhotoURL = fmt.Sprintf("/api/v1/users/%d/photo", user.ID)
+	}
+	if terms, pending := h.authUseCase.PendingTerms(c.Context(), user); pending {
+		termsResponse := toTermsResponse(terms)
+		response.TermsRequired = &termsResponse
 	}
 
-	// Ищем контакт по telegram_id пользователя
-	contact, err := h.authUseCase.GetContactByTelegramID(c.Context(), user.TelegramID)
+	contact, err := h.authUseCase.GetUserContact(c.Context(), user)
 	if err != nil && err != usecase.ErrContactNotFound {
-		h.logger.WarnContext(c.Context(), "Failed to get contact by telegram_id", slog.Int64("telegram_id", user.TelegramID), slog.Any("error", err))
+		h.logger.WarnContext(c.Context(), "Failed to get user contact", slog.Uint64("user_id", uint64(user.ID)), slog.Any("error", err))
 	}
 
 	// Если контакт найден, добавляем его информацию
 	if contact != nil {
 		response.Contact = &ContactResponse{
-			ID:         contact.ID,
-			Name:       contact.Name,
-			Phone:      contact.Phone,
-			Email:      contact.Email,
-			Transport:  contact.Transport,
-			Printer:    contact.Printer,
-			Allergies:  contact.Allergies,
-			VK:         contact.VK,
-			Telegram:   contact.Telegram,
-			TelegramID: contact.TelegramID,
+			ID:            contact.ID,
+			Name:          contact.Name,
+			Phone:         contact.Phone,
+			Email:         contact.Email,
+			Transport:     contact.Transport,
+			Printer:       contact.Printer,
+			Allergies:     contact.Allergies,
+			VK:            contact.VK,
+			Telegram:      contact.Telegram,
+			TelegramID:    contact.TelegramID,
+			EmailVerified: contact.EmailVerified,
 		}
 	}
 
 	return c.JSON(response)
 }
 
+// GetUserPhoto отдает фото профиля пользователя из кэша сервера
+// @Summary Получить фото пользователя
+// @Description Отдает фото профиля Telegram через сервер, кэшируя его на диске, чтобы не зависеть от истекающих ссылок CDN Telegram
+// @Tags auth
+// @Produce image/jpeg
+// @Param id path int true "ID пользователя"
+// @Success 200 {file} binary
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /users/{id}/photo [get]
+func (h *Handler) GetUserPhoto(c *fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID format",
+		})
+	}
+
+	photoURL, err := h.authUseCase.GetUserPhotoURL(c.Context(), uint(userID))
+	if err != nil && err != usecase.ErrUserNotFound {
+		h.logger.ErrorContext(c.Context(), "Failed to get user photo URL", slog.Uint64("user_id", userID), slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+	if photoURL == "" {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "Photo not found",
+		})
+	}
+
+	data, contentType, err := h.photoCache.Get(c.Context(), fmt.Sprintf("user_%d", userID), photoURL)
+	if err != nil {
+		h.logger.WarnContext(c.Context(), "Failed to load user photo", slog.Uint64("user_id", userID), slog.Any("error", err))
+		return c.Status(http.StatusBadGateway).JSON(fiber.Map{
+			"error": "Photo is unavailable",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=3600")
+	return c.Send(data)
+}
+
 // UpdateMyContact обновляет контакт текущего пользователя
 // @Summary Обновить свой контакт
-// @Description Обновляет контакт пользователя, найденный по telegram_id
+// @Description Обновляет контакт, связанный с пользователем.
+// @Description Изменения полей из модерируемых групп не применяются сразу и возвращаются в pending_changes до решения администратора.
+// @Description При смене email на новый адрес отправляется ссылка подтверждения, а email_verified сбрасывается до перехода по ней.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param contact body UpdateContactRequest true "Данные для обновления контакта"
 // @Success 200 {object} ContactResponse
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} validation.Response "Ошибка валидации или некорректный запрос"
 // @Failure 401 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -272,6 +453,9 @@ func (h *Handler) UpdateMyContact(c *fiber.Ctx) error {
 
 	user, err := h.authUseCase.GetUserBySession(c.Context(), sessionToken)
 	if err != nil {
+		if errors.Is(err, usecase.ErrSessionStoreUnavailable) {
+			return h.sessionStoreUnavailable(c)
+		}
 		switch err {
 		case usecase.ErrSessionNotFound, usecase.ErrSessionExpired:
 			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
@@ -289,11 +473,9 @@ func (h *Handler) UpdateMyContact(c *fiber.Ctx) error {
 		}
 	}
 
-	var req UpdateContactRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, ok, err := validation.BindAndValidate[UpdateContactRequest](c)
+	if !ok {
+		return err
 	}
 
 	contactData := usecase.UpdateUserContactData{
@@ -308,13 +490,18 @@ func (h *Handler) UpdateMyContact(c *fiber.Ctx) error {
 		TelegramID: req.TelegramID,
 	}
 
-	updatedContact, err := h.authUseCase.UpdateUserContact(c.Context(), user.ID, contactData)
+	updatedContact, changeRequest, err := h.authUseCase.UpdateUserContact(c.Context(), user.ID, contactData)
 	if err != nil {
 		if err == usecase.ErrContactNotFound {
 			return c.Status(http.StatusNotFound).JSON(fiber.Map{
 				"error": "Contact not found",
 			})
 		}
+		if errors.Is(err, contactRepo.ErrDuplicateEmail) || errors.Is(err, contactRepo.ErrDuplicatePhone) {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		h.logger.ErrorContext(c.Context(), "Failed to update user contact", slog.Uint64("user_id", uint64(user.ID)), slog.Any("error", err))
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Internal server error",
@@ -322,21 +509,77 @@ func (h *Handler) UpdateMyContact(c *fiber.Ctx) error {
 	}
 
 	response := ContactResponse{
-		ID:         updatedContact.ID,
-		Name:       updatedContact.Name,
-		Phone:      updatedContact.Phone,
-		Email:      updatedContact.Email,
-		Transport:  updatedContact.Transport,
-		Printer:    updatedContact.Printer,
-		Allergies:  updatedContact.Allergies,
-		VK:         updatedContact.VK,
-		Telegram:   updatedContact.Telegram,
-		TelegramID: updatedContact.TelegramID,
+		ID:            updatedContact.ID,
+		Name:          updatedContact.Name,
+		Phone:         updatedContact.Phone,
+		Email:         updatedContact.Email,
+		Transport:     updatedContact.Transport,
+		Printer:       updatedContact.Printer,
+		Allergies:     updatedContact.Allergies,
+		VK:            updatedContact.VK,
+		Telegram:      updatedContact.Telegram,
+		TelegramID:    updatedContact.TelegramID,
+		EmailVerified: updatedContact.EmailVerified,
+	}
+	if changeRequest != nil {
+		response.PendingChanges = &PendingChangesResponse{
+			RequestID: changeRequest.ID,
+			Fields:    changeRequest.ChangeMap(),
+		}
 	}
 
 	return c.JSON(response)
 }
 
+// UpdateTimezone сохраняет часовой пояс текущего пользователя
+// @Summary Установить часовой пояс
+// @Description Сохраняет часовой пояс пользователя (имя IANA), в котором отображается время в ответах API
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param timezone body TimezoneRequest true "Часовой пояс, например Europe/Moscow"
+// @Success 200 {object} TimezoneRequest
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/timezone [put]
+func (h *Handler) UpdateTimezone(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	req, ok, err := validation.BindAndValidate[TimezoneRequest](c)
+	if !ok {
+		return err
+	}
+
+	user, err := h.authUseCase.SetUserTimezone(c.Context(), userID, req.Timezone)
+	if err != nil {
+		switch err {
+		case usecase.ErrInvalidTimezone:
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid timezone",
+			})
+		case usecase.ErrUserNotFound:
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"error": "User not found",
+			})
+		default:
+			h.logger.ErrorContext(c.Context(), "Failed to update user timezone", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Internal server error",
+			})
+		}
+	}
+
+	return c.JSON(TimezoneRequest{
+		Timezone: user.Timezone,
+	})
+}
+
 // Logout завершает сессию пользователя
 // @Summary Выход из системы
 // @Description Завершает текущую сессию пользователя
@@ -363,21 +606,18 @@ func (h *Handler) Logout(c *fiber.Ctx) error {
 	}
 
 	// Удаляем cookie
-	c.Cookie(&fiber.Cookie{
-		Name:     "session_token",
-		Value:    "",
-		Expires:  time.Now().Add(-time.Hour),
-		HTTPOnly: true,
-		Secure:   true,
-		SameSite: "Strict",
-		Path:     "/",
-	})
+	c.Cookie(h.cookies.Expired(h.cookies.SessionName))
 
 	return c.JSON(fiber.Map{
 		"message": "Successfully logged out",
 	})
 }
 
+// setSessionCookie устанавливает httpOnly cookie сессии для защиты от XSS
+func (h *Handler) setSessionCookie(c *fiber.Ctx, session *domain.UserSession) {
+	c.Cookie(h.cookies.Cookie(h.cookies.SessionName, session.SessionToken, session.ExpiredAt))
+}
+
 // extractSessionToken извлекает токен сессии из заголовка Authorization
 func (h *Handler) extractSessionToken(c *fiber.Ctx) string {
 	authHeader := c.Get("Authorization")
@@ -0,0 +1,76 @@
+package delivery
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"rim/internal/auth/usecase"
+	"rim/pkg/requestctx"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxBlockReasonLength ограничивает длину причины блокировки в журнале аудита
+const maxBlockReasonLength = 500
+
+// ContactBlockRequest представляет запрос на блокировку или разблокировку контакта
+type ContactBlockRequest struct {
+	Blocked bool   `json:"blocked"`
+	Reason  string `json:"reason"` // Причина для журнала аудита
+}
+
+// ContactBlockResponse представляет состояние блокировки контакта
+type ContactBlockResponse struct {
+	ContactID uint `json:"contact_id"`
+	Blocked   bool `json:"blocked"`
+}
+
+// SetContactBlocked блокирует или разблокирует контакт
+// @Summary Заблокировать или разблокировать контакт
+// @Description Заблокированный контакт сохраняется в справочнике, но его пользователь не может войти:
+// @Description при блокировке все его сессии завершаются, а попытки входа через Telegram или по телефону отклоняются и записываются в журнал аудита.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param id path int true "ID контакта"
+// @Param block body ContactBlockRequest true "Новое состояние и причина"
+// @Success 200 {object} ContactBlockResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /contacts/{id}/block [put]
+func (h *Handler) SetContactBlocked(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid contact ID format",
+		})
+	}
+
+	req, ok, err := validation.BindAndValidate[ContactBlockRequest](c)
+	if !ok {
+		return err
+	}
+	if utf8.RuneCountInString(req.Reason) > maxBlockReasonLength {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Reason is too long",
+		})
+	}
+
+	contact, err := h.authUseCase.SetContactBlocked(c.Context(), uint(id), req.Blocked, req.Reason, requestctx.ActorID(c))
+	if err != nil {
+		if err == usecase.ErrContactNotFound {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to set contact blocked flag", slog.Uint64("contact_id", id), slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+	return c.JSON(ContactBlockResponse{ContactID: contact.ID, Blocked: contact.Blocked})
+}
@@ -0,0 +1,194 @@
+package delivery
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rim/internal/auth/usecase"
+	"rim/internal/domain"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// deviceCookieName - cookie со случайным идентификатором устройства
+const deviceCookieName = "device_id"
+
+// DeviceResponse представляет устройство пользователя
+type DeviceResponse struct {
+	ID         uint   `json:"id"`
+	Label      string `json:"label"`
+	UserAgent  string `json:"user_agent"`
+	Trusted    bool   `json:"trusted"`
+	LastSeenAt string `json:"last_seen_at"` // RFC3339 в часовом поясе пользователя
+	CreatedAt  string `json:"created_at"`
+}
+
+// UpdateDeviceRequest представляет запрос на изменение устройства
+type UpdateDeviceRequest struct {
+	Label   *string `json:"label,omitempty"`
+	Trusted *bool   `json:"trusted,omitempty"`
+}
+
+// GetDevices возвращает устройства текущего пользователя
+// @Summary Получить свои устройства
+// @Description Возвращает устройства, с которых пользователь входил в систему
+// @Tags auth
+// @Produce json
+// @Success 200 {array} DeviceResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/devices [get]
+func (h *Handler) GetDevices(c *fiber.Ctx) error {
+	user, ok := GetUserFromContext(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	devices, err := h.authUseCase.GetUserDevices(c.Context(), user.ID)
+	if err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to get user devices", slog.Uint64("user_id", uint64(user.ID)), slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	loc := timeutil.LocationOrUTC(user.Timezone)
+	resp := make([]DeviceResponse, len(devices))
+	for i := range devices {
+		resp[i] = toDeviceResponse(&devices[i], loc)
+	}
+	return c.JSON(resp)
+}
+
+// UpdateDevice переименовывает устройство или меняет признак доверия
+// @Summary Изменить устройство
+// @Description Задает название устройства или подтверждает/отзывает доверие к нему. При снятии доверия сессии устройства завершаются.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID устройства"
+// @Param device body UpdateDeviceRequest true "Изменения"
+// @Success 200 {object} DeviceResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/devices/{id} [put]
+func (h *Handler) UpdateDevice(c *fiber.Ctx) error {
+	user, ok := GetUserFromContext(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	deviceID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid device ID format",
+		})
+	}
+
+	req, ok, err := validation.BindAndValidate[UpdateDeviceRequest](c)
+	if !ok {
+		return err
+	}
+
+	device, err := h.authUseCase.UpdateUserDevice(c.Context(), user.ID, uint(deviceID), usecase.UpdateDeviceData{
+		Label:   req.Label,
+		Trusted: req.Trusted,
+	})
+	if err != nil {
+		if err == usecase.ErrDeviceNotFound {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Device not found",
+			})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to update user device", slog.Uint64("device_id", deviceID), slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.JSON(toDeviceResponse(device, timeutil.LocationOrUTC(user.Timezone)))
+}
+
+// RevokeDevice завершает сессии устройства и удаляет его
+// @Summary Отозвать устройство
+// @Description Завершает все сессии устройства и удаляет его из списка
+// @Tags auth
+// @Param id path int true "ID устройства"
+// @Success 204 "Устройство отозвано"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/devices/{id} [delete]
+func (h *Handler) RevokeDevice(c *fiber.Ctx) error {
+	user, ok := GetUserFromContext(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	deviceID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid device ID format",
+		})
+	}
+
+	if err := h.authUseCase.RevokeUserDevice(c.Context(), user.ID, uint(deviceID)); err != nil {
+		if err == usecase.ErrDeviceNotFound {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Device not found",
+			})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to revoke user device", slog.Uint64("device_id", deviceID), slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// deviceInfo возвращает данные устройства для входа.
+// Идентификатор берется из cookie device_id или заголовка X-Device-ID; если его нет, выдается новый.
+func (h *Handler) deviceInfo(c *fiber.Ctx) usecase.DeviceInfo {
+	deviceID := c.Cookies(deviceCookieName)
+	if deviceID == "" {
+		deviceID = c.Get("X-Device-ID")
+	}
+	if deviceID == "" {
+		deviceID = uuid.New().String()
+	}
+
+	// Продлеваем cookie при каждом входе
+	c.Cookie(h.cookies.Cookie(deviceCookieName, deviceID, time.Now().AddDate(1, 0, 0)))
+
+	return usecase.DeviceInfo{
+		ID:        deviceID,
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IP:        c.IP(),
+	}
+}
+
+func toDeviceResponse(device *domain.UserDevice, loc *time.Location) DeviceResponse {
+	return DeviceResponse{
+		ID:         device.ID,
+		Label:      device.Label,
+		UserAgent:  device.UserAgent,
+		Trusted:    device.Trusted,
+		LastSeenAt: timeutil.Format(device.LastSeenAt, loc),
+		CreatedAt:  timeutil.Format(device.CreatedAt, loc),
+	}
+}
@@ -0,0 +1,88 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"rim/internal/auth/usecase"
+	"rim/pkg/apperror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// EmailVerificationResponse представляет подтвержденный email контакта
+type EmailVerificationResponse struct {
+	ContactID     uint   `json:"contact_id"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+// ResendEmailVerification повторно отправляет ссылку подтверждения email текущего пользователя
+// @Summary Отправить ссылку подтверждения email повторно
+// @Description Ссылка подтверждения отправляется автоматически, когда email меняется через PUT /auth/contact.
+// @Description Повторная отправка заменяет прежнюю ссылку; запрашивать ее можно не чаще раза в минуту.
+// @Tags auth
+// @Produce json
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} map[string]string "У контакта нет email"
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string "У пользователя нет контакта"
+// @Failure 409 {object} map[string]string "Email уже подтвержден"
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/email/verification [post]
+func (h *Handler) ResendEmailVerification(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	if err := h.authUseCase.ResendEmailVerification(c.Context(), userID); err != nil {
+		if errors.Is(err, usecase.ErrEmailVerificationTooSoon) {
+			return c.Status(http.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "Verification email was requested too recently",
+			})
+		}
+		return h.emailVerificationError(c, err)
+	}
+
+	return c.Status(http.StatusAccepted).JSON(fiber.Map{
+		"message": "Verification email has been sent",
+	})
+}
+
+// VerifyEmail подтверждает email по ссылке из письма
+// @Summary Подтвердить email
+// @Description Открывается по ссылке из письма и не требует входа. Ссылка действует сутки и подтверждает только адрес,
+// @Description на который была отправлена: после смены email она перестает действовать.
+// @Tags auth
+// @Produce json
+// @Param token query string true "Токен из ссылки"
+// @Success 200 {object} EmailVerificationResponse
+// @Failure 400 {object} map[string]string "Ссылка недействительна или истекла"
+// @Failure 500 {object} map[string]string
+// @Router /auth/email/verify [get]
+func (h *Handler) VerifyEmail(c *fiber.Ctx) error {
+	contact, err := h.authUseCase.VerifyEmail(c.Context(), c.Query("token"))
+	if err != nil {
+		return h.emailVerificationError(c, err)
+	}
+	return c.JSON(EmailVerificationResponse{ContactID: contact.ID, Email: contact.Email, EmailVerified: contact.EmailVerified})
+}
+
+// emailVerificationError преобразует ошибки usecase в HTTP-ответ по их виду (pkg/apperror)
+func (h *Handler) emailVerificationError(c *fiber.Ctx, err error) error {
+	status := apperror.HTTPStatus(err)
+	if status == http.StatusInternalServerError {
+		h.logger.ErrorContext(c.Context(), "Email verification failed", slog.Any("error", err))
+		return c.Status(status).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
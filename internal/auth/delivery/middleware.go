@@ -1,17 +1,87 @@
 package delivery
 
 import (
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 
 	"rim/internal/auth/usecase"
 	"rim/internal/domain"
+	orgDelivery "rim/internal/organization/delivery"
+	orgUseCase "rim/internal/organization/usecase"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// apiKeyHeader - заголовок с API-ключом сервисного аккаунта
+const apiKeyHeader = "X-API-Key"
+
+// sessionStoreRetryAfter - через сколько секунд клиенту стоит повторить запрос, если хранилище сессий недоступно
+const sessionStoreRetryAfter = "5"
+
+// OrganizationResolver выбирает организацию запроса для пользователя и сохраняет ее в Locals.
+type OrganizationResolver func(c *fiber.Ctx, user *domain.User) error
+
+// setUser сохраняет аутентифицированного пользователя в контексте и выбирает организацию запроса.
+// Если пользователь не принял действующую версию соглашения, возвращает *TermsRequiredError,
+// кроме маршрутов с AllowPendingTerms.
+func (h *Handler) setUser(c *fiber.Ctx, user *domain.User) error {
+	c.Locals("user", user)
+	c.Locals("user_id", user.ID)
+	c.Locals("isAuthenticated", true)
+	if err := h.resolveOrganization(c, user); err != nil {
+		return err
+	}
+	if allowed, _ := c.Locals(allowPendingTermsKey).(bool); !allowed {
+		if terms, pending := h.authUseCase.PendingTerms(c.Context(), user); pending {
+			return &TermsRequiredError{Terms: terms}
+		}
+	}
+	return nil
+}
+
+// setUserError отвечает на ошибку setUser: выбора организации запроса или непринятого соглашения.
+func (h *Handler) setUserError(c *fiber.Ctx, err error) error {
+	var termsErr *TermsRequiredError
+	if errors.As(err, &termsErr) {
+		return c.Status(http.StatusForbidden).JSON(TermsRequiredResponse{
+			Error: "Terms of service must be accepted",
+			Terms: toTermsResponse(termsErr.Terms),
+		})
+	}
+	switch {
+	case errors.Is(err, orgDelivery.ErrInvalidOrganizationHeader):
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, orgUseCase.ErrNotOrganizationMember):
+		return c.Status(http.StatusForbidden).JSON(fiber.Map{
+			"error": "Not a member of the requested organization",
+		})
+	}
+	h.logger.ErrorContext(c.Context(), "Failed to resolve request organization", slog.Any("error", err))
+	return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Internal server error",
+	})
+}
+
+// sessionStoreUnavailable отвечает 503, когда хранилище сессий недоступно. Сессия при этом может быть
+// действительной, поэтому cookie не удаляется, а клиенту предлагается повторить запрос.
+func (h *Handler) sessionStoreUnavailable(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderRetryAfter, sessionStoreRetryAfter)
+	return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{
+		"error": "Authentication is temporarily unavailable",
+	})
+}
+
 // AuthMiddleware проверяет авторизацию пользователя
 func (h *Handler) AuthMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if apiKey := c.Get(apiKeyHeader); apiKey != "" {
+			return h.serviceAccountAuth(c, apiKey)
+		}
+
 		sessionToken := h.extractSessionToken(c)
 		if sessionToken == "" {
 			// Если токена нет, сохраняем информацию о том, что пользователь не авторизован
@@ -22,6 +92,10 @@ func (h *Handler) AuthMiddleware() fiber.Handler {
 
 		user, err := h.authUseCase.GetUserBySession(c.Context(), sessionToken)
 		if err != nil {
+			// Хранилище недоступно: гостевой ответ выдал бы вошедшему пользователю чужое представление
+			if errors.Is(err, usecase.ErrSessionStoreUnavailable) {
+				return h.sessionStoreUnavailable(c)
+			}
 			// Если сессия недействительна, считаем пользователя неавторизованным
 			c.Locals("user", nil)
 			c.Locals("isAuthenticated", false)
@@ -29,9 +103,9 @@ func (h *Handler) AuthMiddleware() fiber.Handler {
 		}
 
 		// Сохраняем информацию о пользователе в контексте
-		c.Locals("user", user)
-		c.Locals("user_id", user.ID)
-		c.Locals("isAuthenticated", true)
+		if err := h.setUser(c, user); err != nil {
+			return h.setUserError(c, err)
+		}
 		return c.Next()
 	}
 }
@@ -39,6 +113,10 @@ func (h *Handler) AuthMiddleware() fiber.Handler {
 // RequireAuth middleware, который требует обязательной авторизации
 func (h *Handler) RequireAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if apiKey := c.Get(apiKeyHeader); apiKey != "" {
+			return h.serviceAccountAuth(c, apiKey)
+		}
+
 		sessionToken := h.extractSessionToken(c)
 		if sessionToken == "" {
 			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
@@ -48,6 +126,9 @@ func (h *Handler) RequireAuth() fiber.Handler {
 
 		user, err := h.authUseCase.GetUserBySession(c.Context(), sessionToken)
 		if err != nil {
+			if errors.Is(err, usecase.ErrSessionStoreUnavailable) {
+				return h.sessionStoreUnavailable(c)
+			}
 			switch err {
 			case usecase.ErrSessionNotFound, usecase.ErrSessionExpired:
 				return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
@@ -65,13 +146,166 @@ func (h *Handler) RequireAuth() fiber.Handler {
 		}
 
 		// Сохраняем информацию о пользователе в контексте
-		c.Locals("user", user)
-		c.Locals("user_id", user.ID)
-		c.Locals("isAuthenticated", true)
+		if err := h.setUser(c, user); err != nil {
+			return h.setUserError(c, err)
+		}
+		return c.Next()
+	}
+}
+
+// RequireAdmin middleware, который пропускает только администраторов.
+// При включенном отладочном режиме (DEBUG_MODE из окружения или настройка debug_mode в БД)
+// доступ получает любой авторизованный пользователь.
+// Должен использоваться после RequireAuth/RequireAuthCookie, которые устанавливают user_id.
+func (h *Handler) RequireAdmin() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.isDebugModeEnabled(c.Context()) {
+			return c.Next()
+		}
+
+		userID, ok := c.Locals("user_id").(uint)
+		if !ok {
+			h.logger.WarnContext(c.Context(), "User ID not found in context")
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized",
+			})
+		}
+
+		isAdmin, err := h.isAdmin(c, userID)
+		if err != nil {
+			h.logger.ErrorContext(c.Context(), "Failed to check admin status", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Internal server error",
+			})
+		}
+
+		if !isAdmin {
+			h.logger.WarnContext(c.Context(), "User is not admin and debug mode is off", slog.Uint64("user_id", uint64(userID)))
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"error": "Admin rights required",
+			})
+		}
+
+		h.logger.InfoContext(c.Context(), "User has admin rights", slog.Uint64("user_id", uint64(userID)))
+		return c.Next()
+	}
+}
+
+// serviceAccountAuth аутентифицирует запрос сервисного аккаунта по API-ключу.
+// Неверный ключ отклоняется даже на маршрутах, доступных без авторизации.
+func (h *Handler) serviceAccountAuth(c *fiber.Ctx, apiKey string) error {
+	// Ключ уже проверен предыдущим middleware в цепочке
+	if user, ok := c.Locals("user").(*domain.User); ok && user.IsServiceAccount() {
+		return c.Next()
+	}
+
+	user, err := h.authUseCase.AuthenticateAPIKey(c.Context(), apiKey)
+	if err != nil {
+		if err == usecase.ErrInvalidAPIKey {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid API key",
+			})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to authenticate API key", slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	if err := h.setUser(c, user); err != nil {
+		return h.setUserError(c, err)
+	}
+	return c.Next()
+}
+
+// RequireSuperAdmin middleware, который пропускает только суперадминистраторов (ADMIN_TELEGRAM_IDS).
+// Отладочный режим на него не влияет.
+// Должен использоваться после RequireAuth/RequireAuthCookie, которые устанавливают user_id.
+func (h *Handler) RequireSuperAdmin() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := c.Locals("user_id").(uint)
+		if !ok {
+			h.logger.WarnContext(c.Context(), "User ID not found in context")
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized",
+			})
+		}
+
+		isSuperAdmin, err := h.authUseCase.IsUserSuperAdmin(c.Context(), userID)
+		if err != nil {
+			h.logger.ErrorContext(c.Context(), "Failed to check superadmin status", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Internal server error",
+			})
+		}
+
+		if !isSuperAdmin {
+			h.logger.WarnContext(c.Context(), "User is not superadmin", slog.Uint64("user_id", uint64(userID)))
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"error": "Superadmin rights required",
+			})
+		}
+
 		return c.Next()
 	}
 }
 
+// ResolveRole определяет роль пользователя для политики доступа:
+// guest - без авторизации, admin - администратор организации запроса или отладочный режим, user - остальные.
+// Для сервисных аккаунтов возвращается назначенная им роль.
+func (h *Handler) ResolveRole(c *fiber.Ctx) (string, error) {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return domain.RoleGuest, nil
+	}
+
+	// Роль сервисного аккаунта задана явно и не зависит от отладочного режима
+	if user, ok := c.Locals("user").(*domain.User); ok && user.IsServiceAccount() {
+		return user.Role, nil
+	}
+
+	if h.isDebugModeEnabled(c.Context()) {
+		return domain.RoleAdmin, nil
+	}
+
+	isAdmin, err := h.isAdmin(c, userID)
+	if err != nil {
+		return "", err
+	}
+	if isAdmin {
+		return domain.RoleAdmin, nil
+	}
+	return domain.RoleUser, nil
+}
+
+// isAdmin проверяет права администратора в организации запроса:
+// роль admin в организации или группа "Администраторы" ее контактов.
+func (h *Handler) isAdmin(c *fiber.Ctx, userID uint) (bool, error) {
+	if orgDelivery.CurrentRole(c) == domain.OrgRoleAdmin {
+		return true, nil
+	}
+	return h.authUseCase.IsUserAdmin(c.Context(), userID)
+}
+
+// isDebugModeEnabled проверяет принудительный отладочный режим из переменной окружения,
+// а затем отладочный режим из системных настроек.
+func (h *Handler) isDebugModeEnabled(ctx context.Context) bool {
+	if h.forceDebugMode {
+		h.logger.InfoContext(ctx, "Force debug mode is enabled via environment variable, granting admin rights")
+		return true
+	}
+
+	debugMode, err := h.systemUseCase.GetDebugMode(ctx)
+	if err != nil {
+		h.logger.WarnContext(ctx, "Failed to get debug mode status", slog.Any("error", err))
+		return false
+	}
+	if debugMode {
+		h.logger.InfoContext(ctx, "Debug mode is enabled, granting admin rights")
+	}
+	return debugMode
+}
+
 // GetUserFromContext получает пользователя из контекста Fiber
 func GetUserFromContext(c *fiber.Ctx) (*domain.User, bool) {
 	user := c.Locals("user")
@@ -0,0 +1,126 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rim/internal/auth/usecase"
+	systemUseCase "rim/internal/system/usecase"
+	"rim/pkg/securityheaders"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeAuthUseCase отвечает на проверку прав администратора; остальные методы не используются
+type fakeAuthUseCase struct {
+	usecase.UseCase
+	admins map[uint]bool
+	err    error
+}
+
+func (f *fakeAuthUseCase) IsUserAdmin(_ context.Context, userID uint) (bool, error) {
+	return f.admins[userID], f.err
+}
+
+// fakeSystemUseCase возвращает отладочный режим из настроек БД; остальные методы не используются
+type fakeSystemUseCase struct {
+	systemUseCase.UseCase
+	debugMode bool
+	err       error
+}
+
+func (f *fakeSystemUseCase) GetDebugMode(context.Context) (bool, error) {
+	return f.debugMode, f.err
+}
+
+const (
+	testAdminID = uint(1)
+	testUserID  = uint(2)
+)
+
+// newRequireAdminApp собирает приложение, в котором запрос от userID проходит через RequireAdmin
+func newRequireAdminApp(authUC usecase.UseCase, systemUC systemUseCase.UseCase, forceDebugMode bool, userID uint) *fiber.App {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := NewHandler(authUC, systemUC, nil, nil, func() string { return "" }, forceDebugMode, securityheaders.CookiePolicy{}, logger)
+
+	app := fiber.New()
+	app.Get("/admin",
+		func(c *fiber.Ctx) error {
+			if userID != 0 {
+				c.Locals("user_id", userID)
+			}
+			return c.Next()
+		},
+		h.RequireAdmin(),
+		func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) },
+	)
+	return app
+}
+
+func TestRequireAdmin(t *testing.T) {
+	tests := []struct {
+		name           string
+		forceDebugMode bool
+		dbDebugMode    bool
+		dbDebugErr     error
+		userID         uint
+		wantStatus     int
+	}{
+		{name: "admin, debug off", userID: testAdminID, wantStatus: http.StatusOK},
+		{name: "user, debug off", userID: testUserID, wantStatus: http.StatusForbidden},
+		{name: "admin, debug in DB", dbDebugMode: true, userID: testAdminID, wantStatus: http.StatusOK},
+		{name: "user, debug in DB", dbDebugMode: true, userID: testUserID, wantStatus: http.StatusOK},
+		{name: "admin, forced debug", forceDebugMode: true, userID: testAdminID, wantStatus: http.StatusOK},
+		{name: "user, forced debug", forceDebugMode: true, userID: testUserID, wantStatus: http.StatusOK},
+		{name: "admin, forced and DB debug", forceDebugMode: true, dbDebugMode: true, userID: testAdminID, wantStatus: http.StatusOK},
+		{name: "user, forced and DB debug", forceDebugMode: true, dbDebugMode: true, userID: testUserID, wantStatus: http.StatusOK},
+		// Ошибка чтения настройки не включает отладочный режим
+		{name: "user, DB debug unavailable", dbDebugErr: errors.New("db is down"), userID: testUserID, wantStatus: http.StatusForbidden},
+		{name: "admin, DB debug unavailable", dbDebugErr: errors.New("db is down"), userID: testAdminID, wantStatus: http.StatusOK},
+		{name: "no user, debug off", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authUC := &fakeAuthUseCase{admins: map[uint]bool{testAdminID: true}}
+			systemUC := &fakeSystemUseCase{debugMode: tt.dbDebugMode, err: tt.dbDebugErr}
+			app := newRequireAdminApp(authUC, systemUC, tt.forceDebugMode, tt.userID)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/admin", nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusForbidden {
+				body, _ := io.ReadAll(resp.Body)
+				if want := `{"error":"Admin rights required"}`; string(body) != want {
+					t.Errorf("body = %s, want %s", body, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRequireAdminAdminCheckError(t *testing.T) {
+	authUC := &fakeAuthUseCase{err: errors.New("db is down")}
+	app := newRequireAdminApp(authUC, &fakeSystemUseCase{}, false, testUserID)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/admin", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusInternalServerError)
+	}
+}
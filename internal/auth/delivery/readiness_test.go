@@ -0,0 +1,131 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authRepo "rim/internal/auth/repository"
+	"rim/internal/auth/usecase"
+	"rim/internal/domain"
+	"rim/pkg/health"
+	"rim/pkg/securityheaders"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const testSessionToken = "v1:test-session"
+
+// memorySessionStore хранит одну сессию; остальные методы не используются
+type memorySessionStore struct {
+	authRepo.SessionStore
+	session domain.UserSession
+}
+
+func (s *memorySessionStore) GetSession(_ context.Context, sessionToken string) (*domain.UserSession, error) {
+	if sessionToken != s.session.SessionToken {
+		return nil, authRepo.ErrSessionNotFound
+	}
+	session := s.session
+	return &session, nil
+}
+
+// sessionAuthUseCase находит пользователя по сессии из хранилища, как authUseCase
+type sessionAuthUseCase struct {
+	usecase.UseCase
+	sessions authRepo.SessionStore
+}
+
+func (f *sessionAuthUseCase) GetUserBySession(ctx context.Context, sessionToken string) (*domain.User, error) {
+	session, err := f.sessions.GetSession(ctx, sessionToken)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.User{ID: session.UserID, IsActive: true}, nil
+}
+
+func (f *sessionAuthUseCase) PendingTerms(context.Context, *domain.User) (domain.TermsOfService, bool) {
+	return domain.TermsOfService{}, false
+}
+
+// newReadinessApp собирает приложение с хранилищем сессий, готовность которого определяет monitor по проверке Redis
+func newReadinessApp(monitor *health.Monitor) *fiber.App {
+	store := &memorySessionStore{session: domain.UserSession{UserID: testUserID, SessionToken: testSessionToken}}
+	sessions := authRepo.NewReadySessionStore(store, func() bool { return monitor.Available(health.DependencyRedis) })
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	resolveOrganization := func(*fiber.Ctx, *domain.User) error { return nil }
+	h := NewHandler(&sessionAuthUseCase{sessions: sessions}, &fakeSystemUseCase{}, resolveOrganization, nil, func() string { return "" }, false, securityheaders.CookiePolicy{SessionName: "session_token"}, logger)
+
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) }
+	app := fiber.New()
+	// Список контактов доступен и гостям, личные маршруты - только с сессией
+	app.Get("/contacts", h.CookieAuthMiddleware(), ok)
+	app.Get("/me", h.RequireAuthCookie(), ok)
+	app.Get("/legacy/me", h.RequireAuth(), ok)
+	return app
+}
+
+func TestSessionRoutesFollowRedisHealth(t *testing.T) {
+	errRedisDown := errors.New("connection refused")
+	var redisErr error
+	monitor := health.NewMonitor([]health.Check{{
+		Name:  health.DependencyRedis,
+		Probe: func(context.Context) error { return redisErr },
+	}}, health.NewMemoryStore(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	app := newReadinessApp(monitor)
+
+	steps := []struct {
+		name      string
+		redisDown bool
+		path      string
+		session   bool
+		want      int
+	}{
+		{name: "guest list, redis up", path: "/contacts", want: http.StatusOK},
+		{name: "session list, redis up", path: "/contacts", session: true, want: http.StatusOK},
+		{name: "session route, redis up", path: "/me", session: true, want: http.StatusOK},
+		{name: "guest list, redis down", redisDown: true, path: "/contacts", want: http.StatusOK},
+		{name: "session list, redis down", redisDown: true, path: "/contacts", session: true, want: http.StatusServiceUnavailable},
+		{name: "session route, redis down", redisDown: true, path: "/me", session: true, want: http.StatusServiceUnavailable},
+		{name: "bearer route, redis down", redisDown: true, path: "/legacy/me", session: true, want: http.StatusServiceUnavailable},
+		{name: "session route without session, redis down", redisDown: true, path: "/me", want: http.StatusUnauthorized},
+		{name: "session route, redis recovered", path: "/me", session: true, want: http.StatusOK},
+		{name: "bearer route, redis recovered", path: "/legacy/me", session: true, want: http.StatusOK},
+	}
+
+	for _, step := range steps {
+		redisErr = nil
+		if step.redisDown {
+			redisErr = errRedisDown
+		}
+		monitor.CheckAll(context.Background())
+
+		req := httptest.NewRequest(http.MethodGet, step.path, nil)
+		if step.session {
+			req.Header.Set("Authorization", "Bearer "+testSessionToken)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", step.name, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != step.want {
+			t.Errorf("%s: status = %d, want %d (%s)", step.name, resp.StatusCode, step.want, body)
+			continue
+		}
+		if step.want == http.StatusServiceUnavailable {
+			if want := `{"error":"Authentication is temporarily unavailable"}`; string(body) != want {
+				t.Errorf("%s: body = %s, want %s", step.name, body, want)
+			}
+			if resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+				t.Errorf("%s: Retry-After header is missing", step.name)
+			}
+		}
+	}
+}
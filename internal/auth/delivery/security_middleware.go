@@ -3,38 +3,16 @@ package delivery
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
-	"time"
+
+	"rim/internal/auth/usecase"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// SecurityMiddleware добавляет заголовки безопасности для защиты от XSS
-func SecurityMiddleware() fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		// Защита от XSS
-		c.Set("X-Content-Type-Options", "nosniff")
-		c.Set("X-Frame-Options", "DENY")
-		c.Set("X-XSS-Protection", "1; mode=block")
-		c.Set("Referrer-Policy", "strict-origin-when-cross-origin")
-		c.Set("Permissions-Policy", "geolocation=(), microphone=(), camera=()")
-
-		// Content Security Policy для защиты от XSS
-		csp := "default-src 'self'; " +
-			"script-src 'self' 'unsafe-inline' 'unsafe-eval' https://telegram.org; " +
-			"style-src 'self' 'unsafe-inline'; " +
-			"img-src 'self' data: https:; " +
-			"connect-src 'self' http://localhost:3000; " +
-			"font-src 'self'; " +
-			"object-src 'none'; " +
-			"base-uri 'self'"
-		c.Set("Content-Security-Policy", csp)
-
-		return c.Next()
-	}
-}
-
 // CSRFMiddleware генерирует и проверяет CSRF токены
 func (h *Handler) CSRFMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -116,8 +94,13 @@ func (h *Handler) validateCSRFToken(c *fiber.Ctx, sessionToken, csrfToken string
 // CookieAuthMiddleware работает с cookies вместо localStorage
 func (h *Handler) CookieAuthMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if apiKey := c.Get(apiKeyHeader); apiKey != "" {
+			return h.serviceAccountAuth(c, apiKey)
+		}
+
 		// Сначала пробуем получить токен из cookie
-		sessionToken := c.Cookies("session_token")
+		sessionToken := c.Cookies(h.cookies.SessionName)
+		fromCookie := sessionToken != ""
 
 		// Если нет в cookie, пробуем заголовок (для обратной совместимости)
 		if sessionToken == "" {
@@ -132,23 +115,23 @@ func (h *Handler) CookieAuthMiddleware() fiber.Handler {
 
 		user, err := h.authUseCase.GetUserBySession(c.Context(), sessionToken)
 		if err != nil {
+			if errors.Is(err, usecase.ErrSessionStoreUnavailable) {
+				return h.sessionStoreUnavailable(c)
+			}
 			// Удаляем невалидный cookie
-			c.Cookie(&fiber.Cookie{
-				Name:     "session_token",
-				Value:    "",
-				Expires:  time.Now().Add(-time.Hour),
-				HTTPOnly: true,
-				Secure:   true,
-				SameSite: "Strict",
-			})
+			c.Cookie(h.cookies.Expired(h.cookies.SessionName))
 			c.Locals("user", nil)
 			c.Locals("isAuthenticated", false)
 			return c.Next()
 		}
 
-		c.Locals("user", user)
-		c.Locals("user_id", user.ID)
-		c.Locals("isAuthenticated", true)
+		if fromCookie {
+			h.migrateSessionCookie(c, sessionToken)
+		}
+
+		if err := h.setUser(c, user); err != nil {
+			return h.setUserError(c, err)
+		}
 		return c.Next()
 	}
 }
@@ -156,7 +139,13 @@ func (h *Handler) CookieAuthMiddleware() fiber.Handler {
 // RequireAuthCookie требует авторизации через cookie
 func (h *Handler) RequireAuthCookie() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		sessionToken := c.Cookies("session_token")
+		// Сервисные аккаунты аутентифицируются API-ключом
+		if apiKey := c.Get(apiKeyHeader); apiKey != "" {
+			return h.serviceAccountAuth(c, apiKey)
+		}
+
+		sessionToken := c.Cookies(h.cookies.SessionName)
+		fromCookie := sessionToken != ""
 
 		// Поддержка заголовка для API клиентов
 		if sessionToken == "" {
@@ -171,23 +160,42 @@ func (h *Handler) RequireAuthCookie() fiber.Handler {
 
 		user, err := h.authUseCase.GetUserBySession(c.Context(), sessionToken)
 		if err != nil {
+			if errors.Is(err, usecase.ErrSessionStoreUnavailable) {
+				return h.sessionStoreUnavailable(c)
+			}
 			// Удаляем невалидный cookie
-			c.Cookie(&fiber.Cookie{
-				Name:     "session_token",
-				Value:    "",
-				Expires:  time.Now().Add(-time.Hour),
-				HTTPOnly: true,
-				Secure:   true,
-				SameSite: "Strict",
-			})
+			c.Cookie(h.cookies.Expired(h.cookies.SessionName))
 			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Invalid or expired session",
 			})
 		}
 
-		c.Locals("user", user)
-		c.Locals("user_id", user.ID)
-		c.Locals("isAuthenticated", true)
+		if fromCookie {
+			h.migrateSessionCookie(c, sessionToken)
+		}
+
+		if err := h.setUser(c, user); err != nil {
+			return h.setUserError(c, err)
+		}
 		return c.Next()
 	}
 }
+
+// migrateSessionCookie заменяет cookie с токеном прежней версии на токен текущей версии.
+// Токены из заголовка Authorization не заменяются: клиент API не узнает новый токен и действует
+// со старым до истечения сессии. Ошибка замены не прерывает запрос - попытка повторится при следующем.
+func (h *Handler) migrateSessionCookie(c *fiber.Ctx, sessionToken string) {
+	// Маршрут может проходить через несколько middleware авторизации; токен заменяется один раз
+	if migrated, _ := c.Locals("sessionMigrated").(bool); migrated {
+		return
+	}
+	c.Locals("sessionMigrated", true)
+	session, err := h.authUseCase.MigrateSessionToken(c.Context(), sessionToken)
+	if err != nil {
+		h.logger.WarnContext(c.Context(), "Failed to migrate session token", slog.Any("error", err))
+		return
+	}
+	if session != nil {
+		h.setSessionCookie(c, session)
+	}
+}
@@ -0,0 +1,188 @@
+package delivery
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rim/internal/auth/usecase"
+	"rim/internal/domain"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ServiceAccountRequest представляет запрос на создание сервисного аккаунта
+type ServiceAccountRequest struct {
+	Name string `json:"name" validate:"required"`
+	Role string `json:"role" validate:"required"` // Роль политики доступа, например user или admin
+}
+
+// ServiceAccountStatusRequest представляет запрос на включение или отключение сервисного аккаунта
+type ServiceAccountStatusRequest struct {
+	IsActive bool `json:"is_active"`
+}
+
+// ServiceAccountResponse представляет сервисный аккаунт
+type ServiceAccountResponse struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	IsActive  bool   `json:"is_active"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ServiceAccountKeyResponse содержит API-ключ. Он возвращается только при создании и ротации.
+type ServiceAccountKeyResponse struct {
+	ServiceAccountResponse
+	APIKey string `json:"api_key"`
+}
+
+// CreateServiceAccount создает сервисный аккаунт
+// @Summary Создать сервисный аккаунт
+// @Description Создает аккаунт для бота или интеграции. Запросы выполняются с заголовком X-API-Key.
+// @Tags service-accounts
+// @Accept json
+// @Produce json
+// @Param account body ServiceAccountRequest true "Название и роль"
+// @Success 201 {object} ServiceAccountKeyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /service-accounts [post]
+func (h *Handler) CreateServiceAccount(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[ServiceAccountRequest](c)
+	if !ok {
+		return err
+	}
+
+	user, apiKey, err := h.authUseCase.CreateServiceAccount(c.Context(), req.Name, req.Role)
+	if err != nil {
+		switch err {
+		case usecase.ErrServiceAccountName, usecase.ErrServiceAccountRole:
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		default:
+			h.logger.ErrorContext(c.Context(), "Failed to create service account", slog.Any("error", err))
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Internal server error",
+			})
+		}
+	}
+
+	return c.Status(http.StatusCreated).JSON(ServiceAccountKeyResponse{
+		ServiceAccountResponse: toServiceAccountResponse(user),
+		APIKey:                 apiKey,
+	})
+}
+
+// GetServiceAccounts возвращает сервисные аккаунты
+// @Summary Получить сервисные аккаунты
+// @Description Возвращает все сервисные аккаунты без API-ключей
+// @Tags service-accounts
+// @Produce json
+// @Success 200 {array} ServiceAccountResponse
+// @Failure 500 {object} map[string]string
+// @Router /service-accounts [get]
+func (h *Handler) GetServiceAccounts(c *fiber.Ctx) error {
+	users, err := h.authUseCase.GetServiceAccounts(c.Context())
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	resp := make([]ServiceAccountResponse, len(users))
+	for i := range users {
+		resp[i] = toServiceAccountResponse(&users[i])
+	}
+	return c.JSON(resp)
+}
+
+// RotateServiceAccountKey выдает новый API-ключ
+// @Summary Сменить API-ключ сервисного аккаунта
+// @Description Выдает новый API-ключ; прежний сразу перестает действовать
+// @Tags service-accounts
+// @Produce json
+// @Param id path int true "ID сервисного аккаунта"
+// @Success 200 {object} ServiceAccountKeyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /service-accounts/{id}/rotate-key [post]
+func (h *Handler) RotateServiceAccountKey(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid service account ID format",
+		})
+	}
+
+	user, apiKey, err := h.authUseCase.RotateServiceAccountKey(c.Context(), uint(id))
+	if err != nil {
+		return h.serviceAccountError(c, err)
+	}
+
+	return c.JSON(ServiceAccountKeyResponse{
+		ServiceAccountResponse: toServiceAccountResponse(user),
+		APIKey:                 apiKey,
+	})
+}
+
+// SetServiceAccountStatus включает или отключает сервисный аккаунт
+// @Summary Включить или отключить сервисный аккаунт
+// @Description Отключенный аккаунт не проходит аутентификацию по API-ключу
+// @Tags service-accounts
+// @Accept json
+// @Produce json
+// @Param id path int true "ID сервисного аккаунта"
+// @Param status body ServiceAccountStatusRequest true "Новое состояние"
+// @Success 200 {object} ServiceAccountResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /service-accounts/{id}/status [put]
+func (h *Handler) SetServiceAccountStatus(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid service account ID format",
+		})
+	}
+
+	req, ok, err := validation.BindAndValidate[ServiceAccountStatusRequest](c)
+	if !ok {
+		return err
+	}
+
+	user, err := h.authUseCase.SetServiceAccountActive(c.Context(), uint(id), req.IsActive)
+	if err != nil {
+		return h.serviceAccountError(c, err)
+	}
+	return c.JSON(toServiceAccountResponse(user))
+}
+
+// serviceAccountError преобразует ошибки usecase сервисных аккаунтов в HTTP ответы
+func (h *Handler) serviceAccountError(c *fiber.Ctx, err error) error {
+	if err == usecase.ErrServiceAccountNotFound {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "Service account not found",
+		})
+	}
+	h.logger.ErrorContext(c.Context(), "Failed to update service account", slog.Any("error", err))
+	return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Internal server error",
+	})
+}
+
+func toServiceAccountResponse(user *domain.User) ServiceAccountResponse {
+	return ServiceAccountResponse{
+		ID:        user.ID,
+		Name:      user.Name,
+		Role:      user.Role,
+		IsActive:  user.IsActive,
+		CreatedAt: timeutil.Format(user.CreatedAt, time.UTC),
+	}
+}
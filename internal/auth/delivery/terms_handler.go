@@ -0,0 +1,97 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"rim/internal/auth/usecase"
+	"rim/internal/domain"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// allowPendingTermsKey - ключ Locals, который пропускает пользователя, не принявшего соглашение
+const allowPendingTermsKey = "allowPendingTerms"
+
+// TermsRequiredError - пользователь не принял действующую версию соглашения
+type TermsRequiredError struct {
+	Terms domain.TermsOfService
+}
+
+func (e *TermsRequiredError) Error() string {
+	return "terms of service version " + e.Terms.Version + " must be accepted"
+}
+
+// TermsResponse описывает версию пользовательского соглашения
+type TermsResponse struct {
+	Version string `json:"version"`
+	URL     string `json:"url,omitempty"`
+}
+
+// TermsRequiredResponse - ответ 403, пока пользователь не принял действующую версию соглашения
+type TermsRequiredResponse struct {
+	Error string        `json:"error"`
+	Terms TermsResponse `json:"terms"`
+}
+
+// AcceptTermsRequest - версия соглашения, которую принимает пользователь
+type AcceptTermsRequest struct {
+	Version string `json:"version" validate:"required"`
+}
+
+func toTermsResponse(terms domain.TermsOfService) TermsResponse {
+	return TermsResponse{Version: terms.Version, URL: terms.URL}
+}
+
+// AllowPendingTerms пропускает через middleware авторизации пользователя, который еще не принял
+// действующую версию соглашения. Ставится перед RequireAuthCookie на маршруте принятия соглашения.
+func (h *Handler) AllowPendingTerms() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(allowPendingTermsKey, true)
+		return c.Next()
+	}
+}
+
+// AcceptTerms записывает принятие текущим пользователем действующей версии соглашения
+// @Summary Принять пользовательское соглашение
+// @Description Записывает принятие действующей версии соглашения в журнал. Пока версия, заданная в настройке terms_of_service, не принята, остальные маршруты с авторизацией отвечают 403 с описанием соглашения.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param terms body AcceptTermsRequest true "Принимаемая версия"
+// @Success 200 {object} TermsResponse
+// @Failure 400 {object} validation.Response
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} TermsRequiredResponse "Версия не совпадает с действующей"
+// @Failure 500 {object} map[string]string
+// @Router /auth/accept-terms [post]
+func (h *Handler) AcceptTerms(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	req, ok, err := validation.BindAndValidate[AcceptTermsRequest](c)
+	if !ok {
+		return err
+	}
+
+	if err := h.authUseCase.AcceptTerms(c.Context(), userID, req.Version, c.IP(), c.Get(fiber.HeaderUserAgent)); err != nil {
+		if errors.Is(err, usecase.ErrTermsVersionMismatch) {
+			return c.Status(http.StatusConflict).JSON(TermsRequiredResponse{
+				Error: err.Error(),
+				Terms: toTermsResponse(h.systemUseCase.CurrentTermsOfService(c.Context())),
+			})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to accept terms of service", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.JSON(TermsResponse{Version: req.Version, URL: h.systemUseCase.CurrentTermsOfService(c.Context()).URL})
+}
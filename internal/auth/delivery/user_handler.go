@@ -0,0 +1,86 @@
+package delivery
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rim/internal/auth/usecase"
+	"rim/internal/domain"
+	"rim/pkg/timeutil"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminUserResponse представляет пользователя в списке для администраторов
+type AdminUserResponse struct {
+	ID          uint   `json:"id"`
+	TelegramID  int64  `json:"telegram_id,omitempty"`
+	ContactID   *uint  `json:"contact_id,omitempty"`
+	Name        string `json:"name,omitempty"` // Имя из связанного контакта
+	IsActive    bool   `json:"is_active"`
+	LastLoginAt string `json:"last_login_at,omitempty"` // Пусто, если пользователь не входил
+	CreatedAt   string `json:"created_at"`
+	// AcceptedTermsVersion - последняя принятая версия пользовательского соглашения
+	AcceptedTermsVersion string `json:"accepted_terms_version,omitempty"`
+}
+
+// GetUsers возвращает пользователей системы
+// @Summary Получить пользователей
+// @Description Возвращает пользователей (без сервисных аккаунтов), начиная с давно не входивших. С inactive_days - только не входившие указанное число дней, например для периодической чистки.
+// @Tags users
+// @Produce json
+// @Param inactive_days query int false "Не входили столько дней (включая ни разу не входивших)"
+// @Success 200 {array} AdminUserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users [get]
+func (h *Handler) GetUsers(c *fiber.Ctx) error {
+	inactiveDays := 0
+	if raw := c.Query("inactive_days"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid inactive_days",
+			})
+		}
+		inactiveDays = days
+	}
+
+	users, err := h.authUseCase.GetUsers(c.Context(), inactiveDays)
+	if err != nil {
+		if err == usecase.ErrInvalidInactiveDays {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to get users", slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	loc := time.UTC
+	if viewer, ok := c.Locals("user").(*domain.User); ok && viewer != nil {
+		loc = timeutil.LocationOrUTC(viewer.Timezone)
+	}
+	resp := make([]AdminUserResponse, len(users))
+	for i, user := range users {
+		resp[i] = AdminUserResponse{
+			ID:                   user.ID,
+			TelegramID:           user.TelegramID,
+			ContactID:            user.ContactID,
+			IsActive:             user.IsActive,
+			CreatedAt:            timeutil.Format(user.CreatedAt, loc),
+			AcceptedTermsVersion: user.AcceptedTermsVersion,
+		}
+		if user.Contact != nil {
+			resp[i].Name = user.Contact.Name
+		}
+		if user.LastLoginAt != nil {
+			resp[i].LastLoginAt = timeutil.Format(*user.LastLoginAt, loc)
+		}
+	}
+	return c.JSON(resp)
+}
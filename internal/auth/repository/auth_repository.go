@@ -2,16 +2,15 @@ package repository
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"log/slog"
 	"time"
 
 	"rim/internal/domain"
 	"rim/pkg/repository"
+	"rim/pkg/tenant"
 
-	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository определяет интерфейс для auth репозитория
@@ -20,25 +19,65 @@ type Repository interface {
 	CreateUser(ctx context.Context, user *domain.User) (*domain.User, error)
 	GetUserByID(ctx context.Context, id uint) (*domain.User, error)
 	GetUserByTelegramID(ctx context.Context, telegramID int64) (*domain.User, error)
+	GetUserByContactID(ctx context.Context, contactID uint) (*domain.User, error)
 	UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error)
+	// UpdateLastLogin записывает время входа, не меняя UpdatedAt пользователя
+	UpdateLastLogin(ctx context.Context, userID uint, at time.Time) error
+	// GetUsers возвращает пользователей-людей с контактами; если inactiveSince задан -
+	// только тех, кто не входил с этого момента или не входил ни разу
+	GetUsers(ctx context.Context, inactiveSince *time.Time) ([]domain.User, error)
+	// IsInGroupNamed проверяет одним запросом, состоит ли контакт пользователя в неудаленной группе groupName.
+	// Контакт ищется по contact_id, а если он не задан - по Telegram ID; orgID != 0 требует контакт этой организации.
+	IsInGroupNamed(ctx context.Context, user *domain.User, groupName string, orgID uint) (bool, error)
 
-	// Операции с сессиями в Redis
-	CreateSession(ctx context.Context, session *domain.UserSession) error
-	GetSession(ctx context.Context, sessionToken string) (*domain.UserSession, error)
-	DeleteSession(ctx context.Context, sessionToken string) error
-	DeleteAllUserSessions(ctx context.Context, userID uint) error
+	// Сервисные аккаунты
+	GetUserByAPIKeyHash(ctx context.Context, apiKeyHash string) (*domain.User, error)
+	GetServiceAccounts(ctx context.Context) ([]domain.User, error)
+
+	// Одноразовые коды входа по телефону
+	// IssueLoginCode сохраняет код входа, заменяя предыдущий код телефона, только если тот создан не позже issuedBefore.
+	// Возвращает false, если у телефона есть более свежий код.
+	IssueLoginCode(ctx context.Context, code *domain.LoginCode, issuedBefore time.Time) (bool, error)
+	GetLoginCode(ctx context.Context, phone string) (*domain.LoginCode, error)
+	// ClaimLoginCodeAttempt засчитывает попытку ввода кода, если их меньше maxAttempts.
+	// Возвращает false, если попытки исчерпаны или кода нет.
+	ClaimLoginCodeAttempt(ctx context.Context, phone string, maxAttempts int) (bool, error)
+	DeleteLoginCode(ctx context.Context, phone string) error
+
+	// Ссылки подтверждения email контактов
+	SaveEmailVerification(ctx context.Context, verification *domain.EmailVerification) error
+	GetEmailVerification(ctx context.Context, contactID uint) (*domain.EmailVerification, error)
+	GetEmailVerificationByTokenHash(ctx context.Context, tokenHash string) (*domain.EmailVerification, error)
+	DeleteEmailVerification(ctx context.Context, contactID uint) error
+
+	// Устройства пользователей
+	CreateDevice(ctx context.Context, device *domain.UserDevice) error
+	UpdateDevice(ctx context.Context, device *domain.UserDevice) error
+	GetDeviceByFingerprint(ctx context.Context, userID uint, fingerprintHash string) (*domain.UserDevice, error)
+	GetUserDevice(ctx context.Context, userID, deviceID uint) (*domain.UserDevice, error)
+	GetUserDevices(ctx context.Context, userID uint) ([]domain.UserDevice, error)
+	CountTrustedDevices(ctx context.Context, userID uint) (int64, error)
+	TouchDevice(ctx context.Context, deviceID uint, seenAt time.Time) error
+	DeleteDevice(ctx context.Context, deviceID uint) error
+
+	// AcceptTerms записывает принятие пользовательского соглашения в журнал и обновляет принятую версию пользователя
+	AcceptTerms(ctx context.Context, acceptance *domain.TermsAcceptance) error
+
+	// Операции с сессиями (Redis или SQLite, см. SessionStore)
+	SessionStore
 }
 
 type authRepository struct {
 	*repository.BaseRepository[domain.User]
-	redisClient *redis.Client
+	SessionStore
 }
 
-// NewAuthRepository создает новый экземпляр auth репозитория
-func NewAuthRepository(db *gorm.DB, redisClient *redis.Client, logger *slog.Logger) Repository {
+// NewAuthRepository создает новый экземпляр auth репозитория.
+// Пользователи хранятся в SQLite, сессии - в переданном хранилище.
+func NewAuthRepository(db *gorm.DB, sessionStore SessionStore, logger *slog.Logger) Repository {
 	return &authRepository{
 		BaseRepository: repository.NewBaseRepository[domain.User](db, logger),
-		redisClient:    redisClient,
+		SessionStore:   sessionStore,
 	}
 }
 
@@ -67,99 +106,168 @@ func (r *authRepository) GetUserByTelegramID(ctx context.Context, telegramID int
 	return &user, nil
 }
 
+// GetUserByContactID получает активного пользователя, связанного с контактом
+func (r *authRepository) GetUserByContactID(ctx context.Context, contactID uint) (*domain.User, error) {
+	var user domain.User
+	err := r.DB().WithContext(ctx).Preload("Contact").Where("contact_id = ? AND is_active = ?", contactID, true).First(&user).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.Logger().WarnContext(ctx, "User not found by contact ID", slog.Uint64("contact_id", uint64(contactID)))
+		} else {
+			r.Logger().ErrorContext(ctx, "Failed to get user by contact ID", slog.Uint64("contact_id", uint64(contactID)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// IsInGroupNamed проверяет членство контакта пользователя в группе через EXISTS с соединением
+// contacts, contact_groups и groups, не загружая контакт и его ассоциации
+func (r *authRepository) IsInGroupNamed(ctx context.Context, user *domain.User, groupName string, orgID uint) (bool, error) {
+	membership := r.DB().WithContext(ctx).Table("contacts").Select("1").
+		Joins("JOIN contact_groups ON contact_groups.contact_id = contacts.id").
+		Joins("JOIN groups ON groups.id = contact_groups.group_id AND groups.deleted_at IS NULL").
+		Where("contacts.deleted_at IS NULL AND groups.name = ?", groupName)
+	switch {
+	case user.ContactID != nil:
+		membership = membership.Where("contacts.id = ?", *user.ContactID)
+	case user.TelegramID != 0:
+		membership = membership.Where("contacts.telegram_id = ?", user.TelegramID)
+	default:
+		return false, nil
+	}
+	if orgID != 0 {
+		membership = membership.Where("contacts.organization_id = ?", orgID)
+	}
+
+	var exists bool
+	if err := r.DB().WithContext(ctx).Raw("SELECT EXISTS (?)", membership).Scan(&exists).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to check group membership", slog.Uint64("user_id", uint64(user.ID)), slog.String("group", groupName), slog.Any("error", err))
+		return false, err
+	}
+	return exists, nil
+}
+
 // UpdateUser обновляет данные пользователя
 func (r *authRepository) UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
 	return r.BaseRepository.Update(ctx, user)
 }
 
-// CreateSession создает сессию в Redis
-func (r *authRepository) CreateSession(ctx context.Context, session *domain.UserSession) error {
-	sessionData, err := json.Marshal(session)
-	if err != nil {
-		r.Logger().ErrorContext(ctx, "Failed to marshal session", slog.Any("error", err))
+// UpdateLastLogin записывает время последнего входа пользователя
+func (r *authRepository) UpdateLastLogin(ctx context.Context, userID uint, at time.Time) error {
+	if err := r.DB().WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).UpdateColumn("last_login_at", at).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to update user last login", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
 		return err
 	}
+	return nil
+}
 
-	key := r.getSessionKey(session.SessionToken)
-	ttl := time.Until(session.ExpiredAt)
-
-	if err := r.redisClient.Set(ctx, key, sessionData, ttl).Err(); err != nil {
-		r.Logger().ErrorContext(ctx, "Failed to create session in Redis", slog.String("session_token", session.SessionToken), slog.Any("error", err))
-		return err
+// GetUsers возвращает пользователей-людей, начиная с давно не входивших.
+// С организацией в контексте - только ее участников: по контакту или явному членству.
+func (r *authRepository) GetUsers(ctx context.Context, inactiveSince *time.Time) ([]domain.User, error) {
+	var users []domain.User
+	query := r.DB().WithContext(ctx).Preload("Contact").Where("type = ?", domain.UserTypeHuman)
+	if orgID := tenant.FromContext(ctx); orgID != 0 {
+		query = query.Where("contact_id IN (?) OR id IN (?)",
+			r.DB().Model(&domain.Contact{}).Select("id").Where("organization_id = ?", orgID),
+			r.DB().Model(&domain.OrganizationMember{}).Select("user_id").Where("organization_id = ?", orgID))
 	}
-
-	r.Logger().InfoContext(ctx, "Session created successfully", slog.String("session_token", session.SessionToken), slog.Uint64("user_id", uint64(session.UserID)))
-	return nil
+	if inactiveSince != nil {
+		query = query.Where("last_login_at IS NULL OR last_login_at < ?", *inactiveSince)
+	}
+	if err := query.Order("last_login_at IS NOT NULL, last_login_at, id").Find(&users).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to get users", slog.Any("error", err))
+		return nil, err
+	}
+	return users, nil
 }
 
-// GetSession получает сессию из Redis
-func (r *authRepository) GetSession(ctx context.Context, sessionToken string) (*domain.UserSession, error) {
-	key := r.getSessionKey(sessionToken)
+// IssueLoginCode сохраняет код входа одним запросом INSERT ... ON CONFLICT DO UPDATE ... WHERE,
+// чтобы одновременные запросы кода не обходили интервал повторной отправки
+func (r *authRepository) IssueLoginCode(ctx context.Context, code *domain.LoginCode, issuedBefore time.Time) (bool, error) {
+	result := r.DB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "phone"}},
+		DoUpdates: clause.AssignmentColumns([]string{"code_hash", "attempts", "created_at", "expires_at"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Lte{Column: clause.Column{Table: domain.LoginCode{}.TableName(), Name: "created_at"}, Value: issuedBefore}}},
+	}).Create(code)
+	if result.Error != nil {
+		r.Logger().ErrorContext(ctx, "Failed to save login code", slog.Any("error", result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
 
-	sessionData, err := r.redisClient.Get(ctx, key).Result()
-	if err != nil {
-		if err == redis.Nil {
-			r.Logger().WarnContext(ctx, "Session not found", slog.String("session_token", sessionToken))
-			return nil, fmt.Errorf("session not found")
+// GetLoginCode получает код входа по телефону
+func (r *authRepository) GetLoginCode(ctx context.Context, phone string) (*domain.LoginCode, error) {
+	var code domain.LoginCode
+	if err := r.DB().WithContext(ctx).Where("phone = ?", phone).First(&code).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.Logger().ErrorContext(ctx, "Failed to get login code", slog.Any("error", err))
 		}
-		r.Logger().ErrorContext(ctx, "Failed to get session from Redis", slog.String("session_token", sessionToken), slog.Any("error", err))
 		return nil, err
 	}
+	return &code, nil
+}
 
-	var session domain.UserSession
-	if err := json.Unmarshal([]byte(sessionData), &session); err != nil {
-		r.Logger().ErrorContext(ctx, "Failed to unmarshal session", slog.String("session_token", sessionToken), slog.Any("error", err))
-		return nil, err
+// ClaimLoginCodeAttempt увеличивает счетчик попыток условным UPDATE, чтобы одновременные
+// попытки не превышали лимит
+func (r *authRepository) ClaimLoginCodeAttempt(ctx context.Context, phone string, maxAttempts int) (bool, error) {
+	result := r.DB().WithContext(ctx).Model(&domain.LoginCode{}).Where("phone = ? AND attempts < ?", phone, maxAttempts).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		r.Logger().ErrorContext(ctx, "Failed to increment login code attempts", slog.Any("error", result.Error))
+		return false, result.Error
 	}
+	return result.RowsAffected > 0, nil
+}
 
-	// Проверяем, не истекла ли сессия
-	if time.Now().After(session.ExpiredAt) {
-		r.Logger().WarnContext(ctx, "Session expired", slog.String("session_token", sessionToken))
-		// Удаляем истекшую сессию
-		r.DeleteSession(ctx, sessionToken)
-		return nil, fmt.Errorf("session expired")
+// DeleteLoginCode удаляет код входа
+func (r *authRepository) DeleteLoginCode(ctx context.Context, phone string) error {
+	if err := r.DB().WithContext(ctx).Where("phone = ?", phone).Delete(&domain.LoginCode{}).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to delete login code", slog.Any("error", err))
+		return err
 	}
-
-	return &session, nil
+	return nil
 }
 
-// DeleteSession удаляет сессию из Redis
-func (r *authRepository) DeleteSession(ctx context.Context, sessionToken string) error {
-	key := r.getSessionKey(sessionToken)
-
-	if err := r.redisClient.Del(ctx, key).Err(); err != nil {
-		r.Logger().ErrorContext(ctx, "Failed to delete session from Redis", slog.String("session_token", sessionToken), slog.Any("error", err))
+// SaveEmailVerification сохраняет ссылку подтверждения, заменяя прежнюю ссылку контакта
+func (r *authRepository) SaveEmailVerification(ctx context.Context, verification *domain.EmailVerification) error {
+	if err := r.DB().WithContext(ctx).Save(verification).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to save email verification", slog.Uint64("contact_id", uint64(verification.ContactID)), slog.Any("error", err))
 		return err
 	}
-
-	r.Logger().InfoContext(ctx, "Session deleted successfully", slog.String("session_token", sessionToken))
 	return nil
 }
 
-// DeleteAllUserSessions удаляет все сессии пользователя
-func (r *authRepository) DeleteAllUserSessions(ctx context.Context, userID uint) error {
-	pattern := fmt.Sprintf("session:user:%d:*", userID)
-
-	keys, err := r.redisClient.Keys(ctx, pattern).Result()
-	if err != nil {
-		r.Logger().ErrorContext(ctx, "Failed to get user sessions keys", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
-		return err
+// GetEmailVerification получает действующую ссылку подтверждения контакта
+func (r *authRepository) GetEmailVerification(ctx context.Context, contactID uint) (*domain.EmailVerification, error) {
+	var verification domain.EmailVerification
+	if err := r.DB().WithContext(ctx).Where("contact_id = ?", contactID).First(&verification).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.Logger().ErrorContext(ctx, "Failed to get email verification", slog.Uint64("contact_id", uint64(contactID)), slog.Any("error", err))
+		}
+		return nil, err
 	}
+	return &verification, nil
+}
 
-	if len(keys) == 0 {
-		return nil
+// GetEmailVerificationByTokenHash получает ссылку подтверждения по хешу токена
+func (r *authRepository) GetEmailVerificationByTokenHash(ctx context.Context, tokenHash string) (*domain.EmailVerification, error) {
+	var verification domain.EmailVerification
+	if err := r.DB().WithContext(ctx).Where("token_hash = ?", tokenHash).First(&verification).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.Logger().ErrorContext(ctx, "Failed to get email verification by token", slog.Any("error", err))
+		}
+		return nil, err
 	}
+	return &verification, nil
+}
 
-	if err := r.redisClient.Del(ctx, keys...).Err(); err != nil {
-		r.Logger().ErrorContext(ctx, "Failed to delete user sessions", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+// DeleteEmailVerification удаляет ссылку подтверждения контакта
+func (r *authRepository) DeleteEmailVerification(ctx context.Context, contactID uint) error {
+	if err := r.DB().WithContext(ctx).Where("contact_id = ?", contactID).Delete(&domain.EmailVerification{}).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to delete email verification", slog.Uint64("contact_id", uint64(contactID)), slog.Any("error", err))
 		return err
 	}
-
-	r.Logger().InfoContext(ctx, "All user sessions deleted", slog.Uint64("user_id", uint64(userID)), slog.Int("count", len(keys)))
 	return nil
 }
-
-// getSessionKey формирует ключ для хранения сессии в Redis
-func (r *authRepository) getSessionKey(sessionToken string) string {
-	return fmt.Sprintf("session:%s", sessionToken)
-}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"rim/internal/domain"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestRepository создает репозиторий на временной БД SQLite с таблицей кодов входа
+func newTestRepository(t *testing.T) Repository {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "rim.db") + "?_busy_timeout=5000&_journal_mode=WAL"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.LoginCode{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return NewAuthRepository(db, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestClaimLoginCodeAttemptConcurrent(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	const phone, maxAttempts, guesses = "+79990001122", 5, 20
+
+	code := &domain.LoginCode{Phone: phone, CodeHash: "hash", CreatedAt: now, ExpiresAt: now.Add(time.Minute)}
+	if _, err := repo.IssueLoginCode(ctx, code, now); err != nil {
+		t.Fatalf("IssueLoginCode: %v", err)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		claimed int
+	)
+	for range guesses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := repo.ClaimLoginCodeAttempt(ctx, phone, maxAttempts)
+			if err != nil {
+				t.Errorf("ClaimLoginCodeAttempt: %v", err)
+				return
+			}
+			if ok {
+				mu.Lock()
+				claimed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if claimed != maxAttempts {
+		t.Errorf("claimed %d attempts, want %d", claimed, maxAttempts)
+	}
+	stored, err := repo.GetLoginCode(ctx, phone)
+	if err != nil {
+		t.Fatalf("GetLoginCode: %v", err)
+	}
+	if stored.Attempts != maxAttempts {
+		t.Errorf("stored attempts = %d, want %d", stored.Attempts, maxAttempts)
+	}
+}
+
+func TestClaimLoginCodeAttemptMissingCode(t *testing.T) {
+	repo := newTestRepository(t)
+
+	ok, err := repo.ClaimLoginCodeAttempt(context.Background(), "+79990001122", 5)
+	if err != nil {
+		t.Fatalf("ClaimLoginCodeAttempt: %v", err)
+	}
+	if ok {
+		t.Error("attempt claimed for missing code")
+	}
+}
+
+func TestIssueLoginCodeResendInterval(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	const phone = "+79990001122"
+
+	first := &domain.LoginCode{Phone: phone, CodeHash: "first", CreatedAt: now, ExpiresAt: now.Add(5 * time.Minute)}
+	if ok, err := repo.IssueLoginCode(ctx, first, now.Add(-time.Minute)); err != nil || !ok {
+		t.Fatalf("first IssueLoginCode = %v, %v; want true", ok, err)
+	}
+	if _, err := repo.ClaimLoginCodeAttempt(ctx, phone, 5); err != nil {
+		t.Fatalf("ClaimLoginCodeAttempt: %v", err)
+	}
+
+	// Код создан позже issuedBefore - замена запрещена
+	soon := now.Add(30 * time.Second)
+	second := &domain.LoginCode{Phone: phone, CodeHash: "second", CreatedAt: soon, ExpiresAt: soon.Add(5 * time.Minute)}
+	if ok, err := repo.IssueLoginCode(ctx, second, soon.Add(-time.Minute)); err != nil || ok {
+		t.Fatalf("early IssueLoginCode = %v, %v; want false", ok, err)
+	}
+	if stored, _ := repo.GetLoginCode(ctx, phone); stored.CodeHash != "first" {
+		t.Fatalf("code replaced before resend interval: %s", stored.CodeHash)
+	}
+
+	// После интервала код заменяется, а счетчик попыток сбрасывается
+	later := now.Add(2 * time.Minute)
+	third := &domain.LoginCode{Phone: phone, CodeHash: "third", CreatedAt: later, ExpiresAt: later.Add(5 * time.Minute)}
+	if ok, err := repo.IssueLoginCode(ctx, third, later.Add(-time.Minute)); err != nil || !ok {
+		t.Fatalf("late IssueLoginCode = %v, %v; want true", ok, err)
+	}
+	stored, err := repo.GetLoginCode(ctx, phone)
+	if err != nil {
+		t.Fatalf("GetLoginCode: %v", err)
+	}
+	if stored.CodeHash != "third" || stored.Attempts != 0 {
+		t.Errorf("stored code = %s with %d attempts, want third with 0", stored.CodeHash, stored.Attempts)
+	}
+}
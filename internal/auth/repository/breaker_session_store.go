@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/circuitbreaker"
+)
+
+// breakerSessionStore ограничивает время каждой операции хранилища и не обращается к нему,
+// пока автомат разомкнут. Сбой хранилища возвращается как ErrSessionStoreUnavailable.
+type breakerSessionStore struct {
+	store   SessionStore
+	timeout time.Duration
+	breaker *circuitbreaker.Breaker
+}
+
+// NewBreakerSessionStore оборачивает store таймаутом timeout на операцию и автоматом breaker.
+// breaker должен считать сбоем только ошибки, для которых IsStoreFailure возвращает true.
+func NewBreakerSessionStore(store SessionStore, timeout time.Duration, breaker *circuitbreaker.Breaker) SessionStore {
+	return &breakerSessionStore{store: store, timeout: timeout, breaker: breaker}
+}
+
+// IsStoreFailure сообщает, что ошибка операции хранилища означает его недоступность. Доменные ошибки
+// (сессия не найдена, истекла) - обычный ответ; отмена запроса клиентом тоже не сбой хранилища.
+func IsStoreFailure(err error) bool {
+	return err != nil && apperror.CodeOf(err) == "" && !errors.Is(err, context.Canceled)
+}
+
+func (s *breakerSessionStore) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := s.breaker.Do(func() error {
+		ctx, cancel := context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+		return fn(ctx)
+	})
+	if errors.Is(err, circuitbreaker.ErrOpen) {
+		return ErrSessionStoreUnavailable
+	}
+	if IsStoreFailure(err) {
+		return ErrSessionStoreUnavailable.Wrap(err)
+	}
+	return err
+}
+
+func (s *breakerSessionStore) CreateSession(ctx context.Context, session *domain.UserSession) error {
+	return s.call(ctx, func(ctx context.Context) error {
+		return s.store.CreateSession(ctx, session)
+	})
+}
+
+func (s *breakerSessionStore) GetSession(ctx context.Context, sessionToken string) (*domain.UserSession, error) {
+	var session *domain.UserSession
+	err := s.call(ctx, func(ctx context.Context) error {
+		var err error
+		session, err = s.store.GetSession(ctx, sessionToken)
+		return err
+	})
+	return session, err
+}
+
+func (s *breakerSessionStore) DeleteSession(ctx context.Context, sessionToken string) error {
+	return s.call(ctx, func(ctx context.Context) error {
+		return s.store.DeleteSession(ctx, sessionToken)
+	})
+}
+
+func (s *breakerSessionStore) MigrateSession(ctx context.Context, oldToken string, session *domain.UserSession, grace time.Duration) error {
+	return s.call(ctx, func(ctx context.Context) error {
+		return s.store.MigrateSession(ctx, oldToken, session, grace)
+	})
+}
+
+func (s *breakerSessionStore) DeleteAllUserSessions(ctx context.Context, userID uint) error {
+	return s.call(ctx, func(ctx context.Context) error {
+		return s.store.DeleteAllUserSessions(ctx, userID)
+	})
+}
+
+func (s *breakerSessionStore) GetUserSessions(ctx context.Context, userID uint) ([]domain.UserSession, error) {
+	var sessions []domain.UserSession
+	err := s.call(ctx, func(ctx context.Context) error {
+		var err error
+		sessions, err = s.store.GetUserSessions(ctx, userID)
+		return err
+	})
+	return sessions, err
+}
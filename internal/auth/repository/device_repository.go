@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"rim/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// deviceTouchInterval - минимальный интервал между обновлениями last_seen_at,
+// чтобы не писать в БД на каждый запрос
+const deviceTouchInterval = 5 * time.Minute
+
+// CreateDevice сохраняет новое устройство пользователя
+func (r *authRepository) CreateDevice(ctx context.Context, device *domain.UserDevice) error {
+	if err := r.DB().WithContext(ctx).Create(device).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to create user device", slog.Uint64("user_id", uint64(device.UserID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// UpdateDevice сохраняет изменения устройства
+func (r *authRepository) UpdateDevice(ctx context.Context, device *domain.UserDevice) error {
+	if err := r.DB().WithContext(ctx).Save(device).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to update user device", slog.Uint64("device_id", uint64(device.ID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// GetDeviceByFingerprint получает устройство пользователя по хешу идентификатора
+func (r *authRepository) GetDeviceByFingerprint(ctx context.Context, userID uint, fingerprintHash string) (*domain.UserDevice, error) {
+	var device domain.UserDevice
+	err := r.DB().WithContext(ctx).Where("user_id = ? AND fingerprint_hash = ?", userID, fingerprintHash).First(&device).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.Logger().ErrorContext(ctx, "Failed to get user device by fingerprint", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &device, nil
+}
+
+// GetUserDevice получает устройство по ID, только если оно принадлежит пользователю
+func (r *authRepository) GetUserDevice(ctx context.Context, userID, deviceID uint) (*domain.UserDevice, error) {
+	var device domain.UserDevice
+	err := r.DB().WithContext(ctx).Where("id = ? AND user_id = ?", deviceID, userID).First(&device).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.Logger().ErrorContext(ctx, "Failed to get user device", slog.Uint64("device_id", uint64(deviceID)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &device, nil
+}
+
+// GetUserDevices возвращает устройства пользователя, начиная с последнего активного
+func (r *authRepository) GetUserDevices(ctx context.Context, userID uint) ([]domain.UserDevice, error) {
+	var devices []domain.UserDevice
+	if err := r.DB().WithContext(ctx).Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&devices).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to get user devices", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		return nil, err
+	}
+	return devices, nil
+}
+
+// CountTrustedDevices возвращает число доверенных устройств пользователя
+func (r *authRepository) CountTrustedDevices(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	if err := r.DB().WithContext(ctx).Model(&domain.UserDevice{}).Where("user_id = ? AND trusted = ?", userID, true).Count(&count).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to count trusted devices", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// TouchDevice обновляет время последней активности устройства не чаще deviceTouchInterval
+func (r *authRepository) TouchDevice(ctx context.Context, deviceID uint, seenAt time.Time) error {
+	err := r.DB().WithContext(ctx).Model(&domain.UserDevice{}).
+		Where("id = ? AND last_seen_at < ?", deviceID, seenAt.Add(-deviceTouchInterval)).
+		UpdateColumn("last_seen_at", seenAt).Error
+	if err != nil {
+		r.Logger().WarnContext(ctx, "Failed to update device last seen time", slog.Uint64("device_id", uint64(deviceID)), slog.Any("error", err))
+	}
+	return err
+}
+
+// DeleteDevice удаляет устройство
+func (r *authRepository) DeleteDevice(ctx context.Context, deviceID uint) error {
+	if err := r.DB().WithContext(ctx).Delete(&domain.UserDevice{}, deviceID).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to delete user device", slog.Uint64("device_id", uint64(deviceID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
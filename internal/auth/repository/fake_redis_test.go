@@ -0,0 +1,407 @@
+package repository
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeRedis - сервер RESP2 в памяти с командами, которые использует redisSessionStore:
+// строки и sorted set со сроком жизни, SCAN, MGET и транзакции MULTI/EXEC с WATCH
+type fakeRedis struct {
+	mu       sync.Mutex
+	strings  map[string]string
+	zsets    map[string]map[string]float64
+	expires  map[string]time.Time
+	versions map[string]int // Номер изменения ключа для WATCH
+}
+
+// errReply - ответ-ошибка RESP
+type errReply string
+
+// statusReply - простая строка RESP
+type statusReply string
+
+// newFakeRedis запускает fakeRedis на локальном порту и возвращает клиент к нему
+func newFakeRedis(t *testing.T) (*fakeRedis, *redis.Client) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	f := &fakeRedis{
+		strings:  map[string]string{},
+		zsets:    map[string]map[string]float64{},
+		expires:  map[string]time.Time{},
+		versions: map[string]int{},
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	client := redis.NewClient(&redis.Options{Addr: ln.Addr().String(), Protocol: 2, DisableIdentity: true})
+	t.Cleanup(func() {
+		client.Close()
+		ln.Close()
+	})
+	return f, client
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+	var (
+		watched map[string]int
+		queued  [][]string
+		inMulti bool
+	)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		var reply interface{}
+		switch name := strings.ToUpper(args[0]); {
+		case name == "MULTI":
+			inMulti, queued = true, nil
+			reply = statusReply("OK")
+		case name == "EXEC":
+			reply = f.exec(watched, queued)
+			inMulti, queued, watched = false, nil, nil
+		case name == "DISCARD":
+			inMulti, queued, watched = false, nil, nil
+			reply = statusReply("OK")
+		case inMulti:
+			queued = append(queued, args)
+			reply = statusReply("QUEUED")
+		case name == "WATCH":
+			f.mu.Lock()
+			if watched == nil {
+				watched = map[string]int{}
+			}
+			for _, key := range args[1:] {
+				watched[key] = f.versions[key]
+			}
+			f.mu.Unlock()
+			reply = statusReply("OK")
+		case name == "UNWATCH":
+			watched = nil
+			reply = statusReply("OK")
+		default:
+			f.mu.Lock()
+			reply = f.do(args)
+			f.mu.Unlock()
+		}
+		writeReply(w, reply)
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// exec выполняет очередь транзакции; если ключи из WATCH изменились, возвращает nil, как Redis
+func (f *fakeRedis) exec(watched map[string]int, queued [][]string) interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for key, version := range watched {
+		if f.versions[key] != version {
+			return []interface{}(nil)
+		}
+	}
+	replies := make([]interface{}, len(queued))
+	for i, args := range queued {
+		replies[i] = f.do(args)
+	}
+	return replies
+}
+
+// do выполняет команду; f.mu должен быть захвачен
+func (f *fakeRedis) do(args []string) interface{} {
+	name, args := strings.ToUpper(args[0]), args[1:]
+	switch name {
+	case "PING":
+		return statusReply("PONG")
+	case "CLIENT", "SELECT":
+		return statusReply("OK")
+	case "GET":
+		if value, ok := f.str(args[0]); ok {
+			return &value
+		}
+		return (*string)(nil)
+	case "MGET":
+		values := make([]interface{}, len(args))
+		for i, key := range args {
+			values[i] = (*string)(nil)
+			if value, ok := f.str(key); ok {
+				values[i] = &value
+			}
+		}
+		return values
+	case "SET":
+		f.del(args[0])
+		f.strings[args[0]] = args[1]
+		for i := 2; i+1 < len(args); i += 2 {
+			n, _ := strconv.ParseInt(args[i+1], 10, 64)
+			switch strings.ToUpper(args[i]) {
+			case "EX":
+				f.expires[args[0]] = time.Now().Add(time.Duration(n) * time.Second)
+			case "PX":
+				f.expires[args[0]] = time.Now().Add(time.Duration(n) * time.Millisecond)
+			}
+		}
+		return statusReply("OK")
+	case "DEL", "EXISTS":
+		var n int64
+		for _, key := range args {
+			if f.exists(key) {
+				n++
+				if name == "DEL" {
+					f.del(key)
+				}
+			}
+		}
+		return n
+	case "PTTL":
+		if !f.exists(args[0]) {
+			return int64(-2)
+		}
+		if at, ok := f.expires[args[0]]; ok {
+			return time.Until(at).Milliseconds()
+		}
+		return int64(-1)
+	case "EXPIRE", "PEXPIRE", "EXPIREAT", "PEXPIREAT":
+		if !f.exists(args[0]) {
+			return int64(0)
+		}
+		n, _ := strconv.ParseInt(args[1], 10, 64)
+		at := map[string]time.Time{
+			"EXPIRE":    time.Now().Add(time.Duration(n) * time.Second),
+			"PEXPIRE":   time.Now().Add(time.Duration(n) * time.Millisecond),
+			"EXPIREAT":  time.Unix(n, 0),
+			"PEXPIREAT": time.UnixMilli(n),
+		}[name]
+		f.expires[args[0]] = at
+		f.versions[args[0]]++
+		return int64(1)
+	case "ZADD":
+		f.exists(args[0])
+		set := f.zsets[args[0]]
+		if set == nil {
+			set = map[string]float64{}
+			f.zsets[args[0]] = set
+		}
+		var added int64
+		for i := 1; i+1 < len(args); i += 2 {
+			score, _ := strconv.ParseFloat(args[i], 64)
+			if _, ok := set[args[i+1]]; !ok {
+				added++
+			}
+			set[args[i+1]] = score
+		}
+		f.versions[args[0]]++
+		return added
+	case "ZREM":
+		var removed int64
+		if f.exists(args[0]) {
+			for _, member := range args[1:] {
+				if _, ok := f.zsets[args[0]][member]; ok {
+					delete(f.zsets[args[0]], member)
+					removed++
+				}
+			}
+			if len(f.zsets[args[0]]) == 0 {
+				f.del(args[0])
+			}
+			f.versions[args[0]]++
+		}
+		return removed
+	case "ZRANGE":
+		members := f.members(args[0])
+		start, _ := strconv.Atoi(args[1])
+		stop, _ := strconv.Atoi(args[2])
+		if stop < 0 {
+			stop += len(members)
+		}
+		out := []interface{}{}
+		for i := start; i <= stop && i < len(members); i++ {
+			member := members[i]
+			out = append(out, &member)
+		}
+		return out
+	case "SCAN":
+		var pattern string
+		for i := 1; i+1 < len(args); i += 2 {
+			if strings.EqualFold(args[i], "MATCH") {
+				pattern = args[i+1]
+			}
+		}
+		keys := []interface{}{}
+		for _, key := range f.keys() {
+			if ok, _ := path.Match(pattern, key); ok || pattern == "" {
+				k := key
+				keys = append(keys, &k)
+			}
+		}
+		cursor := "0"
+		return []interface{}{&cursor, keys}
+	}
+	return errReply(fmt.Sprintf("ERR unknown command '%s'", name))
+}
+
+// exists сообщает, есть ли ключ, и удаляет его, если срок жизни истек
+func (f *fakeRedis) exists(key string) bool {
+	if at, ok := f.expires[key]; ok && !time.Now().Before(at) {
+		f.del(key)
+	}
+	_, isString := f.strings[key]
+	_, isSet := f.zsets[key]
+	return isString || isSet
+}
+
+func (f *fakeRedis) str(key string) (string, bool) {
+	if !f.exists(key) {
+		return "", false
+	}
+	value, ok := f.strings[key]
+	return value, ok
+}
+
+func (f *fakeRedis) del(key string) {
+	delete(f.strings, key)
+	delete(f.zsets, key)
+	delete(f.expires, key)
+	f.versions[key]++
+}
+
+// members возвращает элементы sorted set по возрастанию оценки
+func (f *fakeRedis) members(key string) []string {
+	if !f.exists(key) {
+		return nil
+	}
+	set := f.zsets[key]
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if set[members[i]] != set[members[j]] {
+			return set[members[i]] < set[members[j]]
+		}
+		return members[i] < members[j]
+	})
+	return members
+}
+
+// keys возвращает все действующие ключи по алфавиту
+func (f *fakeRedis) keys() []string {
+	var keys []string
+	for key := range f.strings {
+		keys = append(keys, key)
+	}
+	for key := range f.zsets {
+		keys = append(keys, key)
+	}
+	keys = slices.DeleteFunc(keys, func(key string) bool { return !f.exists(key) })
+	slices.Sort(keys)
+	return keys
+}
+
+// ttl возвращает оставшийся срок жизни ключа; 0 - ключ без срока
+func (f *fakeRedis) ttl(key string) time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if at, ok := f.expires[key]; ok {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// Keys возвращает действующие ключи, подходящие под шаблон
+func (f *fakeRedis) Keys(pattern string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.DeleteFunc(f.keys(), func(key string) bool {
+		ok, _ := path.Match(pattern, key)
+		return !ok
+	})
+}
+
+// readCommand читает команду клиента: массив bulk-строк
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, errors.New("expected array")
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 1 {
+		return nil, errors.New("invalid array length")
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimPrefix(line, "$"))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+func writeReply(w *bufio.Writer, reply interface{}) {
+	switch v := reply.(type) {
+	case statusReply:
+		fmt.Fprintf(w, "+%s\r\n", v)
+	case errReply:
+		fmt.Fprintf(w, "-%s\r\n", v)
+	case int64:
+		fmt.Fprintf(w, ":%d\r\n", v)
+	case *string:
+		if v == nil {
+			w.WriteString("$-1\r\n")
+			return
+		}
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(*v), *v)
+	case []interface{}:
+		if v == nil {
+			w.WriteString("*-1\r\n")
+			return
+		}
+		fmt.Fprintf(w, "*%d\r\n", len(v))
+		for _, item := range v {
+			writeReply(w, item)
+		}
+	}
+}
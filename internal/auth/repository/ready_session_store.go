@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rim/internal/domain"
+)
+
+// readySessionStore не обращается к хранилищу, пока ready сообщает о его недоступности
+// (по последней проверке монитора зависимостей), и сразу возвращает ErrSessionStoreUnavailable.
+// В отличие от автомата breakerSessionStore, запросы отклоняются без ожидания таймаута даже первых операций.
+type readySessionStore struct {
+	store SessionStore
+	ready func() bool
+}
+
+// NewReadySessionStore оборачивает store проверкой готовности ready
+func NewReadySessionStore(store SessionStore, ready func() bool) SessionStore {
+	return &readySessionStore{store: store, ready: ready}
+}
+
+func (s *readySessionStore) CreateSession(ctx context.Context, session *domain.UserSession) error {
+	if !s.ready() {
+		return ErrSessionStoreUnavailable
+	}
+	return s.store.CreateSession(ctx, session)
+}
+
+func (s *readySessionStore) GetSession(ctx context.Context, sessionToken string) (*domain.UserSession, error) {
+	if !s.ready() {
+		return nil, ErrSessionStoreUnavailable
+	}
+	return s.store.GetSession(ctx, sessionToken)
+}
+
+func (s *readySessionStore) DeleteSession(ctx context.Context, sessionToken string) error {
+	if !s.ready() {
+		return ErrSessionStoreUnavailable
+	}
+	return s.store.DeleteSession(ctx, sessionToken)
+}
+
+func (s *readySessionStore) MigrateSession(ctx context.Context, oldToken string, session *domain.UserSession, grace time.Duration) error {
+	if !s.ready() {
+		return ErrSessionStoreUnavailable
+	}
+	return s.store.MigrateSession(ctx, oldToken, session, grace)
+}
+
+func (s *readySessionStore) DeleteAllUserSessions(ctx context.Context, userID uint) error {
+	if !s.ready() {
+		return ErrSessionStoreUnavailable
+	}
+	return s.store.DeleteAllUserSessions(ctx, userID)
+}
+
+func (s *readySessionStore) GetUserSessions(ctx context.Context, userID uint) ([]domain.UserSession, error) {
+	if !s.ready() {
+		return nil, ErrSessionStoreUnavailable
+	}
+	return s.store.GetUserSessions(ctx, userID)
+}
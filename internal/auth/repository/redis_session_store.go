@@ -0,0 +1,460 @@
+package repository
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"rim/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// sessionScanBatchSize - подсказка COUNT для SCAN и размер пачки ключей при переносе сессий
+const sessionScanBatchSize = 500
+
+// sessionRefPrefix - префикс ссылки на сессию в SessionToken результатов GetUserSessions
+const sessionRefPrefix = "sha256:"
+
+// redisSessionStore хранит сессию под SHA-256 токена (session_sha256:{хеш}), а индекс сессий пользователя -
+// из хешей, поэтому дамп Redis не содержит действующих токенов. Сессии, сохраненные прежними версиями
+// под самим токеном (session:{токен}), переносятся при первом чтении и фоновой задачей MigrateRedisSessions.
+type redisSessionStore struct {
+	redisClient *redis.Client
+	logger      *slog.Logger
+}
+
+// NewRedisSessionStore создает хранилище сессий в Redis
+func NewRedisSessionStore(redisClient *redis.Client, logger *slog.Logger) SessionStore {
+	return &redisSessionStore{
+		redisClient: redisClient,
+		logger:      logger,
+	}
+}
+
+// CreateSession создает сессию в Redis и добавляет ее в индекс сессий пользователя
+func (s *redisSessionStore) CreateSession(ctx context.Context, session *domain.UserSession) error {
+	sessionData, err := marshalStoredSession(session)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to marshal session", slog.Any("error", err))
+		return err
+	}
+
+	hash := tokenHash(session.SessionToken)
+	indexKey := s.getUserSessionsKey(session.UserID)
+	ttl := time.Until(session.ExpiredAt)
+
+	// Сессия и индекс записываются одной транзакцией, чтобы не оставлять сессий вне индекса.
+	// Индекс живет не меньше самой свежей сессии пользователя.
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.getSessionKey(hash), sessionData, ttl)
+		pipe.ZAdd(ctx, indexKey, redis.Z{Score: float64(session.CreatedAt.UnixNano()), Member: hash})
+		pipe.Expire(ctx, indexKey, ttl)
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create session in Redis", sessionLogAttr(session.SessionToken), slog.Any("error", err))
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "Session created successfully", sessionLogAttr(session.SessionToken), slog.Uint64("user_id", uint64(session.UserID)))
+	return nil
+}
+
+// GetSession получает сессию из Redis
+func (s *redisSessionStore) GetSession(ctx context.Context, sessionToken string) (*domain.UserSession, error) {
+	key := s.getSessionKey(tokenHash(sessionToken))
+
+	sessionData, err := s.redisClient.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		sessionData, err = s.getLegacySession(ctx, sessionToken)
+	}
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			s.logger.WarnContext(ctx, "Session not found", sessionLogAttr(sessionToken))
+			return nil, ErrSessionNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get session from Redis", sessionLogAttr(sessionToken), slog.Any("error", err))
+		return nil, err
+	}
+
+	var session domain.UserSession
+	if err := json.Unmarshal([]byte(sessionData), &session); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to unmarshal session", sessionLogAttr(sessionToken), slog.Any("error", err))
+		return nil, err
+	}
+	session.SessionToken = sessionToken
+
+	// Проверяем, не истекла ли сессия
+	if time.Now().After(session.ExpiredAt) {
+		s.logger.WarnContext(ctx, "Session expired", sessionLogAttr(sessionToken))
+		// Удаляем истекшую сессию
+		s.DeleteSession(ctx, sessionToken)
+		return nil, ErrSessionExpired
+	}
+
+	return &session, nil
+}
+
+// getLegacySession переносит под хеш сессию, созданную до хеширования токенов, и возвращает ее данные.
+// redis.Nil - такой сессии нет.
+func (s *redisSessionStore) getLegacySession(ctx context.Context, sessionToken string) (string, error) {
+	exists, err := s.redisClient.Exists(ctx, legacySessionKey(sessionToken)).Result()
+	if err != nil {
+		return "", err
+	}
+	if exists == 0 {
+		return "", redis.Nil
+	}
+	if _, err := s.migrateLegacySessions(ctx, []string{sessionToken}); err != nil {
+		return "", err
+	}
+	return s.redisClient.Get(ctx, s.getSessionKey(tokenHash(sessionToken))).Result()
+}
+
+// DeleteSession удаляет сессию из Redis и из индекса сессий пользователя.
+// sessionToken - токен или ссылка на сессию из GetUserSessions.
+func (s *redisSessionStore) DeleteSession(ctx context.Context, sessionToken string) error {
+	hash, isRef := strings.CutPrefix(sessionToken, sessionRefPrefix)
+	if isRef && !isTokenHash(hash) {
+		return nil
+	}
+	keys := []string{s.getSessionKey(hash)}
+	members := []interface{}{hash}
+	if !isRef {
+		// По токену удаляется и сессия, еще не перенесенная под хеш
+		hash = tokenHash(sessionToken)
+		keys = []string{s.getSessionKey(hash), legacySessionKey(sessionToken)}
+		members = []interface{}{hash, sessionToken}
+	}
+
+	// Читаем сессию, чтобы узнать пользователя и убрать ее из его индекса
+	values, err := s.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get session from Redis before delete", slog.String("session", hash[:12]), slog.Any("error", err))
+		return err
+	}
+	var userID uint
+	for _, value := range values {
+		var session domain.UserSession
+		if data, ok := value.(string); ok && json.Unmarshal([]byte(data), &session) == nil {
+			userID = session.UserID
+			break
+		}
+	}
+
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keys...)
+		if userID != 0 {
+			pipe.ZRem(ctx, s.getUserSessionsKey(userID), members...)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete session from Redis", slog.String("session", hash[:12]), slog.Any("error", err))
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "Session deleted successfully", slog.String("session", hash[:12]))
+	return nil
+}
+
+// MigrateSession записывает сессию под новым токеном и сокращает TTL прежнего токена до grace.
+// Прежний токен сразу убирается из индекса, чтобы не занимать место в лимите сессий.
+// Сессию прежнего токена GetSession к этому моменту уже перенес под хеш.
+func (s *redisSessionStore) MigrateSession(ctx context.Context, oldToken string, session *domain.UserSession, grace time.Duration) error {
+	sessionData, err := marshalStoredSession(session)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to marshal session", slog.Any("error", err))
+		return err
+	}
+
+	newHash, oldHash := tokenHash(session.SessionToken), tokenHash(oldToken)
+	indexKey := s.getUserSessionsKey(session.UserID)
+	ttl := time.Until(session.ExpiredAt)
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.getSessionKey(newHash), sessionData, ttl)
+		pipe.ZAdd(ctx, indexKey, redis.Z{Score: float64(session.CreatedAt.UnixNano()), Member: newHash})
+		pipe.ZRem(ctx, indexKey, oldHash)
+		pipe.Expire(ctx, indexKey, ttl)
+		pipe.Expire(ctx, s.getSessionKey(oldHash), grace)
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to migrate session in Redis", slog.Uint64("user_id", uint64(session.UserID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// GetUserSessions возвращает активные сессии пользователя из индекса, начиная с самой старой.
+// Вместо токена в SessionToken - ссылка на сессию. Истекшие сессии удаляются из индекса.
+func (s *redisSessionStore) GetUserSessions(ctx context.Context, userID uint) ([]domain.UserSession, error) {
+	indexKey := s.getUserSessionsKey(userID)
+
+	members, err := s.redisClient.ZRange(ctx, indexKey, 0, -1).Result()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get user sessions index", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(members))
+	for i, member := range members {
+		keys[i] = s.indexMemberKey(member)
+	}
+
+	values, err := s.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get user sessions", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		return nil, err
+	}
+
+	sessions := make([]domain.UserSession, 0, len(values))
+	var stale []interface{}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			stale = append(stale, members[i])
+			continue
+		}
+		var session domain.UserSession
+		if err := json.Unmarshal([]byte(data), &session); err != nil {
+			s.logger.WarnContext(ctx, "Failed to unmarshal session from index", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+			continue
+		}
+		// Токен, еще не замененный хешем, остается в индексе как есть: DeleteSession примет и его
+		session.SessionToken = members[i]
+		if isTokenHash(members[i]) {
+			session.SessionToken = sessionRefPrefix + members[i]
+		}
+		sessions = append(sessions, session)
+	}
+
+	if len(stale) > 0 {
+		if err := s.redisClient.ZRem(ctx, indexKey, stale...).Err(); err != nil {
+			s.logger.WarnContext(ctx, "Failed to remove stale tokens from user sessions index", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		}
+	}
+
+	return sessions, nil
+}
+
+// DeleteAllUserSessions удаляет все сессии пользователя по индексу user_sessions:{id}
+func (s *redisSessionStore) DeleteAllUserSessions(ctx context.Context, userID uint) error {
+	indexKey := s.getUserSessionsKey(userID)
+
+	members, err := s.redisClient.ZRange(ctx, indexKey, 0, -1).Result()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get user sessions index", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		return err
+	}
+
+	keys := make([]string, 0, len(members)+1)
+	for _, member := range members {
+		keys = append(keys, s.indexMemberKey(member))
+	}
+	keys = append(keys, indexKey)
+
+	if err := s.redisClient.Del(ctx, keys...).Err(); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete user sessions", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "All user sessions deleted", slog.Uint64("user_id", uint64(userID)), slog.Int("count", len(members)))
+	return nil
+}
+
+// MigrateRedisSessions переносит сессии, сохраненные под самим токеном, под хеш токена и заменяет токены
+// в индексах user_sessions:{id} хешами; сессии, созданные до появления индекса, при этом попадают в него.
+// Ключи перебираются через SCAN пачками. Повторный запуск ничего не меняет. Возвращает число перенесенных сессий.
+func MigrateRedisSessions(ctx context.Context, redisClient *redis.Client, logger *slog.Logger) (int, error) {
+	s := &redisSessionStore{redisClient: redisClient, logger: logger}
+	migrated := 0
+
+	iter := redisClient.Scan(ctx, 0, legacySessionKey("*"), sessionScanBatchSize).Iterator()
+	tokens := make([]string, 0, sessionScanBatchSize)
+	for {
+		more := iter.Next(ctx)
+		if more {
+			tokens = append(tokens, strings.TrimPrefix(iter.Val(), legacySessionKey("")))
+		}
+		if len(tokens) == sessionScanBatchSize || (!more && len(tokens) > 0) {
+			n, err := s.migrateLegacySessions(ctx, tokens)
+			if err != nil {
+				return migrated, err
+			}
+			migrated += n
+			tokens = tokens[:0]
+		}
+		if !more {
+			break
+		}
+	}
+	if err := iter.Err(); err != nil {
+		logger.ErrorContext(ctx, "Failed to scan sessions", slog.Any("error", err))
+		return migrated, err
+	}
+
+	if migrated > 0 {
+		logger.InfoContext(ctx, "Redis sessions moved to hashed tokens", slog.Int("sessions", migrated))
+	}
+	return migrated, nil
+}
+
+// migrateLegacySessions переносит сессии session:{токен} под хеши токенов tokens. Если сессию одновременно
+// удалили (выход), перенос пачки отменяется, чтобы не восстановить ее; такие сессии переносятся при следующем
+// обращении. Возвращает число перенесенных сессий.
+func (s *redisSessionStore) migrateLegacySessions(ctx context.Context, tokens []string) (int, error) {
+	keys := make([]string, len(tokens))
+	for i, token := range tokens {
+		keys[i] = legacySessionKey(token)
+	}
+
+	migrated := 0
+	expiresAt := map[uint]time.Time{} // Самая поздняя сессия каждого пользователя - до нее должен жить индекс
+	err := s.redisClient.Watch(ctx, func(tx *redis.Tx) error {
+		values := make([]*redis.StringCmd, len(tokens))
+		ttls := make([]*redis.DurationCmd, len(tokens))
+		_, err := tx.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range keys {
+				values[i] = pipe.Get(ctx, key)
+				ttls[i] = pipe.PTTL(ctx, key)
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, token := range tokens {
+				data, err := values[i].Result()
+				if err != nil {
+					continue // Сессия истекла или уже перенесена
+				}
+				var session domain.UserSession
+				if err := json.Unmarshal([]byte(data), &session); err != nil || session.UserID == 0 {
+					continue
+				}
+				// Ключ без срока (PTTL -1) получает срок сессии
+				ttl := ttls[i].Val()
+				if ttl <= 0 {
+					ttl = time.Until(session.ExpiredAt)
+				}
+				pipe.Del(ctx, keys[i])
+				indexKey := s.getUserSessionsKey(session.UserID)
+				pipe.ZRem(ctx, indexKey, token)
+				if ttl <= 0 {
+					continue
+				}
+
+				hash := tokenHash(token)
+				sessionData, err := marshalStoredSession(&session)
+				if err != nil {
+					return err
+				}
+				pipe.Set(ctx, s.getSessionKey(hash), sessionData, ttl)
+				pipe.ZAdd(ctx, indexKey, redis.Z{Score: float64(session.CreatedAt.UnixNano()), Member: hash})
+				if session.ExpiredAt.After(expiresAt[session.UserID]) {
+					expiresAt[session.UserID] = session.ExpiredAt
+				}
+				migrated++
+			}
+			return nil
+		})
+		return err
+	}, keys...)
+	if errors.Is(err, redis.TxFailedErr) {
+		return 0, nil
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to move sessions to hashed tokens", slog.Any("error", err))
+		return 0, err
+	}
+
+	if err := s.extendIndexTTL(ctx, expiresAt); err != nil {
+		return migrated, err
+	}
+	return migrated, nil
+}
+
+// extendIndexTTL продлевает индексы до истечения самой поздней сессии пользователя, не сокращая текущий TTL
+func (s *redisSessionStore) extendIndexTTL(ctx context.Context, expiresAt map[uint]time.Time) error {
+	if len(expiresAt) == 0 {
+		return nil
+	}
+	userIDs := make([]uint, 0, len(expiresAt))
+	ttls := make([]*redis.DurationCmd, 0, len(expiresAt))
+	_, err := s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for userID := range expiresAt {
+			userIDs = append(userIDs, userID)
+			ttls = append(ttls, pipe.PTTL(ctx, s.getUserSessionsKey(userID)))
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get user sessions index TTL", slog.Any("error", err))
+		return err
+	}
+	_, err = s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, userID := range userIDs {
+			// Индекс, созданный здесь через ZADD, не имеет срока (PTTL -1) и тоже получает его
+			if ttl := ttls[i].Val(); ttl > 0 && time.Now().Add(ttl).After(expiresAt[userID]) {
+				continue
+			}
+			pipe.ExpireAt(ctx, s.getUserSessionsKey(userID), expiresAt[userID])
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to extend user sessions index TTL", slog.Any("error", err))
+	}
+	return err
+}
+
+// getSessionKey формирует ключ сессии в Redis по хешу ее токена
+func (s *redisSessionStore) getSessionKey(hash string) string {
+	return fmt.Sprintf("session_sha256:%s", hash)
+}
+
+// legacySessionKey - ключ сессии, сохраненной прежними версиями под самим токеном
+func legacySessionKey(sessionToken string) string {
+	return fmt.Sprintf("session:%s", sessionToken)
+}
+
+// indexMemberKey возвращает ключ сессии по элементу индекса: хешу или еще не перенесенному токену
+func (s *redisSessionStore) indexMemberKey(member string) string {
+	if isTokenHash(member) {
+		return s.getSessionKey(member)
+	}
+	return legacySessionKey(member)
+}
+
+// getUserSessionsKey формирует ключ индекса сессий пользователя (sorted set хешей токенов по времени создания)
+func (s *redisSessionStore) getUserSessionsKey(userID uint) string {
+	return fmt.Sprintf("user_sessions:%d", userID)
+}
+
+// isTokenHash отличает хеш токена от самого токена: токены содержат дефисы UUID и не бывают 64 hex-символами
+func isTokenHash(member string) bool {
+	if len(member) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(member)
+	return err == nil
+}
+
+// marshalStoredSession сериализует сессию без токена: в Redis он хранится только как хеш в ключе
+func marshalStoredSession(session *domain.UserSession) ([]byte, error) {
+	stored := *session
+	stored.SessionToken = ""
+	return json.Marshal(stored)
+}
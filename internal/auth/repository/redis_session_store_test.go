@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"rim/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// seedLegacySession сохраняет сессию так, как ее хранили до хеширования токенов: под самим токеном и с токеном
+// в индексе пользователя. ttl = 0 - ключ без срока.
+func seedLegacySession(t *testing.T, client *redis.Client, token string, session domain.UserSession, ttl time.Duration) {
+	t.Helper()
+	ctx := context.Background()
+	session.SessionToken = token
+	data, err := json.Marshal(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Set(ctx, legacySessionKey(token), data, ttl).Err(); err != nil {
+		t.Fatalf("seed session: %v", err)
+	}
+	if err := client.ZAdd(ctx, "user_sessions:1", redis.Z{Score: float64(session.CreatedAt.UnixNano()), Member: token}).Err(); err != nil {
+		t.Fatalf("seed index: %v", err)
+	}
+}
+
+func TestMigrateRedisSessions(t *testing.T) {
+	fake, client := newFakeRedis(t)
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Now().UTC()
+
+	const (
+		withTTL    = "11111111-1111-4111-8111-111111111111"
+		withoutTTL = "22222222-2222-4222-8222-222222222222"
+		expired    = "33333333-3333-4333-8333-333333333333"
+	)
+	seedLegacySession(t, client, withTTL, domain.UserSession{UserID: 1, CreatedAt: now.Add(-time.Hour), ExpiredAt: now.Add(time.Hour)}, time.Hour)
+	seedLegacySession(t, client, withoutTTL, domain.UserSession{UserID: 1, CreatedAt: now, ExpiredAt: now.Add(2 * time.Hour)}, 0)
+	seedLegacySession(t, client, expired, domain.UserSession{UserID: 1, CreatedAt: now.Add(-3 * time.Hour), ExpiredAt: now.Add(-time.Hour)}, 0)
+
+	migrated, err := MigrateRedisSessions(ctx, client, logger)
+	if err != nil {
+		t.Fatalf("MigrateRedisSessions: %v", err)
+	}
+	if migrated != 2 {
+		t.Errorf("migrated = %d, want 2", migrated)
+	}
+	if keys := fake.Keys("session:*"); len(keys) != 0 {
+		t.Errorf("legacy keys left: %v", keys)
+	}
+
+	store := &redisSessionStore{redisClient: client, logger: logger}
+	for _, token := range []string{withTTL, withoutTTL} {
+		key := store.getSessionKey(tokenHash(token))
+		data, err := client.Get(ctx, key).Result()
+		if err != nil {
+			t.Fatalf("session %s was not moved under its hash: %v", token, err)
+		}
+		if strings.Contains(data, token) {
+			t.Errorf("stored session contains its token: %s", data)
+		}
+		// Ключ без срока получает срок сессии
+		if ttl := fake.ttl(key); ttl <= 0 || ttl > 2*time.Hour {
+			t.Errorf("ttl of %s = %v, want the session lifetime", token, ttl)
+		}
+	}
+	if keys := fake.Keys("session_sha256:" + tokenHash(expired)); len(keys) != 0 {
+		t.Errorf("expired session was moved: %v", keys)
+	}
+
+	members, err := client.ZRange(ctx, "user_sessions:1", 0, -1).Result()
+	if err != nil {
+		t.Fatalf("ZRange: %v", err)
+	}
+	if want := []string{tokenHash(withTTL), tokenHash(withoutTTL)}; strings.Join(members, ",") != strings.Join(want, ",") {
+		t.Errorf("index = %v, want hashes %v", members, want)
+	}
+	if ttl := fake.ttl("user_sessions:1"); ttl < time.Hour || ttl > 2*time.Hour {
+		t.Errorf("index ttl = %v, want until the latest session expires", ttl)
+	}
+
+	// Перенесенная сессия доступна по токену, повторный запуск ничего не меняет
+	session, err := store.GetSession(ctx, withoutTTL)
+	if err != nil || session.UserID != 1 || session.SessionToken != withoutTTL {
+		t.Fatalf("GetSession = %+v, %v", session, err)
+	}
+	if migrated, err := MigrateRedisSessions(ctx, client, logger); err != nil || migrated != 0 {
+		t.Errorf("second run = %d, %v; want 0, nil", migrated, err)
+	}
+}
+
+func TestGetSessionMovesLegacySession(t *testing.T) {
+	fake, client := newFakeRedis(t)
+	ctx := context.Background()
+	store := &redisSessionStore{redisClient: client, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	now := time.Now().UTC()
+	const token = "44444444-4444-4444-8444-444444444444"
+	seedLegacySession(t, client, token, domain.UserSession{UserID: 1, CreatedAt: now, ExpiredAt: now.Add(time.Hour)}, time.Hour)
+
+	session, err := store.GetSession(ctx, token)
+	if err != nil || session.UserID != 1 || session.SessionToken != token {
+		t.Fatalf("GetSession = %+v, %v", session, err)
+	}
+	if keys := fake.Keys("session:*"); len(keys) != 0 {
+		t.Errorf("legacy keys left after read: %v", keys)
+	}
+
+	sessions, err := store.GetUserSessions(ctx, 1)
+	if err != nil || len(sessions) != 1 || sessions[0].SessionToken != sessionRefPrefix+tokenHash(token) {
+		t.Fatalf("GetUserSessions = %+v, %v", sessions, err)
+	}
+	// Ссылка из списка сессий завершает сессию, как и токен
+	if err := store.DeleteSession(ctx, sessions[0].SessionToken); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	if _, err := store.GetSession(ctx, token); err != ErrSessionNotFound {
+		t.Errorf("GetSession after delete: error = %v, want ErrSessionNotFound", err)
+	}
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// GetUserByAPIKeyHash получает активный сервисный аккаунт по хешу API-ключа
+func (r *authRepository) GetUserByAPIKeyHash(ctx context.Context, apiKeyHash string) (*domain.User, error) {
+	var user domain.User
+	err := r.DB().WithContext(ctx).
+		Where("api_key_hash = ? AND type = ? AND is_active = ?", apiKeyHash, domain.UserTypeService, true).
+		First(&user).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.Logger().ErrorContext(ctx, "Failed to get service account by API key", slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetServiceAccounts возвращает все сервисные аккаунты, включая отключенные
+func (r *authRepository) GetServiceAccounts(ctx context.Context) ([]domain.User, error) {
+	var users []domain.User
+	if err := r.DB().WithContext(ctx).Where("type = ?", domain.UserTypeService).Order("id").Find(&users).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to get service accounts", slog.Any("error", err))
+		return nil, err
+	}
+	return users, nil
+}
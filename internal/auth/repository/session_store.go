@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+)
+
+var (
+	ErrSessionNotFound = apperror.Unauthorized("session_not_found", "session not found")
+	ErrSessionExpired  = apperror.Unauthorized("session_expired", "session expired")
+	// ErrSessionStoreUnavailable - хранилище сессий не прошло последнюю проверку, не ответило вовремя
+	// или отключено автоматом после сбоев
+	ErrSessionStoreUnavailable = apperror.Unavailable("session_store_unavailable", "session store unavailable")
+)
+
+// SessionStore определяет интерфейс хранилища сессий пользователей.
+// Реализации: Redis (по умолчанию) и SQLite для небольших однонодовых установок.
+type SessionStore interface {
+	CreateSession(ctx context.Context, session *domain.UserSession) error
+	GetSession(ctx context.Context, sessionToken string) (*domain.UserSession, error)
+	DeleteSession(ctx context.Context, sessionToken string) error
+	// MigrateSession сохраняет session под новым токеном и оставляет прежний токен oldToken действующим
+	// еще grace, чтобы параллельные запросы со старым cookie не разлогинили пользователя
+	MigrateSession(ctx context.Context, oldToken string, session *domain.UserSession, grace time.Duration) error
+	DeleteAllUserSessions(ctx context.Context, userID uint) error
+	// GetUserSessions возвращает активные сессии пользователя, начиная с самой старой. Хранилище может
+	// не знать токенов, тогда SessionToken содержит ссылку на сессию, которую принимает только DeleteSession.
+	GetUserSessions(ctx context.Context, userID uint) ([]domain.UserSession, error)
+}
+
+// tokenHash возвращает SHA-256 токена сессии в hex
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// sessionLogAttr обозначает сессию в логе началом хеша токена: записи одной сессии можно сопоставить,
+// а токен из лога нельзя предъявить серверу
+func sessionLogAttr(token string) slog.Attr {
+	return slog.String("session", tokenHash(token)[:12])
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"rim/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type sqliteSessionStore struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteSessionStore создает хранилище сессий в таблице user_sessions SQLite.
+// Используется вместо Redis в небольших однонодовых установках (SESSION_STORE=sqlite).
+func NewSQLiteSessionStore(db *gorm.DB, logger *slog.Logger) SessionStore {
+	return &sqliteSessionStore{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateSession сохраняет сессию в SQLite и удаляет истекшие сессии
+func (s *sqliteSessionStore) CreateSession(ctx context.Context, session *domain.UserSession) error {
+	// В отличие от Redis, в SQLite нет TTL, поэтому чистим истекшие записи при создании новых
+	if err := s.db.WithContext(ctx).Where("expired_at < ?", time.Now().UTC()).Delete(&domain.UserSession{}).Error; err != nil {
+		s.logger.WarnContext(ctx, "Failed to purge expired sessions from SQLite", slog.Any("error", err))
+	}
+
+	if err := s.db.WithContext(ctx).Create(session).Error; err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create session in SQLite", sessionLogAttr(session.SessionToken), slog.Any("error", err))
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "Session created successfully", sessionLogAttr(session.SessionToken), slog.Uint64("user_id", uint64(session.UserID)))
+	return nil
+}
+
+// MigrateSession сохраняет сессию под новым токеном и переносит истечение прежнего токена на grace от текущего момента
+func (s *sqliteSessionStore) MigrateSession(ctx context.Context, oldToken string, session *domain.UserSession, grace time.Duration) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.UserSession{}).Where("session_token = ?", oldToken).
+			Update("expired_at", time.Now().UTC().Add(grace)).Error
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to migrate session in SQLite", slog.Uint64("user_id", uint64(session.UserID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// GetSession получает сессию из SQLite
+func (s *sqliteSessionStore) GetSession(ctx context.Context, sessionToken string) (*domain.UserSession, error) {
+	var session domain.UserSession
+	if err := s.db.WithContext(ctx).Where("session_token = ?", sessionToken).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			s.logger.WarnContext(ctx, "Session not found", sessionLogAttr(sessionToken))
+			return nil, ErrSessionNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get session from SQLite", sessionLogAttr(sessionToken), slog.Any("error", err))
+		return nil, err
+	}
+
+	// Проверяем, не истекла ли сессия
+	if time.Now().After(session.ExpiredAt) {
+		s.logger.WarnContext(ctx, "Session expired", sessionLogAttr(sessionToken))
+		// Удаляем истекшую сессию
+		s.DeleteSession(ctx, sessionToken)
+		return nil, ErrSessionExpired
+	}
+
+	return &session, nil
+}
+
+// DeleteSession удаляет сессию из SQLite
+func (s *sqliteSessionStore) DeleteSession(ctx context.Context, sessionToken string) error {
+	if err := s.db.WithContext(ctx).Where("session_token = ?", sessionToken).Delete(&domain.UserSession{}).Error; err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete session from SQLite", sessionLogAttr(sessionToken), slog.Any("error", err))
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "Session deleted successfully", sessionLogAttr(sessionToken))
+	return nil
+}
+
+// GetUserSessions возвращает активные сессии пользователя, начиная с самой старой
+func (s *sqliteSessionStore) GetUserSessions(ctx context.Context, userID uint) ([]domain.UserSession, error) {
+	var sessions []domain.UserSession
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ? AND expired_at > ?", userID, time.Now().UTC()).
+		Order("created_at ASC").
+		Find(&sessions).Error; err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get user sessions from SQLite", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// DeleteAllUserSessions удаляет все сессии пользователя
+func (s *sqliteSessionStore) DeleteAllUserSessions(ctx context.Context, userID uint) error {
+	result := s.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&domain.UserSession{})
+	if result.Error != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete user sessions", slog.Uint64("user_id", uint64(userID)), slog.Any("error", result.Error))
+		return result.Error
+	}
+
+	s.logger.InfoContext(ctx, "All user sessions deleted", slog.Uint64("user_id", uint64(userID)), slog.Int64("count", result.RowsAffected))
+	return nil
+}
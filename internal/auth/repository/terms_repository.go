@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// AcceptTerms записывает принятие соглашения в журнал и запоминает версию у пользователя
+func (r *authRepository) AcceptTerms(ctx context.Context, acceptance *domain.TermsAcceptance) error {
+	err := r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(acceptance).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.User{}).Where("id = ?", acceptance.UserID).
+			UpdateColumn("accepted_terms_version", acceptance.Version).Error
+	})
+	if err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to save terms acceptance", slog.Uint64("user_id", uint64(acceptance.UserID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
@@ -3,32 +3,67 @@ package usecase
 import (
 	"context"
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math/big"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	auditUseCase "rim/internal/audit/usecase"
 	"rim/internal/auth/repository"
 	contactRepo "rim/internal/contact/repository"
+	contactUseCase "rim/internal/contact/usecase"
 	"rim/internal/domain"
+	moderationRepo "rim/internal/moderation/repository"
+	systemUseCase "rim/internal/system/usecase"
+	"rim/pkg/apperror"
+	"rim/pkg/eventbus"
+	"rim/pkg/mailer"
+	"rim/pkg/sessiontoken"
+	"rim/pkg/sms"
+	"rim/pkg/tenant"
+	"rim/pkg/timeutil"
+	"rim/pkg/transaction"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 var (
-	ErrInvalidTelegramAuth = errors.New("invalid telegram authentication data")
-	ErrSessionNotFound     = errors.New("session not found")
-	ErrSessionExpired      = errors.New("session expired")
-	ErrUserNotFound        = errors.New("user not found")
-	ErrContactNotFound     = errors.New("contact not found")
+	ErrInvalidTelegramAuth = apperror.Unauthorized("invalid_telegram_auth", "invalid telegram authentication data")
+	ErrSessionNotFound     = repository.ErrSessionNotFound
+	ErrSessionExpired      = repository.ErrSessionExpired
+	ErrUserNotFound        = apperror.NotFound("user_not_found", "user not found")
+	ErrContactNotFound     = apperror.NotFound("contact_not_found", "contact not found")
+	ErrInvalidTimezone     = apperror.Invalid("invalid_timezone", "invalid timezone")
+	ErrInvalidLoginCode    = apperror.Unauthorized("invalid_login_code", "invalid login code")
+	ErrLoginCodeExpired    = apperror.Unauthorized("login_code_expired", "login code expired")
+	ErrUserBlocked         = apperror.Forbidden("user_blocked", "user is blocked")
+	// ErrSessionStoreUnavailable - хранилище сессий недоступно, запрос стоит повторить позже
+	ErrSessionStoreUnavailable = repository.ErrSessionStoreUnavailable
+	// ErrTermsVersionMismatch - принимаемая версия соглашения не совпадает с действующей или соглашение не требуется
+	ErrTermsVersionMismatch = apperror.Conflict("terms_version_mismatch", "terms version does not match the current terms of service")
 )
 
+// Параметры одноразовых кодов входа по телефону
+const (
+	loginCodeTTL            = 5 * time.Minute
+	loginCodeResendInterval = time.Minute
+	loginCodeMaxAttempts    = 5
+)
+
+// legacyTokenGrace - сколько прежний токен сессии действует после замены на токен текущей версии:
+// запросы, отправленные со старым cookie до получения нового, не должны завершать сессию
+const legacyTokenGrace = time.Minute
+
+// adminGroupName - группа, участники которой получают роль admin в организации своего контакта
+const adminGroupName = "Администраторы"
+
 // TelegramAuthData представляет данные авторизации от Telegram
 type TelegramAuthData struct {
 	ID        int64  `json:"id"`
@@ -42,12 +77,51 @@ type TelegramAuthData struct {
 
 // UseCase определяет интерфейс для auth бизнес-логики
 type UseCase interface {
-	AuthenticateWithTelegram(ctx context.Context, authData TelegramAuthData, botToken string) (*domain.UserSession, error)
+	AuthenticateWithTelegram(ctx context.Context, authData TelegramAuthData, botToken string, device DeviceInfo) (*domain.UserSession, error)
 	GetUserBySession(ctx context.Context, sessionToken string) (*domain.User, error)
-	GetContactByTelegramID(ctx context.Context, telegramID int64) (*domain.Contact, error)
+	// GetUserByTelegramID возвращает активного пользователя, вошедшего через Telegram; используется ботом
+	GetUserByTelegramID(ctx context.Context, telegramID int64) (*domain.User, error)
+	RequestPhoneLoginCode(ctx context.Context, phone string) error
+	AuthenticateWithPhone(ctx context.Context, phone, code string, device DeviceInfo) (*domain.UserSession, error)
+	GetUserContact(ctx context.Context, user *domain.User) (*domain.Contact, error)
+	// GetUserPhotoURL возвращает исходный адрес фото пользователя (пустой, если фото нет)
+	GetUserPhotoURL(ctx context.Context, userID uint) (string, error)
+	// GetContactPhotoURL возвращает пользователя контакта и исходный адрес его фото (пустой, если фото нет)
+	GetContactPhotoURL(ctx context.Context, contactID uint) (uint, string, error)
 	IsUserAdmin(ctx context.Context, userID uint) (bool, error)
-	UpdateUserContact(ctx context.Context, userID uint, contactData UpdateUserContactData) (*domain.Contact, error)
+	IsUserSuperAdmin(ctx context.Context, userID uint) (bool, error)
+	UpdateUserContact(ctx context.Context, userID uint, contactData UpdateUserContactData) (*domain.Contact, *domain.ChangeRequest, error)
+	// ResendEmailVerification повторно отправляет ссылку подтверждения на email контакта пользователя
+	ResendEmailVerification(ctx context.Context, userID uint) error
+	// VerifyEmail подтверждает email контакта по токену из ссылки, отправленной письмом
+	VerifyEmail(ctx context.Context, token string) (*domain.Contact, error)
+	SetUserTimezone(ctx context.Context, userID uint, timezone string) (*domain.User, error)
+	// PendingTerms возвращает действующее соглашение, если пользователь еще не принял его версию
+	PendingTerms(ctx context.Context, user *domain.User) (domain.TermsOfService, bool)
+	// AcceptTerms записывает принятие пользователем действующей версии соглашения version
+	AcceptTerms(ctx context.Context, userID uint, version, ipAddress, userAgent string) error
 	Logout(ctx context.Context, sessionToken string) error
+	// MigrateSessionToken заменяет токен прежней версии на токен текущей версии для той же сессии.
+	// Для токена текущей версии возвращает nil без ошибки.
+	MigrateSessionToken(ctx context.Context, sessionToken string) (*domain.UserSession, error)
+
+	// GetUsers возвращает пользователей; inactiveDays > 0 оставляет только тех, кто не входил столько дней
+	GetUsers(ctx context.Context, inactiveDays int) ([]domain.User, error)
+	// SetContactBlocked блокирует или разблокирует контакт. При блокировке сессии его пользователя завершаются,
+	// а новые входы отклоняются с записью в журнал аудита. actorID - пользователь, выполняющий действие.
+	SetContactBlocked(ctx context.Context, contactID uint, blocked bool, reason string, actorID *uint) (*domain.Contact, error)
+
+	// Сервисные аккаунты
+	CreateServiceAccount(ctx context.Context, name, role string) (*domain.User, string, error)
+	GetServiceAccounts(ctx context.Context) ([]domain.User, error)
+	RotateServiceAccountKey(ctx context.Context, id uint) (*domain.User, string, error)
+	SetServiceAccountActive(ctx context.Context, id uint, active bool) (*domain.User, error)
+	AuthenticateAPIKey(ctx context.Context, apiKey string) (*domain.User, error)
+
+	// Устройства пользователя
+	GetUserDevices(ctx context.Context, userID uint) ([]domain.UserDevice, error)
+	UpdateUserDevice(ctx context.Context, userID, deviceID uint, data UpdateDeviceData) (*domain.UserDevice, error)
+	RevokeUserDevice(ctx context.Context, userID, deviceID uint) error
 }
 
 // UpdateUserContactData определяет данные для обновления контакта пользователя
@@ -64,22 +138,46 @@ type UpdateUserContactData struct {
 }
 
 type authUseCase struct {
-	authRepo    repository.Repository
-	contactRepo contactRepo.Repository
-	logger      *slog.Logger
+	authRepo          repository.Repository
+	contactRepo       contactRepo.Repository
+	changeRequestRepo moderationRepo.Repository
+	systemUseCase     systemUseCase.UseCase
+	events            eventbus.Publisher  // Вход пользователя и изменение им своего контакта
+	tx                transaction.Manager // Изменение контакта и обработка событий о нем выполняются вместе
+	smsSender         sms.Sender
+	mailSender        mailer.Sender
+	portalURL         string // Адрес портала, от которого строятся ссылки подтверждения email
+	auditor           auditUseCase.UseCase
+	adminTelegramIDs  map[int64]struct{}
+	logger            *slog.Logger
 }
 
-// NewAuthUseCase создает новый экземпляр auth usecase
-func NewAuthUseCase(authRepo repository.Repository, contactRepo contactRepo.Repository, logger *slog.Logger) UseCase {
+// NewAuthUseCase создает новый экземпляр auth usecase.
+// adminTelegramIDs - Telegram ID пользователей, которые являются администраторами независимо от групп.
+// mailSender отправляет ссылки подтверждения email, которые ведут на portalURL.
+func NewAuthUseCase(authRepo repository.Repository, contactRepo contactRepo.Repository, changeRequestRepo moderationRepo.Repository, sysUseCase systemUseCase.UseCase, events eventbus.Publisher, tx transaction.Manager, smsSender sms.Sender, mailSender mailer.Sender, portalURL string, auditor auditUseCase.UseCase, adminTelegramIDs []int64, logger *slog.Logger) UseCase {
+	admins := make(map[int64]struct{}, len(adminTelegramIDs))
+	for _, id := range adminTelegramIDs {
+		admins[id] = struct{}{}
+	}
 	return &authUseCase{
-		authRepo:    authRepo,
-		contactRepo: contactRepo,
-		logger:      logger,
+		authRepo:          authRepo,
+		contactRepo:       contactRepo,
+		changeRequestRepo: changeRequestRepo,
+		systemUseCase:     sysUseCase,
+		events:            events,
+		tx:                tx,
+		smsSender:         smsSender,
+		mailSender:        mailSender,
+		portalURL:         portalURL,
+		auditor:           auditor,
+		adminTelegramIDs:  admins,
+		logger:            logger,
 	}
 }
 
 // AuthenticateWithTelegram аутентифицирует пользователя через Telegram
-func (uc *authUseCase) AuthenticateWithTelegram(ctx context.Context, authData TelegramAuthData, botToken string) (*domain.UserSession, error) {
+func (uc *authUseCase) AuthenticateWithTelegram(ctx context.Context, authData TelegramAuthData, botToken string, device DeviceInfo) (*domain.UserSession, error) {
 	// Проверяем подлинность данных от Telegram
 	if !uc.verifyTelegramAuth(authData, botToken) {
 		uc.logger.WarnContext(ctx, "Invalid telegram authentication", slog.Int64("telegram_id", authData.ID))
@@ -93,17 +191,29 @@ func (uc *authUseCase) AuthenticateWithTelegram(ctx context.Context, authData Te
 		return nil, err
 	}
 
-	// Если пользователь не найден, ищем контакт по Telegram ID
-	if user == nil {
-		contact, err := uc.contactRepo.GetByTelegramID(ctx, authData.ID)
+	// Контакт пользователя, а если пользователь не связан с контактом - контакт с этим Telegram ID
+	var contact *domain.Contact
+	if user != nil {
+		contact = user.Contact
+	}
+	if contact == nil {
+		contact, err = uc.contactRepo.GetByTelegramID(ctx, authData.ID)
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 			uc.logger.ErrorContext(ctx, "Failed to get contact by telegram ID", slog.Int64("telegram_id", authData.ID), slog.Any("error", err))
 			return nil, err
 		}
+	}
+	// Заблокированному контакту пользователь не создается
+	if err := uc.rejectBlocked(ctx, contact, user, authData.ID, device); err != nil {
+		return nil, err
+	}
 
+	// Если пользователь не найден, создаем его и связываем с найденным контактом
+	if user == nil {
 		// Создаем нового пользователя
 		user = &domain.User{
 			TelegramID: authData.ID,
+			PhotoURL:   authData.PhotoURL,
 			IsActive:   true,
 		}
 
@@ -127,13 +237,201 @@ func (uc *authUseCase) AuthenticateWithTelegram(ctx context.Context, authData Te
 		return nil, ErrUserNotFound
 	}
 
-	// Создаем новую сессию
-	sessionToken := uuid.New().String()
+	// Ссылки на фото Telegram со временем меняются, сохраняем актуальную при каждом входе
+	if user.PhotoURL != authData.PhotoURL {
+		user.PhotoURL = authData.PhotoURL
+		if _, err := uc.authRepo.UpdateUser(ctx, user); err != nil {
+			uc.logger.WarnContext(ctx, "Failed to update user photo URL", slog.Uint64("user_id", uint64(user.ID)), slog.Any("error", err))
+		}
+	}
+
+	session, err := uc.createSession(ctx, user, device)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.InfoContext(ctx, "User authenticated successfully", slog.Uint64("user_id", uint64(user.ID)), slog.Int64("telegram_id", authData.ID))
+	return session, nil
+}
+
+// RequestPhoneLoginCode отправляет одноразовый код входа на телефон контакта.
+// Если контакт с таким телефоном не найден или код запрошен раньше loginCodeResendInterval, код не отправляется,
+// но ошибка не возвращается, чтобы по ответу нельзя было проверить наличие телефона в базе.
+func (uc *authUseCase) RequestPhoneLoginCode(ctx context.Context, phone string) error {
+	// Номер приводится к виду, в котором хранится, иначе другая запись того же номера обходила бы интервал отправки
+	phone = contactUseCase.NormalizePhone(phone)
+	if phone == "" {
+		uc.logger.WarnContext(ctx, "Login code requested for invalid phone")
+		return nil
+	}
+
+	contact, err := uc.contactRepo.GetByPhone(ctx, phone)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			uc.logger.WarnContext(ctx, "Login code requested for unknown phone")
+			return nil
+		}
+		return err
+	}
+	if contact.Blocked {
+		// Как и для неизвестного телефона, ответ не выдает, что контакт заблокирован
+		uc.logger.WarnContext(ctx, "Login code requested for blocked contact", slog.Uint64("contact_id", uint64(contact.ID)))
+		return nil
+	}
+
+	code, err := generateLoginCode()
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to generate login code", slog.Any("error", err))
+		return err
+	}
+
+	now := timeutil.Now()
+	loginCode := &domain.LoginCode{
+		Phone:     phone,
+		CodeHash:  hashLoginCode(code),
+		CreatedAt: now,
+		ExpiresAt: now.Add(loginCodeTTL),
+	}
+	// Интервал проверяется в том же запросе, что и замена кода, иначе одновременные запросы отправят несколько кодов
+	issued, err := uc.authRepo.IssueLoginCode(ctx, loginCode, now.Add(-loginCodeResendInterval))
+	if err != nil {
+		return err
+	}
+	if !issued {
+		uc.logger.InfoContext(ctx, "Login code requested too soon, not sent", slog.Uint64("contact_id", uint64(contact.ID)))
+		return nil
+	}
+
+	text := fmt.Sprintf("Код входа в RIM: %s. Никому его не сообщайте.", code)
+	if err := uc.smsSender.Send(ctx, phone, text); err != nil {
+		// Код без доставки бесполезен, удаляем его, чтобы можно было сразу запросить новый
+		_ = uc.authRepo.DeleteLoginCode(ctx, phone)
+		return err
+	}
+
+	uc.logger.InfoContext(ctx, "Login code sent")
+	return nil
+}
+
+// AuthenticateWithPhone проверяет одноразовый код и создает сессию для пользователя, связанного с контактом.
+// Пользователь создается при первом входе.
+func (uc *authUseCase) AuthenticateWithPhone(ctx context.Context, phone, code string, device DeviceInfo) (*domain.UserSession, error) {
+	phone = contactUseCase.NormalizePhone(phone)
+	code = strings.TrimSpace(code)
+
+	loginCode, err := uc.authRepo.GetLoginCode(ctx, phone)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidLoginCode
+		}
+		return nil, err
+	}
+
+	if timeutil.Now().After(loginCode.ExpiresAt) {
+		_ = uc.authRepo.DeleteLoginCode(ctx, phone)
+		return nil, ErrLoginCodeExpired
+	}
+
+	// Попытка засчитывается до сравнения кода одним условным UPDATE,
+	// чтобы одновременные попытки не превышали loginCodeMaxAttempts
+	claimed, err := uc.authRepo.ClaimLoginCodeAttempt(ctx, phone, loginCodeMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		_ = uc.authRepo.DeleteLoginCode(ctx, phone)
+		return nil, ErrLoginCodeExpired
+	}
+
+	if !hmac.Equal([]byte(hashLoginCode(code)), []byte(loginCode.CodeHash)) {
+		uc.logger.WarnContext(ctx, "Invalid login code entered")
+		return nil, ErrInvalidLoginCode
+	}
+
+	// Код одноразовый
+	if err := uc.authRepo.DeleteLoginCode(ctx, phone); err != nil {
+		return nil, err
+	}
+
+	contact, err := uc.contactRepo.GetByPhone(ctx, phone)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContactNotFound
+		}
+		return nil, err
+	}
+	if err := uc.rejectBlocked(ctx, contact, nil, 0, device); err != nil {
+		return nil, err
+	}
+
+	user, err := uc.findOrCreateContactUser(ctx, contact)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		uc.logger.WarnContext(ctx, "User is not active", slog.Uint64("user_id", uint64(user.ID)))
+		return nil, ErrUserNotFound
+	}
+
+	session, err := uc.createSession(ctx, user, device)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.InfoContext(ctx, "User authenticated with phone", slog.Uint64("user_id", uint64(user.ID)), slog.Uint64("contact_id", uint64(contact.ID)))
+	return session, nil
+}
+
+// findOrCreateContactUser находит пользователя контакта по contact_id или Telegram ID контакта,
+// иначе создает нового пользователя, связанного с контактом.
+func (uc *authUseCase) findOrCreateContactUser(ctx context.Context, contact *domain.Contact) (*domain.User, error) {
+	user, err := uc.authRepo.GetUserByContactID(ctx, contact.ID)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if contact.TelegramID != 0 {
+		user, err = uc.authRepo.GetUserByTelegramID(ctx, contact.TelegramID)
+		if err == nil {
+			user.ContactID = &contact.ID
+			return uc.authRepo.UpdateUser(ctx, user)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	user, err = uc.authRepo.CreateUser(ctx, &domain.User{
+		TelegramID: contact.TelegramID,
+		ContactID:  &contact.ID,
+		IsActive:   true,
+	})
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to create user for contact", slog.Uint64("contact_id", uint64(contact.ID)), slog.Any("error", err))
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "New user created", slog.Uint64("user_id", uint64(user.ID)), slog.Uint64("contact_id", uint64(contact.ID)))
+	return user, nil
+}
+
+// createSession регистрирует устройство входа, создает новую сессию пользователя и применяет лимит сессий
+func (uc *authUseCase) createSession(ctx context.Context, user *domain.User, device DeviceInfo) (*domain.UserSession, error) {
+	userDevice, err := uc.registerDevice(ctx, user, device)
+	if err != nil {
+		return nil, err
+	}
+
+	now := timeutil.Now()
 	session := &domain.UserSession{
-		SessionToken: sessionToken,
+		SessionToken: sessiontoken.New(),
 		UserID:       user.ID,
-		CreatedAt:    time.Now(),
-		ExpiredAt:    time.Now().Add(7 * 24 * time.Hour), // 7 дней
+		DeviceID:     userDevice.ID,
+		CreatedAt:    now,
+		ExpiredAt:    now.Add(7 * 24 * time.Hour), // 7 дней
 	}
 
 	if err := uc.authRepo.CreateSession(ctx, session); err != nil {
@@ -141,20 +439,62 @@ func (uc *authUseCase) AuthenticateWithTelegram(ctx context.Context, authData Te
 		return nil, err
 	}
 
-	uc.logger.InfoContext(ctx, "User authenticated successfully", slog.Uint64("user_id", uint64(user.ID)), slog.Int64("telegram_id", authData.ID))
+	// Время входа нужно только для отчетов о неактивных пользователях, поэтому ошибка не прерывает вход
+	if err := uc.authRepo.UpdateLastLogin(ctx, user.ID, now); err != nil {
+		uc.logger.WarnContext(ctx, "Failed to record last login", slog.Uint64("user_id", uint64(user.ID)), slog.Any("error", err))
+	}
+	user.LastLoginAt = &now
+
+	uc.enforceSessionLimit(ctx, user.ID)
+
+	// Сессия уже создана, поэтому ошибка подписчика не отменяет вход
+	event := domain.UserLoggedInEvent{User: user, DeviceID: userDevice.ID, IP: device.IP, UserAgent: device.UserAgent}
+	if err := uc.events.Publish(ctx, event); err != nil {
+		uc.logger.WarnContext(ctx, "Failed to handle login event", slog.Uint64("user_id", uint64(user.ID)), slog.Any("error", err))
+	}
 	return session, nil
 }
 
+// enforceSessionLimit завершает самые старые сессии пользователя сверх лимита max_sessions_per_user.
+// Ошибки не прерывают вход и только логируются.
+func (uc *authUseCase) enforceSessionLimit(ctx context.Context, userID uint) {
+	limit, err := uc.systemUseCase.GetMaxSessionsPerUser(ctx)
+	if err != nil {
+		uc.logger.WarnContext(ctx, "Failed to get sessions limit, skipping eviction", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		return
+	}
+	if limit <= 0 {
+		return
+	}
+
+	sessions, err := uc.authRepo.GetUserSessions(ctx, userID)
+	if err != nil {
+		uc.logger.WarnContext(ctx, "Failed to get user sessions, skipping eviction", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		return
+	}
+
+	// Сессии отсортированы от самой старой к самой новой
+	for i := 0; i < len(sessions)-limit; i++ {
+		if err := uc.authRepo.DeleteSession(ctx, sessions[i].SessionToken); err != nil {
+			uc.logger.WarnContext(ctx, "Failed to evict old session", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+			continue
+		}
+		uc.logger.InfoContext(ctx, "Evicted oldest session over limit", slog.Uint64("user_id", uint64(userID)), slog.Int("limit", limit))
+	}
+}
+
 // GetUserBySession получает пользователя по сессии
 func (uc *authUseCase) GetUserBySession(ctx context.Context, sessionToken string) (*domain.User, error) {
+	if _, err := sessiontoken.Version(sessionToken); err != nil {
+		return nil, ErrSessionNotFound
+	}
 	session, err := uc.authRepo.GetSession(ctx, sessionToken)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			return nil, ErrSessionNotFound
-		}
-		if strings.Contains(err.Error(), "expired") {
-			return nil, ErrSessionExpired
+		// Недоступность хранилища уже залогирована им и автоматом, который его отключает
+		if errors.Is(err, ErrSessionNotFound) || errors.Is(err, ErrSessionExpired) || errors.Is(err, ErrSessionStoreUnavailable) {
+			return nil, err
 		}
+		uc.logger.ErrorContext(ctx, "Failed to get session", slog.Any("error", err))
 		return nil, err
 	}
 
@@ -172,164 +512,437 @@ func (uc *authUseCase) GetUserBySession(ctx context.Context, sessionToken string
 		return nil, ErrUserNotFound
 	}
 
+	// Сессии, созданные до учета устройств, не привязаны к устройству
+	if session.DeviceID != 0 {
+		_ = uc.authRepo.TouchDevice(ctx, session.DeviceID, timeutil.Now())
+	}
+
+	return user, nil
+}
+
+func (uc *authUseCase) GetUserByTelegramID(ctx context.Context, telegramID int64) (*domain.User, error) {
+	if telegramID == 0 {
+		return nil, ErrUserNotFound
+	}
+	user, err := uc.authRepo.GetUserByTelegramID(ctx, telegramID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
 	return user, nil
 }
 
-// GetContactByTelegramID получает контакт по telegram_id пользователя
-func (uc *authUseCase) GetContactByTelegramID(ctx context.Context, telegramID int64) (*domain.Contact, error) {
-	contact, err := uc.contactRepo.GetByTelegramID(ctx, telegramID)
+// GetUserContact получает контакт пользователя
+func (uc *authUseCase) GetUserContact(ctx context.Context, user *domain.User) (*domain.Contact, error) {
+	contact, err := uc.findUserContact(ctx, user)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrContactNotFound
 		}
-		uc.logger.ErrorContext(ctx, "Failed to get contact by telegram ID", slog.Int64("telegram_id", telegramID), slog.Any("error", err))
+		uc.logger.ErrorContext(ctx, "Failed to get user contact", slog.Uint64("user_id", uint64(user.ID)), slog.Any("error", err))
 		return nil, err
 	}
 	return contact, nil
 }
 
-// IsUserAdmin проверяет принадлежит ли пользователь к группе "Администраторы"
-func (uc *authUseCase) IsUserAdmin(ctx context.Context, userID uint) (bool, error) {
-	// Получаем пользователя с контактом
+func (uc *authUseCase) GetUserPhotoURL(ctx context.Context, userID uint) (string, error) {
 	user, err := uc.authRepo.GetUserByID(ctx, userID)
 	if err != nil {
-		uc.logger.ErrorContext(ctx, "Failed to get user for admin check", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
-		return false, err
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrUserNotFound
+		}
+		return "", err
 	}
+	return user.PhotoURL, nil
+}
 
-	// Ищем контакт по telegram_id
-	contact, err := uc.contactRepo.GetByTelegramID(ctx, user.TelegramID)
+func (uc *authUseCase) GetContactPhotoURL(ctx context.Context, contactID uint) (uint, string, error) {
+	user, err := uc.authRepo.GetUserByContactID(ctx, contactID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, nil // Нет контакта - не администратор
+			return 0, "", ErrUserNotFound
 		}
-		uc.logger.ErrorContext(ctx, "Failed to get contact for admin check", slog.Int64("telegram_id", user.TelegramID), slog.Any("error", err))
+		return 0, "", err
+	}
+	return user.ID, user.PhotoURL, nil
+}
+
+// findUserContact ищет контакт пользователя по связи contact_id, а для старых пользователей - по Telegram ID.
+// Возвращает gorm.ErrRecordNotFound, если контакт не найден.
+func (uc *authUseCase) findUserContact(ctx context.Context, user *domain.User) (*domain.Contact, error) {
+	// Собственный контакт доступен пользователю в любой организации
+	ctx = tenant.Unscoped(ctx)
+	if user.ContactID != nil {
+		return uc.contactRepo.GetByID(ctx, *user.ContactID)
+	}
+	if user.TelegramID != 0 {
+		return uc.contactRepo.GetByTelegramID(ctx, user.TelegramID)
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// IsUserSuperAdmin проверяет, является ли пользователь суперадминистратором.
+// Суперадминистраторы задаются только конфигурацией (ADMIN_TELEGRAM_IDS) и не зависят от групп и отладочного режима.
+func (uc *authUseCase) IsUserSuperAdmin(ctx context.Context, userID uint) (bool, error) {
+	user, err := uc.authRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to get user for superadmin check", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
 		return false, err
 	}
 
-	// Логируем информацию о группах контакта для отладки
-	groupNames := make([]string, len(contact.Groups))
-	for i, group := range contact.Groups {
-		groupNames[i] = group.Name
+	if user.IsServiceAccount() {
+		return false, nil
 	}
-	uc.logger.InfoContext(ctx, "Contact groups for admin check",
-		slog.Int64("telegram_id", user.TelegramID),
-		slog.Uint64("contact_id", uint64(contact.ID)),
-		slog.Any("groups", groupNames))
+	_, ok := uc.adminTelegramIDs[user.TelegramID]
+	return ok, nil
+}
 
-	// Проверяем есть ли группа "Администраторы"
-	for _, group := range contact.Groups {
-		if group.Name == "Администраторы" {
-			uc.logger.InfoContext(ctx, "User is admin", slog.Uint64("user_id", uint64(userID)))
-			return true, nil
-		}
+// IsUserAdmin проверяет принадлежит ли пользователь к группе "Администраторы"
+func (uc *authUseCase) IsUserAdmin(ctx context.Context, userID uint) (bool, error) {
+	user, err := uc.authRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to get user for admin check", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		return false, err
+	}
+
+	// Права сервисного аккаунта определяются его ролью
+	if user.IsServiceAccount() {
+		return user.Role == domain.RoleAdmin, nil
+	}
+
+	// Администраторы из конфигурации (ADMIN_TELEGRAM_IDS) не требуют членства в группе
+	if _, ok := uc.adminTelegramIDs[user.TelegramID]; ok {
+		uc.logger.InfoContext(ctx, "User is admin by configuration", slog.Uint64("user_id", uint64(userID)), slog.Int64("telegram_id", user.TelegramID))
+		return true, nil
+	}
+
+	// Группа "Администраторы" дает права только в организации контакта
+	isAdmin, err := uc.authRepo.IsInGroupNamed(ctx, user, adminGroupName, tenant.FromContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	if isAdmin {
+		uc.logger.InfoContext(ctx, "User is admin", slog.Uint64("user_id", uint64(userID)))
+		return true, nil
 	}
 
 	uc.logger.InfoContext(ctx, "User is not admin", slog.Uint64("user_id", uint64(userID)))
 	return false, nil
 }
 
-// UpdateUserContact обновляет контакт пользователя
-func (uc *authUseCase) UpdateUserContact(ctx context.Context, userID uint, contactData UpdateUserContactData) (*domain.Contact, error) {
+// UpdateUserContact обновляет контакт пользователя.
+// Изменения полей из модерируемых групп (настройка moderated_contact_field_groups) не применяются сразу,
+// а попадают в заявку на одобрение, которая возвращается вторым значением (nil, если таких изменений нет).
+func (uc *authUseCase) UpdateUserContact(ctx context.Context, userID uint, contactData UpdateUserContactData) (*domain.Contact, *domain.ChangeRequest, error) {
 	// Получаем пользователя
 	user, err := uc.authRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		uc.logger.ErrorContext(ctx, "Failed to get user for contact update", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Ищем контакт по telegram_id
-	contact, err := uc.contactRepo.GetByTelegramID(ctx, user.TelegramID)
+	contact, err := uc.findUserContact(ctx, user)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrContactNotFound
+			return nil, nil, ErrContactNotFound
 		}
 		uc.logger.ErrorContext(ctx, "Failed to get contact for update", slog.Int64("telegram_id", user.TelegramID), slog.Any("error", err))
-		return nil, err
+		return nil, nil, err
+	}
+
+	changes, err := uc.contactChanges(ctx, contact, contactData)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(changes) == 0 {
+		return contact, nil, nil
+	}
+
+	moderatedGroups, err := uc.systemUseCase.GetModeratedFieldGroups(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	moderated := make(map[string]bool, len(moderatedGroups))
+	for _, group := range moderatedGroups {
+		moderated[group] = true
+	}
+
+	direct := map[string]string{}
+	pending := map[string]string{}
+	for field, value := range changes {
+		if moderated[domain.ContactFieldGroup(field)] {
+			pending[field] = value
+		} else {
+			direct[field] = value
+		}
+	}
+
+	var request *domain.ChangeRequest
+	if len(pending) > 0 {
+		request, err = uc.submitChangeRequest(ctx, user.ID, contact, pending)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
-	// Обновляем поля контакта
-	changed := false
-	if contactData.Name != nil {
-		name := strings.TrimSpace(*contactData.Name)
-		if name != "" && contact.Name != name {
-			contact.Name = name
-			changed = true
+	if len(direct) > 0 {
+		previous := make(map[string]string, len(direct))
+		for field, value := range direct {
+			previous[field] = contactField(contact, field)
+			setContactField(contact, field, value)
+		}
+		contact.UpdatedBy = &user.ID
+		err := uc.tx.Run(ctx, func(ctx context.Context) error {
+			if err := uc.contactRepo.Update(ctx, contact); err != nil {
+				uc.logger.ErrorContext(ctx, "Failed to update user contact", slog.Uint64("contact_id", uint64(contact.ID)), slog.Any("error", err))
+				return err
+			}
+			for _, field := range []string{"name", "phone", "email"} {
+				if value, ok := direct[field]; ok {
+					event := domain.ContactChangedEvent{Contact: contact, Field: field, OldValue: previous[field], NewValue: value}
+					if err := uc.events.Publish(ctx, event); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := direct["email"]; ok {
+			// Контакт уже обновлен: если письмо не ушло, ссылку можно запросить повторно
+			if err := uc.sendEmailVerification(ctx, contact); err != nil {
+				uc.logger.WarnContext(ctx, "Failed to send email verification", slog.Uint64("contact_id", uint64(contact.ID)), slog.Any("error", err))
+			}
+		}
+	}
+
+	return contact, request, nil
+}
+
+// contactChanges возвращает поля, значения которых отличаются от текущих, в строковом виде.
+// Пустые имя, телефон и email игнорируются; занятые другим контактом телефон и email возвращают ошибку.
+func (uc *authUseCase) contactChanges(ctx context.Context, contact *domain.Contact, data UpdateUserContactData) (map[string]string, error) {
+	changes := map[string]string{}
+	if data.Name != nil {
+		if name := strings.TrimSpace(*data.Name); name != "" && contact.Name != name {
+			changes["name"] = name
 		}
 	}
-	if contactData.Email != nil {
-		email := strings.TrimSpace(*contactData.Email)
-		if email != "" && contact.Email != email {
+	if data.Email != nil {
+		if email := strings.TrimSpace(*data.Email); email != "" && contact.Email != email {
 			// Проверка уникальности email
 			existingByEmail, err := uc.contactRepo.GetByEmail(ctx, email)
 			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 				return nil, err
 			}
 			if existingByEmail != nil && existingByEmail.ID != contact.ID {
-				return nil, errors.New("email already exists")
+				return nil, contactRepo.ErrDuplicateEmail
 			}
-			contact.Email = email
-			changed = true
+			changes["email"] = email
 		}
 	}
-	if contactData.Phone != nil {
-		phone := strings.TrimSpace(*contactData.Phone)
-		if phone != "" && contact.Phone != phone {
+	if data.Phone != nil {
+		if phone := strings.TrimSpace(*data.Phone); phone != "" && contact.Phone != phone {
 			// Проверка уникальности phone
 			existingByPhone, err := uc.contactRepo.GetByPhone(ctx, phone)
 			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 				return nil, err
 			}
 			if existingByPhone != nil && existingByPhone.ID != contact.ID {
-				return nil, errors.New("phone already exists")
+				return nil, contactRepo.ErrDuplicatePhone
 			}
-			contact.Phone = phone
-			changed = true
+			changes["phone"] = phone
 		}
 	}
-	if contactData.Transport != nil && contact.Transport != *contactData.Transport {
-		contact.Transport = *contactData.Transport
-		changed = true
+	if data.Transport != nil && contact.Transport != *data.Transport {
+		changes["transport"] = *data.Transport
+	}
+	if data.Printer != nil && contact.Printer != *data.Printer {
+		changes["printer"] = *data.Printer
 	}
-	if contactData.Printer != nil && contact.Printer != *contactData.Printer {
-		contact.Printer = *contactData.Printer
-		changed = true
+	if data.Allergies != nil && contact.Allergies != *data.Allergies {
+		changes["allergies"] = *data.Allergies
 	}
-	if contactData.Allergies != nil && contact.Allergies != *contactData.Allergies {
-		contact.Allergies = *contactData.Allergies
-		changed = true
+	if data.VK != nil && contact.VK != *data.VK {
+		changes["vk"] = *data.VK
 	}
-	if contactData.VK != nil && contact.VK != *contactData.VK {
-		contact.VK = *contactData.VK
-		changed = true
+	if data.Telegram != nil && contact.Telegram != *data.Telegram {
+		changes["telegram"] = *data.Telegram
 	}
-	if contactData.Telegram != nil && contact.Telegram != *contactData.Telegram {
-		contact.Telegram = *contactData.Telegram
-		changed = true
+	if data.TelegramID != nil && contact.TelegramID != *data.TelegramID {
+		changes["telegram_id"] = strconv.FormatInt(*data.TelegramID, 10)
+	}
+	return changes, nil
+}
+
+// submitChangeRequest создает заявку на изменение контакта или дополняет уже ожидающую.
+func (uc *authUseCase) submitChangeRequest(ctx context.Context, userID uint, contact *domain.Contact, pending map[string]string) (*domain.ChangeRequest, error) {
+	request, err := uc.changeRequestRepo.GetPendingByContact(ctx, contact.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
 	}
-	if contactData.TelegramID != nil && contact.TelegramID != *contactData.TelegramID {
-		contact.TelegramID = *contactData.TelegramID
-		changed = true
+	if request == nil {
+		request = &domain.ChangeRequest{ContactID: contact.ID, Status: domain.ChangeRequestPending}
 	}
 
-	if !changed {
-		return contact, nil
+	changes := request.ChangeMap()
+	previous := request.PreviousMap()
+	for field, value := range pending {
+		changes[field] = value
+		if _, ok := previous[field]; !ok {
+			previous[field] = contactField(contact, field)
+		}
+	}
+	request.UserID = userID
+	if err := request.SetFields(changes, previous); err != nil {
+		return nil, err
 	}
 
-	// Обновляем контакт
-	if err := uc.contactRepo.Update(ctx, contact); err != nil {
-		uc.logger.ErrorContext(ctx, "Failed to update user contact", slog.Uint64("contact_id", uint64(contact.ID)), slog.Any("error", err))
+	if request.ID == 0 {
+		err = uc.changeRequestRepo.Create(ctx, request)
+	} else {
+		err = uc.changeRequestRepo.Update(ctx, request)
+	}
+	if err != nil {
 		return nil, err
 	}
+	uc.logger.InfoContext(ctx, "Contact change request submitted", slog.Uint64("request_id", uint64(request.ID)), slog.Uint64("contact_id", uint64(contact.ID)))
+	return request, nil
+}
 
-	return contact, nil
+// contactField возвращает значение поля контакта в строковом виде.
+func contactField(contact *domain.Contact, field string) string {
+	switch field {
+	case "name":
+		return contact.Name
+	case "phone":
+		return contact.Phone
+	case "email":
+		return contact.Email
+	case "transport":
+		return contact.Transport
+	case "printer":
+		return contact.Printer
+	case "allergies":
+		return contact.Allergies
+	case "vk":
+		return contact.VK
+	case "telegram":
+		return contact.Telegram
+	case "telegram_id":
+		return strconv.FormatInt(contact.TelegramID, 10)
+	}
+	return ""
+}
+
+// setContactField устанавливает значение поля контакта из строкового вида.
+func setContactField(contact *domain.Contact, field, value string) {
+	switch field {
+	case "name":
+		contact.Name = value
+	case "phone":
+		contact.Phone = value
+	case "email":
+		contact.Email = value
+	case "transport":
+		contact.Transport = value
+	case "printer":
+		contact.Printer = value
+	case "allergies":
+		contact.Allergies = value
+	case "vk":
+		contact.VK = value
+	case "telegram":
+		contact.Telegram = value
+	case "telegram_id":
+		if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+			contact.TelegramID = id
+		}
+	}
+}
+
+// SetUserTimezone сохраняет часовой пояс пользователя (имя IANA, например "Europe/Moscow").
+// Часовой пояс используется при отображении времени в ответах API.
+func (uc *authUseCase) SetUserTimezone(ctx context.Context, userID uint, timezone string) (*domain.User, error) {
+	timezone = strings.TrimSpace(timezone)
+	if _, err := timeutil.LoadLocation(timezone); err != nil || timezone == "" {
+		uc.logger.WarnContext(ctx, "Invalid timezone", slog.Uint64("user_id", uint64(userID)), slog.String("timezone", timezone))
+		return nil, ErrInvalidTimezone
+	}
+
+	user, err := uc.authRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		uc.logger.ErrorContext(ctx, "Failed to get user for timezone update", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		return nil, err
+	}
+
+	user.Timezone = timezone
+	user, err = uc.authRepo.UpdateUser(ctx, user)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to update user timezone", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		return nil, err
+	}
+
+	uc.logger.InfoContext(ctx, "User timezone updated", slog.Uint64("user_id", uint64(userID)), slog.String("timezone", timezone))
+	return user, nil
+}
+
+// MigrateSessionToken переносит сессию с токеном прежней версии на новый токен текущей версии.
+// Прежний токен действует еще legacyTokenGrace, чтобы не завершить сессию для запросов, отправленных со старым cookie.
+func (uc *authUseCase) MigrateSessionToken(ctx context.Context, sessionToken string) (*domain.UserSession, error) {
+	if sessiontoken.IsCurrent(sessionToken) {
+		return nil, nil
+	}
+	session, err := uc.authRepo.GetSession(ctx, sessionToken)
+	if err != nil {
+		return nil, err
+	}
+	// Токен уже заменен параллельным запросом и доживает отсрочку
+	if time.Until(session.ExpiredAt) <= legacyTokenGrace {
+		return nil, nil
+	}
+
+	// Сессия переносится без изменений: срок не продлевается, а время создания сохраняет порядок вытеснения
+	migrated := *session
+	migrated.SessionToken = sessiontoken.New()
+	if err := uc.authRepo.MigrateSession(ctx, sessionToken, &migrated, legacyTokenGrace); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Session token migrated to current version", slog.Uint64("user_id", uint64(session.UserID)), slog.Int("version", sessiontoken.Current))
+	return &migrated, nil
 }
 
 // Logout завершает сессию пользователя
 func (uc *authUseCase) Logout(ctx context.Context, sessionToken string) error {
+	// Значение, не похожее на токен, не может принадлежать сессии; хранилище принимает от клиента только токены
+	if _, err := sessiontoken.Version(sessionToken); err != nil {
+		return nil
+	}
 	return uc.authRepo.DeleteSession(ctx, sessionToken)
 }
 
+// generateLoginCode генерирует случайный шестизначный код
+func generateLoginCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// hashLoginCode возвращает SHA-256 хеш кода для хранения в БД
+func hashLoginCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
 // verifyTelegramAuth проверяет подлинность данных авторизации от Telegram
 func (uc *authUseCase) verifyTelegramAuth(authData TelegramAuthData, botToken string) bool {
 	// Добавляем логирование для диагностики
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"rim/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+func (uc *authUseCase) SetContactBlocked(ctx context.Context, contactID uint, blocked bool, reason string, actorID *uint) (*domain.Contact, error) {
+	contact, err := uc.contactRepo.GetByID(ctx, contactID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContactNotFound
+		}
+		return nil, err
+	}
+
+	if contact.Blocked != blocked {
+		if err := uc.contactRepo.SetBlocked(ctx, contactID, blocked); err != nil {
+			return nil, err
+		}
+		contact.Blocked = blocked
+
+		action := domain.AuditContactUnblocked
+		if blocked {
+			action = domain.AuditContactBlocked
+		}
+		uc.auditor.Record(ctx, domain.AuditEvent{
+			Action:     action,
+			ActorID:    actorID,
+			ContactID:  &contact.ID,
+			TelegramID: contact.TelegramID,
+			Details:    reason,
+		})
+		uc.logger.InfoContext(ctx, "Contact blocked flag changed", slog.Uint64("contact_id", uint64(contactID)), slog.Bool("blocked", blocked))
+	}
+
+	// Сессии завершаются и при повторной блокировке: так можно повторить запрос, если в прошлый раз это не удалось
+	if blocked {
+		if err := uc.revokeContactSessions(ctx, contact); err != nil {
+			return nil, err
+		}
+	}
+	return contact, nil
+}
+
+// revokeContactSessions завершает все сессии пользователей, связанных с контактом по contact_id или Telegram ID
+func (uc *authUseCase) revokeContactSessions(ctx context.Context, contact *domain.Contact) error {
+	var users []*domain.User
+	user, err := uc.authRepo.GetUserByContactID(ctx, contact.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if user != nil {
+		users = append(users, user)
+	}
+	if contact.TelegramID != 0 {
+		user, err := uc.authRepo.GetUserByTelegramID(ctx, contact.TelegramID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if user != nil && (len(users) == 0 || users[0].ID != user.ID) {
+			users = append(users, user)
+		}
+	}
+
+	for _, u := range users {
+		if err := uc.authRepo.DeleteAllUserSessions(ctx, u.ID); err != nil {
+			uc.logger.ErrorContext(ctx, "Failed to revoke sessions of blocked contact", slog.Uint64("contact_id", uint64(contact.ID)), slog.Uint64("user_id", uint64(u.ID)), slog.Any("error", err))
+			return err
+		}
+		uc.logger.InfoContext(ctx, "Sessions of blocked contact revoked", slog.Uint64("contact_id", uint64(contact.ID)), slog.Uint64("user_id", uint64(u.ID)))
+	}
+	return nil
+}
+
+// rejectBlocked возвращает ErrUserBlocked и записывает попытку входа в журнал аудита, если контакт заблокирован.
+// user - найденный пользователь (nil, если входит впервые); telegramID - ID входа через Telegram, 0 - вход по телефону.
+func (uc *authUseCase) rejectBlocked(ctx context.Context, contact *domain.Contact, user *domain.User, telegramID int64, device DeviceInfo) error {
+	if contact == nil || !contact.Blocked {
+		return nil
+	}
+	event := domain.AuditEvent{
+		Action:     domain.AuditLoginBlocked,
+		ContactID:  &contact.ID,
+		TelegramID: telegramID,
+		IP:         device.IP,
+		Details:    "phone login",
+	}
+	if telegramID != 0 {
+		event.Details = "telegram login"
+	}
+	if user != nil {
+		event.ActorID = &user.ID
+	}
+	uc.auditor.Record(ctx, event)
+	uc.logger.WarnContext(ctx, "Login rejected for blocked contact", slog.Uint64("contact_id", uint64(contact.ID)), slog.Int64("telegram_id", telegramID))
+	return ErrUserBlocked
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/timeutil"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDeviceNotFound   = apperror.NotFound("device_not_found", "device not found")
+	ErrDeviceNotTrusted = apperror.Forbidden("device_not_trusted", "device is not trusted, approve it from a trusted device and log in again")
+	ErrDeviceIDRequired = apperror.Invalid("device_id_required", "device id is required")
+)
+
+// maxDeviceLabelLength ограничивает длину названия устройства
+const maxDeviceLabelLength = 100
+
+// DeviceInfo описывает устройство, с которого выполняется вход
+type DeviceInfo struct {
+	ID        string // Случайный идентификатор из cookie device_id
+	UserAgent string
+	IP        string // Адрес клиента для журнала аудита
+}
+
+// UpdateDeviceData определяет изменяемые поля устройства
+type UpdateDeviceData struct {
+	Label   *string
+	Trusted *bool
+}
+
+// registerDevice находит или создает устройство входа и проверяет, можно ли с него войти.
+// Новые устройства администраторов требуют подтверждения, если включена настройка admin_device_approval
+// и у администратора уже есть доверенные устройства.
+func (uc *authUseCase) registerDevice(ctx context.Context, user *domain.User, info DeviceInfo) (*domain.UserDevice, error) {
+	if info.ID == "" {
+		return nil, ErrDeviceIDRequired
+	}
+	now := timeutil.Now()
+	fingerprint := hashDeviceID(info.ID)
+
+	device, err := uc.authRepo.GetDeviceByFingerprint(ctx, user.ID, fingerprint)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if device == nil {
+		trusted, err := uc.trustNewDevice(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		device = &domain.UserDevice{
+			UserID:          user.ID,
+			FingerprintHash: fingerprint,
+			Label:           truncate(info.UserAgent, maxDeviceLabelLength),
+			UserAgent:       info.UserAgent,
+			Trusted:         trusted,
+			LastSeenAt:      now,
+		}
+		if err := uc.authRepo.CreateDevice(ctx, device); err != nil {
+			return nil, err
+		}
+		uc.logger.InfoContext(ctx, "New device registered", slog.Uint64("user_id", uint64(user.ID)), slog.Uint64("device_id", uint64(device.ID)), slog.Bool("trusted", trusted))
+	} else {
+		device.UserAgent = info.UserAgent
+		device.LastSeenAt = now
+		if err := uc.authRepo.UpdateDevice(ctx, device); err != nil {
+			return nil, err
+		}
+	}
+
+	if !device.Trusted {
+		uc.logger.WarnContext(ctx, "Login from untrusted device rejected", slog.Uint64("user_id", uint64(user.ID)), slog.Uint64("device_id", uint64(device.ID)))
+		return nil, ErrDeviceNotTrusted
+	}
+	return device, nil
+}
+
+// trustNewDevice определяет, считается ли новое устройство пользователя доверенным сразу
+func (uc *authUseCase) trustNewDevice(ctx context.Context, user *domain.User) (bool, error) {
+	approval, err := uc.systemUseCase.GetAdminDeviceApproval(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !approval {
+		return true, nil
+	}
+
+	isAdmin, err := uc.IsUserAdmin(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	if !isAdmin {
+		return true, nil
+	}
+
+	// Первое устройство администратора доверенное, иначе подтвердить новое было бы неоткуда
+	trustedCount, err := uc.authRepo.CountTrustedDevices(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	return trustedCount == 0, nil
+}
+
+// GetUserDevices возвращает устройства пользователя
+func (uc *authUseCase) GetUserDevices(ctx context.Context, userID uint) ([]domain.UserDevice, error) {
+	return uc.authRepo.GetUserDevices(ctx, userID)
+}
+
+// UpdateUserDevice переименовывает устройство или меняет признак доверия
+func (uc *authUseCase) UpdateUserDevice(ctx context.Context, userID, deviceID uint, data UpdateDeviceData) (*domain.UserDevice, error) {
+	device, err := uc.authRepo.GetUserDevice(ctx, userID, deviceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeviceNotFound
+		}
+		return nil, err
+	}
+
+	if data.Label != nil {
+		device.Label = truncate(strings.TrimSpace(*data.Label), maxDeviceLabelLength)
+	}
+	if data.Trusted != nil {
+		device.Trusted = *data.Trusted
+	}
+
+	if err := uc.authRepo.UpdateDevice(ctx, device); err != nil {
+		return nil, err
+	}
+
+	// Сессии с устройства, с которого сняли доверие, завершаются
+	if data.Trusted != nil && !*data.Trusted {
+		uc.deleteDeviceSessions(ctx, userID, deviceID)
+	}
+
+	uc.logger.InfoContext(ctx, "User device updated", slog.Uint64("user_id", uint64(userID)), slog.Uint64("device_id", uint64(deviceID)), slog.Bool("trusted", device.Trusted))
+	return device, nil
+}
+
+// RevokeUserDevice завершает сессии устройства и удаляет его.
+// При следующем входе с этого устройства оно будет зарегистрировано заново.
+func (uc *authUseCase) RevokeUserDevice(ctx context.Context, userID, deviceID uint) error {
+	if _, err := uc.authRepo.GetUserDevice(ctx, userID, deviceID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDeviceNotFound
+		}
+		return err
+	}
+
+	uc.deleteDeviceSessions(ctx, userID, deviceID)
+
+	if err := uc.authRepo.DeleteDevice(ctx, deviceID); err != nil {
+		return err
+	}
+
+	uc.logger.InfoContext(ctx, "User device revoked", slog.Uint64("user_id", uint64(userID)), slog.Uint64("device_id", uint64(deviceID)))
+	return nil
+}
+
+// deleteDeviceSessions завершает сессии пользователя, открытые с устройства. Ошибки только логируются.
+func (uc *authUseCase) deleteDeviceSessions(ctx context.Context, userID, deviceID uint) {
+	sessions, err := uc.authRepo.GetUserSessions(ctx, userID)
+	if err != nil {
+		uc.logger.WarnContext(ctx, "Failed to get user sessions for device", slog.Uint64("device_id", uint64(deviceID)), slog.Any("error", err))
+		return
+	}
+	for _, session := range sessions {
+		if session.DeviceID != deviceID {
+			continue
+		}
+		if err := uc.authRepo.DeleteSession(ctx, session.SessionToken); err != nil {
+			uc.logger.WarnContext(ctx, "Failed to delete device session", slog.Uint64("device_id", uint64(deviceID)), slog.Any("error", err))
+		}
+	}
+}
+
+// hashDeviceID возвращает SHA-256 хеш идентификатора устройства
+func hashDeviceID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// truncate обрезает строку до max символов
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/timeutil"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmailMissing             = apperror.Invalid("contact_email_empty", "contact has no email")
+	ErrEmailAlreadyVerified     = apperror.Conflict("email_already_verified", "email is already verified")
+	ErrEmailVerificationTooSoon = apperror.Conflict("email_verification_too_soon", "verification email was requested too recently")
+	// ErrInvalidEmailVerification - токена нет, он заменен новой ссылкой или email контакта изменился после отправки
+	ErrInvalidEmailVerification = apperror.Invalid("invalid_email_verification", "invalid email verification link")
+	ErrEmailVerificationExpired = apperror.Invalid("email_verification_expired", "email verification link expired")
+)
+
+// Параметры ссылок подтверждения email
+const (
+	emailVerificationTTL            = 24 * time.Hour
+	emailVerificationResendInterval = time.Minute
+)
+
+// ResendEmailVerification повторно отправляет ссылку подтверждения на email контакта пользователя.
+// Прежняя ссылка перестает действовать.
+func (uc *authUseCase) ResendEmailVerification(ctx context.Context, userID uint) error {
+	user, err := uc.authRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	contact, err := uc.findUserContact(ctx, user)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrContactNotFound
+		}
+		return err
+	}
+	if contact.Email == "" {
+		return ErrEmailMissing
+	}
+	if contact.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	existing, err := uc.authRepo.GetEmailVerification(ctx, contact.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if existing != nil && timeutil.Now().Sub(existing.CreatedAt) < emailVerificationResendInterval {
+		return ErrEmailVerificationTooSoon
+	}
+	return uc.sendEmailVerification(ctx, contact)
+}
+
+// VerifyEmail подтверждает email контакта по токену из ссылки и возвращает контакт
+func (uc *authUseCase) VerifyEmail(ctx context.Context, token string) (*domain.Contact, error) {
+	if token == "" {
+		return nil, ErrInvalidEmailVerification
+	}
+	verification, err := uc.authRepo.GetEmailVerificationByTokenHash(ctx, hashVerificationToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidEmailVerification
+		}
+		return nil, err
+	}
+	if timeutil.Now().After(verification.ExpiresAt) {
+		_ = uc.authRepo.DeleteEmailVerification(ctx, verification.ContactID)
+		return nil, ErrEmailVerificationExpired
+	}
+
+	// Ссылка подтверждает только адрес, на который она отправлена
+	if err := uc.contactRepo.SetEmailVerified(ctx, verification.ContactID, verification.Email); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = uc.authRepo.DeleteEmailVerification(ctx, verification.ContactID)
+			return nil, ErrInvalidEmailVerification
+		}
+		return nil, err
+	}
+	if err := uc.authRepo.DeleteEmailVerification(ctx, verification.ContactID); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Contact email verified", slog.Uint64("contact_id", uint64(verification.ContactID)))
+
+	contact, err := uc.contactRepo.GetByID(ctx, verification.ContactID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContactNotFound
+		}
+		return nil, err
+	}
+	return contact, nil
+}
+
+// sendEmailVerification создает ссылку подтверждения email контакта, заменяя прежнюю, и отправляет ее письмом
+func (uc *authUseCase) sendEmailVerification(ctx context.Context, contact *domain.Contact) error {
+	token, err := generateVerificationToken()
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to generate email verification token", slog.Any("error", err))
+		return err
+	}
+	now := timeutil.Now()
+	verification := &domain.EmailVerification{
+		ContactID: contact.ID,
+		Email:     contact.Email,
+		TokenHash: hashVerificationToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(emailVerificationTTL),
+	}
+	if err := uc.authRepo.SaveEmailVerification(ctx, verification); err != nil {
+		return err
+	}
+
+	link := uc.portalURL + "/api/v1/auth/email/verify?token=" + token
+	text := fmt.Sprintf("Здравствуйте, %s!\n\nЧтобы подтвердить адрес %s в RIM, перейдите по ссылке:\n%s\n\nСсылка действует %d часа. Если вы не указывали этот адрес, просто проигнорируйте письмо.",
+		contact.Name, contact.Email, link, int(emailVerificationTTL.Hours()))
+	if err := uc.mailSender.Send(ctx, contact.Email, "Подтверждение email в RIM", text); err != nil {
+		// Ссылка без доставки бесполезна, удаляем ее, чтобы можно было сразу запросить новую
+		_ = uc.authRepo.DeleteEmailVerification(ctx, contact.ID)
+		return err
+	}
+
+	uc.logger.InfoContext(ctx, "Email verification sent", slog.Uint64("contact_id", uint64(contact.ID)))
+	return nil
+}
+
+// generateVerificationToken генерирует случайный токен ссылки подтверждения
+func generateVerificationToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashVerificationToken возвращает SHA-256 хеш токена для хранения в БД
+func hashVerificationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
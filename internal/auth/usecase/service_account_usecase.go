@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix отличает API-ключи сервисных аккаунтов от других токенов
+const APIKeyPrefix = "rsk_"
+
+var (
+	ErrInvalidAPIKey          = apperror.Unauthorized("invalid_api_key", "invalid API key")
+	ErrServiceAccountNotFound = apperror.NotFound("service_account_not_found", "service account not found")
+	ErrServiceAccountName     = apperror.Invalid("service_account_name_empty", "service account name cannot be empty")
+	ErrServiceAccountRole     = apperror.Invalid("invalid_service_account_role", "service account role must be a non-guest policy role")
+)
+
+// CreateServiceAccount создает сервисный аккаунт и возвращает его API-ключ.
+// Открытое значение ключа возвращается только здесь и при ротации.
+func (uc *authUseCase) CreateServiceAccount(ctx context.Context, name, role string) (*domain.User, string, error) {
+	name = strings.TrimSpace(name)
+	role = strings.TrimSpace(role)
+	if name == "" {
+		return nil, "", ErrServiceAccountName
+	}
+	if role == "" || role == domain.RoleGuest {
+		return nil, "", ErrServiceAccountRole
+	}
+
+	apiKey, err := generateAPIKey()
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to generate API key", slog.Any("error", err))
+		return nil, "", err
+	}
+
+	user, err := uc.authRepo.CreateUser(ctx, &domain.User{
+		Type:       domain.UserTypeService,
+		Name:       name,
+		Role:       role,
+		APIKeyHash: hashAPIKey(apiKey),
+		IsActive:   true,
+	})
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to create service account", slog.String("name", name), slog.Any("error", err))
+		return nil, "", err
+	}
+
+	uc.logger.InfoContext(ctx, "Service account created", slog.Uint64("user_id", uint64(user.ID)), slog.String("name", name), slog.String("role", role))
+	return user, apiKey, nil
+}
+
+// GetServiceAccounts возвращает все сервисные аккаунты
+func (uc *authUseCase) GetServiceAccounts(ctx context.Context) ([]domain.User, error) {
+	return uc.authRepo.GetServiceAccounts(ctx)
+}
+
+// RotateServiceAccountKey выдает сервисному аккаунту новый API-ключ; старый перестает действовать
+func (uc *authUseCase) RotateServiceAccountKey(ctx context.Context, id uint) (*domain.User, string, error) {
+	user, err := uc.getServiceAccount(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	apiKey, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	user.APIKeyHash = hashAPIKey(apiKey)
+
+	user, err = uc.authRepo.UpdateUser(ctx, user)
+	if err != nil {
+		return nil, "", err
+	}
+
+	uc.logger.InfoContext(ctx, "Service account API key rotated", slog.Uint64("user_id", uint64(id)))
+	return user, apiKey, nil
+}
+
+// SetServiceAccountActive включает или отключает сервисный аккаунт
+func (uc *authUseCase) SetServiceAccountActive(ctx context.Context, id uint, active bool) (*domain.User, error) {
+	user, err := uc.getServiceAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user.IsActive = active
+	user, err = uc.authRepo.UpdateUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.InfoContext(ctx, "Service account status changed", slog.Uint64("user_id", uint64(id)), slog.Bool("active", active))
+	return user, nil
+}
+
+// AuthenticateAPIKey возвращает активный сервисный аккаунт по API-ключу
+func (uc *authUseCase) AuthenticateAPIKey(ctx context.Context, apiKey string) (*domain.User, error) {
+	if !strings.HasPrefix(apiKey, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	user, err := uc.authRepo.GetUserByAPIKeyHash(ctx, hashAPIKey(apiKey))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			uc.logger.WarnContext(ctx, "Unknown or disabled API key used")
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	uc.logger.InfoContext(ctx, "Service account authenticated", slog.Uint64("user_id", uint64(user.ID)), slog.String("name", user.Name))
+	return user, nil
+}
+
+// getServiceAccount получает пользователя по ID, только если это сервисный аккаунт
+func (uc *authUseCase) getServiceAccount(ctx context.Context, id uint) (*domain.User, error) {
+	user, err := uc.authRepo.GetUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrServiceAccountNotFound
+		}
+		return nil, err
+	}
+	if !user.IsServiceAccount() {
+		return nil, ErrServiceAccountNotFound
+	}
+	return user, nil
+}
+
+// generateAPIKey генерирует случайный API-ключ с префиксом APIKeyPrefix
+func generateAPIKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return APIKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+// hashAPIKey возвращает SHA-256 хеш API-ключа для хранения в БД
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/timeutil"
+)
+
+func (uc *authUseCase) PendingTerms(ctx context.Context, user *domain.User) (domain.TermsOfService, bool) {
+	if user.IsServiceAccount() {
+		return domain.TermsOfService{}, false
+	}
+	terms := uc.systemUseCase.CurrentTermsOfService(ctx)
+	if terms.Version == "" || terms.Version == user.AcceptedTermsVersion {
+		return domain.TermsOfService{}, false
+	}
+	return terms, true
+}
+
+func (uc *authUseCase) AcceptTerms(ctx context.Context, userID uint, version, ipAddress, userAgent string) error {
+	// Пользователь принимает текст, который ему показали: если версия успела смениться, он должен увидеть новую
+	current := uc.systemUseCase.CurrentTermsOfService(ctx)
+	if current.Version == "" || current.Version != version {
+		return ErrTermsVersionMismatch
+	}
+
+	acceptance := &domain.TermsAcceptance{
+		UserID:     userID,
+		Version:    version,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		AcceptedAt: timeutil.Now(),
+	}
+	if err := uc.authRepo.AcceptTerms(ctx, acceptance); err != nil {
+		return err
+	}
+
+	uc.logger.InfoContext(ctx, "Terms of service accepted", slog.Uint64("user_id", uint64(userID)), slog.String("version", version))
+	return nil
+}
@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/timeutil"
+)
+
+// ErrInvalidInactiveDays возвращается при отрицательном периоде неактивности
+var ErrInvalidInactiveDays = apperror.Invalid("invalid_inactive_days", "inactive days must not be negative")
+
+// GetUsers возвращает пользователей-людей. Пользователи, не входившие ни разу, считаются неактивными.
+func (uc *authUseCase) GetUsers(ctx context.Context, inactiveDays int) ([]domain.User, error) {
+	if inactiveDays < 0 {
+		return nil, ErrInvalidInactiveDays
+	}
+	var inactiveSince *time.Time
+	if inactiveDays > 0 {
+		since := timeutil.Now().AddDate(0, 0, -inactiveDays)
+		inactiveSince = &since
+	}
+	return uc.authRepo.GetUsers(ctx, inactiveSince)
+}
@@ -0,0 +1,60 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"rim/internal/avatar/usecase"
+	contactUseCase "rim/internal/contact/usecase"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/pkg/requestctx"
+)
+
+// Handler отвечает за HTTP-запросы аватаров контактов.
+type Handler struct {
+	avatarUseCase usecase.UseCase
+	logger        *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для аватаров.
+func NewHandler(au usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		avatarUseCase: au,
+		logger:        logger,
+	}
+}
+
+// GetContactAvatar возвращает фото профиля Telegram контакта.
+// @Summary Аватар контакта
+// @Description Возвращает фото профиля Telegram контакта, сохраненное сервером. Адрес постоянный: фото обновляется фоновой задачей раз в сутки.
+// @Description Если роли недоступно поле контакта telegram_id, фото не отдается.
+// @Tags contacts
+// @Produce image/jpeg
+// @Param id path int true "ID контакта"
+// @Success 200 {file} binary "Фото"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт не найден или у него нет аватара"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/avatar [get]
+func (h *Handler) GetContactAvatar(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+
+	data, contentType, err := h.avatarUseCase.Avatar(c.Context(), uint(contactID), requestctx.Role(c))
+	if err != nil {
+		if errors.Is(err, contactUseCase.ErrContactNotFound) || errors.Is(err, usecase.ErrAvatarNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to get contact avatar", slog.Uint64("contactID", contactID), slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=3600")
+	return c.Send(data)
+}
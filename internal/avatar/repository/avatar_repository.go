@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+var ErrContactNotFound = errors.New("contact not found")
+
+// Repository определяет интерфейс хранения сведений об аватарах контактов
+type Repository interface {
+	// GetDue возвращает до limit контактов всех организаций, чье фото пора запросить у Telegram:
+	// с TelegramID, не проверявшихся с момента checkedBefore, а также отвязанных от Telegram, у которых остался аватар.
+	// Загружаются только ID, TelegramID и AvatarFileID.
+	GetDue(ctx context.Context, checkedBefore time.Time, limit int) ([]domain.Contact, error)
+	// SetAvatar сохраняет file_unique_id аватара контакта (пусто - аватара нет) и время проверки
+	SetAvatar(ctx context.Context, contactID uint, fileID string, checkedAt time.Time) error
+	// GetAvatar возвращает контакт организации запроса с полями TelegramID и AvatarFileID
+	GetAvatar(ctx context.Context, contactID uint) (*domain.Contact, error)
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр репозитория аватаров
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{db: db, logger: logger}
+}
+
+func (r *sqliteRepository) GetDue(ctx context.Context, checkedBefore time.Time, limit int) ([]domain.Contact, error) {
+	var contacts []domain.Contact
+	// Зашифрованные поля не загружаются: задаче нужен только Telegram ID
+	err := transaction.DB(ctx, r.db).Select("id", "telegram_id", "avatar_file_id").
+		Where("(telegram_id <> 0 AND (avatar_checked_at IS NULL OR avatar_checked_at < ?)) OR (telegram_id = 0 AND avatar_file_id <> '')", checkedBefore).
+		Order("avatar_checked_at IS NOT NULL, avatar_checked_at, id").
+		Limit(limit).
+		Find(&contacts).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error getting contacts with due avatars from DB", slog.Any("error", err))
+		return nil, err
+	}
+	return contacts, nil
+}
+
+func (r *sqliteRepository) SetAvatar(ctx context.Context, contactID uint, fileID string, checkedAt time.Time) error {
+	// UpdateColumns не меняет updated_at: обновление аватара не считается изменением контакта
+	err := transaction.DB(ctx, r.db).Model(&domain.Contact{}).Where("id = ?", contactID).
+		UpdateColumns(map[string]interface{}{"avatar_file_id": fileID, "avatar_checked_at": checkedAt}).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error saving contact avatar in DB", slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) GetAvatar(ctx context.Context, contactID uint) (*domain.Contact, error) {
+	var contact domain.Contact
+	err := transaction.DB(ctx, r.db).Select("id", "telegram_id", "avatar_file_id").
+		Scopes(tenant.Scope(ctx, "contacts")).
+		First(&contact, contactID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContactNotFound
+		}
+		r.logger.ErrorContext(ctx, "Error getting contact avatar from DB", slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return nil, err
+	}
+	return &contact, nil
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"rim/internal/avatar/repository"
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	"rim/pkg/photocache"
+	"rim/pkg/timeutil"
+)
+
+var ErrAvatarNotFound = errors.New("avatar not found")
+
+const (
+	// refreshAge - как часто фото одного контакта перепроверяется в Telegram
+	refreshAge = 24 * time.Hour
+	// syncBatchSize - сколько контактов проверяется за один запуск задачи
+	syncBatchSize = 200
+	// requestPause - пауза между контактами, чтобы не упираться в лимиты Bot API
+	requestPause = 200 * time.Millisecond
+	// maxAvatarSide - наибольшая сторона сохраняемого фото; Telegram отдает размеры 160, 320 и 640
+	maxAvatarSide = 640
+)
+
+// UseCase сохраняет фото профилей Telegram контактов и отдает их по постоянным адресам
+// вместо внешних ссылок Telegram, которые со временем перестают работать
+type UseCase interface {
+	// Run периодически вызывает SyncDue, пока не отменен ctx
+	Run(ctx context.Context, interval time.Duration)
+	// SyncDue запрашивает у Telegram фото контактов, которые давно не проверялись, и возвращает число проверенных.
+	// Новое фото загружается, только если оно сменилось; аватар удаляется, если фото убрано или контакт отвязан от Telegram.
+	SyncDue(ctx context.Context) (int, error)
+	// Avatar возвращает сохраненное фото контакта и его тип. Если роли недоступно поле контакта telegram_id,
+	// возвращается ErrAvatarNotFound.
+	Avatar(ctx context.Context, contactID uint, role string) ([]byte, string, error)
+}
+
+type avatarUseCase struct {
+	repo           repository.Repository
+	contactUseCase contactUseCase.UseCase
+	telegram       *telegramClient
+	photos         *photocache.Cache // Тот же кэш, что и у фото профилей пользователей
+	logger         *slog.Logger
+}
+
+// NewAvatarUseCase создает новый экземпляр UseCase для аватаров. Фото хранятся в кэше photos под версией
+// file_unique_id Telegram; botToken читается при каждом запросе к Telegram.
+func NewAvatarUseCase(repo repository.Repository, cu contactUseCase.UseCase, botToken func() string, photos *photocache.Cache, logger *slog.Logger) UseCase {
+	return &avatarUseCase{
+		repo:           repo,
+		contactUseCase: cu,
+		telegram:       newTelegramClient(botToken),
+		photos:         photos,
+		logger:         logger,
+	}
+}
+
+func (uc *avatarUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := uc.SyncDue(ctx); err != nil {
+			uc.logger.ErrorContext(ctx, "Failed to sync contact avatars", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (uc *avatarUseCase) SyncDue(ctx context.Context) (int, error) {
+	contacts, err := uc.repo.GetDue(ctx, timeutil.Now().Add(-refreshAge), syncBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	synced := 0
+	for i := range contacts {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return synced, ctx.Err()
+			case <-time.After(requestPause):
+			}
+		}
+		if err := uc.sync(ctx, &contacts[i]); err != nil {
+			// Недоступность Telegram касается всех контактов: остальные проверим при следующем запуске
+			if errors.Is(err, errTelegramUnavailable) {
+				return synced, err
+			}
+			uc.logger.WarnContext(ctx, "Failed to sync contact avatar", slog.Uint64("contactID", uint64(contacts[i].ID)), slog.Any("error", err))
+			continue
+		}
+		synced++
+	}
+	if synced > 0 {
+		uc.logger.InfoContext(ctx, "Contact avatars synced", slog.Int("contacts", synced))
+	}
+	return synced, nil
+}
+
+// sync сверяет аватар контакта с текущим фото профиля в Telegram
+func (uc *avatarUseCase) sync(ctx context.Context, contact *domain.Contact) error {
+	now := timeutil.Now()
+	if contact.TelegramID == 0 {
+		return uc.clear(ctx, contact.ID, now)
+	}
+
+	photo, err := uc.telegram.profilePhoto(ctx, contact.TelegramID)
+	if errors.Is(err, errUserUnavailable) {
+		// Оставляем прежний аватар: пользователь мог временно стать недоступен боту
+		uc.logger.InfoContext(ctx, "Telegram user is unavailable for avatar sync", slog.Uint64("contactID", uint64(contact.ID)), slog.Any("error", err))
+		return uc.repo.SetAvatar(ctx, contact.ID, contact.AvatarFileID, now)
+	}
+	if err != nil {
+		return err
+	}
+	if photo == nil {
+		return uc.clear(ctx, contact.ID, now)
+	}
+
+	owner := avatarOwner(contact.ID)
+	if photo.FileUniqueID == contact.AvatarFileID {
+		if _, _, err := uc.photos.Lookup(owner, photo.FileUniqueID); err == nil {
+			return uc.repo.SetAvatar(ctx, contact.ID, contact.AvatarFileID, now)
+		}
+	}
+
+	fileURL, err := uc.telegram.fileURL(ctx, photo.FileID)
+	if err != nil {
+		return err
+	}
+	if err := uc.photos.Fetch(ctx, owner, photo.FileUniqueID, fileURL); err != nil {
+		if errors.Is(err, photocache.ErrSourceUnavailable) {
+			return fmt.Errorf("%w: %v", errTelegramUnavailable, err)
+		}
+		return err
+	}
+	return uc.repo.SetAvatar(ctx, contact.ID, photo.FileUniqueID, now)
+}
+
+// clear удаляет сохраненный аватар контакта
+func (uc *avatarUseCase) clear(ctx context.Context, contactID uint, now time.Time) error {
+	if err := uc.photos.Remove(avatarOwner(contactID)); err != nil {
+		return err
+	}
+	return uc.repo.SetAvatar(ctx, contactID, "", now)
+}
+
+func (uc *avatarUseCase) Avatar(ctx context.Context, contactID uint, role string) ([]byte, string, error) {
+	contact, err := uc.repo.GetAvatar(ctx, contactID)
+	if err != nil {
+		if errors.Is(err, repository.ErrContactNotFound) {
+			return nil, "", contactUseCase.ErrContactNotFound
+		}
+		return nil, "", err
+	}
+	version := contact.AvatarFileID
+	// Фото позволяет узнать человека в Telegram, поэтому подчиняется правилу поля telegram_id
+	contacts := []domain.Contact{*contact}
+	if err := uc.contactUseCase.FilterFieldsForRole(ctx, role, contacts); err != nil {
+		return nil, "", err
+	}
+	if contacts[0].AvatarFileID == "" {
+		return nil, "", ErrAvatarNotFound
+	}
+
+	data, contentType, err := uc.photos.Lookup(avatarOwner(contactID), version)
+	if err != nil {
+		if errors.Is(err, photocache.ErrPhotoNotFound) {
+			return nil, "", ErrAvatarNotFound
+		}
+		return nil, "", err
+	}
+	return data, contentType, nil
+}
+
+// avatarOwner - владелец фото контакта в кэше; фото пользователей хранятся там же под user_<ID>
+func avatarOwner(contactID uint) string {
+	return fmt.Sprintf("contact_%d", contactID)
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const telegramAPI = "https://api.telegram.org"
+
+var (
+	// errTelegramUnavailable - Telegram не ответил или перегружен; задачу стоит прервать до следующего запуска
+	errTelegramUnavailable = errors.New("telegram bot api is unavailable")
+	// errUserUnavailable - Telegram не отдает фото этого пользователя (например, бот его не знает)
+	errUserUnavailable = errors.New("telegram user is unavailable to the bot")
+)
+
+// photoSize - один из размеров фото профиля в ответе getUserProfilePhotos
+type photoSize struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"` // Одинаков для всех ботов и не меняется, пока фото то же
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+// telegramClient запрашивает фото профилей через Telegram Bot API
+type telegramClient struct {
+	token  func() string // Читается при каждом запросе, чтобы учитывать ротацию токена
+	client *http.Client
+}
+
+func newTelegramClient(botToken func() string) *telegramClient {
+	return &telegramClient{token: botToken, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// profilePhoto возвращает текущее фото профиля пользователя в размере не больше maxAvatarSide, nil - фото нет
+func (t *telegramClient) profilePhoto(ctx context.Context, telegramID int64) (*photoSize, error) {
+	var result struct {
+		Photos [][]photoSize `json:"photos"`
+	}
+	params := url.Values{"user_id": {strconv.FormatInt(telegramID, 10)}, "limit": {"1"}}
+	if err := t.call(ctx, "getUserProfilePhotos", params, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Photos) == 0 || len(result.Photos[0]) == 0 {
+		return nil, nil
+	}
+
+	// Размеры идут по возрастанию: берем наибольший, который помещается в maxAvatarSide
+	sizes := result.Photos[0]
+	best := sizes[0]
+	for _, s := range sizes[1:] {
+		if s.Width <= maxAvatarSide && s.Height <= maxAvatarSide {
+			best = s
+		}
+	}
+	return &best, nil
+}
+
+// fileURL возвращает адрес загрузки файла, полученного от Bot API. Адрес содержит токен бота.
+func (t *telegramClient) fileURL(ctx context.Context, fileID string) (string, error) {
+	var file struct {
+		FilePath string `json:"file_path"`
+	}
+	if err := t.call(ctx, "getFile", url.Values{"file_id": {fileID}}, &file); err != nil {
+		return "", err
+	}
+	if file.FilePath == "" {
+		return "", fmt.Errorf("telegram returned no file path")
+	}
+	return telegramAPI + "/file/bot" + t.token() + "/" + file.FilePath, nil
+}
+
+// call выполняет метод Bot API и разбирает поле result ответа в out
+func (t *telegramClient) call(ctx context.Context, method string, params url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, telegramAPI+"/bot"+t.token()+"/"+method+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return errTelegramUnavailable
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return fmt.Errorf("%w: %s returned status %d", errTelegramUnavailable, method, resp.StatusCode)
+	}
+	if !body.OK {
+		// 400 относится к конкретному пользователю; 401, 429 и 5xx - ко всем запросам задачи
+		if body.ErrorCode == http.StatusBadRequest {
+			return fmt.Errorf("%w: %s", errUserUnavailable, body.Description)
+		}
+		return fmt.Errorf("%w: %s failed with %d %s", errTelegramUnavailable, method, body.ErrorCode, body.Description)
+	}
+	return json.Unmarshal(body.Result, out)
+}
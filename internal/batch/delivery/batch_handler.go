@@ -0,0 +1,247 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"rim/internal/batch/usecase"
+	contactUseCase "rim/internal/contact/usecase"
+	groupDelivery "rim/internal/group/delivery"
+	groupUseCase "rim/internal/group/usecase"
+	locationUseCase "rim/internal/location/usecase"
+	"rim/pkg/requestctx"
+	"rim/pkg/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// errInternal заменяет в ответе внутреннюю ошибку операции пакета
+var errInternal = errors.New("internal server error")
+
+// Handler отвечает за обработку пакетных запросов.
+type Handler struct {
+	batchUseCase usecase.UseCase
+	logger       *slog.Logger
+	validate     *validator.Validate
+}
+
+// NewHandler создает новый экземпляр Handler для пакетных запросов.
+func NewHandler(batchUseCase usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		batchUseCase: batchUseCase,
+		logger:       logger,
+		validate:     validation.Default(),
+	}
+}
+
+// ExecuteBatch выполняет операции пакета.
+// @Summary Пакетный запрос
+// @Description Выполняет операции по порядку: create_contact, update_contact, delete_contact, create_group, add_to_group.
+// @Description Операция может сослаться на контакт или группу, созданные ранее в пакете, по их ref.
+// @Description По умолчанию (atomic) операции выполняются в одной транзакции: если любая не выполнена, не сохраняется ни одна;
+// @Description в ответе указаны номер и ошибка этой операции. С "atomic": false каждая операция выполняется отдельно, ответ - 200
+// @Description с итогом каждой операции; операция, ссылающаяся на объект невыполненной, тоже не выполняется.
+// @Description Права проверяются для каждого типа операции: contacts create, update, delete или manage_groups.
+// @Tags batch
+// @Accept json
+// @Produce json
+// @Param batch body BatchRequest true "Операции пакета"
+// @Success 200 {object} BatchResponse "Все операции выполнены"
+// @Failure 400 {object} BatchResponse "Некорректная операция; ничего не сохранено"
+// @Failure 403 {object} BatchResponse "Операция запрещена политикой доступа"
+// @Failure 404 {object} BatchResponse "Контакт или группа не найдены; ничего не сохранено"
+// @Failure 409 {object} BatchResponse "Нарушение уникальности; ничего не сохранено"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /batch [post]
+// @Router /contacts/batch [post]
+func (h *Handler) ExecuteBatch(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[BatchRequest](c)
+	if !ok {
+		return err
+	}
+
+	operations := make([]usecase.Operation, len(req.Operations))
+	for i, opReq := range req.Operations {
+		op, err := h.toOperation(opReq)
+		if err != nil {
+			h.logger.WarnContext(c.Context(), "Validation failed for batch operation", slog.Int("index", i), slog.Any("error", err))
+			resp := failedResponse(req.Operations, i, err)
+			if invalid := validation.NewResponse(err, c.AcceptsLanguages(validation.Languages...)); len(invalid.Errors) > 0 {
+				resp.Error, resp.Results[i].Error = invalid.Message, invalid.Message
+				resp.Errors = invalid.Errors
+				for j := range resp.Errors {
+					resp.Errors[j].Field = fmt.Sprintf("operations[%d].%s", i, resp.Errors[j].Field)
+				}
+			}
+			return c.Status(fiber.StatusBadRequest).JSON(resp)
+		}
+		op.Contact.CreatedBy = requestctx.ActorID(c)
+		op.Changes.UpdatedBy = requestctx.ActorID(c)
+		op.ActorID = requestctx.ActorID(c)
+		operations[i] = op
+	}
+
+	atomic := req.Atomic == nil || *req.Atomic
+	results, err := h.batchUseCase.Execute(c.Context(), requestctx.Role(c), operations, atomic)
+	if err != nil {
+		var opErr *usecase.OperationError
+		if errors.As(err, &opErr) {
+			status := operationErrorStatus(opErr.Err)
+			if status == fiber.StatusInternalServerError {
+				h.logger.ErrorContext(c.Context(), "Batch operation failed", slog.Int("index", opErr.Index), slog.Any("error", err))
+				return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+			}
+			return c.Status(status).JSON(toBatchResponse(results, opErr))
+		}
+		if errors.Is(err, usecase.ErrEmptyBatch) || errors.Is(err, usecase.ErrTooManyOperations) {
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to execute batch", slog.Int("operations", len(operations)), slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+
+	// Без общей транзакции внутренние ошибки отдельных операций не прерывают пакет, но и не раскрываются в ответе
+	for i := range results {
+		if results[i].Err != nil && operationErrorStatus(results[i].Err) == fiber.StatusInternalServerError {
+			h.logger.ErrorContext(c.Context(), "Batch operation failed", slog.Int("index", i), slog.Any("error", results[i].Err))
+			results[i].Err = errInternal
+		}
+	}
+	return c.JSON(toBatchResponse(results, nil))
+}
+
+// toOperation проверяет поля операции по правилам одиночных запросов и переводит ее в usecase.Operation.
+func (h *Handler) toOperation(req OperationRequest) (usecase.Operation, error) {
+	op := usecase.Operation{Type: req.Op, Ref: req.Ref}
+	// Вложенные contact и group проверяются вместе с операцией
+	if err := h.validate.Struct(req); err != nil {
+		return op, err
+	}
+
+	switch req.Op {
+	case usecase.OpCreateContact:
+		if req.Contact == nil {
+			return op, errors.New("contact is required")
+		}
+		op.Contact = contactUseCase.CreateContactData{
+			Name:       req.Contact.Name,
+			Phone:      req.Contact.Phone,
+			Email:      req.Contact.Email,
+			Transport:  req.Contact.Transport,
+			Printer:    req.Contact.Printer,
+			Allergies:  req.Contact.Allergies,
+			VK:         req.Contact.VK,
+			Telegram:   req.Contact.Telegram,
+			TelegramID: req.Contact.TelegramID,
+			GroupIDs:   req.Contact.GroupIDs,
+			Location: locationUseCase.Location{
+				City:     req.Contact.City,
+				Campus:   req.Contact.Campus,
+				Building: req.Contact.Building,
+				Room:     req.Contact.Room,
+			},
+			Department: req.Contact.Department,
+			Position:   req.Contact.Position,
+		}
+		op.GroupRefs = req.GroupRefs
+	case usecase.OpUpdateContact:
+		if req.Changes == nil {
+			return op, errors.New("changes is required")
+		}
+		op.ContactID = req.ContactID
+		op.ContactRef = req.ContactRef
+		op.Changes = contactUseCase.UpdateContactData{
+			Name:       req.Changes.Name,
+			Phone:      req.Changes.Phone,
+			Email:      req.Changes.Email,
+			Transport:  req.Changes.Transport,
+			Printer:    req.Changes.Printer,
+			Allergies:  req.Changes.Allergies,
+			VK:         req.Changes.VK,
+			Telegram:   req.Changes.Telegram,
+			TelegramID: req.Changes.TelegramID,
+			GroupIDs:   req.Changes.GroupIDs,
+			City:       req.Changes.City,
+			Campus:     req.Changes.Campus,
+			Building:   req.Changes.Building,
+			Room:       req.Changes.Room,
+			Department: req.Changes.Department,
+			Position:   req.Changes.Position,
+		}
+	case usecase.OpDeleteContact:
+		op.ContactID = req.ContactID
+		op.ContactRef = req.ContactRef
+	case usecase.OpCreateGroup:
+		if req.Group == nil {
+			return op, errors.New("group is required")
+		}
+		op.GroupName = req.Group.Name
+		op.GroupIsOpen = req.Group.IsOpen
+	case usecase.OpAddToGroup:
+		op.ContactID = req.ContactID
+		op.ContactRef = req.ContactRef
+		op.GroupID = req.GroupID
+		op.GroupRef = req.GroupRef
+		op.ExpiresAt = req.ExpiresAt
+	default:
+		return op, fmt.Errorf("%w: %q", usecase.ErrUnknownOperation, req.Op)
+	}
+	return op, nil
+}
+
+// operationErrorStatus возвращает HTTP-статус ответа для ошибки операции пакета.
+func operationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrOperationDenied), errors.Is(err, contactUseCase.ErrOutOfGroupScope):
+		return fiber.StatusForbidden
+	case errors.Is(err, contactUseCase.ErrContactNotFound), errors.Is(err, groupUseCase.ErrGroupNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, contactUseCase.ErrContactEmailExists), errors.Is(err, contactUseCase.ErrContactPhoneExists), errors.Is(err, groupUseCase.ErrGroupNameExists):
+		return fiber.StatusConflict
+	case errors.Is(err, usecase.ErrUnknownOperation), errors.Is(err, usecase.ErrUnknownRef), errors.Is(err, usecase.ErrDuplicateRef), errors.Is(err, usecase.ErrMissingTarget),
+		errors.Is(err, usecase.ErrMissingContact), errors.Is(err, usecase.ErrRefFailed),
+		errors.Is(err, contactUseCase.ErrContactNameEmpty), errors.Is(err, contactUseCase.ErrContactPhoneEmpty), errors.Is(err, contactUseCase.ErrContactEmailEmpty),
+		errors.Is(err, contactUseCase.ErrInvalidExpiry), errors.Is(err, groupUseCase.ErrGroupNameEmpty), errors.Is(err, locationUseCase.ErrUnknownLocation):
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+// toBatchResponse формирует ответ по результатам операций; opErr - ошибка, откатившая пакет.
+func toBatchResponse(results []usecase.Result, opErr *usecase.OperationError) BatchResponse {
+	resp := BatchResponse{Committed: opErr == nil, Results: make([]OperationResponse, len(results))}
+	if opErr != nil {
+		resp.FailedIndex = &opErr.Index
+		resp.Error = opErr.Err.Error()
+	}
+	for i, result := range results {
+		item := OperationResponse{Index: i, Op: result.Type, Ref: result.Ref, Status: result.Status}
+		// ID созданных объектов не отдаются, если пакет откачен
+		if result.Status == usecase.StatusOK {
+			switch {
+			case result.Contact != nil:
+				item.ID = result.Contact.ID
+			case result.Group != nil:
+				item.ID = result.Group.ID
+			}
+		}
+		if result.Err != nil {
+			item.Error = result.Err.Error()
+		}
+		resp.Results[i] = item
+	}
+	return resp
+}
+
+// failedResponse описывает пакет, отклоненный до выполнения из-за некорректной операции index.
+func failedResponse(operations []OperationRequest, index int, err error) BatchResponse {
+	results := make([]usecase.Result, len(operations))
+	for i, op := range operations {
+		results[i] = usecase.Result{Type: op.Op, Ref: op.Ref, Status: usecase.StatusSkipped}
+	}
+	results[index].Status = usecase.StatusFailed
+	results[index].Err = err
+	return toBatchResponse(results, &usecase.OperationError{Index: index, Err: err})
+}
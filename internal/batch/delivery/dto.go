@@ -0,0 +1,63 @@
+package delivery
+
+import (
+	"time"
+
+	contactDelivery "rim/internal/contact/delivery"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/pkg/validation"
+)
+
+// BatchRequest определяет структуру пакетного запроса.
+type BatchRequest struct {
+	Operations []OperationRequest `json:"operations"`
+	// Atomic - выполнить все операции в одной транзакции (по умолчанию). false - каждую отдельно:
+	// невыполненные операции отмечаются в results, остальные сохраняются
+	Atomic *bool `json:"atomic,omitempty"`
+}
+
+// OperationRequest определяет одну операцию пакета: create_contact, update_contact, delete_contact, create_group или add_to_group.
+// Ref дает созданному контакту или группе имя, на которое ссылаются следующие операции пакета.
+type OperationRequest struct {
+	Op  string `json:"op"`
+	Ref string `json:"ref,omitempty" validate:"omitempty,max=100"`
+
+	// create_contact; group_refs - группы, созданные ранее в пакете
+	Contact   *contactDelivery.CreateContactRequest `json:"contact,omitempty"`
+	GroupRefs []string                              `json:"group_refs,omitempty"`
+
+	// create_group
+	Group *groupDelivery.CreateGroupRequest `json:"group,omitempty"`
+
+	// update_contact: изменяемые поля, как в PUT /contacts/{id}
+	Changes *contactDelivery.UpdateContactRequest `json:"changes,omitempty"`
+
+	// add_to_group: контакт и группа задаются ID или ссылкой; update_contact и delete_contact - только контакт
+	ContactID  uint       `json:"contact_id,omitempty"`
+	ContactRef string     `json:"contact_ref,omitempty"`
+	GroupID    uint       `json:"group_id,omitempty"`
+	GroupRef   string     `json:"group_ref,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // RFC3339; без срока - бессрочно
+}
+
+// BatchResponse определяет структуру ответа на пакетный запрос.
+type BatchResponse struct {
+	// Committed - изменения сохранены: все операции пакета с atomic или выполненные операции без него
+	Committed bool `json:"committed"`
+	// FailedIndex - номер операции (с 0), из-за которой пакет откачен
+	FailedIndex *int   `json:"failed_index,omitempty"`
+	Error       string `json:"error,omitempty"`
+	// Errors - ошибки проверки полей операции failed_index; путь поля начинается с operations[N]
+	Errors  []validation.FieldError `json:"errors,omitempty"`
+	Results []OperationResponse     `json:"results"`
+}
+
+// OperationResponse определяет итог операции пакета.
+type OperationResponse struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	Ref    string `json:"ref,omitempty"`
+	Status string `json:"status"`       // ok, failed, rolled_back или skipped
+	ID     uint   `json:"id,omitempty"` // ID созданного или измененного контакта либо созданной группы
+	Error  string `json:"error,omitempty"`
+}
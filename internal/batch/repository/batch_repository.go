@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+// Repository выполняет операции пакетного запроса в одной транзакции БД.
+type Repository interface {
+	// Transaction выполняет fn в транзакции: репозитории других модулей, вызванные с ctx из fn,
+	// работают внутри нее. Ошибка fn откатывает все изменения.
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр sqliteRepository для пакетных запросов.
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *sqliteRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := transaction.Run(ctx, r.db, fn); err != nil {
+		r.logger.InfoContext(ctx, "Batch transaction rolled back", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"rim/internal/batch/repository"
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	groupUseCase "rim/internal/group/usecase"
+	policyUseCase "rim/internal/policy/usecase"
+)
+
+// Типы операций пакетного запроса
+const (
+	OpCreateContact = "create_contact"
+	OpUpdateContact = "update_contact"
+	OpDeleteContact = "delete_contact"
+	OpCreateGroup   = "create_group"
+	OpAddToGroup    = "add_to_group"
+)
+
+// Состояния операций в результате пакетного запроса
+const (
+	StatusOK         = "ok"
+	StatusFailed     = "failed"
+	StatusRolledBack = "rolled_back" // Операция выполнилась, но пакет откачен из-за ошибки следующей
+	StatusSkipped    = "skipped"     // Операция не выполнялась, так как раньше произошла ошибка
+)
+
+// ErrRefFailed - в пакете без общей транзакции ссылка указывает на объект операции, которая не выполнена
+var ErrRefFailed = errors.New("batch reference points to a failed operation")
+
+// MaxOperations - наибольшее число операций в одном пакете
+const MaxOperations = 500
+
+var (
+	ErrEmptyBatch        = errors.New("batch has no operations")
+	ErrTooManyOperations = fmt.Errorf("batch has more than %d operations", MaxOperations)
+	ErrUnknownOperation  = errors.New("unknown batch operation")
+	ErrDuplicateRef      = errors.New("batch reference is already defined")
+	ErrUnknownRef        = errors.New("batch reference is not defined by a previous operation")
+	ErrMissingTarget     = errors.New("contact and group must be set by id or ref")
+	ErrMissingContact    = errors.New("contact must be set by id or ref")
+	ErrOperationDenied   = errors.New("operation is not allowed for this role")
+)
+
+// Operation - одна операция пакета. Заполняются поля, относящиеся к ее типу.
+// Ref дает созданному объекту имя, по которому на него ссылаются следующие операции (ContactRef, GroupRef, GroupRefs).
+type Operation struct {
+	Type string
+	Ref  string
+
+	// create_contact; GroupRefs добавляются к Contact.GroupIDs
+	Contact   contactUseCase.CreateContactData
+	GroupRefs []string
+
+	// create_group
+	GroupName   string
+	GroupIsOpen bool
+
+	// update_contact: изменяемые поля; контакт задается ContactID или ContactRef, как и в delete_contact
+	Changes contactUseCase.UpdateContactData
+
+	// add_to_group: контакт и группа задаются ID или ссылкой на созданные ранее в пакете
+	ContactID  uint
+	ContactRef string
+	GroupID    uint
+	GroupRef   string
+	ExpiresAt  *time.Time
+
+	// ActorID - пользователь, выполняющий пакет; попадает в историю членства
+	ActorID *uint
+}
+
+// Result - итог операции пакета
+type Result struct {
+	Type    string
+	Ref     string
+	Status  string
+	Contact *domain.Contact // create_contact, update_contact
+	Group   *domain.Group   // create_group
+	Err     error           // Для StatusFailed
+}
+
+// OperationError указывает операцию, из-за которой пакет откачен
+type OperationError struct {
+	Index int
+	Err   error
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("operation %d: %v", e.Index, e.Err)
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// UseCase определяет интерфейс пакетных запросов.
+type UseCase interface {
+	// Execute выполняет операции по порядку от имени роли role.
+	// Если atomic, операции выполняются в одной транзакции: если какая-то не выполнена, изменения всех
+	// откатываются и возвращается *OperationError; результаты при этом описывают состояние каждой операции.
+	// Иначе каждая операция выполняется в своей транзакции, невыполненные отмечаются в результатах
+	// со StatusFailed, а остальные сохраняются.
+	Execute(ctx context.Context, role string, operations []Operation, atomic bool) ([]Result, error)
+}
+
+type batchUseCase struct {
+	batchRepo      repository.Repository
+	contactUseCase contactUseCase.UseCase
+	groupUseCase   groupUseCase.UseCase
+	policy         policyUseCase.UseCase
+	logger         *slog.Logger
+}
+
+// NewBatchUseCase создает новый экземпляр batchUseCase.
+// Операции выполняются через UseCase контактов и групп со всеми их проверками.
+func NewBatchUseCase(br repository.Repository, cu contactUseCase.UseCase, gu groupUseCase.UseCase, pu policyUseCase.UseCase, logger *slog.Logger) UseCase {
+	return &batchUseCase{
+		batchRepo:      br,
+		contactUseCase: cu,
+		groupUseCase:   gu,
+		policy:         pu,
+		logger:         logger,
+	}
+}
+
+// operationPermissions - правило политики доступа, которое требуется для каждого типа операции
+var operationPermissions = map[string][2]string{
+	OpCreateContact: {policyUseCase.ResourceContacts, policyUseCase.ActionCreate},
+	OpUpdateContact: {policyUseCase.ResourceContacts, policyUseCase.ActionUpdate},
+	OpDeleteContact: {policyUseCase.ResourceContacts, policyUseCase.ActionDelete},
+	OpCreateGroup:   {policyUseCase.ResourceContacts, policyUseCase.ActionManageGroups},
+	OpAddToGroup:    {policyUseCase.ResourceContacts, policyUseCase.ActionManageGroups},
+}
+
+func (uc *batchUseCase) Execute(ctx context.Context, role string, operations []Operation, atomic bool) ([]Result, error) {
+	if len(operations) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(operations) > MaxOperations {
+		return nil, ErrTooManyOperations
+	}
+
+	results := make([]Result, len(operations))
+	for i, op := range operations {
+		results[i] = Result{Type: op.Type, Ref: op.Ref, Status: StatusSkipped}
+	}
+
+	// Типы, ссылки и права проверяются до начала транзакции
+	if err := uc.checkOperations(ctx, role, operations); err != nil {
+		var opErr *OperationError
+		if errors.As(err, &opErr) {
+			results[opErr.Index].Status = StatusFailed
+			results[opErr.Index].Err = opErr.Err
+		}
+		return results, err
+	}
+
+	contacts := make(map[string]uint)
+	groups := make(map[string]uint)
+	if !atomic {
+		return uc.executeEach(ctx, operations, results, contacts, groups), nil
+	}
+	err := uc.batchRepo.Transaction(ctx, func(ctx context.Context) error {
+		for i, op := range operations {
+			if err := uc.execute(ctx, op, &results[i], contacts, groups); err != nil {
+				results[i].Status = StatusFailed
+				results[i].Err = err
+				for j := 0; j < i; j++ {
+					results[j].Status = StatusRolledBack
+				}
+				return &OperationError{Index: i, Err: err}
+			}
+			results[i].Status = StatusOK
+		}
+		return nil
+	})
+	if err != nil {
+		var opErr *OperationError
+		if !errors.As(err, &opErr) {
+			// Ошибка фиксации транзакции: ни одна операция не сохранена
+			for i := range results {
+				if results[i].Status == StatusOK {
+					results[i].Status = StatusRolledBack
+				}
+			}
+			uc.logger.ErrorContext(ctx, "Failed to commit batch", slog.Int("operations", len(operations)), slog.Any("error", err))
+		}
+		return results, err
+	}
+
+	uc.logger.InfoContext(ctx, "Batch executed successfully", slog.Int("operations", len(operations)))
+	return results, nil
+}
+
+// executeEach выполняет каждую операцию в отдельной транзакции, продолжая после невыполненных.
+// Операция, ссылающаяся на объект невыполненной операции, тоже не выполняется.
+func (uc *batchUseCase) executeEach(ctx context.Context, operations []Operation, results []Result, contacts, groups map[string]uint) []Result {
+	failedRefs := make(map[string]bool)
+	failed := 0
+	for i, op := range operations {
+		err := failedRef(op, failedRefs)
+		if err == nil {
+			err = uc.batchRepo.Transaction(ctx, func(ctx context.Context) error {
+				return uc.execute(ctx, op, &results[i], contacts, groups)
+			})
+		}
+		if err != nil {
+			results[i].Status = StatusFailed
+			results[i].Err = err
+			results[i].Contact, results[i].Group = nil, nil
+			if op.Ref != "" {
+				failedRefs[op.Ref] = true
+			}
+			failed++
+			continue
+		}
+		results[i].Status = StatusOK
+	}
+	uc.logger.InfoContext(ctx, "Batch executed without common transaction", slog.Int("operations", len(operations)), slog.Int("failed", failed))
+	return results
+}
+
+// failedRef возвращает ErrRefFailed, если операция ссылается на объект невыполненной операции
+func failedRef(op Operation, failedRefs map[string]bool) error {
+	for _, ref := range append([]string{op.ContactRef, op.GroupRef}, op.GroupRefs...) {
+		if failedRefs[ref] {
+			return fmt.Errorf("%w: %q", ErrRefFailed, ref)
+		}
+	}
+	return nil
+}
+
+// checkOperations проверяет типы операций, права роли и то, что ссылки определены предыдущими операциями.
+func (uc *batchUseCase) checkOperations(ctx context.Context, role string, operations []Operation) error {
+	allowed := make(map[string]bool)
+	contactRefs := make(map[string]bool)
+	groupRefs := make(map[string]bool)
+	for i, op := range operations {
+		permission, ok := operationPermissions[op.Type]
+		if !ok {
+			return &OperationError{Index: i, Err: fmt.Errorf("%w: %q", ErrUnknownOperation, op.Type)}
+		}
+		if _, checked := allowed[op.Type]; !checked {
+			ok, err := uc.policy.IsAllowed(ctx, role, permission[0], permission[1])
+			if err != nil {
+				return err
+			}
+			allowed[op.Type] = ok
+		}
+		if !allowed[op.Type] {
+			return &OperationError{Index: i, Err: fmt.Errorf("%w: %s", ErrOperationDenied, op.Type)}
+		}
+
+		switch op.Type {
+		case OpCreateContact:
+			for _, ref := range op.GroupRefs {
+				if !groupRefs[ref] {
+					return &OperationError{Index: i, Err: fmt.Errorf("%w: %q", ErrUnknownRef, ref)}
+				}
+			}
+		case OpUpdateContact, OpDeleteContact:
+			if (op.ContactID == 0) == (op.ContactRef == "") {
+				return &OperationError{Index: i, Err: ErrMissingContact}
+			}
+			if op.ContactRef != "" && !contactRefs[op.ContactRef] {
+				return &OperationError{Index: i, Err: fmt.Errorf("%w: %q", ErrUnknownRef, op.ContactRef)}
+			}
+		case OpAddToGroup:
+			if (op.ContactID == 0) == (op.ContactRef == "") || (op.GroupID == 0) == (op.GroupRef == "") {
+				return &OperationError{Index: i, Err: ErrMissingTarget}
+			}
+			if op.ContactRef != "" && !contactRefs[op.ContactRef] {
+				return &OperationError{Index: i, Err: fmt.Errorf("%w: %q", ErrUnknownRef, op.ContactRef)}
+			}
+			if op.GroupRef != "" && !groupRefs[op.GroupRef] {
+				return &OperationError{Index: i, Err: fmt.Errorf("%w: %q", ErrUnknownRef, op.GroupRef)}
+			}
+		}
+
+		if op.Ref == "" {
+			continue
+		}
+		if contactRefs[op.Ref] || groupRefs[op.Ref] {
+			return &OperationError{Index: i, Err: fmt.Errorf("%w: %q", ErrDuplicateRef, op.Ref)}
+		}
+		switch op.Type {
+		case OpCreateContact:
+			contactRefs[op.Ref] = true
+		case OpCreateGroup:
+			groupRefs[op.Ref] = true
+		}
+	}
+	return nil
+}
+
+// execute выполняет одну операцию и запоминает ID созданного объекта под ее ссылкой.
+func (uc *batchUseCase) execute(ctx context.Context, op Operation, result *Result, contacts, groups map[string]uint) error {
+	switch op.Type {
+	case OpCreateContact:
+		data := op.Contact
+		data.GroupIDs = append([]uint(nil), data.GroupIDs...)
+		for _, ref := range op.GroupRefs {
+			data.GroupIDs = append(data.GroupIDs, groups[ref])
+		}
+		contact, err := uc.contactUseCase.CreateContact(ctx, data)
+		if err != nil {
+			return err
+		}
+		result.Contact = contact
+		if op.Ref != "" {
+			contacts[op.Ref] = contact.ID
+		}
+	case OpUpdateContact:
+		contactID := op.ContactID
+		if op.ContactRef != "" {
+			contactID = contacts[op.ContactRef]
+		}
+		contact, err := uc.contactUseCase.UpdateContact(ctx, contactID, op.Changes)
+		if err != nil {
+			return err
+		}
+		result.Contact = contact
+	case OpDeleteContact:
+		contactID := op.ContactID
+		if op.ContactRef != "" {
+			contactID = contacts[op.ContactRef]
+		}
+		return uc.contactUseCase.DeleteContact(ctx, contactID, op.ActorID)
+	case OpCreateGroup:
+		group, err := uc.groupUseCase.CreateGroup(ctx, op.GroupName, op.GroupIsOpen)
+		if err != nil {
+			return err
+		}
+		result.Group = group
+		if op.Ref != "" {
+			groups[op.Ref] = group.ID
+		}
+	case OpAddToGroup:
+		contactID, groupID := op.ContactID, op.GroupID
+		if op.ContactRef != "" {
+			contactID = contacts[op.ContactRef]
+		}
+		if op.GroupRef != "" {
+			groupID = groups[op.GroupRef]
+		}
+		return uc.contactUseCase.AddContactToGroup(ctx, contactID, groupID, op.ExpiresAt, nil, op.ActorID)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package delivery
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rim/internal/bot/usecase"
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	shortLinkUseCase "rim/internal/shortlink/usecase"
+	"rim/pkg/timeutil"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// inlineCacheTime - сколько секунд Telegram кэширует ответ; результаты персональные и зависят от прав
+	inlineCacheTime = 10
+	// draftLinkTTL - срок короткой ссылки на черновик в сообщении бота
+	draftLinkTTL = 7 * 24 * time.Hour
+)
+
+// Handler обрабатывает обновления Telegram, приходящие на webhook бота
+type Handler struct {
+	botUseCase usecase.UseCase
+	shortLinks shortLinkUseCase.UseCase
+	secret     string
+	portalURL  string
+	logger     *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для бота.
+// secret сверяется с заголовком X-Telegram-Bot-Api-Secret-Token, переданным в setWebhook;
+// portalURL - адрес портала для ссылок на созданные ботом черновики; ссылки сокращаются через shortLinks.
+func NewHandler(botUseCase usecase.UseCase, shortLinks shortLinkUseCase.UseCase, secret, portalURL string, logger *slog.Logger) *Handler {
+	return &Handler{
+		botUseCase: botUseCase,
+		shortLinks: shortLinks,
+		secret:     secret,
+		portalURL:  portalURL,
+		logger:     logger,
+	}
+}
+
+// Webhook принимает обновления Telegram
+// @Summary Webhook бота Telegram
+// @Description Принимает обновления от Telegram. Встроенный запрос "@bot имя" ищет контакты по имени или Telegram с правами пользователя, чей Telegram ID привязан к аккаунту.
+// @Description Ответ answerInlineQuery возвращается в теле ответа на webhook. Незарегистрированные пользователи получают пустой список с кнопкой перехода к боту.
+// @Description Контакт, отправленный или пересланный боту в личном чате, сохраняется черновиком без email, если у пользователя есть право на создание контактов.
+// @Description Бот отвечает сообщением sendMessage со ссылкой на черновик в портале, где профиль дополняется.
+// @Tags telegram
+// @Accept json
+// @Produce json
+// @Param X-Telegram-Bot-Api-Secret-Token header string true "Секрет webhook (TELEGRAM_WEBHOOK_SECRET)"
+// @Param update body Update true "Обновление Telegram"
+// @Success 200 {object} AnswerInlineQuery "Ответ на встроенный запрос, SendMessage на присланный контакт; для прочих обновлений тело пустое"
+// @Failure 401 {object} map[string]string
+// @Router /telegram/webhook [post]
+func (h *Handler) Webhook(c *fiber.Ctx) error {
+	token := c.Get("X-Telegram-Bot-Api-Secret-Token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.secret)) != 1 {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid webhook secret",
+		})
+	}
+
+	var update Update
+	if err := c.BodyParser(&update); err != nil {
+		// Telegram повторяет доставку при ошибке, поэтому неразобранное обновление только логируется
+		h.logger.WarnContext(c.Context(), "Failed to parse Telegram update", slog.Any("error", err))
+		return c.SendStatus(http.StatusOK)
+	}
+	if update.Message != nil && update.Message.Contact != nil && update.Message.Chat.Type == "private" {
+		return h.createDraftContact(c, update.Message)
+	}
+	if update.InlineQuery == nil {
+		return c.SendStatus(http.StatusOK)
+	}
+
+	query := update.InlineQuery
+	answer := AnswerInlineQuery{
+		Method:        "answerInlineQuery",
+		InlineQueryID: query.ID,
+		Results:       []InlineQueryArticle{},
+		CacheTime:     inlineCacheTime,
+		IsPersonal:    true,
+	}
+	offset, _ := strconv.Atoi(query.Offset)
+
+	results, err := h.botUseCase.SearchContacts(c.Context(), query.From.ID, query.Query, offset)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrUnknownUser):
+			answer.Button = &InlineQueryResultsButton{Text: "Привяжите Telegram к аккаунту, чтобы искать контакты", StartParameter: "login"}
+		case errors.Is(err, usecase.ErrAccessDenied), errors.Is(err, usecase.ErrNotMember):
+		default:
+			h.logger.ErrorContext(c.Context(), "Failed to answer inline query", slog.Int64("telegram_id", query.From.ID), slog.Any("error", err))
+		}
+		return c.JSON(answer)
+	}
+
+	for _, ct := range results.Contacts {
+		answer.Results = append(answer.Results, contactArticle(ct))
+	}
+	if results.NextOffset > 0 {
+		answer.NextOffset = strconv.Itoa(results.NextOffset)
+	}
+	return c.JSON(answer)
+}
+
+// createDraftContact сохраняет присланный контакт черновиком и отвечает ссылкой на него в портале
+func (h *Handler) createDraftContact(c *fiber.Ctx, msg *Message) error {
+	reply := SendMessage{Method: "sendMessage", ChatID: msg.Chat.ID, ReplyToMessageID: msg.MessageID}
+
+	contact, err := h.botUseCase.CreateDraftContact(c.Context(), msg.From.ID, usecase.SharedContact{
+		Phone:      msg.Contact.PhoneNumber,
+		FirstName:  msg.Contact.FirstName,
+		LastName:   msg.Contact.LastName,
+		TelegramID: msg.Contact.UserID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrUnknownUser):
+			reply.Text = "Привяжите Telegram к аккаунту в портале, чтобы добавлять контакты."
+		case errors.Is(err, usecase.ErrAccessDenied):
+			reply.Text = "У вас нет прав на создание контактов."
+		case errors.Is(err, usecase.ErrNotMember):
+			reply.Text = "Вы не состоите ни в одной организации портала."
+		case errors.Is(err, contactUseCase.ErrContactPhoneExists):
+			reply.Text = "Контакт с таким телефоном уже есть."
+		case errors.Is(err, contactUseCase.ErrContactTelegramExists):
+			reply.Text = "Контакт с таким Telegram уже есть."
+		case errors.Is(err, contactUseCase.ErrContactNameEmpty), errors.Is(err, contactUseCase.ErrContactPhoneEmpty):
+			reply.Text = "В карточке контакта нет имени или телефона."
+		default:
+			h.logger.ErrorContext(c.Context(), "Failed to create draft contact from Telegram", slog.Int64("telegram_id", msg.From.ID), slog.Any("error", err))
+			reply.Text = "Не удалось сохранить контакт, попробуйте позже."
+		}
+		return c.JSON(reply)
+	}
+
+	reply.Text = "Черновик контакта «" + contact.Name + "» создан. Дополните профиль в портале."
+	reply.ReplyMarkup = &InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{{
+		{Text: "Заполнить профиль", URL: h.draftLink(c, contact.ID)},
+	}}}
+	return c.JSON(reply)
+}
+
+// draftLink возвращает короткую ссылку на черновик в портале; если сократить не удалось - полную
+func (h *Handler) draftLink(c *fiber.Ctx, contactID uint) string {
+	target := h.portalURL + "/contacts?edit=" + strconv.FormatUint(uint64(contactID), 10)
+	expiresAt := timeutil.Now().Add(draftLinkTTL)
+	link, err := h.shortLinks.Create(c.Context(), shortLinkUseCase.CreateData{TargetURL: target, ExpiresAt: &expiresAt, Purpose: domain.ShortLinkBot})
+	if err != nil {
+		h.logger.WarnContext(c.Context(), "Failed to shorten draft contact link", slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return target
+	}
+	return h.portalURL + "/l/" + link.Code
+}
+
+// contactArticle формирует результат встроенного запроса: имя в заголовке, контакты в описании и в сообщении.
+func contactArticle(ct usecase.ContactResult) InlineQueryArticle {
+	var details []string
+	if ct.Phone != "" {
+		details = append(details, ct.Phone)
+	}
+	if ct.Telegram != "" {
+		details = append(details, "@"+strings.TrimPrefix(ct.Telegram, "@"))
+	}
+
+	text := ct.Name
+	if len(details) > 0 {
+		text += "\n" + strings.Join(details, "\n")
+	}
+	return InlineQueryArticle{
+		Type:                "article",
+		ID:                  strconv.FormatUint(uint64(ct.ID), 10),
+		Title:               ct.Name,
+		Description:         strings.Join(details, " · "),
+		InputMessageContent: InputMessageContent{MessageText: text},
+	}
+}
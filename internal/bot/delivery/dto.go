@@ -0,0 +1,94 @@
+package delivery
+
+// Update - обновление Telegram Bot API. Разбираются встроенные запросы и сообщения с контактами, остальные обновления игнорируются.
+type Update struct {
+	UpdateID    int64        `json:"update_id"`
+	InlineQuery *InlineQuery `json:"inline_query,omitempty"`
+	Message     *Message     `json:"message,omitempty"`
+}
+
+// Message - входящее сообщение. Из содержимого разбирается только контакт.
+type Message struct {
+	MessageID int64          `json:"message_id"`
+	From      TelegramUser   `json:"from"`
+	Chat      Chat           `json:"chat"`
+	Contact   *SharedContact `json:"contact,omitempty"`
+}
+
+// Chat - чат сообщения; type "private" - личный чат с ботом
+type Chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+// SharedContact - карточка контакта, отправленная или пересланная в чат
+type SharedContact struct {
+	PhoneNumber string `json:"phone_number"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name,omitempty"`
+	UserID      int64  `json:"user_id,omitempty"` // Telegram ID, если контакт зарегистрирован в Telegram
+}
+
+// InlineQuery - встроенный запрос "@bot текст" из любого чата
+type InlineQuery struct {
+	ID     string       `json:"id"`
+	From   TelegramUser `json:"from"`
+	Query  string       `json:"query"`
+	Offset string       `json:"offset"`
+}
+
+// TelegramUser - отправитель обновления
+type TelegramUser struct {
+	ID int64 `json:"id"`
+}
+
+// AnswerInlineQuery - вызов метода answerInlineQuery, возвращаемый в ответе на webhook
+type AnswerInlineQuery struct {
+	Method        string                    `json:"method"`
+	InlineQueryID string                    `json:"inline_query_id"`
+	Results       []InlineQueryArticle      `json:"results"`
+	CacheTime     int                       `json:"cache_time"`
+	IsPersonal    bool                      `json:"is_personal"`
+	NextOffset    string                    `json:"next_offset,omitempty"`
+	Button        *InlineQueryResultsButton `json:"button,omitempty"`
+}
+
+// InlineQueryArticle - результат типа article
+type InlineQueryArticle struct {
+	Type                string              `json:"type"`
+	ID                  string              `json:"id"`
+	Title               string              `json:"title"`
+	Description         string              `json:"description,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content"`
+}
+
+// InputMessageContent - сообщение, которое отправляется в чат при выборе результата
+type InputMessageContent struct {
+	MessageText string `json:"message_text"`
+}
+
+// InlineQueryResultsButton - кнопка над результатами, открывающая личный чат с ботом
+type InlineQueryResultsButton struct {
+	Text           string `json:"text"`
+	StartParameter string `json:"start_parameter"`
+}
+
+// SendMessage - вызов метода sendMessage, возвращаемый в ответе на webhook
+type SendMessage struct {
+	Method           string                `json:"method"`
+	ChatID           int64                 `json:"chat_id"`
+	Text             string                `json:"text"`
+	ReplyToMessageID int64                 `json:"reply_to_message_id,omitempty"`
+	ReplyMarkup      *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+}
+
+// InlineKeyboardMarkup - кнопки под сообщением
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// InlineKeyboardButton - кнопка-ссылка
+type InlineKeyboardButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
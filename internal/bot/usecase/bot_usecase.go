@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	authUseCase "rim/internal/auth/usecase"
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	organizationUseCase "rim/internal/organization/usecase"
+	policyUseCase "rim/internal/policy/usecase"
+	"rim/pkg/tenant"
+)
+
+// InlineResultsLimit - число результатов на странице встроенного запроса (Telegram допускает до 50)
+const InlineResultsLimit = 20
+
+// minQueryLength - запросы короче не выполняются, чтобы не выдавать справочник целиком по одной букве
+const minQueryLength = 2
+
+// maxSearchResults - сколько лучших результатов поиска можно пролистать страницами (предел SearchContacts)
+const maxSearchResults = 100
+
+var (
+	ErrUnknownUser  = errors.New("telegram user is not registered")
+	ErrAccessDenied = errors.New("contact list is not allowed for this user")
+	ErrNotMember    = errors.New("telegram user is not a member of any organization")
+)
+
+// SharedContact - контакт, присланный боту карточкой Telegram
+type SharedContact struct {
+	Phone      string
+	FirstName  string
+	LastName   string
+	TelegramID int64 // 0, если контакт не зарегистрирован в Telegram
+}
+
+// ContactResult - найденный контакт. Поля, скрытые политикой доступа, пусты.
+type ContactResult struct {
+	ID       uint
+	Name     string
+	Phone    string
+	Telegram string
+}
+
+// SearchResults - страница результатов поиска
+type SearchResults struct {
+	Contacts   []ContactResult
+	NextOffset int // Смещение следующей страницы; 0 - страниц больше нет
+}
+
+// UseCase определяет интерфейс бизнес-логики бота.
+type UseCase interface {
+	// SearchContacts ищет активные контакты организации пользователя с Telegram ID telegramID от его имени,
+	// как поиск API: по словам запроса с ранжированием. Права и видимые поля определяются ролью пользователя.
+	SearchContacts(ctx context.Context, telegramID int64, query string, offset int) (*SearchResults, error)
+	// CreateDraftContact сохраняет присланный боту контакт черновиком в организации пользователя с Telegram ID telegramID.
+	// Нужно право на создание контактов; email и остальной профиль заполняются позже в портале.
+	CreateDraftContact(ctx context.Context, telegramID int64, shared SharedContact) (*domain.Contact, error)
+}
+
+type botUseCase struct {
+	authUseCase    authUseCase.UseCase
+	contactUseCase contactUseCase.UseCase
+	orgUseCase     organizationUseCase.UseCase // Организация, в которой работает пользователь бота
+	policy         policyUseCase.UseCase
+	logger         *slog.Logger
+}
+
+// NewBotUseCase создает новый экземпляр botUseCase.
+func NewBotUseCase(au authUseCase.UseCase, cu contactUseCase.UseCase, ou organizationUseCase.UseCase, pu policyUseCase.UseCase, logger *slog.Logger) UseCase {
+	return &botUseCase{
+		authUseCase:    au,
+		contactUseCase: cu,
+		orgUseCase:     ou,
+		policy:         pu,
+		logger:         logger,
+	}
+}
+
+func (uc *botUseCase) SearchContacts(ctx context.Context, telegramID int64, query string, offset int) (*SearchResults, error) {
+	ctx, _, role, err := uc.resolveUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := uc.policy.IsAllowed(ctx, role, policyUseCase.ResourceContacts, policyUseCase.ActionList)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to check contact list permission for bot", slog.Any("error", err))
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrAccessDenied
+	}
+
+	// Имя пользователя Telegram ищется без "@", как оно и сравнивается в поиске
+	query = strings.TrimPrefix(strings.TrimSpace(query), "@")
+	if utf8.RuneCountInString(query) < minQueryLength || offset < 0 || offset >= maxSearchResults {
+		return &SearchResults{}, nil
+	}
+
+	// На одну запись больше страницы, чтобы узнать, есть ли следующая
+	end := offset + InlineResultsLimit
+	contacts, err := uc.contactUseCase.SearchContacts(ctx, role, query, min(end+1, maxSearchResults))
+	if errors.Is(err, contactUseCase.ErrSearchQueryTooLong) {
+		return &SearchResults{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Поиск уже не учитывает скрытые от роли поля, а в ответе они обнуляются
+	if err := uc.contactUseCase.FilterFieldsForRole(ctx, role, contacts); err != nil {
+		return nil, err
+	}
+
+	var matched []ContactResult
+	for _, ct := range contacts {
+		if ct.Status == domain.ContactStatusActive {
+			matched = append(matched, ContactResult{ID: ct.ID, Name: ct.Name, Phone: ct.Phone, Telegram: ct.Telegram})
+		}
+	}
+
+	results := &SearchResults{}
+	if offset >= len(matched) {
+		return results, nil
+	}
+	if end < len(matched) {
+		results.NextOffset = end
+	} else {
+		end = len(matched)
+	}
+	results.Contacts = matched[offset:end]
+	return results, nil
+}
+
+func (uc *botUseCase) CreateDraftContact(ctx context.Context, telegramID int64, shared SharedContact) (*domain.Contact, error) {
+	ctx, user, role, err := uc.resolveUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := uc.policy.IsAllowed(ctx, role, policyUseCase.ResourceContacts, policyUseCase.ActionCreate)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to check contact create permission for bot", slog.Any("error", err))
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrAccessDenied
+	}
+
+	// Telegram присылает номер без "+", если он был записан в адресной книге без него
+	phone := strings.TrimSpace(shared.Phone)
+	if phone != "" && !strings.HasPrefix(phone, "+") {
+		phone = "+" + phone
+	}
+	data := contactUseCase.CreateContactData{
+		Name:      strings.TrimSpace(shared.FirstName + " " + shared.LastName),
+		Phone:     phone,
+		CreatedBy: &user.ID,
+		Draft:     true,
+	}
+	if shared.TelegramID != 0 {
+		data.TelegramID = &shared.TelegramID
+	}
+
+	contact, err := uc.contactUseCase.CreateContact(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Draft contact created from Telegram", slog.Uint64("contactID", uint64(contact.ID)), slog.Uint64("createdBy", uint64(user.ID)))
+	return contact, nil
+}
+
+// resolveUser находит пользователя Telegram, его организацию и роль в ней и возвращает контекст, ограниченный
+// этой организацией, как для запросов API без заголовка X-Organization-ID. Роль admin получают администраторы
+// организации и участники ее группы "Администраторы", остальные - user. Пользователю вне организаций бот отказывает.
+// Отладочный режим, в отличие от API, прав в боте не повышает.
+func (uc *botUseCase) resolveUser(ctx context.Context, telegramID int64) (context.Context, *domain.User, string, error) {
+	user, err := uc.authUseCase.GetUserByTelegramID(ctx, telegramID)
+	if err != nil {
+		if errors.Is(err, authUseCase.ErrUserNotFound) {
+			return nil, nil, "", ErrUnknownUser
+		}
+		uc.logger.ErrorContext(ctx, "Failed to get user for bot request", slog.Int64("telegram_id", telegramID), slog.Any("error", err))
+		return nil, nil, "", err
+	}
+	member, err := uc.orgUseCase.ResolveOrganization(ctx, user, 0)
+	if err != nil {
+		if errors.Is(err, organizationUseCase.ErrNotOrganizationMember) {
+			uc.logger.WarnContext(ctx, "Bot request from user outside organizations", slog.Uint64("user_id", uint64(user.ID)))
+			return nil, nil, "", ErrNotMember
+		}
+		uc.logger.ErrorContext(ctx, "Failed to resolve organization for bot request", slog.Uint64("user_id", uint64(user.ID)), slog.Any("error", err))
+		return nil, nil, "", err
+	}
+	ctx = tenant.With(ctx, member.OrganizationID)
+
+	if member.Role == domain.OrgRoleAdmin {
+		return ctx, user, domain.RoleAdmin, nil
+	}
+	isAdmin, err := uc.authUseCase.IsUserAdmin(ctx, user.ID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if isAdmin {
+		return ctx, user, domain.RoleAdmin, nil
+	}
+	return ctx, user, domain.RoleUser, nil
+}
@@ -4,20 +4,140 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"rim/pkg/secrets"
+	"rim/pkg/securityheaders"
 
 	"github.com/joho/godotenv"
 )
 
+// Поддерживаемые хранилища сессий (SESSION_STORE)
+const (
+	SessionStoreRedis  = "redis"
+	SessionStoreSQLite = "sqlite"
+)
+
 // Config хранит все конфигурационные параметры приложения.
 // Значения читаются из переменных окружения или .env файла.
+// Секреты (*secrets.Secret) также могут читаться из файлов <KEY>_FILE или Vault и обновляются при ротации.
 type Config struct {
 	AppPort        string
 	RedisAddr      string
-	RedisPassword  string
+	RedisPassword  *secrets.Secret
 	RedisDB        int
 	SQLitePath     string
-	BotToken       string
+	SessionStore   string // "redis" или "sqlite"
+	BotToken       *secrets.Secret
 	ForceDebugMode bool
+	// AdminTelegramIDs содержит Telegram ID пользователей, которые всегда считаются администраторами,
+	// даже если не состоят в группе "Администраторы". Нужен для первичной настройки.
+	AdminTelegramIDs []int64
+	// RedisTimeout ограничивает каждую операцию хранилища сессий в Redis, включая установку соединения.
+	// После RedisBreakerFailures сбоев подряд Redis не опрашивается RedisBreakerCooldown,
+	// и запросы с сессией сразу получают 503.
+	RedisTimeout         time.Duration
+	RedisBreakerFailures int
+	RedisBreakerCooldown time.Duration
+	// ListenReusePort открывает порт с SO_REUSEPORT, чтобы новая версия запускалась рядом со старой.
+	// При остановке сервер перестает принимать соединения и до ShutdownTimeout дожидается начатых запросов.
+	ListenReusePort bool
+	ShutdownTimeout time.Duration
+	// SchedulerLockTTL - срок блокировки в Redis, которой экземпляр закрепляет за собой задачи по расписанию;
+	// за это время задачи переходят к другому экземпляру, если лидер завершился аварийно.
+	SchedulerLockTTL time.Duration
+	// SMSGatewayURL - адрес HTTP шлюза для отправки SMS с кодами входа.
+	// Если не задан, SMS пишутся в лог с замаскированными кодами, и вход по телефону недоступен.
+	SMSGatewayURL   string
+	SMSGatewayToken *secrets.Secret
+	// PhotoCacheDir - каталог для кэша фото профилей Telegram
+	PhotoCacheDir string
+	// AvatarSyncInterval - как часто запускается задача аватаров контактов, 0 - задача отключена
+	AvatarSyncInterval time.Duration
+	// PrintJobDir - каталог файлов заявок на печать
+	PrintJobDir string
+	// PDFFontPath - шрифт TrueType с кириллицей для печатных карточек и списков групп (PDF)
+	PDFFontPath string
+	// ImportRollbackDays - сколько дней после подтверждения импорт можно откатить
+	ImportRollbackDays int
+	// GoogleSheetsCredentialsFile - путь к JSON-ключу сервисного аккаунта Google.
+	// Если не задан, выгрузка в Google Sheets отключена.
+	GoogleSheetsCredentialsFile string
+	// GoogleSheetsSpreadsheetID и GoogleSheetsSheet - таблица и лист по умолчанию
+	GoogleSheetsSpreadsheetID string
+	GoogleSheetsSheet         string
+	// GoogleSheetsSyncInterval - период автоматической выгрузки, 0 - только по запросу
+	GoogleSheetsSyncInterval time.Duration
+	// GoogleSheetsSyncFilter - фильтр контактов для автоматической выгрузки в виде query-строки,
+	// например "status=active&group_id=2"
+	GoogleSheetsSyncFilter string
+	// GoogleSheetsSyncTemplateID - шаблон столбцов автоматической выгрузки, 0 - все столбцы
+	GoogleSheetsSyncTemplateID uint
+	// HRSyncURL - адрес списка сотрудников в REST API кадровой системы; пусто - синхронизация отключена.
+	// Изменения полей HRSyncPushFields отправляются запросом PATCH {HRSyncURL}/{id}.
+	HRSyncURL   string
+	HRSyncToken *secrets.Secret
+	// HRSyncRecordsKey - поле ответа с массивом записей; пусто - массив в корне ответа
+	HRSyncRecordsKey string
+	// HRSyncMapping - сопоставление полей контакта полям записи: "id=employee_id,name=full_name,phone=mobile,email=work_email"
+	HRSyncMapping string
+	// HRSyncPushFields - поля контакта через запятую, которыми владеет портал и которые отправляются в источник
+	HRSyncPushFields string
+	// HRSyncInterval - период автоматической синхронизации, 0 - только по запросу администратора
+	HRSyncInterval time.Duration
+	// NotifyAdminGroupID - группа, участники которой получают уведомления о создании и удалении
+	// контактов и смене телефона/email. 0 - уведомления отключены.
+	NotifyAdminGroupID uint
+	// NotifyDigestInterval - как часто накопленные события отправляются одним дайджестом
+	NotifyDigestInterval time.Duration
+	// MembershipExpiryInterval - как часто контакты с истекшим сроком членства исключаются из групп
+	MembershipExpiryInterval time.Duration
+	// HealthCheckInterval - как часто проверяются внешние зависимости для /system/status.
+	// По последней проверке Redis маршруты с сессией отвечают 503, пока он недоступен.
+	HealthCheckInterval time.Duration
+	// WeeklyDigestWeekday - день недели еженедельной сводки изменений справочника (1 - понедельник, 7 - воскресенье),
+	// 0 - сводка отключена. WeeklyDigestHour - час отправки в часовом поясе пользователя.
+	WeeklyDigestWeekday int
+	WeeklyDigestHour    int
+	// SMTP-сервер для уведомлений по email. Если SMTPAddr не задан, email не отправляется.
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword *secrets.Secret
+	SMTPFrom     string
+	// Ключи VAPID для уведомлений Web Push (go run ./cmd/vapid-keys). Если ключи не заданы, Web Push отключен.
+	VAPIDPublicKey  string
+	VAPIDPrivateKey *secrets.Secret
+	// VAPIDSubject - контакт администратора для сервисов доставки: mailto: или https-адрес
+	VAPIDSubject string
+	// EncryptionKey - ключ AES-256 в base64 для шифрования телефона и аллергий контактов.
+	// Если не задан, значения хранятся открыто.
+	EncryptionKey string
+	// EncryptionPreviousKeys - прежние ключи, нужные для расшифровки данных до ротации
+	EncryptionPreviousKeys []string
+	// MetricsToken - токен доступа к /metrics; если пуст, метрики доступны без авторизации
+	MetricsToken *secrets.Secret
+	// MetricsAlertChatID - чат Telegram, куда бот (BOT_TOKEN) отправляет тревоги SLO; 0 - тревоги отключены
+	MetricsAlertChatID int64
+	// Пороги тревог: p95 длительности и доля ответов 5xx маршрута за интервал MetricsAlertInterval.
+	// Маршруты с числом запросов меньше MetricsAlertMinRequests не оцениваются.
+	MetricsAlertP95         time.Duration
+	MetricsAlertErrorRate   float64
+	MetricsAlertMinRequests uint64
+	MetricsAlertInterval    time.Duration
+	// TelegramWebhookSecret - секрет вебхука бота (заголовок X-Telegram-Bot-Api-Secret-Token);
+	// если пуст, вебхук и встроенный поиск контактов в Telegram отключены
+	TelegramWebhookSecret *secrets.Secret
+	// PortalURL - адрес веб-портала для ссылок, которые бот отправляет в Telegram
+	PortalURL string
+	// PublicDirectoryCacheTTL - время кэширования ответов публичного справочника /api/public/v1
+	PublicDirectoryCacheTTL time.Duration
+	// Security - заголовки безопасности и правила CORS: профиль окружения APP_ENV с переопределениями
+	// CORS_ALLOW_ORIGINS, CORS_MAX_AGE_SECONDS, HSTS_MAX_AGE_SECONDS, FRAME_OPTIONS и REFERRER_POLICY
+	Security securityheaders.Profile
+	// Secrets перечитывает секреты из файлов и Vault; SecretsRefreshInterval - как часто
+	Secrets                *secrets.Loader
+	SecretsRefreshInterval time.Duration
 }
 
 // LoadConfig загружает конфигурацию из переменных окружения.
@@ -29,13 +149,131 @@ func LoadConfig() (*Config, error) {
 		log.Println("No .env file found, reading from environment variables")
 	}
 
+	// Секреты могут храниться в Vault: VAULT_SECRET_PATH указывает на секрет с полями BOT_TOKEN, REDIS_PASSWORD и т.д.
+	secretLoader, err := secrets.NewLoader(secrets.VaultConfig{
+		Addr:      getEnv("VAULT_ADDR", ""),
+		Token:     getEnv("VAULT_TOKEN", ""),
+		TokenFile: getEnv("VAULT_TOKEN_FILE", ""),
+		Path:      getEnv("VAULT_SECRET_PATH", ""),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// loadSecret сохраняет первую ошибку в err; после нее остальные секреты не загружаются
+	loadSecret := func(key, defaultValue string) *secrets.Secret {
+		if err != nil {
+			return nil
+		}
+		var secret *secrets.Secret
+		secret, err = secretLoader.Load(key, defaultValue)
+		return secret
+	}
+
 	appPort := getEnv("APP_PORT", "3000")
 	redisAddr := getEnv("REDIS_ADDR", "localhost:6379")
-	redisPassword := getEnv("REDIS_PASSWORD", "")
+	redisPassword := loadSecret("REDIS_PASSWORD", "")
 	redisDBStr := getEnv("REDIS_DB", "0")
+	redisTimeoutMsStr := getEnv("REDIS_TIMEOUT_MS", "500")
+	redisBreakerFailuresStr := getEnv("REDIS_BREAKER_FAILURES", "5")
+	redisBreakerCooldownSecondsStr := getEnv("REDIS_BREAKER_COOLDOWN_SECONDS", "10")
+	listenReusePortStr := getEnv("LISTEN_REUSEPORT", "false")
+	shutdownTimeoutSecondsStr := getEnv("SHUTDOWN_TIMEOUT_SECONDS", "30")
+	schedulerLockTTLSecondsStr := getEnv("SCHEDULER_LOCK_TTL_SECONDS", "30")
 	sqlitePath := getEnv("SQLITE_PATH", "./rim.db")
-	botToken := getEnv("BOT_TOKEN", "7190707372:AAHGNCZr8dhT9kJ40rBa1wdLa1cHqANGXJA")
+	sessionStore := getEnv("SESSION_STORE", SessionStoreRedis)
+	botToken := loadSecret("BOT_TOKEN", "7190707372:AAHGNCZr8dhT9kJ40rBa1wdLa1cHqANGXJA")
 	forceDebugModeStr := getEnv("DEBUG_MODE", "false")
+	adminTelegramIDsStr := getEnv("ADMIN_TELEGRAM_IDS", "")
+	smsGatewayURL := getEnv("SMS_GATEWAY_URL", "")
+	smsGatewayToken := loadSecret("SMS_GATEWAY_TOKEN", "")
+	photoCacheDir := getEnv("PHOTO_CACHE_DIR", "./data/photos")
+	avatarSyncMinutesStr := getEnv("AVATAR_SYNC_INTERVAL_MINUTES", "60")
+	printJobDir := getEnv("PRINT_JOB_DIR", "./data/print_jobs")
+	pdfFontPath := getEnv("PDF_FONT_PATH", "/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf")
+	importRollbackDaysStr := getEnv("IMPORT_ROLLBACK_DAYS", "7")
+	googleSheetsCredentialsFile := getEnv("GOOGLE_SHEETS_CREDENTIALS_FILE", "")
+	googleSheetsSpreadsheetID := getEnv("GOOGLE_SHEETS_SPREADSHEET_ID", "")
+	googleSheetsSheet := getEnv("GOOGLE_SHEETS_SHEET", "Contacts")
+	googleSheetsSyncMinutesStr := getEnv("GOOGLE_SHEETS_SYNC_INTERVAL_MINUTES", "0")
+	googleSheetsSyncFilter := getEnv("GOOGLE_SHEETS_SYNC_FILTER", "")
+	googleSheetsSyncTemplateStr := getEnv("GOOGLE_SHEETS_SYNC_TEMPLATE_ID", "0")
+	hrSyncURL := getEnv("HR_SYNC_URL", "")
+	hrSyncToken := loadSecret("HR_SYNC_TOKEN", "")
+	hrSyncRecordsKey := getEnv("HR_SYNC_RECORDS_KEY", "")
+	hrSyncMapping := getEnv("HR_SYNC_MAPPING", "id=id,name=name,phone=phone,email=email")
+	hrSyncPushFields := getEnv("HR_SYNC_PUSH_FIELDS", "")
+	hrSyncMinutesStr := getEnv("HR_SYNC_INTERVAL_MINUTES", "0")
+	notifyAdminGroupIDStr := getEnv("NOTIFY_ADMIN_GROUP_ID", "0")
+	notifyDigestSecondsStr := getEnv("NOTIFY_DIGEST_INTERVAL_SECONDS", "60")
+	membershipExpiryMinutesStr := getEnv("MEMBERSHIP_EXPIRY_CHECK_MINUTES", "60")
+	healthCheckSecondsStr := getEnv("HEALTH_CHECK_INTERVAL_SECONDS", "60")
+	weeklyDigestWeekdayStr := getEnv("WEEKLY_DIGEST_WEEKDAY", "0")
+	weeklyDigestHourStr := getEnv("WEEKLY_DIGEST_HOUR", "9")
+	smtpAddr := getEnv("SMTP_ADDR", "")
+	smtpUsername := getEnv("SMTP_USERNAME", "")
+	smtpPassword := loadSecret("SMTP_PASSWORD", "")
+	smtpFrom := getEnv("SMTP_FROM", "")
+	vapidPublicKey := getEnv("VAPID_PUBLIC_KEY", "")
+	vapidPrivateKey := loadSecret("VAPID_PRIVATE_KEY", "")
+	vapidSubject := getEnv("VAPID_SUBJECT", "")
+	// Ключи шифрования читаются один раз: смена ключа требует перешифровки (cmd/rotate-key)
+	encryptionKey := loadSecret("ENCRYPTION_KEY", "")
+	encryptionPreviousKeys := loadSecret("ENCRYPTION_PREVIOUS_KEYS", "")
+	secretsRefreshSecondsStr := getEnv("SECRETS_REFRESH_INTERVAL_SECONDS", "60")
+	metricsToken := loadSecret("METRICS_TOKEN", "")
+	telegramWebhookSecret := loadSecret("TELEGRAM_WEBHOOK_SECRET", "")
+	portalURL := strings.TrimSuffix(getEnv("PORTAL_URL", "http://localhost"), "/")
+	metricsAlertChatIDStr := getEnv("METRICS_ALERT_CHAT_ID", "0")
+	metricsAlertP95MsStr := getEnv("METRICS_ALERT_P95_MS", "1000")
+	metricsAlertErrorRateStr := getEnv("METRICS_ALERT_ERROR_RATE_PERCENT", "5")
+	metricsAlertMinRequestsStr := getEnv("METRICS_ALERT_MIN_REQUESTS", "20")
+	metricsAlertSecondsStr := getEnv("METRICS_ALERT_INTERVAL_SECONDS", "60")
+	publicDirectoryCacheSecondsStr := getEnv("PUBLIC_DIRECTORY_CACHE_SECONDS", "300")
+	if err != nil {
+		return nil, err
+	}
+
+	secretsRefreshSeconds, err := strconv.Atoi(secretsRefreshSecondsStr)
+	if err != nil || secretsRefreshSeconds <= 0 {
+		log.Printf("Invalid SECRETS_REFRESH_INTERVAL_SECONDS value: %s. Using default 60.", secretsRefreshSecondsStr)
+		secretsRefreshSeconds = 60
+	}
+
+	metricsAlertChatID, err := strconv.ParseInt(metricsAlertChatIDStr, 10, 64)
+	if err != nil {
+		log.Printf("Invalid METRICS_ALERT_CHAT_ID value: %s. SLO alerts disabled.", metricsAlertChatIDStr)
+		metricsAlertChatID = 0
+	}
+
+	metricsAlertP95Ms, err := strconv.Atoi(metricsAlertP95MsStr)
+	if err != nil || metricsAlertP95Ms < 0 {
+		log.Printf("Invalid METRICS_ALERT_P95_MS value: %s. Using default 1000.", metricsAlertP95MsStr)
+		metricsAlertP95Ms = 1000
+	}
+
+	metricsAlertErrorRate, err := strconv.ParseFloat(metricsAlertErrorRateStr, 64)
+	if err != nil || metricsAlertErrorRate < 0 || metricsAlertErrorRate > 100 {
+		log.Printf("Invalid METRICS_ALERT_ERROR_RATE_PERCENT value: %s. Using default 5.", metricsAlertErrorRateStr)
+		metricsAlertErrorRate = 5
+	}
+
+	metricsAlertMinRequests, err := strconv.ParseUint(metricsAlertMinRequestsStr, 10, 64)
+	if err != nil {
+		log.Printf("Invalid METRICS_ALERT_MIN_REQUESTS value: %s. Using default 20.", metricsAlertMinRequestsStr)
+		metricsAlertMinRequests = 20
+	}
+
+	metricsAlertSeconds, err := strconv.Atoi(metricsAlertSecondsStr)
+	if err != nil || metricsAlertSeconds <= 0 {
+		log.Printf("Invalid METRICS_ALERT_INTERVAL_SECONDS value: %s. Using default 60.", metricsAlertSecondsStr)
+		metricsAlertSeconds = 60
+	}
+
+	publicDirectoryCacheSeconds, err := strconv.Atoi(publicDirectoryCacheSecondsStr)
+	if err != nil || publicDirectoryCacheSeconds <= 0 {
+		log.Printf("Invalid PUBLIC_DIRECTORY_CACHE_SECONDS value: %s. Using default 300.", publicDirectoryCacheSecondsStr)
+		publicDirectoryCacheSeconds = 300
+	}
 
 	redisDB, err := strconv.Atoi(redisDBStr)
 	if err != nil {
@@ -43,23 +281,286 @@ func LoadConfig() (*Config, error) {
 		redisDB = 0 // Используем значение по умолчанию в случае ошибки
 	}
 
+	redisTimeoutMs, err := strconv.Atoi(redisTimeoutMsStr)
+	if err != nil || redisTimeoutMs <= 0 {
+		log.Printf("Invalid REDIS_TIMEOUT_MS value: %s. Using default 500.", redisTimeoutMsStr)
+		redisTimeoutMs = 500
+	}
+
+	redisBreakerFailures, err := strconv.Atoi(redisBreakerFailuresStr)
+	if err != nil || redisBreakerFailures <= 0 {
+		log.Printf("Invalid REDIS_BREAKER_FAILURES value: %s. Using default 5.", redisBreakerFailuresStr)
+		redisBreakerFailures = 5
+	}
+
+	redisBreakerCooldownSeconds, err := strconv.Atoi(redisBreakerCooldownSecondsStr)
+	if err != nil || redisBreakerCooldownSeconds <= 0 {
+		log.Printf("Invalid REDIS_BREAKER_COOLDOWN_SECONDS value: %s. Using default 10.", redisBreakerCooldownSecondsStr)
+		redisBreakerCooldownSeconds = 10
+	}
+
+	listenReusePort, err := strconv.ParseBool(listenReusePortStr)
+	if err != nil {
+		log.Printf("Invalid LISTEN_REUSEPORT value: %s. Using default false.", listenReusePortStr)
+		listenReusePort = false
+	}
+
+	shutdownTimeoutSeconds, err := strconv.Atoi(shutdownTimeoutSecondsStr)
+	if err != nil || shutdownTimeoutSeconds <= 0 {
+		log.Printf("Invalid SHUTDOWN_TIMEOUT_SECONDS value: %s. Using default 30.", shutdownTimeoutSecondsStr)
+		shutdownTimeoutSeconds = 30
+	}
+
+	schedulerLockTTLSeconds, err := strconv.Atoi(schedulerLockTTLSecondsStr)
+	if err != nil || schedulerLockTTLSeconds < 3 {
+		log.Printf("Invalid SCHEDULER_LOCK_TTL_SECONDS value: %s. Using default 30.", schedulerLockTTLSecondsStr)
+		schedulerLockTTLSeconds = 30
+	}
+
+	if sessionStore != SessionStoreRedis && sessionStore != SessionStoreSQLite {
+		log.Printf("Invalid SESSION_STORE value: %s. Using default %s.", sessionStore, SessionStoreRedis)
+		sessionStore = SessionStoreRedis
+	}
+
 	forceDebugMode, err := strconv.ParseBool(forceDebugModeStr)
 	if err != nil {
 		log.Printf("Invalid DEBUG_MODE value: %s. Using default false. Error: %v", forceDebugModeStr, err)
 		forceDebugMode = false
 	}
 
+	importRollbackDays, err := strconv.Atoi(importRollbackDaysStr)
+	if err != nil || importRollbackDays < 0 {
+		log.Printf("Invalid IMPORT_ROLLBACK_DAYS value: %s. Using default 7.", importRollbackDaysStr)
+		importRollbackDays = 7
+	}
+
+	googleSheetsSyncMinutes, err := strconv.Atoi(googleSheetsSyncMinutesStr)
+	if err != nil || googleSheetsSyncMinutes < 0 {
+		log.Printf("Invalid GOOGLE_SHEETS_SYNC_INTERVAL_MINUTES value: %s. Scheduled export disabled.", googleSheetsSyncMinutesStr)
+		googleSheetsSyncMinutes = 0
+	}
+
+	googleSheetsSyncTemplateID, err := strconv.ParseUint(googleSheetsSyncTemplateStr, 10, 32)
+	if err != nil {
+		log.Printf("Invalid GOOGLE_SHEETS_SYNC_TEMPLATE_ID value: %s. Using all columns.", googleSheetsSyncTemplateStr)
+		googleSheetsSyncTemplateID = 0
+	}
+
+	hrSyncMinutes, err := strconv.Atoi(hrSyncMinutesStr)
+	if err != nil || hrSyncMinutes < 0 {
+		log.Printf("Invalid HR_SYNC_INTERVAL_MINUTES value: %s. Scheduled HR sync disabled.", hrSyncMinutesStr)
+		hrSyncMinutes = 0
+	}
+
+	notifyAdminGroupID, err := strconv.ParseUint(notifyAdminGroupIDStr, 10, 32)
+	if err != nil {
+		log.Printf("Invalid NOTIFY_ADMIN_GROUP_ID value: %s. Notifications disabled.", notifyAdminGroupIDStr)
+		notifyAdminGroupID = 0
+	}
+
+	notifyDigestSeconds, err := strconv.Atoi(notifyDigestSecondsStr)
+	if err != nil || notifyDigestSeconds <= 0 {
+		log.Printf("Invalid NOTIFY_DIGEST_INTERVAL_SECONDS value: %s. Using default 60.", notifyDigestSecondsStr)
+		notifyDigestSeconds = 60
+	}
+
+	avatarSyncMinutes, err := strconv.Atoi(avatarSyncMinutesStr)
+	if err != nil || avatarSyncMinutes < 0 {
+		log.Printf("Invalid AVATAR_SYNC_INTERVAL_MINUTES value: %s. Using default 60.", avatarSyncMinutesStr)
+		avatarSyncMinutes = 60
+	}
+
+	membershipExpiryMinutes, err := strconv.Atoi(membershipExpiryMinutesStr)
+	if err != nil || membershipExpiryMinutes <= 0 {
+		log.Printf("Invalid MEMBERSHIP_EXPIRY_CHECK_MINUTES value: %s. Using default 60.", membershipExpiryMinutesStr)
+		membershipExpiryMinutes = 60
+	}
+
+	healthCheckSeconds, err := strconv.Atoi(healthCheckSecondsStr)
+	if err != nil || healthCheckSeconds <= 0 {
+		log.Printf("Invalid HEALTH_CHECK_INTERVAL_SECONDS value: %s. Using default 60.", healthCheckSecondsStr)
+		healthCheckSeconds = 60
+	}
+
+	weeklyDigestWeekday, err := strconv.Atoi(weeklyDigestWeekdayStr)
+	if err != nil || weeklyDigestWeekday < 0 || weeklyDigestWeekday > 7 {
+		log.Printf("Invalid WEEKLY_DIGEST_WEEKDAY value: %s. Weekly digest disabled.", weeklyDigestWeekdayStr)
+		weeklyDigestWeekday = 0
+	}
+
+	weeklyDigestHour, err := strconv.Atoi(weeklyDigestHourStr)
+	if err != nil || weeklyDigestHour < 0 || weeklyDigestHour > 23 {
+		log.Printf("Invalid WEEKLY_DIGEST_HOUR value: %s. Using default 9.", weeklyDigestHourStr)
+		weeklyDigestHour = 9
+	}
+
 	return &Config{
-		AppPort:        appPort,
-		RedisAddr:      redisAddr,
-		RedisPassword:  redisPassword,
-		RedisDB:        redisDB,
-		SQLitePath:     sqlitePath,
-		BotToken:       botToken,
-		ForceDebugMode: forceDebugMode,
+		AppPort:            appPort,
+		RedisAddr:          redisAddr,
+		RedisPassword:      redisPassword,
+		RedisDB:            redisDB,
+		SQLitePath:         sqlitePath,
+		SessionStore:       sessionStore,
+		BotToken:           botToken,
+		ForceDebugMode:     forceDebugMode,
+		AdminTelegramIDs:   parseInt64List("ADMIN_TELEGRAM_IDS", adminTelegramIDsStr),
+		SMSGatewayURL:      smsGatewayURL,
+		SMSGatewayToken:    smsGatewayToken,
+		PhotoCacheDir:      photoCacheDir,
+		AvatarSyncInterval: time.Duration(avatarSyncMinutes) * time.Minute,
+		PrintJobDir:        printJobDir,
+		PDFFontPath:        pdfFontPath,
+		ImportRollbackDays: importRollbackDays,
+
+		RedisTimeout:         time.Duration(redisTimeoutMs) * time.Millisecond,
+		RedisBreakerFailures: redisBreakerFailures,
+		RedisBreakerCooldown: time.Duration(redisBreakerCooldownSeconds) * time.Second,
+
+		ListenReusePort:  listenReusePort,
+		ShutdownTimeout:  time.Duration(shutdownTimeoutSeconds) * time.Second,
+		SchedulerLockTTL: time.Duration(schedulerLockTTLSeconds) * time.Second,
+
+		GoogleSheetsCredentialsFile: googleSheetsCredentialsFile,
+		GoogleSheetsSpreadsheetID:   googleSheetsSpreadsheetID,
+		GoogleSheetsSheet:           googleSheetsSheet,
+		GoogleSheetsSyncInterval:    time.Duration(googleSheetsSyncMinutes) * time.Minute,
+		GoogleSheetsSyncFilter:      googleSheetsSyncFilter,
+		GoogleSheetsSyncTemplateID:  uint(googleSheetsSyncTemplateID),
+		HRSyncURL:                   hrSyncURL,
+		HRSyncToken:                 hrSyncToken,
+		HRSyncRecordsKey:            hrSyncRecordsKey,
+		HRSyncMapping:               hrSyncMapping,
+		HRSyncPushFields:            hrSyncPushFields,
+		HRSyncInterval:              time.Duration(hrSyncMinutes) * time.Minute,
+
+		NotifyAdminGroupID:       uint(notifyAdminGroupID),
+		NotifyDigestInterval:     time.Duration(notifyDigestSeconds) * time.Second,
+		MembershipExpiryInterval: time.Duration(membershipExpiryMinutes) * time.Minute,
+		HealthCheckInterval:      time.Duration(healthCheckSeconds) * time.Second,
+		WeeklyDigestWeekday:      weeklyDigestWeekday,
+		WeeklyDigestHour:         weeklyDigestHour,
+		SMTPAddr:                 smtpAddr,
+		SMTPUsername:             smtpUsername,
+		SMTPPassword:             smtpPassword,
+		SMTPFrom:                 smtpFrom,
+		VAPIDPublicKey:           vapidPublicKey,
+		VAPIDPrivateKey:          vapidPrivateKey,
+		VAPIDSubject:             vapidSubject,
+		EncryptionKey:            encryptionKey.Get(),
+		EncryptionPreviousKeys:   parseStringList(encryptionPreviousKeys.Get()),
+		MetricsToken:             metricsToken,
+		MetricsAlertChatID:       metricsAlertChatID,
+		MetricsAlertP95:          time.Duration(metricsAlertP95Ms) * time.Millisecond,
+		MetricsAlertErrorRate:    metricsAlertErrorRate / 100,
+		MetricsAlertMinRequests:  metricsAlertMinRequests,
+		MetricsAlertInterval:     time.Duration(metricsAlertSeconds) * time.Second,
+		TelegramWebhookSecret:    telegramWebhookSecret,
+		PortalURL:                portalURL,
+		PublicDirectoryCacheTTL:  time.Duration(publicDirectoryCacheSeconds) * time.Second,
+		Security:                 loadSecurityProfile(),
+		Secrets:                  secretLoader,
+		SecretsRefreshInterval:   time.Duration(secretsRefreshSeconds) * time.Second,
 	}, nil
 }
 
+// loadSecurityProfile возвращает профиль заголовков безопасности окружения APP_ENV.
+// Переменные, заданные явно (в том числе пустыми), заменяют значения профиля.
+func loadSecurityProfile() securityheaders.Profile {
+	appEnv := getEnv("APP_ENV", securityheaders.EnvDevelopment)
+	profile, ok := securityheaders.ForEnvironment(appEnv)
+	if !ok {
+		log.Printf("Invalid APP_ENV value: %s. Using default %s.", appEnv, securityheaders.EnvDevelopment)
+		profile, _ = securityheaders.ForEnvironment(securityheaders.EnvDevelopment)
+	}
+
+	if value, ok := os.LookupEnv("CORS_ALLOW_ORIGINS"); ok {
+		profile.CORSAllowOrigins = parseStringList(value)
+	}
+	if value, ok := os.LookupEnv("CORS_MAX_AGE_SECONDS"); ok {
+		if seconds, err := strconv.Atoi(value); err != nil || seconds < 0 {
+			log.Printf("Invalid CORS_MAX_AGE_SECONDS value: %s. Using profile value.", value)
+		} else {
+			profile.CORSMaxAge = time.Duration(seconds) * time.Second
+		}
+	}
+	if value, ok := os.LookupEnv("HSTS_MAX_AGE_SECONDS"); ok {
+		if seconds, err := strconv.Atoi(value); err != nil || seconds < 0 {
+			log.Printf("Invalid HSTS_MAX_AGE_SECONDS value: %s. Using profile value.", value)
+		} else {
+			profile.HSTSMaxAge = time.Duration(seconds) * time.Second
+		}
+	}
+	if value, ok := os.LookupEnv("FRAME_OPTIONS"); ok {
+		switch value = strings.ToUpper(strings.TrimSpace(value)); value {
+		case "DENY", "SAMEORIGIN", "":
+			profile.FrameOptions = value
+		default:
+			log.Printf("Invalid FRAME_OPTIONS value: %s. Using profile value.", value)
+		}
+	}
+	if value, ok := os.LookupEnv("REFERRER_POLICY"); ok {
+		profile.ReferrerPolicy = strings.TrimSpace(value)
+	}
+
+	if value, ok := os.LookupEnv("COOKIE_SECURE"); ok {
+		if secure, err := strconv.ParseBool(value); err != nil {
+			log.Printf("Invalid COOKIE_SECURE value: %s. Using profile value.", value)
+		} else {
+			profile.Cookies.Secure = secure
+		}
+	}
+	if value, ok := os.LookupEnv("COOKIE_SAMESITE"); ok {
+		switch value = strings.ToLower(strings.TrimSpace(value)); value {
+		case "strict", "lax", "none":
+			profile.Cookies.SameSite = value
+		default:
+			log.Printf("Invalid COOKIE_SAMESITE value: %s. Using profile value.", value)
+		}
+	}
+	if value, ok := os.LookupEnv("COOKIE_DOMAIN"); ok {
+		profile.Cookies.Domain = strings.TrimSpace(value)
+	}
+	if value := strings.TrimSpace(os.Getenv("SESSION_COOKIE_NAME")); value != "" {
+		profile.Cookies.SessionName = value
+	}
+	// Браузеры отклоняют SameSite=None без Secure
+	if profile.Cookies.SameSite == "none" && !profile.Cookies.Secure {
+		log.Printf("COOKIE_SAMESITE=None requires Secure cookies. Enabling COOKIE_SECURE.")
+		profile.Cookies.Secure = true
+	}
+	return profile
+}
+
+// parseInt64List разбирает список чисел, разделенных запятыми.
+// Некорректные значения пропускаются с записью в лог.
+func parseInt64List(key, value string) []int64 {
+	var result []int64
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			log.Printf("Invalid %s entry: %s. Skipping. Error: %v", key, part, err)
+			continue
+		}
+		result = append(result, id)
+	}
+	return result
+}
+
+// parseStringList разбирает список строк, разделенных запятыми, пропуская пустые значения.
+func parseStringList(value string) []string {
+	var result []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
 // getEnv читает переменную окружения или возвращает значение по умолчанию.
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
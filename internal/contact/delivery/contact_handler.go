@@ -1,12 +1,16 @@
 package delivery
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 
 	authUseCase "rim/internal/auth/usecase"
@@ -14,6 +18,14 @@ import (
 	"rim/internal/domain"
 	groupDelivery "rim/internal/group/delivery"
 	groupUseCase "rim/internal/group/usecase"
+	locationUseCase "rim/internal/location/usecase"
+	skillDelivery "rim/internal/skill/delivery"
+	skillUseCase "rim/internal/skill/usecase"
+	tagDelivery "rim/internal/tag/delivery"
+	"rim/pkg/requestctx"
+	"rim/pkg/tenant"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
 )
 
 // Handler отвечает за обработку HTTP-запросов, связанных с контактами.
@@ -21,7 +33,7 @@ type Handler struct {
 	contactUseCase contactUseCase.UseCase
 	authUseCase    authUseCase.UseCase
 	logger         *slog.Logger
-	validate       *validator.Validate
+	guestCache     *guestDirectoryCache
 }
 
 // NewHandler создает новый экземпляр Handler для контактов.
@@ -30,42 +42,7 @@ func NewHandler(cu contactUseCase.UseCase, au authUseCase.UseCase, logger *slog.
 		contactUseCase: cu,
 		authUseCase:    au,
 		logger:         logger,
-		validate:       validator.New(),
-	}
-}
-
-// RequireAdmin middleware для проверки прав администратора
-func (h *Handler) RequireAdmin() fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		// Получаем пользователя из контекста (должен быть установлен AuthMiddleware)
-		userID, ok := c.Locals("user_id").(uint)
-		if !ok {
-			h.logger.ErrorContext(c.Context(), "User ID not found in context", slog.Any("user_id_raw", c.Locals("user_id")))
-			return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{
-				Message: "Unauthorized",
-			})
-		}
-
-		h.logger.InfoContext(c.Context(), "Checking admin rights", slog.Uint64("user_id", uint64(userID)))
-
-		// Проверяем права администратора
-		isAdmin, err := h.authUseCase.IsUserAdmin(c.Context(), userID)
-		if err != nil {
-			h.logger.ErrorContext(c.Context(), "Failed to check admin status", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
-			return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{
-				Message: "Internal server error",
-			})
-		}
-
-		h.logger.InfoContext(c.Context(), "Admin check result", slog.Uint64("user_id", uint64(userID)), slog.Bool("is_admin", isAdmin))
-
-		if !isAdmin {
-			return c.Status(fiber.StatusForbidden).JSON(groupDelivery.ErrorResponse{
-				Message: "Admin rights required",
-			})
-		}
-
-		return c.Next()
+		guestCache:     newGuestDirectoryCache(),
 	}
 }
 
@@ -77,21 +54,15 @@ func (h *Handler) RequireAdmin() fiber.Handler {
 // @Produce json
 // @Param contact body CreateContactRequest true "Данные для создания контакта"
 // @Success 201 {object} ContactResponse "Контакт успешно создан"
-// @Failure 400 {object} groupDelivery.ErrorResponse "Ошибка валидации или некорректный запрос"
+// @Failure 400 {object} validation.Response "Ошибка валидации или некорректный запрос"
 // @Failure 404 {object} groupDelivery.ErrorResponse "Одна из указанных групп не найдена"
 // @Failure 409 {object} groupDelivery.ErrorResponse "Контакт с таким email или телефоном уже существует"
 // @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /contacts [post]
 func (h *Handler) CreateContact(c *fiber.Ctx) error {
-	var req CreateContactRequest
-	if err := c.BodyParser(&req); err != nil {
-		h.logger.WarnContext(c.Context(), "Failed to parse request body for create contact", slog.Any("error", err))
-		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid request body"})
-	}
-
-	if err := h.validate.Struct(req); err != nil {
-		h.logger.WarnContext(c.Context(), "Validation failed for create contact request", slog.Any("error", err))
-		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: fmt.Sprintf("Validation failed: %s", err.Error())})
+	req, ok, err := validation.BindAndValidate[CreateContactRequest](c)
+	if !ok {
+		return err
 	}
 
 	ucData := contactUseCase.CreateContactData{
@@ -105,11 +76,20 @@ func (h *Handler) CreateContact(c *fiber.Ctx) error {
 		Telegram:   req.Telegram,
 		TelegramID: req.TelegramID,
 		GroupIDs:   req.GroupIDs,
+		Location: locationUseCase.Location{
+			City:     req.City,
+			Campus:   req.Campus,
+			Building: req.Building,
+			Room:     req.Room,
+		},
+		Department: req.Department,
+		Position:   req.Position,
+		CreatedBy:  requestctx.ActorID(c),
 	}
 
 	contact, err := h.contactUseCase.CreateContact(c.Context(), ucData)
 	if err != nil {
-		if errors.Is(err, contactUseCase.ErrContactNameEmpty) || errors.Is(err, contactUseCase.ErrContactPhoneEmpty) || errors.Is(err, contactUseCase.ErrContactEmailEmpty) {
+		if errors.Is(err, contactUseCase.ErrContactNameEmpty) || errors.Is(err, contactUseCase.ErrContactPhoneEmpty) || errors.Is(err, contactUseCase.ErrContactEmailEmpty) || errors.Is(err, locationUseCase.ErrUnknownLocation) {
 			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
 		}
 		if errors.Is(err, contactUseCase.ErrContactEmailExists) || errors.Is(err, contactUseCase.ErrContactPhoneExists) {
@@ -122,7 +102,7 @@ func (h *Handler) CreateContact(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(toContactResponse(contact))
+	return c.Status(fiber.StatusCreated).JSON(toContactResponse(contact, requestctx.Location(c)))
 }
 
 // GetContactByID обрабатывает запрос на получение контакта по ID.
@@ -151,52 +131,221 @@ func (h *Handler) GetContactByID(c *fiber.Ctx) error {
 		h.logger.ErrorContext(c.Context(), "Failed to get contact by ID from use case", slog.Uint64("id", contactID), slog.Any("error", err))
 		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
 	}
-	return c.Status(fiber.StatusOK).JSON(toContactResponse(contact))
+
+	filtered := []domain.Contact{*contact}
+	if err := h.contactUseCase.FilterFieldsForRole(c.Context(), requestctx.Role(c), filtered); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	return c.Status(fiber.StatusOK).JSON(toContactResponse(&filtered[0], requestctx.Location(c)))
 }
 
 // GetAllContacts обрабатывает запрос на получение всех контактов.
 // @Summary Получить все контакты
-// @Description Возвращает список всех контактов. Для неавторизованных пользователей возвращает только имена.
+// @Description Возвращает список всех контактов. Для неавторизованных пользователей возвращает только имена, остальным - поля, разрешенные политикой доступа.
+// @Description Анонимные запросы ограничены отдельно (группы лимитов anonymous_directory и suspected_bots). При исчерпанном лимите запрос без параметров
+// @Description получает список имен из кэша (до 5 минут) с заголовками X-RateLimit-Throttled и Retry-After, запрос с параметрами - 429.
 // @Tags contacts
 // @Produce json
+// @Param skills query string false "Навыки через запятую: контакты, обладающие хотя бы одним из них (например, design,video,sound)"
+// @Param tag query string false "Метки через запятую: контакты хотя бы с одной из них (GET /tags)"
+// @Param status query string false "Статус: active, on_leave или alumni"
+// @Param city query string false "Город"
+// @Param campus query string false "Кампус"
+// @Param building query string false "Корпус"
+// @Param room query string false "Аудитория"
+// @Param department query string false "Отдел"
+// @Param include_archived query bool false "true - включить архивные контакты, которые по умолчанию не выводятся"
+// @Param group_by query string false "department - сгруппировать контакты по отделам (ответ - массив DepartmentContactsResponse)"
+// @Param group_id query int false "ID группы"
+// @Param q query string false "Поиск: подстрока имени, email, Telegram или VK либо телефон целиком; ищется только в полях, доступных роли"
+// @Param email query string false "Подстрока email"
+// @Param phone query string false "Телефон целиком в любом написании, например 8 999 000-11-22"
+// @Param has_telegram query bool false "true - только контакты с привязанным Telegram, false - только без него"
+// @Param sort query string false "Порядок: поля id, name, created_at, updated_at через запятую, \"-\" - по убыванию (например, name,-created_at)"
+// @Param filter query string false "Выражение фильтра, например: transport eq 'car' and (group.name eq 'Логистика' or skill.name in ('video', 'sound')). Поля: id, name, status, email, transport, printer, vk, telegram, city, campus, building, room, department, position, created_at, updated_at, group.id, group.name, skill.name, tag.name; операторы: eq, ne, gt, ge, lt, le, contains, startswith, in, and, or, not"
 // @Success 200 {array} ContactResponse "Список контактов для авторизованных пользователей"
-// @Success 200 {array} ContactBasicResponse "Список контактов для неавторизованных пользователей"
+// @Success 200 {array} DepartmentContactsResponse "Контакты по отделам при group_by=department"
+// @Success 200 {array} GuestContactResponse "Список контактов для неавторизованных пользователей: ID, имя и поля, открытые контактом всем"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный фильтр или поле сортировки"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Фильтр по полю, скрытому от роли политикой доступа"
+// @Failure 429 {object} map[string]string "Анонимный лимит исчерпан"
 // @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /contacts [get]
 func (h *Handler) GetAllContacts(c *fiber.Ctx) error {
-	contacts, err := h.contactUseCase.GetAllContacts(c.Context())
+	filter, err := contactFilterFromQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	}
+	role := requestctx.Role(c)
+	if err := h.contactUseCase.CheckFilterAccess(c.Context(), role, &filter); err != nil {
+		if errors.Is(err, contactUseCase.ErrFilterFieldDenied) {
+			return c.Status(fiber.StatusForbidden).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	contacts, err := h.contactUseCase.GetAllContacts(c.Context(), filter)
 	if err != nil {
 		h.logger.ErrorContext(c.Context(), "Failed to get all contacts from use case", slog.Any("error", err))
 		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
 	}
 
-	// Проверяем авторизацию пользователя
-	isAuthenticated := c.Locals("isAuthenticated")
-	isAuth := false
-	if isAuthenticated != nil {
-		if isAuthBool, ok := isAuthenticated.(bool); ok {
-			isAuth = isAuthBool
+	if role == domain.RoleGuest {
+		// Гостям возвращаем только ID, имена и поля, которые контакт открыл всем
+		resp := make([]GuestContactResponse, len(contacts))
+		for i := range contacts {
+			resp[i] = toGuestContactResponse(&contacts[i])
+		}
+		if len(c.Request().URI().QueryString()) > 0 {
+			return c.Status(fiber.StatusOK).JSON(resp)
+		}
+		// Полный список обновляет кэш, из которого отвечают гостям при исчерпанном анонимном лимите
+		body, err := json.Marshal(resp)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
 		}
+		h.guestCache.store(tenant.FromContext(c.Context()), body)
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Status(fiber.StatusOK).Send(body)
 	}
 
-	if isAuth {
-		// Возвращаем полную информацию для авторизованных пользователей
-		resp := make([]ContactResponse, len(contacts))
-		for i, ct := range contacts {
-			resp[i] = toContactResponse(&ct)
+	// Остальным ролям возвращаем поля, разрешенные политикой доступа
+	if err := h.contactUseCase.FilterFieldsForRole(c.Context(), role, contacts); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	resp := make([]ContactResponse, len(contacts))
+	for i, ct := range contacts {
+		resp[i] = toContactResponse(&ct, requestctx.Location(c))
+	}
+	if filter.GroupBy == contactUseCase.GroupByDepartment {
+		return c.Status(fiber.StatusOK).JSON(groupByDepartment(resp))
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// groupByDepartment раскладывает контакты по отделам в алфавитном порядке; контакты без отдела идут последними.
+// Внутри отдела сохраняется порядок списка.
+func groupByDepartment(contacts []ContactResponse) []DepartmentContactsResponse {
+	index := map[string]int{}
+	groups := []DepartmentContactsResponse{}
+	for _, ct := range contacts {
+		i, ok := index[ct.Department]
+		if !ok {
+			i = len(groups)
+			index[ct.Department] = i
+			groups = append(groups, DepartmentContactsResponse{Department: ct.Department})
 		}
-		return c.Status(fiber.StatusOK).JSON(resp)
-	} else {
-		// Возвращаем только имена для неавторизованных пользователей
-		resp := make([]ContactBasicResponse, len(contacts))
-		for i, ct := range contacts {
-			resp[i] = ContactBasicResponse{
-				ID:   ct.ID,
-				Name: ct.Name,
+		groups[i].Contacts = append(groups[i].Contacts, ct)
+	}
+	slices.SortFunc(groups, func(a, b DepartmentContactsResponse) int {
+		if (a.Department == "") != (b.Department == "") {
+			if a.Department == "" {
+				return 1
 			}
+			return -1
+		}
+		return strings.Compare(a.Department, b.Department)
+	})
+	return groups
+}
+
+// GetDashboardContacts обрабатывает запрос списка контактов по токену доступа.
+// Если токен ограничен группой, возвращаются только контакты этой группы.
+// @Summary Получить контакты для дашборда
+// @Description Возвращает контакты по токену доступа с областью contacts:read. Поля фильтруются правилами роли dashboard.
+// @Tags dashboard
+// @Produce json
+// @Param Authorization header string true "Bearer rim_..."
+// @Param status query string false "Статус: active, on_leave или alumni"
+// @Success 200 {array} ContactResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /dashboard/contacts [get]
+func (h *Handler) GetDashboardContacts(c *fiber.Ctx) error {
+	token, ok := c.Locals("api_token").(*domain.APIToken)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "API token required"})
+	}
+
+	filter := contactUseCase.ContactFilter{Status: c.Query("status")}
+	if token.GroupID != nil {
+		filter.GroupID = *token.GroupID
+	}
+	contacts, err := h.contactUseCase.GetAllContacts(c.Context(), filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+
+	if err := h.contactUseCase.FilterFieldsForRole(c.Context(), domain.RoleDashboard, contacts); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	resp := make([]ContactResponse, len(contacts))
+	for i, ct := range contacts {
+		resp[i] = toContactResponse(&ct, time.UTC)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// GetPublicContacts обрабатывает запрос публичного справочника по токену доступа с областью directory:read.
+// Возвращаются только активные контакты и только поля PublicContactResponse, дополнительно отфильтрованные правилами роли public.
+// @Summary Публичный справочник контактов
+// @Description Полный путь: /api/public/v1/contacts. Для встраивания справочника на публичный сайт: доступен с любого источника (CORS),
+// @Description ответ кэшируется на сервере и в браузере (Cache-Control, ETag). Группы и навыки выводятся, только если их открывают правила роли public.
+// @Tags public
+// @Produce json
+// @Param Authorization header string true "Bearer rim_..."
+// @Param group_id query int false "ID группы; для токена, ограниченного группой, - только она"
+// @Param skills query string false "Навыки через запятую: контакты, обладающие хотя бы одним из них"
+// @Success 200 {array} PublicContactResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID группы"
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /public/v1/contacts [get]
+func (h *Handler) GetPublicContacts(c *fiber.Ctx) error {
+	token, ok := c.Locals("api_token").(*domain.APIToken)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "API token required"})
+	}
+
+	filter := contactUseCase.ContactFilter{Status: domain.ContactStatusActive}
+	if skills := c.Query("skills"); skills != "" {
+		filter.Skills = strings.Split(skills, ",")
+	}
+	if raw := c.Query("group_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid group ID format"})
+		}
+		filter.GroupID = uint(id)
+	}
+	if token.GroupID != nil {
+		if filter.GroupID != 0 && filter.GroupID != *token.GroupID {
+			return c.Status(fiber.StatusForbidden).JSON(groupDelivery.ErrorResponse{Message: "Token is limited to another group"})
+		}
+		filter.GroupID = *token.GroupID
+	}
+
+	contacts, err := h.contactUseCase.GetAllContacts(c.Context(), filter)
+	if err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to get public contacts from use case", slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	if err := h.contactUseCase.FilterFieldsForRole(c.Context(), domain.RolePublic, contacts); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+
+	resp := make([]PublicContactResponse, len(contacts))
+	for i, ct := range contacts {
+		resp[i] = PublicContactResponse{ID: ct.ID, Name: ct.Name}
+		for _, g := range ct.Groups {
+			resp[i].Groups = append(resp[i].Groups, g.Name)
+		}
+		for _, sk := range ct.Skills {
+			resp[i].Skills = append(resp[i].Skills, sk.Name)
 		}
-		return c.Status(fiber.StatusOK).JSON(resp)
 	}
+	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
 // UpdateContact обрабатывает запрос на обновление контакта.
@@ -208,7 +357,8 @@ func (h *Handler) GetAllContacts(c *fiber.Ctx) error {
 // @Param id path int true "ID контакта для обновления"
 // @Param contact body UpdateContactRequest true "Данные для обновления контакта"
 // @Success 200 {object} ContactResponse "Контакт успешно обновлен"
-// @Failure 400 {object} groupDelivery.ErrorResponse "Ошибка валидации, некорректный ID или некорректный запрос"
+// @Failure 400 {object} validation.Response "Ошибка валидации, некорректный ID или некорректный запрос"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Контакт или группы вне групп модератора"
 // @Failure 404 {object} groupDelivery.ErrorResponse "Контакт или одна из указанных групп не найдена"
 // @Failure 409 {object} groupDelivery.ErrorResponse "Конфликт данных (например, email или телефон уже занят)"
 // @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
@@ -220,15 +370,46 @@ func (h *Handler) UpdateContact(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
 	}
 
-	var req UpdateContactRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid request body"})
+	req, ok, err := validation.BindAndValidate[UpdateContactRequest](c)
+	if !ok {
+		return err
 	}
+	return h.updateContact(c, uint(contactID), req)
+}
 
-	if err := h.validate.Struct(req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: fmt.Sprintf("Validation failed: %s", err.Error())})
+// PatchContact обрабатывает запрос на частичное обновление контакта в формате JSON Merge Patch.
+// @Summary Частично обновить контакт
+// @Description Изменяет только переданные поля по правилам JSON Merge Patch (RFC 7396): отсутствующее поле не меняется,
+// @Description null очищает его (telegram_id: null отвязывает Telegram, group_ids: null исключает из всех групп).
+// @Description Имя, телефон и email очистить нельзя. Поля и проверки - как в PUT /contacts/{id}.
+// @Tags contacts
+// @Accept json,application/merge-patch+json
+// @Produce json
+// @Param id path int true "ID контакта для обновления"
+// @Param contact body UpdateContactRequest true "Изменяемые поля; null очищает поле"
+// @Success 200 {object} ContactResponse "Контакт успешно обновлен"
+// @Failure 400 {object} validation.Response "Ошибка валидации, некорректный ID, попытка очистить имя, телефон или email"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Контакт или группы вне групп модератора"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт или одна из указанных групп не найдена"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Конфликт данных (например, email или телефон уже занят)"
+// @Failure 415 {object} validation.Response "Тело запроса не в формате JSON"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id} [patch]
+func (h *Handler) PatchContact(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
 	}
 
+	req, ok, err := validation.BindMergePatch[UpdateContactRequest](c)
+	if !ok {
+		return err
+	}
+	return h.updateContact(c, uint(contactID), req)
+}
+
+// updateContact применяет изменения контакта из PUT и PATCH: nil - поле не передано, пустое значение очищает поле
+func (h *Handler) updateContact(c *fiber.Ctx, contactID uint, req UpdateContactRequest) error {
 	ucData := contactUseCase.UpdateContactData{
 		Name:       req.Name,
 		Phone:      req.Phone,
@@ -240,24 +421,35 @@ func (h *Handler) UpdateContact(c *fiber.Ctx) error {
 		Telegram:   req.Telegram,
 		TelegramID: req.TelegramID,
 		GroupIDs:   req.GroupIDs,
+		City:       req.City,
+		Campus:     req.Campus,
+		Building:   req.Building,
+		Room:       req.Room,
+		Department: req.Department,
+		Position:   req.Position,
+		UpdatedBy:  requestctx.ActorID(c),
+		Scope:      groupDelivery.GroupScope(c),
 	}
 
-	updatedContact, err := h.contactUseCase.UpdateContact(c.Context(), uint(contactID), ucData)
+	updatedContact, err := h.contactUseCase.UpdateContact(c.Context(), contactID, ucData)
 	if err != nil {
+		if errors.Is(err, contactUseCase.ErrOutOfGroupScope) {
+			return c.Status(fiber.StatusForbidden).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
 		if errors.Is(err, contactUseCase.ErrContactNotFound) || errors.Is(err, groupUseCase.ErrGroupNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
 		}
-		if errors.Is(err, contactUseCase.ErrContactNameEmpty) || errors.Is(err, contactUseCase.ErrContactPhoneEmpty) || errors.Is(err, contactUseCase.ErrContactEmailEmpty) {
+		if errors.Is(err, contactUseCase.ErrContactNameEmpty) || errors.Is(err, contactUseCase.ErrContactPhoneEmpty) || errors.Is(err, contactUseCase.ErrContactEmailEmpty) || errors.Is(err, locationUseCase.ErrUnknownLocation) {
 			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
 		}
 		if errors.Is(err, contactUseCase.ErrContactEmailExists) || errors.Is(err, contactUseCase.ErrContactPhoneExists) {
 			return c.Status(fiber.StatusConflict).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
 		}
-		h.logger.ErrorContext(c.Context(), "Failed to update contact via use case", slog.Uint64("id", contactID), slog.Any("error", err))
+		h.logger.ErrorContext(c.Context(), "Failed to update contact via use case", slog.Uint64("id", uint64(contactID)), slog.Any("error", err))
 		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(toContactResponse(updatedContact))
+	return c.Status(fiber.StatusOK).JSON(toContactResponse(updatedContact, requestctx.Location(c)))
 }
 
 // DeleteContact обрабатывает запрос на удаление контакта.
@@ -278,7 +470,7 @@ func (h *Handler) DeleteContact(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
 	}
 
-	if err := h.contactUseCase.DeleteContact(c.Context(), uint(contactID)); err != nil {
+	if err := h.contactUseCase.DeleteContact(c.Context(), uint(contactID), requestctx.ActorID(c)); err != nil {
 		if errors.Is(err, contactUseCase.ErrContactNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
 		}
@@ -288,15 +480,170 @@ func (h *Handler) DeleteContact(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// GetDeletedContacts возвращает мягко удаленные контакты с указанным телефоном или email.
+// @Summary Найти удаленные контакты
+// @Description Возвращает мягко удаленные контакты, совпадающие по телефону или email, чтобы администратор мог восстановить нужную запись.
+// @Tags contacts
+// @Produce json
+// @Param phone query string false "Телефон"
+// @Param email query string false "Email"
+// @Success 200 {array} ContactResponse "Список удаленных контактов"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Не указан ни телефон, ни email"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/deleted [get]
+func (h *Handler) GetDeletedContacts(c *fiber.Ctx) error {
+	phone := c.Query("phone")
+	email := c.Query("email")
+	if phone == "" && email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "phone or email query parameter is required"})
+	}
+
+	contacts, err := h.contactUseCase.GetDeletedContacts(c.Context(), phone, email)
+	if err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to get deleted contacts from use case", slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+
+	resp := make([]ContactResponse, len(contacts))
+	for i, ct := range contacts {
+		resp[i] = toContactResponse(&ct, requestctx.Location(c))
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// RestoreContact восстанавливает мягко удаленный контакт.
+// @Summary Восстановить удаленный контакт
+// @Description Снимает отметку удаления с контакта, если его телефон и email не заняты активными контактами.
+// @Tags contacts
+// @Produce json
+// @Param id path int true "ID удаленного контакта"
+// @Success 200 {object} ContactResponse "Контакт восстановлен"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Удаленный контакт не найден"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Телефон или email уже заняты активным контактом"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/restore [post]
+func (h *Handler) RestoreContact(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	contactID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+
+	contact, err := h.contactUseCase.RestoreContact(c.Context(), uint(contactID))
+	if err != nil {
+		if errors.Is(err, contactUseCase.ErrContactNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		if errors.Is(err, contactUseCase.ErrContactEmailExists) || errors.Is(err, contactUseCase.ErrContactPhoneExists) {
+			return c.Status(fiber.StatusConflict).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to restore contact via use case", slog.Uint64("id", contactID), slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	return c.Status(fiber.StatusOK).JSON(toContactResponse(contact, requestctx.Location(c)))
+}
+
+// ChangeContactStatus меняет статус жизненного цикла контакта.
+// @Summary Изменить статус контакта
+// @Description Переводит контакт в статус active, on_leave или alumni. Выпускника нельзя перевести в on_leave.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param id path int true "ID контакта"
+// @Param status body ContactStatusRequest true "Новый статус"
+// @Success 200 {object} ContactResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный статус"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт не найден"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Переход между статусами запрещен"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/status [put]
+func (h *Handler) ChangeContactStatus(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+	req, ok, err := validation.BindAndValidate[ContactStatusRequest](c)
+	if !ok {
+		return err
+	}
+
+	contact, err := h.contactUseCase.ChangeContactStatus(c.Context(), uint(contactID), req.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, contactUseCase.ErrInvalidStatus):
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		case errors.Is(err, contactUseCase.ErrContactNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		case errors.Is(err, contactUseCase.ErrStatusTransition):
+			return c.Status(fiber.StatusConflict).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to change contact status", slog.Uint64("contactID", contactID), slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+
+	filtered := []domain.Contact{*contact}
+	if err := h.contactUseCase.FilterFieldsForRole(c.Context(), requestctx.Role(c), filtered); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	return c.Status(fiber.StatusOK).JSON(toContactResponse(&filtered[0], requestctx.Location(c)))
+}
+
+// ArchiveContact убирает контакт в архив или возвращает из него.
+// @Summary Архивировать контакт
+// @Description Архивный контакт не удаляется: он доступен по ID, сохраняет статус, группы и пользователя, к нему
+// @Description привязываются пользователи при входе, но в GET /contacts, поиск, выгрузки и рассылки он не попадает без include_archived=true.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param id path int true "ID контакта"
+// @Param archive body ArchiveContactRequest true "true - в архив, false - из архива"
+// @Success 200 {object} ContactResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID или запрос"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/archive [patch]
+func (h *Handler) ArchiveContact(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+	req, ok, err := validation.BindAndValidate[ArchiveContactRequest](c)
+	if !ok {
+		return err
+	}
+
+	contact, err := h.contactUseCase.SetArchived(c.Context(), uint(contactID), *req.Archived)
+	if err != nil {
+		if errors.Is(err, contactUseCase.ErrContactNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to change contact archive state", slog.Uint64("contactID", contactID), slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	// Архивный контакт пропадает из списка для гостей
+	h.guestCache.invalidate(contact.OrganizationID)
+
+	filtered := []domain.Contact{*contact}
+	if err := h.contactUseCase.FilterFieldsForRole(c.Context(), requestctx.Role(c), filtered); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	return c.Status(fiber.StatusOK).JSON(toContactResponse(&filtered[0], requestctx.Location(c)))
+}
+
 // AddContactToGroup добавляет контакт в группу.
 // @Summary Добавить контакт в группу
-// @Description Добавляет существующий контакт в существующую группу.
+// @Description Добавляет существующий контакт в существующую группу и задает срок членства.
+// @Description Если контакт уже в группе, обновляется только срок; без expires_at членство бессрочное.
 // @Tags contacts
+// @Accept json
 // @Produce json
 // @Param contact_id path int true "ID контакта"
 // @Param group_id path int true "ID группы"
+// @Param membership body AddToGroupRequest false "Срок членства"
 // @Success 204 "Контакт успешно добавлен в группу"
-// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID контакта или группы"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID контакта или группы или срок в прошлом"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Группа вне групп модератора"
 // @Failure 404 {object} groupDelivery.ErrorResponse "Контакт или группа не найдены"
 // @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /contacts/{contact_id}/groups/{group_id} [post]
@@ -313,14 +660,26 @@ func (h *Handler) AddContactToGroup(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid group ID format"})
 	}
 
-	err = h.contactUseCase.AddContactToGroup(c.Context(), uint(contactID), uint(groupID))
+	var req AddToGroupRequest
+	if len(c.Body()) > 0 {
+		parsed, ok, err := validation.BindAndValidate[AddToGroupRequest](c)
+		if !ok {
+			return err
+		}
+		req = parsed
+	}
+
+	err = h.contactUseCase.AddContactToGroup(c.Context(), uint(contactID), uint(groupID), req.ExpiresAt, groupDelivery.GroupScope(c), requestctx.ActorID(c))
 	if err != nil {
+		if errors.Is(err, contactUseCase.ErrInvalidExpiry) {
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		if errors.Is(err, contactUseCase.ErrOutOfGroupScope) {
+			return c.Status(fiber.StatusForbidden).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
 		if errors.Is(err, contactUseCase.ErrContactNotFound) || errors.Is(err, groupUseCase.ErrGroupNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
 		}
-		if errors.Is(err, contactUseCase.ErrGroupAssociation) { // Ошибка при ассоциации
-			return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
-		}
 		h.logger.ErrorContext(c.Context(), "Failed to add contact to group", slog.Uint64("contactID", contactID), slog.Uint64("groupID", groupID), slog.Any("error", err))
 		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
 	}
@@ -336,6 +695,7 @@ func (h *Handler) AddContactToGroup(c *fiber.Ctx) error {
 // @Param group_id path int true "ID группы"
 // @Success 204 "Контакт успешно удален из группы"
 // @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID контакта или группы, или контакт не в группе"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Группа вне групп модератора"
 // @Failure 404 {object} groupDelivery.ErrorResponse "Контакт или группа не найдены"
 // @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /contacts/{contact_id}/groups/{group_id} [delete]
@@ -352,48 +712,130 @@ func (h *Handler) RemoveContactFromGroup(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid group ID format"})
 	}
 
-	err = h.contactUseCase.RemoveContactFromGroup(c.Context(), uint(contactID), uint(groupID))
+	err = h.contactUseCase.RemoveContactFromGroup(c.Context(), uint(contactID), uint(groupID), groupDelivery.GroupScope(c), requestctx.ActorID(c))
 	if err != nil {
+		if errors.Is(err, contactUseCase.ErrOutOfGroupScope) {
+			return c.Status(fiber.StatusForbidden).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
 		if errors.Is(err, contactUseCase.ErrContactNotFound) || errors.Is(err, groupUseCase.ErrGroupNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
 		}
-		// Если usecase возвращает ошибку, что контакт не в группе, это BadRequest
-		if e, ok := err.(interface{ Error() string }); ok && e.Error() == fmt.Sprintf("contact is not a member of group %d", groupID) {
+		if errors.Is(err, contactUseCase.ErrContactNotMember) {
 			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
 		}
-		if errors.Is(err, contactUseCase.ErrGroupAssociation) { // Ошибка при диссоциации
-			return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
-		}
 		h.logger.ErrorContext(c.Context(), "Failed to remove contact from group", slog.Uint64("contactID", contactID), slog.Uint64("groupID", groupID), slog.Any("error", err))
 		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
-// toContactResponse преобразует domain.Contact в ContactResponse DTO.
-func toContactResponse(contact *domain.Contact) ContactResponse {
+// contactFilterFromQuery собирает фильтр списка контактов из query-параметров.
+func contactFilterFromQuery(c *fiber.Ctx) (contactUseCase.ContactFilter, error) {
+	return contactUseCase.ParseContactFilter(func(key string) string { return c.Query(key) })
+}
+
+// toContactResponse преобразует domain.Contact в ContactResponse DTO, отображая время в часовом поясе loc.
+func toContactResponse(contact *domain.Contact, loc *time.Location) ContactResponse {
 	grRes := make([]groupDelivery.GroupResponse, len(contact.Groups))
 	for i, g := range contact.Groups {
-		grRes[i] = groupDelivery.GroupResponse{
-			ID:        g.ID,
-			Name:      g.Name,
-			CreatedAt: g.CreatedAt,
-			UpdatedAt: g.UpdatedAt,
+		grRes[i] = groupDelivery.ToGroupResponse(g, loc)
+		if expiresAt := contact.ExpiresAtFor(g.ID); expiresAt != nil {
+			grRes[i].MembershipExpiresAt = timeutil.Format(*expiresAt, loc)
 		}
 	}
+	var skRes []skillDelivery.SkillResponse
+	for _, sk := range contact.Skills {
+		skRes = append(skRes, skillDelivery.ToSkillResponse(sk))
+	}
+	var tgRes []tagDelivery.TagResponse
+	for _, tg := range contact.Tags {
+		tgRes = append(tgRes, tagDelivery.ToTagResponse(tg))
+	}
+	var archivedAt string
+	if contact.ArchivedAt != nil {
+		archivedAt = timeutil.Format(*contact.ArchivedAt, loc)
+	}
+	var avatarURL string
+	if contact.AvatarFileID != "" {
+		avatarURL = fmt.Sprintf("/api/v1/contacts/%d/avatar", contact.ID)
+	}
 	return ContactResponse{
-		ID:         contact.ID,
-		Name:       contact.Name,
-		Phone:      contact.Phone,
-		Email:      contact.Email,
-		Transport:  contact.Transport,
-		Printer:    contact.Printer,
-		Allergies:  contact.Allergies,
-		VK:         contact.VK,
-		Telegram:   contact.Telegram,
-		TelegramID: contact.TelegramID,
-		Groups:     grRes,
-		CreatedAt:  contact.CreatedAt,
-		UpdatedAt:  contact.UpdatedAt,
+		ID:            contact.ID,
+		Name:          contact.Name,
+		Status:        contact.Status,
+		Blocked:       contact.Blocked,
+		ArchivedAt:    archivedAt,
+		Phone:         contact.Phone,
+		Email:         contact.Email,
+		Transport:     contact.Transport,
+		Printer:       contact.Printer,
+		Allergies:     contact.Allergies,
+		VK:            contact.VK,
+		Telegram:      contact.Telegram,
+		TelegramID:    contact.TelegramID,
+		AvatarURL:     avatarURL,
+		City:          contact.City,
+		Campus:        contact.Campus,
+		Building:      contact.Building,
+		Room:          contact.Room,
+		Department:    contact.Department,
+		Position:      contact.Position,
+		Groups:        grRes,
+		Skills:        skRes,
+		Relations:     toRelationResponses(contact),
+		Tags:          tgRes,
+		EmailVerified: contact.EmailVerified,
+		CustomFields:  contact.CustomFields(),
+		CreatedAt:     timeutil.Format(contact.CreatedAt, loc),
+		UpdatedAt:     timeutil.Format(contact.UpdatedAt, loc),
+		UpdatedBy:     contact.UpdatedBy,
 	}
 }
+
+// AddContactSkill назначает контакту навык из справочника.
+// @Summary Добавить навык контакту
+// @Tags contacts
+// @Param id path int true "ID контакта"
+// @Param skill_id path int true "ID навыка"
+// @Success 204 "Навык добавлен"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт или навык не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/skills/{skill_id} [post]
+func (h *Handler) AddContactSkill(c *fiber.Ctx) error {
+	return h.changeContactSkill(c, h.contactUseCase.AddContactSkill)
+}
+
+// RemoveContactSkill снимает навык с контакта.
+// @Summary Удалить навык у контакта
+// @Tags contacts
+// @Param id path int true "ID контакта"
+// @Param skill_id path int true "ID навыка"
+// @Success 204 "Навык удален"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт или навык не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/skills/{skill_id} [delete]
+func (h *Handler) RemoveContactSkill(c *fiber.Ctx) error {
+	return h.changeContactSkill(c, h.contactUseCase.RemoveContactSkill)
+}
+
+func (h *Handler) changeContactSkill(c *fiber.Ctx, change func(ctx context.Context, contactID, skillID uint) error) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+	skillID, err := strconv.ParseUint(c.Params("skill_id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid skill ID format"})
+	}
+
+	if err := change(c.Context(), uint(contactID), uint(skillID)); err != nil {
+		if errors.Is(err, contactUseCase.ErrContactNotFound) || errors.Is(err, skillUseCase.ErrSkillNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to change contact skills", slog.Uint64("contactID", contactID), slog.Uint64("skillID", skillID), slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
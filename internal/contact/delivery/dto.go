@@ -1,54 +1,107 @@
 package delivery
 
 import (
-	groupDelivery "rim/internal/group/delivery"
 	"time"
+
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	skillDelivery "rim/internal/skill/delivery"
+	tagDelivery "rim/internal/tag/delivery"
+	"rim/pkg/validation"
 )
 
+// Статусы контакта и типы связей для тега enum
+func init() {
+	validation.RegisterEnum("contact_status", domain.ContactStatusActive, domain.ContactStatusOnLeave, domain.ContactStatusAlumni)
+	validation.RegisterEnum("contact_relation", domain.RelationMentor, domain.RelationManager, domain.RelationEmergencyContact)
+}
+
 // CreateContactRequest определяет структуру для запроса на создание контакта.
 type CreateContactRequest struct {
-	Name       string `json:"name" validate:"required,min=2,max=100"`
+	Name       string `json:"name" validate:"required,length=contact_name"`
 	Phone      string `json:"phone" validate:"required,e164"` // Или другой формат телефона
 	Email      string `json:"email" validate:"required,email"`
-	Transport  string `json:"transport,omitempty" validate:"omitempty,oneof='есть машина' 'есть права' 'нет ничего'"`
-	Printer    string `json:"printer,omitempty" validate:"omitempty,oneof='цветной' 'обычный' 'нет'"`
+	Transport  string `json:"transport,omitempty" validate:"omitempty,enum=transport"`
+	Printer    string `json:"printer,omitempty" validate:"omitempty,enum=printer"`
 	Allergies  string `json:"allergies,omitempty" validate:"omitempty,max=255"`
-	VK         string `json:"vk,omitempty" validate:"omitempty,url"`            // Или более специфичная валидация для VK/TG
-	Telegram   string `json:"telegram,omitempty" validate:"omitempty,alphanum"` // Пример: только буквы и цифры для username
-	TelegramID *int64 `json:"telegram_id,omitempty"`                            // ID пользователя в Telegram
+	VK         string `json:"vk,omitempty" validate:"omitempty,vk_url"`
+	Telegram   string `json:"telegram,omitempty" validate:"omitempty,telegram_username"` // Имя пользователя без @
+	TelegramID *int64 `json:"telegram_id,omitempty"`                                     // ID пользователя в Telegram
 	GroupIDs   []uint `json:"group_ids,omitempty"`
+	City       string `json:"city,omitempty" validate:"omitempty,max=100"` // Значения местоположения берутся из справочника /locations
+	Campus     string `json:"campus,omitempty" validate:"omitempty,max=100"`
+	Building   string `json:"building,omitempty" validate:"omitempty,max=100"`
+	Room       string `json:"room,omitempty" validate:"omitempty,max=100"`
+	Department string `json:"department,omitempty" validate:"omitempty,max=100"`
+	Position   string `json:"position,omitempty" validate:"omitempty,max=100"`
 }
 
 // UpdateContactRequest определяет структуру для запроса на обновление контакта.
 // Используем указатели, чтобы различать пустые значения от непереданных.
+// AddToGroupRequest определяет необязательные параметры членства при добавлении контакта в группу.
+type AddToGroupRequest struct {
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // RFC3339; по истечении контакт исключается из группы, без срока - бессрочно
+}
+
+// UpdateContactRequest определяет структуру для запроса на обновление контакта.
 type UpdateContactRequest struct {
-	Name       *string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Name       *string `json:"name,omitempty" validate:"omitempty,length=contact_name"`
 	Phone      *string `json:"phone,omitempty" validate:"omitempty,e164"`
 	Email      *string `json:"email,omitempty" validate:"omitempty,email"`
-	Transport  *string `json:"transport,omitempty" validate:"omitempty,oneof='есть машина' 'есть права' 'нет ничего'"`
-	Printer    *string `json:"printer,omitempty" validate:"omitempty,oneof='цветной' 'обычный' 'нет'"`
+	Transport  *string `json:"transport,omitempty" validate:"omitempty,enum=transport"`
+	Printer    *string `json:"printer,omitempty" validate:"omitempty,enum=printer"`
 	Allergies  *string `json:"allergies,omitempty" validate:"omitempty,max=255"`
-	VK         *string `json:"vk,omitempty" validate:"omitempty,url"`
-	Telegram   *string `json:"telegram,omitempty" validate:"omitempty,alphanum"`
+	VK         *string `json:"vk,omitempty" validate:"omitempty,vk_url"`
+	Telegram   *string `json:"telegram,omitempty" validate:"omitempty,telegram_username"`
 	TelegramID *int64  `json:"telegram_id,omitempty"` // ID пользователя в Telegram
 	GroupIDs   *[]uint `json:"group_ids,omitempty"`
+	City       *string `json:"city,omitempty" validate:"omitempty,max=100"` // Пустая строка очищает поле
+	Campus     *string `json:"campus,omitempty" validate:"omitempty,max=100"`
+	Building   *string `json:"building,omitempty" validate:"omitempty,max=100"`
+	Room       *string `json:"room,omitempty" validate:"omitempty,max=100"`
+	Department *string `json:"department,omitempty" validate:"omitempty,max=100"` // Пустая строка очищает поле
+	Position   *string `json:"position,omitempty" validate:"omitempty,max=100"`
 }
 
 // ContactResponse определяет структуру для ответа с информацией о контакте.
 type ContactResponse struct {
 	ID         uint                          `json:"id"`
 	Name       string                        `json:"name"`
-	Phone      string                        `json:"phone"`
-	Email      string                        `json:"email"`
+	Status     string                        `json:"status"`
+	Blocked    bool                          `json:"blocked,omitempty"` // Пользователь контакта не может войти
+	Phone      string                        `json:"phone,omitempty"`   // Пустое, если скрыто политикой доступа
+	Email      string                        `json:"email,omitempty"`
+	ArchivedAt string                        `json:"archived_at,omitempty"` // Когда контакт убран в архив; пусто, если не в архиве
 	Transport  string                        `json:"transport,omitempty"`
 	Printer    string                        `json:"printer,omitempty"`
 	Allergies  string                        `json:"allergies,omitempty"`
 	VK         string                        `json:"vk,omitempty"`
 	Telegram   string                        `json:"telegram,omitempty"`
 	TelegramID int64                         `json:"telegram_id,omitempty"` // ID пользователя в Telegram
+	AvatarURL  string                        `json:"avatar_url,omitempty"`  // Фото профиля Telegram через сервер: /api/v1/contacts/{id}/avatar
+	City       string                        `json:"city,omitempty"`
+	Campus     string                        `json:"campus,omitempty"`
+	Building   string                        `json:"building,omitempty"`
+	Room       string                        `json:"room,omitempty"`
+	Department string                        `json:"department,omitempty"`
+	Position   string                        `json:"position,omitempty"`
 	Groups     []groupDelivery.GroupResponse `json:"groups,omitempty"`
-	CreatedAt  time.Time                     `json:"created_at"`
-	UpdatedAt  time.Time                     `json:"updated_at"`
+	Skills     []skillDelivery.SkillResponse `json:"skills,omitempty"`
+	Relations  []ContactRelationResponse     `json:"relations,omitempty"`
+	Tags       []tagDelivery.TagResponse     `json:"tags,omitempty"`
+	// EmailVerified - владелец подтвердил email по ссылке из письма; не передается, если email скрыт
+	EmailVerified bool `json:"email_verified,omitempty"`
+	// CustomFields - значения дополнительных полей организации по ключам (GET /contact-fields)
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+	CreatedAt    string            `json:"created_at"`           // RFC3339 в часовом поясе пользователя
+	UpdatedAt    string            `json:"updated_at"`           // RFC3339 в часовом поясе пользователя
+	UpdatedBy    *uint             `json:"updated_by,omitempty"` // ID пользователя, последним изменившего контакт
+}
+
+// DepartmentContactsResponse определяет контакты одного отдела в списке с group_by=department.
+type DepartmentContactsResponse struct {
+	Department string            `json:"department"` // Пустая строка - контакты без отдела
+	Contacts   []ContactResponse `json:"contacts"`
 }
 
 // ContactBasicResponse определяет ограниченную структуру для неавторизованных пользователей.
@@ -57,8 +110,103 @@ type ContactBasicResponse struct {
 	Name string `json:"name"`
 }
 
+// GuestContactResponse определяет контакт в списке для гостей: ID, имя и поля, открытые контактом всем (уровень public).
+type GuestContactResponse struct {
+	ContactBasicResponse
+	Phone     string `json:"phone,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Transport string `json:"transport,omitempty"`
+	Printer   string `json:"printer,omitempty"`
+	Allergies string `json:"allergies,omitempty"`
+	VK        string `json:"vk,omitempty"`
+	Telegram  string `json:"telegram,omitempty"`
+	City      string `json:"city,omitempty"`
+	Campus    string `json:"campus,omitempty"`
+	Building  string `json:"building,omitempty"`
+	Room      string `json:"room,omitempty"`
+}
+
+// FieldVisibilityRequest определяет структуру запроса на изменение видимости полей контакта.
+// Ключи - поля (phone, email, transport, printer, allergies, vk, telegram, location), значения - admins, authenticated,
+// public или пустая строка, чтобы вернуть поле к правилам политики доступа.
+type FieldVisibilityRequest struct {
+	Fields map[string]string `json:"fields" validate:"required"`
+}
+
+// FieldVisibilityResponse определяет уровни видимости, заданные для полей контакта.
+type FieldVisibilityResponse struct {
+	Fields map[string]string `json:"fields"`
+}
+
+// PublicContactResponse определяет структуру контакта публичного справочника.
+// Набор полей закрыт: телефон, email и другие личные данные не выводятся при любых правилах политики.
+type PublicContactResponse struct {
+	ID     uint     `json:"id"`
+	Name   string   `json:"name"`
+	Groups []string `json:"groups,omitempty"` // Названия групп, если их открывает правило contact.groups роли public
+	Skills []string `json:"skills,omitempty"` // Названия навыков, если их открывает правило contact.skills роли public
+}
+
+// ContactStatusRequest определяет структуру запроса на смену статуса контакта.
+type ContactStatusRequest struct {
+	Status string `json:"status" validate:"required,enum=contact_status"`
+}
+
+// ArchiveContactRequest определяет структуру запроса на перемещение контакта в архив или из него.
+type ArchiveContactRequest struct {
+	Archived *bool `json:"archived" validate:"required"`
+}
+
+// ContactRelationRequest определяет структуру запроса на создание связи между контактами.
+// Контакт с ID to_contact_id становится для текущего контакта тем, что указано в type.
+type ContactRelationRequest struct {
+	ToContactID uint   `json:"to_contact_id" validate:"required"`
+	Type        string `json:"type" validate:"required,enum=contact_relation"`
+}
+
+// ContactRelationResponse определяет структуру связи в ответе.
+// Direction "outgoing" означает, что связанный контакт является для текущего тем, что указано в type,
+// "incoming" - что текущий контакт является таковым для связанного.
+type ContactRelationResponse struct {
+	ID        uint                 `json:"id"`
+	Type      string               `json:"type"`
+	Direction string               `json:"direction"`
+	Contact   ContactBasicResponse `json:"contact"`
+}
+
 // AddRemoveContactGroupRequest используется для запросов на добавление/удаление контакта из группы.
 // Пока не используется, так как ID группы берется из URL.
 // type AddRemoveContactGroupRequest struct {
 // 	GroupID uint `json:"group_id" validate:"required"`
 // }
+
+// GroupMembershipEventResponse определяет структуру события истории членства контакта в группе.
+// Type - "joined" или "left"; Source - "manual", "join_request" или "expired".
+// ActorID - пользователь, выполнивший изменение (отсутствует для автоматических изменений).
+type GroupMembershipEventResponse struct {
+	ID        uint   `json:"id"`
+	GroupID   uint   `json:"group_id"`
+	GroupName string `json:"group_name"`
+	Type      string `json:"type"`
+	Source    string `json:"source"`
+	ActorID   *uint  `json:"actor_id,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ContactHistoryResponse определяет структуру записи истории изменений контакта.
+// Action - "updated" или "deleted"; у удаления Changes пустой.
+// ActorID - пользователь, выполнивший изменение (отсутствует для системных изменений).
+type ContactHistoryResponse struct {
+	ID        uint                         `json:"id"`
+	Action    string                       `json:"action"`
+	ActorID   *uint                        `json:"actor_id,omitempty"`
+	Changes   []ContactFieldChangeResponse `json:"changes"`
+	CreatedAt string                       `json:"created_at"`
+}
+
+// ContactFieldChangeResponse определяет изменение одного поля контакта: прежнее и новое значение.
+type ContactFieldChangeResponse struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
@@ -0,0 +1,101 @@
+package delivery
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	groupDelivery "rim/internal/group/delivery"
+	"rim/pkg/apperror"
+	"rim/pkg/requestctx"
+)
+
+// GetFavoriteContacts возвращает избранные контакты текущего пользователя.
+// @Summary Получить избранные контакты
+// @Description Возвращает контакты, которые текущий пользователь добавил в избранное, начиная с добавленного последним.
+// @Description Избранное личное и видно только его владельцу. Удаленные контакты не выводятся, пока их не восстановят.
+// @Description Поля фильтруются, как в GET /contacts.
+// @Tags contacts
+// @Produce json
+// @Success 200 {array} ContactResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/favorites [get]
+func (h *Handler) GetFavoriteContacts(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Authentication required"})
+	}
+
+	contacts, err := h.contactUseCase.GetFavorites(c.Context(), userID)
+	if err != nil {
+		return h.favoriteError(c, err)
+	}
+	role := requestctx.Role(c)
+	if err := h.contactUseCase.FilterFieldsForRole(c.Context(), role, contacts); err != nil {
+		return h.favoriteError(c, err)
+	}
+	loc := requestctx.Location(c)
+	resp := make([]ContactResponse, len(contacts))
+	for i := range contacts {
+		resp[i] = toContactResponse(&contacts[i], loc)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// AddFavoriteContact добавляет контакт в избранное текущего пользователя.
+// @Summary Добавить контакт в избранное
+// @Description Повторное добавление не считается ошибкой.
+// @Tags contacts
+// @Param id path int true "ID контакта"
+// @Success 204 "Контакт в избранном"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/favorite [post]
+func (h *Handler) AddFavoriteContact(c *fiber.Ctx) error {
+	return h.changeFavorite(c, h.contactUseCase.AddFavorite)
+}
+
+// RemoveFavoriteContact убирает контакт из избранного текущего пользователя.
+// @Summary Убрать контакт из избранного
+// @Description Убрать можно и удаленный контакт; если контакта нет в избранном, запрос тоже успешен.
+// @Tags contacts
+// @Param id path int true "ID контакта"
+// @Success 204 "Контакта нет в избранном"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/favorite [delete]
+func (h *Handler) RemoveFavoriteContact(c *fiber.Ctx) error {
+	return h.changeFavorite(c, h.contactUseCase.RemoveFavorite)
+}
+
+func (h *Handler) changeFavorite(c *fiber.Ctx, change func(ctx context.Context, userID, contactID uint) error) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Authentication required"})
+	}
+
+	if err := change(c.Context(), userID, uint(contactID)); err != nil {
+		return h.favoriteError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// favoriteError преобразует ошибки usecase в HTTP-ответ по их виду (pkg/apperror)
+func (h *Handler) favoriteError(c *fiber.Ctx, err error) error {
+	status := apperror.HTTPStatus(err)
+	if status == fiber.StatusInternalServerError {
+		h.logger.ErrorContext(c.Context(), "Favorite contacts operation failed", slog.Any("error", err))
+		return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+}
@@ -0,0 +1,72 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/pkg/requestctx"
+	"rim/pkg/timeutil"
+)
+
+// GetContactGroupHistory возвращает историю членства контакта в группах.
+// @Summary Получить историю членства контакта в группах
+// @Description Возвращает события вступления в группы и выхода из них, начиная с последнего: когда, через что (вручную, по заявке, по истечении срока) и кем.
+// @Description Включает события удаленных групп. Если роли недоступно поле контакта "groups", возвращается пустой список.
+// @Tags contacts
+// @Produce json
+// @Param id path int true "ID контакта"
+// @Success 200 {array} GroupMembershipEventResponse "История членства"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/group-history [get]
+func (h *Handler) GetContactGroupHistory(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+
+	events, err := h.contactUseCase.GetGroupHistory(c.Context(), uint(contactID))
+	if err != nil {
+		if errors.Is(err, contactUseCase.ErrContactNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to get contact group history", slog.Uint64("contactID", contactID), slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+
+	// История раскрывает состав групп контакта, поэтому подчиняется тому же правилу политики, что и поле groups
+	contact := domain.Contact{Groups: []*domain.Group{}}
+	contact.ID = uint(contactID)
+	filtered := []domain.Contact{contact}
+	if err := h.contactUseCase.FilterFieldsForRole(c.Context(), requestctx.Role(c), filtered); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	response := []GroupMembershipEventResponse{}
+	if filtered[0].Groups == nil {
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+
+	loc := requestctx.Location(c)
+	for _, ev := range events {
+		res := GroupMembershipEventResponse{
+			ID:        ev.ID,
+			GroupID:   ev.GroupID,
+			Type:      ev.Type,
+			Source:    ev.Source,
+			ActorID:   ev.ActorID,
+			CreatedAt: timeutil.Format(ev.CreatedAt, loc),
+		}
+		if ev.Group != nil {
+			res.GroupName = ev.Group.Name
+		}
+		response = append(response, res)
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
@@ -0,0 +1,109 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/pkg/eventbus"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
+)
+
+// guestDirectoryTTL - как долго список имен для гостей отдается из кэша, когда анонимный лимит исчерпан
+const guestDirectoryTTL = 5 * time.Minute
+
+// guestDirectoryCache хранит последний ответ GET /contacts без параметров для гостей, по организациям
+type guestDirectoryCache struct {
+	mu      sync.Mutex
+	entries map[uint]guestDirectoryEntry
+}
+
+type guestDirectoryEntry struct {
+	body     []byte
+	storedAt time.Time
+}
+
+func newGuestDirectoryCache() *guestDirectoryCache {
+	return &guestDirectoryCache{entries: map[uint]guestDirectoryEntry{}}
+}
+
+func (gc *guestDirectoryCache) store(orgID uint, body []byte) {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	gc.entries[orgID] = guestDirectoryEntry{body: body, storedAt: time.Now()}
+}
+
+// invalidate удаляет список организации orgID; следующий гость получит его построенным заново
+func (gc *guestDirectoryCache) invalidate(orgID uint) {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	delete(gc.entries, orgID)
+}
+
+// SubscribeEvents сбрасывает кэш списка для гостей, когда в нем появляется, исчезает или переименовывается контакт
+// либо меняется телефон или email, открытые контактом всем.
+// Кэш сбрасывается после фиксации транзакции, иначе гость успел бы закэшировать список без изменения.
+func (h *Handler) SubscribeEvents(bus *eventbus.Bus) {
+	invalidate := func(ctx context.Context, contact *domain.Contact) error {
+		transaction.AfterCommit(ctx, func() { h.guestCache.invalidate(contact.OrganizationID) })
+		return nil
+	}
+	eventbus.On(bus, func(ctx context.Context, e domain.ContactCreatedEvent) error {
+		return invalidate(ctx, e.Contact)
+	})
+	eventbus.On(bus, func(ctx context.Context, e domain.ContactDeletedEvent) error {
+		return invalidate(ctx, e.Contact)
+	})
+	eventbus.On(bus, func(ctx context.Context, e domain.ContactChangedEvent) error {
+		if e.Field != "name" && e.Contact.Visibility()[e.Field] != domain.VisibilityPublic {
+			return nil
+		}
+		return invalidate(ctx, e.Contact)
+	})
+}
+
+// GetCachedGuestContacts отдает гостю список имен из кэша, не старше guestDirectoryTTL.
+// Используется вместо GetAllContacts, когда анонимный лимит частоты запросов исчерпан: при устаревшем кэше
+// список строится заново не чаще одного раза за guestDirectoryTTL, сколько бы запросов ни пришло.
+func (h *Handler) GetCachedGuestContacts(c *fiber.Ctx) error {
+	orgID := tenant.FromContext(c.Context())
+
+	h.guestCache.mu.Lock()
+	defer h.guestCache.mu.Unlock()
+	entry, ok := h.guestCache.entries[orgID]
+	if !ok || time.Since(entry.storedAt) > guestDirectoryTTL {
+		body, err := h.guestDirectory(c)
+		if err != nil {
+			return c.Status(fiber.StatusTooManyRequests).JSON(groupDelivery.ErrorResponse{Message: "Too many requests"})
+		}
+		entry = guestDirectoryEntry{body: body, storedAt: time.Now()}
+		h.guestCache.entries[orgID] = entry
+	}
+
+	c.Set("X-RateLimit-Throttled", "true")
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	return c.Status(fiber.StatusOK).Send(entry.body)
+}
+
+// guestDirectory строит ответ GET /contacts без параметров для гостя: ID, имена и открытые всем поля
+func (h *Handler) guestDirectory(c *fiber.Ctx) ([]byte, error) {
+	filter, err := contactFilterFromQuery(c)
+	if err != nil {
+		return nil, err
+	}
+	contacts, err := h.contactUseCase.GetAllContacts(c.Context(), filter)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]GuestContactResponse, len(contacts))
+	for i := range contacts {
+		resp[i] = toGuestContactResponse(&contacts[i])
+	}
+	return json.Marshal(resp)
+}
@@ -0,0 +1,67 @@
+package delivery
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/pkg/apperror"
+	"rim/pkg/requestctx"
+	"rim/pkg/timeutil"
+)
+
+// GetContactHistory возвращает историю изменений контакта.
+// @Summary Получить историю изменений контакта
+// @Description Возвращает изменения полей и удаление контакта, начиная с последнего: кто, когда и какие значения изменил.
+// @Description Доступна и для удаленного контакта. Изменения полей, скрытых от роли политикой доступа, не выводятся.
+// @Description Изменения групп - в /contacts/{id}/group-history.
+// @Tags contacts
+// @Produce json
+// @Param id path int true "ID контакта"
+// @Success 200 {array} ContactHistoryResponse "История изменений"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/history [get]
+func (h *Handler) GetContactHistory(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+
+	history, err := h.contactUseCase.GetHistory(c.Context(), requestctx.Role(c), uint(contactID))
+	if err != nil {
+		status := apperror.HTTPStatus(err)
+		if status == fiber.StatusInternalServerError {
+			h.logger.ErrorContext(c.Context(), "Failed to get contact history", slog.Uint64("contactID", contactID), slog.Any("error", err))
+			return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+		}
+		return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	}
+
+	loc := requestctx.Location(c)
+	response := make([]ContactHistoryResponse, len(history))
+	for i := range history {
+		response[i] = toContactHistoryResponse(&history[i], loc)
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func toContactHistoryResponse(entry *domain.ContactHistory, loc *time.Location) ContactHistoryResponse {
+	changes := entry.ChangeList()
+	res := ContactHistoryResponse{
+		ID:        entry.ID,
+		Action:    entry.Action,
+		ActorID:   entry.ActorID,
+		Changes:   make([]ContactFieldChangeResponse, len(changes)),
+		CreatedAt: timeutil.Format(entry.CreatedAt, loc),
+	}
+	for i, ch := range changes {
+		res.Changes[i] = ContactFieldChangeResponse{Field: ch.Field, Old: ch.Old, New: ch.New}
+	}
+	return res
+}
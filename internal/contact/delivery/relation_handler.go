@@ -0,0 +1,173 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/pkg/requestctx"
+	"rim/pkg/validation"
+)
+
+const (
+	relationOutgoing = "outgoing"
+	relationIncoming = "incoming"
+)
+
+// GetContactRelations возвращает связи контакта.
+// @Summary Получить связи контакта
+// @Description Возвращает исходящие и входящие связи контакта (наставник, руководитель, экстренный контакт).
+// @Tags contacts
+// @Produce json
+// @Param id path int true "ID контакта"
+// @Success 200 {array} ContactRelationResponse "Список связей"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/relations [get]
+func (h *Handler) GetContactRelations(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+
+	relations, err := h.contactUseCase.GetRelations(c.Context(), uint(contactID))
+	if err != nil {
+		if errors.Is(err, contactUseCase.ErrContactNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to get contact relations", slog.Uint64("contactID", contactID), slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+
+	// Собираем связи в контакт, чтобы применить к ним те же правила политики, что и к карточке контакта
+	var contact domain.Contact
+	contact.ID = uint(contactID)
+	for _, rel := range relations {
+		if rel.FromContactID == contact.ID {
+			contact.Relations = append(contact.Relations, rel)
+		} else {
+			contact.InverseRelations = append(contact.InverseRelations, rel)
+		}
+	}
+	filtered := []domain.Contact{contact}
+	if err := h.contactUseCase.FilterFieldsForRole(c.Context(), requestctx.Role(c), filtered); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+
+	response := toRelationResponses(&filtered[0])
+	if response == nil {
+		response = []ContactRelationResponse{}
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// AddContactRelation создает связь между контактами.
+// @Summary Добавить связь контакта
+// @Description Указывает, что другой контакт является наставником, руководителем или экстренным контактом текущего.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param id path int true "ID контакта"
+// @Param relation body ContactRelationRequest true "Данные связи"
+// @Success 201 {object} ContactRelationResponse "Связь создана"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Ошибка валидации или связь с самим собой"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт или связанный контакт не найден"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Такая связь уже существует"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/relations [post]
+func (h *Handler) AddContactRelation(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+
+	req, ok, err := validation.BindAndValidate[ContactRelationRequest](c)
+	if !ok {
+		return err
+	}
+
+	relation, err := h.contactUseCase.AddRelation(c.Context(), uint(contactID), contactUseCase.RelationData{
+		ToContactID: req.ToContactID,
+		Type:        req.Type,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, contactUseCase.ErrRelationInvalidType), errors.Is(err, contactUseCase.ErrRelationSelf):
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		case errors.Is(err, contactUseCase.ErrContactNotFound), errors.Is(err, contactUseCase.ErrRelatedNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		case errors.Is(err, contactUseCase.ErrRelationExists):
+			return c.Status(fiber.StatusConflict).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to add contact relation", slog.Uint64("contactID", contactID), slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(toRelationResponse(*relation, relationOutgoing))
+}
+
+// DeleteContactRelation удаляет связь контакта.
+// @Summary Удалить связь контакта
+// @Description Удаляет связь, в которой контакт участвует с любой стороны.
+// @Tags contacts
+// @Param id path int true "ID контакта"
+// @Param relation_id path int true "ID связи"
+// @Success 204 "Связь удалена"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Связь не найдена"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/relations/{relation_id} [delete]
+func (h *Handler) DeleteContactRelation(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+	relationID, err := strconv.ParseUint(c.Params("relation_id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid relation ID format"})
+	}
+
+	if err := h.contactUseCase.DeleteRelation(c.Context(), uint(contactID), uint(relationID)); err != nil {
+		if errors.Is(err, contactUseCase.ErrRelationNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to delete contact relation", slog.Uint64("contactID", contactID), slog.Uint64("relationID", relationID), slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// toRelationResponses собирает исходящие и входящие связи контакта.
+// Связи с удаленными контактами пропускаются.
+func toRelationResponses(contact *domain.Contact) []ContactRelationResponse {
+	var res []ContactRelationResponse
+	for _, rel := range contact.Relations {
+		if rel.ToContact != nil {
+			res = append(res, toRelationResponse(rel, relationOutgoing))
+		}
+	}
+	for _, rel := range contact.InverseRelations {
+		if rel.FromContact != nil {
+			res = append(res, toRelationResponse(rel, relationIncoming))
+		}
+	}
+	return res
+}
+
+func toRelationResponse(rel domain.ContactRelation, direction string) ContactRelationResponse {
+	related := rel.ToContact
+	if direction == relationIncoming {
+		related = rel.FromContact
+	}
+	res := ContactRelationResponse{ID: rel.ID, Type: rel.Type, Direction: direction}
+	if related != nil {
+		res.Contact = ContactBasicResponse{ID: related.ID, Name: related.Name}
+	}
+	return res
+}
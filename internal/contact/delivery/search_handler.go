@@ -0,0 +1,57 @@
+package delivery
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	groupDelivery "rim/internal/group/delivery"
+	"rim/pkg/apperror"
+	"rim/pkg/requestctx"
+)
+
+// SearchContacts обрабатывает запрос полнотекстового поиска контактов.
+// @Summary Полнотекстовый поиск контактов
+// @Description Ищет слова запроса в имени, email, имени пользователя Telegram и аллергиях и возвращает контакты по убыванию релевантности.
+// @Description Каждое слово должно найтись хотя бы в одном поле. Совпадение в имени весит больше, чем в контактах; совпадение поля целиком -
+// @Description больше, чем начала слова или подстроки. Аллергии хранятся зашифрованными и ищутся только целыми словами.
+// @Description Поиск идет только по полям, доступным роли. Точные условия отбора - в GET /contacts.
+// @Tags contacts
+// @Produce json
+// @Param q query string true "Слова запроса через пробел, не больше 10"
+// @Param limit query int false "Количество контактов (по умолчанию 20, не больше 100)"
+// @Success 200 {array} ContactResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Пустой или слишком длинный запрос, некорректный limit"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/search [get]
+func (h *Handler) SearchContacts(c *fiber.Ctx) error {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid limit"})
+		}
+	}
+
+	role := requestctx.Role(c)
+	contacts, err := h.contactUseCase.SearchContacts(c.Context(), role, c.Query("q"), limit)
+	if err != nil {
+		status := apperror.HTTPStatus(err)
+		if status == fiber.StatusInternalServerError {
+			h.logger.ErrorContext(c.Context(), "Failed to search contacts", slog.Any("error", err))
+			return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+		}
+		return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	}
+
+	if err := h.contactUseCase.FilterFieldsForRole(c.Context(), role, contacts); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	loc := requestctx.Location(c)
+	resp := make([]ContactResponse, len(contacts))
+	for i := range contacts {
+		resp[i] = toContactResponse(&contacts[i], loc)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
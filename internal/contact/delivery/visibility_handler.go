@@ -0,0 +1,163 @@
+package delivery
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/pkg/apperror"
+	"rim/pkg/validation"
+)
+
+// GetContactFieldVisibility возвращает уровни видимости полей контакта.
+// @Summary Получить видимость полей контакта
+// @Description Возвращает поля контакта, для которых задан уровень видимости. Поля без уровня видны по правилам политики доступа.
+// @Tags contacts
+// @Produce json
+// @Param id path int true "ID контакта"
+// @Success 200 {object} FieldVisibilityResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/visibility [get]
+func (h *Handler) GetContactFieldVisibility(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+	return h.getFieldVisibility(c, uint(id))
+}
+
+// SetContactFieldVisibility задает уровни видимости полей контакта.
+// @Summary Задать видимость полей контакта
+// @Description Заменяет уровни видимости полей контакта: admins - только администраторам, authenticated - авторизованным
+// @Description пользователям по правилам политики, но не гостям, public - всем, включая гостей в GET /contacts.
+// @Description Поля: phone, email, transport, printer, allergies, vk, telegram, location (город, кампус, корпус и аудитория).
+// @Description Поля, не указанные в запросе или с пустым уровнем, видны по правилам политики доступа.
+// @Description Контакт, подходящий под фильтр или поиск только по скрытым от роли полям, в результаты не попадает.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param id path int true "ID контакта"
+// @Param visibility body FieldVisibilityRequest true "Уровни видимости по полям"
+// @Success 200 {object} FieldVisibilityResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID, неизвестное поле или уровень"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/visibility [put]
+func (h *Handler) SetContactFieldVisibility(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+	return h.setFieldVisibility(c, uint(id))
+}
+
+// GetMyFieldVisibility возвращает уровни видимости полей контакта текущего пользователя.
+// @Summary Получить видимость полей своего контакта
+// @Tags auth
+// @Produce json
+// @Success 200 {object} FieldVisibilityResponse
+// @Failure 404 {object} groupDelivery.ErrorResponse "У пользователя нет контакта"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/contact/visibility [get]
+func (h *Handler) GetMyFieldVisibility(c *fiber.Ctx) error {
+	contactID, ok := ownContactID(c)
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: "Contact not found"})
+	}
+	return h.getFieldVisibility(c, contactID)
+}
+
+// SetMyFieldVisibility задает уровни видимости полей контакта текущего пользователя.
+// @Summary Задать видимость полей своего контакта
+// @Description Уровни и поля - как в PUT /contacts/{id}/visibility. Видимость не проходит модерацию групп.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param visibility body FieldVisibilityRequest true "Уровни видимости по полям"
+// @Success 200 {object} FieldVisibilityResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Неизвестное поле или уровень"
+// @Failure 404 {object} groupDelivery.ErrorResponse "У пользователя нет контакта"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/contact/visibility [put]
+func (h *Handler) SetMyFieldVisibility(c *fiber.Ctx) error {
+	contactID, ok := ownContactID(c)
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: "Contact not found"})
+	}
+	return h.setFieldVisibility(c, contactID)
+}
+
+func (h *Handler) getFieldVisibility(c *fiber.Ctx, id uint) error {
+	contact, err := h.contactUseCase.GetContactByID(c.Context(), id)
+	if err != nil {
+		return h.visibilityError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(FieldVisibilityResponse{Fields: contact.Visibility()})
+}
+
+func (h *Handler) setFieldVisibility(c *fiber.Ctx, id uint) error {
+	req, ok, err := validation.BindAndValidate[FieldVisibilityRequest](c)
+	if !ok {
+		return err
+	}
+	contact, err := h.contactUseCase.SetFieldVisibility(c.Context(), id, req.Fields)
+	if err != nil {
+		return h.visibilityError(c, err)
+	}
+	// Открытые гостям поля попадают в кэшированный список для гостей
+	h.guestCache.invalidate(contact.OrganizationID)
+	return c.Status(fiber.StatusOK).JSON(FieldVisibilityResponse{Fields: contact.Visibility()})
+}
+
+// visibilityError преобразует ошибки usecase в HTTP-ответ по их виду (pkg/apperror)
+func (h *Handler) visibilityError(c *fiber.Ctx, err error) error {
+	status := apperror.HTTPStatus(err)
+	if status == fiber.StatusInternalServerError {
+		h.logger.ErrorContext(c.Context(), "Contact field visibility operation failed", slog.Any("error", err))
+		return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+}
+
+// ownContactID возвращает ID контакта, связанного с текущим пользователем
+func ownContactID(c *fiber.Ctx) (uint, bool) {
+	user, ok := c.Locals("user").(*domain.User)
+	if !ok || user == nil || user.ContactID == nil {
+		return 0, false
+	}
+	return *user.ContactID, true
+}
+
+// toGuestContactResponse оставляет гостю ID, имя и поля, которые контакт открыл всем уровнем public
+func toGuestContactResponse(contact *domain.Contact) GuestContactResponse {
+	resp := GuestContactResponse{ContactBasicResponse: ContactBasicResponse{ID: contact.ID, Name: contact.Name}}
+	for field, level := range contact.Visibility() {
+		if level != domain.VisibilityPublic {
+			continue
+		}
+		switch field {
+		case "phone":
+			resp.Phone = contact.Phone
+		case "email":
+			resp.Email = contact.Email
+		case "transport":
+			resp.Transport = contact.Transport
+		case "printer":
+			resp.Printer = contact.Printer
+		case "allergies":
+			resp.Allergies = contact.Allergies
+		case "vk":
+			resp.VK = contact.VK
+		case "telegram":
+			resp.Telegram = contact.Telegram
+		case "location":
+			resp.City, resp.Campus, resp.Building, resp.Room = contact.City, contact.Campus, contact.Building, contact.Room
+		}
+	}
+	return resp
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm/clause"
+)
+
+func (r *sqliteRepository) AddFavorite(ctx context.Context, userID, contactID uint) error {
+	err := transaction.DB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.FavoriteContact{UserID: userID, ContactID: contactID}).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error adding contact to favorites in DB", slog.Uint64("userID", uint64(userID)), slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) RemoveFavorite(ctx context.Context, userID, contactID uint) error {
+	if err := transaction.DB(ctx, r.db).Where("user_id = ? AND contact_id = ?", userID, contactID).Delete(&domain.FavoriteContact{}).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error removing contact from favorites in DB", slog.Uint64("userID", uint64(userID)), slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// GetFavoriteIDs возвращает ID неудаленных контактов организации запроса из избранного пользователя,
+// начиная с добавленного последним
+func (r *sqliteRepository) GetFavoriteIDs(ctx context.Context, userID uint) ([]uint, error) {
+	var ids []uint
+	err := transaction.DB(ctx, r.db).Model(&domain.FavoriteContact{}).
+		Joins("JOIN contacts ON contacts.id = favorite_contacts.contact_id AND contacts.deleted_at IS NULL").
+		Scopes(tenant.Scope(ctx, "contacts")).
+		Where("favorite_contacts.user_id = ?", userID).
+		Order("favorite_contacts.created_at DESC, favorite_contacts.contact_id DESC").
+		Pluck("favorite_contacts.contact_id", &ids).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error getting favorite contacts from DB", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+		return nil, err
+	}
+	return ids, nil
+}
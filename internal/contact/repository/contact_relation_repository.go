@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+// ErrDuplicateRelation возвращается, если такая связь между контактами уже существует.
+var ErrDuplicateRelation = apperror.Conflict("contact_relation_exists", "contact relation already exists")
+
+// withRelations добавляет к запросу загрузку связей контакта вместе со связанными контактами.
+func withRelations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Relations.ToContact").Preload("InverseRelations.FromContact")
+}
+
+func (r *sqliteRepository) CreateRelation(ctx context.Context, relation *domain.ContactRelation) error {
+	if err := transaction.DB(ctx, r.db).Create(relation).Error; err != nil {
+		msg := strings.ToLower(err.Error())
+		if strings.Contains(msg, "unique constraint") || strings.Contains(msg, "duplicate key") {
+			r.logger.WarnContext(ctx, "Contact relation already exists", slog.Uint64("fromContactID", uint64(relation.FromContactID)), slog.Uint64("toContactID", uint64(relation.ToContactID)), slog.String("type", relation.Type))
+			return ErrDuplicateRelation
+		}
+		r.logger.ErrorContext(ctx, "Error creating contact relation in DB", slog.Uint64("fromContactID", uint64(relation.FromContactID)), slog.Any("error", err))
+		return err
+	}
+	r.logger.InfoContext(ctx, "Successfully created contact relation in DB", slog.Uint64("relationID", uint64(relation.ID)))
+	return nil
+}
+
+// GetRelation возвращает связь, в которой контакт участвует с любой стороны.
+func (r *sqliteRepository) GetRelation(ctx context.Context, contactID, relationID uint) (*domain.ContactRelation, error) {
+	var relation domain.ContactRelation
+	err := transaction.DB(ctx, r.db).
+		Where("id = ? AND (from_contact_id = ? OR to_contact_id = ?)", relationID, contactID, contactID).
+		First(&relation).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.ErrorContext(ctx, "Error getting contact relation from DB", slog.Uint64("relationID", uint64(relationID)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &relation, nil
+}
+
+// GetRelations возвращает исходящие и входящие связи контакта со связанными контактами.
+func (r *sqliteRepository) GetRelations(ctx context.Context, contactID uint) ([]domain.ContactRelation, error) {
+	var relations []domain.ContactRelation
+	err := transaction.DB(ctx, r.db).
+		Preload("FromContact").Preload("ToContact").
+		Where("from_contact_id = ? OR to_contact_id = ?", contactID, contactID).
+		Order("id").
+		Find(&relations).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error getting contact relations from DB", slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return nil, err
+	}
+	return relations, nil
+}
+
+func (r *sqliteRepository) DeleteRelation(ctx context.Context, id uint) error {
+	result := transaction.DB(ctx, r.db).Delete(&domain.ContactRelation{}, id)
+	if result.Error != nil {
+		r.logger.ErrorContext(ctx, "Error deleting contact relation from DB", slog.Uint64("relationID", uint64(id)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	r.logger.InfoContext(ctx, "Successfully deleted contact relation from DB", slog.Uint64("relationID", uint64(id)))
+	return nil
+}
+
+// deleteContactRelations удаляет все связи контакта перед его окончательным удалением.
+func (r *sqliteRepository) deleteContactRelations(ctx context.Context, contactID uint) error {
+	err := transaction.DB(ctx, r.db).
+		Where("from_contact_id = ? OR to_contact_id = ?", contactID, contactID).
+		Delete(&domain.ContactRelation{}).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error deleting contact relations from DB", slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+	}
+	return err
+}
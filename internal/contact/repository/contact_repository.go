@@ -2,39 +2,222 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/crypto"
+	"rim/pkg/filterexpr"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrDuplicatePhone возвращается, когда запись нарушает уникальность телефона на уровне БД.
+	ErrDuplicatePhone = apperror.Conflict("contact_phone_exists", "contact with this phone already exists")
+	// ErrDuplicateEmail возвращается, когда запись нарушает уникальность email на уровне БД.
+	ErrDuplicateEmail = apperror.Conflict("contact_email_exists", "contact with this email already exists")
+	// ErrDuplicateTelegramID возвращается, когда запись нарушает уникальность Telegram ID на уровне БД.
+	ErrDuplicateTelegramID = apperror.Conflict("contact_telegram_exists", "contact with this telegram id already exists")
 )
 
 // Repository определяет интерфейс для операций с данными контактов.
+// Выборки ограничены организацией из контекста (pkg/tenant), кроме поиска по email, телефону
+// и Telegram ID: эти поля уникальны во всем развертывании и используются при входе.
 type Repository interface {
 	Create(ctx context.Context, contact *domain.Contact) (*domain.Contact, error)
 	GetByID(ctx context.Context, id uint) (*domain.Contact, error)
+	// Exists проверяет наличие неудаленного контакта организации запроса, не загружая ассоциации
+	Exists(ctx context.Context, id uint) (bool, error)
 	GetByEmail(ctx context.Context, email string) (*domain.Contact, error)
 	GetByPhone(ctx context.Context, phone string) (*domain.Contact, error)
 	GetByTelegramID(ctx context.Context, telegramID int64) (*domain.Contact, error)
-	GetByEmailUnscoped(ctx context.Context, email string) (*domain.Contact, error)
-	GetByPhoneUnscoped(ctx context.Context, phone string) (*domain.Contact, error)
-	GetAll(ctx context.Context) ([]domain.Contact, error)
+	GetDeletedByPhoneOrEmail(ctx context.Context, phone, email string) ([]domain.Contact, error)
+	GetAll(ctx context.Context, filter ListFilter) ([]domain.Contact, error)
+	// Search возвращает контакты, подходящие под полнотекстовый запрос filter, со связями, как GetAll.
+	// Порядок не задан: релевантность оценивает usecase по расшифрованным полям.
+	Search(ctx context.Context, filter SearchFilter) ([]domain.Contact, error)
+	// Each читает контакты по фильтру пачками по batchSize и передает каждую пачку в fn,
+	// не держа в памяти всю выборку. Ошибка fn прерывает обход.
+	Each(ctx context.Context, filter ListFilter, batchSize int, fn func(contacts []domain.Contact) error) error
+	// Update сохраняет поля контакта и записывает изменения в историю от имени contact.UpdatedBy
 	Update(ctx context.Context, contact *domain.Contact) error
-	Delete(ctx context.Context, id uint) error
+	UpdateStatus(ctx context.Context, id uint, status string) error
+	// SetArchived убирает контакт в архив с момента archivedAt или возвращает из архива (nil)
+	SetArchived(ctx context.Context, id uint, archivedAt *time.Time) error
+	// SetEmailVerified отмечает email контакта подтвержденным, если он все еще равен email;
+	// иначе возвращает gorm.ErrRecordNotFound
+	SetEmailVerified(ctx context.Context, id uint, email string) error
+	// UpdateFieldVisibility сохраняет уровни видимости полей контакта (domain.Contact.FieldVisibility)
+	UpdateFieldVisibility(ctx context.Context, id uint, visibility string) error
+	// SetBlocked блокирует или разблокирует контакт, не меняя остальные поля
+	SetBlocked(ctx context.Context, id uint, blocked bool) error
+	// Delete мягко удаляет контакт и записывает удаление в историю от имени actorID
+	Delete(ctx context.Context, id uint, actorID *uint) error
 	HardDelete(ctx context.Context, id uint) error
-	AddContactToGroup(ctx context.Context, contact *domain.Contact, group *domain.Group) error
-	RemoveContactFromGroup(ctx context.Context, contact *domain.Contact, group *domain.Group) error
+	Restore(ctx context.Context, id uint) error
+	// GetHistory возвращает историю изменений контакта, в том числе удаленного, начиная с последней записи
+	GetHistory(ctx context.Context, contactID uint) ([]domain.ContactHistory, error)
+	AddSkill(ctx context.Context, contact *domain.Contact, skill *domain.Skill) error
+	RemoveSkill(ctx context.Context, contact *domain.Contact, skill *domain.Skill) error
+
+	CreateRelation(ctx context.Context, relation *domain.ContactRelation) error
+	GetRelation(ctx context.Context, contactID, relationID uint) (*domain.ContactRelation, error)
+	GetRelations(ctx context.Context, contactID uint) ([]domain.ContactRelation, error)
+	DeleteRelation(ctx context.Context, id uint) error
+
+	// AddFavorite добавляет контакт в избранное пользователя; повторное добавление не считается ошибкой
+	AddFavorite(ctx context.Context, userID, contactID uint) error
+	RemoveFavorite(ctx context.Context, userID, contactID uint) error
+	// GetFavoriteIDs возвращает ID контактов из избранного пользователя, начиная с добавленного последним.
+	// Удаленные контакты и контакты других организаций пропускаются.
+	GetFavoriteIDs(ctx context.Context, userID uint) ([]uint, error)
+}
+
+// ListFilter задает условия отбора контактов в GetAll. Пустые поля не участвуют в отборе.
+type ListFilter struct {
+	// SkillNames - контакт должен обладать хотя бы одним из навыков
+	SkillNames []string
+	// TagNames - у контакта должна быть хотя бы одна из меток
+	TagNames []string
+	GroupID  uint
+	// GroupIDs - контакт должен состоять хотя бы в одной из групп
+	GroupIDs []uint
+	// IDs - отбор по ID; пустой список не ограничивает выборку
+	IDs      []uint
+	Status   string
+	City     string
+	Campus   string
+	Building string
+	Room     string
+	// Department - отдел, точное совпадение
+	Department string
+	// Expression - выражение фильтра по полям из filterColumns
+	Expression filterexpr.Expr
+
+	// Search - подстрока имени или одного из столбцов SearchColumns (email, telegram, vk).
+	// Контакт с телефоном SearchPhone (+7XXXXXXXXXX) тоже подходит.
+	Search        string
+	SearchColumns []string
+	SearchPhone   string
+	// Email - подстрока email
+	Email string
+	// Phone - телефон +7XXXXXXXXXX; сравнивается по индексу, так как телефоны зашифрованы
+	Phone string
+	// HasTelegram - контакт привязан к Telegram (ID или имя пользователя) или не привязан
+	HasTelegram *bool
+	// IncludeArchived - выбирать и архивные контакты; по умолчанию они пропускаются
+	IncludeArchived bool
+
+	// Sort - порядок выборки по полям из sortColumns; при равенстве значений контакты упорядочены по ID
+	Sort []SortField
+}
+
+// SearchFilter задает полнотекстовый поиск в Search: каждое слово Words должно найтись в имени или одном из
+// столбцов Columns (email, telegram), а если Allergies - еще и совпасть со словом аллергий
+type SearchFilter struct {
+	Words     []string
+	Columns   []string
+	Allergies bool
+
+	// Limit ограничивает число кандидатов (0 - без ограничения). Кандидаты упорядочиваются в SQL по оценке
+	// совпадения с весами Weights столбцов (name, email, telegram, allergies), и связи загружаются только у первых Limit.
+	Limit   int
+	Weights map[string]int
+}
+
+// SortField - поле сортировки; Desc - по убыванию
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// sortColumns переводит поля сортировки в столбцы
+var sortColumns = map[string]string{
+	"id":         "contacts.id",
+	"name":       "contacts.name",
+	"created_at": "contacts.created_at",
+	"updated_at": "contacts.updated_at",
+}
+
+// filterColumns переводит поля выражения фильтра в условия SQL
+var filterColumns = filterexpr.SQLBuilder{
+	Columns: map[string]string{
+		"id":         "contacts.id",
+		"name":       "contacts.name",
+		"status":     "contacts.status",
+		"email":      "contacts.email",
+		"transport":  "contacts.transport",
+		"printer":    "contacts.printer",
+		"vk":         "contacts.vk",
+		"telegram":   "contacts.telegram",
+		"city":       "contacts.city",
+		"campus":     "contacts.campus",
+		"building":   "contacts.building",
+		"room":       "contacts.room",
+		"department": "contacts.department",
+		"position":   "contacts.position",
+		"created_at": "contacts.created_at",
+		"updated_at": "contacts.updated_at",
+	},
+	Custom: map[string]func(c *filterexpr.Comparison) (string, []interface{}){
+		"group.id":   relatedCondition(groupsOfContacts, "groups.id"),
+		"group.name": relatedCondition(groupsOfContacts, "groups.name"),
+		"skill.name": relatedCondition(skillsOfContacts, "skills.name"),
+		"tag.name":   relatedCondition(tagsOfContacts, "tags.name"),
+	},
+}
+
+const (
+	groupsOfContacts = "SELECT contact_groups.contact_id FROM contact_groups JOIN groups ON groups.id = contact_groups.group_id WHERE groups.deleted_at IS NULL"
+	skillsOfContacts = "SELECT contact_skills.contact_id FROM contact_skills JOIN skills ON skills.id = contact_skills.skill_id WHERE 1 = 1"
+	tagsOfContacts   = "SELECT contact_tags.contact_id FROM contact_tags JOIN tags ON tags.id = contact_tags.tag_id WHERE 1 = 1"
+)
+
+// relatedCondition строит условие по полю связанных записей (групп, навыков): контакт подходит,
+// если подходит хотя бы одна из них. ne - ни одна не равна значению, eq null - связанных записей нет.
+func relatedCondition(related, column string) func(c *filterexpr.Comparison) (string, []interface{}) {
+	return func(c *filterexpr.Comparison) (string, []interface{}) {
+		if c.Value == nil {
+			if c.Op == filterexpr.Ne {
+				return "contacts.id IN (" + related + ")", nil
+			}
+			return "contacts.id NOT IN (" + related + ")", nil
+		}
+		positive := *c
+		membership := "IN"
+		if c.Op == filterexpr.Ne {
+			positive.Op = filterexpr.Eq
+			membership = "NOT IN"
+		}
+		condition, args := filterexpr.Condition(column, &positive)
+		return "contacts.id " + membership + " (" + related + " AND " + condition + ")", args
+	}
 }
 
 type sqliteRepository struct {
 	db     *gorm.DB
+	cipher *crypto.Cipher
 	logger *slog.Logger
 }
 
 // NewSQLiteRepository создает новый экземпляр sqliteRepository для контактов.
-func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+// Телефон и аллергии шифруются сериализатором GORM, а cipher строит индекс телефона для поиска.
+func NewSQLiteRepository(db *gorm.DB, cipher *crypto.Cipher, logger *slog.Logger) Repository {
 	return &sqliteRepository{
 		db:     db,
+		cipher: cipher,
 		logger: logger,
 	}
 }
@@ -42,7 +225,26 @@ func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
 func (r *sqliteRepository) Create(ctx context.Context, contact *domain.Contact) (*domain.Contact, error) {
 	// Возвращаем к простому созданию. GORM должен сам обработать уникальные индексы.
 	// Проверки на существующие активные email/phone теперь полностью в usecase.
-	if err := r.db.WithContext(ctx).Create(contact).Error; err != nil {
+	if orgID := tenant.FromContext(ctx); orgID != 0 && contact.OrganizationID == 0 {
+		contact.OrganizationID = orgID
+	}
+	contact.PhoneHash = r.cipher.BlindIndex(contact.Phone)
+	contact.AllergyIndex = r.cipher.WordIndex(contact.Allergies)
+	err := transaction.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(contact).Error; err != nil {
+			return err
+		}
+		groupIDs := make([]uint, len(contact.Groups))
+		for i, g := range contact.Groups {
+			groupIDs[i] = g.ID
+		}
+		return recordMembershipEvents(tx, contact.ID, groupIDs, domain.MembershipJoined, contact.UpdatedBy)
+	})
+	if err != nil {
+		if uniqueErr := translateUniqueViolation(err); uniqueErr != nil {
+			r.logger.WarnContext(ctx, "Unique constraint violation while creating contact", slog.Any("error", err), slog.String("contactName", contact.Name))
+			return nil, uniqueErr
+		}
 		r.logger.ErrorContext(ctx, "Error creating contact in DB", slog.Any("error", err), slog.String("contactName", contact.Name))
 		return nil, err
 	}
@@ -53,7 +255,7 @@ func (r *sqliteRepository) Create(ctx context.Context, contact *domain.Contact)
 func (r *sqliteRepository) GetByID(ctx context.Context, id uint) (*domain.Contact, error) {
 	var contact domain.Contact
 	// Загружаем связанные группы при получении контакта
-	if err := r.db.WithContext(ctx).Preload("Groups").First(&contact, id).Error; err != nil {
+	if err := withRelations(transaction.DB(ctx, r.db)).Scopes(tenant.Scope(ctx, "contacts")).Preload("Groups").Preload("Memberships").Preload("Skills").Preload("Tags").Preload("FieldValues.Field").First(&contact, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.WarnContext(ctx, "Contact not found by ID in DB", slog.Uint64("contactID", uint64(id)))
 			return nil, err
@@ -64,9 +266,18 @@ func (r *sqliteRepository) GetByID(ctx context.Context, id uint) (*domain.Contac
 	return &contact, nil
 }
 
+func (r *sqliteRepository) Exists(ctx context.Context, id uint) (bool, error) {
+	var count int64
+	if err := transaction.DB(ctx, r.db).Model(&domain.Contact{}).Scopes(tenant.Scope(ctx, "contacts")).Where("id = ?", id).Count(&count).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error checking contact existence in DB", slog.Uint64("contactID", uint64(id)), slog.Any("error", err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
 func (r *sqliteRepository) GetByEmail(ctx context.Context, email string) (*domain.Contact, error) {
 	var contact domain.Contact
-	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&contact).Error; err != nil {
+	if err := transaction.DB(ctx, r.db).Where("email = ?", email).First(&contact).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.InfoContext(ctx, "Contact not found by email in DB", slog.String("email", email))
 			return nil, err
@@ -79,7 +290,7 @@ func (r *sqliteRepository) GetByEmail(ctx context.Context, email string) (*domai
 
 func (r *sqliteRepository) GetByPhone(ctx context.Context, phone string) (*domain.Contact, error) {
 	var contact domain.Contact
-	if err := r.db.WithContext(ctx).Where("phone = ?", phone).First(&contact).Error; err != nil {
+	if err := transaction.DB(ctx, r.db).Where("phone_hash = ?", r.cipher.BlindIndex(phone)).First(&contact).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.InfoContext(ctx, "Contact not found by phone in DB", slog.String("phone", phone))
 			return nil, err
@@ -93,7 +304,7 @@ func (r *sqliteRepository) GetByPhone(ctx context.Context, phone string) (*domai
 func (r *sqliteRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*domain.Contact, error) {
 	var contact domain.Contact
 	// Загружаем связанные группы при получении контакта по telegram_id
-	if err := r.db.WithContext(ctx).Preload("Groups").Where("telegram_id = ?", telegramID).First(&contact).Error; err != nil {
+	if err := transaction.DB(ctx, r.db).Preload("Groups").Where("telegram_id = ?", telegramID).First(&contact).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.InfoContext(ctx, "Contact not found by telegram ID in DB", slog.Int64("telegram_id", telegramID))
 			return nil, err
@@ -104,17 +315,292 @@ func (r *sqliteRepository) GetByTelegramID(ctx context.Context, telegramID int64
 	return &contact, nil
 }
 
-// GetAll извлекает все контакты (упрощенная версия).
-func (r *sqliteRepository) GetAll(ctx context.Context) ([]domain.Contact, error) {
+// GetAll извлекает контакты, подходящие под фильтр.
+func (r *sqliteRepository) GetAll(ctx context.Context, filter ListFilter) ([]domain.Contact, error) {
 	var contacts []domain.Contact
-	// Загружаем связанные группы для каждого контакта
-	if err := r.db.WithContext(ctx).Preload("Groups").Find(&contacts).Error; err != nil {
+	query, err := r.listQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(filter.Sort) > 0 {
+		if query, err = sorted(query, filter.Sort); err != nil {
+			return nil, err
+		}
+	}
+	if err := query.Find(&contacts).Error; err != nil {
 		r.logger.ErrorContext(ctx, "Error getting all contacts from DB", slog.Any("error", err))
 		return nil, err
 	}
 	return contacts, nil
 }
 
+func (r *sqliteRepository) Each(ctx context.Context, filter ListFilter, batchSize int, fn func(contacts []domain.Contact) error) error {
+	query, err := r.listQuery(ctx, filter)
+	if err != nil {
+		return err
+	}
+	var contacts []domain.Contact
+	if len(filter.Sort) > 0 {
+		// FindInBatches продолжает выборку с последнего ID, что верно только для порядка по ID
+		if query, err = sorted(query, filter.Sort); err != nil {
+			return err
+		}
+		err = eachPage(query, batchSize, fn)
+	} else {
+		err = query.FindInBatches(&contacts, batchSize, func(_ *gorm.DB, _ int) error {
+			return fn(contacts)
+		}).Error
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error iterating contacts from DB", slog.Any("error", err))
+	}
+	return err
+}
+
+// sorted упорядочивает запрос по полям sort, а при их равенстве - по ID
+func sorted(query *gorm.DB, sort []SortField) (*gorm.DB, error) {
+	for _, field := range sort {
+		column, ok := sortColumns[field.Field]
+		if !ok {
+			return nil, fmt.Errorf("unknown contact sort field %q", field.Field)
+		}
+		if field.Desc {
+			column += " DESC"
+		}
+		query = query.Order(column)
+	}
+	return query.Order("contacts.id"), nil
+}
+
+// eachPage читает упорядоченный запрос страницами по batchSize. Контакт, добавленный или измененный
+// во время обхода, может сдвинуть страницы, поэтому обход по ID без сортировки надежнее.
+func eachPage(query *gorm.DB, batchSize int, fn func(contacts []domain.Contact) error) error {
+	for offset := 0; ; offset += batchSize {
+		var contacts []domain.Contact
+		if err := query.Session(&gorm.Session{}).Offset(offset).Limit(batchSize).Find(&contacts).Error; err != nil {
+			return err
+		}
+		if len(contacts) > 0 {
+			if err := fn(contacts); err != nil {
+				return err
+			}
+		}
+		if len(contacts) < batchSize {
+			return nil
+		}
+	}
+}
+
+// listQuery строит запрос контактов по фильтру вместе со связями, группами, сроками членства, навыками и метками
+func (r *sqliteRepository) listQuery(ctx context.Context, filter ListFilter) (*gorm.DB, error) {
+	query := withRelations(transaction.DB(ctx, r.db)).Scopes(tenant.Scope(ctx, "contacts")).Preload("Groups").Preload("Memberships").Preload("Skills").Preload("Tags").Preload("FieldValues.Field")
+	if len(filter.SkillNames) > 0 {
+		query = query.Where("contacts.id IN (?)", r.db.Table("contact_skills").
+			Select("contact_skills.contact_id").
+			Joins("JOIN skills ON skills.id = contact_skills.skill_id").
+			Where("skills.name IN ?", filter.SkillNames))
+	}
+	if len(filter.TagNames) > 0 {
+		query = query.Where("contacts.id IN (?)", r.db.Table("contact_tags").
+			Select("contact_tags.contact_id").
+			Joins("JOIN tags ON tags.id = contact_tags.tag_id").
+			Where("tags.name IN ?", filter.TagNames).
+			Scopes(tenant.Scope(ctx, "tags")))
+	}
+	if filter.GroupID != 0 {
+		query = query.Where("contacts.id IN (?)", r.db.Table("contact_groups").
+			Select("contact_groups.contact_id").
+			Where("contact_groups.group_id = ?", filter.GroupID))
+	}
+	if len(filter.GroupIDs) > 0 {
+		query = query.Where("contacts.id IN (?)", r.db.Table("contact_groups").
+			Select("contact_groups.contact_id").
+			Where("contact_groups.group_id IN ?", filter.GroupIDs))
+	}
+	if len(filter.IDs) > 0 {
+		query = query.Where("contacts.id IN ?", filter.IDs)
+	}
+	if !filter.IncludeArchived {
+		query = query.Where("contacts.archived_at IS NULL")
+	}
+	if filter.Expression != nil {
+		condition, args, err := filterColumns.Build(filter.Expression)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Failed to build contact filter condition", slog.Any("error", err))
+			return nil, err
+		}
+		query = query.Where(condition, args...)
+	}
+	if filter.Search != "" {
+		query = query.Where(r.searchCondition(filter))
+	}
+	if filter.Email != "" {
+		condition, args := filterexpr.Condition("contacts.email", &filterexpr.Comparison{Op: filterexpr.Contains, Value: filter.Email})
+		query = query.Where(condition, args...)
+	}
+	if filter.Phone != "" {
+		query = query.Where("contacts.phone_hash = ?", r.cipher.BlindIndex(filter.Phone))
+	}
+	if filter.HasTelegram != nil {
+		// Колонки допускают NULL у контактов, созданных до их появления
+		const linked = "(COALESCE(contacts.telegram_id, 0) <> 0 OR COALESCE(contacts.telegram, '') <> '')"
+		if *filter.HasTelegram {
+			query = query.Where(linked)
+		} else {
+			query = query.Where("NOT " + linked)
+		}
+	}
+	for column, value := range map[string]string{"status": filter.Status, "city": filter.City, "campus": filter.Campus, "building": filter.Building, "room": filter.Room, "department": filter.Department} {
+		if value != "" {
+			query = query.Where("contacts."+column+" = ?", value)
+		}
+	}
+	return query, nil
+}
+
+func (r *sqliteRepository) Search(ctx context.Context, filter SearchFilter) ([]domain.Contact, error) {
+	query, err := r.listQuery(ctx, ListFilter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, word := range filter.Words {
+		query = query.Where(r.wordCondition(word, filter))
+	}
+	if filter.Limit > 0 {
+		score, args := r.searchScore(filter)
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                score + " DESC, contacts.name, contacts.id",
+			Vars:               args,
+			WithoutParentheses: true,
+		}}).Limit(filter.Limit)
+	}
+	var contacts []domain.Contact
+	if err := query.Find(&contacts).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error searching contacts in DB", slog.Int("words", len(filter.Words)), slog.Any("error", err))
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// wordCondition строит условие для одного слова полнотекстового запроса. Аллергии зашифрованы, поэтому
+// сравниваются по индексам слов (allergy_index): слово запроса должно совпасть со словом аллергий целиком.
+func (r *sqliteRepository) wordCondition(word string, filter SearchFilter) *gorm.DB {
+	condition := r.db.Where("1 = 0")
+	for _, column := range append([]string{"name"}, filter.Columns...) {
+		value := word
+		if column == "telegram" {
+			// Имя пользователя Telegram хранится как введено, с @ или без
+			value = strings.TrimPrefix(word, "@")
+		}
+		if value == "" {
+			continue
+		}
+		for _, v := range likeVariants(value) {
+			sql, args := filterexpr.Condition("contacts."+column, &filterexpr.Comparison{Op: filterexpr.Contains, Value: v})
+			condition = condition.Or(sql, args...)
+		}
+	}
+	if sql, args := r.allergyCondition(word); filter.Allergies && sql != "" {
+		condition = condition.Or(sql, args...)
+	}
+	return condition
+}
+
+// allergyCondition строит условие совпадения всех слов word со словами аллергий; пустое, если слов нет
+func (r *sqliteRepository) allergyCondition(word string) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, w := range crypto.Words(word) {
+		conditions = append(conditions, "(' ' || contacts.allergy_index || ' ') LIKE ?")
+		args = append(args, "% "+r.cipher.BlindIndex(w)+" %")
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "(" + strings.Join(conditions, " AND ") + ")", args
+}
+
+// searchScore строит SQL-оценку совпадения контакта со словами запроса для отбора кандидатов: сумма по словам
+// и столбцам веса столбца, умноженного на качество совпадения (3 - значение целиком, 2 - начало слова, 1 - подстрока).
+// Оценка приблизительная (не учитывает ё/е и уровни видимости полей): точное ранжирование выполняет usecase.
+func (r *sqliteRepository) searchScore(filter SearchFilter) (string, []interface{}) {
+	terms := []string{"0"}
+	var args []interface{}
+	for _, word := range filter.Words {
+		for _, column := range append([]string{"name"}, filter.Columns...) {
+			weight := filter.Weights[column]
+			value, expr := word, "contacts."+column
+			if column == "telegram" {
+				value, expr = strings.TrimPrefix(word, "@"), "LTRIM(contacts.telegram, '@')"
+			}
+			if weight == 0 || value == "" {
+				continue
+			}
+			var exact, prefix, contains []string
+			for _, v := range likeVariants(value) {
+				sql, a := filterexpr.Condition(expr, &filterexpr.Comparison{Op: filterexpr.Eq, Value: v})
+				exact, args = append(exact, sql), append(args, a...)
+			}
+			for _, v := range likeVariants(value) {
+				for _, c := range []*filterexpr.Comparison{{Op: filterexpr.StartsWith, Value: v}, {Op: filterexpr.Contains, Value: " " + v}} {
+					sql, a := filterexpr.Condition(expr, c)
+					prefix, args = append(prefix, sql), append(args, a...)
+				}
+			}
+			for _, v := range likeVariants(value) {
+				sql, a := filterexpr.Condition(expr, &filterexpr.Comparison{Op: filterexpr.Contains, Value: v})
+				contains, args = append(contains, sql), append(args, a...)
+			}
+			terms = append(terms, fmt.Sprintf("%d * (CASE WHEN %s THEN 3 WHEN %s THEN 2 WHEN %s THEN 1 ELSE 0 END)",
+				weight, strings.Join(exact, " OR "), strings.Join(prefix, " OR "), strings.Join(contains, " OR ")))
+		}
+		if sql, a := r.allergyCondition(word); filter.Allergies && filter.Weights["allergies"] > 0 && sql != "" {
+			// Аллергии совпадают только целыми словами
+			terms = append(terms, fmt.Sprintf("%d * (CASE WHEN %s THEN 2 ELSE 0 END)", filter.Weights["allergies"], sql))
+			args = append(args, a...)
+		}
+	}
+	return "(" + strings.Join(terms, " + ") + ")", args
+}
+
+// searchCondition строит условие строки поиска: совпадение в любом из столбцов. LIKE в SQLite не различает
+// регистр только латиницы, поэтому кириллица ищется в написании запроса, строчными и с заглавной первой буквы.
+func (r *sqliteRepository) searchCondition(filter ListFilter) *gorm.DB {
+	variants := likeVariants(filter.Search)
+	condition := r.db.Where("1 = 0")
+	for _, column := range append([]string{"name"}, filter.SearchColumns...) {
+		for _, v := range variants {
+			sql, args := filterexpr.Condition("contacts."+column, &filterexpr.Comparison{Op: filterexpr.Contains, Value: v})
+			condition = condition.Or(sql, args...)
+		}
+	}
+	if filter.SearchPhone != "" {
+		condition = condition.Or("contacts.phone_hash = ?", r.cipher.BlindIndex(filter.SearchPhone))
+	}
+	return condition
+}
+
+// likeVariants возвращает написания s, в которых его ищет LIKE: как введено, строчными и с заглавной первой буквы
+func likeVariants(s string) []string {
+	variants := []string{s}
+	lower := strings.ToLower(s)
+	for _, v := range []string{lower, capitalize(lower)} {
+		if !slices.Contains(variants, v) {
+			variants = append(variants, v)
+		}
+	}
+	return variants
+}
+
+// capitalize переводит первую букву s в верхний регистр
+func capitalize(s string) string {
+	first, size := utf8.DecodeRuneInString(s)
+	if first == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(first)) + s[size:]
+}
+
 func (r *sqliteRepository) Update(ctx context.Context, contact *domain.Contact) error {
 	// При обновлении контакта важно также обновить его связи с группами.
 	// GORM .Save() для структуры с ассоциациями many2many может потребовать явного управления ассоциациями,
@@ -126,28 +612,64 @@ func (r *sqliteRepository) Update(ctx context.Context, contact *domain.Contact)
 	// Начнем с обновления только полей самого контакта.
 	// Ассоциации будем менеджить через AddContactToGroup/RemoveContactFromGroup.
 
-	tx := r.db.WithContext(ctx).Begin()
+	contact.PhoneHash = r.cipher.BlindIndex(contact.Phone)
+	contact.AllergyIndex = r.cipher.WordIndex(contact.Allergies)
+	// Transaction, а не Begin: внутри внешней транзакции (pkg/transaction) GORM использует точку сохранения
+	err := transaction.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var before domain.Contact
+		if err := tx.First(&before, contact.ID).Error; err != nil {
+			r.logger.ErrorContext(ctx, "Error getting contact before update from DB", slog.Uint64("contactID", uint64(contact.ID)), slog.Any("error", err))
+			return err
+		}
 
-	// Обновляем основные поля контакта
-	// Используем Select, чтобы обновить только указанные поля, исключая ассоциации из этого шага
-	if err := tx.Select("Name", "Phone", "Email", "Transport", "Printer", "Allergies", "VK", "Telegram", "TelegramID", "UpdatedAt").Updates(contact).Error; err != nil {
-		tx.Rollback()
-		r.logger.ErrorContext(ctx, "Error updating contact fields in DB", slog.Uint64("contactID", uint64(contact.ID)), slog.Any("error", err))
-		return err
-	}
+		// Подтверждение относится к адресу: новый email подтверждается заново
+		contact.EmailVerified = before.EmailVerified && before.Email == contact.Email
 
-	// Обновляем ассоциации (если переданы группы в contact.Groups)
-	// Это заменит все существующие ассоциации на новые.
-	if contact.Groups != nil { // Проверяем, переданы ли группы для обновления
-		if err := tx.Model(contact).Association("Groups").Replace(contact.Groups); err != nil {
-			tx.Rollback()
-			r.logger.ErrorContext(ctx, "Error updating contact group associations in DB", slog.Uint64("contactID", uint64(contact.ID)), slog.Any("error", err))
+		// Обновляем основные поля контакта
+		// Используем Select, чтобы обновить только указанные поля, исключая ассоциации из этого шага
+		if err := tx.Select("Name", "Phone", "PhoneHash", "Email", "EmailVerified", "Transport", "Printer", "Allergies", "AllergyIndex", "VK", "Telegram", "TelegramID", "AvatarCheckedAt", "City", "Campus", "Building", "Room", "Department", "Position", "UpdatedBy", "UpdatedAt").Updates(contact).Error; err != nil {
+			if uniqueErr := translateUniqueViolation(err); uniqueErr != nil {
+				r.logger.WarnContext(ctx, "Unique constraint violation while updating contact", slog.Uint64("contactID", uint64(contact.ID)), slog.Any("error", err))
+				return uniqueErr
+			}
+			r.logger.ErrorContext(ctx, "Error updating contact fields in DB", slog.Uint64("contactID", uint64(contact.ID)), slog.Any("error", err))
 			return err
 		}
-	}
+		if changes := diffContact(&before, contact); len(changes) > 0 {
+			if err := recordHistory(tx, &before, domain.ContactHistoryUpdated, changes, contact.UpdatedBy); err != nil {
+				r.logger.ErrorContext(ctx, "Error recording contact history in DB", slog.Uint64("contactID", uint64(contact.ID)), slog.Any("error", err))
+				return err
+			}
+		}
 
-	if err := tx.Commit().Error; err != nil {
-		r.logger.ErrorContext(ctx, "Error committing transaction for contact update", slog.Uint64("contactID", uint64(contact.ID)), slog.Any("error", err))
+		// Обновляем ассоциации (если переданы группы в contact.Groups)
+		// Это заменит все существующие ассоциации на новые.
+		if contact.Groups != nil { // Проверяем, переданы ли группы для обновления
+			var before []uint
+			if err := tx.Table("contact_groups").Where("contact_id = ?", contact.ID).Pluck("group_id", &before).Error; err != nil {
+				r.logger.ErrorContext(ctx, "Error getting contact group associations from DB", slog.Uint64("contactID", uint64(contact.ID)), slog.Any("error", err))
+				return err
+			}
+			if err := tx.Model(contact).Association("Groups").Replace(contact.Groups); err != nil {
+				r.logger.ErrorContext(ctx, "Error updating contact group associations in DB", slog.Uint64("contactID", uint64(contact.ID)), slog.Any("error", err))
+				return err
+			}
+
+			// Разница между прежним и новым составом групп попадает в историю членства
+			after := make([]uint, len(contact.Groups))
+			for i, g := range contact.Groups {
+				after[i] = g.ID
+			}
+			if err := recordMembershipEvents(tx, contact.ID, subtractIDs(after, before), domain.MembershipJoined, contact.UpdatedBy); err != nil {
+				return err
+			}
+			if err := recordMembershipEvents(tx, contact.ID, subtractIDs(before, after), domain.MembershipLeft, contact.UpdatedBy); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
@@ -155,69 +677,153 @@ func (r *sqliteRepository) Update(ctx context.Context, contact *domain.Contact)
 	return nil
 }
 
-func (r *sqliteRepository) Delete(ctx context.Context, id uint) error {
-	// Мягкое удаление, GORM сам обработает DeletedAt
-	// Также нужно учесть удаление связей в contact_groups. GORM должен это сделать автоматически при правильной настройке foreign keys и onDelete каскадов, либо это нужно делать явно.
-	// Пока что просто удаляем контакт.
-	result := r.db.WithContext(ctx).Delete(&domain.Contact{}, id)
+func (r *sqliteRepository) UpdateStatus(ctx context.Context, id uint, status string) error {
+	result := transaction.DB(ctx, r.db).Model(&domain.Contact{}).Scopes(tenant.Scope(ctx, "contacts")).Where("id = ?", id).Update("status", status)
 	if result.Error != nil {
-		r.logger.ErrorContext(ctx, "Error deleting contact from DB", slog.Uint64("contactID", uint64(id)), slog.Any("error", result.Error))
+		r.logger.ErrorContext(ctx, "Error updating contact status in DB", slog.Uint64("contactID", uint64(id)), slog.Any("error", result.Error))
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		r.logger.WarnContext(ctx, "Contact not found for deletion in DB", slog.Uint64("contactID", uint64(id)))
 		return gorm.ErrRecordNotFound
 	}
-	r.logger.InfoContext(ctx, "Successfully marked contact as deleted in DB", slog.Uint64("contactID", uint64(id)))
+	r.logger.InfoContext(ctx, "Successfully updated contact status in DB", slog.Uint64("contactID", uint64(id)), slog.String("status", status))
+	return nil
+}
+
+func (r *sqliteRepository) SetArchived(ctx context.Context, id uint, archivedAt *time.Time) error {
+	result := transaction.DB(ctx, r.db).Model(&domain.Contact{}).Scopes(tenant.Scope(ctx, "contacts")).Where("id = ?", id).Update("archived_at", archivedAt)
+	if result.Error != nil {
+		r.logger.ErrorContext(ctx, "Error updating contact archive state in DB", slog.Uint64("contactID", uint64(id)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	r.logger.InfoContext(ctx, "Successfully updated contact archive state in DB", slog.Uint64("contactID", uint64(id)), slog.Bool("archived", archivedAt != nil))
 	return nil
 }
 
-func (r *sqliteRepository) AddContactToGroup(ctx context.Context, contact *domain.Contact, group *domain.Group) error {
-	if err := r.db.WithContext(ctx).Model(contact).Association("Groups").Append(group); err != nil {
-		r.logger.ErrorContext(ctx, "Error adding contact to group in DB", slog.Uint64("contactID", uint64(contact.ID)), slog.Uint64("groupID", uint64(group.ID)), slog.Any("error", err))
+func (r *sqliteRepository) SetEmailVerified(ctx context.Context, id uint, email string) error {
+	// UpdateColumn не меняет updated_at: подтверждение адреса не считается изменением контакта
+	result := transaction.DB(ctx, r.db).Model(&domain.Contact{}).Scopes(tenant.Scope(ctx, "contacts")).Where("id = ? AND email = ?", id, email).UpdateColumn("email_verified", true)
+	if result.Error != nil {
+		r.logger.ErrorContext(ctx, "Error marking contact email verified in DB", slog.Uint64("contactID", uint64(id)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	r.logger.InfoContext(ctx, "Successfully marked contact email verified in DB", slog.Uint64("contactID", uint64(id)))
+	return nil
+}
+
+func (r *sqliteRepository) UpdateFieldVisibility(ctx context.Context, id uint, visibility string) error {
+	result := transaction.DB(ctx, r.db).Model(&domain.Contact{}).Scopes(tenant.Scope(ctx, "contacts")).Where("id = ?", id).Update("field_visibility", visibility)
+	if result.Error != nil {
+		r.logger.ErrorContext(ctx, "Error updating contact field visibility in DB", slog.Uint64("contactID", uint64(id)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	r.logger.InfoContext(ctx, "Successfully updated contact field visibility in DB", slog.Uint64("contactID", uint64(id)))
+	return nil
+}
+
+func (r *sqliteRepository) SetBlocked(ctx context.Context, id uint, blocked bool) error {
+	result := transaction.DB(ctx, r.db).Model(&domain.Contact{}).Scopes(tenant.Scope(ctx, "contacts")).Where("id = ?", id).Update("blocked", blocked)
+	if result.Error != nil {
+		r.logger.ErrorContext(ctx, "Error updating contact blocked flag in DB", slog.Uint64("contactID", uint64(id)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	r.logger.InfoContext(ctx, "Successfully updated contact blocked flag in DB", slog.Uint64("contactID", uint64(id)), slog.Bool("blocked", blocked))
+	return nil
+}
+
+func (r *sqliteRepository) Delete(ctx context.Context, id uint, actorID *uint) error {
+	// Мягкое удаление, GORM сам обработает DeletedAt
+	// Также нужно учесть удаление связей в contact_groups. GORM должен это сделать автоматически при правильной настройке foreign keys и onDelete каскадов, либо это нужно делать явно.
+	// Пока что просто удаляем контакт.
+	err := transaction.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var contact domain.Contact
+		if err := tx.Scopes(tenant.Scope(ctx, "contacts")).First(&contact, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				r.logger.WarnContext(ctx, "Contact not found for deletion in DB", slog.Uint64("contactID", uint64(id)))
+			} else {
+				r.logger.ErrorContext(ctx, "Error getting contact for deletion from DB", slog.Uint64("contactID", uint64(id)), slog.Any("error", err))
+			}
+			return err
+		}
+		if err := tx.Delete(&contact).Error; err != nil {
+			r.logger.ErrorContext(ctx, "Error deleting contact from DB", slog.Uint64("contactID", uint64(id)), slog.Any("error", err))
+			return err
+		}
+		if err := recordHistory(tx, &contact, domain.ContactHistoryDeleted, nil, actorID); err != nil {
+			r.logger.ErrorContext(ctx, "Error recording contact history in DB", slog.Uint64("contactID", uint64(id)), slog.Any("error", err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
-	r.logger.InfoContext(ctx, "Successfully added contact to group in DB", slog.Uint64("contactID", uint64(contact.ID)), slog.Uint64("groupID", uint64(group.ID)))
+	r.logger.InfoContext(ctx, "Successfully marked contact as deleted in DB", slog.Uint64("contactID", uint64(id)))
 	return nil
 }
 
-func (r *sqliteRepository) RemoveContactFromGroup(ctx context.Context, contact *domain.Contact, group *domain.Group) error {
-	if err := r.db.WithContext(ctx).Model(contact).Association("Groups").Delete(group); err != nil {
-		r.logger.ErrorContext(ctx, "Error removing contact from group in DB", slog.Uint64("contactID", uint64(contact.ID)), slog.Uint64("groupID", uint64(group.ID)), slog.Any("error", err))
+func (r *sqliteRepository) AddSkill(ctx context.Context, contact *domain.Contact, skill *domain.Skill) error {
+	if err := transaction.DB(ctx, r.db).Model(contact).Association("Skills").Append(skill); err != nil {
+		r.logger.ErrorContext(ctx, "Error adding skill to contact in DB", slog.Uint64("contactID", uint64(contact.ID)), slog.Uint64("skillID", uint64(skill.ID)), slog.Any("error", err))
 		return err
 	}
-	r.logger.InfoContext(ctx, "Successfully removed contact from group in DB", slog.Uint64("contactID", uint64(contact.ID)), slog.Uint64("groupID", uint64(group.ID)))
 	return nil
 }
 
-func (r *sqliteRepository) GetByEmailUnscoped(ctx context.Context, email string) (*domain.Contact, error) {
-	var contact domain.Contact
-	if err := r.db.Unscoped().WithContext(ctx).Where("email = ?", email).First(&contact).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			r.logger.InfoContext(ctx, "Contact not found by email (unscoped) in DB", slog.String("email", email))
-			return nil, err
-		}
-		r.logger.ErrorContext(ctx, "Error getting contact by email (unscoped) from DB", slog.String("email", email), slog.Any("error", err))
-		return nil, err
+func (r *sqliteRepository) RemoveSkill(ctx context.Context, contact *domain.Contact, skill *domain.Skill) error {
+	if err := transaction.DB(ctx, r.db).Model(contact).Association("Skills").Delete(skill); err != nil {
+		r.logger.ErrorContext(ctx, "Error removing skill from contact in DB", slog.Uint64("contactID", uint64(contact.ID)), slog.Uint64("skillID", uint64(skill.ID)), slog.Any("error", err))
+		return err
 	}
-	return &contact, nil
+	return nil
 }
 
-func (r *sqliteRepository) GetByPhoneUnscoped(ctx context.Context, phone string) (*domain.Contact, error) {
-	var contact domain.Contact
-	if err := r.db.Unscoped().WithContext(ctx).Where("phone = ?", phone).First(&contact).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			r.logger.InfoContext(ctx, "Contact not found by phone (unscoped) in DB", slog.String("phone", phone))
-			return nil, err
-		}
-		r.logger.ErrorContext(ctx, "Error getting contact by phone (unscoped) from DB", slog.String("phone", phone), slog.Any("error", err))
+// GetDeletedByPhoneOrEmail возвращает мягко удаленные контакты с указанным телефоном или email.
+// Пустые значения не участвуют в поиске.
+func (r *sqliteRepository) GetDeletedByPhoneOrEmail(ctx context.Context, phone, email string) ([]domain.Contact, error) {
+	var contacts []domain.Contact
+	if phone == "" && email == "" {
+		return contacts, nil
+	}
+
+	query := transaction.DB(ctx, r.db).Unscoped().Scopes(tenant.Scope(ctx, "contacts")).Preload("Groups").Where("deleted_at IS NOT NULL")
+	switch {
+	case phone != "" && email != "":
+		query = query.Where("phone_hash = ? OR email = ?", r.cipher.BlindIndex(phone), email)
+	case phone != "":
+		query = query.Where("phone_hash = ?", r.cipher.BlindIndex(phone))
+	default:
+		query = query.Where("email = ?", email)
+	}
+
+	if err := query.Order("deleted_at DESC").Find(&contacts).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting deleted contacts by phone or email from DB", slog.String("phone", phone), slog.String("email", email), slog.Any("error", err))
 		return nil, err
 	}
-	return &contact, nil
+	return contacts, nil
 }
 
 func (r *sqliteRepository) HardDelete(ctx context.Context, id uint) error {
-	result := r.db.Unscoped().WithContext(ctx).Delete(&domain.Contact{}, id)
+	if err := r.deleteContactRelations(ctx, id); err != nil {
+		return err
+	}
+	if err := transaction.DB(ctx, r.db).Where("contact_id = ?", id).Delete(&domain.FavoriteContact{}).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error deleting favorites of contact from DB", slog.Uint64("contactID", uint64(id)), slog.Any("error", err))
+		return err
+	}
+	result := transaction.DB(ctx, r.db).Unscoped().Delete(&domain.Contact{}, id)
 	if result.Error != nil {
 		r.logger.ErrorContext(ctx, "Error hard deleting contact from DB", slog.Uint64("contactID", uint64(id)), slog.Any("error", result.Error))
 		return result.Error
@@ -229,3 +835,140 @@ func (r *sqliteRepository) HardDelete(ctx context.Context, id uint) error {
 	r.logger.InfoContext(ctx, "Successfully hard deleted contact from DB", slog.Uint64("contactID", uint64(id)))
 	return nil
 }
+
+// translateUniqueViolation преобразует ошибку нарушения уникального индекса по телефону или email
+// в ErrDuplicatePhone/ErrDuplicateEmail. Для остальных ошибок возвращает nil.
+// Поддерживаются сообщения SQLite ("UNIQUE constraint failed: contacts.phone_hash")
+// и Postgres ("duplicate key value violates unique constraint \"idx_contacts_phone_hash_active\"").
+func translateUniqueViolation(err error) error {
+	msg := strings.ToLower(err.Error())
+	if !strings.Contains(msg, "unique constraint") && !strings.Contains(msg, "duplicate key") {
+		return nil
+	}
+	switch {
+	case strings.Contains(msg, "contacts.phone") || strings.Contains(msg, "idx_contacts_phone"):
+		return ErrDuplicatePhone
+	case strings.Contains(msg, "contacts.email") || strings.Contains(msg, "idx_contacts_email"):
+		return ErrDuplicateEmail
+	case strings.Contains(msg, "contacts.telegram_id") || strings.Contains(msg, "idx_contacts_telegram_id"):
+		return ErrDuplicateTelegramID
+	}
+	return nil
+}
+
+// Restore снимает отметку мягкого удаления с контакта.
+// Если телефон или email уже заняты активным контактом, возвращает ErrDuplicatePhone/ErrDuplicateEmail.
+func (r *sqliteRepository) Restore(ctx context.Context, id uint) error {
+	result := transaction.DB(ctx, r.db).Unscoped().Model(&domain.Contact{}).Scopes(tenant.Scope(ctx, "contacts")).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		if uniqueErr := translateUniqueViolation(result.Error); uniqueErr != nil {
+			r.logger.WarnContext(ctx, "Unique constraint violation while restoring contact", slog.Uint64("contactID", uint64(id)), slog.Any("error", result.Error))
+			return uniqueErr
+		}
+		r.logger.ErrorContext(ctx, "Error restoring contact in DB", slog.Uint64("contactID", uint64(id)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		r.logger.WarnContext(ctx, "Deleted contact not found for restore in DB", slog.Uint64("contactID", uint64(id)))
+		return gorm.ErrRecordNotFound
+	}
+	r.logger.InfoContext(ctx, "Successfully restored contact in DB", slog.Uint64("contactID", uint64(id)))
+	return nil
+}
+
+func (r *sqliteRepository) GetHistory(ctx context.Context, contactID uint) ([]domain.ContactHistory, error) {
+	var history []domain.ContactHistory
+	if err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "contact_histories")).
+		Where("contact_id = ?", contactID).
+		Order("created_at DESC, id DESC").
+		Find(&history).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting contact history from DB", slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return nil, err
+	}
+	return history, nil
+}
+
+// historyFields - поля контакта, изменения которых записываются в историю, в порядке вывода.
+// Имена совпадают с полями ответа API; группы пишутся в историю членства.
+var historyFields = []struct {
+	name  string
+	value func(c *domain.Contact) string
+}{
+	{"name", func(c *domain.Contact) string { return c.Name }},
+	{"phone", func(c *domain.Contact) string { return c.Phone }},
+	{"email", func(c *domain.Contact) string { return c.Email }},
+	{"transport", func(c *domain.Contact) string { return c.Transport }},
+	{"printer", func(c *domain.Contact) string { return c.Printer }},
+	{"allergies", func(c *domain.Contact) string { return c.Allergies }},
+	{"vk", func(c *domain.Contact) string { return c.VK }},
+	{"telegram", func(c *domain.Contact) string { return c.Telegram }},
+	{"telegram_id", func(c *domain.Contact) string { return strconv.FormatInt(c.TelegramID, 10) }},
+	{"city", func(c *domain.Contact) string { return c.City }},
+	{"campus", func(c *domain.Contact) string { return c.Campus }},
+	{"building", func(c *domain.Contact) string { return c.Building }},
+	{"room", func(c *domain.Contact) string { return c.Room }},
+	{"department", func(c *domain.Contact) string { return c.Department }},
+	{"position", func(c *domain.Contact) string { return c.Position }},
+}
+
+// diffContact возвращает поля, значения которых различаются у before и after
+func diffContact(before, after *domain.Contact) []domain.FieldChange {
+	var changes []domain.FieldChange
+	for _, f := range historyFields {
+		if old, updated := f.value(before), f.value(after); old != updated {
+			changes = append(changes, domain.FieldChange{Field: f.name, Old: old, New: updated})
+		}
+	}
+	return changes
+}
+
+// recordHistory записывает в историю действие action пользователя actorID над контактом
+func recordHistory(tx *gorm.DB, contact *domain.Contact, action string, changes []domain.FieldChange, actorID *uint) error {
+	entry := domain.ContactHistory{
+		OrganizationID: contact.OrganizationID,
+		ContactID:      contact.ID,
+		ActorID:        actorID,
+		Action:         action,
+	}
+	if err := entry.SetChangeList(changes); err != nil {
+		return err
+	}
+	return tx.Create(&entry).Error
+}
+
+// recordMembershipEvents записывает в историю членства вступление в группы или выход из них,
+// выполненные пользователем actorID через форму контакта
+func recordMembershipEvents(tx *gorm.DB, contactID uint, groupIDs []uint, eventType string, actorID *uint) error {
+	if len(groupIDs) == 0 {
+		return nil
+	}
+	events := make([]domain.GroupMembershipEvent, len(groupIDs))
+	for i, groupID := range groupIDs {
+		events[i] = domain.GroupMembershipEvent{
+			ContactID: contactID,
+			GroupID:   groupID,
+			Type:      eventType,
+			Source:    domain.MembershipSourceManual,
+			ActorID:   actorID,
+		}
+	}
+	return tx.Create(&events).Error
+}
+
+// subtractIDs возвращает ID из a, которых нет в b
+func subtractIDs(a, b []uint) []uint {
+	exclude := make(map[uint]bool, len(b))
+	for _, id := range b {
+		exclude[id] = true
+	}
+	var result []uint
+	for _, id := range a {
+		if !exclude[id] {
+			result = append(result, id)
+			exclude[id] = true
+		}
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"rim/internal/config"
+	"rim/internal/domain"
+	"rim/pkg/crypto"
+	"rim/pkg/database"
+)
+
+func newTestRepository(t *testing.T) Repository {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cipher, err := crypto.NewCipher(bytes.Repeat([]byte{1}, crypto.KeySize))
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	db, err := database.NewSQLiteConnection(&config.Config{SQLitePath: filepath.Join(t.TempDir(), "rim.db")}, cipher, logger)
+	if err != nil {
+		t.Fatalf("NewSQLiteConnection: %v", err)
+	}
+	return NewSQLiteRepository(db, cipher, logger)
+}
+
+func TestSearchLimitKeepsBestCandidates(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	// Слабые совпадения (подстрока) созданы раньше сильных и при отборе без оценки заняли бы весь лимит
+	for i := range 20 {
+		contact := &domain.Contact{Name: fmt.Sprintf("Joanna %02d", i), Phone: fmt.Sprintf("+7999000%04d", i)}
+		if _, err := repo.Create(ctx, contact); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	for _, c := range []domain.Contact{
+		{Name: "Anna Petrova", Phone: "+79991000001"},                    // Начало слова имени
+		{Name: "Anna", Phone: "+79991000002"},                            // Имя целиком
+		{Name: "Boris", Phone: "+79991000003", Telegram: "@anna_b"},      // Начало имени пользователя Telegram
+		{Name: "Vera", Phone: "+79991000004", Email: "anna@example.com"}, // Начало email: оценка как у подстроки имени
+	} {
+		contact := c
+		if _, err := repo.Create(ctx, &contact); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	filter := SearchFilter{
+		Words:   []string{"anna"},
+		Columns: []string{"email", "telegram"},
+		Limit:   5,
+		Weights: map[string]int{"name": 4, "telegram": 3, "email": 2},
+	}
+	contacts, err := repo.Search(ctx, filter)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	var names []string
+	for _, c := range contacts {
+		names = append(names, c.Name)
+	}
+	// При равной оценке кандидаты упорядочены по имени
+	want := []string{"Anna", "Anna Petrova", "Boris", "Joanna 00", "Joanna 01"}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("candidates = %v, want %v", names, want)
+	}
+
+	// Без лимита возвращаются все совпадения
+	filter.Limit = 0
+	contacts, err = repo.Search(ctx, filter)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(contacts) != 24 {
+		t.Errorf("found %d contacts without limit, want 24", len(contacts))
+	}
+}
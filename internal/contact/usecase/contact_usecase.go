@@ -5,26 +5,48 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	contactRepo "rim/internal/contact/repository"
 	"rim/internal/domain"
 	groupRepo "rim/internal/group/repository"
 	groupUseCase "rim/internal/group/usecase" // Для ошибок ErrGroupNotFound
+	locationRepo "rim/internal/location/repository"
+	locationUseCase "rim/internal/location/usecase"
+	policyUseCase "rim/internal/policy/usecase"
+	skillRepo "rim/internal/skill/repository"
+	skillUseCase "rim/internal/skill/usecase"
+	"rim/pkg/apperror"
+	"rim/pkg/eventbus"
+	"rim/pkg/filterexpr"
+	"rim/pkg/transaction"
 
 	"gorm.io/gorm"
 )
 
 var (
-	ErrContactNotFound    = errors.New("contact not found")
-	ErrContactNameEmpty   = errors.New("contact name cannot be empty")
-	ErrContactPhoneEmpty  = errors.New("contact phone cannot be empty")
-	ErrContactEmailEmpty  = errors.New("contact email cannot be empty")
-	ErrContactPhoneExists = errors.New("contact with this phone already exists")
-	ErrContactEmailExists = errors.New("contact with this email already exists")
-	ErrInvalidEmailFormat = errors.New("invalid email format")
-	ErrInvalidPhoneFormat = errors.New("invalid phone format") // Может понадобиться более сложная валидация
-	ErrGroupAssociation   = errors.New("error associating contact with group")
+	ErrContactNotFound       = apperror.NotFound("contact_not_found", "contact not found")
+	ErrContactNameEmpty      = apperror.Invalid("contact_name_empty", "contact name cannot be empty")
+	ErrContactPhoneEmpty     = apperror.Invalid("contact_phone_empty", "contact phone cannot be empty")
+	ErrContactEmailEmpty     = apperror.Invalid("contact_email_empty", "contact email cannot be empty")
+	ErrContactPhoneExists    = contactRepo.ErrDuplicatePhone // Возвращается также при гонке на уникальном индексе в БД
+	ErrContactEmailExists    = contactRepo.ErrDuplicateEmail
+	ErrContactTelegramExists = contactRepo.ErrDuplicateTelegramID
+	ErrInvalidEmailFormat    = apperror.Invalid("invalid_email_format", "invalid email format")
+	ErrInvalidPhoneFormat    = apperror.Invalid("invalid_phone_format", "invalid phone format") // Может понадобиться более сложная валидация
+	ErrGroupAssociation      = apperror.Internal("group_association_failed", "error associating contact with group")
+	ErrInvalidStatus         = apperror.Invalid("invalid_contact_status", "invalid contact status")
+	ErrStatusTransition      = apperror.Conflict("contact_status_transition", "contact status transition is not allowed")
+	ErrInvalidFilter         = apperror.Invalid("invalid_contact_filter", "invalid contact filter")
+	ErrFilterFieldDenied     = apperror.Forbidden("filter_field_denied", "filtering by this field is not allowed")
+	ErrOutOfGroupScope       = groupUseCase.ErrOutOfGroupScope // Контакт или группа вне групп модератора
+	ErrInvalidExpiry         = apperror.Invalid("invalid_membership_expiry", "membership expiry must be in the future")
+	ErrContactNotMember      = apperror.Invalid("contact_not_member", "contact is not a member of the group")
+	ErrSearchQueryEmpty      = apperror.Invalid("search_query_empty", "search query cannot be empty")
+	ErrSearchQueryTooLong    = apperror.Invalid("search_query_too_long", "search query has too many words")
 )
 
 // CreateContactData определяет данные для создания нового контакта.
@@ -39,6 +61,12 @@ type CreateContactData struct {
 	Telegram   string
 	TelegramID *int64 // ID пользователя в Telegram
 	GroupIDs   []uint // ID групп, к которым нужно добавить контакт
+	CreatedBy  *uint  // Пользователь, создающий контакт
+	Location   locationUseCase.Location
+	Department string
+	Position   string
+	// Draft создает черновик со статусом draft: email необязателен, профиль дополняется позже в портале
+	Draft bool
 }
 
 // UpdateContactData определяет данные для обновления существующего контакта.
@@ -53,30 +81,348 @@ type UpdateContactData struct {
 	Telegram   *string
 	TelegramID *int64  // ID пользователя в Telegram
 	GroupIDs   *[]uint // Список ID групп для полной замены существующих связей
+	UpdatedBy  *uint   // Пользователь, выполняющий изменение
+	// Scope - группы модератора: контакт должен состоять хотя бы в одной из них,
+	// а состав групп меняется только в их пределах. nil - без ограничений.
+	Scope    domain.GroupScope
+	City     *string
+	Campus   *string
+	Building *string
+	Room     *string
+	// Department и Position - отдел и должность; пустая строка очищает поле
+	Department *string
+	Position   *string
+}
+
+// ContactFilter определяет условия отбора контактов в списке.
+type ContactFilter struct {
+	// Skills - названия навыков; в список попадают контакты, обладающие хотя бы одним из них
+	Skills []string
+	// Tags - названия меток; в список попадают контакты, у которых есть хотя бы одна из них
+	Tags []string
+	// GroupID - только участники группы
+	GroupID uint
+	// GroupIDs - участники хотя бы одной из групп
+	GroupIDs []uint
+	// IDs - только контакты с этими ID
+	IDs []uint
+	// IncludeArchived - включать архивные контакты, которые по умолчанию в список не попадают
+	IncludeArchived bool
+	// Status - только контакты с этим статусом
+	Status string
+	// Поля местоположения сравниваются на точное совпадение
+	City     string
+	Campus   string
+	Building string
+	Room     string
+	// Department - только контакты отдела, точное совпадение
+	Department string
+	// GroupBy - группировка списка в ответе: пусто или "department"
+	GroupBy string
+	// Expression - выражение параметра filter; условия объединяются с остальными полями через and
+	Expression filterexpr.Expr
+
+	// Query - строка поиска: подстрока имени, email, Telegram или VK либо телефон целиком
+	Query string
+	// QueryFields - поля из searchFields, в которых кроме имени ищется Query; nil - все.
+	// CheckFilterAccess оставляет в нем только поля, которые роль может читать.
+	QueryFields []string
+	// Email - подстрока email
+	Email string
+	// Phone - телефон целиком в любом написании: +7 999 000-11-22, 89990001122
+	Phone string
+	// HasTelegram - только контакты с привязанным Telegram (true) или без него (false)
+	HasTelegram *bool
+
+	// Sort - порядок списка; без него контакты идут в порядке добавления
+	Sort []SortField
+
+	// viewerRole - роль, для которой CheckFilterAccess проверил фильтр; из результатов убираются контакты,
+	// подошедшие под него только по полям, скрытым от нее уровнем видимости
+	viewerRole string
+}
+
+// hasLocation сообщает, отбирает ли фильтр по городу, кампусу, корпусу или аудитории
+func (f ContactFilter) hasLocation() bool {
+	return f.City != "" || f.Campus != "" || f.Building != "" || f.Room != ""
+}
+
+// SortField - поле сортировки из sortFields; Desc - по убыванию
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// sortFields - поля, по которым можно сортировать список. Они видны любой роли, поэтому порядок
+// не раскрывает значений, скрытых политикой доступа.
+var sortFields = []string{"id", "name", "created_at", "updated_at"}
+
+// GroupByDepartment - значение group_by, группирующее список контактов по отделам
+const GroupByDepartment = "department"
+
+// searchFields - поля контакта, в которых кроме имени ищется строка поиска; совпадают с полями правил "contact.<поле>"
+var searchFields = []string{"email", "telegram", "vk", "phone"}
+
+// filterField - поле выражения фильтра; policy - поле правил "contact.<поле>", открывающее его для роли,
+// пустое - поле без отдельного правила
+type filterField struct {
+	filterexpr.Field
+	policy string
+}
+
+// filterFields - поля, по которым можно фильтровать выражением. Зашифрованные телефон и аллергии недоступны.
+var filterFields = []filterField{
+	{filterexpr.Field{Name: "id", Type: filterexpr.Number}, ""},
+	{filterexpr.Field{Name: "name", Type: filterexpr.String}, ""},
+	{filterexpr.Field{Name: "status", Type: filterexpr.String}, ""},
+	{filterexpr.Field{Name: "email", Type: filterexpr.String}, "email"},
+	{filterexpr.Field{Name: "transport", Type: filterexpr.String}, "transport"},
+	{filterexpr.Field{Name: "printer", Type: filterexpr.String}, "printer"},
+	{filterexpr.Field{Name: "vk", Type: filterexpr.String}, "vk"},
+	{filterexpr.Field{Name: "telegram", Type: filterexpr.String}, "telegram"},
+	{filterexpr.Field{Name: "city", Type: filterexpr.String}, "location"},
+	{filterexpr.Field{Name: "campus", Type: filterexpr.String}, "location"},
+	{filterexpr.Field{Name: "building", Type: filterexpr.String}, "location"},
+	{filterexpr.Field{Name: "room", Type: filterexpr.String}, "location"},
+	{filterexpr.Field{Name: "department", Type: filterexpr.String}, "department"},
+	{filterexpr.Field{Name: "position", Type: filterexpr.String}, "position"},
+	{filterexpr.Field{Name: "created_at", Type: filterexpr.Time}, ""},
+	{filterexpr.Field{Name: "updated_at", Type: filterexpr.Time}, ""},
+	{filterexpr.Field{Name: "group.id", Type: filterexpr.Number}, "groups"},
+	{filterexpr.Field{Name: "group.name", Type: filterexpr.String}, "groups"},
+	{filterexpr.Field{Name: "skill.name", Type: filterexpr.String}, "skills"},
+	{filterexpr.Field{Name: "tag.name", Type: filterexpr.String}, "tags"},
+}
+
+// ParseContactFilter собирает ContactFilter из параметров вида query-строки: skills и tag (через запятую), group_id,
+// status, city, campus, building, room, department, include_archived, строка поиска q, email, phone, has_telegram, выражение filter,
+// порядок sort: поля через запятую, "-" перед полем - по убыванию (например, name,-created_at) и группировку group_by.
+func ParseContactFilter(get func(key string) string) (ContactFilter, error) {
+	filter := ContactFilter{
+		Status:     get("status"),
+		City:       get("city"),
+		Campus:     get("campus"),
+		Building:   get("building"),
+		Room:       get("room"),
+		Department: strings.TrimSpace(get("department")),
+		GroupBy:    get("group_by"),
+		Query:      strings.TrimSpace(get("q")),
+		Email:      strings.TrimSpace(get("email")),
+		Phone:      strings.TrimSpace(get("phone")),
+	}
+	if filter.GroupBy != "" && filter.GroupBy != GroupByDepartment {
+		return ContactFilter{}, fmt.Errorf("%w: cannot group by %q, allowed: %s", ErrInvalidFilter, filter.GroupBy, GroupByDepartment)
+	}
+	if filter.Phone != "" && NormalizePhone(filter.Phone) == "" {
+		return ContactFilter{}, fmt.Errorf("%w: phone must be a full phone number", ErrInvalidFilter)
+	}
+	if includeArchived := get("include_archived"); includeArchived != "" {
+		value, err := strconv.ParseBool(includeArchived)
+		if err != nil {
+			return ContactFilter{}, fmt.Errorf("%w: include_archived must be true or false", ErrInvalidFilter)
+		}
+		filter.IncludeArchived = value
+	}
+	if hasTelegram := get("has_telegram"); hasTelegram != "" {
+		value, err := strconv.ParseBool(hasTelegram)
+		if err != nil {
+			return ContactFilter{}, fmt.Errorf("%w: has_telegram must be true or false", ErrInvalidFilter)
+		}
+		filter.HasTelegram = &value
+	}
+	if skills := get("skills"); skills != "" {
+		filter.Skills = strings.Split(skills, ",")
+	}
+	if tags := get("tag"); tags != "" {
+		filter.Tags = strings.Split(tags, ",")
+	}
+	if groupID := get("group_id"); groupID != "" {
+		id, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			return ContactFilter{}, fmt.Errorf("%w: group_id must be a number", ErrInvalidFilter)
+		}
+		filter.GroupID = uint(id)
+	}
+	if sort := get("sort"); sort != "" {
+		for _, field := range strings.Split(sort, ",") {
+			field = strings.TrimSpace(field)
+			desc := strings.HasPrefix(field, "-")
+			field = strings.TrimPrefix(field, "-")
+			if !slices.Contains(sortFields, field) {
+				return ContactFilter{}, fmt.Errorf("%w: cannot sort by %q, allowed fields: %s", ErrInvalidFilter, field, strings.Join(sortFields, ", "))
+			}
+			filter.Sort = append(filter.Sort, SortField{Field: field, Desc: desc})
+		}
+	}
+	if expression := strings.TrimSpace(get("filter")); expression != "" {
+		fields := make([]filterexpr.Field, len(filterFields))
+		for i, f := range filterFields {
+			fields[i] = f.Field
+		}
+		expr, err := filterexpr.Parse(expression, fields)
+		if err != nil {
+			return ContactFilter{}, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+		filter.Expression = expr
+	}
+	return filter, nil
+}
+
+func (f ContactFilter) toRepository() contactRepo.ListFilter {
+	filter := contactRepo.ListFilter{
+		IDs:      f.IDs,
+		GroupID:  f.GroupID,
+		GroupIDs: f.GroupIDs,
+		Status:   f.Status,
+		City:     strings.TrimSpace(f.City),
+		Campus:   strings.TrimSpace(f.Campus),
+		Building: strings.TrimSpace(f.Building),
+		Room:     strings.TrimSpace(f.Room),
+
+		Department: strings.TrimSpace(f.Department),
+		Expression: f.Expression,
+
+		Email:       f.Email,
+		Phone:       NormalizePhone(f.Phone),
+		HasTelegram: f.HasTelegram,
+
+		IncludeArchived: f.IncludeArchived,
+	}
+	for _, field := range f.Sort {
+		filter.Sort = append(filter.Sort, contactRepo.SortField{Field: field.Field, Desc: field.Desc})
+	}
+	for _, name := range f.Skills {
+		if name = skillUseCase.NormalizeName(name); name != "" {
+			filter.SkillNames = append(filter.SkillNames, name)
+		}
+	}
+	for _, name := range f.Tags {
+		// Названия меток хранятся без пробелов по краям и повторов внутри (tag usecase NormalizeName)
+		if name = strings.Join(strings.Fields(name), " "); name != "" {
+			filter.TagNames = append(filter.TagNames, name)
+		}
+	}
+	if f.Query != "" {
+		fields := f.QueryFields
+		if fields == nil {
+			fields = searchFields
+		}
+		filter.Search = f.Query
+		for _, field := range fields {
+			if field == "phone" {
+				filter.SearchPhone = NormalizePhone(f.Query)
+			} else {
+				filter.SearchColumns = append(filter.SearchColumns, field)
+			}
+		}
+	}
+	return filter
+}
+
+// NormalizePhone приводит номер к виду +7XXXXXXXXXX, в котором телефоны хранятся, чтобы найти его по индексу.
+// Пустая строка - значение не похоже на полный российский номер.
+func NormalizePhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case !strings.ContainsRune("+-() ", r):
+			return ""
+		}
+	}
+	normalized := digits.String()
+	switch {
+	case len(normalized) == 11 && normalized[0] == '8':
+		normalized = "7" + normalized[1:]
+	case len(normalized) == 10 && normalized[0] == '9':
+		normalized = "7" + normalized
+	}
+	if len(normalized) != 11 || normalized[0] != '7' {
+		return ""
+	}
+	return "+" + normalized
 }
 
 // UseCase определяет интерфейс для бизнес-логики управления контактами.
 type UseCase interface {
 	CreateContact(ctx context.Context, data CreateContactData) (*domain.Contact, error)
 	GetContactByID(ctx context.Context, id uint) (*domain.Contact, error)
-	GetAllContacts(ctx context.Context) ([]domain.Contact, error)
+	GetAllContacts(ctx context.Context, filter ContactFilter) ([]domain.Contact, error)
+	// SearchContacts ищет контакты по словам запроса и возвращает не больше limit самых релевантных (0 - 20, не больше 100).
+	// Ищет в имени, а также в email, имени пользователя Telegram и аллергиях, если роль может их читать.
+	SearchContacts(ctx context.Context, role, query string, limit int) ([]domain.Contact, error)
+	// EachContact передает контакты, подходящие под фильтр, в fn пачками по batchSize; для выгрузок больших справочников
+	EachContact(ctx context.Context, filter ContactFilter, batchSize int, fn func(contacts []domain.Contact) error) error
 	UpdateContact(ctx context.Context, id uint, data UpdateContactData) (*domain.Contact, error)
-	DeleteContact(ctx context.Context, id uint) error
-	AddContactToGroup(ctx context.Context, contactID uint, groupID uint) error
-	RemoveContactFromGroup(ctx context.Context, contactID uint, groupID uint) error
+	ChangeContactStatus(ctx context.Context, id uint, status string) (*domain.Contact, error)
+	// SetArchived убирает контакт в архив или возвращает из него; статус жизненного цикла при этом сохраняется
+	SetArchived(ctx context.Context, id uint, archived bool) (*domain.Contact, error)
+	// SetFieldVisibility заменяет уровни видимости полей контакта (domain.VisibilityFields): admins - только
+	// администраторам, authenticated - авторизованным по правилам политики, public - всем, включая гостей
+	SetFieldVisibility(ctx context.Context, id uint, visibility map[string]string) (*domain.Contact, error)
+	// DeleteContact мягко удаляет контакт; actorID - пользователь, выполняющий удаление, для истории изменений
+	DeleteContact(ctx context.Context, id uint, actorID *uint) error
+	GetDeletedContacts(ctx context.Context, phone, email string) ([]domain.Contact, error)
+	RestoreContact(ctx context.Context, id uint) (*domain.Contact, error)
+	// AddContactToGroup и RemoveContactFromGroup меняют состав группы; scope ограничивает их группами модератора.
+	// AddContactToGroup также задает срок членства (nil - бессрочно), в том числе если контакт уже в группе.
+	// actorID - пользователь, выполняющий изменение, для истории членства.
+	AddContactToGroup(ctx context.Context, contactID uint, groupID uint, expiresAt *time.Time, scope domain.GroupScope, actorID *uint) error
+	RemoveContactFromGroup(ctx context.Context, contactID uint, groupID uint, scope domain.GroupScope, actorID *uint) error
+	// GetGroupHistory возвращает историю вступлений контакта в группы и выходов из них, начиная с последнего события
+	GetGroupHistory(ctx context.Context, contactID uint) ([]domain.GroupMembershipEvent, error)
+	// GetHistory возвращает историю изменений контакта, начиная с последней записи. Изменения полей, которые
+	// роль не может читать, из записей убираются, а записи без оставшихся изменений пропускаются.
+	GetHistory(ctx context.Context, role string, contactID uint) ([]domain.ContactHistory, error)
+	// CheckFilterAccess проверяет, что фильтр использует только поля, которые роль может читать: иначе
+	// по результатам фильтра можно было бы узнать значения скрытых полей. Строку поиска он не отклоняет,
+	// а ограничивает доступными полями.
+	CheckFilterAccess(ctx context.Context, role string, filter *ContactFilter) error
+	// FilterFieldsForRole обнуляет поля контактов, которые роль не может читать согласно политике доступа
+	// и уровням видимости, заданным самими контактами (SetFieldVisibility)
+	FilterFieldsForRole(ctx context.Context, role string, contacts []domain.Contact) error
+
+	GetRelations(ctx context.Context, contactID uint) ([]domain.ContactRelation, error)
+	AddRelation(ctx context.Context, contactID uint, data RelationData) (*domain.ContactRelation, error)
+	DeleteRelation(ctx context.Context, contactID, relationID uint) error
+
+	AddContactSkill(ctx context.Context, contactID, skillID uint) error
+	RemoveContactSkill(ctx context.Context, contactID, skillID uint) error
+
+	// AddFavorite и RemoveFavorite меняют личное избранное пользователя userID; повторное добавление
+	// или удаление не считается ошибкой
+	AddFavorite(ctx context.Context, userID, contactID uint) error
+	RemoveFavorite(ctx context.Context, userID, contactID uint) error
+	// GetFavorites возвращает избранные контакты пользователя, начиная с добавленного последним
+	GetFavorites(ctx context.Context, userID uint) ([]domain.Contact, error)
 }
 
+// filterableFields - поля контакта, доступ к которым задается правилами "contact.<поле>".
+// ID и имя доступны всем, кому разрешен просмотр списка.
+var filterableFields = []string{"phone", "email", "transport", "printer", "allergies", "vk", "telegram", "telegram_id", "groups", "skills", "relations", "location", "department", "position", "custom_fields", "tags"}
+
 type contactUseCase struct {
 	contactRepo contactRepo.Repository
 	groupRepo   groupRepo.Repository // Нужен для проверки существования групп
+	skillRepo   skillRepo.Repository
+	locRepo     locationRepo.Repository // Справочник допустимых значений местоположения
+	policy      policyUseCase.UseCase
+	events      eventbus.Publisher  // Создание, изменение и удаление контактов и смена их групп
+	tx          transaction.Manager // Контакт и его членство в группах изменяются атомарно
 	logger      *slog.Logger
 }
 
 // NewContactUseCase создает новый экземпляр contactUseCase.
-func NewContactUseCase(cr contactRepo.Repository, gr groupRepo.Repository, logger *slog.Logger) UseCase {
+func NewContactUseCase(cr contactRepo.Repository, gr groupRepo.Repository, sr skillRepo.Repository, lr locationRepo.Repository, pu policyUseCase.UseCase, events eventbus.Publisher, tx transaction.Manager, logger *slog.Logger) UseCase {
 	return &contactUseCase{
 		contactRepo: cr,
 		groupRepo:   gr,
+		skillRepo:   sr,
+		locRepo:     lr,
+		policy:      pu,
+		events:      events,
+		tx:          tx,
 		logger:      logger,
 	}
 }
@@ -85,6 +431,12 @@ func (uc *contactUseCase) CreateContact(ctx context.Context, data CreateContactD
 	data.Name = strings.TrimSpace(data.Name)
 	data.Phone = strings.TrimSpace(data.Phone)
 	data.Email = strings.TrimSpace(data.Email)
+	data.Location.City = strings.TrimSpace(data.Location.City)
+	data.Location.Campus = strings.TrimSpace(data.Location.Campus)
+	data.Location.Building = strings.TrimSpace(data.Location.Building)
+	data.Location.Room = strings.TrimSpace(data.Location.Room)
+	data.Department = strings.TrimSpace(data.Department)
+	data.Position = strings.TrimSpace(data.Position)
 
 	if data.Name == "" {
 		return nil, ErrContactNameEmpty
@@ -92,53 +444,42 @@ func (uc *contactUseCase) CreateContact(ctx context.Context, data CreateContactD
 	if data.Phone == "" {
 		return nil, ErrContactPhoneEmpty
 	}
-	if data.Email == "" {
+	if data.Email == "" && !data.Draft {
 		return nil, ErrContactEmailEmpty
 	}
-
-	// 1. Проверка и удаление "мягко удаленного" контакта с таким же телефоном
-	deletedContactByPhone, err := uc.contactRepo.GetByPhoneUnscoped(ctx, data.Phone)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		uc.logger.ErrorContext(ctx, "Error checking unscoped contact phone existence", slog.String("phone", data.Phone), slog.Any("error", err))
-		return nil, err // Ошибка при доступе к БД
-	}
-	if deletedContactByPhone != nil && deletedContactByPhone.DeletedAt.Valid {
-		uc.logger.InfoContext(ctx, "Found soft-deleted contact by phone, proceeding with hard delete", slog.String("phone", data.Phone), slog.Uint64("contactID", uint64(deletedContactByPhone.ID)))
-		if err := uc.contactRepo.HardDelete(ctx, deletedContactByPhone.ID); err != nil {
-			uc.logger.ErrorContext(ctx, "Error hard deleting contact by phone", slog.Uint64("contactID", uint64(deletedContactByPhone.ID)), slog.Any("error", err))
-			return nil, err // Ошибка при физическом удалении
-		}
-	}
-
-	// 2. Проверка и удаление "мягко удаленного" контакта с таким же email
-	// Проверяем, не был ли этот контакт уже удален на предыдущем шаге (если phone и email совпадали у удаленной записи)
-	deletedContactByEmail, err := uc.contactRepo.GetByEmailUnscoped(ctx, data.Email)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		uc.logger.ErrorContext(ctx, "Error checking unscoped contact email existence", slog.String("email", data.Email), slog.Any("error", err))
-		return nil, err // Ошибка при доступе к БД
-	}
-	if deletedContactByEmail != nil && deletedContactByEmail.DeletedAt.Valid {
-		// Если это тот же контакт, что и найденный по телефону и уже удаленный, ничего не делаем
-		if deletedContactByPhone == nil || deletedContactByEmail.ID != deletedContactByPhone.ID || !deletedContactByPhone.DeletedAt.Valid {
-			uc.logger.InfoContext(ctx, "Found soft-deleted contact by email, proceeding with hard delete", slog.String("email", data.Email), slog.Uint64("contactID", uint64(deletedContactByEmail.ID)))
-			if err := uc.contactRepo.HardDelete(ctx, deletedContactByEmail.ID); err != nil {
-				uc.logger.ErrorContext(ctx, "Error hard deleting contact by email", slog.Uint64("contactID", uint64(deletedContactByEmail.ID)), slog.Any("error", err))
-				return nil, err // Ошибка при физическом удалении
-			}
-		}
+	if err := locationUseCase.Validate(ctx, uc.locRepo, data.Location); err != nil {
+		return nil, err
 	}
 
-	// 3. Проверка уникальности Email среди АКТИВНЫХ контактов (как и было)
-	existingByEmail, err := uc.contactRepo.GetByEmail(ctx, data.Email) // Эта функция ищет только активные
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		uc.logger.ErrorContext(ctx, "Error checking active contact email existence", slog.String("email", data.Email), slog.Any("error", err))
+	var created *domain.Contact
+	err := uc.tx.Run(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = uc.createContact(ctx, data)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
-	if existingByEmail != nil {
-		return nil, ErrContactEmailExists
+	return created, nil
+}
+
+// createContact проверяет уникальность и группы и создает контакт; вызывается в транзакции CreateContact,
+// чтобы контакт не остался без групп, если запись членства не удалась
+func (uc *contactUseCase) createContact(ctx context.Context, data CreateContactData) (*domain.Contact, error) {
+	// 1. Проверка уникальности Email среди АКТИВНЫХ контактов.
+	// Мягко удаленные контакты не мешают: уникальные индексы частичные.
+	if data.Email != "" {
+		existingByEmail, err := uc.contactRepo.GetByEmail(ctx, data.Email) // Эта функция ищет только активные
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			uc.logger.ErrorContext(ctx, "Error checking active contact email existence", slog.String("email", data.Email), slog.Any("error", err))
+			return nil, err
+		}
+		if existingByEmail != nil {
+			return nil, ErrContactEmailExists
+		}
 	}
 
-	// 4. Проверка уникальности Phone среди АКТИВНЫХ контактов (как и было)
+	// 2. Проверка уникальности Phone среди АКТИВНЫХ контактов
 	existingByPhone, err := uc.contactRepo.GetByPhone(ctx, data.Phone) // Эта функция ищет только активные
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		uc.logger.ErrorContext(ctx, "Error checking active contact phone existence", slog.String("phone", data.Phone), slog.Any("error", err))
@@ -149,14 +490,25 @@ func (uc *contactUseCase) CreateContact(ctx context.Context, data CreateContactD
 	}
 
 	contact := &domain.Contact{
-		Name:      data.Name,
-		Phone:     data.Phone,
-		Email:     data.Email,
-		Transport: data.Transport,
-		Printer:   data.Printer,
-		Allergies: data.Allergies,
-		VK:        data.VK,
-		Telegram:  data.Telegram,
+		Name:       data.Name,
+		Status:     domain.ContactStatusActive,
+		UpdatedBy:  data.CreatedBy,
+		Phone:      data.Phone,
+		Email:      data.Email,
+		Transport:  data.Transport,
+		Printer:    data.Printer,
+		Allergies:  data.Allergies,
+		VK:         data.VK,
+		Telegram:   data.Telegram,
+		City:       data.Location.City,
+		Campus:     data.Location.Campus,
+		Building:   data.Location.Building,
+		Room:       data.Location.Room,
+		Department: data.Department,
+		Position:   data.Position,
+	}
+	if data.Draft {
+		contact.Status = domain.ContactStatusDraft
 	}
 
 	// Устанавливаем TelegramID если передан
@@ -184,22 +536,24 @@ func (uc *contactUseCase) CreateContact(ctx context.Context, data CreateContactD
 
 	createdContact, err := uc.contactRepo.Create(ctx, contact)
 	if err != nil {
-		// Здесь могут быть ошибки типа UNIQUE constraint failed, если логика выше не отработала
-		// или если есть уникальные ограничения на другие поля, которые мы не проверяли.
-		// Проверим еще раз на всякий случай, чтобы вернуть кастомную ошибку клиенту.
-		if strings.Contains(err.Error(), "UNIQUE constraint failed: contacts.phone") {
-			uc.logger.ErrorContext(ctx, "Final unique constraint failed for phone", slog.String("name", contact.Name), slog.Any("error", err))
-			return nil, ErrContactPhoneExists
-		}
-		if strings.Contains(err.Error(), "UNIQUE constraint failed: contacts.email") {
-			uc.logger.ErrorContext(ctx, "Final unique constraint failed for email", slog.String("name", contact.Name), slog.Any("error", err))
-			return nil, ErrContactEmailExists
+		// Проверки выше не защищают от конкурентных запросов: репозиторий сам
+		// переводит нарушение уникального индекса в ErrContactPhoneExists/ErrContactEmailExists.
+		if errors.Is(err, ErrContactPhoneExists) || errors.Is(err, ErrContactEmailExists) || errors.Is(err, ErrContactTelegramExists) {
+			uc.logger.WarnContext(ctx, "Unique constraint violated on contact create", slog.String("name", contact.Name), slog.Any("error", err))
+			return nil, err
 		}
 		uc.logger.ErrorContext(ctx, "Failed to create contact via repository", slog.String("name", contact.Name), slog.Any("error", err))
 		return nil, err
 	}
 
 	uc.logger.InfoContext(ctx, "Contact created successfully", slog.Uint64("id", uint64(createdContact.ID)))
+	// Подписчики обрабатывают события в той же транзакции, что и создание контакта
+	if err := uc.events.Publish(ctx, domain.ContactCreatedEvent{Contact: createdContact}); err != nil {
+		return nil, err
+	}
+	if err := uc.publishMembershipChanges(ctx, createdContact.ID, nil, groupIDsOf(createdContact.Groups), data.CreatedBy); err != nil {
+		return nil, err
+	}
 	return createdContact, nil
 }
 
@@ -216,16 +570,40 @@ func (uc *contactUseCase) GetContactByID(ctx context.Context, id uint) (*domain.
 	return contact, nil
 }
 
-func (uc *contactUseCase) GetAllContacts(ctx context.Context) ([]domain.Contact, error) {
-	contacts, err := uc.contactRepo.GetAll(ctx)
+func (uc *contactUseCase) EachContact(ctx context.Context, filter ContactFilter, batchSize int, fn func(contacts []domain.Contact) error) error {
+	return uc.contactRepo.Each(ctx, filter.toRepository(), batchSize, func(contacts []domain.Contact) error {
+		if contacts = dropHiddenMatches(filter, contacts); len(contacts) == 0 {
+			return nil
+		}
+		return fn(contacts)
+	})
+}
+
+func (uc *contactUseCase) GetAllContacts(ctx context.Context, filter ContactFilter) ([]domain.Contact, error) {
+	contacts, err := uc.contactRepo.GetAll(ctx, filter.toRepository())
 	if err != nil {
 		uc.logger.ErrorContext(ctx, "Error getting all contacts from repository", slog.Any("error", err))
 		return nil, err
 	}
-	return contacts, nil
+	return dropHiddenMatches(filter, contacts), nil
 }
 
 func (uc *contactUseCase) UpdateContact(ctx context.Context, id uint, data UpdateContactData) (*domain.Contact, error) {
+	var updated *domain.Contact
+	err := uc.tx.Run(ctx, func(ctx context.Context) error {
+		var err error
+		updated, err = uc.updateContact(ctx, id, data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// updateContact применяет изменения полей и заменяет группы контакта; вызывается в транзакции UpdateContact:
+// замена групп, активация черновика и повторное чтение контакта видят одно состояние
+func (uc *contactUseCase) updateContact(ctx context.Context, id uint, data UpdateContactData) (*domain.Contact, error) {
 	contactToUpdate, err := uc.contactRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -236,6 +614,18 @@ func (uc *contactUseCase) UpdateContact(ctx context.Context, id uint, data Updat
 		return nil, err
 	}
 
+	if !data.Scope.AllowsAny(contactToUpdate.Groups) {
+		uc.logger.WarnContext(ctx, "Contact update outside of moderated groups", slog.Uint64("id", uint64(id)))
+		return nil, ErrOutOfGroupScope
+	}
+	if data.GroupIDs != nil && !groupChangesAllowed(data.Scope, contactToUpdate.Groups, *data.GroupIDs) {
+		uc.logger.WarnContext(ctx, "Contact group change outside of moderated groups", slog.Uint64("id", uint64(id)))
+		return nil, ErrOutOfGroupScope
+	}
+
+	oldName, oldPhone, oldEmail := contactToUpdate.Name, contactToUpdate.Phone, contactToUpdate.Email
+	oldGroupIDs := groupIDsOf(contactToUpdate.Groups)
+
 	// Обновляем поля, если они переданы
 	changed := false
 	if data.Name != nil {
@@ -309,6 +699,23 @@ func (uc *contactUseCase) UpdateContact(ctx context.Context, id uint, data Updat
 	}
 	if data.TelegramID != nil && contactToUpdate.TelegramID != *data.TelegramID {
 		contactToUpdate.TelegramID = *data.TelegramID
+		contactToUpdate.AvatarCheckedAt = nil // Фото нового пользователя запрашивается при следующем запуске задачи аватаров
+		changed = true
+	}
+	for _, f := range []struct{ field, value *string }{
+		{&contactToUpdate.Department, data.Department},
+		{&contactToUpdate.Position, data.Position},
+	} {
+		if f.value != nil && *f.field != strings.TrimSpace(*f.value) {
+			*f.field = strings.TrimSpace(*f.value)
+			changed = true
+		}
+	}
+	if locationChanged(contactToUpdate, data) {
+		loc := locationUseCase.Location{City: contactToUpdate.City, Campus: contactToUpdate.Campus, Building: contactToUpdate.Building, Room: contactToUpdate.Room}
+		if err := locationUseCase.Validate(ctx, uc.locRepo, loc); err != nil {
+			return nil, err
+		}
 		changed = true
 	}
 
@@ -336,18 +743,48 @@ func (uc *contactUseCase) UpdateContact(ctx context.Context, id uint, data Updat
 		return contactToUpdate, nil
 	}
 
+	contactToUpdate.UpdatedBy = data.UpdatedBy
 	if err := uc.contactRepo.Update(ctx, contactToUpdate); err != nil {
+		if errors.Is(err, ErrContactPhoneExists) || errors.Is(err, ErrContactEmailExists) {
+			uc.logger.WarnContext(ctx, "Unique constraint violated on contact update", slog.Uint64("id", uint64(id)), slog.Any("error", err))
+			return nil, err
+		}
 		uc.logger.ErrorContext(ctx, "Failed to update contact via repository", slog.Uint64("id", uint64(id)), slog.Any("error", err))
 		return nil, err
 	}
 
 	uc.logger.InfoContext(ctx, "Contact updated successfully", slog.Uint64("id", uint64(id)))
+	// Черновик из бота становится обычным контактом, когда профиль дополнен email
+	if contactToUpdate.Status == domain.ContactStatusDraft && contactToUpdate.Email != "" {
+		if err := uc.contactRepo.UpdateStatus(ctx, id, domain.ContactStatusActive); err != nil {
+			return nil, err
+		}
+		uc.logger.InfoContext(ctx, "Draft contact activated", slog.Uint64("id", uint64(id)))
+	}
+	for _, change := range []domain.ContactChangedEvent{
+		{Field: "name", OldValue: oldName, NewValue: contactToUpdate.Name},
+		{Field: "phone", OldValue: oldPhone, NewValue: contactToUpdate.Phone},
+		{Field: "email", OldValue: oldEmail, NewValue: contactToUpdate.Email},
+	} {
+		if change.OldValue == change.NewValue {
+			continue
+		}
+		change.Contact = contactToUpdate
+		if err := uc.events.Publish(ctx, change); err != nil {
+			return nil, err
+		}
+	}
+	if data.GroupIDs != nil {
+		if err := uc.publishMembershipChanges(ctx, id, oldGroupIDs, groupIDsOf(contactToUpdate.Groups), data.UpdatedBy); err != nil {
+			return nil, err
+		}
+	}
 	// Возвращаем обновленный контакт со всеми ассоциациями
 	return uc.contactRepo.GetByID(ctx, id)
 }
 
-func (uc *contactUseCase) DeleteContact(ctx context.Context, id uint) error {
-	_, err := uc.contactRepo.GetByID(ctx, id) // Проверяем существование
+func (uc *contactUseCase) DeleteContact(ctx context.Context, id uint, actorID *uint) error {
+	contact, err := uc.contactRepo.GetByID(ctx, id) // Проверяем существование
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrContactNotFound
@@ -355,81 +792,384 @@ func (uc *contactUseCase) DeleteContact(ctx context.Context, id uint) error {
 		return err
 	}
 
-	if err := uc.contactRepo.Delete(ctx, id); err != nil {
-		uc.logger.ErrorContext(ctx, "Failed to delete contact via repository", slog.Uint64("id", uint64(id)), slog.Any("error", err))
-		return err
-	}
-	uc.logger.InfoContext(ctx, "Contact deleted successfully", slog.Uint64("id", uint64(id)))
-	return nil
+	return uc.tx.Run(ctx, func(ctx context.Context) error {
+		if err := uc.contactRepo.Delete(ctx, id, actorID); err != nil {
+			uc.logger.ErrorContext(ctx, "Failed to delete contact via repository", slog.Uint64("id", uint64(id)), slog.Any("error", err))
+			return err
+		}
+		uc.logger.InfoContext(ctx, "Contact deleted successfully", slog.Uint64("id", uint64(id)))
+		return uc.events.Publish(ctx, domain.ContactDeletedEvent{Contact: contact})
+	})
 }
 
-func (uc *contactUseCase) AddContactToGroup(ctx context.Context, contactID uint, groupID uint) error {
-	contact, err := uc.contactRepo.GetByID(ctx, contactID)
+// GetDeletedContacts возвращает мягко удаленные контакты с указанным телефоном или email,
+// чтобы администратор мог найти запись, конфликтующую с новыми данными, и восстановить ее.
+func (uc *contactUseCase) GetDeletedContacts(ctx context.Context, phone, email string) ([]domain.Contact, error) {
+	contacts, err := uc.contactRepo.GetDeletedByPhoneOrEmail(ctx, strings.TrimSpace(phone), strings.TrimSpace(email))
 	if err != nil {
+		uc.logger.ErrorContext(ctx, "Error getting deleted contacts from repository", slog.String("phone", phone), slog.String("email", email), slog.Any("error", err))
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// RestoreContact восстанавливает мягко удаленный контакт.
+// Если его телефон или email уже заняты активным контактом, возвращает ErrContactPhoneExists/ErrContactEmailExists.
+func (uc *contactUseCase) RestoreContact(ctx context.Context, id uint) (*domain.Contact, error) {
+	if err := uc.contactRepo.Restore(ctx, id); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrContactNotFound
+			return nil, ErrContactNotFound
+		}
+		if errors.Is(err, ErrContactPhoneExists) || errors.Is(err, ErrContactEmailExists) {
+			return nil, err
 		}
+		uc.logger.ErrorContext(ctx, "Failed to restore contact via repository", slog.Uint64("id", uint64(id)), slog.Any("error", err))
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Contact restored successfully", slog.Uint64("id", uint64(id)))
+	return uc.contactRepo.GetByID(ctx, id)
+}
+
+func (uc *contactUseCase) AddContactToGroup(ctx context.Context, contactID uint, groupID uint, expiresAt *time.Time, scope domain.GroupScope, actorID *uint) error {
+	if !scope.Allows(groupID) {
+		return ErrOutOfGroupScope
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return ErrInvalidExpiry
+	}
+	if err := uc.checkMembershipTarget(ctx, contactID, groupID); err != nil {
 		return err
 	}
 
-	group, err := uc.groupRepo.GetByID(ctx, groupID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return groupUseCase.ErrGroupNotFound
+	// Членство и срок записываются вместе: иначе сбой на сроке оставил бы бессрочное членство
+	return uc.tx.Run(ctx, func(ctx context.Context) error {
+		// Повторное добавление ничего не меняет, кроме срока членства
+		member, err := uc.groupRepo.IsMember(ctx, groupID, contactID)
+		if err != nil {
+			return err
 		}
+		if member {
+			uc.logger.InfoContext(ctx, "Contact already in group", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("groupID", uint64(groupID)))
+		} else {
+			if err := uc.groupRepo.AddMember(ctx, groupID, contactID, actorID, domain.MembershipSourceManual); err != nil {
+				return ErrGroupAssociation.Wrap(err)
+			}
+			uc.logger.InfoContext(ctx, "Contact added to group successfully", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("groupID", uint64(groupID)))
+			if err := uc.publishMembershipChanges(ctx, contactID, nil, []uint{groupID}, actorID); err != nil {
+				return err
+			}
+		}
+
+		if err := uc.groupRepo.SetMembershipExpiry(ctx, groupID, contactID, expiresAt); err != nil {
+			return ErrGroupAssociation.Wrap(err)
+		}
+		return nil
+	})
+}
+
+func (uc *contactUseCase) RemoveContactFromGroup(ctx context.Context, contactID uint, groupID uint, scope domain.GroupScope, actorID *uint) error {
+	if !scope.Allows(groupID) {
+		return ErrOutOfGroupScope
+	}
+	if err := uc.checkMembershipTarget(ctx, contactID, groupID); err != nil {
 		return err
 	}
 
-	// Проверим, не состоит ли контакт уже в этой группе (опционально, Append идемпотентен для связей)
-	for _, existingGroup := range contact.Groups {
-		if existingGroup.ID == group.ID {
-			uc.logger.InfoContext(ctx, "Contact already in group", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("groupID", uint64(groupID)))
-			return nil // Уже в группе
+	member, err := uc.groupRepo.IsMember(ctx, groupID, contactID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		uc.logger.WarnContext(ctx, "Contact not in group, cannot remove", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("groupID", uint64(groupID)))
+		return ErrContactNotMember
+	}
+
+	return uc.tx.Run(ctx, func(ctx context.Context) error {
+		if err := uc.groupRepo.RemoveMember(ctx, groupID, contactID, actorID, domain.MembershipSourceManual); err != nil {
+			return ErrGroupAssociation.Wrap(err)
 		}
+		uc.logger.InfoContext(ctx, "Contact removed from group successfully", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("groupID", uint64(groupID)))
+		return uc.publishMembershipChanges(ctx, contactID, []uint{groupID}, nil, actorID)
+	})
+}
+
+func (uc *contactUseCase) GetGroupHistory(ctx context.Context, contactID uint) ([]domain.GroupMembershipEvent, error) {
+	exists, err := uc.contactRepo.Exists(ctx, contactID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrContactNotFound
 	}
+	return uc.groupRepo.GetMembershipHistory(ctx, contactID)
+}
 
-	if err := uc.contactRepo.AddContactToGroup(ctx, contact, group); err != nil {
-		uc.logger.ErrorContext(ctx, "Failed to add contact to group via repository", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("groupID", uint64(groupID)), slog.Any("error", err))
-		return ErrGroupAssociation
+func (uc *contactUseCase) GetHistory(ctx context.Context, role string, contactID uint) ([]domain.ContactHistory, error) {
+	history, err := uc.contactRepo.GetHistory(ctx, contactID)
+	if err != nil {
+		return nil, err
+	}
+	// У удаленного контакта всегда есть запись об удалении, поэтому пустая история означает
+	// либо несуществующий контакт, либо контакт, который еще не меняли
+	if len(history) == 0 {
+		exists, err := uc.contactRepo.Exists(ctx, contactID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrContactNotFound
+		}
+		return history, nil
+	}
+
+	allowed, err := uc.policy.AllowedFields(ctx, role, policyUseCase.ContactFieldsPrefix, filterableFields)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to evaluate contact field policy", slog.String("role", role), slog.Any("error", err))
+		return nil, err
+	}
+	visible := history[:0]
+	for _, entry := range history {
+		changes := entry.ChangeList()
+		if len(changes) == 0 {
+			visible = append(visible, entry)
+			continue
+		}
+		changes = slices.DeleteFunc(changes, func(ch domain.FieldChange) bool {
+			return !historyFieldAllowed(ch.Field, allowed)
+		})
+		if len(changes) == 0 {
+			continue
+		}
+		if err := entry.SetChangeList(changes); err != nil {
+			return nil, err
+		}
+		visible = append(visible, entry)
+	}
+	return visible, nil
+}
+
+// historyFieldAllowed сообщает, может ли роль видеть изменение поля field из истории контакта.
+// Имя доступно всем, адрес проживания подчиняется правилу поля location.
+func historyFieldAllowed(field string, allowed map[string]bool) bool {
+	switch field {
+	case "name":
+		return true
+	case "city", "campus", "building", "room":
+		return allowed["location"]
+	default:
+		return allowed[field]
 	}
-	uc.logger.InfoContext(ctx, "Contact added to group successfully", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("groupID", uint64(groupID)))
-	return nil
 }
 
-func (uc *contactUseCase) RemoveContactFromGroup(ctx context.Context, contactID uint, groupID uint) error {
-	contact, err := uc.contactRepo.GetByID(ctx, contactID)
+// checkMembershipTarget проверяет, что контакт и группа существуют, не загружая их ассоциации
+func (uc *contactUseCase) checkMembershipTarget(ctx context.Context, contactID, groupID uint) error {
+	exists, err := uc.contactRepo.Exists(ctx, contactID)
 	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrContactNotFound
+	}
+	if _, err := uc.groupRepo.GetByID(ctx, groupID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrContactNotFound
+			return groupUseCase.ErrGroupNotFound
 		}
 		return err
 	}
+	return nil
+}
 
-	group, err := uc.groupRepo.GetByID(ctx, groupID)
+func (uc *contactUseCase) FilterFieldsForRole(ctx context.Context, role string, contacts []domain.Contact) error {
+	allowed, err := uc.policy.AllowedFields(ctx, role, policyUseCase.ContactFieldsPrefix, filterableFields)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return groupUseCase.ErrGroupNotFound
+		uc.logger.ErrorContext(ctx, "Failed to evaluate contact field policy", slog.String("role", role), slog.Any("error", err))
+		return err
+	}
+
+	for i := range contacts {
+		ct := &contacts[i]
+		visible := VisibleFields(ct, role, allowed)
+		if !visible["phone"] {
+			ct.Phone = ""
+		}
+		if !visible["email"] {
+			ct.Email = ""
+			ct.EmailVerified = false
+		}
+		if !visible["transport"] {
+			ct.Transport = ""
+		}
+		if !visible["printer"] {
+			ct.Printer = ""
+		}
+		if !visible["allergies"] {
+			ct.Allergies = ""
+		}
+		if !visible["vk"] {
+			ct.VK = ""
+		}
+		if !visible["telegram"] {
+			ct.Telegram = ""
+		}
+		if !visible["telegram_id"] {
+			ct.TelegramID = 0
+			ct.AvatarFileID = "" // Фото позволяет узнать человека в Telegram
+		}
+		if !visible["groups"] {
+			ct.Groups = nil
+		}
+		if !visible["skills"] {
+			ct.Skills = nil
+		}
+		if !visible["location"] {
+			ct.City, ct.Campus, ct.Building, ct.Room = "", "", "", ""
 		}
+		if !visible["department"] {
+			ct.Department = ""
+		}
+		if !visible["position"] {
+			ct.Position = ""
+		}
+		if !visible["relations"] {
+			ct.Relations = nil
+			ct.InverseRelations = nil
+		}
+		if !visible["custom_fields"] {
+			ct.FieldValues = nil
+		}
+		if !visible["tags"] {
+			ct.Tags = nil
+		}
+	}
+	return nil
+}
+
+func (uc *contactUseCase) CheckFilterAccess(ctx context.Context, role string, filter *ContactFilter) error {
+	if filter.Expression == nil && filter.Query == "" && filter.Email == "" && filter.Phone == "" && filter.HasTelegram == nil && len(filter.Tags) == 0 &&
+		len(filter.Skills) == 0 && filter.GroupID == 0 && !filter.hasLocation() && filter.Department == "" && filter.GroupBy == "" {
+		return nil
+	}
+	filter.viewerRole = role
+	allowed, err := uc.policy.AllowedFields(ctx, role, policyUseCase.ContactFieldsPrefix, filterableFields)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to evaluate contact field policy", slog.String("role", role), slog.Any("error", err))
 		return err
 	}
+	if role == domain.RoleGuest {
+		// Гостям, как и в списке контактов, доступны только ID и имя
+		allowed = map[string]bool{}
+	}
 
-	// Проверим, состоит ли контакт в этой группе
-	found := false
-	for _, existingGroup := range contact.Groups {
-		if existingGroup.ID == group.ID {
-			found = true
-			break
+	filter.QueryFields = []string{}
+	for _, field := range searchFields {
+		if allowed[field] {
+			filter.QueryFields = append(filter.QueryFields, field)
 		}
 	}
-	if !found {
-		uc.logger.WarnContext(ctx, "Contact not in group, cannot remove", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("groupID", uint64(groupID)))
-		return fmt.Errorf("contact is not a member of group %d", groupID) // Или можно просто nil вернуть, если не считать это ошибкой
+	for field, used := range map[string]bool{"email": filter.Email != "", "phone": filter.Phone != "", "telegram": filter.HasTelegram != nil, "tags": len(filter.Tags) > 0,
+		"skills": len(filter.Skills) > 0, "groups": filter.GroupID != 0, "location": filter.hasLocation(),
+		"department": filter.Department != "" || filter.GroupBy == GroupByDepartment} {
+		if used && !allowed[field] {
+			return fmt.Errorf("%w: %s", ErrFilterFieldDenied, field)
+		}
+	}
+	if filter.Expression == nil {
+		return nil
 	}
+	for _, name := range filterexpr.Fields(filter.Expression) {
+		for _, f := range filterFields {
+			if f.Name != name {
+				continue
+			}
+			// Гостям, как и в списке контактов, доступны только ID и имя
+			guestField := name == "id" || name == "name"
+			if role == domain.RoleGuest && !guestField || f.policy != "" && !allowed[f.policy] {
+				return fmt.Errorf("%w: %s", ErrFilterFieldDenied, name)
+			}
+		}
+	}
+	return nil
+}
 
-	if err := uc.contactRepo.RemoveContactFromGroup(ctx, contact, group); err != nil {
-		uc.logger.ErrorContext(ctx, "Failed to remove contact from group via repository", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("groupID", uint64(groupID)), slog.Any("error", err))
-		return ErrGroupAssociation
+// locationChanged применяет переданные поля местоположения к контакту и сообщает, изменилось ли что-нибудь.
+func locationChanged(contact *domain.Contact, data UpdateContactData) bool {
+	changed := false
+	apply := func(field *string, value *string) {
+		if value != nil && *field != strings.TrimSpace(*value) {
+			*field = strings.TrimSpace(*value)
+			changed = true
+		}
+	}
+	apply(&contact.City, data.City)
+	apply(&contact.Campus, data.Campus)
+	apply(&contact.Building, data.Building)
+	apply(&contact.Room, data.Room)
+	return changed
+}
+
+// publishMembershipChanges публикует вступление в группы из newIDs, которых нет в oldIDs, и выход из групп oldIDs, которых нет в newIDs
+func (uc *contactUseCase) publishMembershipChanges(ctx context.Context, contactID uint, oldIDs, newIDs []uint, actorID *uint) error {
+	for _, change := range []struct {
+		eventType string
+		groupIDs  []uint
+	}{
+		{domain.MembershipJoined, subtractIDs(newIDs, oldIDs)},
+		{domain.MembershipLeft, subtractIDs(oldIDs, newIDs)},
+	} {
+		for _, groupID := range change.groupIDs {
+			event := domain.GroupMembershipChangedEvent{GroupID: groupID, ContactID: contactID, Type: change.eventType, Source: domain.MembershipSourceManual, ActorID: actorID}
+			if err := uc.events.Publish(ctx, event); err != nil {
+				return err
+			}
+		}
 	}
-	uc.logger.InfoContext(ctx, "Contact removed from group successfully", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("groupID", uint64(groupID)))
 	return nil
 }
+
+// groupIDsOf возвращает ID групп
+func groupIDsOf(groups []*domain.Group) []uint {
+	ids := make([]uint, len(groups))
+	for i, g := range groups {
+		ids[i] = g.ID
+	}
+	return ids
+}
+
+// subtractIDs возвращает ID из a, которых нет в b
+func subtractIDs(a, b []uint) []uint {
+	exclude := make(map[uint]bool, len(b))
+	for _, id := range b {
+		exclude[id] = true
+	}
+	var result []uint
+	for _, id := range a {
+		if !exclude[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// groupChangesAllowed проверяет, что замена групп контакта добавляет и удаляет только группы из scope.
+func groupChangesAllowed(scope domain.GroupScope, current []*domain.Group, newIDs []uint) bool {
+	if !scope.Restricted() {
+		return true
+	}
+	old := make(map[uint]bool, len(current))
+	for _, g := range current {
+		old[g.ID] = true
+	}
+	next := make(map[uint]bool, len(newIDs))
+	for _, id := range newIDs {
+		next[id] = true
+		if !old[id] && !scope.Allows(id) {
+			return false
+		}
+	}
+	for id := range old {
+		if !next[id] && !scope.Allows(id) {
+			return false
+		}
+	}
+	return true
+}
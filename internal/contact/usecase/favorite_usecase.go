@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+
+	"rim/internal/domain"
+)
+
+// AddFavorite добавляет контакт в избранное пользователя userID
+func (uc *contactUseCase) AddFavorite(ctx context.Context, userID, contactID uint) error {
+	exists, err := uc.contactRepo.Exists(ctx, contactID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrContactNotFound
+	}
+	if err := uc.contactRepo.AddFavorite(ctx, userID, contactID); err != nil {
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Contact added to favorites", slog.Uint64("userID", uint64(userID)), slog.Uint64("contactID", uint64(contactID)))
+	return nil
+}
+
+// RemoveFavorite убирает контакт из избранного. Контакт может быть уже удален: иначе его нельзя было бы убрать
+// из избранного до восстановления.
+func (uc *contactUseCase) RemoveFavorite(ctx context.Context, userID, contactID uint) error {
+	if err := uc.contactRepo.RemoveFavorite(ctx, userID, contactID); err != nil {
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Contact removed from favorites", slog.Uint64("userID", uint64(userID)), slog.Uint64("contactID", uint64(contactID)))
+	return nil
+}
+
+// GetFavorites возвращает избранные контакты пользователя со связями, как GetAllContacts, начиная с добавленного последним
+func (uc *contactUseCase) GetFavorites(ctx context.Context, userID uint) ([]domain.Contact, error) {
+	ids, err := uc.contactRepo.GetFavoriteIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		// Пустой список ID не ограничивает выборку GetAllContacts
+		return []domain.Contact{}, nil
+	}
+	// Архивный контакт остается в избранном: пользователь закрепил его сам
+	contacts, err := uc.GetAllContacts(ctx, ContactFilter{IDs: ids, IncludeArchived: true})
+	if err != nil {
+		return nil, err
+	}
+	position := make(map[uint]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+	slices.SortFunc(contacts, func(a, b domain.Contact) int {
+		return cmp.Compare(position[a.ID], position[b.ID])
+	})
+	return contacts, nil
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	contactRepo "rim/internal/contact/repository"
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRelationInvalidType = apperror.Invalid("invalid_relation_type", "invalid contact relation type")
+	ErrRelationSelf        = apperror.Invalid("relation_to_self", "contact cannot be related to itself")
+	ErrRelationExists      = contactRepo.ErrDuplicateRelation
+	ErrRelationNotFound    = apperror.NotFound("contact_relation_not_found", "contact relation not found")
+	ErrRelatedNotFound     = apperror.NotFound("related_contact_not_found", "related contact not found")
+)
+
+// RelationData определяет данные для создания связи: ToContactID является для контакта тем, что указано в Type.
+type RelationData struct {
+	ToContactID uint
+	Type        string
+}
+
+// IsValidRelationType проверяет, что тип связи поддерживается.
+func IsValidRelationType(relationType string) bool {
+	switch relationType {
+	case domain.RelationMentor, domain.RelationManager, domain.RelationEmergencyContact:
+		return true
+	}
+	return false
+}
+
+func (uc *contactUseCase) GetRelations(ctx context.Context, contactID uint) ([]domain.ContactRelation, error) {
+	if _, err := uc.GetContactByID(ctx, contactID); err != nil {
+		return nil, err
+	}
+	return uc.contactRepo.GetRelations(ctx, contactID)
+}
+
+func (uc *contactUseCase) AddRelation(ctx context.Context, contactID uint, data RelationData) (*domain.ContactRelation, error) {
+	if !IsValidRelationType(data.Type) {
+		return nil, ErrRelationInvalidType
+	}
+	if data.ToContactID == contactID {
+		return nil, ErrRelationSelf
+	}
+	from, err := uc.GetContactByID(ctx, contactID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := uc.contactRepo.GetByID(ctx, data.ToContactID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRelatedNotFound
+		}
+		return nil, err
+	}
+
+	relation := &domain.ContactRelation{
+		FromContactID: from.ID,
+		ToContactID:   to.ID,
+		Type:          data.Type,
+	}
+	if err := uc.contactRepo.CreateRelation(ctx, relation); err != nil {
+		return nil, err
+	}
+	relation.FromContact = from
+	relation.ToContact = to
+	uc.logger.InfoContext(ctx, "Contact relation created", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("relatedContactID", uint64(to.ID)), slog.String("type", data.Type))
+	return relation, nil
+}
+
+// DeleteRelation удаляет связь, в которой контакт участвует с любой стороны.
+func (uc *contactUseCase) DeleteRelation(ctx context.Context, contactID, relationID uint) error {
+	relation, err := uc.contactRepo.GetRelation(ctx, contactID, relationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRelationNotFound
+		}
+		return err
+	}
+	if err := uc.contactRepo.DeleteRelation(ctx, relation.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRelationNotFound
+		}
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Contact relation deleted", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("relationID", uint64(relationID)))
+	return nil
+}
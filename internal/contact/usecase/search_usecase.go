@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	contactRepo "rim/internal/contact/repository"
+	"rim/internal/domain"
+	policyUseCase "rim/internal/policy/usecase"
+	"rim/pkg/crypto"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	// maxSearchWords ограничивает число слов запроса: каждое добавляет к запросу условия по всем полям
+	maxSearchWords = 10
+	// searchCandidatesFactor - во сколько раз больше limit кандидатов загружается из БД для точного ранжирования:
+	// оценка в SQL приблизительна, а часть кандидатов отсеивается уровнями видимости полей
+	searchCandidatesFactor = 5
+)
+
+// Качество совпадения слова запроса с полем
+const (
+	matchNone     = 0
+	matchContains = 1 // Слово - подстрока поля
+	matchWord     = 2 // Со слова запроса начинается одно из слов поля
+	matchExact    = 3 // Поле целиком совпадает со словом
+)
+
+// searchField - поле полнотекстового поиска: имя правила "contact.<поле>", вес и значение у контакта
+type searchField struct {
+	field  string
+	weight int
+	value  func(c *domain.Contact) string
+}
+
+// searchRanking - поля полнотекстового поиска и их вес при ранжировании: совпадение в имени важнее совпадения
+// в контактах, а аллергии, которые ищутся только целыми словами, весят меньше всего. Имя доступно всем ролям,
+// остальные поля ищутся, только если роль может их читать (правила "contact.<поле>").
+var searchRanking = []searchField{
+	{"name", 4, func(c *domain.Contact) string { return c.Name }},
+	{"telegram", 3, func(c *domain.Contact) string { return strings.TrimPrefix(c.Telegram, "@") }},
+	{"email", 2, func(c *domain.Contact) string { return c.Email }},
+	{"allergies", 1, func(c *domain.Contact) string { return c.Allergies }},
+}
+
+// SearchContacts ищет контакты по словам запроса query в имени, email, имени пользователя Telegram и аллергиях
+// и возвращает не больше limit (0 - по умолчанию) самых релевантных. Каждое слово должно найтись хотя бы в одном поле.
+// Поля, которые роль не может читать, в поиске не участвуют. Из БД загружаются только лучшие по оценке в SQL
+// кандидаты (searchCandidatesFactor * limit), которые затем ранжируются точно.
+func (uc *contactUseCase) SearchContacts(ctx context.Context, role, query string, limit int) ([]domain.Contact, error) {
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return nil, ErrSearchQueryEmpty
+	}
+	if len(words) > maxSearchWords {
+		return nil, ErrSearchQueryTooLong
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	limit = min(limit, maxSearchLimit)
+
+	allowed, err := uc.policy.AllowedFields(ctx, role, policyUseCase.ContactFieldsPrefix, filterableFields)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to evaluate contact field policy", slog.String("role", role), slog.Any("error", err))
+		return nil, err
+	}
+	allowed["name"] = true
+	filter := contactRepo.SearchFilter{
+		Words:     words,
+		Allergies: allowed["allergies"],
+		Limit:     limit * searchCandidatesFactor,
+		Weights:   make(map[string]int, len(searchRanking)),
+	}
+	for _, column := range []string{"email", "telegram"} {
+		if allowed[column] {
+			filter.Columns = append(filter.Columns, column)
+		}
+	}
+	for _, f := range searchRanking {
+		filter.Weights[f.field] = f.weight
+	}
+
+	contacts, err := uc.contactRepo.Search(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Поля, скрытые контактом от роли уровнем видимости, не участвуют в оценке, а контакт, найденный
+	// только по ним, исключается из результатов
+	scores := make(map[uint]int, len(contacts))
+	found := contacts[:0]
+	for i := range contacts {
+		searchable := allowed
+		if hidden := hiddenFields(&contacts[i], role); len(hidden) > 0 {
+			searchable = maps.Clone(allowed)
+			for field := range hidden {
+				searchable[field] = false
+			}
+			if !matchesAll(&contacts[i], words, searchable) {
+				continue
+			}
+		}
+		scores[contacts[i].ID] = searchScore(&contacts[i], words, searchable)
+		found = append(found, contacts[i])
+	}
+	contacts = found
+	slices.SortStableFunc(contacts, func(a, b domain.Contact) int {
+		if c := cmp.Compare(scores[b.ID], scores[a.ID]); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	uc.logger.InfoContext(ctx, "Contacts searched", slog.Int("words", len(words)), slog.Int("found", len(contacts)))
+	if len(contacts) > limit {
+		contacts = contacts[:limit]
+	}
+	return contacts, nil
+}
+
+// searchScore оценивает релевантность контакта: сумма по словам запроса и доступным полям веса поля,
+// умноженного на качество совпадения
+func searchScore(contact *domain.Contact, words []string, allowed map[string]bool) int {
+	score := 0
+	for _, word := range words {
+		for _, f := range searchRanking {
+			if allowed[f.field] {
+				score += f.weight * matchQuality(f.field, f.value(contact), word)
+			}
+		}
+	}
+	return score
+}
+
+// matchesAll сообщает, что каждое слово запроса совпадает хотя бы с одним доступным полем контакта
+func matchesAll(contact *domain.Contact, words []string, allowed map[string]bool) bool {
+	for _, word := range words {
+		if !slices.ContainsFunc(searchRanking, func(f searchField) bool {
+			return allowed[f.field] && matchQuality(f.field, f.value(contact), word) > matchNone
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchQuality сравнивает слово запроса со значением поля без учета регистра и различия ё/е. Аллергии сравниваются
+// только целыми словами, как их находит поиск по индексу слов (crypto.WordIndex).
+func matchQuality(field, value, word string) int {
+	if field == "allergies" {
+		valueWords := crypto.Words(value)
+		queryWords := crypto.Words(word)
+		if len(queryWords) == 0 {
+			return matchNone
+		}
+		for _, w := range queryWords {
+			if !slices.Contains(valueWords, w) {
+				return matchNone
+			}
+		}
+		return matchWord
+	}
+
+	value = foldSearch(value)
+	word = foldSearch(word)
+	if field == "telegram" {
+		word = strings.TrimPrefix(word, "@")
+	}
+	switch {
+	case word == "" || value == "":
+		return matchNone
+	case value == word:
+		return matchExact
+	case slices.ContainsFunc(crypto.Words(value), func(w string) bool { return strings.HasPrefix(w, word) }):
+		return matchWord
+	case strings.Contains(value, word):
+		return matchContains
+	}
+	return matchNone
+}
+
+// foldSearch приводит строку к виду, в котором сравниваются значения при ранжировании
+func foldSearch(s string) string {
+	return strings.ReplaceAll(strings.ToLower(s), "ё", "е")
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	skillUseCase "rim/internal/skill/usecase"
+
+	"gorm.io/gorm"
+)
+
+func (uc *contactUseCase) AddContactSkill(ctx context.Context, contactID, skillID uint) error {
+	contact, err := uc.GetContactByID(ctx, contactID)
+	if err != nil {
+		return err
+	}
+	skill, err := uc.skillRepo.GetByID(ctx, skillID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return skillUseCase.ErrSkillNotFound
+		}
+		return err
+	}
+	for _, existing := range contact.Skills {
+		if existing.ID == skill.ID {
+			return nil
+		}
+	}
+	if err := uc.contactRepo.AddSkill(ctx, contact, skill); err != nil {
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Skill added to contact", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("skillID", uint64(skillID)))
+	return nil
+}
+
+func (uc *contactUseCase) RemoveContactSkill(ctx context.Context, contactID, skillID uint) error {
+	contact, err := uc.GetContactByID(ctx, contactID)
+	if err != nil {
+		return err
+	}
+	skill, err := uc.skillRepo.GetByID(ctx, skillID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return skillUseCase.ErrSkillNotFound
+		}
+		return err
+	}
+	if err := uc.contactRepo.RemoveSkill(ctx, contact, skill); err != nil {
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Skill removed from contact", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("skillID", uint64(skillID)))
+	return nil
+}
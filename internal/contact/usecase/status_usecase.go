@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"rim/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// statusTransitions перечисляет допустимые переходы между статусами контакта.
+// Выпускник может вернуться к работе, но не уйти в академ.
+var statusTransitions = map[string][]string{
+	domain.ContactStatusActive:  {domain.ContactStatusOnLeave, domain.ContactStatusAlumni},
+	domain.ContactStatusOnLeave: {domain.ContactStatusActive, domain.ContactStatusAlumni},
+	domain.ContactStatusAlumni:  {domain.ContactStatusActive},
+}
+
+// IsValidStatus проверяет, что статус контакта поддерживается.
+func IsValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+func (uc *contactUseCase) ChangeContactStatus(ctx context.Context, id uint, status string) (*domain.Contact, error) {
+	if !IsValidStatus(status) {
+		return nil, ErrInvalidStatus
+	}
+	contact, err := uc.GetContactByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	current := contact.Status
+	if current == "" {
+		current = domain.ContactStatusActive
+	}
+	if current == status {
+		return contact, nil
+	}
+	if !canTransition(current, status) {
+		uc.logger.WarnContext(ctx, "Contact status transition rejected", slog.Uint64("id", uint64(id)), slog.String("from", current), slog.String("to", status))
+		return nil, ErrStatusTransition
+	}
+
+	if err := uc.contactRepo.UpdateStatus(ctx, id, status); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContactNotFound
+		}
+		return nil, err
+	}
+	contact.Status = status
+	uc.logger.InfoContext(ctx, "Contact status changed", slog.Uint64("id", uint64(id)), slog.String("from", current), slog.String("to", status))
+	return contact, nil
+}
+
+func (uc *contactUseCase) SetArchived(ctx context.Context, id uint, archived bool) (*domain.Contact, error) {
+	contact, err := uc.GetContactByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if contact.IsArchived() == archived {
+		return contact, nil
+	}
+
+	var archivedAt *time.Time
+	if archived {
+		now := time.Now()
+		archivedAt = &now
+	}
+	if err := uc.contactRepo.SetArchived(ctx, id, archivedAt); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContactNotFound
+		}
+		return nil, err
+	}
+	contact.ArchivedAt = archivedAt
+	uc.logger.InfoContext(ctx, "Contact archive state changed", slog.Uint64("id", uint64(id)), slog.Bool("archived", archived))
+	return contact, nil
+}
+
+func canTransition(from, to string) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/filterexpr"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidVisibility = apperror.Invalid("invalid_field_visibility", "invalid field visibility")
+
+// SetFieldVisibility заменяет уровни видимости полей контакта. Пустой уровень возвращает поле к правилам политики.
+func (uc *contactUseCase) SetFieldVisibility(ctx context.Context, id uint, visibility map[string]string) (*domain.Contact, error) {
+	levels := make(map[string]string, len(visibility))
+	for field, level := range visibility {
+		if !slices.Contains(domain.VisibilityFields, field) {
+			return nil, fmt.Errorf("%w: unknown field %q, allowed fields: %s", ErrInvalidVisibility, field, strings.Join(domain.VisibilityFields, ", "))
+		}
+		switch level {
+		case "":
+		case domain.VisibilityAdmins, domain.VisibilityAuthenticated, domain.VisibilityPublic:
+			levels[field] = level
+		default:
+			return nil, fmt.Errorf("%w: unknown level %q for field %s", ErrInvalidVisibility, level, field)
+		}
+	}
+
+	var contact domain.Contact
+	if err := contact.SetVisibility(levels); err != nil {
+		return nil, err
+	}
+	if err := uc.contactRepo.UpdateFieldVisibility(ctx, id, contact.FieldVisibility); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContactNotFound
+		}
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Contact field visibility updated", slog.Uint64("id", uint64(id)), slog.Int("fields", len(levels)))
+	return uc.GetContactByID(ctx, id)
+}
+
+// VisibleFields возвращает поля, которые роль видит у контакта: правила политики allowed
+// с поправкой на уровни видимости, заданные контактом
+func VisibleFields(contact *domain.Contact, role string, allowed map[string]bool) map[string]bool {
+	visibility := contact.Visibility()
+	if len(visibility) == 0 {
+		return allowed
+	}
+	visible := maps.Clone(allowed)
+	for field, level := range visibility {
+		fields := []string{field}
+		if field == "telegram" {
+			// ID в Telegram указывает на ту же учетную запись, что и имя пользователя
+			fields = append(fields, "telegram_id")
+		}
+		for _, f := range fields {
+			if level == domain.VisibilityPublic {
+				visible[f] = true
+			} else if hiddenByLevel(level, role) {
+				visible[f] = false
+			}
+		}
+	}
+	return visible
+}
+
+// hiddenFields возвращает поля, которые контакт скрыл от роли уровнем видимости, независимо от правил политики
+func hiddenFields(contact *domain.Contact, role string) map[string]bool {
+	hidden := map[string]bool{}
+	for field, level := range contact.Visibility() {
+		if hiddenByLevel(level, role) {
+			hidden[field] = true
+		}
+	}
+	return hidden
+}
+
+// hiddenByLevel сообщает, скрывает ли уровень видимости поле от роли
+func hiddenByLevel(level, role string) bool {
+	switch level {
+	case domain.VisibilityAdmins:
+		return role != domain.RoleAdmin
+	case domain.VisibilityAuthenticated:
+		return role == domain.RoleGuest
+	}
+	return false
+}
+
+// dropHiddenMatches убирает из результатов фильтра контакты, которые подошли под него только по полям, скрытым
+// от роли уровнем видимости: иначе по фильтру можно было бы узнать их значения. Роль фильтра задает CheckFilterAccess.
+func dropHiddenMatches(filter ContactFilter, contacts []domain.Contact) []domain.Contact {
+	if filter.viewerRole == "" || filter.viewerRole == domain.RoleAdmin {
+		return contacts
+	}
+	used := filterVisibilityFields(filter)
+	kept := contacts[:0]
+	for i := range contacts {
+		ct := &contacts[i]
+		hidden := hiddenFields(ct, filter.viewerRole)
+		if len(hidden) > 0 {
+			if slices.ContainsFunc(used, func(field string) bool { return hidden[field] }) {
+				continue
+			}
+			if filter.Query != "" && !queryMatchesVisible(ct, filter, hidden) {
+				continue
+			}
+		}
+		kept = append(kept, *ct)
+	}
+	return kept
+}
+
+// filterVisibilityFields возвращает поля из domain.VisibilityFields, по которым отбирает фильтр (кроме строки поиска)
+func filterVisibilityFields(filter ContactFilter) []string {
+	var used []string
+	for field, set := range map[string]bool{"email": filter.Email != "", "phone": filter.Phone != "", "telegram": filter.HasTelegram != nil, "location": filter.hasLocation()} {
+		if set {
+			used = append(used, field)
+		}
+	}
+	if filter.Expression != nil {
+		for _, name := range filterexpr.Fields(filter.Expression) {
+			for _, f := range filterFields {
+				if f.Name == name && slices.Contains(domain.VisibilityFields, f.policy) {
+					used = append(used, f.policy)
+				}
+			}
+		}
+	}
+	return used
+}
+
+// queryMatchesVisible повторяет сравнение строки поиска (подстрока имени, email, Telegram или VK либо телефон
+// целиком) только по полям контакта, не скрытым от роли
+func queryMatchesVisible(contact *domain.Contact, filter ContactFilter, hidden map[string]bool) bool {
+	query := foldSearch(filter.Query)
+	if strings.Contains(foldSearch(contact.Name), query) {
+		return true
+	}
+	fields := filter.QueryFields
+	if fields == nil {
+		fields = searchFields
+	}
+	for _, field := range fields {
+		if hidden[field] {
+			continue
+		}
+		switch field {
+		case "phone":
+			if phone := NormalizePhone(filter.Query); phone != "" && phone == contact.Phone {
+				return true
+			}
+		case "email":
+			if strings.Contains(foldSearch(contact.Email), query) {
+				return true
+			}
+		case "telegram":
+			if strings.Contains(foldSearch(contact.Telegram), query) {
+				return true
+			}
+		case "vk":
+			if strings.Contains(foldSearch(contact.VK), query) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"rim/internal/domain"
+	policyUseCase "rim/internal/policy/usecase"
+)
+
+// fakePolicy разрешает роли чтение полей контакта из fields
+type fakePolicy struct {
+	policyUseCase.UseCase
+	fields map[string]bool
+}
+
+func (p *fakePolicy) AllowedFields(_ context.Context, _, _ string, fields []string) (map[string]bool, error) {
+	allowed := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		allowed[field] = p.fields[field]
+	}
+	return allowed, nil
+}
+
+func TestCheckFilterAccessDeniesHiddenFields(t *testing.T) {
+	uc := &contactUseCase{
+		policy: &fakePolicy{fields: map[string]bool{"email": true}},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	tests := []struct {
+		name   string
+		filter ContactFilter
+		denied bool
+	}{
+		{name: "city", filter: ContactFilter{City: "Москва"}, denied: true},
+		{name: "campus", filter: ContactFilter{Campus: "Север"}, denied: true},
+		{name: "building", filter: ContactFilter{Building: "1"}, denied: true},
+		{name: "room", filter: ContactFilter{Room: "101"}, denied: true},
+		{name: "skills", filter: ContactFilter{Skills: []string{"go"}}, denied: true},
+		{name: "group", filter: ContactFilter{GroupID: 3}, denied: true},
+		{name: "allowed field", filter: ContactFilter{Email: "anna@"}},
+		{name: "status only", filter: ContactFilter{Status: domain.ContactStatusActive}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := uc.CheckFilterAccess(context.Background(), domain.RoleUser, &tt.filter)
+			if denied := errors.Is(err, ErrFilterFieldDenied); denied != tt.denied {
+				t.Fatalf("error = %v, denied = %v, want %v", err, denied, tt.denied)
+			}
+		})
+	}
+}
+
+func TestDropHiddenMatchesByLocation(t *testing.T) {
+	hidden := domain.Contact{Name: "Анна", City: "Москва"}
+	if err := hidden.SetVisibility(map[string]string{"location": domain.VisibilityAdmins}); err != nil {
+		t.Fatal(err)
+	}
+	open := domain.Contact{Name: "Борис", City: "Москва"}
+
+	filter := ContactFilter{City: "Москва", viewerRole: domain.RoleUser}
+	got := dropHiddenMatches(filter, []domain.Contact{hidden, open})
+	if len(got) != 1 || got[0].Name != "Борис" {
+		t.Fatalf("contacts = %+v, want only the contact with a visible city", got)
+	}
+
+	filter.viewerRole = domain.RoleAdmin
+	if got := dropHiddenMatches(filter, []domain.Contact{hidden, open}); len(got) != 2 {
+		t.Fatalf("admin contacts = %d, want 2", len(got))
+	}
+}
@@ -0,0 +1,180 @@
+package delivery
+
+import (
+	"log/slog"
+	"strconv"
+
+	"rim/internal/contactfield/usecase"
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/pkg/apperror"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler отвечает за обработку HTTP-запросов дополнительных полей контактов.
+type Handler struct {
+	fieldUseCase usecase.UseCase
+	logger       *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler.
+func NewHandler(fieldUC usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		fieldUseCase: fieldUC,
+		logger:       logger,
+	}
+}
+
+// GetContactFields возвращает дополнительные поля контактов организации.
+// @Summary Получить дополнительные поля контактов
+// @Description Возвращает поля, заданные организацией, в порядке position. Значения полей выводятся в custom_fields контакта по ключу поля.
+// @Tags contact-fields
+// @Produce json
+// @Success 200 {array} ContactFieldResponse
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contact-fields [get]
+func (h *Handler) GetContactFields(c *fiber.Ctx) error {
+	fields, err := h.fieldUseCase.GetFields(c.Context())
+	if err != nil {
+		return h.fieldError(c, err)
+	}
+	resp := make([]ContactFieldResponse, len(fields))
+	for i := range fields {
+		resp[i] = toFieldResponse(&fields[i])
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// CreateContactField создает дополнительное поле контактов.
+// @Summary Создать дополнительное поле контактов
+// @Description Типы: text, number, date (ГГГГ-ММ-ДД) и select - одно из значений options. Ключ и тип после создания не меняются.
+// @Tags contact-fields
+// @Accept json
+// @Produce json
+// @Param field body ContactFieldRequest true "Поле"
+// @Success 201 {object} ContactFieldResponse
+// @Failure 400 {object} validation.Response "Ошибка валидации"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Поле с таким ключом уже существует"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contact-fields [post]
+func (h *Handler) CreateContactField(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[ContactFieldRequest](c)
+	if !ok {
+		return err
+	}
+
+	field, err := h.fieldUseCase.CreateField(c.Context(), usecase.FieldData{
+		Key:      req.Key,
+		Label:    req.Label,
+		Type:     req.Type,
+		Options:  req.Options,
+		Position: req.Position,
+	})
+	if err != nil {
+		return h.fieldError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(toFieldResponse(field))
+}
+
+// UpdateContactField меняет название, варианты и позицию дополнительного поля.
+// @Summary Изменить дополнительное поле контактов
+// @Tags contact-fields
+// @Accept json
+// @Produce json
+// @Param id path int true "ID поля"
+// @Param field body ContactFieldUpdateRequest true "Поле"
+// @Success 200 {object} ContactFieldResponse
+// @Failure 400 {object} validation.Response "Ошибка валидации"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Поле не найдено"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contact-fields/{id} [put]
+func (h *Handler) UpdateContactField(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact field ID format"})
+	}
+	req, ok, err := validation.BindAndValidate[ContactFieldUpdateRequest](c)
+	if !ok {
+		return err
+	}
+
+	field, err := h.fieldUseCase.UpdateField(c.Context(), uint(id), req.Label, req.Options, req.Position)
+	if err != nil {
+		return h.fieldError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(toFieldResponse(field))
+}
+
+// DeleteContactField удаляет дополнительное поле и его значения у всех контактов.
+// @Summary Удалить дополнительное поле контактов
+// @Tags contact-fields
+// @Param id path int true "ID поля"
+// @Success 204 "Поле удалено"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Поле не найдено"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contact-fields/{id} [delete]
+func (h *Handler) DeleteContactField(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact field ID format"})
+	}
+	if err := h.fieldUseCase.DeleteField(c.Context(), uint(id)); err != nil {
+		return h.fieldError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// SetContactFieldValues меняет значения дополнительных полей контакта.
+// @Summary Изменить значения дополнительных полей контакта
+// @Description Принимает значения по ключам полей; не переданные поля не меняются, пустая строка удаляет значение.
+// @Description Возвращает все значения контакта после изменения.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param id path int true "ID контакта"
+// @Param values body ContactFieldValuesRequest true "Значения по ключам полей"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} groupDelivery.ErrorResponse "Неизвестное поле или значение не подходит к типу поля"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/fields [put]
+func (h *Handler) SetContactFieldValues(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+	req, ok, err := validation.BindAndValidate[ContactFieldValuesRequest](c)
+	if !ok {
+		return err
+	}
+
+	values, err := h.fieldUseCase.SetValues(c.Context(), uint(id), req.Values)
+	if err != nil {
+		return h.fieldError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(values)
+}
+
+// fieldError преобразует ошибки usecase в HTTP-ответ по их виду (pkg/apperror)
+func (h *Handler) fieldError(c *fiber.Ctx, err error) error {
+	status := apperror.HTTPStatus(err)
+	if status == fiber.StatusInternalServerError {
+		h.logger.ErrorContext(c.Context(), "Contact field operation failed", slog.Any("error", err))
+		return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+}
+
+func toFieldResponse(field *domain.ContactFieldDefinition) ContactFieldResponse {
+	return ContactFieldResponse{
+		ID:       field.ID,
+		Key:      field.Key,
+		Label:    field.Label,
+		Type:     field.Type,
+		Options:  field.OptionList(),
+		Position: field.Position,
+	}
+}
@@ -0,0 +1,43 @@
+package delivery
+
+import (
+	"rim/internal/domain"
+	"rim/pkg/validation"
+)
+
+// Типы дополнительных полей для тега enum=contact_field_type
+func init() {
+	validation.RegisterEnum("contact_field_type", domain.ContactFieldText, domain.ContactFieldNumber, domain.ContactFieldDate, domain.ContactFieldSelect)
+}
+
+// ContactFieldRequest определяет структуру запроса на создание дополнительного поля.
+type ContactFieldRequest struct {
+	Key      string   `json:"key" validate:"required,max=50"` // Латиница в нижнем регистре, цифры и _; не меняется
+	Label    string   `json:"label" validate:"required,max=100"`
+	Type     string   `json:"type" validate:"required,enum=contact_field_type"` // Не меняется
+	Options  []string `json:"options,omitempty"`                                // Варианты поля select
+	Position int      `json:"position"`
+}
+
+// ContactFieldUpdateRequest определяет структуру запроса на изменение дополнительного поля.
+type ContactFieldUpdateRequest struct {
+	Label    string   `json:"label" validate:"required,max=100"`
+	Options  []string `json:"options,omitempty"`
+	Position int      `json:"position"`
+}
+
+// ContactFieldResponse определяет структуру дополнительного поля в ответе.
+type ContactFieldResponse struct {
+	ID       uint     `json:"id"`
+	Key      string   `json:"key"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Options  []string `json:"options,omitempty"`
+	Position int      `json:"position"`
+}
+
+// ContactFieldValuesRequest определяет значения дополнительных полей контакта по ключам.
+// Не переданные поля не меняются, пустая строка удаляет значение.
+type ContactFieldValuesRequest struct {
+	Values map[string]string `json:"values" validate:"required"`
+}
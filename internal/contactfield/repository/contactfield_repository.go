@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"rim/internal/domain"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrKeyTaken - в организации уже есть поле с таким ключом
+var ErrKeyTaken = errors.New("contact field key is already taken")
+
+// Repository определяет интерфейс хранения дополнительных полей контактов и их значений.
+type Repository interface {
+	Create(ctx context.Context, field *domain.ContactFieldDefinition) error
+	GetByID(ctx context.Context, id uint) (*domain.ContactFieldDefinition, error)
+	// GetAll возвращает поля организации в порядке Position
+	GetAll(ctx context.Context) ([]domain.ContactFieldDefinition, error)
+	// Update сохраняет название, варианты и позицию поля
+	Update(ctx context.Context, field *domain.ContactFieldDefinition) error
+	// Delete удаляет поле вместе с его значениями у всех контактов
+	Delete(ctx context.Context, id uint) error
+	// SetValues сохраняет значения полей контакта; поля из clear удаляются
+	SetValues(ctx context.Context, contactID uint, values []domain.ContactFieldValue, clear []uint) error
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр sqliteRepository для дополнительных полей контактов.
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *sqliteRepository) Create(ctx context.Context, field *domain.ContactFieldDefinition) error {
+	if orgID := tenant.FromContext(ctx); orgID != 0 && field.OrganizationID == 0 {
+		field.OrganizationID = orgID
+	}
+	if err := transaction.DB(ctx, r.db).Create(field).Error; err != nil {
+		// Единственный уникальный индекс таблицы - по организации и ключу (сообщения SQLite и Postgres)
+		if msg := strings.ToLower(err.Error()); strings.Contains(msg, "unique constraint") || strings.Contains(msg, "duplicate key") {
+			return ErrKeyTaken
+		}
+		r.logger.ErrorContext(ctx, "Error creating contact field in DB", slog.String("key", field.Key), slog.Any("error", err))
+		return err
+	}
+	r.logger.InfoContext(ctx, "Successfully created contact field in DB", slog.Uint64("fieldID", uint64(field.ID)), slog.String("key", field.Key))
+	return nil
+}
+
+func (r *sqliteRepository) GetByID(ctx context.Context, id uint) (*domain.ContactFieldDefinition, error) {
+	var field domain.ContactFieldDefinition
+	if err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "contact_field_definitions")).First(&field, id).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.ErrorContext(ctx, "Error getting contact field from DB", slog.Uint64("fieldID", uint64(id)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &field, nil
+}
+
+func (r *sqliteRepository) GetAll(ctx context.Context) ([]domain.ContactFieldDefinition, error) {
+	var fields []domain.ContactFieldDefinition
+	if err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "contact_field_definitions")).Order("position, id").Find(&fields).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting contact fields from DB", slog.Any("error", err))
+		return nil, err
+	}
+	return fields, nil
+}
+
+func (r *sqliteRepository) Update(ctx context.Context, field *domain.ContactFieldDefinition) error {
+	if err := transaction.DB(ctx, r.db).Model(field).Select("Label", "Options", "Position", "UpdatedAt").Updates(field).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error updating contact field in DB", slog.Uint64("fieldID", uint64(field.ID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) Delete(ctx context.Context, id uint) error {
+	return transaction.Run(ctx, r.db, func(ctx context.Context) error {
+		tx := transaction.DB(ctx, r.db)
+		if err := tx.Where("field_id = ?", id).Delete(&domain.ContactFieldValue{}).Error; err != nil {
+			r.logger.ErrorContext(ctx, "Error deleting contact field values from DB", slog.Uint64("fieldID", uint64(id)), slog.Any("error", err))
+			return err
+		}
+		result := tx.Scopes(tenant.Scope(ctx, "contact_field_definitions")).Delete(&domain.ContactFieldDefinition{}, id)
+		if result.Error != nil {
+			r.logger.ErrorContext(ctx, "Error deleting contact field from DB", slog.Uint64("fieldID", uint64(id)), slog.Any("error", result.Error))
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		r.logger.InfoContext(ctx, "Successfully deleted contact field from DB", slog.Uint64("fieldID", uint64(id)))
+		return nil
+	})
+}
+
+func (r *sqliteRepository) SetValues(ctx context.Context, contactID uint, values []domain.ContactFieldValue, clear []uint) error {
+	return transaction.Run(ctx, r.db, func(ctx context.Context) error {
+		tx := transaction.DB(ctx, r.db)
+		if len(clear) > 0 {
+			if err := tx.Where("contact_id = ? AND field_id IN ?", contactID, clear).Delete(&domain.ContactFieldValue{}).Error; err != nil {
+				r.logger.ErrorContext(ctx, "Error clearing contact field values in DB", slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+				return err
+			}
+		}
+		if len(values) > 0 {
+			err := tx.Omit("Field").Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "contact_id"}, {Name: "field_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+			}).Create(&values).Error
+			if err != nil {
+				r.logger.ErrorContext(ctx, "Error saving contact field values in DB", slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	contactRepo "rim/internal/contact/repository"
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/contactfield/repository"
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+
+	"gorm.io/gorm"
+)
+
+// maxValueLength - наибольшая длина значения текстового поля в символах
+const maxValueLength = 255
+
+var (
+	ErrFieldNotFound       = apperror.NotFound("contact_field_not_found", "contact field not found")
+	ErrFieldKeyExists      = apperror.Conflict("contact_field_key_exists", "contact field with this key already exists")
+	ErrFieldKeyInvalid     = apperror.Invalid("contact_field_key_invalid", "key must be 1-50 characters: lowercase latin letters, digits and underscores, starting with a letter")
+	ErrFieldLabelEmpty     = apperror.Invalid("contact_field_label_empty", "contact field label cannot be empty")
+	ErrFieldTypeInvalid    = apperror.Invalid("contact_field_type_invalid", "contact field type must be text, number, date or select")
+	ErrFieldOptionsInvalid = apperror.Invalid("contact_field_options_invalid", "select field needs distinct non-empty options, other types take none")
+	ErrUnknownField        = apperror.Invalid("contact_field_unknown", "unknown contact field")
+	ErrInvalidFieldValue   = apperror.Invalid("contact_field_value_invalid", "invalid contact field value")
+)
+
+// keyPattern - допустимый ключ поля; ключ становится именем свойства в custom_fields
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// fieldTypes - типы значений полей
+var fieldTypes = []string{domain.ContactFieldText, domain.ContactFieldNumber, domain.ContactFieldDate, domain.ContactFieldSelect}
+
+// FieldData - данные нового поля
+type FieldData struct {
+	Key      string
+	Label    string
+	Type     string
+	Options  []string
+	Position int
+}
+
+// UseCase определяет интерфейс управления дополнительными полями контактов.
+type UseCase interface {
+	GetFields(ctx context.Context) ([]domain.ContactFieldDefinition, error)
+	CreateField(ctx context.Context, data FieldData) (*domain.ContactFieldDefinition, error)
+	// UpdateField меняет название, варианты и позицию; ключ и тип поля не меняются.
+	// Значения контактов, которых нет среди новых вариантов поля select, сохраняются.
+	UpdateField(ctx context.Context, id uint, label string, options []string, position int) (*domain.ContactFieldDefinition, error)
+	// DeleteField удаляет поле и его значения у всех контактов
+	DeleteField(ctx context.Context, id uint) error
+	// SetValues меняет значения полей контакта по ключам; пустое значение удаляет его.
+	// Возвращает все значения контакта после изменения.
+	SetValues(ctx context.Context, contactID uint, values map[string]string) (map[string]string, error)
+}
+
+type contactFieldUseCase struct {
+	fieldRepo   repository.Repository
+	contactRepo contactRepo.Repository
+	logger      *slog.Logger
+}
+
+// NewContactFieldUseCase создает новый экземпляр contactFieldUseCase.
+func NewContactFieldUseCase(fieldRepo repository.Repository, cr contactRepo.Repository, logger *slog.Logger) UseCase {
+	return &contactFieldUseCase{
+		fieldRepo:   fieldRepo,
+		contactRepo: cr,
+		logger:      logger,
+	}
+}
+
+func (uc *contactFieldUseCase) GetFields(ctx context.Context) ([]domain.ContactFieldDefinition, error) {
+	return uc.fieldRepo.GetAll(ctx)
+}
+
+func (uc *contactFieldUseCase) CreateField(ctx context.Context, data FieldData) (*domain.ContactFieldDefinition, error) {
+	data.Key = strings.TrimSpace(data.Key)
+	data.Label = strings.TrimSpace(data.Label)
+	if !keyPattern.MatchString(data.Key) {
+		return nil, ErrFieldKeyInvalid
+	}
+	if data.Label == "" {
+		return nil, ErrFieldLabelEmpty
+	}
+	if !slices.Contains(fieldTypes, data.Type) {
+		return nil, ErrFieldTypeInvalid
+	}
+	field := &domain.ContactFieldDefinition{Key: data.Key, Label: data.Label, Type: data.Type, Position: data.Position}
+	if err := setOptions(field, data.Options); err != nil {
+		return nil, err
+	}
+
+	if err := uc.fieldRepo.Create(ctx, field); err != nil {
+		if errors.Is(err, repository.ErrKeyTaken) {
+			return nil, ErrFieldKeyExists
+		}
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Contact field created", slog.Uint64("id", uint64(field.ID)), slog.String("key", field.Key), slog.String("type", field.Type))
+	return field, nil
+}
+
+func (uc *contactFieldUseCase) UpdateField(ctx context.Context, id uint, label string, options []string, position int) (*domain.ContactFieldDefinition, error) {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return nil, ErrFieldLabelEmpty
+	}
+	field, err := uc.getField(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	field.Label = label
+	field.Position = position
+	if err := setOptions(field, options); err != nil {
+		return nil, err
+	}
+
+	if err := uc.fieldRepo.Update(ctx, field); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Contact field updated", slog.Uint64("id", uint64(id)), slog.String("key", field.Key))
+	return field, nil
+}
+
+func (uc *contactFieldUseCase) DeleteField(ctx context.Context, id uint) error {
+	if err := uc.fieldRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrFieldNotFound
+		}
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Contact field deleted", slog.Uint64("id", uint64(id)))
+	return nil
+}
+
+func (uc *contactFieldUseCase) SetValues(ctx context.Context, contactID uint, values map[string]string) (map[string]string, error) {
+	// GetByID ищет контакт в организации запроса: значения нельзя записать контакту другой организации
+	if _, err := uc.contactRepo.GetByID(ctx, contactID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, contactUseCase.ErrContactNotFound
+		}
+		return nil, err
+	}
+	fields, err := uc.fieldRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var set []domain.ContactFieldValue
+	var clear []uint
+	now := time.Now()
+	for key, value := range values {
+		i := slices.IndexFunc(fields, func(f domain.ContactFieldDefinition) bool { return f.Key == key })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownField, key)
+		}
+		field := &fields[i]
+		value = strings.TrimSpace(value)
+		if value == "" {
+			clear = append(clear, field.ID)
+			continue
+		}
+		if value, err = normalizeValue(field, value); err != nil {
+			return nil, err
+		}
+		set = append(set, domain.ContactFieldValue{ContactID: contactID, FieldID: field.ID, Value: value, UpdatedAt: now})
+	}
+	if err := uc.fieldRepo.SetValues(ctx, contactID, set, clear); err != nil {
+		return nil, err
+	}
+
+	uc.logger.InfoContext(ctx, "Contact field values updated", slog.Uint64("contactID", uint64(contactID)), slog.Int("set", len(set)), slog.Int("cleared", len(clear)))
+
+	contact, err := uc.contactRepo.GetByID(ctx, contactID)
+	if err != nil {
+		return nil, err
+	}
+	result := contact.CustomFields()
+	if result == nil {
+		result = map[string]string{}
+	}
+	return result, nil
+}
+
+func (uc *contactFieldUseCase) getField(ctx context.Context, id uint) (*domain.ContactFieldDefinition, error) {
+	field, err := uc.fieldRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFieldNotFound
+		}
+		return nil, err
+	}
+	return field, nil
+}
+
+// setOptions проверяет и сохраняет варианты поля: они обязательны для select и не допускаются у остальных типов
+func setOptions(field *domain.ContactFieldDefinition, options []string) error {
+	var cleaned []string
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" || slices.Contains(cleaned, option) {
+			return ErrFieldOptionsInvalid
+		}
+		cleaned = append(cleaned, option)
+	}
+	if (field.Type == domain.ContactFieldSelect) != (len(cleaned) > 0) {
+		return ErrFieldOptionsInvalid
+	}
+	return field.SetOptions(cleaned)
+}
+
+// normalizeValue проверяет значение по типу поля и приводит его к виду, в котором оно хранится
+func normalizeValue(field *domain.ContactFieldDefinition, value string) (string, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: %s: %s", ErrInvalidFieldValue, field.Key, reason)
+	}
+	switch field.Type {
+	case domain.ContactFieldNumber:
+		number, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+		if err != nil {
+			return "", invalid("must be a number")
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	case domain.ContactFieldDate:
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			return "", invalid("must be a date YYYY-MM-DD")
+		}
+	case domain.ContactFieldSelect:
+		if !slices.Contains(field.OptionList(), value) {
+			return "", invalid("must be one of " + strings.Join(field.OptionList(), ", "))
+		}
+	}
+	if utf8.RuneCountInString(value) > maxValueLength {
+		return "", invalid(fmt.Sprintf("must be at most %d characters", maxValueLength))
+	}
+	return value, nil
+}
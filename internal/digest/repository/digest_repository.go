@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/tenant"
+
+	"gorm.io/gorm"
+)
+
+// Changes - изменения контактов организации за период
+type Changes struct {
+	Created []domain.Contact
+	Updated []domain.Contact // Созданные до начала периода
+	Deleted []domain.Contact
+}
+
+// Empty проверяет, были ли изменения за период
+func (c *Changes) Empty() bool {
+	return len(c.Created) == 0 && len(c.Updated) == 0 && len(c.Deleted) == 0
+}
+
+// Repository определяет интерфейс для данных еженедельной сводки
+type Repository interface {
+	// GetRecipients возвращает активных пользователей с контактом, не отказавшихся от сводки
+	GetRecipients(ctx context.Context) ([]domain.User, error)
+	// GetChanges возвращает контакты, созданные, измененные и удаленные с момента since, в организации из ctx
+	GetChanges(ctx context.Context, since time.Time) (*Changes, error)
+	// MarkSent сохраняет время отправки сводки пользователю
+	MarkSent(ctx context.Context, userID uint, sentAt time.Time) error
+}
+
+type digestRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр репозитория сводки
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &digestRepository{db: db, logger: logger}
+}
+
+func (r *digestRepository) GetRecipients(ctx context.Context) ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.WithContext(ctx).Preload("Contact").
+		Where("type = ? AND is_active = ? AND weekly_digest = ? AND contact_id IS NOT NULL", domain.UserTypeHuman, true, true).
+		Find(&users).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to get digest recipients", slog.Any("error", err))
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *digestRepository) GetChanges(ctx context.Context, since time.Time) (*Changes, error) {
+	var changes Changes
+	// Телефоны и аллергии в сводку не попадают, поэтому расшифровываемые поля не загружаются
+	columns := []string{"id", "name", "created_at", "updated_at", "deleted_at"}
+	scoped := r.db.WithContext(ctx).Model(&domain.Contact{}).Select(columns).Scopes(tenant.Scope(ctx, "contacts")).Order("name")
+
+	if err := scoped.Session(&gorm.Session{}).Where("created_at >= ?", since).Find(&changes.Created).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Failed to get created contacts for digest", slog.Any("error", err))
+		return nil, err
+	}
+	if err := scoped.Session(&gorm.Session{}).Where("updated_at >= ? AND created_at < ?", since, since).Find(&changes.Updated).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Failed to get updated contacts for digest", slog.Any("error", err))
+		return nil, err
+	}
+	if err := scoped.Session(&gorm.Session{}).Unscoped().Where("deleted_at >= ? AND created_at < ?", since, since).Find(&changes.Deleted).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Failed to get deleted contacts for digest", slog.Any("error", err))
+		return nil, err
+	}
+	return &changes, nil
+}
+
+func (r *digestRepository) MarkSent(ctx context.Context, userID uint, sentAt time.Time) error {
+	err := r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).UpdateColumn("digest_sent_at", sentAt).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to mark digest as sent", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+	}
+	return err
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	authUseCase "rim/internal/auth/usecase"
+	digestRepo "rim/internal/digest/repository"
+	"rim/internal/domain"
+	notificationUseCase "rim/internal/notification/usecase"
+	policyUseCase "rim/internal/policy/usecase"
+	"rim/pkg/tenant"
+	"rim/pkg/timeutil"
+)
+
+// digestPeriod - за какой период собирается сводка, если прежняя не отправлялась
+const digestPeriod = 7 * 24 * time.Hour
+
+// minDigestGap - сводка не отправляется пользователю чаще, даже если сменился часовой пояс
+const minDigestGap = 6 * 24 * time.Hour
+
+// maxDigestNames - сколько имен перечисляется в разделе сводки, остальные только считаются
+const maxDigestNames = 20
+
+// Schedule - когда пользователи получают сводку: день недели и час в часовом поясе пользователя
+type Schedule struct {
+	Weekday time.Weekday
+	Hour    int
+}
+
+// UseCase определяет интерфейс еженедельной сводки изменений справочника.
+type UseCase interface {
+	// SendDue отправляет сводку пользователям, у которых по их часовому поясу наступило время отправки
+	SendDue(ctx context.Context, now time.Time) error
+	// Run вызывает SendDue каждые interval до отмены ctx
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type digestUseCase struct {
+	repo         digestRepo.Repository
+	authUseCase  authUseCase.UseCase
+	policy       policyUseCase.UseCase
+	notification notificationUseCase.UseCase
+	schedule     Schedule
+	logger       *slog.Logger
+}
+
+// NewDigestUseCase создает новый экземпляр digestUseCase.
+// Сводка отправляется через подсистему уведомлений в каналы, выбранные пользователем.
+func NewDigestUseCase(repo digestRepo.Repository, au authUseCase.UseCase, pu policyUseCase.UseCase, nu notificationUseCase.UseCase, schedule Schedule, logger *slog.Logger) UseCase {
+	return &digestUseCase{
+		repo:         repo,
+		authUseCase:  au,
+		policy:       pu,
+		notification: nu,
+		schedule:     schedule,
+		logger:       logger,
+	}
+}
+
+func (uc *digestUseCase) SendDue(ctx context.Context, now time.Time) error {
+	recipients, err := uc.repo.GetRecipients(tenant.Unscoped(ctx))
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	for i := range recipients {
+		user := &recipients[i]
+		if user.Contact == nil || !uc.due(user, now) {
+			continue
+		}
+		ok, err := uc.sendTo(ctx, user, now)
+		if err != nil {
+			uc.logger.ErrorContext(ctx, "Failed to send weekly digest", slog.Uint64("user_id", uint64(user.ID)), slog.Any("error", err))
+			continue
+		}
+		// Время отмечается и для пустой сводки, чтобы не проверять пользователя до следующей недели
+		if err := uc.repo.MarkSent(ctx, user.ID, now); err != nil {
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	if sent > 0 {
+		uc.logger.InfoContext(ctx, "Weekly digests sent", slog.Int("recipients", sent))
+	}
+	return nil
+}
+
+func (uc *digestUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = uc.SendDue(ctx, timeutil.Now())
+		}
+	}
+}
+
+// due проверяет, наступило ли время сводки в часовом поясе пользователя и не отправлялась ли она на этой неделе
+func (uc *digestUseCase) due(user *domain.User, now time.Time) bool {
+	local := now.In(timeutil.LocationOrUTC(user.Timezone))
+	if local.Weekday() != uc.schedule.Weekday || local.Hour() < uc.schedule.Hour {
+		return false
+	}
+	return user.DigestSentAt == nil || now.Sub(*user.DigestSentAt) >= minDigestGap
+}
+
+// sendTo собирает и отправляет сводку пользователю. false - изменений не было и сводка не отправлена.
+func (uc *digestUseCase) sendTo(ctx context.Context, user *domain.User, now time.Time) (bool, error) {
+	role := domain.RoleUser
+	isAdmin, err := uc.authUseCase.IsUserAdmin(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	if isAdmin {
+		role = domain.RoleAdmin
+	}
+	allowed, err := uc.policy.IsAllowed(ctx, role, policyUseCase.ResourceContacts, policyUseCase.ActionList)
+	if err != nil || !allowed {
+		return false, err
+	}
+
+	since := now.Add(-digestPeriod)
+	if user.DigestSentAt != nil && user.DigestSentAt.After(since) {
+		since = *user.DigestSentAt
+	}
+	// Сводка строится по организации контакта пользователя
+	changes, err := uc.repo.GetChanges(tenant.With(ctx, user.Contact.OrganizationID), since)
+	if err != nil {
+		return false, err
+	}
+	if changes.Empty() {
+		return false, nil
+	}
+
+	subject, text := compose(changes, since, timeutil.LocationOrUTC(user.Timezone))
+	uc.notification.NotifyContact(ctx, user.Contact, subject, text)
+	return true, nil
+}
+
+// compose формирует тему и текст сводки
+func compose(changes *digestRepo.Changes, since time.Time, loc *time.Location) (string, string) {
+	var sections []string
+	sections = append(sections, "Изменения справочника с "+since.In(loc).Format("02.01.2006")+".")
+	if s := section("Новые контакты", changes.Created); s != "" {
+		sections = append(sections, s)
+	}
+	if s := section("Изменены", changes.Updated); s != "" {
+		sections = append(sections, s)
+	}
+	if s := section("Удалены", changes.Deleted); s != "" {
+		sections = append(sections, s)
+	}
+	total := len(changes.Created) + len(changes.Updated) + len(changes.Deleted)
+	return fmt.Sprintf("RIM: сводка за неделю (%d)", total), strings.Join(sections, "\n\n")
+}
+
+func section(title string, contacts []domain.Contact) string {
+	if len(contacts) == 0 {
+		return ""
+	}
+	lines := []string{fmt.Sprintf("%s (%d):", title, len(contacts))}
+	for i, ct := range contacts {
+		if i == maxDigestNames {
+			lines = append(lines, fmt.Sprintf("...и еще %d", len(contacts)-maxDigestNames))
+			break
+		}
+		lines = append(lines, "• "+ct.Name)
+	}
+	return strings.Join(lines, "\n")
+}
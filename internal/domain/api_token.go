@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Области действия токенов доступа
+const (
+	ScopeContactsRead = "contacts:read"
+	ScopeGroupsRead   = "groups:read"
+	// ScopeDirectoryRead - публичный справочник /api/public/v1. Такой токен встраивается в публичный сайт
+	// и считается открытым, поэтому не сочетается с другими областями.
+	ScopeDirectoryRead = "directory:read"
+)
+
+// RoleDashboard - роль для запросов по токену доступа при фильтрации полей политикой
+const RoleDashboard = "dashboard"
+
+// RolePublic - роль публичного справочника при фильтрации полей политикой
+const RolePublic = "public"
+
+// APIToken представляет токен доступа только для чтения (например, для экрана-дашборда).
+// Хранится только хеш токена. Токен может быть ограничен одной группой и сроком действия.
+type APIToken struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Name       string     `json:"name" gorm:"not null"`
+	TokenHash  string     `json:"-" gorm:"not null;uniqueIndex"`
+	Scopes     string     `json:"scopes" gorm:"not null"` // Области через запятую, например "contacts:read,groups:read"
+	GroupID    *uint      `json:"group_id"`               // Если задан, доступны только контакты этой группы
+	CreatedBy  uint       `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"` // nil - бессрочный
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TableName возвращает имя таблицы для APIToken
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// ScopeList возвращает области действия токена
+func (t APIToken) ScopeList() []string {
+	var scopes []string
+	for _, scope := range strings.Split(t.Scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// HasScope проверяет, выдан ли токену доступ к области
+func (t APIToken) HasScope(scope string) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// Действия журнала аудита
+const (
+	AuditContactBlocked   = "contact.blocked"
+	AuditContactUnblocked = "contact.unblocked"
+	AuditLoginBlocked     = "auth.login_blocked" // Отклонена попытка входа заблокированного контакта
+	AuditUserLoggedIn     = "auth.login"
+	// Доступ к экстренным контактам (ICE)
+	AuditEmergencyViewed   = "emergency.viewed"
+	AuditEmergencyUpdated  = "emergency.updated"
+	AuditEmergencyExported = "emergency.exported" // Выгрузка экстренных контактов группы
+)
+
+// AuditEvent - запись журнала аудита о действии, связанном с безопасностью или доступом к чувствительным данным.
+// Записи не изменяются и не удаляются через API.
+type AuditEvent struct {
+	ID             uint      `gorm:"primarykey"`
+	CreatedAt      time.Time `gorm:"index"`
+	OrganizationID uint      `gorm:"not null;default:1;index"`
+	Action         string    `gorm:"not null;index"`
+	ActorID        *uint     `gorm:"index"` // Пользователь, выполнивший действие; nil - система или запрос без входа
+	ContactID      *uint     `gorm:"index"` // Контакт, к которому относится действие
+	TelegramID     int64     // Telegram ID, с которым выполнялся вход, 0 - не относится к входу через Telegram
+	IP             string    // Адрес клиента, если действие выполнено запросом
+	Details        string    // Дополнительные сведения, например причина блокировки
+}
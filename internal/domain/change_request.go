@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Статусы заявки на изменение контакта
+const (
+	ChangeRequestPending  = "pending"
+	ChangeRequestApproved = "approved"
+	ChangeRequestRejected = "rejected"
+)
+
+// Группы полей контакта, для которых можно включить модерацию самостоятельных правок
+const (
+	FieldGroupProfile   = "profile"   // имя
+	FieldGroupContacts  = "contacts"  // телефон, email, соцсети
+	FieldGroupLogistics = "logistics" // транспорт, принтер
+	FieldGroupHealth    = "health"    // аллергии
+)
+
+// ContactFieldGroups сопоставляет группе полей имена полей контакта (как в JSON API).
+var ContactFieldGroups = map[string][]string{
+	FieldGroupProfile:   {"name"},
+	FieldGroupContacts:  {"phone", "email", "vk", "telegram", "telegram_id"},
+	FieldGroupLogistics: {"transport", "printer"},
+	FieldGroupHealth:    {"allergies"},
+}
+
+// ContactFieldGroup возвращает группу, в которую входит поле контакта, или пустую строку.
+func ContactFieldGroup(field string) string {
+	for group, fields := range ContactFieldGroups {
+		for _, f := range fields {
+			if f == field {
+				return group
+			}
+		}
+	}
+	return ""
+}
+
+// ChangeRequest - заявка пользователя на изменение своего контакта, ожидающая решения администратора.
+// Changes и Previous хранят JSON-объекты "поле -> значение": новые значения и значения на момент подачи.
+type ChangeRequest struct {
+	ID            uint   `gorm:"primaryKey"`
+	ContactID     uint   `gorm:"not null;index"`
+	UserID        uint   `gorm:"not null"`
+	Changes       string `gorm:"not null"`
+	Previous      string `gorm:"not null"`
+	Status        string `gorm:"not null;default:pending;index"`
+	ReviewedBy    *uint
+	ReviewComment string
+	ReviewedAt    *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TableName возвращает имя таблицы для ChangeRequest
+func (ChangeRequest) TableName() string {
+	return "contact_change_requests"
+}
+
+// ChangeMap возвращает новые значения полей заявки.
+func (r ChangeRequest) ChangeMap() map[string]string {
+	return decodeFieldMap(r.Changes)
+}
+
+// PreviousMap возвращает значения полей на момент подачи заявки.
+func (r ChangeRequest) PreviousMap() map[string]string {
+	return decodeFieldMap(r.Previous)
+}
+
+// SetFields сохраняет новые и прежние значения полей заявки.
+func (r *ChangeRequest) SetFields(changes, previous map[string]string) error {
+	c, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	p, err := json.Marshal(previous)
+	if err != nil {
+		return err
+	}
+	r.Changes, r.Previous = string(c), string(p)
+	return nil
+}
+
+func decodeFieldMap(raw string) map[string]string {
+	fields := map[string]string{}
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &fields)
+	}
+	return fields
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Типы значений дополнительных полей контакта
+const (
+	ContactFieldText   = "text"
+	ContactFieldNumber = "number"
+	ContactFieldDate   = "date"   // ГГГГ-ММ-ДД
+	ContactFieldSelect = "select" // Одно из значений Options
+)
+
+// ContactFieldDefinition - дополнительное поле контактов организации ("размер одежды", "номер студенческого").
+// Значения хранятся в ContactFieldValue и выводятся в custom_fields контакта по ключу Key.
+type ContactFieldDefinition struct {
+	ID             uint   `gorm:"primaryKey"`
+	OrganizationID uint   `gorm:"not null;default:1;uniqueIndex:idx_contact_field_definitions_org_key"`
+	Key            string `gorm:"not null;uniqueIndex:idx_contact_field_definitions_org_key"` // Не меняется после создания
+	Label          string `gorm:"not null"`
+	Type           string `gorm:"not null;default:'text'"` // Не меняется после создания: значения уже проверены по типу
+	Options        string `gorm:"not null;default:''"`     // JSON-массив допустимых значений поля select
+	Position       int    `gorm:"not null;default:0"`      // Порядок вывода в форме контакта
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// OptionList возвращает допустимые значения поля select
+func (d *ContactFieldDefinition) OptionList() []string {
+	var options []string
+	if d.Options != "" {
+		_ = json.Unmarshal([]byte(d.Options), &options)
+	}
+	return options
+}
+
+// SetOptions сохраняет допустимые значения поля select
+func (d *ContactFieldDefinition) SetOptions(options []string) error {
+	if len(options) == 0 {
+		d.Options = ""
+		return nil
+	}
+	data, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	d.Options = string(data)
+	return nil
+}
+
+// ContactFieldValue - значение дополнительного поля у контакта. Пустые значения не хранятся.
+type ContactFieldValue struct {
+	ContactID uint   `gorm:"primaryKey"`
+	FieldID   uint   `gorm:"primaryKey;index"`
+	Value     string `gorm:"not null"`
+	UpdatedAt time.Time
+
+	Field *ContactFieldDefinition `gorm:"foreignKey:FieldID"`
+}
+
+// CustomFields возвращает значения дополнительных полей контакта по ключам полей.
+// FieldValues должны быть загружены вместе с Field.
+func (c *Contact) CustomFields() map[string]string {
+	if len(c.FieldValues) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(c.FieldValues))
+	for _, v := range c.FieldValues {
+		if v.Field != nil {
+			values[v.Field.Key] = v.Value
+		}
+	}
+	return values
+}
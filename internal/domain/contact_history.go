@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Действия истории изменений контакта
+const (
+	ContactHistoryUpdated = "updated"
+	ContactHistoryDeleted = "deleted"
+)
+
+// ContactHistory - запись истории изменений контакта: кто, когда и какие поля изменил.
+// Группы контакта в нее не входят: их история - GroupMembershipEvent.
+type ContactHistory struct {
+	ID             uint      `gorm:"primarykey"`
+	CreatedAt      time.Time `gorm:"index"`
+	OrganizationID uint      `gorm:"not null;default:1;index"`
+	ContactID      uint      `gorm:"not null;index"`
+	ActorID        *uint     `gorm:"index"` // Пользователь, выполнивший изменение; nil - система
+	Action         string    `gorm:"not null"`
+	// Changes - JSON-массив FieldChange. Шифруется (pkg/crypto), так как содержит прежние и новые телефоны и аллергии
+	Changes string `gorm:"not null;default:'';serializer:encrypted"`
+}
+
+// FieldChange - изменение поля контакта
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// ChangeList возвращает изменения полей записи
+func (h *ContactHistory) ChangeList() []FieldChange {
+	var changes []FieldChange
+	if h.Changes != "" {
+		_ = json.Unmarshal([]byte(h.Changes), &changes)
+	}
+	return changes
+}
+
+// SetChangeList сохраняет изменения полей в записи; пустой список - изменений нет (например, при удалении)
+func (h *ContactHistory) SetChangeList(changes []FieldChange) error {
+	if len(changes) == 0 {
+		h.Changes = ""
+		return nil
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	h.Changes = string(data)
+	return nil
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// EmailVerification - ссылка подтверждения email, отправленная владельцу контакта. У контакта действует
+// одна ссылка: новая заменяет прежнюю. Ссылка подтверждает только адрес Email, на который была отправлена.
+type EmailVerification struct {
+	ContactID uint   `gorm:"primaryKey;autoIncrement:false"`
+	Email     string `gorm:"not null"`
+	TokenHash string `gorm:"not null;uniqueIndex"` // SHA-256 токена из ссылки; сам токен не хранится
+	CreatedAt time.Time
+	ExpiresAt time.Time `gorm:"index"`
+}
@@ -0,0 +1,49 @@
+package domain
+
+// Доменные события, публикуемые UseCase через pkg/eventbus. События передаются подписчикам
+// в транзакции изменения, поэтому указатели на модели действительны только во время обработки.
+
+// ContactCreatedEvent - создан контакт
+type ContactCreatedEvent struct {
+	Contact *Contact
+}
+
+func (ContactCreatedEvent) EventName() string { return "contact.created" }
+
+// ContactChangedEvent - изменено имя, телефон или email контакта; по событию на каждое поле
+type ContactChangedEvent struct {
+	Contact  *Contact
+	Field    string // name, phone или email
+	OldValue string
+	NewValue string
+}
+
+func (ContactChangedEvent) EventName() string { return "contact.changed" }
+
+// ContactDeletedEvent - контакт мягко удален
+type ContactDeletedEvent struct {
+	Contact *Contact
+}
+
+func (ContactDeletedEvent) EventName() string { return "contact.deleted" }
+
+// GroupMembershipChangedEvent - контакт вступил в группу или вышел из нее
+type GroupMembershipChangedEvent struct {
+	GroupID   uint
+	ContactID uint
+	Type      string // MembershipJoined или MembershipLeft
+	Source    string // MembershipSource*
+	ActorID   *uint  // nil - изменение выполнено системой, например по истечении срока
+}
+
+func (GroupMembershipChangedEvent) EventName() string { return "group.membership_changed" }
+
+// UserLoggedInEvent - пользователь вошел и получил новую сессию
+type UserLoggedInEvent struct {
+	User      *User
+	DeviceID  uint
+	IP        string
+	UserAgent string
+}
+
+func (UserLoggedInEvent) EventName() string { return "user.logged_in" }
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ExportColumn - столбец шаблона выгрузки: ключ поля контакта и заголовок в файле
+type ExportColumn struct {
+	Key   string `json:"key"`
+	Title string `json:"title"`
+}
+
+// ExportTemplate - именованный набор столбцов для выгрузки контактов.
+// Columns хранит JSON-массив ExportColumn в порядке следования.
+type ExportTemplate struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"not null;uniqueIndex"`
+	Columns   string `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ColumnList возвращает столбцы шаблона.
+func (t ExportTemplate) ColumnList() []ExportColumn {
+	var columns []ExportColumn
+	if t.Columns != "" {
+		_ = json.Unmarshal([]byte(t.Columns), &columns)
+	}
+	return columns
+}
+
+// SetColumns сохраняет столбцы шаблона.
+func (t *ExportTemplate) SetColumns(columns []ExportColumn) error {
+	data, err := json.Marshal(columns)
+	if err != nil {
+		return err
+	}
+	t.Columns = string(data)
+	return nil
+}
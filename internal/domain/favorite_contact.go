@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// FavoriteContact - контакт, закрепленный пользователем в избранном. Избранное личное: другие пользователи
+// и администраторы его не видят, в отличие от групп оно не дает контакту ролей и доступа.
+type FavoriteContact struct {
+	UserID    uint `gorm:"primaryKey;autoIncrement:false"`
+	ContactID uint `gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt time.Time
+}
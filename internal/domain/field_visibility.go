@@ -0,0 +1,37 @@
+package domain
+
+import "encoding/json"
+
+// Уровни видимости поля контакта, которые задает сам контакт или администратор
+const (
+	VisibilityAdmins        = "admins"        // Только администраторам, даже если правила политики открывают поле другим ролям
+	VisibilityAuthenticated = "authenticated" // Авторизованным пользователям по правилам политики, но не гостям
+	VisibilityPublic        = "public"        // Всем, включая гостей, независимо от правил политики
+)
+
+// VisibilityFields - поля контакта, для которых задается видимость; совпадают с полями правил "contact.<поле>".
+// location - город, кампус, корпус и аудитория вместе; уровень telegram относится и к telegram_id.
+var VisibilityFields = []string{"phone", "email", "transport", "printer", "allergies", "vk", "telegram", "location"}
+
+// Visibility возвращает заданные уровни видимости полей; поля без уровня видны по правилам политики
+func (c *Contact) Visibility() map[string]string {
+	visibility := map[string]string{}
+	if c.FieldVisibility != "" {
+		_ = json.Unmarshal([]byte(c.FieldVisibility), &visibility)
+	}
+	return visibility
+}
+
+// SetVisibility сохраняет уровни видимости полей; пустой набор возвращает все поля к правилам политики
+func (c *Contact) SetVisibility(visibility map[string]string) error {
+	if len(visibility) == 0 {
+		c.FieldVisibility = ""
+		return nil
+	}
+	data, err := json.Marshal(visibility)
+	if err != nil {
+		return err
+	}
+	c.FieldVisibility = string(data)
+	return nil
+}
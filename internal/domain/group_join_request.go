@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// Статусы заявки на вступление в группу
+const (
+	JoinRequestPending  = "pending"
+	JoinRequestApproved = "approved"
+	JoinRequestRejected = "rejected"
+)
+
+// GroupJoinRequest - заявка пользователя на добавление его контакта в открытую группу.
+// На одну пару группа/контакт допускается одна нерассмотренная заявка.
+type GroupJoinRequest struct {
+	ID            uint `gorm:"primaryKey"`
+	GroupID       uint `gorm:"not null;index;uniqueIndex:idx_group_join_requests_pending,where:status = 'pending'"`
+	ContactID     uint `gorm:"not null;uniqueIndex:idx_group_join_requests_pending,where:status = 'pending'"`
+	UserID        uint `gorm:"not null;index"`
+	Message       string
+	Status        string `gorm:"not null;default:pending;index"`
+	ReviewedBy    *uint
+	ReviewComment string
+	ReviewedAt    *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	Group   *Group   `gorm:"foreignKey:GroupID"`
+	Contact *Contact `gorm:"foreignKey:ContactID"`
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// GroupModerator - пользователь, которому доверено управлять одной группой:
+// редактировать контакты ее участников и состав группы, рассматривать заявки на вступление.
+type GroupModerator struct {
+	ID        uint `gorm:"primaryKey"`
+	GroupID   uint `gorm:"not null;uniqueIndex:idx_group_moderators_group_user"`
+	UserID    uint `gorm:"not null;uniqueIndex:idx_group_moderators_group_user;index"`
+	CreatedBy *uint
+	CreatedAt time.Time
+
+	User *User `gorm:"foreignKey:UserID"`
+}
+
+// GroupScope - ID групп, которыми ограничены действия пользователя.
+// nil означает отсутствие ограничений: права выданы политикой доступа, а не модерацией групп.
+type GroupScope []uint
+
+// Restricted сообщает, ограничены ли действия группами.
+func (s GroupScope) Restricted() bool {
+	return s != nil
+}
+
+// Allows проверяет, входит ли группа в область действий.
+func (s GroupScope) Allows(groupID uint) bool {
+	return s == nil || slices.Contains(s, groupID)
+}
+
+// AllowsAny проверяет, входит ли в область действий хотя бы одна из групп.
+func (s GroupScope) AllowsAny(groups []*Group) bool {
+	if s == nil {
+		return true
+	}
+	for _, g := range groups {
+		if slices.Contains(s, g.ID) {
+			return true
+		}
+	}
+	return false
+}
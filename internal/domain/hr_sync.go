@@ -0,0 +1,74 @@
+package domain
+
+import "time"
+
+// Статусы запуска синхронизации с кадровой системой
+const (
+	HRSyncRunning   = "running"
+	HRSyncSucceeded = "succeeded" // Выполнен; ошибки отдельных записей перечислены в элементах отчета
+	HRSyncFailed    = "failed"    // Прерван: источник недоступен или вернул некорректный ответ
+)
+
+// Способы запуска синхронизации
+const (
+	HRSyncTriggerSchedule = "schedule"
+	HRSyncTriggerManual   = "manual"
+)
+
+// Действия в отчете о синхронизации
+const (
+	HRSyncActionCreated  = "created"  // Создан контакт по записи источника
+	HRSyncActionLinked   = "linked"   // Существующий контакт сопоставлен с записью по телефону или email
+	HRSyncActionUpdated  = "updated"  // Поля контакта обновлены из источника
+	HRSyncActionArchived = "archived" // Записи больше нет в источнике, контакт переведен в alumni
+	HRSyncActionRestored = "restored" // Запись вернулась в источник, контакт снова active
+	HRSyncActionPushed   = "pushed"   // Изменения контакта отправлены в источник
+	HRSyncActionFailed   = "failed"   // Запись не удалось обработать
+)
+
+// HRSyncLink связывает контакт с записью во внешней кадровой системе.
+// Хэш значений позволяет не перезаписывать изменения, сделанные в портале, пока запись в источнике не изменилась.
+type HRSyncLink struct {
+	ID             uint   `gorm:"primaryKey"`
+	OrganizationID uint   `gorm:"not null;default:1;uniqueIndex:idx_hr_sync_links_org_external"`
+	ExternalID     string `gorm:"not null;uniqueIndex:idx_hr_sync_links_org_external"`
+	ContactID      uint   `gorm:"not null;uniqueIndex"`
+	PulledHash     string `gorm:"not null;default:''"` // Хэш значений источника, примененных к контакту
+	// ArchivedAt - когда контакт переведен в alumni из-за отсутствия записи в источнике; nil - запись есть
+	ArchivedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// HRSyncRun - запуск синхронизации с отчетом о сверке
+type HRSyncRun struct {
+	ID             uint   `gorm:"primaryKey"`
+	OrganizationID uint   `gorm:"not null;default:1;index"`
+	Trigger        string `gorm:"not null"`
+	Status         string `gorm:"not null;index"`
+	StartedBy      *uint  // Пользователь, запустивший синхронизацию вручную
+	Fetched        int    // Записей получено из источника
+	Created        int
+	Linked         int
+	Updated        int
+	Archived       int
+	Restored       int
+	Pushed         int
+	Failed         int
+	Error          string // Причина прерывания запуска
+	StartedAt      time.Time
+	FinishedAt     *time.Time
+
+	Items []HRSyncRunItem `gorm:"foreignKey:RunID"`
+}
+
+// HRSyncRunItem - изменение или ошибка по одной записи в отчете о синхронизации.
+// Записи без изменений в отчет не попадают.
+type HRSyncRunItem struct {
+	ID         uint   `gorm:"primaryKey"`
+	RunID      uint   `gorm:"not null;index"`
+	ExternalID string `gorm:"not null"`
+	ContactID  *uint
+	Action     string `gorm:"not null"`
+	Details    string // Измененные поля или текст ошибки
+}
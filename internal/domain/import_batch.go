@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Статусы пакета импорта
+const (
+	ImportBatchStaged     = "staged"      // Файл разобран, ожидает подтверждения
+	ImportBatchConfirmed  = "confirmed"   // Контакты созданы
+	ImportBatchRolledBack = "rolled_back" // Созданные контакты удалены
+	ImportBatchDiscarded  = "discarded"   // Отменен без создания контактов
+)
+
+// Статусы строки пакета импорта
+const (
+	ImportRowValid      = "valid"
+	ImportRowInvalid    = "invalid"
+	ImportRowCreated    = "created"
+	ImportRowFailed     = "failed"
+	ImportRowRolledBack = "rolled_back"
+)
+
+// ImportBatch - загруженный файл с контактами, разобранный в строки для предпросмотра перед созданием.
+type ImportBatch struct {
+	ID           uint   `gorm:"primaryKey"`
+	FileName     string `gorm:"not null"`
+	Status       string `gorm:"not null;index"`
+	CreatedBy    uint   `gorm:"not null"`
+	TotalRows    int
+	ValidRows    int
+	CreatedRows  int
+	ConfirmedAt  *time.Time
+	RolledBackAt *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	Rows []ImportRow `gorm:"foreignKey:BatchID"`
+}
+
+// ImportRow - строка файла импорта. Data хранит JSON-объект "поле -> значение".
+type ImportRow struct {
+	ID        uint   `gorm:"primaryKey"`
+	BatchID   uint   `gorm:"not null;index"`
+	RowNumber int    `gorm:"not null"` // Номер строки в файле, начиная с 1 (заголовок не считается)
+	Data      string `gorm:"not null"`
+	Status    string `gorm:"not null"`
+	Error     string
+	ContactID *uint // Контакт, созданный из строки
+}
+
+// Fields возвращает значения полей строки.
+func (r ImportRow) Fields() map[string]string {
+	fields := map[string]string{}
+	if r.Data != "" {
+		_ = json.Unmarshal([]byte(r.Data), &fields)
+	}
+	return fields
+}
+
+// SetFields сохраняет значения полей строки.
+func (r *ImportRow) SetFields(fields map[string]string) error {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	r.Data = string(data)
+	return nil
+}
@@ -11,50 +11,283 @@ import (
 type Contact struct {
 	gorm.Model        // Включает ID, CreatedAt, UpdatedAt, DeletedAt
 	Name       string `gorm:"not null"`
-	Phone      string `gorm:"not null;uniqueIndex"` // Телефон должен быть уникальным
-	Email      string `gorm:"not null;uniqueIndex"` // Email должен быть уникальным
+	Phone      string `gorm:"not null;serializer:encrypted"`                                                        // Хранится зашифрованным (pkg/crypto)
+	Email      string `gorm:"not null;uniqueIndex:idx_contacts_email_set,where:deleted_at IS NULL AND email <> ''"` // Уникален среди неудаленных контактов; пуст только у черновиков
+	Status     string `gorm:"not null;default:active;index"`                                                        // Жизненный цикл: active, on_leave, alumni, draft
+	UpdatedBy  *uint  `gorm:"index"`                                                                                // Пользователь, последним создавший или изменивший контакт
+	// Blocked - контакт заблокирован: его пользователь не может войти, данные контакта сохраняются для истории
+	Blocked bool `gorm:"not null;default:false;index"`
+	// ArchivedAt - когда контакт убран в архив, nil - не в архиве. В отличие от удаления архивный контакт
+	// доступен по ID, к нему привязываются пользователи, но в справочник и выборки по умолчанию он не попадает.
+	ArchivedAt *time.Time `gorm:"index"`
+	// EmailVerified - владелец подтвердил Email по ссылке из письма; сбрасывается при каждой смене email
+	EmailVerified bool `gorm:"not null;default:false"`
+	// PhoneHash - детерминированный индекс телефона для поиска; уникален среди неудаленных контактов
+	PhoneHash string `gorm:"not null;default:'';uniqueIndex:idx_contacts_phone_hash_active,where:deleted_at IS NULL AND phone_hash <> ''" json:"-"`
+	// AllergyIndex - индексы слов аллергий (crypto.WordIndex) для поиска по целым словам без расшифровки
+	AllergyIndex string `gorm:"not null;default:''" json:"-"`
+	// FieldVisibility - JSON-объект "поле -> уровень видимости" (Visibility, SetVisibility)
+	FieldVisibility string `gorm:"not null;default:''" json:"-"`
+	// OrganizationID - организация контакта; телефон и email уникальны во всем развертывании
+	OrganizationID uint `gorm:"not null;default:1;index"`
 
 	// Необязательные поля
 	Transport  string // "car", "license", "none"
-	Printer    string // "color", "plain", "none"
-	Allergies  string
+	Printer    string // PrinterColor, PrinterPlain или PrinterNone; по нему заявки на печать направляются владельцам принтеров
+	Allergies  string `gorm:"serializer:encrypted"` // Хранится зашифрованным (pkg/crypto)
 	VK         string
 	Telegram   string
-	TelegramID int64 `gorm:"uniqueIndex"` // ID пользователя в Telegram
+	TelegramID int64 `gorm:"uniqueIndex:idx_contacts_telegram_id_set,where:telegram_id <> 0"` // ID пользователя в Telegram, 0 - не привязан
+
+	// Экстренный контакт (ICE) - кого известить в чрезвычайной ситуации. Не входит в ответы о контактах:
+	// читается только через internal/emergency с правом на ресурс emergency_contacts, каждое чтение попадает в журнал аудита
+	EmergencyName     string `json:"-"`
+	EmergencyPhone    string `gorm:"serializer:encrypted" json:"-"` // Хранится зашифрованным (pkg/crypto)
+	EmergencyRelation string `json:"-"`                             // Кем приходится контакту
+
+	// Местоположение; значения выбираются из справочника LocationOption
+	City     string `gorm:"index"`
+	Campus   string
+	Building string
+	Room     string
+
+	// Место в структуре организации; по отделу список контактов фильтруется и группируется
+	Department string `gorm:"index"`
+	Position   string // Должность
+
+	// Фото профиля Telegram, сохраненное фоновой задачей (internal/avatar) и отдаваемое по /contacts/{id}/avatar
+	AvatarFileID    string     `gorm:"not null;default:''" json:"-"` // file_unique_id сохраненного фото, пусто - аватара нет
+	AvatarCheckedAt *time.Time `gorm:"index" json:"-"`               // Когда фото в последний раз запрашивалось у Telegram, nil - еще не запрашивалось
 
 	Groups []*Group `gorm:"many2many:contact_groups;"` // Связь многие-ко-многим с группами
+	// Memberships - строки contact_groups со сроками членства; загружаются вместе с Groups
+	Memberships []ContactGroup `gorm:"foreignKey:ContactID"`
+	Skills      []*Skill       `gorm:"many2many:contact_skills;"` // Навыки контакта (дизайн, видео, звук и т.д.)
+	Tags        []*Tag         `gorm:"many2many:contact_tags;"`   // Метки организации (Tag)
+
+	Relations        []ContactRelation `gorm:"foreignKey:FromContactID"` // Связи, где контакт - источник
+	InverseRelations []ContactRelation `gorm:"foreignKey:ToContactID"`   // Связи, где контакт - цель
+
+	// FieldValues - значения дополнительных полей организации (ContactFieldDefinition)
+	FieldValues []ContactFieldValue `gorm:"foreignKey:ContactID"`
+}
+
+// Статусы жизненного цикла контакта
+const (
+	ContactStatusActive  = "active"
+	ContactStatusOnLeave = "on_leave" // Временно не участвует (академ, отпуск)
+	ContactStatusAlumni  = "alumni"   // Выпускник, больше не участвует в работе
+	ContactStatusDraft   = "draft"    // Создан из контакта, пересланного боту; становится active, когда профиль дополнен
+)
+
+// IsArchived сообщает, убран ли контакт в архив
+func (c *Contact) IsArchived() bool {
+	return c.ArchivedAt != nil
+}
+
+// IsActive сообщает, участвует ли контакт в текущей работе.
+// Неактивные контакты не должны попадать в рассылки и графики дежурств.
+func (c *Contact) IsActive() bool {
+	return c.Status == "" || c.Status == ContactStatusActive
+}
+
+// Типы связей между контактами
+const (
+	RelationMentor           = "mentor"            // To - наставник From
+	RelationManager          = "manager"           // To - руководитель From
+	RelationEmergencyContact = "emergency_contact" // To - контакт для экстренной связи с From
+)
+
+// ContactRelation представляет направленную связь между контактами:
+// To является для From тем, что указано в Type (например, наставником).
+type ContactRelation struct {
+	ID            uint   `gorm:"primaryKey"`
+	FromContactID uint   `gorm:"not null;uniqueIndex:idx_contact_relations_from_to_type"`
+	ToContactID   uint   `gorm:"not null;index;uniqueIndex:idx_contact_relations_from_to_type"`
+	Type          string `gorm:"not null;uniqueIndex:idx_contact_relations_from_to_type"`
+	CreatedAt     time.Time
+
+	FromContact *Contact `gorm:"foreignKey:FromContactID"`
+	ToContact   *Contact `gorm:"foreignKey:ToContactID"`
 }
 
-// User представляет авторизованного пользователя системы
+// Типы пользователей
+const (
+	UserTypeHuman   = "human"   // Входит через Telegram или по телефону
+	UserTypeService = "service" // Сервисный аккаунт бота или интеграции, аутентифицируется API-ключом
+)
+
+// User представляет авторизованного пользователя системы.
+// Сервисные аккаунты не имеют Telegram ID и контакта, их права задаются полем Role.
 type User struct {
-	ID         uint      `json:"id" gorm:"primaryKey"`
-	TelegramID int64     `json:"telegram_id" gorm:"uniqueIndex;not null"`
-	ContactID  *uint     `json:"contact_id" gorm:"index"` // Связь с контактом
-	IsActive   bool      `json:"is_active" gorm:"default:true"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	TelegramID int64  `json:"telegram_id" gorm:"not null;uniqueIndex:idx_users_telegram_id_set,where:telegram_id <> 0"` // 0 у пользователей, вошедших по телефону, и сервисных аккаунтов
+	ContactID  *uint  `json:"contact_id" gorm:"index"`                                                                  // Связь с контактом
+	IsActive   bool   `json:"is_active" gorm:"default:true"`
+	Timezone   string `json:"timezone" gorm:"not null;default:'UTC'"` // Часовой пояс IANA, например "Europe/Moscow"
+	PhotoURL   string `json:"photo_url,omitempty"`                    // Исходный адрес фото из Telegram, обновляется при каждом входе
+	Type       string `json:"type" gorm:"not null;default:'human'"`   // human или service
+	Name       string `json:"name,omitempty"`                         // Название сервисного аккаунта
+	Role       string `json:"role,omitempty"`                         // Роль политики доступа сервисного аккаунта
+	APIKeyHash string `json:"-" gorm:"uniqueIndex:idx_users_api_key_hash,where:api_key_hash <> ''"`
+	// LastLoginAt - время последнего входа; nil, если пользователь еще не входил после появления поля
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" gorm:"index"`
+	// NotificationChannels - каналы уведомлений через запятую; пусто - все каналы (см. NotificationChannelList)
+	NotificationChannels string `json:"-" gorm:"not null;default:''"`
+	// WeeklyDigest - получать ли еженедельную сводку изменений справочника; DigestSentAt - время последней сводки
+	WeeklyDigest bool       `json:"-" gorm:"not null;default:true"`
+	DigestSentAt *time.Time `json:"-"`
+	// AcceptedTermsVersion - последняя принятая версия пользовательского соглашения; журнал - TermsAcceptance
+	AcceptedTermsVersion string    `json:"-" gorm:"not null;default:''"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
 
 	// Связь с контактом
 	Contact *Contact `json:"contact,omitempty" gorm:"foreignKey:ContactID"`
 }
 
-// UserSession представляет сессию пользователя в Redis
+// IsServiceAccount проверяет, является ли пользователь сервисным аккаунтом
+func (u *User) IsServiceAccount() bool {
+	return u.Type == UserTypeService
+}
+
+// UserSession представляет сессию пользователя.
+// Хранится в Redis (JSON) или в таблице user_sessions SQLite, в зависимости от SESSION_STORE.
 type UserSession struct {
-	SessionToken string    `json:"session_token"`
-	UserID       uint      `json:"user_id"`
+	SessionToken string    `json:"session_token" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"not null;index"`
+	DeviceID     uint      `json:"device_id" gorm:"index"` // Устройство, с которого выполнен вход
 	CreatedAt    time.Time `json:"created_at"`
-	ExpiredAt    time.Time `json:"expired_at"`
+	ExpiredAt    time.Time `json:"expired_at" gorm:"index"`
+}
+
+// TableName возвращает имя таблицы для UserSession
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// UserDevice представляет устройство, с которого пользователь входил в систему.
+// Устройство определяется по хешу идентификатора из cookie device_id (сам идентификатор не хранится).
+type UserDevice struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	UserID          uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_devices_user_fingerprint"`
+	FingerprintHash string    `json:"-" gorm:"not null;uniqueIndex:idx_user_devices_user_fingerprint"`
+	Label           string    `json:"label"`                        // Название, заданное пользователем (по умолчанию - User-Agent)
+	UserAgent       string    `json:"user_agent"`                   // User-Agent последнего входа
+	Trusted         bool      `json:"trusted" gorm:"default:false"` // Недоверенные устройства администраторов ожидают подтверждения
+	LastSeenAt      time.Time `json:"last_seen_at"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// TableName возвращает имя таблицы для UserDevice
+func (UserDevice) TableName() string {
+	return "user_devices"
+}
+
+// LoginCode представляет одноразовый код входа по телефону.
+// Хранится только хеш кода; на один телефон действует один код.
+type LoginCode struct {
+	Phone     string `gorm:"primaryKey"`
+	CodeHash  string `gorm:"not null"`
+	Attempts  int    `gorm:"not null;default:0"` // Количество неудачных попыток ввода
+	CreatedAt time.Time
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+// TableName возвращает имя таблицы для LoginCode
+func (LoginCode) TableName() string {
+	return "login_codes"
 }
 
 // Group представляет модель группы контактов.
 // Контакты могут принадлежать к нескольким группам.
 type Group struct {
 	gorm.Model        // Включает ID, CreatedAt, UpdatedAt, DeletedAt
-	Name       string `gorm:"not null;uniqueIndex"` // Название группы должно быть уникальным
+	Name       string `gorm:"not null;uniqueIndex:idx_groups_org_name"` // Название группы уникально в пределах организации
+	IsOpen     bool   `gorm:"not null;default:false"`                   // Пользователи могут подать заявку на вступление
+
+	OrganizationID uint `gorm:"not null;default:1;uniqueIndex:idx_groups_org_name"`
 
 	Contacts []*Contact `gorm:"many2many:contact_groups;"` // Связь многие-ко-многим с контактами
 }
 
+// ContactGroup - членство контакта в группе (таблица связи contact_groups).
+// ExpiresAt задает срок членства, после которого контакт автоматически исключается из группы; nil - бессрочно.
+type ContactGroup struct {
+	GroupID   uint       `gorm:"primaryKey"`
+	ContactID uint       `gorm:"primaryKey"`
+	ExpiresAt *time.Time `gorm:"index"`
+
+	Group   *Group   `gorm:"foreignKey:GroupID"`
+	Contact *Contact `gorm:"foreignKey:ContactID"`
+}
+
+// Виды событий истории членства в группах
+const (
+	MembershipJoined = "joined"
+	MembershipLeft   = "left"
+)
+
+// Причины изменения членства
+const (
+	MembershipSourceManual      = "manual"       // Группы контакта изменил пользователь
+	MembershipSourceJoinRequest = "join_request" // Одобрена заявка на вступление
+	MembershipSourceExpired     = "expired"      // Истек срок членства
+	MembershipSourceMerge       = "merge"        // Группы перешли от дубликата при объединении контактов
+)
+
+// GroupMembershipEvent - вступление контакта в группу или выход из нее.
+// События только добавляются и не удаляются вместе с группой, поэтому по ним можно восстановить,
+// в какие периоды контакт состоял в группе.
+type GroupMembershipEvent struct {
+	ID        uint      `gorm:"primaryKey"`
+	ContactID uint      `gorm:"not null;index"`
+	GroupID   uint      `gorm:"not null;index"`
+	Type      string    `gorm:"not null"`                // joined или left
+	Source    string    `gorm:"not null;default:manual"` // MembershipSource*
+	ActorID   *uint     `gorm:"index"`                   // Пользователь, изменивший членство; nil - система или аноним
+	CreatedAt time.Time `gorm:"index"`
+
+	Group *Group `gorm:"foreignKey:GroupID"`
+}
+
+// ExpiresAtFor возвращает срок членства контакта в группе или nil, если он не задан.
+func (c *Contact) ExpiresAtFor(groupID uint) *time.Time {
+	for _, m := range c.Memberships {
+		if m.GroupID == groupID {
+			return m.ExpiresAt
+		}
+	}
+	return nil
+}
+
+// Виды значений справочника местоположений
+const (
+	LocationCity     = "city"
+	LocationCampus   = "campus"
+	LocationBuilding = "building"
+	LocationRoom     = "room"
+)
+
+// LocationOption - допустимое значение одного из полей местоположения контакта.
+// Справочник ведут администраторы.
+type LocationOption struct {
+	ID        uint   `gorm:"primaryKey"`
+	Kind      string `gorm:"not null;uniqueIndex:idx_location_options_kind_value"`
+	Value     string `gorm:"not null;uniqueIndex:idx_location_options_kind_value"`
+	CreatedAt time.Time
+}
+
+// Skill представляет навык из общего справочника, который можно назначить контактам.
+type Skill struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"not null;uniqueIndex"` // Хранится в нижнем регистре
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Contacts []*Contact `gorm:"many2many:contact_skills;"`
+}
+
 // TODO: Рассмотреть необходимость отдельных типов для Transport и Printer,
 // например, enum-подобные константы, для улучшения типобезопасности и валидации.
 
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Каналы уведомлений, которые пользователь может выбрать
+const (
+	NotifyChannelTelegram = "telegram"
+	NotifyChannelEmail    = "email"
+	NotifyChannelPush     = "push"
+)
+
+// NotifyChannels - все выбираемые каналы уведомлений
+var NotifyChannels = []string{NotifyChannelTelegram, NotifyChannelEmail, NotifyChannelPush}
+
+// PushSubscription представляет подписку браузера на Web Push.
+// У пользователя может быть несколько подписок - по одной на браузер или установленное PWA.
+type PushSubscription struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Endpoint  string    `json:"endpoint" gorm:"not null;uniqueIndex"` // Адрес сервиса доставки браузера
+	P256dh    string    `json:"-" gorm:"not null"`                    // Открытый ключ браузера для шифрования
+	Auth      string    `json:"-" gorm:"not null"`                    // Секрет аутентификации браузера
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName возвращает имя таблицы для PushSubscription
+func (PushSubscription) TableName() string {
+	return "push_subscriptions"
+}
+
+// NotificationChannelList возвращает каналы, выбранные пользователем; nil - все каналы
+func (u *User) NotificationChannelList() []string {
+	if u.NotificationChannels == "" {
+		return nil
+	}
+	var channels []string
+	for _, channel := range strings.Split(u.NotificationChannels, ",") {
+		if channel = strings.TrimSpace(channel); channel != "" && channel != notifyChannelsNone {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+// WantsNotifications проверяет, получает ли пользователь уведомления в канал
+func (u *User) WantsNotifications(channel string) bool {
+	if u.NotificationChannels == "" {
+		return true
+	}
+	for _, c := range u.NotificationChannelList() {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// SetNotificationChannels сохраняет выбор каналов. Пустой список отключает все каналы.
+func (u *User) SetNotificationChannels(channels []string) {
+	if len(channels) == 0 {
+		u.NotificationChannels = notifyChannelsNone
+		return
+	}
+	u.NotificationChannels = strings.Join(channels, ",")
+}
+
+// notifyChannelsNone хранится, когда пользователь отключил все каналы (пустая строка означает "все")
+const notifyChannelsNone = "none"
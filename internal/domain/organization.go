@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// DefaultOrganizationID - организация, созданная при миграции. К ней относятся данные,
+// существовавшие до появления организаций, и запросы без выбранной организации.
+const DefaultOrganizationID uint = 1
+
+// Роли пользователя внутри организации
+const (
+	OrgRoleMember = "member" // Участник: права определяются политикой доступа
+	OrgRoleAdmin  = "admin"  // Администратор организации: роль admin политики в пределах организации
+)
+
+// Organization - отдельное пространство данных: контакты, группы, пользователи и настройки
+// одной организации не видны другим организациям того же развертывания.
+type Organization struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// OrganizationMember - явное членство пользователя в организации.
+// Пользователь без такой записи состоит в организации своего контакта с ролью member.
+type OrganizationMember struct {
+	OrganizationID uint   `gorm:"primaryKey"`
+	UserID         uint   `gorm:"primaryKey;index"`
+	Role           string `gorm:"not null;default:member"`
+	CreatedAt      time.Time
+
+	Organization *Organization `gorm:"foreignKey:OrganizationID"`
+	User         *User         `gorm:"foreignKey:UserID"`
+}
+
+// IsValidOrgRole проверяет, является ли строка ролью внутри организации.
+func IsValidOrgRole(role string) bool {
+	return role == OrgRoleMember || role == OrgRoleAdmin
+}
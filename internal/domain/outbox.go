@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// OutboxEvent - исходящее событие, записанное в одной транзакции с изменением данных, которое его вызвало.
+// Фоновый обработчик передает события подписчику темы и удаляет их после успешной обработки,
+// поэтому сбой процесса между изменением и отправкой уведомления не теряет событие.
+type OutboxEvent struct {
+	ID        uint   `gorm:"primaryKey"`
+	Topic     string `gorm:"not null;index"`
+	Payload   string `gorm:"type:text;not null"` // JSON, формат определяется темой
+	Attempts  int    `gorm:"not null;default:0"` // Число неудачных попыток обработки
+	LastError string
+	CreatedAt time.Time
+}
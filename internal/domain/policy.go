@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Роли, к которым применяются правила политики доступа
+const (
+	RoleGuest = "guest" // Неавторизованный посетитель
+	RoleUser  = "user"  // Авторизованный пользователь
+	RoleAdmin = "admin" // Администратор (группа "Администраторы" или отладочный режим)
+)
+
+// PolicyWildcard в правиле соответствует любой роли, ресурсу или действию.
+// Для ресурсов поддерживается также суффикс ".*" (например, "contact.*" - все поля контакта).
+const PolicyWildcard = "*"
+
+// PolicyRule представляет правило политики доступа.
+// Ресурс - либо группа маршрутов ("contacts", "system"), либо поле сущности ("contact.phone").
+// Запрещающие правила (Allow=false) имеют приоритет над разрешающими.
+type PolicyRule struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Role      string    `gorm:"not null;uniqueIndex:idx_policy_rules_role_resource_action" json:"role"`
+	Resource  string    `gorm:"not null;uniqueIndex:idx_policy_rules_role_resource_action" json:"resource"`
+	Action    string    `gorm:"not null;uniqueIndex:idx_policy_rules_role_resource_action" json:"action"`
+	Allow     bool      `gorm:"not null" json:"allow"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName возвращает имя таблицы для PolicyRule
+func (PolicyRule) TableName() string {
+	return "policy_rules"
+}
+
+// Matches проверяет, применяется ли правило к роли, ресурсу и действию.
+func (r PolicyRule) Matches(role, resource, action string) bool {
+	if r.Role != PolicyWildcard && r.Role != role {
+		return false
+	}
+	if r.Action != PolicyWildcard && r.Action != action {
+		return false
+	}
+	switch {
+	case r.Resource == PolicyWildcard || r.Resource == resource:
+		return true
+	case strings.HasSuffix(r.Resource, ".*"):
+		return strings.HasPrefix(resource, strings.TrimSuffix(r.Resource, "*"))
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+// Значения поля Contact.Printer
+const (
+	PrinterColor = "цветной"
+	PrinterPlain = "обычный"
+	PrinterNone  = "нет"
+)
+
+// Статусы заявки на печать
+const (
+	PrintJobPending    = "pending"     // Ждет, пока ее примет владелец подходящего принтера
+	PrintJobAccepted   = "accepted"    // Принята владельцем принтера
+	PrintJobPrinted    = "printed"     // Напечатана, ждет передачи
+	PrintJobHandedOver = "handed_over" // Передана отправителю
+	PrintJobCancelled  = "cancelled"   // Отменена отправителем
+)
+
+// PrintJob - заявка участника на печать файла. Заявка видна владельцам принтеров, которые могут ее выполнить:
+// цветную печать - только владельцам цветных принтеров. Файл хранится на диске до передачи или отмены заявки.
+type PrintJob struct {
+	ID             uint   `gorm:"primaryKey"`
+	OrganizationID uint   `gorm:"not null;default:1;index"`
+	UserID         uint   `gorm:"not null;index"` // Пользователь, отправивший заявку
+	ContactID      uint   `gorm:"not null;index"` // Контакт отправителя
+	FileName       string `gorm:"not null"`
+	ContentType    string `gorm:"not null"`
+	FileSize       int64  `gorm:"not null"`
+	Color          bool   `gorm:"not null;default:false"`
+	Copies         int    `gorm:"not null;default:1"`
+	Deadline       *time.Time
+	Comment        string
+	Status         string `gorm:"not null;default:pending;index"`
+	AssigneeID     *uint  `gorm:"index"` // Контакт владельца принтера, принявшего заявку
+	AcceptedAt     *time.Time
+	PrintedAt      *time.Time
+	HandedOverAt   *time.Time
+	CancelledAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	Contact  *Contact `gorm:"foreignKey:ContactID"`
+	Assignee *Contact `gorm:"foreignKey:AssigneeID"`
+}
+
+// PrinterFits сообщает, может ли принтер printer (значение Contact.Printer) выполнить заявку
+func (j PrintJob) PrinterFits(printer string) bool {
+	return printer == PrinterColor || printer == PrinterPlain && !j.Color
+}
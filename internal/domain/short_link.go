@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// ShortLink - короткая ссылка /l/{code} на длинный адрес, например ссылку бота на черновик контакта.
+// Код можно продиктовать или напечатать: в нем нет похожих символов, регистр не важен.
+type ShortLink struct {
+	ID             uint       `gorm:"primaryKey"`
+	OrganizationID uint       `gorm:"not null;default:1;index"`
+	Code           string     `gorm:"not null;uniqueIndex"` // Хранится в нижнем регистре
+	TargetURL      string     `gorm:"not null"`
+	Purpose        string     `gorm:"not null;default:'';index"` // Откуда создана ссылка: manual, bot и т.д.
+	ExpiresAt      *time.Time `gorm:"index"`                     // nil - бессрочная
+	Clicks         int64      `gorm:"not null;default:0"`
+	LastClickedAt  *time.Time
+	CreatedBy      *uint // Пользователь, создавший ссылку; nil - создана системой
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Назначения коротких ссылок
+const (
+	ShortLinkManual = "manual" // Создана администратором
+	ShortLinkBot    = "bot"    // Ссылка из сообщения бота
+)
+
+// Expired сообщает, истек ли срок ссылки к моменту now
+func (l ShortLink) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
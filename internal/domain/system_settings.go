@@ -6,17 +6,66 @@ import (
 	"gorm.io/gorm"
 )
 
-// SystemSetting представляет настройку системы
+// SystemSetting представляет настройку системы.
+// Настройки хранятся отдельно для каждой организации.
 type SystemSetting struct {
-	ID        uint           `gorm:"primarykey" json:"id"`
-	Key       string         `gorm:"uniqueIndex;not null" json:"key"`
-	Value     string         `gorm:"not null" json:"value"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID             uint           `gorm:"primarykey" json:"id"`
+	OrganizationID uint           `gorm:"not null;default:1;uniqueIndex:idx_system_settings_org_key" json:"organization_id"`
+	Key            string         `gorm:"uniqueIndex:idx_system_settings_org_key;not null" json:"key"`
+	Value          string         `gorm:"not null" json:"value"`
+	UpdatedBy      *uint          `gorm:"index" json:"updated_by,omitempty"` // Пользователь, последним изменивший настройку
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // TableName возвращает имя таблицы для SystemSetting
 func (SystemSetting) TableName() string {
 	return "system_settings"
 }
+
+// RateLimit задает ограничение частоты запросов группы маршрутов для одного клиента.
+type RateLimit struct {
+	RequestsPerMinute int `json:"requests_per_minute"` // 0 - без ограничений
+	Burst             int `json:"burst"`               // Сколько запросов подряд допускается без пауз
+}
+
+// ContentSecurityPolicy задает настраиваемые источники заголовка Content-Security-Policy.
+// Остальные директивы (default-src, style-src, object-src и т.д.) фиксированы.
+type ContentSecurityPolicy struct {
+	ScriptSrc  []string `json:"script_src"`
+	ConnectSrc []string `json:"connect_src"`
+	ImgSrc     []string `json:"img_src"`
+	// ReportOnly отправляет политику в Content-Security-Policy-Report-Only: нарушения
+	// только сообщаются в /csp-report и не блокируются. Для проверки новой политики перед включением.
+	ReportOnly bool `json:"report_only"`
+}
+
+// SecurityHeaders переопределяет заголовки безопасности профиля окружения (APP_ENV).
+// Поля nil оставляют значение профиля.
+type SecurityHeaders struct {
+	// FrameOptions - DENY, SAMEORIGIN или пустая строка, чтобы не отправлять X-Frame-Options
+	// (например, когда приложение открывается как Telegram WebApp во фрейме)
+	FrameOptions *string `json:"frame_options"`
+	// HSTSMaxAgeSeconds - срок Strict-Transport-Security; 0 - заголовок не отправляется
+	HSTSMaxAgeSeconds *int `json:"hsts_max_age_seconds"`
+	// PermissionsPolicy - записи Permissions-Policy вида "camera=()" или "geolocation=(self)";
+	// пустой список - заголовок не отправляется
+	PermissionsPolicy []string `json:"permissions_policy"`
+}
+
+// ContactFieldRules задает правила проверки полей контакта, которые администраторы меняют без выпуска новой версии.
+// Используется при создании и изменении контактов, в профиле пользователя, импорте и синхронизации с кадровой системой.
+type ContactFieldRules struct {
+	Transport     []string `json:"transport"`       // Допустимые значения поля transport
+	Printer       []string `json:"printer"`         // Допустимые значения поля printer
+	NameMinLength int      `json:"name_min_length"` // Минимальная длина имени в символах
+	NameMaxLength int      `json:"name_max_length"` // Максимальная длина имени в символах
+}
+
+// TermsOfService описывает действующую версию пользовательского соглашения.
+// Пустая Version - соглашение не требуется.
+type TermsOfService struct {
+	Version string `json:"version"` // После изменения пользователи принимают соглашение заново
+	URL     string `json:"url"`     // Адрес текста соглашения для показа пользователю
+}
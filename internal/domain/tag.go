@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// Tag - метка контакта ("есть ключи от машины", "говорит по-английски"). В отличие от группы у метки нет
+// модераторов, сроков членства и истории: ее ставят и снимают одним запросом.
+type Tag struct {
+	ID             uint   `gorm:"primaryKey"`
+	OrganizationID uint   `gorm:"not null;default:1;uniqueIndex:idx_tags_org_name"`
+	Name           string `gorm:"not null;uniqueIndex:idx_tags_org_name"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	Contacts []*Contact `gorm:"many2many:contact_tags;"`
+}
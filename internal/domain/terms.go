@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// TermsAcceptance - запись журнала о принятии пользователем версии пользовательского соглашения
+type TermsAcceptance struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"not null;index"`
+	Version    string `gorm:"not null"`
+	IPAddress  string
+	UserAgent  string
+	AcceptedAt time.Time `gorm:"not null"`
+}
@@ -0,0 +1,14 @@
+package domain
+
+// UsageCounter - число событий использования функции за день (UTC).
+// Хранятся только счетчики: ни пользователь, ни параметры запроса не записываются.
+type UsageCounter struct {
+	Date  string `json:"date" gorm:"primaryKey"` // YYYY-MM-DD
+	Event string `json:"event" gorm:"primaryKey"`
+	Count int64  `json:"count" gorm:"not null;default:0"`
+}
+
+// TableName возвращает имя таблицы для UsageCounter
+func (UsageCounter) TableName() string {
+	return "usage_counters"
+}
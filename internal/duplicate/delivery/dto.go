@@ -0,0 +1,18 @@
+package delivery
+
+// DuplicateContactResponse определяет сведения о контакте, по которым администратор выбирает, какой из дубликатов оставить.
+type DuplicateContactResponse struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Phone     string `json:"phone,omitempty"` // Пустое, если скрыто политикой доступа
+	Email     string `json:"email,omitempty"`
+	Telegram  string `json:"telegram,omitempty"`
+	CreatedAt string `json:"created_at"` // RFC3339 в часовом поясе пользователя
+}
+
+// DuplicateGroupResponse определяет группу вероятных дубликатов.
+type DuplicateGroupResponse struct {
+	Reasons  []string                   `json:"reasons"` // phone, telegram, name
+	Contacts []DuplicateContactResponse `json:"contacts"`
+}
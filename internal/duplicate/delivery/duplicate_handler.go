@@ -0,0 +1,120 @@
+package delivery
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	"rim/internal/duplicate/usecase"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/pkg/apperror"
+	"rim/pkg/requestctx"
+	"rim/pkg/timeutil"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler отвечает за обработку HTTP-запросов поиска и объединения дубликатов контактов.
+type Handler struct {
+	duplicateUseCase usecase.UseCase
+	contactUseCase   contactUseCase.UseCase
+	logger           *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler.
+func NewHandler(duplicateUC usecase.UseCase, contactUC contactUseCase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		duplicateUseCase: duplicateUC,
+		contactUseCase:   contactUC,
+		logger:           logger,
+	}
+}
+
+// GetDuplicates возвращает группы вероятных дубликатов контактов.
+// @Summary Найти дубликаты контактов
+// @Description Контакты считаются дубликатами, если совпадает телефон (в любом написании), имя пользователя Telegram
+// @Description или имя без учета регистра, ё/е и порядка слов. Контакты, совпадающие по нескольким признакам, выводятся одной группой.
+// @Tags contacts
+// @Produce json
+// @Success 200 {array} DuplicateGroupResponse
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/duplicates [get]
+func (h *Handler) GetDuplicates(c *fiber.Ctx) error {
+	groups, err := h.duplicateUseCase.FindDuplicates(c.Context())
+	if err != nil {
+		return h.duplicateError(c, err)
+	}
+
+	loc := requestctx.Location(c)
+	resp := make([]DuplicateGroupResponse, len(groups))
+	for i, g := range groups {
+		if err := h.contactUseCase.FilterFieldsForRole(c.Context(), requestctx.Role(c), g.Contacts); err != nil {
+			return h.duplicateError(c, err)
+		}
+		resp[i] = DuplicateGroupResponse{Reasons: g.Reasons, Contacts: make([]DuplicateContactResponse, len(g.Contacts))}
+		for j := range g.Contacts {
+			resp[i].Contacts[j] = toDuplicateContactResponse(&g.Contacts[j], loc)
+		}
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// MergeContacts объединяет дубликат с контактом.
+// @Summary Объединить контакты
+// @Description Контакт id остается, other_id мягко удаляется (его можно восстановить через /contacts/{id}/restore).
+// @Description Пустые поля id заполняются из other_id, группы со сроками членства, навыки, метки и значения дополнительных полей
+// @Description переносятся, пользователь other_id привязывается к id. Если пользователи есть у обоих контактов, объединение не выполняется.
+// @Tags contacts
+// @Produce json
+// @Param id path int true "ID контакта, который остается"
+// @Param other_id path int true "ID дубликата"
+// @Success 200 {object} DuplicateContactResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID или объединение контакта с самим собой"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт не найден"
+// @Failure 409 {object} groupDelivery.ErrorResponse "У обоих контактов есть пользователи"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/merge/{other_id} [post]
+func (h *Handler) MergeContacts(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+	otherID, err := strconv.ParseUint(c.Params("other_id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+
+	contact, err := h.duplicateUseCase.Merge(c.Context(), uint(id), uint(otherID), requestctx.ActorID(c))
+	if err != nil {
+		return h.duplicateError(c, err)
+	}
+	contacts := []domain.Contact{*contact}
+	if err := h.contactUseCase.FilterFieldsForRole(c.Context(), requestctx.Role(c), contacts); err != nil {
+		return h.duplicateError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(toDuplicateContactResponse(&contacts[0], requestctx.Location(c)))
+}
+
+// duplicateError преобразует ошибки usecase в HTTP-ответ по их виду (pkg/apperror)
+func (h *Handler) duplicateError(c *fiber.Ctx, err error) error {
+	status := apperror.HTTPStatus(err)
+	if status == fiber.StatusInternalServerError {
+		h.logger.ErrorContext(c.Context(), "Contact duplicate operation failed", slog.Any("error", err))
+		return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+}
+
+func toDuplicateContactResponse(contact *domain.Contact, loc *time.Location) DuplicateContactResponse {
+	return DuplicateContactResponse{
+		ID:        contact.ID,
+		Name:      contact.Name,
+		Status:    contact.Status,
+		Phone:     contact.Phone,
+		Email:     contact.Email,
+		Telegram:  contact.Telegram,
+		CreatedAt: timeutil.Format(contact.CreatedAt, loc),
+	}
+}
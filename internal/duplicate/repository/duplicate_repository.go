@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+// Repository определяет интерфейс переноса связанных данных при объединении контактов.
+type Repository interface {
+	// MergeInto переносит на контакт winnerID группы, навыки, метки и значения дополнительных полей контакта
+	// loserID, которых у него нет, и привязывает к нему пользователей loserID. Сам loserID не удаляется, но
+	// теряет Telegram ID: индекс Telegram ID учитывает и удаленные контакты. Возвращает ID новых групп winnerID.
+	MergeInto(ctx context.Context, winnerID, loserID uint, actorID *uint) ([]uint, error)
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр sqliteRepository для объединения контактов.
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// mergedLinks - таблицы связей контакта, строки которых переносятся, если у основного контакта нет такой же:
+// таблица, столбец связанной записи и остальные переносимые столбцы
+var mergedLinks = []struct {
+	table, column, extra string
+}{
+	{"contact_skills", "skill_id", ""},
+	{"contact_tags", "tag_id", ""},
+	{"contact_field_values", "field_id", ", value, updated_at"},
+	{"favorite_contacts", "user_id", ", created_at"},
+}
+
+func (r *sqliteRepository) MergeInto(ctx context.Context, winnerID, loserID uint, actorID *uint) ([]uint, error) {
+	var joined []uint
+	err := transaction.Run(ctx, r.db, func(ctx context.Context) error {
+		tx := transaction.DB(ctx, r.db)
+
+		if err := tx.Table("contact_groups").
+			Where("contact_id = ? AND group_id NOT IN (?)", loserID, tx.Table("contact_groups").Select("group_id").Where("contact_id = ?", winnerID)).
+			Pluck("group_id", &joined).Error; err != nil {
+			r.logger.ErrorContext(ctx, "Error getting groups of merged contact from DB", slog.Uint64("contactID", uint64(loserID)), slog.Any("error", err))
+			return err
+		}
+		if len(joined) > 0 {
+			// Срок членства переходит вместе с группой
+			if err := tx.Exec("INSERT INTO contact_groups (group_id, contact_id, expires_at) SELECT group_id, ?, expires_at FROM contact_groups WHERE contact_id = ? AND group_id IN ?", winnerID, loserID, joined).Error; err != nil {
+				r.logger.ErrorContext(ctx, "Error moving group memberships in DB", slog.Uint64("winnerID", uint64(winnerID)), slog.Uint64("loserID", uint64(loserID)), slog.Any("error", err))
+				return err
+			}
+			events := make([]domain.GroupMembershipEvent, len(joined))
+			for i, groupID := range joined {
+				events[i] = domain.GroupMembershipEvent{ContactID: winnerID, GroupID: groupID, Type: domain.MembershipJoined, Source: domain.MembershipSourceMerge, ActorID: actorID}
+			}
+			if err := tx.Create(&events).Error; err != nil {
+				r.logger.ErrorContext(ctx, "Error recording membership events in DB", slog.Uint64("contactID", uint64(winnerID)), slog.Any("error", err))
+				return err
+			}
+		}
+
+		for _, link := range mergedLinks {
+			query := "INSERT INTO " + link.table + " (contact_id, " + link.column + link.extra + ") SELECT ?, " + link.column + link.extra +
+				" FROM " + link.table + " WHERE contact_id = ? AND " + link.column + " NOT IN (SELECT " + link.column + " FROM " + link.table + " WHERE contact_id = ?)"
+			if err := tx.Exec(query, winnerID, loserID, winnerID).Error; err != nil {
+				r.logger.ErrorContext(ctx, "Error moving contact links in DB", slog.String("table", link.table), slog.Uint64("winnerID", uint64(winnerID)), slog.Uint64("loserID", uint64(loserID)), slog.Any("error", err))
+				return err
+			}
+		}
+
+		if err := tx.Model(&domain.User{}).Where("contact_id = ?", loserID).Update("contact_id", winnerID).Error; err != nil {
+			r.logger.ErrorContext(ctx, "Error relinking users in DB", slog.Uint64("winnerID", uint64(winnerID)), slog.Uint64("loserID", uint64(loserID)), slog.Any("error", err))
+			return err
+		}
+		if err := tx.Model(&domain.Contact{}).Where("id = ?", loserID).Update("telegram_id", 0).Error; err != nil {
+			r.logger.ErrorContext(ctx, "Error releasing Telegram ID of merged contact in DB", slog.Uint64("contactID", uint64(loserID)), slog.Any("error", err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.logger.InfoContext(ctx, "Successfully merged contact links in DB", slog.Uint64("winnerID", uint64(winnerID)), slog.Uint64("loserID", uint64(loserID)), slog.Int("groups", len(joined)))
+	return joined, nil
+}
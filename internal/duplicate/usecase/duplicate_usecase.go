@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	authRepo "rim/internal/auth/repository"
+	contactRepo "rim/internal/contact/repository"
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	"rim/internal/duplicate/repository"
+	"rim/pkg/apperror"
+	"rim/pkg/eventbus"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+// scanBatchSize - размер пачки контактов при поиске дубликатов
+const scanBatchSize = 500
+
+// Признаки, по которым контакты считаются дубликатами
+const (
+	ReasonPhone    = "phone"    // Телефон совпадает после приведения к +7XXXXXXXXXX
+	ReasonTelegram = "telegram" // Совпадает имя пользователя Telegram
+	ReasonName     = "name"     // Имя совпадает без учета регистра, ё/е и порядка слов
+)
+
+var (
+	ErrSameContact   = apperror.Invalid("merge_same_contact", "cannot merge a contact with itself")
+	ErrUsersConflict = apperror.Conflict("merge_users_conflict", "both contacts are linked to user accounts")
+)
+
+// DuplicateGroup - контакты, совпадающие по признакам Reasons, в порядке ID
+type DuplicateGroup struct {
+	Reasons  []string
+	Contacts []domain.Contact
+}
+
+// UseCase определяет интерфейс поиска и объединения дубликатов контактов.
+type UseCase interface {
+	// FindDuplicates возвращает группы вероятных дубликатов среди контактов организации
+	FindDuplicates(ctx context.Context) ([]DuplicateGroup, error)
+	// Merge объединяет контакт otherID с контактом id: пустые поля id заполняются из otherID, группы, навыки,
+	// метки и значения дополнительных полей переносятся, пользователь otherID привязывается к id, а otherID
+	// мягко удаляется. Если пользователи есть у обоих контактов, возвращает ErrUsersConflict.
+	Merge(ctx context.Context, id, otherID uint, actorID *uint) (*domain.Contact, error)
+}
+
+type duplicateUseCase struct {
+	repo        repository.Repository
+	contactRepo contactRepo.Repository
+	authRepo    authRepo.Repository
+	events      eventbus.Publisher
+	tx          transaction.Manager
+	logger      *slog.Logger
+}
+
+// NewDuplicateUseCase создает новый экземпляр duplicateUseCase.
+func NewDuplicateUseCase(repo repository.Repository, cr contactRepo.Repository, ar authRepo.Repository, events eventbus.Publisher, tx transaction.Manager, logger *slog.Logger) UseCase {
+	return &duplicateUseCase{
+		repo:        repo,
+		contactRepo: cr,
+		authRepo:    ar,
+		events:      events,
+		tx:          tx,
+		logger:      logger,
+	}
+}
+
+func (uc *duplicateUseCase) FindDuplicates(ctx context.Context) ([]DuplicateGroup, error) {
+	keys := map[string]map[string][]uint{ReasonPhone: {}, ReasonTelegram: {}, ReasonName: {}}
+	contacts := map[uint]domain.Contact{}
+	// Архивный контакт тоже может оказаться дубликатом действующего
+	err := uc.contactRepo.Each(ctx, contactRepo.ListFilter{IncludeArchived: true}, scanBatchSize, func(batch []domain.Contact) error {
+		for _, ct := range batch {
+			contacts[ct.ID] = ct
+			for reason, key := range map[string]string{
+				ReasonPhone:    contactUseCase.NormalizePhone(ct.Phone),
+				ReasonTelegram: strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ct.Telegram), "@")),
+				ReasonName:     nameKey(ct.Name),
+			} {
+				if key != "" {
+					keys[reason][key] = append(keys[reason][key], ct.ID)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to scan contacts for duplicates", slog.Any("error", err))
+		return nil, err
+	}
+
+	// Одни и те же контакты могут совпадать по нескольким признакам: такие совпадения выводятся одной группой
+	byIDs := map[string]*DuplicateGroup{}
+	var groups []*DuplicateGroup
+	for _, reason := range []string{ReasonPhone, ReasonTelegram, ReasonName} {
+		for _, ids := range keys[reason] {
+			if len(ids) < 2 {
+				continue
+			}
+			slices.Sort(ids)
+			key := fmt.Sprint(ids)
+			group, ok := byIDs[key]
+			if !ok {
+				group = &DuplicateGroup{}
+				for _, id := range ids {
+					group.Contacts = append(group.Contacts, contacts[id])
+				}
+				byIDs[key] = group
+				groups = append(groups, group)
+			}
+			group.Reasons = append(group.Reasons, reason)
+		}
+	}
+	slices.SortFunc(groups, func(a, b *DuplicateGroup) int {
+		return int(a.Contacts[0].ID) - int(b.Contacts[0].ID)
+	})
+
+	result := make([]DuplicateGroup, len(groups))
+	for i, g := range groups {
+		result[i] = *g
+	}
+	uc.logger.InfoContext(ctx, "Contact duplicates found", slog.Int("contacts", len(contacts)), slog.Int("groups", len(result)))
+	return result, nil
+}
+
+func (uc *duplicateUseCase) Merge(ctx context.Context, id, otherID uint, actorID *uint) (*domain.Contact, error) {
+	if id == otherID {
+		return nil, ErrSameContact
+	}
+	winner, err := uc.getContact(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	loser, err := uc.getContact(ctx, otherID)
+	if err != nil {
+		return nil, err
+	}
+	winnerUser, err := uc.linkedUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	loserUser, err := uc.linkedUser(ctx, otherID)
+	if err != nil {
+		return nil, err
+	}
+	if winnerUser != nil && loserUser != nil {
+		return nil, ErrUsersConflict
+	}
+
+	oldPhone, oldEmail := winner.Phone, winner.Email
+	fillEmpty(winner, loser)
+	winner.UpdatedBy = actorID
+	// Группы переносит MergeInto; с заполненным Groups Update заменил бы ими состав групп
+	winner.Groups = nil
+
+	err = uc.tx.Run(ctx, func(ctx context.Context) error {
+		joined, err := uc.repo.MergeInto(ctx, id, otherID, actorID)
+		if err != nil {
+			return err
+		}
+		// Удаление освобождает телефон и email дубликата, поэтому выполняется до сохранения основного контакта
+		if err := uc.contactRepo.Delete(ctx, otherID, actorID); err != nil {
+			return err
+		}
+		if err := uc.contactRepo.Update(ctx, winner); err != nil {
+			return err
+		}
+
+		if err := uc.events.Publish(ctx, domain.ContactDeletedEvent{Contact: loser}); err != nil {
+			return err
+		}
+		for _, change := range []domain.ContactChangedEvent{
+			{Field: "phone", OldValue: oldPhone, NewValue: winner.Phone},
+			{Field: "email", OldValue: oldEmail, NewValue: winner.Email},
+		} {
+			if change.OldValue == change.NewValue {
+				continue
+			}
+			change.Contact = winner
+			if err := uc.events.Publish(ctx, change); err != nil {
+				return err
+			}
+		}
+		for _, groupID := range joined {
+			event := domain.GroupMembershipChangedEvent{GroupID: groupID, ContactID: id, Type: domain.MembershipJoined, Source: domain.MembershipSourceMerge, ActorID: actorID}
+			if err := uc.events.Publish(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to merge contacts", slog.Uint64("id", uint64(id)), slog.Uint64("otherID", uint64(otherID)), slog.Any("error", err))
+		return nil, err
+	}
+
+	uc.logger.InfoContext(ctx, "Contacts merged", slog.Uint64("id", uint64(id)), slog.Uint64("mergedID", uint64(otherID)), slog.Bool("userMoved", loserUser != nil))
+	return uc.contactRepo.GetByID(ctx, id)
+}
+
+func (uc *duplicateUseCase) getContact(ctx context.Context, id uint) (*domain.Contact, error) {
+	contact, err := uc.contactRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, contactUseCase.ErrContactNotFound
+		}
+		return nil, err
+	}
+	return contact, nil
+}
+
+// linkedUser возвращает пользователя контакта или nil, если контакт не привязан к пользователю
+func (uc *duplicateUseCase) linkedUser(ctx context.Context, contactID uint) (*domain.User, error) {
+	user, err := uc.authRepo.GetUserByContactID(ctx, contactID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// fillEmpty заполняет пустые поля winner значениями loser; заполненные поля winner не меняются
+func fillEmpty(winner, loser *domain.Contact) {
+	for _, f := range []struct{ to, from *string }{
+		{&winner.Phone, &loser.Phone},
+		{&winner.Email, &loser.Email},
+		{&winner.Transport, &loser.Transport},
+		{&winner.Printer, &loser.Printer},
+		{&winner.Allergies, &loser.Allergies},
+		{&winner.VK, &loser.VK},
+		{&winner.Telegram, &loser.Telegram},
+		{&winner.City, &loser.City},
+		{&winner.Campus, &loser.Campus},
+		{&winner.Building, &loser.Building},
+		{&winner.Room, &loser.Room},
+		{&winner.Department, &loser.Department},
+		{&winner.Position, &loser.Position},
+	} {
+		if *f.to == "" {
+			*f.to = *f.from
+		}
+	}
+	if winner.TelegramID == 0 {
+		winner.TelegramID = loser.TelegramID
+	}
+}
+
+// nameKey приводит имя к виду, в котором сравниваются имена дубликатов: нижний регистр, е вместо ё,
+// слова по алфавиту ("Иванов Иван" и "иван иванов" совпадают)
+func nameKey(name string) string {
+	words := strings.Fields(strings.ReplaceAll(strings.ToLower(name), "ё", "е"))
+	slices.Sort(words)
+	return strings.Join(words, " ")
+}
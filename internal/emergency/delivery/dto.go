@@ -0,0 +1,17 @@
+package delivery
+
+// EmergencyContactResponse - экстренный контакт (ICE)
+type EmergencyContactResponse struct {
+	ContactID   uint   `json:"contact_id"`
+	ContactName string `json:"contact_name"`
+	Name        string `json:"name"`
+	Phone       string `json:"phone"`
+	Relation    string `json:"relation"` // Кем приходится контакту
+}
+
+// UpdateEmergencyContactRequest - новые значения экстренного контакта; все поля пустые - удалить
+type UpdateEmergencyContactRequest struct {
+	Name     string `json:"name" validate:"omitempty,min=2,max=100"`
+	Phone    string `json:"phone" validate:"omitempty,e164"`
+	Relation string `json:"relation" validate:"omitempty,max=50"`
+}
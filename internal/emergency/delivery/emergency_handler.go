@@ -0,0 +1,148 @@
+package delivery
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"rim/internal/domain"
+	"rim/internal/emergency/usecase"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
+)
+
+// Handler отвечает за HTTP-запросы экстренных контактов.
+type Handler struct {
+	emergencyUseCase usecase.UseCase
+	logger           *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для экстренных контактов.
+func NewHandler(eu usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		emergencyUseCase: eu,
+		logger:           logger,
+	}
+}
+
+// GetEmergencyContact возвращает экстренный контакт (ICE) контакта.
+// @Summary Экстренный контакт
+// @Description Возвращает, кого известить в чрезвычайной ситуации. По умолчанию доступно только администраторам (ресурс политики emergency_contacts);
+// @Description каждый запрос записывается в журнал аудита.
+// @Tags contacts
+// @Produce json
+// @Param id path int true "ID контакта"
+// @Success 200 {object} EmergencyContactResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/emergency [get]
+func (h *Handler) GetEmergencyContact(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+	contact, err := h.emergencyUseCase.Get(c.Context(), actor(c), uint(contactID))
+	if err != nil {
+		return h.emergencyError(c, err)
+	}
+	return c.JSON(toEmergencyContactResponse(contact))
+}
+
+// UpdateEmergencyContact изменяет экстренный контакт (ICE) контакта.
+// @Summary Изменить экстренный контакт
+// @Description Имя и телефон задаются вместе; все поля пустые - экстренный контакт удаляется. Изменение записывается в журнал аудита без значений полей.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param id path int true "ID контакта"
+// @Param request body UpdateEmergencyContactRequest true "Экстренный контакт"
+// @Success 200 {object} EmergencyContactResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректные данные"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/emergency [put]
+func (h *Handler) UpdateEmergencyContact(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+	req, ok, err := validation.BindAndValidate[UpdateEmergencyContactRequest](c)
+	if !ok {
+		return err
+	}
+
+	contact, err := h.emergencyUseCase.Update(c.Context(), actor(c), uint(contactID), usecase.Data{
+		Name:     req.Name,
+		Phone:    req.Phone,
+		Relation: req.Relation,
+	})
+	if err != nil {
+		return h.emergencyError(c, err)
+	}
+	return c.JSON(toEmergencyContactResponse(contact))
+}
+
+// ExportGroupEmergencyContacts выгружает экстренные контакты участников группы.
+// @Summary Выгрузить экстренные контакты группы
+// @Description Возвращает CSV (UTF-8 с BOM для Excel) с телефонами участников группы и их экстренными контактами, например для выездного мероприятия.
+// @Description Цель выгрузки обязательна и вместе с пользователем, адресом и числом контактов записывается в журнал аудита.
+// @Tags groups
+// @Produce text/csv
+// @Param id path int true "ID группы"
+// @Param reason query string true "Цель выгрузки, например название выезда"
+// @Success 200 {file} binary "CSV"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID или не указана цель"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Группа не найдена"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id}/emergency-contacts.csv [get]
+func (h *Handler) ExportGroupEmergencyContacts(c *fiber.Ctx) error {
+	groupID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid group ID format"})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\uFEFF")
+	if _, err := h.emergencyUseCase.ExportGroupCSV(c.Context(), &buf, actor(c), uint(groupID), c.Query("reason")); err != nil {
+		return h.emergencyError(c, err)
+	}
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="emergency-contacts-group-%d-%s.csv"`, groupID, timeutil.Now().Format("2006-01-02")))
+	// Выгрузка содержит телефоны и не должна оставаться в кэшах
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Send(buf.Bytes())
+}
+
+// emergencyError преобразует ошибку UseCase в HTTP-ответ
+func (h *Handler) emergencyError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrIncomplete), errors.Is(err, usecase.ErrReasonRequired), errors.Is(err, usecase.ErrReasonTooLong):
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrContactNotFound), errors.Is(err, usecase.ErrGroupNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	}
+	h.logger.ErrorContext(c.Context(), "Emergency contact request failed", slog.Any("error", err))
+	return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+}
+
+func toEmergencyContactResponse(contact *domain.Contact) EmergencyContactResponse {
+	return EmergencyContactResponse{
+		ContactID:   contact.ID,
+		ContactName: contact.Name,
+		Name:        contact.EmergencyName,
+		Phone:       contact.EmergencyPhone,
+		Relation:    contact.EmergencyRelation,
+	}
+}
+
+// actor возвращает пользователя и адрес запроса для журнала аудита
+func actor(c *fiber.Ctx) usecase.Actor {
+	userID, _ := c.Locals("user_id").(uint)
+	return usecase.Actor{UserID: userID, IP: c.IP()}
+}
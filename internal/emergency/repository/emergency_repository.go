@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+var ErrContactNotFound = errors.New("contact not found")
+
+// emergencyColumns - поля контакта, которые нужны для экстренных контактов
+var emergencyColumns = []string{"contacts.id", "contacts.name", "contacts.phone", "contacts.emergency_name", "contacts.emergency_phone", "contacts.emergency_relation"}
+
+// Repository определяет интерфейс хранения экстренных контактов (ICE)
+type Repository interface {
+	// Get возвращает контакт организации запроса с именем, телефоном и экстренным контактом
+	Get(ctx context.Context, contactID uint) (*domain.Contact, error)
+	// Update сохраняет поля экстренного контакта
+	Update(ctx context.Context, contact *domain.Contact) error
+	// GetGroupContacts возвращает участников группы с именем, телефоном и экстренным контактом, по имени
+	GetGroupContacts(ctx context.Context, groupID uint) ([]domain.Contact, error)
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр репозитория экстренных контактов
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{db: db, logger: logger}
+}
+
+func (r *sqliteRepository) Get(ctx context.Context, contactID uint) (*domain.Contact, error) {
+	var contact domain.Contact
+	err := transaction.DB(ctx, r.db).Select(emergencyColumns).
+		Scopes(tenant.Scope(ctx, "contacts")).
+		First(&contact, contactID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContactNotFound
+		}
+		r.logger.ErrorContext(ctx, "Error getting contact emergency info from DB", slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return nil, err
+	}
+	return &contact, nil
+}
+
+func (r *sqliteRepository) Update(ctx context.Context, contact *domain.Contact) error {
+	// Обновление через структуру, чтобы телефон прошел через serializer:encrypted
+	err := transaction.DB(ctx, r.db).Model(contact).
+		Select("EmergencyName", "EmergencyPhone", "EmergencyRelation", "UpdatedAt").
+		Updates(contact).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error updating contact emergency info in DB", slog.Uint64("contactID", uint64(contact.ID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) GetGroupContacts(ctx context.Context, groupID uint) ([]domain.Contact, error) {
+	var contacts []domain.Contact
+	err := transaction.DB(ctx, r.db).Select(emergencyColumns).
+		Joins("JOIN contact_groups ON contact_groups.contact_id = contacts.id AND contact_groups.group_id = ?", groupID).
+		Scopes(tenant.Scope(ctx, "contacts")).
+		Order("contacts.name, contacts.id").
+		Find(&contacts).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error getting group emergency info from DB", slog.Uint64("groupID", uint64(groupID)), slog.Any("error", err))
+		return nil, err
+	}
+	return contacts, nil
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	auditUseCase "rim/internal/audit/usecase"
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	"rim/internal/emergency/repository"
+	groupUseCase "rim/internal/group/usecase"
+)
+
+// maxReasonLength - наибольшая длина цели выгрузки в символах
+const maxReasonLength = 200
+
+var (
+	ErrIncomplete      = errors.New("emergency contact requires both name and phone")
+	ErrReasonRequired  = errors.New("export reason is required")
+	ErrReasonTooLong   = fmt.Errorf("export reason must be at most %d characters", maxReasonLength)
+	ErrContactNotFound = contactUseCase.ErrContactNotFound
+	ErrGroupNotFound   = groupUseCase.ErrGroupNotFound
+)
+
+// Actor - кто и откуда обращается к экстренным контактам; попадает в журнал аудита
+type Actor struct {
+	UserID uint
+	IP     string
+}
+
+// Data - новые значения экстренного контакта. Все поля пустые - экстренный контакт удаляется.
+type Data struct {
+	Name     string
+	Phone    string
+	Relation string
+}
+
+// UseCase определяет интерфейс экстренных контактов (ICE). Каждое чтение, изменение и выгрузка
+// записываются в журнал аудита с пользователем и адресом запроса.
+type UseCase interface {
+	// Get возвращает контакт с именем, телефоном и экстренным контактом
+	Get(ctx context.Context, actor Actor, contactID uint) (*domain.Contact, error)
+	// Update сохраняет экстренный контакт; имя и телефон задаются вместе
+	Update(ctx context.Context, actor Actor, contactID uint, data Data) (*domain.Contact, error)
+	// ExportGroupCSV записывает в w CSV с экстренными контактами участников группы, например для выездного мероприятия.
+	// reason - цель выгрузки, обязательна и сохраняется в журнале.
+	ExportGroupCSV(ctx context.Context, w io.Writer, actor Actor, groupID uint, reason string) (*domain.Group, error)
+}
+
+type emergencyUseCase struct {
+	repo         repository.Repository
+	groupUseCase groupUseCase.UseCase
+	auditor      auditUseCase.UseCase
+	logger       *slog.Logger
+}
+
+// NewEmergencyUseCase создает новый экземпляр UseCase для экстренных контактов
+func NewEmergencyUseCase(repo repository.Repository, gu groupUseCase.UseCase, auditor auditUseCase.UseCase, logger *slog.Logger) UseCase {
+	return &emergencyUseCase{
+		repo:         repo,
+		groupUseCase: gu,
+		auditor:      auditor,
+		logger:       logger,
+	}
+}
+
+func (uc *emergencyUseCase) Get(ctx context.Context, actor Actor, contactID uint) (*domain.Contact, error) {
+	contact, err := uc.get(ctx, contactID)
+	if err != nil {
+		return nil, err
+	}
+	uc.record(ctx, actor, domain.AuditEmergencyViewed, &contact.ID, "")
+	return contact, nil
+}
+
+func (uc *emergencyUseCase) Update(ctx context.Context, actor Actor, contactID uint, data Data) (*domain.Contact, error) {
+	data.Name = strings.TrimSpace(data.Name)
+	data.Phone = strings.TrimSpace(data.Phone)
+	data.Relation = strings.TrimSpace(data.Relation)
+	if (data.Name == "") != (data.Phone == "") || data.Name == "" && data.Relation != "" {
+		return nil, ErrIncomplete
+	}
+
+	contact, err := uc.get(ctx, contactID)
+	if err != nil {
+		return nil, err
+	}
+	// В журнал попадают только имена измененных полей, без значений
+	var changed []string
+	if contact.EmergencyName != data.Name {
+		changed = append(changed, "name")
+	}
+	if contact.EmergencyPhone != data.Phone {
+		changed = append(changed, "phone")
+	}
+	if contact.EmergencyRelation != data.Relation {
+		changed = append(changed, "relation")
+	}
+	if len(changed) == 0 {
+		return contact, nil
+	}
+
+	contact.EmergencyName, contact.EmergencyPhone, contact.EmergencyRelation = data.Name, data.Phone, data.Relation
+	if err := uc.repo.Update(ctx, contact); err != nil {
+		return nil, err
+	}
+	uc.record(ctx, actor, domain.AuditEmergencyUpdated, &contact.ID, "changed: "+strings.Join(changed, ", "))
+	uc.logger.InfoContext(ctx, "Contact emergency info updated", slog.Uint64("contactID", uint64(contact.ID)), slog.Uint64("userID", uint64(actor.UserID)))
+	return contact, nil
+}
+
+func (uc *emergencyUseCase) ExportGroupCSV(ctx context.Context, w io.Writer, actor Actor, groupID uint, reason string) (*domain.Group, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+	if utf8.RuneCountInString(reason) > maxReasonLength {
+		return nil, ErrReasonTooLong
+	}
+
+	group, err := uc.groupUseCase.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	contacts, err := uc.repo.GetGroupContacts(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(contacts)+1)
+	rows = append(rows, []string{"Имя", "Телефон", "Экстренный контакт", "Телефон экстренного контакта", "Кем приходится"})
+	for _, ct := range contacts {
+		rows = append(rows, []string{ct.Name, ct.Phone, ct.EmergencyName, ct.EmergencyPhone, ct.EmergencyRelation})
+	}
+	// Выгрузка записывается в журнал до отдачи данных: если запись не удалась, это видно хотя бы в логе сервера
+	uc.record(ctx, actor, domain.AuditEmergencyExported, nil, fmt.Sprintf("group %d %q, contacts: %d, reason: %s", group.ID, group.Name, len(contacts), reason))
+
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to write emergency contacts CSV", slog.Uint64("groupID", uint64(groupID)), slog.Any("error", err))
+		return nil, err
+	}
+	return group, nil
+}
+
+func (uc *emergencyUseCase) get(ctx context.Context, contactID uint) (*domain.Contact, error) {
+	contact, err := uc.repo.Get(ctx, contactID)
+	if err != nil {
+		if errors.Is(err, repository.ErrContactNotFound) {
+			return nil, ErrContactNotFound
+		}
+		return nil, err
+	}
+	return contact, nil
+}
+
+func (uc *emergencyUseCase) record(ctx context.Context, actor Actor, action string, contactID *uint, details string) {
+	uc.auditor.Record(ctx, domain.AuditEvent{
+		Action:    action,
+		ActorID:   &actor.UserID,
+		ContactID: contactID,
+		IP:        actor.IP,
+		Details:   details,
+	})
+}
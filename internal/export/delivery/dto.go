@@ -0,0 +1,36 @@
+package delivery
+
+// SheetExportRequest задает таблицу для выгрузки; пустые поля берутся из конфигурации
+type SheetExportRequest struct {
+	SpreadsheetID string `json:"spreadsheet_id" validate:"max=200"`
+	Sheet         string `json:"sheet" validate:"max=100"`
+}
+
+// SheetExportResponse представляет результат выгрузки в Google Sheets
+type SheetExportResponse struct {
+	SpreadsheetID string `json:"spreadsheet_id"`
+	Sheet         string `json:"sheet"`
+	Rows          int    `json:"rows"`
+	ExportedAt    string `json:"exported_at"`
+}
+
+// ExportColumnDTO представляет столбец шаблона выгрузки
+type ExportColumnDTO struct {
+	Key   string `json:"key" validate:"required"`
+	Title string `json:"title" validate:"max=100"` // Пустой - заголовок по умолчанию
+}
+
+// ExportTemplateRequest представляет данные для создания или замены шаблона выгрузки
+type ExportTemplateRequest struct {
+	Name    string            `json:"name" validate:"required,min=1,max=100"`
+	Columns []ExportColumnDTO `json:"columns" validate:"required,min=1,dive"`
+}
+
+// ExportTemplateResponse представляет шаблон выгрузки
+type ExportTemplateResponse struct {
+	ID        uint              `json:"id"`
+	Name      string            `json:"name"`
+	Columns   []ExportColumnDTO `json:"columns"`
+	CreatedAt string            `json:"created_at"`
+	UpdatedAt string            `json:"updated_at"`
+}
@@ -0,0 +1,377 @@
+package delivery
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	"rim/internal/export/usecase"
+	"rim/pkg/requestctx"
+	"rim/pkg/tenant"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// mimeNDJSON - тип ответа потоковой выгрузки: JSON-объект на строку
+const mimeNDJSON = "application/x-ndjson"
+
+// Handler обрабатывает HTTP запросы выгрузки контактов
+type Handler struct {
+	exportUseCase usecase.UseCase
+	logger        *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для выгрузки контактов
+func NewHandler(exportUC usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		exportUseCase: exportUC,
+		logger:        logger,
+	}
+}
+
+// ExportToGoogleSheets выгружает контакты на лист Google Sheets
+// @Summary Выгрузить контакты в Google Sheets
+// @Description Заменяет содержимое листа контактами, подходящими под фильтр. Таблица должна быть открыта на редактирование для сервисного аккаунта.
+// @Tags exports
+// @Accept json
+// @Produce json
+// @Param target body SheetExportRequest false "Таблица и лист (по умолчанию из конфигурации)"
+// @Param skills query string false "Навыки через запятую"
+// @Param group_id query int false "ID группы"
+// @Param status query string false "Статус: active, on_leave или alumni"
+// @Param city query string false "Город"
+// @Param template_id query int false "ID шаблона столбцов"
+// @Success 200 {object} SheetExportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string "Шаблон не найден"
+// @Failure 502 {object} map[string]string "Ошибка Google Sheets"
+// @Failure 503 {object} map[string]string "Выгрузка не настроена"
+// @Router /exports/google-sheets [post]
+func (h *Handler) ExportToGoogleSheets(c *fiber.Ctx) error {
+	var req SheetExportRequest
+	if len(c.Body()) > 0 {
+		parsed, ok, err := validation.BindAndValidate[SheetExportRequest](c)
+		if !ok {
+			return err
+		}
+		req = parsed
+	}
+	filter, templateID, err := exportParamsFromQuery(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	result, err := h.exportUseCase.ExportToSheet(c.Context(), usecase.SheetTarget{SpreadsheetID: req.SpreadsheetID, Sheet: req.Sheet}, filter, templateID)
+	if err != nil {
+		if errors.Is(err, usecase.ErrSheetsNotConfigured) {
+			return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, usecase.ErrSpreadsheetRequired) || errors.Is(err, usecase.ErrTemplateNotFound) {
+			return h.exportError(c, err)
+		}
+		return c.Status(http.StatusBadGateway).JSON(fiber.Map{
+			"error": "Failed to export contacts to Google Sheets",
+		})
+	}
+	return c.JSON(SheetExportResponse{
+		SpreadsheetID: result.SpreadsheetID,
+		Sheet:         result.Sheet,
+		Rows:          result.Rows,
+		ExportedAt:    timeutil.Format(result.ExportedAt, requestctx.Location(c)),
+	})
+}
+
+// ExportCSV выгружает контакты в CSV-файл или потоком NDJSON
+// @Summary Выгрузить контакты в CSV
+// @Description Возвращает CSV (UTF-8 с BOM для Excel) с контактами, подходящими под фильтр, в столбцах выбранного шаблона.
+// @Description С заголовком Accept: application/x-ndjson отдает контакты потоком, по JSON-объекту "ключ столбца -> значение" на строку.
+// @Description Поток не держит весь справочник в памяти; ошибка посреди выгрузки обрывает ответ.
+// @Tags exports
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param skills query string false "Навыки через запятую"
+// @Param group_id query int false "ID группы"
+// @Param status query string false "Статус: active, on_leave или alumni"
+// @Param city query string false "Город"
+// @Param template_id query int false "ID шаблона столбцов"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string "Шаблон не найден"
+// @Failure 500 {object} map[string]string
+// @Router /exports/csv [get]
+func (h *Handler) ExportCSV(c *fiber.Ctx) error {
+	filter, templateID, err := exportParamsFromQuery(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if c.Accepts("text/csv", mimeNDJSON) == mimeNDJSON {
+		return h.exportNDJSON(c, filter, templateID)
+	}
+	var buf bytes.Buffer
+	buf.WriteString("\uFEFF")
+	if err := h.exportUseCase.ExportCSV(c.Context(), &buf, filter, templateID); err != nil {
+		return h.exportError(c, err)
+	}
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="contacts-%s.csv"`, timeutil.Now().Format("2006-01-02")))
+	return c.Send(buf.Bytes())
+}
+
+// exportNDJSON отдает контакты потоком NDJSON
+func (h *Handler) exportNDJSON(c *fiber.Ctx, filter contactUseCase.ContactFilter, templateID uint) error {
+	return h.stream(c, h.exportUseCase.ExportNDJSON, filter, templateID, mimeNDJSON, "ndjson")
+}
+
+// ExportContactsCSV выгружает контакты потоком CSV
+// @Summary Выгрузить контакты потоком CSV
+// @Description Отдает CSV (UTF-8 с BOM для Excel) со всеми контактами, подходящими под фильтры списка /contacts, в столбцах шаблона
+// @Description (по умолчанию - все столбцы, включая названия групп). Контакты читаются из БД пачками и сразу отправляются,
+// @Description поэтому выгрузка не держит весь справочник в памяти; ошибка посреди выгрузки обрывает ответ.
+// @Tags contacts
+// @Produce text/csv
+// @Param skills query string false "Навыки через запятую"
+// @Param group_id query int false "ID группы"
+// @Param status query string false "Статус: active, on_leave или alumni"
+// @Param city query string false "Город"
+// @Param q query string false "Поиск: подстрока имени, email, Telegram или VK либо телефон целиком"
+// @Param email query string false "Подстрока email"
+// @Param phone query string false "Телефон целиком в любом написании"
+// @Param has_telegram query bool false "true - только контакты с привязанным Telegram, false - только без него"
+// @Param filter query string false "Выражение фильтра, как в GET /contacts"
+// @Param sort query string false "Порядок, как в GET /contacts"
+// @Param include_archived query bool false "true - выгрузить и архивные контакты"
+// @Param template_id query int false "ID шаблона столбцов"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string "Шаблон не найден"
+// @Failure 500 {object} map[string]string
+// @Router /contacts/export [get]
+func (h *Handler) ExportContactsCSV(c *fiber.Ctx) error {
+	filter, templateID, err := exportParamsFromQuery(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return h.stream(c, h.exportUseCase.StreamCSV, filter, templateID, "text/csv", "csv")
+}
+
+type streamFunc func(ctx context.Context, filter contactUseCase.ContactFilter, templateID uint) (func(w io.Writer) error, error)
+
+// stream отдает выгрузку потоком файлом contacts-<дата>.<ext>. Шаблон проверяется до начала ответа, чтобы вернуть 404;
+// потом статус уже отправлен, и ошибка чтения только обрывает поток.
+func (h *Handler) stream(c *fiber.Ctx, export streamFunc, filter contactUseCase.ContactFilter, templateID uint, contentType, ext string) error {
+	// fiber.Ctx освобождается после возврата из обработчика, а поток пишется позже,
+	// поэтому выгрузка получает отдельный контекст с организацией запроса
+	ctx := tenant.With(context.Background(), tenant.FromContext(c.Context()))
+	write, err := export(ctx, filter, templateID)
+	if err != nil {
+		return h.exportError(c, err)
+	}
+	c.Set(fiber.HeaderContentType, contentType+"; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="contacts-%s.%s"`, timeutil.Now().Format("2006-01-02"), ext))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := write(w); err != nil {
+			h.logger.ErrorContext(ctx, "Contacts export stream aborted", slog.String("format", ext), slog.Any("error", err))
+		}
+	})
+	return nil
+}
+
+// GetColumns возвращает столбцы, доступные для шаблонов выгрузки
+// @Summary Получить доступные столбцы выгрузки
+// @Tags exports
+// @Produce json
+// @Success 200 {array} ExportColumnDTO "Ключ и заголовок по умолчанию"
+// @Router /exports/columns [get]
+func (h *Handler) GetColumns(c *fiber.Ctx) error {
+	resp := make([]ExportColumnDTO, len(usecase.Columns))
+	for i, col := range usecase.Columns {
+		resp[i] = ExportColumnDTO{Key: col.Key, Title: col.Title}
+	}
+	return c.JSON(resp)
+}
+
+// GetTemplates возвращает шаблоны выгрузки
+// @Summary Получить шаблоны выгрузки
+// @Tags exports
+// @Produce json
+// @Success 200 {array} ExportTemplateResponse
+// @Failure 500 {object} map[string]string
+// @Router /exports/templates [get]
+func (h *Handler) GetTemplates(c *fiber.Ctx) error {
+	templates, err := h.exportUseCase.GetTemplates(c.Context())
+	if err != nil {
+		return h.exportError(c, err)
+	}
+	loc := requestctx.Location(c)
+	resp := make([]ExportTemplateResponse, len(templates))
+	for i := range templates {
+		resp[i] = toTemplateResponse(&templates[i], loc)
+	}
+	return c.JSON(resp)
+}
+
+// CreateTemplate создает шаблон выгрузки
+// @Summary Создать шаблон выгрузки
+// @Description Столбцы выгружаются в указанном порядке; ключи - из /exports/columns
+// @Tags exports
+// @Accept json
+// @Produce json
+// @Param template body ExportTemplateRequest true "Шаблон"
+// @Success 201 {object} ExportTemplateResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string "Шаблон с таким именем уже существует"
+// @Failure 500 {object} map[string]string
+// @Router /exports/templates [post]
+func (h *Handler) CreateTemplate(c *fiber.Ctx) error {
+	data, ok, err := h.parseTemplateRequest(c)
+	if !ok {
+		return err
+	}
+	template, err := h.exportUseCase.CreateTemplate(c.Context(), data)
+	if err != nil {
+		return h.exportError(c, err)
+	}
+	return c.Status(http.StatusCreated).JSON(toTemplateResponse(template, requestctx.Location(c)))
+}
+
+// UpdateTemplate заменяет имя и столбцы шаблона выгрузки
+// @Summary Обновить шаблон выгрузки
+// @Tags exports
+// @Accept json
+// @Produce json
+// @Param id path int true "ID шаблона"
+// @Param template body ExportTemplateRequest true "Шаблон"
+// @Success 200 {object} ExportTemplateResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Шаблон с таким именем уже существует"
+// @Failure 500 {object} map[string]string
+// @Router /exports/templates/{id} [put]
+func (h *Handler) UpdateTemplate(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid template ID",
+		})
+	}
+	data, ok, err := h.parseTemplateRequest(c)
+	if !ok {
+		return err
+	}
+	template, err := h.exportUseCase.UpdateTemplate(c.Context(), uint(id), data)
+	if err != nil {
+		return h.exportError(c, err)
+	}
+	return c.JSON(toTemplateResponse(template, requestctx.Location(c)))
+}
+
+// DeleteTemplate удаляет шаблон выгрузки
+// @Summary Удалить шаблон выгрузки
+// @Tags exports
+// @Param id path int true "ID шаблона"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /exports/templates/{id} [delete]
+func (h *Handler) DeleteTemplate(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid template ID",
+		})
+	}
+	if err := h.exportUseCase.DeleteTemplate(c.Context(), uint(id)); err != nil {
+		return h.exportError(c, err)
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// parseTemplateRequest разбирает шаблон столбцов из тела запроса; ok=false - ответ 4xx уже отправлен клиенту
+func (h *Handler) parseTemplateRequest(c *fiber.Ctx) (usecase.TemplateData, bool, error) {
+	req, ok, err := validation.BindAndValidate[ExportTemplateRequest](c)
+	if !ok {
+		return usecase.TemplateData{}, false, err
+	}
+	data := usecase.TemplateData{Name: req.Name, Columns: make([]domain.ExportColumn, len(req.Columns))}
+	for i, col := range req.Columns {
+		data.Columns[i] = domain.ExportColumn{Key: col.Key, Title: col.Title}
+	}
+	return data, true, nil
+}
+
+// exportParamsFromQuery разбирает фильтр контактов и template_id из query-параметров
+func exportParamsFromQuery(c *fiber.Ctx) (contactUseCase.ContactFilter, uint, error) {
+	filter, err := contactUseCase.ParseContactFilter(func(key string) string { return c.Query(key) })
+	if err != nil {
+		return contactUseCase.ContactFilter{}, 0, err
+	}
+	var templateID uint
+	if raw := c.Query("template_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return contactUseCase.ContactFilter{}, 0, errors.New("invalid template ID")
+		}
+		templateID = uint(id)
+	}
+	return filter, templateID, nil
+}
+
+// exportError преобразует ошибки usecase в HTTP-ответ
+func (h *Handler) exportError(c *fiber.Ctx, err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrSpreadsheetRequired),
+		errors.Is(err, usecase.ErrTemplateNameEmpty),
+		errors.Is(err, usecase.ErrTemplateNoColumns),
+		errors.Is(err, usecase.ErrUnknownColumn),
+		errors.Is(err, usecase.ErrDuplicateColumn):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrTemplateNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrTemplateNameExists):
+		status = http.StatusConflict
+	default:
+		h.logger.ErrorContext(c.Context(), "Export operation failed", slog.Any("error", err))
+		return c.Status(status).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+func toTemplateResponse(template *domain.ExportTemplate, loc *time.Location) ExportTemplateResponse {
+	columns := template.ColumnList()
+	resp := ExportTemplateResponse{
+		ID:        template.ID,
+		Name:      template.Name,
+		Columns:   make([]ExportColumnDTO, len(columns)),
+		CreatedAt: timeutil.Format(template.CreatedAt, loc),
+		UpdatedAt: timeutil.Format(template.UpdatedAt, loc),
+	}
+	for i, col := range columns {
+		resp.Columns[i] = ExportColumnDTO{Key: col.Key, Title: col.Title}
+	}
+	return resp
+}
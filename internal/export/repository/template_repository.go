@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// Repository определяет интерфейс для хранения шаблонов выгрузки.
+type Repository interface {
+	Create(ctx context.Context, template *domain.ExportTemplate) error
+	GetByID(ctx context.Context, id uint) (*domain.ExportTemplate, error)
+	GetByName(ctx context.Context, name string) (*domain.ExportTemplate, error)
+	GetAll(ctx context.Context) ([]domain.ExportTemplate, error)
+	Update(ctx context.Context, template *domain.ExportTemplate) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр sqliteRepository для шаблонов выгрузки.
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *sqliteRepository) Create(ctx context.Context, template *domain.ExportTemplate) error {
+	if err := r.db.WithContext(ctx).Create(template).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error creating export template in DB", slog.String("name", template.Name), slog.Any("error", err))
+		return err
+	}
+	r.logger.InfoContext(ctx, "Successfully created export template in DB", slog.Uint64("templateID", uint64(template.ID)), slog.String("name", template.Name))
+	return nil
+}
+
+func (r *sqliteRepository) GetByID(ctx context.Context, id uint) (*domain.ExportTemplate, error) {
+	var template domain.ExportTemplate
+	if err := r.db.WithContext(ctx).First(&template, id).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.logger.ErrorContext(ctx, "Error getting export template by ID from DB", slog.Uint64("templateID", uint64(id)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *sqliteRepository) GetByName(ctx context.Context, name string) (*domain.ExportTemplate, error) {
+	var template domain.ExportTemplate
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&template).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.logger.ErrorContext(ctx, "Error getting export template by name from DB", slog.String("name", name), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *sqliteRepository) GetAll(ctx context.Context) ([]domain.ExportTemplate, error) {
+	var templates []domain.ExportTemplate
+	if err := r.db.WithContext(ctx).Order("name").Find(&templates).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting export templates from DB", slog.Any("error", err))
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (r *sqliteRepository) Update(ctx context.Context, template *domain.ExportTemplate) error {
+	if err := r.db.WithContext(ctx).Save(template).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error updating export template in DB", slog.Uint64("templateID", uint64(template.ID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&domain.ExportTemplate{}, id)
+	if result.Error != nil {
+		r.logger.ErrorContext(ctx, "Error deleting export template from DB", slog.Uint64("templateID", uint64(id)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
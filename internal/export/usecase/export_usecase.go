@@ -0,0 +1,319 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	"rim/internal/export/repository"
+	"rim/pkg/sheets"
+	"rim/pkg/timeutil"
+)
+
+var (
+	ErrSheetsNotConfigured = errors.New("google sheets export is not configured")
+	ErrSpreadsheetRequired = errors.New("spreadsheet id is required")
+)
+
+// streamBatchSize - сколько контактов читается из БД за один запрос при потоковой выгрузке
+const streamBatchSize = 500
+
+// DefaultSheet - лист, на который выгружаются контакты, если он не указан
+const DefaultSheet = "Contacts"
+
+// Column описывает столбец выгрузки контактов
+type Column struct {
+	Key   string
+	Title string
+	Value func(c *domain.Contact) string
+}
+
+// Columns - столбцы выгрузки в порядке следования
+var Columns = []Column{
+	{Key: "id", Title: "ID", Value: func(c *domain.Contact) string { return strconv.FormatUint(uint64(c.ID), 10) }},
+	{Key: "name", Title: "Имя", Value: func(c *domain.Contact) string { return c.Name }},
+	{Key: "status", Title: "Статус", Value: func(c *domain.Contact) string { return c.Status }},
+	{Key: "phone", Title: "Телефон", Value: func(c *domain.Contact) string { return c.Phone }},
+	{Key: "email", Title: "Email", Value: func(c *domain.Contact) string { return c.Email }},
+	{Key: "telegram", Title: "Telegram", Value: func(c *domain.Contact) string { return c.Telegram }},
+	{Key: "vk", Title: "VK", Value: func(c *domain.Contact) string { return c.VK }},
+	{Key: "groups", Title: "Группы", Value: func(c *domain.Contact) string {
+		names := make([]string, len(c.Groups))
+		for i, g := range c.Groups {
+			names[i] = g.Name
+		}
+		return strings.Join(names, ", ")
+	}},
+	{Key: "skills", Title: "Навыки", Value: func(c *domain.Contact) string {
+		names := make([]string, len(c.Skills))
+		for i, s := range c.Skills {
+			names[i] = s.Name
+		}
+		return strings.Join(names, ", ")
+	}},
+	{Key: "city", Title: "Город", Value: func(c *domain.Contact) string { return c.City }},
+	{Key: "campus", Title: "Кампус", Value: func(c *domain.Contact) string { return c.Campus }},
+	{Key: "building", Title: "Корпус", Value: func(c *domain.Contact) string { return c.Building }},
+	{Key: "room", Title: "Аудитория", Value: func(c *domain.Contact) string { return c.Room }},
+	{Key: "department", Title: "Отдел", Value: func(c *domain.Contact) string { return c.Department }},
+	{Key: "position", Title: "Должность", Value: func(c *domain.Contact) string { return c.Position }},
+	{Key: "transport", Title: "Транспорт", Value: func(c *domain.Contact) string { return c.Transport }},
+	{Key: "printer", Title: "Принтер", Value: func(c *domain.Contact) string { return c.Printer }},
+	{Key: "allergies", Title: "Аллергии", Value: func(c *domain.Contact) string { return c.Allergies }},
+	{Key: "updated_at", Title: "Обновлен", Value: func(c *domain.Contact) string { return timeutil.Format(c.UpdatedAt, time.UTC) }},
+}
+
+// columnByKey возвращает столбец выгрузки по ключу
+func columnByKey(key string) (Column, bool) {
+	for _, col := range Columns {
+		if col.Key == key {
+			return col, true
+		}
+	}
+	return Column{}, false
+}
+
+// SheetTarget определяет таблицу и лист для выгрузки
+type SheetTarget struct {
+	SpreadsheetID string
+	Sheet         string
+}
+
+// SheetExport описывает результат выгрузки
+type SheetExport struct {
+	SheetTarget
+	Rows       int // Количество контактов без строки заголовка
+	ExportedAt time.Time
+}
+
+// UseCase определяет интерфейс выгрузки контактов во внешние таблицы.
+type UseCase interface {
+	// ExportToSheet заменяет содержимое листа контактами, подходящими под фильтр.
+	// Пустые поля target заменяются значениями из конфигурации.
+	// templateID выбирает шаблон столбцов, 0 - все столбцы Columns.
+	ExportToSheet(ctx context.Context, target SheetTarget, filter contactUseCase.ContactFilter, templateID uint) (*SheetExport, error)
+	// ExportCSV пишет контакты, подходящие под фильтр, в w в формате CSV
+	ExportCSV(ctx context.Context, w io.Writer, filter contactUseCase.ContactFilter, templateID uint) error
+	// ExportNDJSON проверяет шаблон и возвращает функцию, которая пишет контакты в w по одному JSON-объекту
+	// "ключ столбца -> значение" на строку. Контакты читаются пачками, и после каждой пачки w сбрасывается,
+	// если у него есть Flush, поэтому память не растет с размером справочника.
+	ExportNDJSON(ctx context.Context, filter contactUseCase.ContactFilter, templateID uint) (func(w io.Writer) error, error)
+	// StreamCSV - то же для CSV: функция пишет BOM для Excel, строку заголовков и контакты, читая их пачками
+	StreamCSV(ctx context.Context, filter contactUseCase.ContactFilter, templateID uint) (func(w io.Writer) error, error)
+	// RunSchedule выполняет выгрузку каждые interval до отмены ctx
+	RunSchedule(ctx context.Context, interval time.Duration, filter contactUseCase.ContactFilter, templateID uint)
+
+	GetTemplates(ctx context.Context) ([]domain.ExportTemplate, error)
+	GetTemplate(ctx context.Context, id uint) (*domain.ExportTemplate, error)
+	CreateTemplate(ctx context.Context, data TemplateData) (*domain.ExportTemplate, error)
+	UpdateTemplate(ctx context.Context, id uint, data TemplateData) (*domain.ExportTemplate, error)
+	DeleteTemplate(ctx context.Context, id uint) error
+}
+
+type exportUseCase struct {
+	templateRepo   repository.Repository
+	contactUseCase contactUseCase.UseCase
+	writer         sheets.Writer // nil, если сервисный аккаунт не настроен
+	defaultTarget  SheetTarget
+	logger         *slog.Logger
+}
+
+// NewExportUseCase создает новый экземпляр exportUseCase.
+// writer может быть nil - тогда выгрузка возвращает ErrSheetsNotConfigured.
+func NewExportUseCase(templateRepo repository.Repository, cu contactUseCase.UseCase, writer sheets.Writer, defaultTarget SheetTarget, logger *slog.Logger) UseCase {
+	if defaultTarget.Sheet == "" {
+		defaultTarget.Sheet = DefaultSheet
+	}
+	return &exportUseCase{
+		templateRepo:   templateRepo,
+		contactUseCase: cu,
+		writer:         writer,
+		defaultTarget:  defaultTarget,
+		logger:         logger,
+	}
+}
+
+func (uc *exportUseCase) ExportToSheet(ctx context.Context, target SheetTarget, filter contactUseCase.ContactFilter, templateID uint) (*SheetExport, error) {
+	if uc.writer == nil {
+		return nil, ErrSheetsNotConfigured
+	}
+	if target.SpreadsheetID == "" {
+		target.SpreadsheetID = uc.defaultTarget.SpreadsheetID
+	}
+	if target.Sheet == "" {
+		target.Sheet = uc.defaultTarget.Sheet
+	}
+	if target.SpreadsheetID == "" {
+		return nil, ErrSpreadsheetRequired
+	}
+
+	rows, err := uc.buildRows(ctx, filter, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.writer.ReplaceValues(ctx, target.SpreadsheetID, target.Sheet, rows); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to export contacts to Google Sheets", slog.String("spreadsheetID", target.SpreadsheetID), slog.Any("error", err))
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Contacts exported to Google Sheets", slog.String("spreadsheetID", target.SpreadsheetID), slog.String("sheet", target.Sheet), slog.Int("rows", len(rows)-1))
+	return &SheetExport{SheetTarget: target, Rows: len(rows) - 1, ExportedAt: timeutil.Now()}, nil
+}
+
+func (uc *exportUseCase) ExportCSV(ctx context.Context, w io.Writer, filter contactUseCase.ContactFilter, templateID uint) error {
+	rows, err := uc.buildRows(ctx, filter, templateID)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to write contacts CSV", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (uc *exportUseCase) ExportNDJSON(ctx context.Context, filter contactUseCase.ContactFilter, templateID uint) (func(w io.Writer) error, error) {
+	columns, err := uc.templateColumns(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	return func(w io.Writer) error {
+		var line bytes.Buffer
+		rows := 0
+		err := uc.contactUseCase.EachContact(ctx, filter, streamBatchSize, func(contacts []domain.Contact) error {
+			for i := range contacts {
+				line.Reset()
+				writeNDJSONLine(&line, columns, &contacts[i])
+				if _, err := w.Write(line.Bytes()); err != nil {
+					return err
+				}
+			}
+			rows += len(contacts)
+			return flush(w)
+		})
+		if err != nil {
+			uc.logger.ErrorContext(ctx, "Failed to stream contacts NDJSON", slog.Int("rows", rows), slog.Any("error", err))
+			return err
+		}
+		uc.logger.InfoContext(ctx, "Contacts exported as NDJSON", slog.Int("rows", rows))
+		return nil
+	}, nil
+}
+
+func (uc *exportUseCase) StreamCSV(ctx context.Context, filter contactUseCase.ContactFilter, templateID uint) (func(w io.Writer) error, error) {
+	columns, err := uc.templateColumns(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	return func(w io.Writer) error {
+		if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+			return err
+		}
+		writer := csv.NewWriter(w)
+		row := make([]string, len(columns))
+		for i, col := range columns {
+			row[i] = col.Title
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+		rows := 0
+		err := uc.contactUseCase.EachContact(ctx, filter, streamBatchSize, func(contacts []domain.Contact) error {
+			for i := range contacts {
+				for j, col := range columns {
+					row[j] = col.Value(&contacts[i])
+				}
+				if err := writer.Write(row); err != nil {
+					return err
+				}
+			}
+			rows += len(contacts)
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+			return flush(w)
+		})
+		if err != nil {
+			uc.logger.ErrorContext(ctx, "Failed to stream contacts CSV", slog.Int("rows", rows), slog.Any("error", err))
+			return err
+		}
+		uc.logger.InfoContext(ctx, "Contacts exported as CSV stream", slog.Int("rows", rows))
+		return nil
+	}, nil
+}
+
+// flush отправляет клиенту записанное в w, если w буферизован
+func flush(w io.Writer) error {
+	if flusher, ok := w.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// writeNDJSONLine пишет контакт в buf JSON-объектом с полями в порядке столбцов шаблона и переводом строки
+func writeNDJSONLine(buf *bytes.Buffer, columns []Column, contact *domain.Contact) {
+	buf.WriteByte('{')
+	for i, col := range columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(col.Key)
+		value, _ := json.Marshal(col.Value(contact))
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteString("}\n")
+}
+
+// buildRows возвращает строку заголовков и строки контактов по столбцам шаблона
+func (uc *exportUseCase) buildRows(ctx context.Context, filter contactUseCase.ContactFilter, templateID uint) ([][]string, error) {
+	columns, err := uc.templateColumns(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	contacts, err := uc.contactUseCase.GetAllContacts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(contacts)+1)
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Title
+	}
+	rows = append(rows, header)
+	for i := range contacts {
+		row := make([]string, len(columns))
+		for j, col := range columns {
+			row[j] = col.Value(&contacts[i])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (uc *exportUseCase) RunSchedule(ctx context.Context, interval time.Duration, filter contactUseCase.ContactFilter, templateID uint) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// Ошибки уже залогированы в ExportToSheet, следующая попытка - по расписанию
+		_, _ = uc.ExportToSheet(ctx, SheetTarget{}, filter, templateID)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"rim/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTemplateNotFound   = errors.New("export template not found")
+	ErrTemplateNameEmpty  = errors.New("export template name cannot be empty")
+	ErrTemplateNameExists = errors.New("export template with this name already exists")
+	ErrTemplateNoColumns  = errors.New("export template must have at least one column")
+	ErrUnknownColumn      = errors.New("unknown export column")
+	ErrDuplicateColumn    = errors.New("export column is used more than once")
+)
+
+// TemplateData определяет данные для создания или замены шаблона выгрузки.
+// Пустой Title столбца заменяется заголовком по умолчанию.
+type TemplateData struct {
+	Name    string
+	Columns []domain.ExportColumn
+}
+
+// templateColumns возвращает столбцы шаблона с заголовками из шаблона; templateID 0 - все столбцы
+func (uc *exportUseCase) templateColumns(ctx context.Context, templateID uint) ([]Column, error) {
+	if templateID == 0 {
+		return Columns, nil
+	}
+	template, err := uc.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	var columns []Column
+	for _, tc := range template.ColumnList() {
+		col, ok := columnByKey(tc.Key)
+		if !ok {
+			// Столбец мог быть удален из кода после сохранения шаблона
+			continue
+		}
+		col.Title = tc.Title
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+func (uc *exportUseCase) GetTemplates(ctx context.Context) ([]domain.ExportTemplate, error) {
+	return uc.templateRepo.GetAll(ctx)
+}
+
+func (uc *exportUseCase) GetTemplate(ctx context.Context, id uint) (*domain.ExportTemplate, error) {
+	template, err := uc.templateRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+func (uc *exportUseCase) CreateTemplate(ctx context.Context, data TemplateData) (*domain.ExportTemplate, error) {
+	template := &domain.ExportTemplate{}
+	if err := uc.applyTemplateData(ctx, template, data); err != nil {
+		return nil, err
+	}
+	if err := uc.templateRepo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (uc *exportUseCase) UpdateTemplate(ctx context.Context, id uint, data TemplateData) (*domain.ExportTemplate, error) {
+	template, err := uc.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.applyTemplateData(ctx, template, data); err != nil {
+		return nil, err
+	}
+	if err := uc.templateRepo.Update(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (uc *exportUseCase) DeleteTemplate(ctx context.Context, id uint) error {
+	if err := uc.templateRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTemplateNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// applyTemplateData проверяет данные шаблона и переносит их в template
+func (uc *exportUseCase) applyTemplateData(ctx context.Context, template *domain.ExportTemplate, data TemplateData) error {
+	name := strings.TrimSpace(data.Name)
+	if name == "" {
+		return ErrTemplateNameEmpty
+	}
+	if existing, err := uc.templateRepo.GetByName(ctx, name); err == nil && existing.ID != template.ID {
+		return ErrTemplateNameExists
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if len(data.Columns) == 0 {
+		return ErrTemplateNoColumns
+	}
+
+	columns := make([]domain.ExportColumn, len(data.Columns))
+	seen := map[string]bool{}
+	for i, tc := range data.Columns {
+		col, ok := columnByKey(tc.Key)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownColumn, tc.Key)
+		}
+		if seen[tc.Key] {
+			return fmt.Errorf("%w: %s", ErrDuplicateColumn, tc.Key)
+		}
+		seen[tc.Key] = true
+		title := strings.TrimSpace(tc.Title)
+		if title == "" {
+			title = col.Title
+		}
+		columns[i] = domain.ExportColumn{Key: tc.Key, Title: title}
+	}
+
+	template.Name = name
+	return template.SetColumns(columns)
+}
@@ -0,0 +1,86 @@
+package delivery
+
+import (
+	"encoding/json"
+	"log/slog"
+
+	"rim/internal/graphql/usecase"
+	"rim/pkg/graphql"
+	"rim/pkg/requestctx"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler отвечает за обработку запросов GraphQL.
+type Handler struct {
+	graphqlUseCase usecase.UseCase
+	logger         *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для GraphQL.
+func NewHandler(graphqlUseCase usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		graphqlUseCase: graphqlUseCase,
+		logger:         logger,
+	}
+}
+
+// Query выполняет запрос GraphQL.
+// @Summary Запрос GraphQL
+// @Description Только чтение: contacts, contact, groups, group с вложенными выборками (группы контакта, участники группы, связи).
+// @Description Поля контактов фильтруются политикой доступа так же, как в REST; скрытые поля равны null, гостям доступны только id и name.
+// @Description Вложенные списки загружаются одним запросом на уровень. Схема - GET /graphql/schema.
+// @Description Запрос передается в теле {"query", "variables", "operationName"} или в параметрах GET с variables в виде JSON.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param request body graphql.Request false "Запрос GraphQL (для POST)"
+// @Param query query string false "Текст запроса (для GET)"
+// @Param variables query string false "Переменные в виде JSON (для GET)"
+// @Param operationName query string false "Имя выполняемой операции (для GET)"
+// @Success 200 {object} object "data и errors; ошибки отдельных полей не меняют статус"
+// @Failure 400 {object} object "Запрос не разобран или не прошел проверку по схеме; data отсутствует"
+// @Router /graphql [post]
+// @Router /graphql [get]
+func (h *Handler) Query(c *fiber.Ctx) error {
+	var req graphql.Request
+	if c.Method() == fiber.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(errorResponse("variables must be a JSON object"))
+			}
+		}
+	} else if err := c.BodyParser(&req); err != nil {
+		h.logger.WarnContext(c.Context(), "Failed to parse GraphQL request body", slog.Any("error", err))
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse("Invalid request body"))
+	}
+	if req.Query == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse("query is required"))
+	}
+
+	resp := h.graphqlUseCase.Execute(c.Context(), requestctx.Role(c), requestctx.Location(c), req)
+	status := fiber.StatusOK
+	if !resp.Executed() {
+		status = fiber.StatusBadRequest
+	}
+	return c.Status(status).JSON(resp)
+}
+
+// Schema отдает схему GraphQL.
+// @Summary Схема GraphQL
+// @Description Схема на языке определения схем (SDL) для генерации типов клиента.
+// @Tags graphql
+// @Produce plain
+// @Success 200 {string} string
+// @Router /graphql/schema [get]
+func (h *Handler) Schema(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	return c.SendString(h.graphqlUseCase.SDL())
+}
+
+// errorResponse - ответ на запрос, не дошедший до выполнения, в формате ошибок GraphQL
+func errorResponse(message string) *graphql.Response {
+	return &graphql.Response{Errors: []*graphql.Error{{Message: message}}}
+}
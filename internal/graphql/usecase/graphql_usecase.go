@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	groupUseCase "rim/internal/group/usecase"
+	policyUseCase "rim/internal/policy/usecase"
+	"rim/pkg/graphql"
+)
+
+// MaxQueryDepth - наибольшая вложенность запроса; ограничивает стоимость запросов вида groups{members{groups{...}}}
+const MaxQueryDepth = 8
+
+var (
+	ErrAccessDenied = errors.New("access denied")
+	ErrInvalidID    = errors.New("invalid ID")
+	// errInternal заменяет в ответе внутренние ошибки, чтобы не раскрывать детали хранилища
+	errInternal = errors.New("internal server error")
+)
+
+// UseCase выполняет запросы GraphQL к справочнику контактов и групп.
+type UseCase interface {
+	// Execute выполняет запрос от имени роли; время в ответе выводится в часовом поясе loc
+	Execute(ctx context.Context, role string, loc *time.Location, req graphql.Request) *graphql.Response
+	// SDL возвращает схему на языке определения схем GraphQL
+	SDL() string
+}
+
+type graphqlUseCase struct {
+	schema   *graphql.Schema
+	contacts contactUseCase.UseCase
+	groups   groupUseCase.UseCase
+	policy   policyUseCase.UseCase
+	logger   *slog.Logger
+}
+
+// NewGraphQLUseCase создает новый экземпляр graphqlUseCase и строит схему.
+func NewGraphQLUseCase(cu contactUseCase.UseCase, gu groupUseCase.UseCase, pu policyUseCase.UseCase, logger *slog.Logger) (UseCase, error) {
+	uc := &graphqlUseCase{
+		contacts: cu,
+		groups:   gu,
+		policy:   pu,
+		logger:   logger,
+	}
+	schema, err := graphql.NewSchema(uc.queryType())
+	if err != nil {
+		return nil, err
+	}
+	schema.MaxDepth = MaxQueryDepth
+	uc.schema = schema
+	return uc, nil
+}
+
+// viewer - кто выполняет запрос; резолверы получают его из контекста
+type viewer struct {
+	role string
+	// fields - поля контакта, которые роль может читать по правилам "contact.<поле>"
+	fields map[string]bool
+	loc    *time.Location
+}
+
+type viewerKey struct{}
+
+func viewerFrom(ctx context.Context) *viewer {
+	v, _ := ctx.Value(viewerKey{}).(*viewer)
+	return v
+}
+
+// canRead сообщает, видно ли роли поле контакта ct. Пустое field - поле без отдельного правила,
+// скрытое только от гостей. Остальные поля, как и в REST, видны по правилам политики с поправкой
+// на уровни видимости, заданные контактом: гости видят кроме ID и имени только открытые всем поля.
+func (v *viewer) canRead(ct *domain.Contact, field string) bool {
+	if field == "" {
+		return v.role != domain.RoleGuest
+	}
+	return contactUseCase.VisibleFields(ct, v.role, v.fields)[field]
+}
+
+func (uc *graphqlUseCase) Execute(ctx context.Context, role string, loc *time.Location, req graphql.Request) *graphql.Response {
+	fields, err := uc.policy.AllowedFields(ctx, role, policyUseCase.ContactFieldsPrefix, contactPolicyFields)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to evaluate contact field policy", slog.String("role", role), slog.Any("error", err))
+		return &graphql.Response{Errors: []*graphql.Error{{Message: errInternal.Error()}}}
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	ctx = context.WithValue(ctx, viewerKey{}, &viewer{role: role, fields: fields, loc: loc})
+
+	resp := uc.schema.Execute(ctx, req)
+	if len(resp.Errors) > 0 {
+		uc.logger.InfoContext(ctx, "GraphQL query finished with errors", slog.String("role", role), slog.String("errors", resp.String()))
+	}
+	return resp
+}
+
+func (uc *graphqlUseCase) SDL() string {
+	return uc.schema.SDL()
+}
+
+// internal записывает ошибку в журнал и возвращает обезличенную ошибку для ответа.
+func (uc *graphqlUseCase) internal(ctx context.Context, msg string, err error) error {
+	uc.logger.ErrorContext(ctx, msg, slog.Any("error", err))
+	return errInternal
+}
+
+func parseID(value interface{}) (uint, error) {
+	s, _ := value.(string)
+	id, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || id == 0 {
+		return 0, ErrInvalidID
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,325 @@
+package usecase
+
+import (
+	"errors"
+	"strconv"
+
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	groupUseCase "rim/internal/group/usecase"
+	policyUseCase "rim/internal/policy/usecase"
+	"rim/pkg/graphql"
+	"rim/pkg/timeutil"
+)
+
+// contactPolicyFields - поля контакта с правилами "contact.<поле>", те же, что фильтрует REST
+var contactPolicyFields = []string{"phone", "email", "transport", "printer", "allergies", "vk", "telegram", "telegram_id", "groups", "skills", "relations", "location", "department", "position"}
+
+// Направления связи относительно контакта, как в REST
+const (
+	relationOutgoing = "outgoing"
+	relationIncoming = "incoming"
+)
+
+// relationView - связь контакта в выдаче: related - ID второго контакта
+type relationView struct {
+	relation  domain.ContactRelation
+	direction string
+	related   uint
+}
+
+// queryType строит корневой тип схемы. Contact и Group ссылаются друг на друга,
+// поэтому поля объектов задаются после создания самих типов.
+func (uc *graphqlUseCase) queryType() *graphql.Object {
+	contactType := &graphql.Object{Name: "Contact", Description: "Контакт; поля, скрытые политикой доступа, равны null"}
+	groupType := &graphql.Object{Name: "Group", Description: "Группа контактов"}
+	relationType := &graphql.Object{Name: "Relation", Description: "Связь между контактами"}
+
+	contactList := graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(contactType)))
+	groupList := graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(groupType)))
+
+	contactType.Fields = []*graphql.Field{
+		{Name: "id", Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*domain.Contact).ID, nil
+		}},
+		{Name: "name", Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*domain.Contact).Name, nil
+		}},
+		contactField("status", "", graphql.String, func(ct *domain.Contact, _ *viewer) interface{} { return ct.Status }),
+		contactField("phone", "phone", graphql.String, func(ct *domain.Contact, _ *viewer) interface{} { return ct.Phone }),
+		contactField("email", "email", graphql.String, func(ct *domain.Contact, _ *viewer) interface{} { return ct.Email }),
+		contactField("emailVerified", "email", graphql.Boolean, func(ct *domain.Contact, _ *viewer) interface{} { return ct.EmailVerified }),
+		contactField("transport", "transport", graphql.String, func(ct *domain.Contact, _ *viewer) interface{} { return ct.Transport }),
+		contactField("printer", "printer", graphql.String, func(ct *domain.Contact, _ *viewer) interface{} { return ct.Printer }),
+		contactField("allergies", "allergies", graphql.String, func(ct *domain.Contact, _ *viewer) interface{} { return ct.Allergies }),
+		contactField("vk", "vk", graphql.String, func(ct *domain.Contact, _ *viewer) interface{} { return ct.VK }),
+		contactField("telegram", "telegram", graphql.String, func(ct *domain.Contact, _ *viewer) interface{} { return ct.Telegram }),
+		// ID Telegram не помещается в 32-битный Int, поэтому передается как ID
+		contactField("telegramId", "telegram_id", graphql.ID, func(ct *domain.Contact, _ *viewer) interface{} {
+			if ct.TelegramID == 0 {
+				return nil
+			}
+			return strconv.FormatInt(ct.TelegramID, 10)
+		}),
+		contactField("city", "location", graphql.String, func(ct *domain.Contact, _ *viewer) interface{} { return ct.City }),
+		contactField("campus", "location", graphql.String, func(ct *domain.Contact, _ *viewer) interface{} { return ct.Campus }),
+		contactField("building", "location", graphql.String, func(ct *domain.Contact, _ *viewer) interface{} { return ct.Building }),
+		contactField("room", "location", graphql.String, func(ct *domain.Contact, _ *viewer) interface{} { return ct.Room }),
+		contactField("department", "department", graphql.String, func(ct *domain.Contact, _ *viewer) interface{} { return ct.Department }),
+		contactField("position", "position", graphql.String, func(ct *domain.Contact, _ *viewer) interface{} { return ct.Position }),
+		contactField("createdAt", "", graphql.String, func(ct *domain.Contact, v *viewer) interface{} {
+			return timeutil.Format(ct.CreatedAt, v.loc)
+		}),
+		contactField("updatedAt", "", graphql.String, func(ct *domain.Contact, v *viewer) interface{} {
+			return timeutil.Format(ct.UpdatedAt, v.loc)
+		}),
+		contactField("groups", "groups", graphql.NewList(graphql.NewNonNull(groupType)), func(ct *domain.Contact, _ *viewer) interface{} {
+			return append([]*domain.Group{}, ct.Groups...)
+		}),
+		contactField("skills", "skills", graphql.NewList(graphql.NewNonNull(graphql.String)), func(ct *domain.Contact, _ *viewer) interface{} {
+			names := make([]string, len(ct.Skills))
+			for i, sk := range ct.Skills {
+				names[i] = sk.Name
+			}
+			return names
+		}),
+		contactField("relations", "relations", graphql.NewList(graphql.NewNonNull(relationType)), func(ct *domain.Contact, _ *viewer) interface{} {
+			views := []*relationView{}
+			for _, rel := range ct.Relations {
+				views = append(views, &relationView{relation: rel, direction: relationOutgoing, related: rel.ToContactID})
+			}
+			for _, rel := range ct.InverseRelations {
+				views = append(views, &relationView{relation: rel, direction: relationIncoming, related: rel.FromContactID})
+			}
+			return views
+		}),
+	}
+
+	relationType.Fields = []*graphql.Field{
+		{Name: "id", Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*relationView).relation.ID, nil
+		}},
+		{Name: "type", Description: "mentor, manager или emergency_contact", Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*relationView).relation.Type, nil
+		}},
+		{Name: "direction", Description: "outgoing - связанный контакт является type для этого, incoming - наоборот", Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*relationView).direction, nil
+		}},
+		{Name: "contact", Description: "Второй контакт связи; все связанные контакты уровня загружаются одним запросом", Type: contactType, Batch: uc.relatedContacts},
+	}
+
+	groupType.Fields = []*graphql.Field{
+		{Name: "id", Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*domain.Group).ID, nil
+		}},
+		{Name: "name", Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*domain.Group).Name, nil
+		}},
+		{Name: "isOpen", Description: "Группа принимает заявки на вступление", Type: graphql.NewNonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*domain.Group).IsOpen, nil
+		}},
+		{Name: "createdAt", Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return timeutil.Format(p.Source.(*domain.Group).CreatedAt, viewerFrom(p.Context).loc), nil
+		}},
+		{Name: "updatedAt", Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return timeutil.Format(p.Source.(*domain.Group).UpdatedAt, viewerFrom(p.Context).loc), nil
+		}},
+		{
+			Name:        "members",
+			Description: "Участники группы; участники всех групп уровня загружаются одним запросом",
+			Type:        contactList,
+			Args:        []*graphql.Argument{{Name: "status", Description: "active, on_leave или alumni", Type: graphql.String}},
+			Batch:       uc.groupMembers,
+		},
+	}
+
+	return &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.Field{
+			{
+				Name:        "contacts",
+				Description: "Контакты, подходящие под все заданные условия",
+				Type:        contactList,
+				Args: []*graphql.Argument{
+					{Name: "groupId", Type: graphql.ID},
+					{Name: "status", Description: "active, on_leave или alumni", Type: graphql.String},
+					{Name: "skills", Description: "Контакты, обладающие хотя бы одним из навыков", Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+					{Name: "city", Type: graphql.String},
+					{Name: "campus", Type: graphql.String},
+					{Name: "building", Type: graphql.String},
+					{Name: "room", Type: graphql.String},
+					{Name: "department", Type: graphql.String},
+				},
+				Resolve: uc.resolveContacts,
+			},
+			{
+				Name:        "contact",
+				Description: "Контакт по ID; null, если не найден. Требует права contacts:read",
+				Type:        contactType,
+				Args:        []*graphql.Argument{{Name: "id", Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve:     uc.resolveContact,
+			},
+			{
+				Name:    "groups",
+				Type:    groupList,
+				Resolve: uc.resolveGroups,
+			},
+			{
+				Name:        "group",
+				Description: "Группа по ID; null, если не найдена",
+				Type:        groupType,
+				Args:        []*graphql.Argument{{Name: "id", Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve:     uc.resolveGroup,
+			},
+		},
+	}
+}
+
+// contactField описывает поле контакта, видимое роли с правом на поле policy, если контакт не скрыл его от роли.
+func contactField(name, policy string, typ graphql.Type, value func(ct *domain.Contact, v *viewer) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Name: name,
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			v := viewerFrom(p.Context)
+			ct := p.Source.(*domain.Contact)
+			if !v.canRead(ct, policy) {
+				return nil, nil
+			}
+			return value(ct, v), nil
+		},
+	}
+}
+
+func (uc *graphqlUseCase) resolveContacts(p graphql.ResolveParams) (interface{}, error) {
+	var filter contactUseCase.ContactFilter
+	if groupID, ok := p.Args["groupId"]; ok && groupID != nil {
+		id, err := parseID(groupID)
+		if err != nil {
+			return nil, err
+		}
+		filter.GroupID = id
+	}
+	for arg, field := range map[string]*string{"status": &filter.Status, "city": &filter.City, "campus": &filter.Campus, "building": &filter.Building, "room": &filter.Room, "department": &filter.Department} {
+		if value, ok := p.Args[arg].(string); ok {
+			*field = value
+		}
+	}
+	if skills, ok := p.Args["skills"].([]interface{}); ok {
+		for _, skill := range skills {
+			filter.Skills = append(filter.Skills, skill.(string))
+		}
+	}
+
+	// Отбор по группе, навыкам, местоположению и отделу не должен раскрывать значения полей, скрытых от роли
+	if err := uc.contacts.CheckFilterAccess(p.Context, viewerFrom(p.Context).role, &filter); err != nil {
+		if errors.Is(err, contactUseCase.ErrFilterFieldDenied) {
+			return nil, err
+		}
+		return nil, uc.internal(p.Context, "Failed to check GraphQL contact filter", err)
+	}
+	contacts, err := uc.contacts.GetAllContacts(p.Context, filter)
+	if err != nil {
+		return nil, uc.internal(p.Context, "Failed to get contacts for GraphQL query", err)
+	}
+	return contacts, nil
+}
+
+func (uc *graphqlUseCase) resolveContact(p graphql.ResolveParams) (interface{}, error) {
+	allowed, err := uc.policy.IsAllowed(p.Context, viewerFrom(p.Context).role, policyUseCase.ResourceContacts, policyUseCase.ActionRead)
+	if err != nil {
+		return nil, uc.internal(p.Context, "Failed to check contact read permission", err)
+	}
+	if !allowed {
+		return nil, ErrAccessDenied
+	}
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	contact, err := uc.contacts.GetContactByID(p.Context, id)
+	if errors.Is(err, contactUseCase.ErrContactNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, uc.internal(p.Context, "Failed to get contact for GraphQL query", err)
+	}
+	return contact, nil
+}
+
+func (uc *graphqlUseCase) resolveGroups(p graphql.ResolveParams) (interface{}, error) {
+	groups, err := uc.groups.GetAllGroups(p.Context)
+	if err != nil {
+		return nil, uc.internal(p.Context, "Failed to get groups for GraphQL query", err)
+	}
+	return groups, nil
+}
+
+func (uc *graphqlUseCase) resolveGroup(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	group, err := uc.groups.GetGroupByID(p.Context, id)
+	if errors.Is(err, groupUseCase.ErrGroupNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, uc.internal(p.Context, "Failed to get group for GraphQL query", err)
+	}
+	return group, nil
+}
+
+// groupMembers загружает участников всех групп уровня одним запросом и раскладывает их по группам.
+func (uc *graphqlUseCase) groupMembers(p graphql.BatchParams) ([]interface{}, error) {
+	groupIDs := make([]uint, len(p.Sources))
+	for i, source := range p.Sources {
+		groupIDs[i] = source.(*domain.Group).ID
+	}
+	filter := contactUseCase.ContactFilter{GroupIDs: groupIDs}
+	if status, ok := p.Args["status"].(string); ok {
+		filter.Status = status
+	}
+	contacts, err := uc.contacts.GetAllContacts(p.Context, filter)
+	if err != nil {
+		return nil, uc.internal(p.Context, "Failed to get group members for GraphQL query", err)
+	}
+
+	members := make(map[uint][]*domain.Contact, len(groupIDs))
+	for i := range contacts {
+		for _, g := range contacts[i].Groups {
+			members[g.ID] = append(members[g.ID], &contacts[i])
+		}
+	}
+	out := make([]interface{}, len(groupIDs))
+	for i, id := range groupIDs {
+		out[i] = append([]*domain.Contact{}, members[id]...)
+	}
+	return out, nil
+}
+
+// relatedContacts загружает вторые контакты всех связей уровня одним запросом.
+func (uc *graphqlUseCase) relatedContacts(p graphql.BatchParams) ([]interface{}, error) {
+	ids := make([]uint, len(p.Sources))
+	for i, source := range p.Sources {
+		ids[i] = source.(*relationView).related
+	}
+	// Пустой список ID не ограничивает выборку, поэтому без связей запрос не выполняется
+	byID := map[uint]*domain.Contact{}
+	if len(ids) > 0 {
+		contacts, err := uc.contacts.GetAllContacts(p.Context, contactUseCase.ContactFilter{IDs: ids, IncludeArchived: true})
+		if err != nil {
+			return nil, uc.internal(p.Context, "Failed to get related contacts for GraphQL query", err)
+		}
+		for i := range contacts {
+			byID[contacts[i].ID] = &contacts[i]
+		}
+	}
+	out := make([]interface{}, len(ids))
+	for i, id := range ids {
+		if ct := byID[id]; ct != nil {
+			out[i] = ct
+		}
+	}
+	return out, nil
+}
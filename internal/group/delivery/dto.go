@@ -1,23 +1,67 @@
 package delivery
 
-import "time"
-
 // CreateGroupRequest определяет структуру для запроса на создание группы.
 type CreateGroupRequest struct {
-	Name string `json:"name" validate:"required,min=1,max=100"` // Добавили валидацию
+	Name   string `json:"name" validate:"required,min=1,max=100"` // Добавили валидацию
+	IsOpen bool   `json:"is_open"`                                // Разрешить заявки на вступление
 }
 
 // UpdateGroupRequest определяет структуру для запроса на обновление группы.
 type UpdateGroupRequest struct {
-	Name string `json:"name" validate:"required,min=1,max=100"` // Добавили валидацию
+	Name   string `json:"name" validate:"required,min=1,max=100"` // Добавили валидацию
+	IsOpen *bool  `json:"is_open"`                                // Не передан - не меняется
 }
 
 // GroupResponse определяет структуру для ответа с информацией о группе.
 type GroupResponse struct {
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	IsOpen bool   `json:"is_open"`
+	// MembershipExpiresAt - срок членства контакта; заполняется только в группах контакта
+	MembershipExpiresAt string `json:"membership_expires_at,omitempty"`
+	CreatedAt           string `json:"created_at"` // RFC3339 со смещением
+	UpdatedAt           string `json:"updated_at"` // RFC3339 со смещением
+}
+
+// JoinGroupRequest определяет структуру заявки на вступление в группу.
+type JoinGroupRequest struct {
+	Message string `json:"message" validate:"max=500"`
+}
+
+// ReviewJoinRequest определяет структуру решения по заявке на вступление.
+type ReviewJoinRequest struct {
+	Comment string `json:"comment" validate:"max=500"`
+}
+
+// JoinRequestResponse определяет структуру ответа с заявкой на вступление в группу.
+type JoinRequestResponse struct {
+	ID            uint   `json:"id"`
+	GroupID       uint   `json:"group_id"`
+	GroupName     string `json:"group_name"`
+	ContactID     uint   `json:"contact_id"`
+	ContactName   string `json:"contact_name"`
+	UserID        uint   `json:"user_id"`
+	Message       string `json:"message,omitempty"`
+	Status        string `json:"status"`
+	ReviewedBy    *uint  `json:"reviewed_by,omitempty"`
+	ReviewComment string `json:"review_comment,omitempty"`
+	CreatedAt     string `json:"created_at"`            // RFC3339 со смещением
+	ReviewedAt    string `json:"reviewed_at,omitempty"` // RFC3339 со смещением
+}
+
+// AddModeratorRequest определяет структуру запроса на назначение модератора группы.
+type AddModeratorRequest struct {
+	UserID uint `json:"user_id" validate:"required"`
+}
+
+// ModeratorResponse определяет структуру ответа с модератором группы.
+type ModeratorResponse struct {
+	GroupID   uint   `json:"group_id"`
+	UserID    uint   `json:"user_id"`
+	ContactID *uint  `json:"contact_id,omitempty"`
+	Name      string `json:"name"` // Имя контакта пользователя
+	CreatedBy *uint  `json:"created_by,omitempty"`
+	CreatedAt string `json:"created_at"` // RFC3339 со смещением
 }
 
 // ErrorResponse определяет общую структуру для ответа с ошибкой.
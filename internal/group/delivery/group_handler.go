@@ -2,14 +2,15 @@ package delivery
 
 import (
 	"errors"
-	"fmt"
 	"log/slog"
 	"strconv"
+	"time"
 
 	"rim/internal/domain"
 	"rim/internal/group/usecase"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -17,7 +18,6 @@ import (
 type Handler struct {
 	groupUseCase usecase.UseCase
 	logger       *slog.Logger
-	validate     *validator.Validate
 }
 
 // NewHandler создает новый экземпляр Handler.
@@ -25,36 +25,30 @@ func NewHandler(groupUC usecase.UseCase, logger *slog.Logger) *Handler {
 	return &Handler{
 		groupUseCase: groupUC,
 		logger:       logger,
-		validate:     validator.New(),
 	}
 }
 
 // CreateGroup обрабатывает запрос на создание новой группы.
 // @Summary Создать новую группу
-// @Description Создает новую группу с указанным именем.
+// @Description Создает новую группу с указанным именем. Открытые группы (is_open) принимают заявки на вступление.
 // @Tags groups
 // @Accept json
 // @Produce json
 // @Param group body CreateGroupRequest true "Данные для создания группы"
 // @Success 201 {object} GroupResponse "Группа успешно создана"
-// @Failure 400 {object} ErrorResponse "Ошибка валидации или некорректный запрос"
+// @Failure 400 {object} validation.Response "Ошибка валидации или некорректный запрос"
+// @Failure 401 {object} ErrorResponse "Требуется авторизация"
+// @Failure 403 {object} ErrorResponse "Нет прав на управление группами"
 // @Failure 409 {object} ErrorResponse "Группа с таким именем уже существует"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /groups [post]
 func (h *Handler) CreateGroup(c *fiber.Ctx) error {
-	var req CreateGroupRequest
-	if err := c.BodyParser(&req); err != nil {
-		h.logger.Warn("Failed to parse request body for create group", slog.Any("error", err))
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid request body"})
+	req, ok, err := validation.BindAndValidate[CreateGroupRequest](c)
+	if !ok {
+		return err
 	}
 
-	if err := h.validate.Struct(req); err != nil {
-		h.logger.Warn("Validation failed for create group request", slog.Any("error", err))
-		// Можно вернуть более детализированные ошибки валидации
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Message: fmt.Sprintf("Validation failed: %s", err.Error())})
-	}
-
-	group, err := h.groupUseCase.CreateGroup(c.Context(), req.Name)
+	group, err := h.groupUseCase.CreateGroup(c.Context(), req.Name, req.IsOpen)
 	if err != nil {
 		if errors.Is(err, usecase.ErrGroupNameEmpty) || errors.Is(err, usecase.ErrGroupNameExists) {
 			h.logger.Warn("Failed to create group due to business rule violation", slog.String("name", req.Name), slog.Any("error", err))
@@ -127,14 +121,16 @@ func (h *Handler) GetAllGroups(c *fiber.Ctx) error {
 
 // UpdateGroup обрабатывает запрос на обновление существующей группы.
 // @Summary Обновить группу
-// @Description Обновляет имя существующей группы по ее ID.
+// @Description Обновляет имя существующей группы по ее ID и, если передан is_open, прием заявок на вступление.
 // @Tags groups
 // @Accept json
 // @Produce json
 // @Param id path int true "ID группы для обновления"
 // @Param group body UpdateGroupRequest true "Новое имя для группы"
 // @Success 200 {object} GroupResponse "Группа успешно обновлена"
-// @Failure 400 {object} ErrorResponse "Ошибка валидации, некорректный ID или некорректный запрос"
+// @Failure 400 {object} validation.Response "Ошибка валидации, некорректный ID или некорректный запрос"
+// @Failure 401 {object} ErrorResponse "Требуется авторизация"
+// @Failure 403 {object} ErrorResponse "Нет прав на управление группами"
 // @Failure 404 {object} ErrorResponse "Группа не найдена"
 // @Failure 409 {object} ErrorResponse "Группа с таким новым именем уже существует"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
@@ -147,18 +143,12 @@ func (h *Handler) UpdateGroup(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid group ID format"})
 	}
 
-	var req UpdateGroupRequest
-	if err := c.BodyParser(&req); err != nil {
-		h.logger.Warn("Failed to parse request body for update group", slog.Uint64("id", id), slog.Any("error", err))
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid request body"})
-	}
-
-	if err := h.validate.Struct(req); err != nil {
-		h.logger.Warn("Validation failed for update group request", slog.Uint64("id", id), slog.Any("error", err))
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Message: fmt.Sprintf("Validation failed: %s", err.Error())})
+	req, ok, err := validation.BindAndValidate[UpdateGroupRequest](c)
+	if !ok {
+		return err
 	}
 
-	updatedGroup, err := h.groupUseCase.UpdateGroup(c.Context(), uint(id), req.Name)
+	updatedGroup, err := h.groupUseCase.UpdateGroup(c.Context(), uint(id), req.Name, req.IsOpen)
 	if err != nil {
 		if errors.Is(err, usecase.ErrGroupNotFound) {
 			h.logger.Warn("Group not found for update in handler", slog.Uint64("id", id), slog.String("newName", req.Name))
@@ -187,6 +177,8 @@ func (h *Handler) UpdateGroup(c *fiber.Ctx) error {
 // @Param id path int true "ID группы для удаления"
 // @Success 204 "Группа успешно удалена (нет содержимого)"
 // @Failure 400 {object} ErrorResponse "Некорректный ID"
+// @Failure 401 {object} ErrorResponse "Требуется авторизация"
+// @Failure 403 {object} ErrorResponse "Нет прав на управление группами"
 // @Failure 404 {object} ErrorResponse "Группа не найдена"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /groups/{id} [delete]
@@ -211,12 +203,18 @@ func (h *Handler) DeleteGroup(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
-// toGroupResponse преобразует domain.Group в GroupResponse DTO.
+// toGroupResponse преобразует domain.Group в GroupResponse DTO с временем в UTC.
 func toGroupResponse(group *domain.Group) GroupResponse {
+	return ToGroupResponse(group, time.UTC)
+}
+
+// ToGroupResponse преобразует domain.Group в GroupResponse DTO, отображая время в часовом поясе loc.
+func ToGroupResponse(group *domain.Group, loc *time.Location) GroupResponse {
 	return GroupResponse{
 		ID:        group.ID,
 		Name:      group.Name,
-		CreatedAt: group.CreatedAt,
-		UpdatedAt: group.UpdatedAt,
+		IsOpen:    group.IsOpen,
+		CreatedAt: timeutil.Format(group.CreatedAt, loc),
+		UpdatedAt: timeutil.Format(group.UpdatedAt, loc),
 	}
 }
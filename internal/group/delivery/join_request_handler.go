@@ -0,0 +1,246 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"rim/internal/domain"
+	"rim/internal/group/usecase"
+	"rim/pkg/requestctx"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestJoin обрабатывает заявку текущего пользователя на вступление в группу.
+// @Summary Подать заявку на вступление в группу
+// @Description Создает заявку на добавление контакта текущего пользователя в открытую группу. Администраторы получают уведомление.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param request body JoinGroupRequest false "Сообщение для администраторов"
+// @Success 201 {object} JoinRequestResponse "Заявка создана"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос или у пользователя нет контакта"
+// @Failure 401 {object} ErrorResponse "Требуется авторизация"
+// @Failure 403 {object} ErrorResponse "Группа не принимает заявки"
+// @Failure 404 {object} ErrorResponse "Группа не найдена"
+// @Failure 409 {object} ErrorResponse "Уже состоит в группе или заявка уже подана"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id}/join-requests [post]
+func (h *Handler) RequestJoin(c *fiber.Ctx) error {
+	groupID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid group ID format"})
+	}
+	user, ok := c.Locals("user").(*domain.User)
+	if !ok || user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Message: "Authentication required"})
+	}
+
+	var req JoinGroupRequest
+	if len(c.Body()) > 0 {
+		parsed, ok, err := validation.BindAndValidate[JoinGroupRequest](c)
+		if !ok {
+			return err
+		}
+		req = parsed
+	}
+
+	request, err := h.groupUseCase.RequestJoin(c.Context(), user, uint(groupID), req.Message)
+	if err != nil {
+		return h.joinRequestError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(toJoinRequestResponse(request, requestctx.Location(c)))
+}
+
+// GetMyJoinRequests возвращает заявки текущего пользователя.
+// @Summary Получить свои заявки на вступление в группы
+// @Tags groups
+// @Produce json
+// @Success 200 {array} JoinRequestResponse
+// @Failure 401 {object} ErrorResponse "Требуется авторизация"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/join-requests/mine [get]
+func (h *Handler) GetMyJoinRequests(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Message: "Authentication required"})
+	}
+	requests, err := h.groupUseCase.GetUserJoinRequests(c.Context(), userID)
+	if err != nil {
+		return h.joinRequestError(c, err)
+	}
+	return c.JSON(h.toJoinRequestResponses(c, requests))
+}
+
+// GetJoinRequests возвращает заявки на вступление во все группы.
+// @Summary Получить заявки на вступление в группы
+// @Description Возвращает заявки, начиная с новых. Модераторы видят только заявки в свои группы.
+// @Tags groups
+// @Produce json
+// @Param status query string false "Статус: pending, approved или rejected"
+// @Success 200 {array} JoinRequestResponse
+// @Failure 400 {object} ErrorResponse "Некорректный статус"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/join-requests [get]
+func (h *Handler) GetJoinRequests(c *fiber.Ctx) error {
+	requests, err := h.groupUseCase.GetJoinRequests(c.Context(), GroupScope(c), 0, c.Query("status"))
+	if err != nil {
+		return h.joinRequestError(c, err)
+	}
+	return c.JSON(h.toJoinRequestResponses(c, requests))
+}
+
+// GetGroupJoinRequests возвращает заявки на вступление в группу.
+// @Summary Получить заявки на вступление в группу
+// @Description Возвращает заявки группы, начиная с новых. Доступно управляющим группами и модераторам группы.
+// @Tags groups
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param status query string false "Статус: pending, approved или rejected"
+// @Success 200 {array} JoinRequestResponse
+// @Failure 400 {object} ErrorResponse "Некорректный ID или статус"
+// @Failure 403 {object} ErrorResponse "Группа вне групп модератора"
+// @Failure 404 {object} ErrorResponse "Группа не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id}/join-requests [get]
+func (h *Handler) GetGroupJoinRequests(c *fiber.Ctx) error {
+	groupID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid group ID format"})
+	}
+	requests, err := h.groupUseCase.GetJoinRequests(c.Context(), GroupScope(c), uint(groupID), c.Query("status"))
+	if err != nil {
+		return h.joinRequestError(c, err)
+	}
+	return c.JSON(h.toJoinRequestResponses(c, requests))
+}
+
+// ApproveJoinRequest одобряет заявку и добавляет контакт в группу.
+// @Summary Одобрить заявку на вступление в группу
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param request_id path int true "ID заявки"
+// @Param review body ReviewJoinRequest false "Комментарий для заявителя"
+// @Success 200 {object} JoinRequestResponse
+// @Failure 400 {object} ErrorResponse "Некорректный запрос"
+// @Failure 403 {object} ErrorResponse "Группа вне групп модератора"
+// @Failure 404 {object} ErrorResponse "Заявка не найдена"
+// @Failure 409 {object} ErrorResponse "Заявка уже рассмотрена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id}/join-requests/{request_id}/approve [post]
+func (h *Handler) ApproveJoinRequest(c *fiber.Ctx) error {
+	return h.reviewJoinRequest(c, h.groupUseCase.ApproveJoinRequest)
+}
+
+// RejectJoinRequest отклоняет заявку на вступление.
+// @Summary Отклонить заявку на вступление в группу
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param request_id path int true "ID заявки"
+// @Param review body ReviewJoinRequest false "Причина отказа"
+// @Success 200 {object} JoinRequestResponse
+// @Failure 400 {object} ErrorResponse "Некорректный запрос"
+// @Failure 403 {object} ErrorResponse "Группа вне групп модератора"
+// @Failure 404 {object} ErrorResponse "Заявка не найдена"
+// @Failure 409 {object} ErrorResponse "Заявка уже рассмотрена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id}/join-requests/{request_id}/reject [post]
+func (h *Handler) RejectJoinRequest(c *fiber.Ctx) error {
+	return h.reviewJoinRequest(c, h.groupUseCase.RejectJoinRequest)
+}
+
+type joinReviewFunc func(ctx context.Context, scope domain.GroupScope, groupID, requestID, reviewerID uint, comment string) (*domain.GroupJoinRequest, error)
+
+func (h *Handler) reviewJoinRequest(c *fiber.Ctx, decide joinReviewFunc) error {
+	groupID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid group ID format"})
+	}
+	requestID, err := strconv.ParseUint(c.Params("request_id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid join request ID format"})
+	}
+	reviewerID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Message: "Authentication required"})
+	}
+
+	var req ReviewJoinRequest
+	if len(c.Body()) > 0 {
+		parsed, ok, err := validation.BindAndValidate[ReviewJoinRequest](c)
+		if !ok {
+			return err
+		}
+		req = parsed
+	}
+
+	request, err := decide(c.Context(), GroupScope(c), uint(groupID), uint(requestID), reviewerID, req.Comment)
+	if err != nil {
+		return h.joinRequestError(c, err)
+	}
+	return c.JSON(toJoinRequestResponse(request, requestctx.Location(c)))
+}
+
+// joinRequestError преобразует ошибки usecase в HTTP-ответ
+func (h *Handler) joinRequestError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrInvalidJoinStatus), errors.Is(err, usecase.ErrNoLinkedContact):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, usecase.ErrGroupClosed), errors.Is(err, usecase.ErrOutOfGroupScope):
+		status = fiber.StatusForbidden
+	case errors.Is(err, usecase.ErrGroupNotFound), errors.Is(err, usecase.ErrJoinRequestNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrAlreadyMember),
+		errors.Is(err, usecase.ErrJoinRequestExists),
+		errors.Is(err, usecase.ErrJoinRequestNotPending):
+		status = fiber.StatusConflict
+	default:
+		h.logger.ErrorContext(c.Context(), "Group join request operation failed", slog.Any("error", err))
+		return c.Status(status).JSON(ErrorResponse{Message: "Internal server error"})
+	}
+	return c.Status(status).JSON(ErrorResponse{Message: err.Error()})
+}
+
+func (h *Handler) toJoinRequestResponses(c *fiber.Ctx, requests []domain.GroupJoinRequest) []JoinRequestResponse {
+	loc := requestctx.Location(c)
+	resp := make([]JoinRequestResponse, len(requests))
+	for i := range requests {
+		resp[i] = toJoinRequestResponse(&requests[i], loc)
+	}
+	return resp
+}
+
+func toJoinRequestResponse(request *domain.GroupJoinRequest, loc *time.Location) JoinRequestResponse {
+	resp := JoinRequestResponse{
+		ID:            request.ID,
+		GroupID:       request.GroupID,
+		ContactID:     request.ContactID,
+		UserID:        request.UserID,
+		Message:       request.Message,
+		Status:        request.Status,
+		ReviewedBy:    request.ReviewedBy,
+		ReviewComment: request.ReviewComment,
+		CreatedAt:     timeutil.Format(request.CreatedAt, loc),
+	}
+	if request.Group != nil {
+		resp.GroupName = request.Group.Name
+	}
+	if request.Contact != nil {
+		resp.ContactName = request.Contact.Name
+	}
+	if request.ReviewedAt != nil {
+		resp.ReviewedAt = timeutil.Format(*request.ReviewedAt, loc)
+	}
+	return resp
+}
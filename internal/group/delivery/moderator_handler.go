@@ -0,0 +1,172 @@
+package delivery
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/requestctx"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ModeratorScope возвращает группы, модератором которых является текущий пользователь.
+// Используется как ScopeResolver в middleware авторизации.
+func (h *Handler) ModeratorScope(c *fiber.Ctx) (domain.GroupScope, error) {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return domain.GroupScope{}, nil
+	}
+	return h.groupUseCase.ModeratorScope(c.Context(), userID)
+}
+
+// GroupScope возвращает группы, которыми ограничен текущий запрос, или nil, если доступ выдан политикой.
+func GroupScope(c *fiber.Ctx) domain.GroupScope {
+	scope, _ := c.Locals("group_scope").(domain.GroupScope)
+	return scope
+}
+
+// GetModerators возвращает модераторов группы.
+// @Summary Получить модераторов группы
+// @Tags groups
+// @Produce json
+// @Param id path int true "ID группы"
+// @Success 200 {array} ModeratorResponse
+// @Failure 400 {object} ErrorResponse "Некорректный ID"
+// @Failure 404 {object} ErrorResponse "Группа не найдена"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id}/moderators [get]
+func (h *Handler) GetModerators(c *fiber.Ctx) error {
+	groupID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid group ID format"})
+	}
+	moderators, err := h.groupUseCase.GetModerators(c.Context(), uint(groupID))
+	if err != nil {
+		return h.moderatorError(c, err)
+	}
+	loc := requestctx.Location(c)
+	resp := make([]ModeratorResponse, len(moderators))
+	for i := range moderators {
+		resp[i] = toModeratorResponse(&moderators[i], loc)
+	}
+	return c.JSON(resp)
+}
+
+// AddModerator назначает пользователя модератором группы.
+// @Summary Назначить модератора группы
+// @Description Модератор может редактировать контакты участников группы, менять ее состав и рассматривать заявки на вступление.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param moderator body AddModeratorRequest true "Пользователь"
+// @Success 201 {object} ModeratorResponse
+// @Failure 400 {object} ErrorResponse "Некорректный запрос"
+// @Failure 404 {object} ErrorResponse "Группа или пользователь не найдены"
+// @Failure 409 {object} ErrorResponse "Пользователь уже модератор группы"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id}/moderators [post]
+func (h *Handler) AddModerator(c *fiber.Ctx) error {
+	groupID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid group ID format"})
+	}
+	createdBy, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Message: "Authentication required"})
+	}
+
+	req, ok, err := validation.BindAndValidate[AddModeratorRequest](c)
+	if !ok {
+		return err
+	}
+
+	moderator, err := h.groupUseCase.AddModerator(c.Context(), uint(groupID), req.UserID, createdBy)
+	if err != nil {
+		return h.moderatorError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(toModeratorResponse(moderator, requestctx.Location(c)))
+}
+
+// RemoveModerator снимает пользователя с модерации группы.
+// @Summary Снять модератора группы
+// @Tags groups
+// @Param id path int true "ID группы"
+// @Param user_id path int true "ID пользователя"
+// @Success 204 "Модератор снят"
+// @Failure 400 {object} ErrorResponse "Некорректный ID"
+// @Failure 404 {object} ErrorResponse "Пользователь не модератор группы"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id}/moderators/{user_id} [delete]
+func (h *Handler) RemoveModerator(c *fiber.Ctx) error {
+	groupID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid group ID format"})
+	}
+	userID, err := strconv.ParseUint(c.Params("user_id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid user ID format"})
+	}
+	if err := h.groupUseCase.RemoveModerator(c.Context(), uint(groupID), uint(userID)); err != nil {
+		return h.moderatorError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetModeratedGroups возвращает группы, модератором которых является текущий пользователь.
+// @Summary Получить свои модерируемые группы
+// @Tags groups
+// @Produce json
+// @Success 200 {array} GroupResponse
+// @Failure 401 {object} ErrorResponse "Требуется авторизация"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/moderated [get]
+func (h *Handler) GetModeratedGroups(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Message: "Authentication required"})
+	}
+	groups, err := h.groupUseCase.GetModeratedGroups(c.Context(), userID)
+	if err != nil {
+		return h.moderatorError(c, err)
+	}
+	loc := requestctx.Location(c)
+	resp := make([]GroupResponse, len(groups))
+	for i := range groups {
+		resp[i] = ToGroupResponse(&groups[i], loc)
+	}
+	return c.JSON(resp)
+}
+
+// moderatorError преобразует ошибки usecase в HTTP-ответ по их виду (pkg/apperror)
+// moderatorError преобразует ошибки usecase в HTTP-ответ
+func (h *Handler) moderatorError(c *fiber.Ctx, err error) error {
+	status := apperror.HTTPStatus(err)
+	if status == fiber.StatusInternalServerError {
+		h.logger.ErrorContext(c.Context(), "Group moderator operation failed", slog.Any("error", err))
+		return c.Status(status).JSON(ErrorResponse{Message: "Internal server error"})
+	}
+	return c.Status(status).JSON(ErrorResponse{Message: err.Error()})
+}
+
+func toModeratorResponse(moderator *domain.GroupModerator, loc *time.Location) ModeratorResponse {
+	resp := ModeratorResponse{
+		GroupID:   moderator.GroupID,
+		UserID:    moderator.UserID,
+		CreatedBy: moderator.CreatedBy,
+		CreatedAt: timeutil.Format(moderator.CreatedAt, loc),
+	}
+	if moderator.User != nil {
+		resp.ContactID = moderator.User.ContactID
+		resp.Name = moderator.User.Name
+		if moderator.User.Contact != nil {
+			resp.Name = moderator.User.Contact.Name
+		}
+	}
+	return resp
+}
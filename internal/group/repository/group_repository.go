@@ -3,14 +3,18 @@ package repository
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"rim/internal/domain"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
 
 	"gorm.io/gorm"
 )
 
 // Repository определяет интерфейс для операций с данными групп.
 // Это позволяет абстрагироваться от конкретной реализации хранилища.
+// Группы и заявки на вступление ограничены организацией из контекста (pkg/tenant).
 type Repository interface {
 	Create(ctx context.Context, group *domain.Group) (*domain.Group, error)
 	GetByID(ctx context.Context, id uint) (*domain.Group, error)
@@ -18,6 +22,32 @@ type Repository interface {
 	GetAll(ctx context.Context) ([]domain.Group, error)
 	Update(ctx context.Context, group *domain.Group) error
 	Delete(ctx context.Context, id uint) error
+
+	// Членство в группе
+	IsMember(ctx context.Context, groupID, contactID uint) (bool, error)
+	// AddMember и RemoveMember записывают изменение в историю членства от имени actorID по причине source
+	AddMember(ctx context.Context, groupID, contactID uint, actorID *uint, source string) error
+	RemoveMember(ctx context.Context, groupID, contactID uint, actorID *uint, source string) error
+	SetMembershipExpiry(ctx context.Context, groupID, contactID uint, expiresAt *time.Time) error
+	// GetExpiredMemberships возвращает членства со сроком не позже now вместе с группой и контактом
+	GetExpiredMemberships(ctx context.Context, now time.Time) ([]domain.ContactGroup, error)
+	// GetMembershipHistory возвращает события членства контакта, начиная с последнего, включая удаленные группы
+	GetMembershipHistory(ctx context.Context, contactID uint) ([]domain.GroupMembershipEvent, error)
+
+	// Заявки на вступление в группу
+	CreateJoinRequest(ctx context.Context, request *domain.GroupJoinRequest) error
+	GetJoinRequest(ctx context.Context, id uint) (*domain.GroupJoinRequest, error)
+	GetJoinRequests(ctx context.Context, groupIDs []uint, status string) ([]domain.GroupJoinRequest, error)
+	GetUserJoinRequests(ctx context.Context, userID uint) ([]domain.GroupJoinRequest, error)
+	GetPendingJoinRequest(ctx context.Context, groupID, contactID uint) (*domain.GroupJoinRequest, error)
+	UpdateJoinRequest(ctx context.Context, request *domain.GroupJoinRequest) error
+
+	// Модераторы групп
+	AddModerator(ctx context.Context, moderator *domain.GroupModerator) error
+	RemoveModerator(ctx context.Context, groupID, userID uint) error
+	GetModerators(ctx context.Context, groupID uint) ([]domain.GroupModerator, error)
+	GetModeratedGroups(ctx context.Context, userID uint) ([]domain.Group, error)
+	UserExists(ctx context.Context, userID uint) (bool, error)
 }
 
 // sqliteRepository реализует Repository для работы с SQLite через GORM.
@@ -36,7 +66,10 @@ func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
 
 // Create создает новую группу в базе данных.
 func (r *sqliteRepository) Create(ctx context.Context, group *domain.Group) (*domain.Group, error) {
-	if err := r.db.WithContext(ctx).Create(group).Error; err != nil {
+	if orgID := tenant.FromContext(ctx); orgID != 0 && group.OrganizationID == 0 {
+		group.OrganizationID = orgID
+	}
+	if err := transaction.DB(ctx, r.db).Create(group).Error; err != nil {
 		r.logger.ErrorContext(ctx, "Error creating group in DB", slog.Any("error", err), slog.String("groupName", group.Name))
 		return nil, err
 	}
@@ -47,7 +80,7 @@ func (r *sqliteRepository) Create(ctx context.Context, group *domain.Group) (*do
 // GetByID извлекает группу по ее ID.
 func (r *sqliteRepository) GetByID(ctx context.Context, id uint) (*domain.Group, error) {
 	var group domain.Group
-	if err := r.db.WithContext(ctx).First(&group, id).Error; err != nil {
+	if err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "groups")).First(&group, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.WarnContext(ctx, "Group not found by ID in DB", slog.Uint64("groupID", uint64(id)))
 			return nil, err // Возвращаем gorm.ErrRecordNotFound как есть
@@ -61,7 +94,7 @@ func (r *sqliteRepository) GetByID(ctx context.Context, id uint) (*domain.Group,
 // GetByName извлекает группу по ее имени.
 func (r *sqliteRepository) GetByName(ctx context.Context, name string) (*domain.Group, error) {
 	var group domain.Group
-	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&group).Error; err != nil {
+	if err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "groups")).Where("name = ?", name).First(&group).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.InfoContext(ctx, "Group not found by name in DB", slog.String("groupName", name)) // Info, т.к. это ожидаемое поведение при проверке уникальности
 			return nil, err                                                                            // Возвращаем gorm.ErrRecordNotFound как есть
@@ -75,7 +108,7 @@ func (r *sqliteRepository) GetByName(ctx context.Context, name string) (*domain.
 // GetAll извлекает все группы из базы данных.
 func (r *sqliteRepository) GetAll(ctx context.Context) ([]domain.Group, error) {
 	var groups []domain.Group
-	if err := r.db.WithContext(ctx).Find(&groups).Error; err != nil {
+	if err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "groups")).Find(&groups).Error; err != nil {
 		r.logger.ErrorContext(ctx, "Error getting all groups from DB", slog.Any("error", err))
 		return nil, err
 	}
@@ -91,7 +124,7 @@ func (r *sqliteRepository) Update(ctx context.Context, group *domain.Group) erro
 	// Для простоты начнем с Save, но учитываем, что он обновит все поля, включая CreatedAt, если не обработать это.
 	// Правильнее было бы использовать Updates с мапой или структурой только обновляемых полей.
 	// Пока для простоты оставим Save, предполагая, что передается полная обновленная модель.
-	result := r.db.WithContext(ctx).Save(group)
+	result := transaction.DB(ctx, r.db).Save(group)
 	if result.Error != nil {
 		r.logger.ErrorContext(ctx, "Error updating group in DB", slog.Uint64("groupID", uint64(group.ID)), slog.Any("error", result.Error))
 		return result.Error
@@ -108,7 +141,7 @@ func (r *sqliteRepository) Update(ctx context.Context, group *domain.Group) erro
 func (r *sqliteRepository) Delete(ctx context.Context, id uint) error {
 	// GORM использует мягкое удаление по умолчанию, если в модели есть gorm.DeletedAt
 	// Это установит поле DeletedAt, а не удалит запись физически.
-	result := r.db.WithContext(ctx).Delete(&domain.Group{}, id)
+	result := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "groups")).Delete(&domain.Group{}, id)
 	if result.Error != nil {
 		r.logger.ErrorContext(ctx, "Error deleting group from DB", slog.Uint64("groupID", uint64(id)), slog.Any("error", result.Error))
 		return result.Error
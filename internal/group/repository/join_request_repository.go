@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+func (r *sqliteRepository) CreateJoinRequest(ctx context.Context, request *domain.GroupJoinRequest) error {
+	if err := transaction.DB(ctx, r.db).Create(request).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error creating group join request in DB", slog.Uint64("groupID", uint64(request.GroupID)), slog.Uint64("contactID", uint64(request.ContactID)), slog.Any("error", err))
+		return err
+	}
+	r.logger.InfoContext(ctx, "Successfully created group join request in DB", slog.Uint64("requestID", uint64(request.ID)))
+	return nil
+}
+
+func (r *sqliteRepository) GetJoinRequest(ctx context.Context, id uint) (*domain.GroupJoinRequest, error) {
+	var request domain.GroupJoinRequest
+	if err := r.joinRequests(ctx).First(&request, id).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.logger.ErrorContext(ctx, "Error getting group join request from DB", slog.Uint64("requestID", uint64(id)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetJoinRequests возвращает заявки, начиная с новых; пустой groupIDs - по всем группам, пустой status - в любом статусе.
+func (r *sqliteRepository) GetJoinRequests(ctx context.Context, groupIDs []uint, status string) ([]domain.GroupJoinRequest, error) {
+	var requests []domain.GroupJoinRequest
+	query := r.joinRequests(ctx).Order("created_at DESC")
+	if len(groupIDs) > 0 {
+		query = query.Where("group_id IN ?", groupIDs)
+	}
+	if tenant.FromContext(ctx) != 0 {
+		query = query.Where("group_id IN (?)", r.db.Model(&domain.Group{}).Select("groups.id").Scopes(tenant.Scope(ctx, "groups")))
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&requests).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting group join requests from DB", slog.Any("groupIDs", groupIDs), slog.String("status", status), slog.Any("error", err))
+		return nil, err
+	}
+	return requests, nil
+}
+
+// GetUserJoinRequests возвращает заявки, поданные пользователем, начиная с новых.
+func (r *sqliteRepository) GetUserJoinRequests(ctx context.Context, userID uint) ([]domain.GroupJoinRequest, error) {
+	var requests []domain.GroupJoinRequest
+	if err := r.joinRequests(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&requests).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting user group join requests from DB", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+		return nil, err
+	}
+	return requests, nil
+}
+
+func (r *sqliteRepository) GetPendingJoinRequest(ctx context.Context, groupID, contactID uint) (*domain.GroupJoinRequest, error) {
+	var request domain.GroupJoinRequest
+	err := transaction.DB(ctx, r.db).
+		Where("group_id = ? AND contact_id = ? AND status = ?", groupID, contactID, domain.JoinRequestPending).
+		First(&request).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.logger.ErrorContext(ctx, "Error getting pending group join request from DB", slog.Uint64("groupID", uint64(groupID)), slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *sqliteRepository) UpdateJoinRequest(ctx context.Context, request *domain.GroupJoinRequest) error {
+	err := transaction.DB(ctx, r.db).Model(request).
+		Select("Status", "ReviewedBy", "ReviewComment", "ReviewedAt").
+		Updates(request).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error updating group join request in DB", slog.Uint64("requestID", uint64(request.ID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// joinRequests возвращает запрос к заявкам с загруженными группой и контактом
+func (r *sqliteRepository) joinRequests(ctx context.Context) *gorm.DB {
+	return transaction.DB(ctx, r.db).Preload("Group").Preload("Contact")
+}
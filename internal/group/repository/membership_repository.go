@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IsMember проверяет, состоит ли контакт в группе.
+func (r *sqliteRepository) IsMember(ctx context.Context, groupID, contactID uint) (bool, error) {
+	var count int64
+	err := transaction.DB(ctx, r.db).Model(&domain.ContactGroup{}).
+		Where("group_id = ? AND contact_id = ?", groupID, contactID).
+		Count(&count).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error checking group membership in DB", slog.Uint64("groupID", uint64(groupID)), slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// AddMember добавляет контакт в группу; повторное добавление ничего не меняет и не попадает в историю.
+func (r *sqliteRepository) AddMember(ctx context.Context, groupID, contactID uint, actorID *uint, source string) error {
+	err := transaction.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&domain.ContactGroup{GroupID: groupID, ContactID: contactID})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Create(&domain.GroupMembershipEvent{ContactID: contactID, GroupID: groupID, Type: domain.MembershipJoined, Source: source, ActorID: actorID}).Error
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error adding contact to group in DB", slog.Uint64("groupID", uint64(groupID)), slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return err
+	}
+	r.logger.InfoContext(ctx, "Successfully added contact to group in DB", slog.Uint64("groupID", uint64(groupID)), slog.Uint64("contactID", uint64(contactID)))
+	return nil
+}
+
+// RemoveMember исключает контакт из группы.
+func (r *sqliteRepository) RemoveMember(ctx context.Context, groupID, contactID uint, actorID *uint, source string) error {
+	err := transaction.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("group_id = ? AND contact_id = ?", groupID, contactID).Delete(&domain.ContactGroup{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Create(&domain.GroupMembershipEvent{ContactID: contactID, GroupID: groupID, Type: domain.MembershipLeft, Source: source, ActorID: actorID}).Error
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error removing contact from group in DB", slog.Uint64("groupID", uint64(groupID)), slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return err
+	}
+	r.logger.InfoContext(ctx, "Successfully removed contact from group in DB", slog.Uint64("groupID", uint64(groupID)), slog.Uint64("contactID", uint64(contactID)))
+	return nil
+}
+
+// SetMembershipExpiry задает срок членства контакта в группе; nil делает членство бессрочным.
+func (r *sqliteRepository) SetMembershipExpiry(ctx context.Context, groupID, contactID uint, expiresAt *time.Time) error {
+	err := transaction.DB(ctx, r.db).Model(&domain.ContactGroup{}).
+		Where("group_id = ? AND contact_id = ?", groupID, contactID).
+		Update("expires_at", expiresAt).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error setting group membership expiry in DB", slog.Uint64("groupID", uint64(groupID)), slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) GetExpiredMemberships(ctx context.Context, now time.Time) ([]domain.ContactGroup, error) {
+	var memberships []domain.ContactGroup
+	err := transaction.DB(ctx, r.db).Preload("Group").Preload("Contact").
+		Where("expires_at IS NOT NULL AND expires_at <= ?", now).
+		Order("group_id").
+		Find(&memberships).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error getting expired group memberships from DB", slog.Any("error", err))
+		return nil, err
+	}
+	return memberships, nil
+}
+
+func (r *sqliteRepository) GetMembershipHistory(ctx context.Context, contactID uint) ([]domain.GroupMembershipEvent, error) {
+	var events []domain.GroupMembershipEvent
+	err := transaction.DB(ctx, r.db).
+		Preload("Group", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("contact_id = ?", contactID).
+		Order("created_at DESC, id DESC").
+		Find(&events).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error getting group membership history from DB", slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+// AddModerator создает запись модератора и загружает в нее пользователя с контактом.
+func (r *sqliteRepository) AddModerator(ctx context.Context, moderator *domain.GroupModerator) error {
+	if err := transaction.DB(ctx, r.db).Create(moderator).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error creating group moderator in DB", slog.Uint64("groupID", uint64(moderator.GroupID)), slog.Uint64("userID", uint64(moderator.UserID)), slog.Any("error", err))
+		return err
+	}
+	if err := transaction.DB(ctx, r.db).Preload("User.Contact").First(moderator, moderator.ID).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error reloading group moderator from DB", slog.Uint64("moderatorID", uint64(moderator.ID)), slog.Any("error", err))
+		return err
+	}
+	r.logger.InfoContext(ctx, "Successfully created group moderator in DB", slog.Uint64("groupID", uint64(moderator.GroupID)), slog.Uint64("userID", uint64(moderator.UserID)))
+	return nil
+}
+
+// RemoveModerator снимает пользователя с модерации группы; возвращает gorm.ErrRecordNotFound, если он не был модератором.
+func (r *sqliteRepository) RemoveModerator(ctx context.Context, groupID, userID uint) error {
+	result := transaction.DB(ctx, r.db).Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&domain.GroupModerator{})
+	if result.Error != nil {
+		r.logger.ErrorContext(ctx, "Error deleting group moderator from DB", slog.Uint64("groupID", uint64(groupID)), slog.Uint64("userID", uint64(userID)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	r.logger.InfoContext(ctx, "Successfully deleted group moderator from DB", slog.Uint64("groupID", uint64(groupID)), slog.Uint64("userID", uint64(userID)))
+	return nil
+}
+
+// GetModerators возвращает модераторов группы вместе с учетными записями пользователей и их контактами.
+func (r *sqliteRepository) GetModerators(ctx context.Context, groupID uint) ([]domain.GroupModerator, error) {
+	var moderators []domain.GroupModerator
+	if err := transaction.DB(ctx, r.db).Preload("User.Contact").Where("group_id = ?", groupID).Order("id").Find(&moderators).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting group moderators from DB", slog.Uint64("groupID", uint64(groupID)), slog.Any("error", err))
+		return nil, err
+	}
+	return moderators, nil
+}
+
+// GetModeratedGroups возвращает группы текущей организации, модератором которых является пользователь.
+func (r *sqliteRepository) GetModeratedGroups(ctx context.Context, userID uint) ([]domain.Group, error) {
+	var groups []domain.Group
+	err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "groups")).
+		Joins("JOIN group_moderators ON group_moderators.group_id = groups.id").
+		Where("group_moderators.user_id = ?", userID).
+		Order("groups.name").
+		Find(&groups).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error getting moderated groups from DB", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (r *sqliteRepository) UserExists(ctx context.Context, userID uint) (bool, error) {
+	var count int64
+	if err := transaction.DB(ctx, r.db).Model(&domain.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error checking user existence in DB", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -5,44 +5,78 @@ import (
 	"errors"
 	"log/slog"
 	"strings"
+	"time"
 
 	"rim/internal/domain"
 	"rim/internal/group/repository"
+	notificationUseCase "rim/internal/notification/usecase"
+	"rim/pkg/apperror"
+	"rim/pkg/eventbus"
+	"rim/pkg/transaction"
 
 	"gorm.io/gorm"
 )
 
 var (
-	ErrGroupNameEmpty    = errors.New("group name cannot be empty")
-	ErrGroupNotFound     = errors.New("group not found")
-	ErrGroupNameExists   = errors.New("group with this name already exists")
-	ErrCannotDeleteGroup = errors.New("cannot delete group") // Общая ошибка, может быть детализирована
+	ErrGroupNameEmpty    = apperror.Invalid("group_name_empty", "group name cannot be empty")
+	ErrGroupNotFound     = apperror.NotFound("group_not_found", "group not found")
+	ErrGroupNameExists   = apperror.Conflict("group_name_exists", "group with this name already exists")
+	ErrCannotDeleteGroup = apperror.Internal("group_delete_failed", "cannot delete group") // Общая ошибка, может быть детализирована
 )
 
 // UseCase определяет интерфейс для бизнес-логики управления группами.
 type UseCase interface {
-	CreateGroup(ctx context.Context, name string) (*domain.Group, error)
+	CreateGroup(ctx context.Context, name string, isOpen bool) (*domain.Group, error)
 	GetGroupByID(ctx context.Context, id uint) (*domain.Group, error)
 	GetAllGroups(ctx context.Context) ([]domain.Group, error)
-	UpdateGroup(ctx context.Context, id uint, newName string) (*domain.Group, error)
+	// UpdateGroup переименовывает группу; isOpen, если задан, меняет прием заявок на вступление
+	UpdateGroup(ctx context.Context, id uint, newName string, isOpen *bool) (*domain.Group, error)
 	DeleteGroup(ctx context.Context, id uint) error
+
+	// Заявки на вступление в открытые группы.
+	// scope ограничивает рассмотрение группами модератора; nil - без ограничений.
+	RequestJoin(ctx context.Context, user *domain.User, groupID uint, message string) (*domain.GroupJoinRequest, error)
+	GetJoinRequests(ctx context.Context, scope domain.GroupScope, groupID uint, status string) ([]domain.GroupJoinRequest, error)
+	GetUserJoinRequests(ctx context.Context, userID uint) ([]domain.GroupJoinRequest, error)
+	ApproveJoinRequest(ctx context.Context, scope domain.GroupScope, groupID, requestID, reviewerID uint, comment string) (*domain.GroupJoinRequest, error)
+	RejectJoinRequest(ctx context.Context, scope domain.GroupScope, groupID, requestID, reviewerID uint, comment string) (*domain.GroupJoinRequest, error)
+
+	// Модераторы групп
+	AddModerator(ctx context.Context, groupID, userID, createdBy uint) (*domain.GroupModerator, error)
+	RemoveModerator(ctx context.Context, groupID, userID uint) error
+	GetModerators(ctx context.Context, groupID uint) ([]domain.GroupModerator, error)
+	GetModeratedGroups(ctx context.Context, userID uint) ([]domain.Group, error)
+	// ModeratorScope возвращает группы, которыми управляет пользователь (пустой, но не nil список, если таких нет)
+	ModeratorScope(ctx context.Context, userID uint) (domain.GroupScope, error)
+
+	// ExpireMemberships исключает контакты с истекшим сроком членства и возвращает их число
+	ExpireMemberships(ctx context.Context) (int, error)
+	// RunMembershipExpiry вызывает ExpireMemberships каждые interval до отмены ctx
+	RunMembershipExpiry(ctx context.Context, interval time.Duration)
 }
 
 type groupUseCase struct {
 	groupRepo repository.Repository
+	notifier  notificationUseCase.UseCase
+	events    eventbus.Publisher // Вступление в группы и выход из них
+	tx        transaction.Manager
 	logger    *slog.Logger
 }
 
 // NewGroupUseCase создает новый экземпляр groupUseCase.
-func NewGroupUseCase(groupRepo repository.Repository, logger *slog.Logger) UseCase {
+// notifier сообщает администраторам о новых заявках на вступление, а заявителям - о решении.
+func NewGroupUseCase(groupRepo repository.Repository, notifier notificationUseCase.UseCase, events eventbus.Publisher, tx transaction.Manager, logger *slog.Logger) UseCase {
 	return &groupUseCase{
 		groupRepo: groupRepo,
+		notifier:  notifier,
+		events:    events,
+		tx:        tx,
 		logger:    logger,
 	}
 }
 
 // CreateGroup создает новую группу.
-func (uc *groupUseCase) CreateGroup(ctx context.Context, name string) (*domain.Group, error) {
+func (uc *groupUseCase) CreateGroup(ctx context.Context, name string, isOpen bool) (*domain.Group, error) {
 	name = strings.TrimSpace(name)
 	if name == "" {
 		uc.logger.WarnContext(ctx, "Attempt to create group with empty name")
@@ -60,7 +94,7 @@ func (uc *groupUseCase) CreateGroup(ctx context.Context, name string) (*domain.G
 		return nil, ErrGroupNameExists
 	}
 
-	group := &domain.Group{Name: name}
+	group := &domain.Group{Name: name, IsOpen: isOpen}
 	createdGroup, err := uc.groupRepo.Create(ctx, group)
 	if err != nil {
 		uc.logger.ErrorContext(ctx, "Failed to create group via repository", slog.String("name", name), slog.Any("error", err))
@@ -96,7 +130,7 @@ func (uc *groupUseCase) GetAllGroups(ctx context.Context) ([]domain.Group, error
 }
 
 // UpdateGroup обновляет существующую группу.
-func (uc *groupUseCase) UpdateGroup(ctx context.Context, id uint, newName string) (*domain.Group, error) {
+func (uc *groupUseCase) UpdateGroup(ctx context.Context, id uint, newName string, isOpen *bool) (*domain.Group, error) {
 	newName = strings.TrimSpace(newName)
 	if newName == "" {
 		uc.logger.WarnContext(ctx, "Attempt to update group with empty name", slog.Uint64("id", uint64(id)))
@@ -113,9 +147,9 @@ func (uc *groupUseCase) UpdateGroup(ctx context.Context, id uint, newName string
 		return nil, err // Внутренняя ошибка сервера
 	}
 
-	// Если имя не изменилось, ничего не делаем, возвращаем существующую группу
-	if groupToUpdate.Name == newName {
-		uc.logger.InfoContext(ctx, "Group name not changed, no update needed", slog.Uint64("id", uint64(id)), slog.String("name", newName))
+	// Если ничего не изменилось, возвращаем существующую группу
+	if groupToUpdate.Name == newName && (isOpen == nil || *isOpen == groupToUpdate.IsOpen) {
+		uc.logger.InfoContext(ctx, "Group not changed, no update needed", slog.Uint64("id", uint64(id)), slog.String("name", newName))
 		return groupToUpdate, nil
 	}
 
@@ -131,6 +165,9 @@ func (uc *groupUseCase) UpdateGroup(ctx context.Context, id uint, newName string
 	}
 
 	groupToUpdate.Name = newName
+	if isOpen != nil {
+		groupToUpdate.IsOpen = *isOpen
+	}
 	if err := uc.groupRepo.Update(ctx, groupToUpdate); err != nil {
 		uc.logger.ErrorContext(ctx, "Failed to update group via repository", slog.Uint64("id", uint64(id)), slog.String("newName", newName), slog.Any("error", err))
 		return nil, err // Внутренняя ошибка сервера
@@ -162,7 +199,7 @@ func (uc *groupUseCase) DeleteGroup(ctx context.Context, id uint) error {
 			return ErrGroupNotFound // Повторная проверка, но лучше быть уверенным
 		}
 		uc.logger.ErrorContext(ctx, "Failed to delete group via repository", slog.Uint64("id", uint64(id)), slog.Any("error", err))
-		return ErrCannotDeleteGroup // Используем нашу общую ошибку или можно вернуть err
+		return ErrCannotDeleteGroup.Wrap(err)
 	}
 
 	uc.logger.InfoContext(ctx, "Group deleted successfully", slog.Uint64("id", uint64(id)))
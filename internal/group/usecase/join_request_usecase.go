@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrGroupClosed           = apperror.Forbidden("group_closed", "group does not accept join requests")
+	ErrNoLinkedContact       = apperror.Invalid("no_linked_contact", "user has no linked contact")
+	ErrAlreadyMember         = apperror.Conflict("already_member", "contact is already a member of the group")
+	ErrJoinRequestExists     = apperror.Conflict("join_request_exists", "join request for this group is already pending")
+	ErrJoinRequestNotFound   = apperror.NotFound("join_request_not_found", "join request not found")
+	ErrJoinRequestNotPending = apperror.Conflict("join_request_not_pending", "join request is already reviewed")
+	ErrInvalidJoinStatus     = apperror.Invalid("invalid_join_request_status", "invalid join request status")
+	ErrOutOfGroupScope       = apperror.Forbidden("out_of_group_scope", "group is outside of the moderated groups")
+)
+
+// RequestJoin создает заявку на добавление контакта пользователя в открытую группу
+// и уведомляет администраторов.
+func (uc *groupUseCase) RequestJoin(ctx context.Context, user *domain.User, groupID uint, message string) (*domain.GroupJoinRequest, error) {
+	if user.ContactID == nil {
+		return nil, ErrNoLinkedContact
+	}
+	contactID := *user.ContactID
+
+	group, err := uc.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !group.IsOpen {
+		return nil, ErrGroupClosed
+	}
+
+	member, err := uc.groupRepo.IsMember(ctx, groupID, contactID)
+	if err != nil {
+		return nil, err
+	}
+	if member {
+		return nil, ErrAlreadyMember
+	}
+
+	if _, err := uc.groupRepo.GetPendingJoinRequest(ctx, groupID, contactID); err == nil {
+		return nil, ErrJoinRequestExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	request := &domain.GroupJoinRequest{
+		GroupID:   groupID,
+		ContactID: contactID,
+		UserID:    user.ID,
+		Message:   strings.TrimSpace(message),
+		Status:    domain.JoinRequestPending,
+	}
+	if err := uc.groupRepo.CreateJoinRequest(ctx, request); err != nil {
+		return nil, err
+	}
+	// Перечитываем заявку, чтобы получить группу и контакт для ответа и уведомления
+	created, err := uc.groupRepo.GetJoinRequest(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Group join request created", slog.Uint64("requestID", uint64(created.ID)), slog.Uint64("groupID", uint64(groupID)), slog.Uint64("contactID", uint64(contactID)))
+
+	text := fmt.Sprintf("%s просит добавить в группу %q", contactName(created), group.Name)
+	if created.Message != "" {
+		text += ": " + created.Message
+	}
+	uc.notifier.NotifyAdmins(ctx, "RIM: заявка на вступление в группу", text)
+	return created, nil
+}
+
+// GetJoinRequests возвращает заявки группы; groupID 0 - по всем группам из scope.
+func (uc *groupUseCase) GetJoinRequests(ctx context.Context, scope domain.GroupScope, groupID uint, status string) ([]domain.GroupJoinRequest, error) {
+	switch status {
+	case "", domain.JoinRequestPending, domain.JoinRequestApproved, domain.JoinRequestRejected:
+	default:
+		return nil, ErrInvalidJoinStatus
+	}
+	if groupID == 0 {
+		if scope.Restricted() && len(scope) == 0 {
+			return []domain.GroupJoinRequest{}, nil
+		}
+		return uc.groupRepo.GetJoinRequests(ctx, scope, status)
+	}
+	if !scope.Allows(groupID) {
+		return nil, ErrOutOfGroupScope
+	}
+	if _, err := uc.GetGroupByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+	return uc.groupRepo.GetJoinRequests(ctx, []uint{groupID}, status)
+}
+
+func (uc *groupUseCase) GetUserJoinRequests(ctx context.Context, userID uint) ([]domain.GroupJoinRequest, error) {
+	return uc.groupRepo.GetUserJoinRequests(ctx, userID)
+}
+
+// ApproveJoinRequest добавляет контакт в группу и уведомляет заявителя.
+func (uc *groupUseCase) ApproveJoinRequest(ctx context.Context, scope domain.GroupScope, groupID, requestID, reviewerID uint, comment string) (*domain.GroupJoinRequest, error) {
+	request, err := uc.pendingJoinRequest(ctx, scope, groupID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	err = uc.tx.Run(ctx, func(ctx context.Context) error {
+		if err := uc.groupRepo.AddMember(ctx, request.GroupID, request.ContactID, &reviewerID, domain.MembershipSourceJoinRequest); err != nil {
+			return err
+		}
+		return uc.events.Publish(ctx, domain.GroupMembershipChangedEvent{
+			GroupID:   request.GroupID,
+			ContactID: request.ContactID,
+			Type:      domain.MembershipJoined,
+			Source:    domain.MembershipSourceJoinRequest,
+			ActorID:   &reviewerID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.reviewJoinRequest(ctx, request, domain.JoinRequestApproved, reviewerID, comment); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// RejectJoinRequest отклоняет заявку и уведомляет заявителя.
+func (uc *groupUseCase) RejectJoinRequest(ctx context.Context, scope domain.GroupScope, groupID, requestID, reviewerID uint, comment string) (*domain.GroupJoinRequest, error) {
+	request, err := uc.pendingJoinRequest(ctx, scope, groupID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.reviewJoinRequest(ctx, request, domain.JoinRequestRejected, reviewerID, comment); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// pendingJoinRequest возвращает нерассмотренную заявку, относящуюся к группе groupID
+func (uc *groupUseCase) pendingJoinRequest(ctx context.Context, scope domain.GroupScope, groupID, requestID uint) (*domain.GroupJoinRequest, error) {
+	if !scope.Allows(groupID) {
+		return nil, ErrOutOfGroupScope
+	}
+	request, err := uc.groupRepo.GetJoinRequest(ctx, requestID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJoinRequestNotFound
+		}
+		return nil, err
+	}
+	if request.GroupID != groupID {
+		return nil, ErrJoinRequestNotFound
+	}
+	if request.Status != domain.JoinRequestPending {
+		return nil, ErrJoinRequestNotPending
+	}
+	return request, nil
+}
+
+func (uc *groupUseCase) reviewJoinRequest(ctx context.Context, request *domain.GroupJoinRequest, status string, reviewerID uint, comment string) error {
+	now := time.Now()
+	request.Status = status
+	request.ReviewedBy = &reviewerID
+	request.ReviewComment = strings.TrimSpace(comment)
+	request.ReviewedAt = &now
+	if err := uc.groupRepo.UpdateJoinRequest(ctx, request); err != nil {
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Group join request reviewed", slog.Uint64("requestID", uint64(request.ID)), slog.String("status", status), slog.Uint64("reviewer_id", uint64(reviewerID)))
+
+	if request.Contact != nil {
+		verdict := "одобрена"
+		if status == domain.JoinRequestRejected {
+			verdict = "отклонена"
+		}
+		text := fmt.Sprintf("Ваша заявка на вступление в группу %q %s", groupName(request), verdict)
+		if request.ReviewComment != "" {
+			text += ": " + request.ReviewComment
+		}
+		uc.notifier.NotifyContact(ctx, request.Contact, "RIM: заявка на вступление в группу", text)
+	}
+	return nil
+}
+
+func contactName(request *domain.GroupJoinRequest) string {
+	if request.Contact != nil {
+		return fmt.Sprintf("%s (#%d)", request.Contact.Name, request.ContactID)
+	}
+	return fmt.Sprintf("Контакт #%d", request.ContactID)
+}
+
+func groupName(request *domain.GroupJoinRequest) string {
+	if request.Group != nil {
+		return request.Group.Name
+	}
+	return fmt.Sprintf("#%d", request.GroupID)
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"rim/internal/domain"
+)
+
+const membershipExpiredSubject = "RIM: истек срок участия в группе"
+
+// ExpireMemberships исключает из групп контакты с истекшим сроком членства.
+// Контакт получает уведомление об исключении, модераторы группы - список исключенных;
+// если у группы нет модераторов, список получают администраторы.
+func (uc *groupUseCase) ExpireMemberships(ctx context.Context) (int, error) {
+	memberships, err := uc.groupRepo.GetExpiredMemberships(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	removed := make(map[uint][]string) // ID группы -> имена исключенных контактов
+	groups := make(map[uint]*domain.Group)
+	for _, m := range memberships {
+		err := uc.tx.Run(ctx, func(ctx context.Context) error {
+			if err := uc.groupRepo.RemoveMember(ctx, m.GroupID, m.ContactID, nil, domain.MembershipSourceExpired); err != nil {
+				return err
+			}
+			return uc.events.Publish(ctx, domain.GroupMembershipChangedEvent{
+				GroupID:   m.GroupID,
+				ContactID: m.ContactID,
+				Type:      domain.MembershipLeft,
+				Source:    domain.MembershipSourceExpired,
+			})
+		})
+		if err != nil {
+			return 0, err
+		}
+		groupName := fmt.Sprintf("#%d", m.GroupID)
+		if m.Group != nil {
+			groupName = m.Group.Name
+			groups[m.GroupID] = m.Group
+		}
+		contactName := fmt.Sprintf("Контакт #%d", m.ContactID)
+		if m.Contact != nil {
+			contactName = fmt.Sprintf("%s (#%d)", m.Contact.Name, m.ContactID)
+			uc.notifier.NotifyContact(ctx, m.Contact, membershipExpiredSubject,
+				fmt.Sprintf("Срок вашего участия в группе %q истек, вы исключены из группы", groupName))
+		}
+		removed[m.GroupID] = append(removed[m.GroupID], contactName)
+	}
+
+	for groupID, names := range removed {
+		groupName := fmt.Sprintf("#%d", groupID)
+		if g := groups[groupID]; g != nil {
+			groupName = g.Name
+		}
+		text := fmt.Sprintf("Из группы %q исключены по истечении срока участия:\n%s", groupName, strings.Join(names, "\n"))
+		if err := uc.notifyLeads(ctx, groupID, membershipExpiredSubject, text); err != nil {
+			uc.logger.WarnContext(ctx, "Failed to notify group moderators about expired memberships", slog.Uint64("groupID", uint64(groupID)), slog.Any("error", err))
+		}
+	}
+
+	if len(memberships) > 0 {
+		uc.logger.InfoContext(ctx, "Expired group memberships removed", slog.Int("count", len(memberships)), slog.Int("groups", len(removed)))
+	}
+	return len(memberships), nil
+}
+
+func (uc *groupUseCase) RunMembershipExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := uc.ExpireMemberships(ctx); err != nil {
+			uc.logger.ErrorContext(ctx, "Failed to expire group memberships", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// notifyLeads отправляет сообщение модераторам группы, а при их отсутствии - администраторам
+func (uc *groupUseCase) notifyLeads(ctx context.Context, groupID uint, subject, text string) error {
+	moderators, err := uc.groupRepo.GetModerators(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	sent := false
+	for _, m := range moderators {
+		if m.User != nil && m.User.Contact != nil {
+			uc.notifier.NotifyContact(ctx, m.User.Contact, subject, text)
+			sent = true
+		}
+	}
+	if !sent {
+		uc.notifier.NotifyAdmins(ctx, subject, text)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrModeratorUserNotFound = apperror.NotFound("moderator_user_not_found", "user not found")
+	ErrModeratorExists       = apperror.Conflict("moderator_exists", "user is already a moderator of this group")
+	ErrModeratorNotFound     = apperror.NotFound("moderator_not_found", "user is not a moderator of this group")
+)
+
+// AddModerator назначает пользователя модератором группы.
+func (uc *groupUseCase) AddModerator(ctx context.Context, groupID, userID, createdBy uint) (*domain.GroupModerator, error) {
+	if _, err := uc.GetGroupByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+	exists, err := uc.groupRepo.UserExists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrModeratorUserNotFound
+	}
+
+	moderators, err := uc.groupRepo.GetModerators(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range moderators {
+		if m.UserID == userID {
+			return nil, ErrModeratorExists
+		}
+	}
+
+	moderator := &domain.GroupModerator{GroupID: groupID, UserID: userID, CreatedBy: &createdBy}
+	if err := uc.groupRepo.AddModerator(ctx, moderator); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Group moderator added", slog.Uint64("groupID", uint64(groupID)), slog.Uint64("userID", uint64(userID)), slog.Uint64("created_by", uint64(createdBy)))
+	return moderator, nil
+}
+
+// RemoveModerator снимает пользователя с модерации группы.
+func (uc *groupUseCase) RemoveModerator(ctx context.Context, groupID, userID uint) error {
+	if err := uc.groupRepo.RemoveModerator(ctx, groupID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrModeratorNotFound
+		}
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Group moderator removed", slog.Uint64("groupID", uint64(groupID)), slog.Uint64("userID", uint64(userID)))
+	return nil
+}
+
+func (uc *groupUseCase) GetModerators(ctx context.Context, groupID uint) ([]domain.GroupModerator, error) {
+	if _, err := uc.GetGroupByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+	return uc.groupRepo.GetModerators(ctx, groupID)
+}
+
+func (uc *groupUseCase) GetModeratedGroups(ctx context.Context, userID uint) ([]domain.Group, error) {
+	return uc.groupRepo.GetModeratedGroups(ctx, userID)
+}
+
+func (uc *groupUseCase) ModeratorScope(ctx context.Context, userID uint) (domain.GroupScope, error) {
+	groups, err := uc.groupRepo.GetModeratedGroups(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	scope := make(domain.GroupScope, len(groups))
+	for i, g := range groups {
+		scope[i] = g.ID
+	}
+	return scope, nil
+}
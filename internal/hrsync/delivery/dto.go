@@ -0,0 +1,29 @@
+package delivery
+
+// HRSyncRunResponse - запуск синхронизации с кадровой системой
+type HRSyncRunResponse struct {
+	ID         uint                    `json:"id"`
+	Trigger    string                  `json:"trigger"` // schedule или manual
+	Status     string                  `json:"status"`  // running, succeeded или failed
+	StartedBy  *uint                   `json:"started_by,omitempty"`
+	Fetched    int                     `json:"fetched"`
+	Created    int                     `json:"created"`
+	Linked     int                     `json:"linked"`
+	Updated    int                     `json:"updated"`
+	Archived   int                     `json:"archived"`
+	Restored   int                     `json:"restored"`
+	Pushed     int                     `json:"pushed"`
+	Failed     int                     `json:"failed"`
+	Error      string                  `json:"error,omitempty"`
+	StartedAt  string                  `json:"started_at"`
+	FinishedAt string                  `json:"finished_at,omitempty"`
+	Items      []HRSyncRunItemResponse `json:"items,omitempty"` // Только в ответе на запрос одного запуска
+}
+
+// HRSyncRunItemResponse - изменение или ошибка по одной записи источника
+type HRSyncRunItemResponse struct {
+	ExternalID string `json:"external_id"`
+	ContactID  *uint  `json:"contact_id,omitempty"`
+	Action     string `json:"action"`
+	Details    string `json:"details,omitempty"`
+}
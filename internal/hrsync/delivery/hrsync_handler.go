@@ -0,0 +1,147 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/internal/hrsync/usecase"
+	"rim/pkg/requestctx"
+	"rim/pkg/timeutil"
+)
+
+// Handler отвечает за HTTP-запросы синхронизации с кадровой системой.
+type Handler struct {
+	hrSyncUseCase usecase.UseCase
+	logger        *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для синхронизации с кадровой системой.
+func NewHandler(hu usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		hrSyncUseCase: hu,
+		logger:        logger,
+	}
+}
+
+// StartHRSync запускает синхронизацию с кадровой системой.
+// @Summary Запустить синхронизацию с кадровой системой
+// @Description Запускает сверку в фоне: записи источника создают и обновляют контакты, контакты без записей переводятся в alumni,
+// @Description изменения полей портала (HR_SYNC_PUSH_FIELDS) отправляются в источник. Итог и отчет - в GET /hr-sync/runs/{id}.
+// @Tags hr-sync
+// @Produce json
+// @Success 202 {object} HRSyncRunResponse
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Доступ запрещен"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Синхронизация уже выполняется"
+// @Failure 503 {object} groupDelivery.ErrorResponse "Синхронизация не настроена (HR_SYNC_URL)"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /hr-sync/runs [post]
+func (h *Handler) StartHRSync(c *fiber.Ctx) error {
+	run, err := h.hrSyncUseCase.Start(c.Context(), requestctx.ActorID(c))
+	if err != nil {
+		return h.hrSyncError(c, err)
+	}
+	return c.Status(fiber.StatusAccepted).JSON(toRunResponse(run, requestctx.Location(c)))
+}
+
+// GetHRSyncRuns возвращает последние запуски синхронизации.
+// @Summary Запуски синхронизации с кадровой системой
+// @Description Возвращает 50 последних запусков со счетчиками, без элементов отчета.
+// @Tags hr-sync
+// @Produce json
+// @Success 200 {array} HRSyncRunResponse
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /hr-sync/runs [get]
+func (h *Handler) GetHRSyncRuns(c *fiber.Ctx) error {
+	runs, err := h.hrSyncUseCase.GetRuns(c.Context())
+	if err != nil {
+		return h.hrSyncError(c, err)
+	}
+	loc := requestctx.Location(c)
+	resp := make([]HRSyncRunResponse, len(runs))
+	for i := range runs {
+		resp[i] = toRunResponse(&runs[i], loc)
+	}
+	return c.JSON(resp)
+}
+
+// GetHRSyncRun возвращает запуск синхронизации с отчетом о сверке.
+// @Summary Отчет о синхронизации с кадровой системой
+// @Description Элементы отчета - созданные, сопоставленные, обновленные, архивированные, восстановленные и отправленные в источник контакты,
+// @Description а также записи, которые не удалось обработать. Записи без изменений в отчет не попадают.
+// @Tags hr-sync
+// @Produce json
+// @Param id path int true "ID запуска"
+// @Success 200 {object} HRSyncRunResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Запуск не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /hr-sync/runs/{id} [get]
+func (h *Handler) GetHRSyncRun(c *fiber.Ctx) error {
+	runID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid run ID format"})
+	}
+	run, err := h.hrSyncUseCase.GetRun(c.Context(), uint(runID))
+	if err != nil {
+		return h.hrSyncError(c, err)
+	}
+	resp := toRunResponse(run, requestctx.Location(c))
+	resp.Items = make([]HRSyncRunItemResponse, len(run.Items))
+	for i, item := range run.Items {
+		resp.Items[i] = HRSyncRunItemResponse{
+			ExternalID: item.ExternalID,
+			ContactID:  item.ContactID,
+			Action:     item.Action,
+			Details:    item.Details,
+		}
+	}
+	return c.JSON(resp)
+}
+
+// hrSyncError преобразует ошибку UseCase в HTTP-ответ
+func (h *Handler) hrSyncError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrRunNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrSyncRunning):
+		return c.Status(fiber.StatusConflict).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrNotConfigured):
+		return c.Status(fiber.StatusServiceUnavailable).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	}
+	h.logger.ErrorContext(c.Context(), "HR sync request failed", slog.Any("error", err))
+	return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+}
+
+func toRunResponse(run *domain.HRSyncRun, loc *time.Location) HRSyncRunResponse {
+	resp := HRSyncRunResponse{
+		ID:        run.ID,
+		Trigger:   run.Trigger,
+		Status:    run.Status,
+		StartedBy: run.StartedBy,
+		Fetched:   run.Fetched,
+		Created:   run.Created,
+		Linked:    run.Linked,
+		Updated:   run.Updated,
+		Archived:  run.Archived,
+		Restored:  run.Restored,
+		Pushed:    run.Pushed,
+		Failed:    run.Failed,
+		Error:     run.Error,
+		StartedAt: timeutil.Format(run.StartedAt, loc),
+	}
+	if run.FinishedAt != nil {
+		resp.FinishedAt = timeutil.Format(*run.FinishedAt, loc)
+	}
+	return resp
+}
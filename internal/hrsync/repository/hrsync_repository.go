@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+var ErrRunNotFound = errors.New("hr sync run not found")
+
+// Repository определяет интерфейс хранения связей с кадровой системой и отчетов о синхронизации
+type Repository interface {
+	// GetLinks возвращает связи организации запроса
+	GetLinks(ctx context.Context) ([]domain.HRSyncLink, error)
+	// SaveLink создает или обновляет связь
+	SaveLink(ctx context.Context, link *domain.HRSyncLink) error
+
+	CreateRun(ctx context.Context, run *domain.HRSyncRun) error
+	// UpdateRun сохраняет счетчики и статус запуска без элементов отчета
+	UpdateRun(ctx context.Context, run *domain.HRSyncRun) error
+	AddRunItem(ctx context.Context, item *domain.HRSyncRunItem) error
+	// GetRuns возвращает до limit последних запусков организации без элементов отчета
+	GetRuns(ctx context.Context, limit int) ([]domain.HRSyncRun, error)
+	// GetRun возвращает запуск организации с элементами отчета
+	GetRun(ctx context.Context, id uint) (*domain.HRSyncRun, error)
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр репозитория синхронизации с кадровой системой
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{db: db, logger: logger}
+}
+
+func (r *sqliteRepository) GetLinks(ctx context.Context) ([]domain.HRSyncLink, error) {
+	var links []domain.HRSyncLink
+	if err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "hr_sync_links")).Find(&links).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting HR sync links from DB", slog.Any("error", err))
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *sqliteRepository) SaveLink(ctx context.Context, link *domain.HRSyncLink) error {
+	if orgID := tenant.FromContext(ctx); orgID != 0 && link.OrganizationID == 0 {
+		link.OrganizationID = orgID
+	}
+	if err := transaction.DB(ctx, r.db).Save(link).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error saving HR sync link in DB", slog.String("externalID", link.ExternalID), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) CreateRun(ctx context.Context, run *domain.HRSyncRun) error {
+	if orgID := tenant.FromContext(ctx); orgID != 0 && run.OrganizationID == 0 {
+		run.OrganizationID = orgID
+	}
+	if err := transaction.DB(ctx, r.db).Omit("Items").Create(run).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error creating HR sync run in DB", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) UpdateRun(ctx context.Context, run *domain.HRSyncRun) error {
+	if err := transaction.DB(ctx, r.db).Omit("Items").Save(run).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error updating HR sync run in DB", slog.Uint64("runID", uint64(run.ID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) AddRunItem(ctx context.Context, item *domain.HRSyncRunItem) error {
+	if err := transaction.DB(ctx, r.db).Create(item).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error creating HR sync run item in DB", slog.Uint64("runID", uint64(item.RunID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) GetRuns(ctx context.Context, limit int) ([]domain.HRSyncRun, error) {
+	var runs []domain.HRSyncRun
+	err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "hr_sync_runs")).
+		Order("started_at DESC, id DESC").Limit(limit).Find(&runs).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error getting HR sync runs from DB", slog.Any("error", err))
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (r *sqliteRepository) GetRun(ctx context.Context, id uint) (*domain.HRSyncRun, error) {
+	var run domain.HRSyncRun
+	err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "hr_sync_runs")).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&run, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRunNotFound
+		}
+		r.logger.ErrorContext(ctx, "Error getting HR sync run from DB", slog.Uint64("runID", uint64(id)), slog.Any("error", err))
+		return nil, err
+	}
+	return &run, nil
+}
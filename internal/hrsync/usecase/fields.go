@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+)
+
+// FieldExternalID - поле сопоставления, в котором источник передает идентификатор записи
+const FieldExternalID = "id"
+
+// requiredFields - поля, без которых запись источника не превратить в контакт
+var requiredFields = []string{FieldExternalID, "name", "phone", "email"}
+
+// contactField описывает поле контакта, которое можно сопоставить с полем источника
+type contactField struct {
+	get    func(c *domain.Contact) string
+	create func(d *contactUseCase.CreateContactData, v string)
+	update func(d *contactUseCase.UpdateContactData, v string)
+}
+
+// contactFields - поля контакта, доступные для синхронизации
+var contactFields = map[string]contactField{
+	"name": {
+		get:    func(c *domain.Contact) string { return c.Name },
+		create: func(d *contactUseCase.CreateContactData, v string) { d.Name = v },
+		update: func(d *contactUseCase.UpdateContactData, v string) { d.Name = &v },
+	},
+	"phone": {
+		get:    func(c *domain.Contact) string { return c.Phone },
+		create: func(d *contactUseCase.CreateContactData, v string) { d.Phone = v },
+		update: func(d *contactUseCase.UpdateContactData, v string) { d.Phone = &v },
+	},
+	"email": {
+		get:    func(c *domain.Contact) string { return c.Email },
+		create: func(d *contactUseCase.CreateContactData, v string) { d.Email = v },
+		update: func(d *contactUseCase.UpdateContactData, v string) { d.Email = &v },
+	},
+	"telegram": {
+		get:    func(c *domain.Contact) string { return c.Telegram },
+		create: func(d *contactUseCase.CreateContactData, v string) { d.Telegram = v },
+		update: func(d *contactUseCase.UpdateContactData, v string) { d.Telegram = &v },
+	},
+	"vk": {
+		get:    func(c *domain.Contact) string { return c.VK },
+		create: func(d *contactUseCase.CreateContactData, v string) { d.VK = v },
+		update: func(d *contactUseCase.UpdateContactData, v string) { d.VK = &v },
+	},
+	"transport": {
+		get:    func(c *domain.Contact) string { return c.Transport },
+		create: func(d *contactUseCase.CreateContactData, v string) { d.Transport = v },
+		update: func(d *contactUseCase.UpdateContactData, v string) { d.Transport = &v },
+	},
+	"printer": {
+		get:    func(c *domain.Contact) string { return c.Printer },
+		create: func(d *contactUseCase.CreateContactData, v string) { d.Printer = v },
+		update: func(d *contactUseCase.UpdateContactData, v string) { d.Printer = &v },
+	},
+	"allergies": {
+		get:    func(c *domain.Contact) string { return c.Allergies },
+		create: func(d *contactUseCase.CreateContactData, v string) { d.Allergies = v },
+		update: func(d *contactUseCase.UpdateContactData, v string) { d.Allergies = &v },
+	},
+	"city": {
+		get:    func(c *domain.Contact) string { return c.City },
+		create: func(d *contactUseCase.CreateContactData, v string) { d.Location.City = v },
+		update: func(d *contactUseCase.UpdateContactData, v string) { d.City = &v },
+	},
+	"campus": {
+		get:    func(c *domain.Contact) string { return c.Campus },
+		create: func(d *contactUseCase.CreateContactData, v string) { d.Location.Campus = v },
+		update: func(d *contactUseCase.UpdateContactData, v string) { d.Campus = &v },
+	},
+	"building": {
+		get:    func(c *domain.Contact) string { return c.Building },
+		create: func(d *contactUseCase.CreateContactData, v string) { d.Location.Building = v },
+		update: func(d *contactUseCase.UpdateContactData, v string) { d.Building = &v },
+	},
+	"room": {
+		get:    func(c *domain.Contact) string { return c.Room },
+		create: func(d *contactUseCase.CreateContactData, v string) { d.Location.Room = v },
+		update: func(d *contactUseCase.UpdateContactData, v string) { d.Room = &v },
+	},
+	"department": {
+		get:    func(c *domain.Contact) string { return c.Department },
+		create: func(d *contactUseCase.CreateContactData, v string) { d.Department = v },
+		update: func(d *contactUseCase.UpdateContactData, v string) { d.Department = &v },
+	},
+	"position": {
+		get:    func(c *domain.Contact) string { return c.Position },
+		create: func(d *contactUseCase.CreateContactData, v string) { d.Position = v },
+		update: func(d *contactUseCase.UpdateContactData, v string) { d.Position = &v },
+	},
+}
+
+// recordData повторяет правила проверки CreateContactRequest для значений записи источника
+type recordData struct {
+	Name       string `json:"name" validate:"required,length=contact_name"`
+	Phone      string `json:"phone" validate:"required,e164"`
+	Email      string `json:"email" validate:"required,email"`
+	Transport  string `json:"transport" validate:"omitempty,enum=transport"`
+	Printer    string `json:"printer" validate:"omitempty,enum=printer"`
+	Allergies  string `json:"allergies" validate:"omitempty,max=255"`
+	VK         string `json:"vk" validate:"omitempty,vk_url"`
+	Telegram   string `json:"telegram" validate:"omitempty,telegram_username"`
+	City       string `json:"city" validate:"omitempty,max=100"`
+	Campus     string `json:"campus" validate:"omitempty,max=100"`
+	Building   string `json:"building" validate:"omitempty,max=100"`
+	Room       string `json:"room" validate:"omitempty,max=100"`
+	Department string `json:"department" validate:"omitempty,max=100"`
+	Position   string `json:"position" validate:"omitempty,max=100"`
+}
+
+func newRecordData(values map[string]string) recordData {
+	return recordData{
+		Name: values["name"], Phone: values["phone"], Email: values["email"],
+		Transport: values["transport"], Printer: values["printer"], Allergies: values["allergies"],
+		VK: values["vk"], Telegram: values["telegram"],
+		City: values["city"], Campus: values["campus"], Building: values["building"], Room: values["room"],
+		Department: values["department"], Position: values["position"],
+	}
+}
+
+// Mapping сопоставляет поля контакта (и FieldExternalID) полям записи источника
+type Mapping map[string]string
+
+// ParseMapping разбирает сопоставление вида "id=employee_id,name=full_name,phone=mobile,email=work_email".
+// Поля id, name, phone и email обязательны.
+func ParseMapping(s string) (Mapping, error) {
+	mapping := Mapping{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		field, key, ok := strings.Cut(pair, "=")
+		field, key = strings.ToLower(strings.TrimSpace(field)), strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid mapping entry %q, expected field=source_key", pair)
+		}
+		if _, known := contactFields[field]; !known && field != FieldExternalID {
+			return nil, fmt.Errorf("unknown contact field %q in mapping", field)
+		}
+		mapping[field] = key
+	}
+	for _, field := range requiredFields {
+		if mapping[field] == "" {
+			return nil, fmt.Errorf("mapping must include field %q", field)
+		}
+	}
+	return mapping, nil
+}
+
+// ParsePushFields разбирает список полей контакта, изменения которых отправляются в источник.
+// Каждое поле должно быть в сопоставлении; id, name, phone и email принадлежат источнику и не отправляются.
+func ParsePushFields(s string, mapping Mapping) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(s, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" || slices.Contains(fields, field) {
+			continue
+		}
+		if slices.Contains(requiredFields, field) {
+			return nil, fmt.Errorf("field %q is owned by the source and cannot be pushed", field)
+		}
+		if _, ok := mapping[field]; !ok {
+			return nil, fmt.Errorf("push field %q is not in the mapping", field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// values возвращает значения полей контакта из записи источника по сопоставлению
+func (m Mapping) values(record map[string]string) map[string]string {
+	values := make(map[string]string, len(m))
+	for field, key := range m {
+		values[field] = record[key]
+	}
+	return values
+}
@@ -0,0 +1,443 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	contactRepo "rim/internal/contact/repository"
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	"rim/internal/hrsync/repository"
+	"rim/pkg/tenant"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// runsLimit - сколько последних запусков возвращает GetRuns
+const runsLimit = 50
+
+var (
+	ErrRunNotFound   = repository.ErrRunNotFound
+	ErrNotConfigured = errors.New("hr sync is not configured")
+	ErrSyncRunning   = errors.New("hr sync is already running")
+	// errEmptySource - источник вернул пустой список; вероятнее всего, это сбой, а не увольнение всех сотрудников
+	errEmptySource = errors.New("hr source returned no records, nothing was changed")
+)
+
+// Config - настройки подключения к кадровой системе
+type Config struct {
+	// URL - адрес списка сотрудников (GET); изменения отправляются запросом PATCH {URL}/{id}. Пусто - синхронизация отключена.
+	URL string
+	// Token - bearer-токен источника, читается при каждом запросе
+	Token func() string
+	// RecordsKey - поле ответа с массивом записей; пусто - массив в корне ответа
+	RecordsKey string
+	Mapping    Mapping
+	// PushFields - поля, которыми владеет портал: их изменения отправляются в источник, а не берутся из него
+	PushFields []string
+}
+
+// UseCase определяет интерфейс двусторонней синхронизации контактов с кадровой системой.
+// Поля из сопоставления, кроме PushFields, принадлежат источнику: новые записи создают контакты, изменения записей
+// обновляют контакты, а контакты, чьих записей больше нет, переводятся в alumni. Изменения PushFields в портале
+// отправляются в источник. Каждый запуск сохраняет отчет о сверке.
+type UseCase interface {
+	// Start запускает синхронизацию вручную в фоне и возвращает созданный запуск.
+	// Если синхронизация не настроена или уже выполняется, возвращает ErrNotConfigured или ErrSyncRunning.
+	Start(ctx context.Context, startedBy *uint) (*domain.HRSyncRun, error)
+	// RunSchedule выполняет синхронизацию каждые interval до отмены ctx
+	RunSchedule(ctx context.Context, interval time.Duration)
+	// GetRuns возвращает последние запуски без элементов отчета
+	GetRuns(ctx context.Context) ([]domain.HRSyncRun, error)
+	// GetRun возвращает запуск с отчетом о сверке
+	GetRun(ctx context.Context, id uint) (*domain.HRSyncRun, error)
+}
+
+type hrSyncUseCase struct {
+	repo           repository.Repository
+	contactRepo    contactRepo.Repository
+	contactUseCase contactUseCase.UseCase
+	cfg            Config
+	source         *sourceClient
+	running        atomic.Bool
+	validate       *validator.Validate
+	logger         *slog.Logger
+}
+
+// NewHRSyncUseCase создает новый экземпляр UseCase для синхронизации с кадровой системой
+func NewHRSyncUseCase(repo repository.Repository, cr contactRepo.Repository, cu contactUseCase.UseCase, cfg Config, logger *slog.Logger) UseCase {
+	return &hrSyncUseCase{
+		repo:           repo,
+		contactRepo:    cr,
+		contactUseCase: cu,
+		cfg:            cfg,
+		source:         newSourceClient(cfg),
+		validate:       validation.Default(),
+		logger:         logger,
+	}
+}
+
+func (uc *hrSyncUseCase) Start(ctx context.Context, startedBy *uint) (*domain.HRSyncRun, error) {
+	run, err := uc.begin(ctx, domain.HRSyncTriggerManual, startedBy)
+	if err != nil {
+		return nil, err
+	}
+	// Синхронизация переживает HTTP-запрос, который ее запустил
+	runCtx := tenant.With(context.Background(), tenant.FromContext(ctx))
+	result := *run
+	go uc.execute(runCtx, run)
+	return &result, nil
+}
+
+func (uc *hrSyncUseCase) RunSchedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if run, err := uc.begin(ctx, domain.HRSyncTriggerSchedule, nil); err == nil {
+			uc.execute(ctx, run)
+		} else if !errors.Is(err, ErrSyncRunning) {
+			uc.logger.ErrorContext(ctx, "Failed to start scheduled HR sync", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (uc *hrSyncUseCase) GetRuns(ctx context.Context) ([]domain.HRSyncRun, error) {
+	return uc.repo.GetRuns(ctx, runsLimit)
+}
+
+func (uc *hrSyncUseCase) GetRun(ctx context.Context, id uint) (*domain.HRSyncRun, error) {
+	return uc.repo.GetRun(ctx, id)
+}
+
+// begin занимает синхронизацию и сохраняет запуск; освобождает ее execute
+func (uc *hrSyncUseCase) begin(ctx context.Context, trigger string, startedBy *uint) (*domain.HRSyncRun, error) {
+	if uc.cfg.URL == "" {
+		return nil, ErrNotConfigured
+	}
+	if !uc.running.CompareAndSwap(false, true) {
+		return nil, ErrSyncRunning
+	}
+	run := &domain.HRSyncRun{
+		Trigger:   trigger,
+		Status:    domain.HRSyncRunning,
+		StartedBy: startedBy,
+		StartedAt: timeutil.Now(),
+	}
+	if err := uc.repo.CreateRun(ctx, run); err != nil {
+		uc.running.Store(false)
+		return nil, err
+	}
+	return run, nil
+}
+
+// execute выполняет сверку и сохраняет итог запуска
+func (uc *hrSyncUseCase) execute(ctx context.Context, run *domain.HRSyncRun) {
+	defer uc.running.Store(false)
+
+	err := uc.reconcile(ctx, run)
+	finishedAt := timeutil.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = domain.HRSyncSucceeded
+	if err != nil {
+		run.Status = domain.HRSyncFailed
+		run.Error = err.Error()
+		uc.logger.ErrorContext(ctx, "HR sync failed", slog.Uint64("runID", uint64(run.ID)), slog.Any("error", err))
+	}
+	if err := uc.repo.UpdateRun(ctx, run); err != nil {
+		return
+	}
+	uc.logger.InfoContext(ctx, "HR sync finished", slog.Uint64("runID", uint64(run.ID)), slog.String("status", run.Status),
+		slog.Int("fetched", run.Fetched), slog.Int("created", run.Created), slog.Int("updated", run.Updated),
+		slog.Int("archived", run.Archived), slog.Int("pushed", run.Pushed), slog.Int("failed", run.Failed))
+}
+
+// reconcile сверяет записи источника с контактами. Ошибки отдельных записей попадают в отчет,
+// возвращается только ошибка, из-за которой продолжать сверку бессмысленно.
+func (uc *hrSyncUseCase) reconcile(ctx context.Context, run *domain.HRSyncRun) error {
+	records, err := uc.source.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	run.Fetched = len(records)
+	if len(records) == 0 {
+		return errEmptySource
+	}
+
+	links, err := uc.repo.GetLinks(ctx)
+	if err != nil {
+		return err
+	}
+	byExternalID := make(map[string]*domain.HRSyncLink, len(links))
+	linkedContacts := make(map[uint]string, len(links))
+	for i := range links {
+		byExternalID[links[i].ExternalID] = &links[i]
+		linkedContacts[links[i].ContactID] = links[i].ExternalID
+	}
+
+	seen := make(map[string]bool, len(records))
+	for _, record := range records {
+		values := uc.cfg.Mapping.values(record)
+		externalID := values[FieldExternalID]
+		delete(values, FieldExternalID)
+		switch {
+		case externalID == "":
+			if err := uc.report(ctx, run, domain.HRSyncActionFailed, "", nil, "record has no "+uc.cfg.Mapping[FieldExternalID]); err != nil {
+				return err
+			}
+			continue
+		case seen[externalID]:
+			if err := uc.report(ctx, run, domain.HRSyncActionFailed, externalID, nil, "duplicate record id in the source"); err != nil {
+				return err
+			}
+			continue
+		}
+		seen[externalID] = true
+		if err := uc.validate.Struct(newRecordData(values)); err != nil {
+			if err := uc.report(ctx, run, domain.HRSyncActionFailed, externalID, nil, validation.NewResponse(err, validation.LangEN).Message); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := uc.syncRecord(ctx, run, externalID, values, byExternalID, linkedContacts); err != nil {
+			return err
+		}
+	}
+
+	// Записи, пропавшие из источника
+	for i := range links {
+		link := &links[i]
+		if seen[link.ExternalID] || link.ArchivedAt != nil {
+			continue
+		}
+		if err := uc.archive(ctx, run, link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncRecord создает, сопоставляет или обновляет контакт по записи источника и отправляет в источник изменения портала.
+// Возвращает ошибку только при сбое хранилища.
+func (uc *hrSyncUseCase) syncRecord(ctx context.Context, run *domain.HRSyncRun, externalID string, values map[string]string,
+	byExternalID map[string]*domain.HRSyncLink, linkedContacts map[uint]string) error {
+	link := byExternalID[externalID]
+	var contact *domain.Contact
+	var err error
+
+	if link == nil {
+		contact = uc.findExisting(ctx, values)
+		if contact == nil {
+			return uc.create(ctx, run, externalID, values, linkedContacts)
+		}
+		// Телефон и email уникальны во всем развертывании, поэтому контакт может оказаться в другой организации
+		if orgID := tenant.FromContext(ctx); orgID != 0 && contact.OrganizationID != orgID {
+			return uc.report(ctx, run, domain.HRSyncActionFailed, externalID, nil, "phone or email belongs to a contact of another organization")
+		}
+		if other, ok := linkedContacts[contact.ID]; ok {
+			return uc.report(ctx, run, domain.HRSyncActionFailed, externalID, &contact.ID, "contact with the same phone or email is linked to record "+other)
+		}
+		link = &domain.HRSyncLink{ExternalID: externalID, ContactID: contact.ID}
+		if err := uc.repo.SaveLink(ctx, link); err != nil {
+			return err
+		}
+		linkedContacts[contact.ID] = externalID
+		if err := uc.report(ctx, run, domain.HRSyncActionLinked, externalID, &contact.ID, ""); err != nil {
+			return err
+		}
+	} else {
+		contact, err = uc.contactUseCase.GetContactByID(ctx, link.ContactID)
+		if errors.Is(err, contactUseCase.ErrContactNotFound) {
+			return uc.report(ctx, run, domain.HRSyncActionFailed, externalID, &link.ContactID, "contact was deleted in the portal; restore it or remove the record from the source")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if link.ArchivedAt != nil {
+		if contact.Status == domain.ContactStatusAlumni {
+			if contact, err = uc.contactUseCase.ChangeContactStatus(ctx, contact.ID, domain.ContactStatusActive); err != nil {
+				return uc.report(ctx, run, domain.HRSyncActionFailed, externalID, &link.ContactID, err.Error())
+			}
+		}
+		link.ArchivedAt = nil
+		if err := uc.repo.SaveLink(ctx, link); err != nil {
+			return err
+		}
+		if err := uc.report(ctx, run, domain.HRSyncActionRestored, externalID, &contact.ID, ""); err != nil {
+			return err
+		}
+	}
+
+	if hash := hashValues(uc.pulledValues(values)); hash != link.PulledHash {
+		data := contactUseCase.UpdateContactData{}
+		var changed []string
+		for field, value := range values {
+			current := contactFields[field].get(contact)
+			// Поле портала берется из источника, только пока в портале оно пустое
+			if slices.Contains(uc.cfg.PushFields, field) && (current != "" || value == "") {
+				continue
+			}
+			if value != current {
+				contactFields[field].update(&data, value)
+				changed = append(changed, field)
+			}
+		}
+		if len(changed) > 0 {
+			updated, err := uc.contactUseCase.UpdateContact(ctx, contact.ID, data)
+			if err != nil {
+				// Хэш не сохраняется, чтобы повторить обновление при следующем запуске
+				return uc.report(ctx, run, domain.HRSyncActionFailed, externalID, &contact.ID, err.Error())
+			}
+			contact = updated
+			sort.Strings(changed)
+			if err := uc.report(ctx, run, domain.HRSyncActionUpdated, externalID, &contact.ID, strings.Join(changed, ", ")); err != nil {
+				return err
+			}
+		}
+		link.PulledHash = hash
+		if err := uc.repo.SaveLink(ctx, link); err != nil {
+			return err
+		}
+	}
+
+	return uc.push(ctx, run, externalID, contact, values)
+}
+
+// create создает контакт по записи источника
+func (uc *hrSyncUseCase) create(ctx context.Context, run *domain.HRSyncRun, externalID string, values map[string]string, linkedContacts map[uint]string) error {
+	data := contactUseCase.CreateContactData{}
+	for field, value := range values {
+		contactFields[field].create(&data, value)
+	}
+	contact, err := uc.contactUseCase.CreateContact(ctx, data)
+	if err != nil {
+		return uc.report(ctx, run, domain.HRSyncActionFailed, externalID, nil, err.Error())
+	}
+	link := &domain.HRSyncLink{ExternalID: externalID, ContactID: contact.ID, PulledHash: hashValues(uc.pulledValues(values))}
+	if err := uc.repo.SaveLink(ctx, link); err != nil {
+		return err
+	}
+	linkedContacts[contact.ID] = externalID
+	return uc.report(ctx, run, domain.HRSyncActionCreated, externalID, &contact.ID, "")
+}
+
+// archive переводит в alumni контакт, чьей записи больше нет в источнике
+func (uc *hrSyncUseCase) archive(ctx context.Context, run *domain.HRSyncRun, link *domain.HRSyncLink) error {
+	contact, err := uc.contactUseCase.GetContactByID(ctx, link.ContactID)
+	if err != nil && !errors.Is(err, contactUseCase.ErrContactNotFound) {
+		return err
+	}
+	// Удаленный в портале контакт архивировать не нужно
+	if contact != nil && contact.Status != domain.ContactStatusAlumni {
+		if _, err := uc.contactUseCase.ChangeContactStatus(ctx, contact.ID, domain.ContactStatusAlumni); err != nil {
+			return uc.report(ctx, run, domain.HRSyncActionFailed, link.ExternalID, &link.ContactID, err.Error())
+		}
+	}
+	now := timeutil.Now()
+	link.ArchivedAt = &now
+	if err := uc.repo.SaveLink(ctx, link); err != nil {
+		return err
+	}
+	if contact == nil {
+		return nil
+	}
+	return uc.report(ctx, run, domain.HRSyncActionArchived, link.ExternalID, &link.ContactID, "")
+}
+
+// push отправляет в источник поля портала, которые отличаются от значений записи
+func (uc *hrSyncUseCase) push(ctx context.Context, run *domain.HRSyncRun, externalID string, contact *domain.Contact, values map[string]string) error {
+	body := map[string]string{}
+	var changed []string
+	for _, field := range uc.cfg.PushFields {
+		if current := contactFields[field].get(contact); current != values[field] {
+			body[uc.cfg.Mapping[field]] = current
+			changed = append(changed, field)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	if err := uc.source.push(ctx, externalID, body); err != nil {
+		return uc.report(ctx, run, domain.HRSyncActionFailed, externalID, &contact.ID, fmt.Sprintf("push %s: %v", strings.Join(changed, ", "), err))
+	}
+	return uc.report(ctx, run, domain.HRSyncActionPushed, externalID, &contact.ID, strings.Join(changed, ", "))
+}
+
+// findExisting ищет несвязанный контакт записи по телефону, затем по email
+func (uc *hrSyncUseCase) findExisting(ctx context.Context, values map[string]string) *domain.Contact {
+	if phone := values["phone"]; phone != "" {
+		if contact, err := uc.contactRepo.GetByPhone(ctx, phone); err == nil {
+			return contact
+		}
+	}
+	if email := values["email"]; email != "" {
+		if contact, err := uc.contactRepo.GetByEmail(ctx, email); err == nil {
+			return contact
+		}
+	}
+	return nil
+}
+
+// pulledValues возвращает значения полей, которыми владеет источник
+func (uc *hrSyncUseCase) pulledValues(values map[string]string) map[string]string {
+	pulled := make(map[string]string, len(values))
+	for field, value := range values {
+		if !slices.Contains(uc.cfg.PushFields, field) {
+			pulled[field] = value
+		}
+	}
+	return pulled
+}
+
+// report добавляет элемент в отчет запуска и увеличивает счетчик действия
+func (uc *hrSyncUseCase) report(ctx context.Context, run *domain.HRSyncRun, action, externalID string, contactID *uint, details string) error {
+	switch action {
+	case domain.HRSyncActionCreated:
+		run.Created++
+	case domain.HRSyncActionLinked:
+		run.Linked++
+	case domain.HRSyncActionUpdated:
+		run.Updated++
+	case domain.HRSyncActionArchived:
+		run.Archived++
+	case domain.HRSyncActionRestored:
+		run.Restored++
+	case domain.HRSyncActionPushed:
+		run.Pushed++
+	case domain.HRSyncActionFailed:
+		run.Failed++
+	}
+	return uc.repo.AddRunItem(ctx, &domain.HRSyncRunItem{
+		RunID:      run.ID,
+		ExternalID: externalID,
+		ContactID:  contactID,
+		Action:     action,
+		Details:    details,
+	})
+}
+
+// hashValues возвращает хэш значений полей; json.Marshal упорядочивает ключи
+func hashValues(values map[string]string) string {
+	data, _ := json.Marshal(values)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
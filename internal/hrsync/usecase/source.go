@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSourceResponse ограничивает размер ответа кадровой системы
+const maxSourceResponse = 32 << 20
+
+// errInvalidSourceResponse - источник вернул не то, что описано в настройках
+var errInvalidSourceResponse = errors.New("hr source returned an unexpected response")
+
+// sourceClient читает записи сотрудников из REST API кадровой системы и отправляет в него изменения
+type sourceClient struct {
+	url        string
+	token      func() string // Читается при каждом запросе, чтобы учитывать ротацию токена
+	recordsKey string
+	client     *http.Client
+}
+
+func newSourceClient(cfg Config) *sourceClient {
+	return &sourceClient{
+		url:        strings.TrimSuffix(cfg.URL, "/"),
+		token:      cfg.Token,
+		recordsKey: cfg.RecordsKey,
+		client:     &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// fetch возвращает записи источника: массив объектов в корне ответа или в поле recordsKey.
+// Значения приводятся к строкам, вложенные объекты и массивы пропускаются.
+func (s *sourceClient) fetch(ctx context.Context) ([]map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(io.LimitReader(resp.Body, maxSourceResponse))
+	decoder.UseNumber()
+	var raw []map[string]interface{}
+	if s.recordsKey == "" {
+		err = decoder.Decode(&raw)
+	} else {
+		var body map[string]json.RawMessage
+		if err = decoder.Decode(&body); err == nil {
+			// Отдельный декодер с UseNumber, чтобы числовые ID не превращались в float64
+			itemsDecoder := json.NewDecoder(bytes.NewReader(body[s.recordsKey]))
+			itemsDecoder.UseNumber()
+			err = itemsDecoder.Decode(&raw)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidSourceResponse, err)
+	}
+
+	records := make([]map[string]string, len(raw))
+	for i, item := range raw {
+		records[i] = make(map[string]string, len(item))
+		for key, value := range item {
+			switch v := value.(type) {
+			case string:
+				records[i][key] = strings.TrimSpace(v)
+			case json.Number:
+				records[i][key] = v.String()
+			case bool:
+				records[i][key] = strconv.FormatBool(v)
+			}
+		}
+	}
+	return records, nil
+}
+
+// push отправляет значения полей записи externalID запросом PATCH {url}/{externalID}
+func (s *sourceClient) push(ctx context.Context, externalID string, values map[string]string) error {
+	body, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, s.url+"/"+url.PathEscape(externalID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do выполняет запрос с токеном источника и возвращает ошибку для ответов не 2xx
+func (s *sourceClient) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Accept", "application/json")
+	if token := s.token(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("hr source %s returned status %d", req.Method, resp.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,26 @@
+package delivery
+
+// ImportRowResponse представляет строку файла импорта
+type ImportRowResponse struct {
+	RowNumber int               `json:"row_number"`
+	Status    string            `json:"status"`
+	Error     string            `json:"error,omitempty"`
+	Fields    map[string]string `json:"fields"`
+	ContactID *uint             `json:"contact_id,omitempty"`
+}
+
+// ImportBatchResponse представляет пакет импорта контактов
+type ImportBatchResponse struct {
+	ID             uint                `json:"id"`
+	FileName       string              `json:"file_name"`
+	Status         string              `json:"status"`
+	CreatedBy      uint                `json:"created_by"`
+	TotalRows      int                 `json:"total_rows"`
+	ValidRows      int                 `json:"valid_rows"`
+	CreatedRows    int                 `json:"created_rows"`
+	CreatedAt      string              `json:"created_at"`
+	ConfirmedAt    string              `json:"confirmed_at,omitempty"`
+	RollbackBefore string              `json:"rollback_before,omitempty"` // До какого момента пакет можно откатить
+	RolledBackAt   string              `json:"rolled_back_at,omitempty"`
+	Rows           []ImportRowResponse `json:"rows,omitempty"`
+}
@@ -0,0 +1,262 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rim/internal/domain"
+	"rim/internal/importer/usecase"
+	"rim/pkg/requestctx"
+	"rim/pkg/timeutil"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxFileSize - максимальный размер загружаемого файла импорта
+const maxFileSize = 5 << 20
+
+// Handler обрабатывает HTTP запросы импорта контактов
+type Handler struct {
+	importUseCase  usecase.UseCase
+	rollbackWindow time.Duration
+	logger         *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для импорта контактов
+func NewHandler(importUC usecase.UseCase, rollbackWindow time.Duration, logger *slog.Logger) *Handler {
+	return &Handler{
+		importUseCase:  importUC,
+		rollbackWindow: rollbackWindow,
+		logger:         logger,
+	}
+}
+
+// UploadImport загружает CSV-файл и возвращает предпросмотр
+// @Summary Загрузить файл импорта контактов
+// @Description Разбирает CSV (столбцы name, phone, email, transport, printer, allergies, vk, telegram, group) и возвращает статус каждой строки. Контакты создаются только после подтверждения.
+// @Tags imports
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV-файл"
+// @Success 201 {object} ImportBatchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /imports [post]
+func (h *Handler) UploadImport(c *fiber.Ctx) error {
+	return h.upload(c, h.importUseCase.Stage)
+}
+
+// ImportContacts загружает CSV-файл и сразу создает контакты
+// @Summary Импортировать контакты из CSV
+// @Description Разбирает CSV (столбцы name, phone, email, telegram, group и остальные столбцы /imports) и создает контакты из корректных строк без предпросмотра.
+// @Description В столбце group - названия групп через ";". Строки с ошибками, дубликатами в файле или уже существующими телефоном и email пропускаются,
+// @Description причина указывается в строке ответа. Контакты создаются транзакциями по 100 строк; пакет можно откатить через /imports/{id}/rollback.
+// @Tags contacts
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV-файл"
+// @Success 201 {object} ImportBatchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /contacts/import [post]
+func (h *Handler) ImportContacts(c *fiber.Ctx) error {
+	return h.upload(c, h.importUseCase.Import)
+}
+
+type uploadFunc func(ctx context.Context, userID uint, fileName string, file io.Reader) (*domain.ImportBatch, error)
+
+// upload передает загруженный файл в action и возвращает пакет со строками
+func (h *Handler) upload(c *fiber.Ctx, action uploadFunc) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "File is required",
+		})
+	}
+	if fileHeader.Size > maxFileSize {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "File is too large",
+		})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Cannot read file",
+		})
+	}
+	defer file.Close()
+
+	batch, err := action(c.Context(), userID, fileHeader.Filename, file)
+	if err != nil {
+		return h.importError(c, err)
+	}
+	return c.Status(http.StatusCreated).JSON(h.toBatchResponse(batch, true, requestctx.Location(c)))
+}
+
+// GetImports возвращает список пакетов импорта
+// @Summary Получить пакеты импорта
+// @Tags imports
+// @Produce json
+// @Success 200 {array} ImportBatchResponse
+// @Failure 500 {object} map[string]string
+// @Router /imports [get]
+func (h *Handler) GetImports(c *fiber.Ctx) error {
+	batches, err := h.importUseCase.GetBatches(c.Context())
+	if err != nil {
+		return h.importError(c, err)
+	}
+	loc := requestctx.Location(c)
+	resp := make([]ImportBatchResponse, len(batches))
+	for i := range batches {
+		resp[i] = h.toBatchResponse(&batches[i], false, loc)
+	}
+	return c.JSON(resp)
+}
+
+// GetImport возвращает пакет импорта со статусами строк
+// @Summary Получить пакет импорта
+// @Tags imports
+// @Produce json
+// @Param id path int true "ID пакета"
+// @Success 200 {object} ImportBatchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /imports/{id} [get]
+func (h *Handler) GetImport(c *fiber.Ctx) error {
+	return h.batchAction(c, h.importUseCase.GetBatch)
+}
+
+// ConfirmImport создает контакты из корректных строк пакета
+// @Summary Подтвердить импорт
+// @Tags imports
+// @Produce json
+// @Param id path int true "ID пакета"
+// @Success 200 {object} ImportBatchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Пакет уже подтвержден или отменен"
+// @Failure 500 {object} map[string]string
+// @Router /imports/{id}/confirm [post]
+func (h *Handler) ConfirmImport(c *fiber.Ctx) error {
+	return h.batchAction(c, h.importUseCase.Confirm)
+}
+
+// RollbackImport удаляет контакты, созданные пакетом
+// @Summary Откатить импорт
+// @Description Удаляет ровно те контакты, которые были созданы пакетом. Доступно в течение IMPORT_ROLLBACK_DAYS дней после подтверждения.
+// @Tags imports
+// @Produce json
+// @Param id path int true "ID пакета"
+// @Success 200 {object} ImportBatchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Пакет не подтвержден или срок отката истек"
+// @Failure 500 {object} map[string]string
+// @Router /imports/{id}/rollback [post]
+func (h *Handler) RollbackImport(c *fiber.Ctx) error {
+	return h.batchAction(c, h.importUseCase.Rollback)
+}
+
+// DiscardImport отменяет неподтвержденный пакет
+// @Summary Отменить импорт
+// @Tags imports
+// @Produce json
+// @Param id path int true "ID пакета"
+// @Success 200 {object} ImportBatchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Пакет уже подтвержден"
+// @Failure 500 {object} map[string]string
+// @Router /imports/{id} [delete]
+func (h *Handler) DiscardImport(c *fiber.Ctx) error {
+	return h.batchAction(c, h.importUseCase.Discard)
+}
+
+type batchFunc func(ctx context.Context, id uint) (*domain.ImportBatch, error)
+
+func (h *Handler) batchAction(c *fiber.Ctx, action batchFunc) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid import ID",
+		})
+	}
+	batch, err := action(c.Context(), uint(id))
+	if err != nil {
+		return h.importError(c, err)
+	}
+	return c.JSON(h.toBatchResponse(batch, true, requestctx.Location(c)))
+}
+
+// importError преобразует ошибки usecase в HTTP-ответ
+func (h *Handler) importError(c *fiber.Ctx, err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrEmptyFile),
+		errors.Is(err, usecase.ErrTooManyRows),
+		errors.Is(err, usecase.ErrMissingColumns),
+		errors.Is(err, usecase.ErrInvalidImportFile):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrBatchNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrBatchNotStaged),
+		errors.Is(err, usecase.ErrBatchNotConfirmed),
+		errors.Is(err, usecase.ErrRollbackExpired):
+		status = http.StatusConflict
+	default:
+		h.logger.ErrorContext(c.Context(), "Import operation failed", slog.Any("error", err))
+		return c.Status(status).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+func (h *Handler) toBatchResponse(batch *domain.ImportBatch, withRows bool, loc *time.Location) ImportBatchResponse {
+	resp := ImportBatchResponse{
+		ID:          batch.ID,
+		FileName:    batch.FileName,
+		Status:      batch.Status,
+		CreatedBy:   batch.CreatedBy,
+		TotalRows:   batch.TotalRows,
+		ValidRows:   batch.ValidRows,
+		CreatedRows: batch.CreatedRows,
+		CreatedAt:   timeutil.Format(batch.CreatedAt, loc),
+	}
+	if batch.ConfirmedAt != nil {
+		resp.ConfirmedAt = timeutil.Format(*batch.ConfirmedAt, loc)
+		if batch.Status == domain.ImportBatchConfirmed {
+			resp.RollbackBefore = timeutil.Format(batch.ConfirmedAt.Add(h.rollbackWindow), loc)
+		}
+	}
+	if batch.RolledBackAt != nil {
+		resp.RolledBackAt = timeutil.Format(*batch.RolledBackAt, loc)
+	}
+	if withRows {
+		resp.Rows = make([]ImportRowResponse, len(batch.Rows))
+		for i, row := range batch.Rows {
+			resp.Rows[i] = ImportRowResponse{
+				RowNumber: row.RowNumber,
+				Status:    row.Status,
+				Error:     row.Error,
+				Fields:    row.Fields(),
+				ContactID: row.ContactID,
+			}
+		}
+	}
+	return resp
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+// Repository определяет интерфейс для хранения пакетов импорта контактов.
+type Repository interface {
+	// CreateBatch сохраняет пакет вместе со строками
+	CreateBatch(ctx context.Context, batch *domain.ImportBatch) error
+	// GetBatch возвращает пакет со строками, упорядоченными по номеру
+	GetBatch(ctx context.Context, id uint) (*domain.ImportBatch, error)
+	GetBatches(ctx context.Context) ([]domain.ImportBatch, error)
+	// UpdateBatch сохраняет поля пакета без строк
+	UpdateBatch(ctx context.Context, batch *domain.ImportBatch) error
+	UpdateRow(ctx context.Context, row *domain.ImportRow) error
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр sqliteRepository для пакетов импорта.
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *sqliteRepository) CreateBatch(ctx context.Context, batch *domain.ImportBatch) error {
+	if err := transaction.DB(ctx, r.db).CreateInBatches(batch, 100).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error creating import batch in DB", slog.String("fileName", batch.FileName), slog.Any("error", err))
+		return err
+	}
+	r.logger.InfoContext(ctx, "Successfully created import batch in DB", slog.Uint64("batchID", uint64(batch.ID)), slog.Int("rows", len(batch.Rows)))
+	return nil
+}
+
+func (r *sqliteRepository) GetBatch(ctx context.Context, id uint) (*domain.ImportBatch, error) {
+	var batch domain.ImportBatch
+	err := transaction.DB(ctx, r.db).
+		Preload("Rows", func(db *gorm.DB) *gorm.DB { return db.Order("row_number") }).
+		First(&batch, id).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.logger.ErrorContext(ctx, "Error getting import batch from DB", slog.Uint64("batchID", uint64(id)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *sqliteRepository) GetBatches(ctx context.Context) ([]domain.ImportBatch, error) {
+	var batches []domain.ImportBatch
+	if err := transaction.DB(ctx, r.db).Order("created_at DESC").Find(&batches).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting import batches from DB", slog.Any("error", err))
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (r *sqliteRepository) UpdateBatch(ctx context.Context, batch *domain.ImportBatch) error {
+	if err := transaction.DB(ctx, r.db).Omit("Rows").Save(batch).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error updating import batch in DB", slog.Uint64("batchID", uint64(batch.ID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) UpdateRow(ctx context.Context, row *domain.ImportRow) error {
+	if err := transaction.DB(ctx, r.db).Save(row).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error updating import row in DB", slog.Uint64("rowID", uint64(row.ID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
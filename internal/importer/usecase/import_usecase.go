@@ -0,0 +1,502 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	contactRepo "rim/internal/contact/repository"
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	groupRepo "rim/internal/group/repository"
+	"rim/internal/importer/repository"
+	"rim/pkg/timeutil"
+	"rim/pkg/transaction"
+	"rim/pkg/validation"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// MaxRows - максимальное количество строк в одном файле импорта
+const MaxRows = 5000
+
+// importChunkSize - сколько контактов Import создает в одной транзакции. Транзакция SQLite блокирует
+// остальные записи, поэтому файл на тысячи строк не создается одной транзакцией.
+const importChunkSize = 100
+
+// groupSeparator разделяет названия групп в столбце group
+const groupSeparator = ";"
+
+var (
+	ErrBatchNotFound     = errors.New("import batch not found")
+	ErrBatchNotStaged    = errors.New("import batch is not awaiting confirmation")
+	ErrBatchNotConfirmed = errors.New("import batch is not confirmed")
+	ErrRollbackExpired   = errors.New("import batch rollback window has expired")
+	ErrEmptyFile         = errors.New("import file has no data rows")
+	ErrTooManyRows       = fmt.Errorf("import file has more than %d rows", MaxRows)
+	ErrMissingColumns    = errors.New("import file must have name, phone and email columns")
+	ErrInvalidImportFile = errors.New("import file is not a valid CSV")
+	ErrUnknownGroup      = errors.New("unknown group")
+)
+
+// columnAliases сопоставляет заголовки столбцов CSV полям контакта.
+var columnAliases = map[string]string{
+	"name": "name", "имя": "name", "фио": "name",
+	"phone": "phone", "телефон": "phone",
+	"email": "email", "почта": "email",
+	"transport": "transport", "транспорт": "transport",
+	"printer": "printer", "принтер": "printer",
+	"allergies": "allergies", "аллергии": "allergies",
+	"vk": "vk", "вк": "vk",
+	"telegram": "telegram", "телеграм": "telegram",
+	"department": "department", "отдел": "department",
+	"position": "position", "должность": "position",
+	"group": "group", "groups": "group", "группа": "group", "группы": "group",
+}
+
+// rowData повторяет правила проверки CreateContactRequest.
+type rowData struct {
+	Name       string `validate:"required,length=contact_name"`
+	Phone      string `validate:"required,e164"`
+	Email      string `validate:"required,email"`
+	Transport  string `validate:"omitempty,enum=transport"`
+	Printer    string `validate:"omitempty,enum=printer"`
+	Allergies  string `validate:"omitempty,max=255"`
+	VK         string `validate:"omitempty,vk_url"`
+	Telegram   string `validate:"omitempty,telegram_username"`
+	Department string `validate:"omitempty,max=100"`
+	Position   string `validate:"omitempty,max=100"`
+}
+
+// UseCase определяет интерфейс импорта контактов из CSV с предпросмотром и откатом.
+type UseCase interface {
+	// Stage разбирает файл в пакет со статусами строк; контакты не создаются
+	Stage(ctx context.Context, userID uint, fileName string, file io.Reader) (*domain.ImportBatch, error)
+	// Import разбирает файл и сразу создает контакты из корректных строк, без предпросмотра.
+	// Пакет сохраняется подтвержденным, поэтому его можно откатить, как после Confirm.
+	Import(ctx context.Context, userID uint, fileName string, file io.Reader) (*domain.ImportBatch, error)
+	GetBatches(ctx context.Context) ([]domain.ImportBatch, error)
+	GetBatch(ctx context.Context, id uint) (*domain.ImportBatch, error)
+	// Confirm создает контакты из корректных строк пакета
+	Confirm(ctx context.Context, id uint) (*domain.ImportBatch, error)
+	// Discard отменяет неподтвержденный пакет
+	Discard(ctx context.Context, id uint) (*domain.ImportBatch, error)
+	// Rollback удаляет контакты, созданные пакетом, если не истек срок отката
+	Rollback(ctx context.Context, id uint) (*domain.ImportBatch, error)
+}
+
+type importUseCase struct {
+	importRepo     repository.Repository
+	contactRepo    contactRepo.Repository
+	groupRepo      groupRepo.Repository
+	contactUseCase contactUseCase.UseCase
+	tx             transaction.Manager
+	rollbackWindow time.Duration
+	validate       *validator.Validate
+	logger         *slog.Logger
+}
+
+// NewImportUseCase создает новый экземпляр importUseCase.
+// rollbackWindow - сколько времени после подтверждения пакет можно откатить.
+func NewImportUseCase(importRepo repository.Repository, cr contactRepo.Repository, gr groupRepo.Repository, cu contactUseCase.UseCase, tx transaction.Manager, rollbackWindow time.Duration, logger *slog.Logger) UseCase {
+	return &importUseCase{
+		importRepo:     importRepo,
+		contactRepo:    cr,
+		groupRepo:      gr,
+		contactUseCase: cu,
+		tx:             tx,
+		rollbackWindow: rollbackWindow,
+		validate:       validation.Default(),
+		logger:         logger,
+	}
+}
+
+func (uc *importUseCase) Stage(ctx context.Context, userID uint, fileName string, file io.Reader) (*domain.ImportBatch, error) {
+	batch, err := uc.parse(ctx, userID, fileName, file)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.importRepo.CreateBatch(ctx, batch); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Import batch staged", slog.Uint64("batchID", uint64(batch.ID)), slog.Int("rows", batch.TotalRows), slog.Int("valid", batch.ValidRows))
+	return batch, nil
+}
+
+func (uc *importUseCase) Import(ctx context.Context, userID uint, fileName string, file io.Reader) (*domain.ImportBatch, error) {
+	batch, err := uc.parse(ctx, userID, fileName, file)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := uc.groupsByName(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := timeutil.Now()
+	batch.Status = domain.ImportBatchConfirmed
+	batch.ConfirmedAt = &now
+	if err := uc.importRepo.CreateBatch(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	var valid []*domain.ImportRow
+	for i := range batch.Rows {
+		if batch.Rows[i].Status == domain.ImportRowValid {
+			valid = append(valid, &batch.Rows[i])
+		}
+	}
+	for start := 0; start < len(valid); start += importChunkSize {
+		if err := uc.createChunk(ctx, batch, valid[start:min(start+importChunkSize, len(valid))], groups); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.importRepo.UpdateBatch(ctx, batch); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Contacts imported", slog.Uint64("batchID", uint64(batch.ID)), slog.Int("rows", batch.TotalRows), slog.Int("created", batch.CreatedRows))
+	return batch, nil
+}
+
+// createChunk создает контакты строк chunk в одной транзакции. Если контакт строки не удалось создать
+// (телефон или email заняли после проверки), транзакция откатывается, строка получает статус failed,
+// а остальные строки части создаются заново без нее. Возвращаются только ошибки сохранения строк.
+func (uc *importUseCase) createChunk(ctx context.Context, batch *domain.ImportBatch, chunk []*domain.ImportRow, groups map[string]uint) error {
+	for len(chunk) > 0 {
+		failed := -1
+		var failure error
+		err := uc.tx.Run(ctx, func(ctx context.Context) error {
+			for i, row := range chunk {
+				contact, err := uc.createContact(ctx, batch, row, groups)
+				if err != nil {
+					failed, failure = i, err
+					return err
+				}
+				row.Status = domain.ImportRowCreated
+				row.ContactID = &contact.ID
+				if err := uc.importRepo.UpdateRow(ctx, row); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err == nil {
+			batch.CreatedRows += len(chunk)
+			return nil
+		}
+
+		// Строки, созданные до ошибки, откатились вместе с транзакцией
+		for _, row := range chunk {
+			if row.Status == domain.ImportRowCreated {
+				row.Status = domain.ImportRowValid
+				row.ContactID = nil
+			}
+		}
+		if failed < 0 {
+			return err
+		}
+
+		row := chunk[failed]
+		row.Status = domain.ImportRowFailed
+		row.Error = failure.Error()
+		if err := uc.importRepo.UpdateRow(ctx, row); err != nil {
+			return err
+		}
+		// Новый срез, чтобы не менять общий список строк пакета
+		chunk = append(chunk[:failed:failed], chunk[failed+1:]...)
+	}
+	return nil
+}
+
+// parse разбирает файл в пакет со статусом staged и проверенными строками; пакет не сохраняется
+func (uc *importUseCase) parse(ctx context.Context, userID uint, fileName string, file io.Reader) (*domain.ImportBatch, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrEmptyFile
+		}
+		return nil, ErrInvalidImportFile
+	}
+	columns := make([]string, len(header))
+	found := map[string]bool{}
+	for i, title := range header {
+		title = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(title, "\uFEFF")))
+		columns[i] = columnAliases[title]
+		found[columns[i]] = true
+	}
+	if !found["name"] || !found["phone"] || !found["email"] {
+		return nil, ErrMissingColumns
+	}
+
+	groups, err := uc.groupsByName(ctx)
+	if err != nil {
+		return nil, err
+	}
+	batch := &domain.ImportBatch{
+		FileName:  fileName,
+		Status:    domain.ImportBatchStaged,
+		CreatedBy: userID,
+	}
+	seenPhones := map[string]int{}
+	seenEmails := map[string]int{}
+	for rowNumber := 1; ; rowNumber++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidImportFile, rowNumber, err)
+		}
+		if rowNumber > MaxRows {
+			return nil, ErrTooManyRows
+		}
+
+		fields := map[string]string{}
+		for i, value := range record {
+			if i < len(columns) && columns[i] != "" {
+				fields[columns[i]] = strings.TrimSpace(value)
+			}
+		}
+		row := domain.ImportRow{RowNumber: rowNumber, Status: domain.ImportRowValid}
+		if err := row.SetFields(fields); err != nil {
+			return nil, err
+		}
+		if problem := uc.checkRow(ctx, fields, rowNumber, groups, seenPhones, seenEmails); problem != "" {
+			row.Status = domain.ImportRowInvalid
+			row.Error = problem
+		} else {
+			batch.ValidRows++
+		}
+		batch.Rows = append(batch.Rows, row)
+	}
+	if len(batch.Rows) == 0 {
+		return nil, ErrEmptyFile
+	}
+	batch.TotalRows = len(batch.Rows)
+	return batch, nil
+}
+
+// checkRow возвращает описание проблемы строки или пустую строку, если строку можно импортировать.
+func (uc *importUseCase) checkRow(ctx context.Context, fields map[string]string, rowNumber int, groups map[string]uint, seenPhones, seenEmails map[string]int) string {
+	data := rowData{
+		Name:       fields["name"],
+		Phone:      fields["phone"],
+		Email:      fields["email"],
+		Transport:  fields["transport"],
+		Printer:    fields["printer"],
+		Allergies:  fields["allergies"],
+		VK:         fields["vk"],
+		Telegram:   fields["telegram"],
+		Department: fields["department"],
+		Position:   fields["position"],
+	}
+	if err := uc.validate.Struct(data); err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			problems := make([]string, len(validationErrors))
+			for i, fe := range validationErrors {
+				problems[i] = fmt.Sprintf("%s: %s", strings.ToLower(fe.Field()), fe.Tag())
+			}
+			return "invalid fields: " + strings.Join(problems, ", ")
+		}
+		return err.Error()
+	}
+	if _, err := groupIDs(fields["group"], groups); err != nil {
+		return err.Error()
+	}
+
+	if first, ok := seenPhones[data.Phone]; ok {
+		return fmt.Sprintf("phone duplicates row %d", first)
+	}
+	if first, ok := seenEmails[data.Email]; ok {
+		return fmt.Sprintf("email duplicates row %d", first)
+	}
+	seenPhones[data.Phone] = rowNumber
+	seenEmails[data.Email] = rowNumber
+
+	if existing, err := uc.contactRepo.GetByPhone(ctx, data.Phone); err == nil && existing != nil {
+		return contactUseCase.ErrContactPhoneExists.Error()
+	}
+	if existing, err := uc.contactRepo.GetByEmail(ctx, data.Email); err == nil && existing != nil {
+		return contactUseCase.ErrContactEmailExists.Error()
+	}
+	return ""
+}
+
+// groupsByName возвращает ID групп организации по названиям
+func (uc *importUseCase) groupsByName(ctx context.Context) (map[string]uint, error) {
+	all, err := uc.groupRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groups := make(map[string]uint, len(all))
+	for _, group := range all {
+		groups[group.Name] = group.ID
+	}
+	return groups, nil
+}
+
+// groupIDs переводит значение столбца group - названия через groupSeparator - в ID групп
+func groupIDs(value string, groups map[string]uint) ([]uint, error) {
+	var ids []uint
+	for _, name := range strings.Split(value, groupSeparator) {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		id, ok := groups[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownGroup, name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// createContact создает контакт из корректной строки пакета
+func (uc *importUseCase) createContact(ctx context.Context, batch *domain.ImportBatch, row *domain.ImportRow, groups map[string]uint) (*domain.Contact, error) {
+	fields := row.Fields()
+	ids, err := groupIDs(fields["group"], groups)
+	if err != nil {
+		// Группу удалили между предпросмотром и подтверждением
+		return nil, err
+	}
+	return uc.contactUseCase.CreateContact(ctx, contactUseCase.CreateContactData{
+		Name:       fields["name"],
+		Phone:      fields["phone"],
+		Email:      fields["email"],
+		Transport:  fields["transport"],
+		Printer:    fields["printer"],
+		Allergies:  fields["allergies"],
+		VK:         fields["vk"],
+		Telegram:   fields["telegram"],
+		Department: fields["department"],
+		Position:   fields["position"],
+		GroupIDs:   ids,
+		CreatedBy:  &batch.CreatedBy,
+	})
+}
+
+func (uc *importUseCase) GetBatches(ctx context.Context) ([]domain.ImportBatch, error) {
+	return uc.importRepo.GetBatches(ctx)
+}
+
+func (uc *importUseCase) GetBatch(ctx context.Context, id uint) (*domain.ImportBatch, error) {
+	batch, err := uc.importRepo.GetBatch(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBatchNotFound
+		}
+		return nil, err
+	}
+	return batch, nil
+}
+
+func (uc *importUseCase) Confirm(ctx context.Context, id uint) (*domain.ImportBatch, error) {
+	batch, err := uc.GetBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status != domain.ImportBatchStaged {
+		return nil, ErrBatchNotStaged
+	}
+	groups, err := uc.groupsByName(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Отмечаем пакет подтвержденным до создания контактов, чтобы повторный запрос не создал дубликаты
+	now := timeutil.Now()
+	batch.Status = domain.ImportBatchConfirmed
+	batch.ConfirmedAt = &now
+	if err := uc.importRepo.UpdateBatch(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	for i := range batch.Rows {
+		row := &batch.Rows[i]
+		if row.Status != domain.ImportRowValid {
+			continue
+		}
+		contact, err := uc.createContact(ctx, batch, row, groups)
+		if err != nil {
+			// Телефон или email могли занять между предпросмотром и подтверждением
+			row.Status = domain.ImportRowFailed
+			row.Error = err.Error()
+		} else {
+			row.Status = domain.ImportRowCreated
+			row.ContactID = &contact.ID
+			batch.CreatedRows++
+		}
+		if err := uc.importRepo.UpdateRow(ctx, row); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.importRepo.UpdateBatch(ctx, batch); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Import batch confirmed", slog.Uint64("batchID", uint64(batch.ID)), slog.Int("created", batch.CreatedRows))
+	return batch, nil
+}
+
+func (uc *importUseCase) Discard(ctx context.Context, id uint) (*domain.ImportBatch, error) {
+	batch, err := uc.GetBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status != domain.ImportBatchStaged {
+		return nil, ErrBatchNotStaged
+	}
+	batch.Status = domain.ImportBatchDiscarded
+	if err := uc.importRepo.UpdateBatch(ctx, batch); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Import batch discarded", slog.Uint64("batchID", uint64(batch.ID)))
+	return batch, nil
+}
+
+func (uc *importUseCase) Rollback(ctx context.Context, id uint) (*domain.ImportBatch, error) {
+	batch, err := uc.GetBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status != domain.ImportBatchConfirmed || batch.ConfirmedAt == nil {
+		return nil, ErrBatchNotConfirmed
+	}
+	if timeutil.Now().Sub(*batch.ConfirmedAt) > uc.rollbackWindow {
+		return nil, ErrRollbackExpired
+	}
+
+	for i := range batch.Rows {
+		row := &batch.Rows[i]
+		if row.Status != domain.ImportRowCreated || row.ContactID == nil {
+			continue
+		}
+		if err := uc.contactRepo.HardDelete(ctx, *row.ContactID); err != nil {
+			return nil, err
+		}
+		row.Status = domain.ImportRowRolledBack
+		if err := uc.importRepo.UpdateRow(ctx, row); err != nil {
+			return nil, err
+		}
+	}
+
+	now := timeutil.Now()
+	batch.Status = domain.ImportBatchRolledBack
+	batch.RolledBackAt = &now
+	if err := uc.importRepo.UpdateBatch(ctx, batch); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Import batch rolled back", slog.Uint64("batchID", uint64(batch.ID)), slog.Int("deleted", batch.CreatedRows))
+	return batch, nil
+}
@@ -0,0 +1,24 @@
+package delivery
+
+import (
+	"rim/internal/domain"
+	"rim/pkg/validation"
+)
+
+// Виды значений справочника для тега enum=location_kind
+func init() {
+	validation.RegisterEnum("location_kind", domain.LocationCity, domain.LocationCampus, domain.LocationBuilding, domain.LocationRoom)
+}
+
+// LocationOptionRequest определяет структуру запроса на добавление значения в справочник.
+type LocationOptionRequest struct {
+	Kind  string `json:"kind" validate:"required,enum=location_kind"`
+	Value string `json:"value" validate:"required,min=1,max=100"`
+}
+
+// LocationOptionResponse определяет структуру значения справочника в ответе.
+type LocationOptionResponse struct {
+	ID    uint   `json:"id"`
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
@@ -0,0 +1,108 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+
+	groupDelivery "rim/internal/group/delivery"
+	"rim/internal/location/usecase"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler отвечает за обработку HTTP-запросов справочника местоположений.
+type Handler struct {
+	locationUseCase usecase.UseCase
+	logger          *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler.
+func NewHandler(locationUC usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		locationUseCase: locationUC,
+		logger:          logger,
+	}
+}
+
+// GetOptions возвращает значения справочника местоположений.
+// @Summary Получить справочник местоположений
+// @Description Возвращает допустимые значения полей city, campus, building и room.
+// @Tags locations
+// @Produce json
+// @Param kind query string false "Вид значения: city, campus, building или room"
+// @Success 200 {array} LocationOptionResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный вид значения"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /locations [get]
+func (h *Handler) GetOptions(c *fiber.Ctx) error {
+	options, err := h.locationUseCase.GetOptions(c.Context(), c.Query("kind"))
+	if err != nil {
+		return h.locationError(c, err)
+	}
+	resp := make([]LocationOptionResponse, len(options))
+	for i, o := range options {
+		resp[i] = LocationOptionResponse{ID: o.ID, Kind: o.Kind, Value: o.Value}
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// CreateOption добавляет значение в справочник местоположений.
+// @Summary Добавить значение в справочник местоположений
+// @Tags locations
+// @Accept json
+// @Produce json
+// @Param option body LocationOptionRequest true "Вид и значение"
+// @Success 201 {object} LocationOptionResponse
+// @Failure 400 {object} validation.Response "Ошибка валидации"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Значение уже существует"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /locations [post]
+func (h *Handler) CreateOption(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[LocationOptionRequest](c)
+	if !ok {
+		return err
+	}
+
+	option, err := h.locationUseCase.CreateOption(c.Context(), req.Kind, req.Value)
+	if err != nil {
+		return h.locationError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(LocationOptionResponse{ID: option.ID, Kind: option.Kind, Value: option.Value})
+}
+
+// DeleteOption удаляет значение из справочника местоположений.
+// @Summary Удалить значение из справочника местоположений
+// @Description Уже заполненные у контактов значения не изменяются.
+// @Tags locations
+// @Param id path int true "ID значения"
+// @Success 204 "Значение удалено"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Значение не найдено"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /locations/{id} [delete]
+func (h *Handler) DeleteOption(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid location option ID format"})
+	}
+	if err := h.locationUseCase.DeleteOption(c.Context(), uint(id)); err != nil {
+		return h.locationError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// locationError преобразует ошибки usecase в HTTP-ответ.
+func (h *Handler) locationError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidKind), errors.Is(err, usecase.ErrValueEmpty):
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrOptionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrValueExists):
+		return c.Status(fiber.StatusConflict).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	}
+	h.logger.ErrorContext(c.Context(), "Location option operation failed", slog.Any("error", err))
+	return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+// Repository определяет интерфейс для операций со справочником местоположений.
+type Repository interface {
+	Create(ctx context.Context, option *domain.LocationOption) error
+	GetAll(ctx context.Context, kind string) ([]domain.LocationOption, error)
+	Exists(ctx context.Context, kind, value string) (bool, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр sqliteRepository для справочника местоположений.
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *sqliteRepository) Create(ctx context.Context, option *domain.LocationOption) error {
+	if err := transaction.DB(ctx, r.db).Create(option).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error creating location option in DB", slog.String("kind", option.Kind), slog.String("value", option.Value), slog.Any("error", err))
+		return err
+	}
+	r.logger.InfoContext(ctx, "Successfully created location option in DB", slog.Uint64("optionID", uint64(option.ID)))
+	return nil
+}
+
+// GetAll возвращает значения справочника; если kind пустой - всех видов.
+func (r *sqliteRepository) GetAll(ctx context.Context, kind string) ([]domain.LocationOption, error) {
+	var options []domain.LocationOption
+	query := transaction.DB(ctx, r.db).Order("kind").Order("value")
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if err := query.Find(&options).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting location options from DB", slog.String("kind", kind), slog.Any("error", err))
+		return nil, err
+	}
+	return options, nil
+}
+
+func (r *sqliteRepository) Exists(ctx context.Context, kind, value string) (bool, error) {
+	var count int64
+	err := transaction.DB(ctx, r.db).Model(&domain.LocationOption{}).
+		Where("kind = ? AND value = ?", kind, value).
+		Count(&count).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error checking location option in DB", slog.String("kind", kind), slog.String("value", value), slog.Any("error", err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *sqliteRepository) Delete(ctx context.Context, id uint) error {
+	result := transaction.DB(ctx, r.db).Delete(&domain.LocationOption{}, id)
+	if result.Error != nil {
+		r.logger.ErrorContext(ctx, "Error deleting location option from DB", slog.Uint64("optionID", uint64(id)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	r.logger.InfoContext(ctx, "Successfully deleted location option from DB", slog.Uint64("optionID", uint64(id)))
+	return nil
+}
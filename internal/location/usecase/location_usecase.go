@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"rim/internal/domain"
+	"rim/internal/location/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidKind     = errors.New("invalid location kind")
+	ErrValueEmpty      = errors.New("location value cannot be empty")
+	ErrValueExists     = errors.New("location value already exists")
+	ErrOptionNotFound  = errors.New("location option not found")
+	ErrUnknownLocation = errors.New("unknown location value")
+)
+
+// Location - значения полей местоположения контакта. Пустые поля не проверяются.
+type Location struct {
+	City     string
+	Campus   string
+	Building string
+	Room     string
+}
+
+// UseCase определяет интерфейс для управления справочником местоположений.
+type UseCase interface {
+	GetOptions(ctx context.Context, kind string) ([]domain.LocationOption, error)
+	CreateOption(ctx context.Context, kind, value string) (*domain.LocationOption, error)
+	DeleteOption(ctx context.Context, id uint) error
+}
+
+type locationUseCase struct {
+	locationRepo repository.Repository
+	logger       *slog.Logger
+}
+
+// NewLocationUseCase создает новый экземпляр locationUseCase.
+func NewLocationUseCase(locationRepo repository.Repository, logger *slog.Logger) UseCase {
+	return &locationUseCase{
+		locationRepo: locationRepo,
+		logger:       logger,
+	}
+}
+
+// IsValidKind проверяет, что вид значения справочника поддерживается.
+func IsValidKind(kind string) bool {
+	switch kind {
+	case domain.LocationCity, domain.LocationCampus, domain.LocationBuilding, domain.LocationRoom:
+		return true
+	}
+	return false
+}
+
+// Validate проверяет, что каждое заполненное поле местоположения есть в справочнике.
+func Validate(ctx context.Context, repo repository.Repository, loc Location) error {
+	fields := []struct{ kind, value string }{
+		{domain.LocationCity, loc.City},
+		{domain.LocationCampus, loc.Campus},
+		{domain.LocationBuilding, loc.Building},
+		{domain.LocationRoom, loc.Room},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		ok, err := repo.Exists(ctx, f.kind, f.value)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s %q", ErrUnknownLocation, f.kind, f.value)
+		}
+	}
+	return nil
+}
+
+func (uc *locationUseCase) GetOptions(ctx context.Context, kind string) ([]domain.LocationOption, error) {
+	if kind != "" && !IsValidKind(kind) {
+		return nil, ErrInvalidKind
+	}
+	return uc.locationRepo.GetAll(ctx, kind)
+}
+
+func (uc *locationUseCase) CreateOption(ctx context.Context, kind, value string) (*domain.LocationOption, error) {
+	if !IsValidKind(kind) {
+		return nil, ErrInvalidKind
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, ErrValueEmpty
+	}
+	exists, err := uc.locationRepo.Exists(ctx, kind, value)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrValueExists
+	}
+
+	option := &domain.LocationOption{Kind: kind, Value: value}
+	if err := uc.locationRepo.Create(ctx, option); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Location option created", slog.String("kind", kind), slog.String("value", value))
+	return option, nil
+}
+
+// DeleteOption удаляет значение из справочника. Контакты, у которых оно уже указано, не меняются.
+func (uc *locationUseCase) DeleteOption(ctx context.Context, id uint) error {
+	if err := uc.locationRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOptionNotFound
+		}
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Location option deleted", slog.Uint64("id", uint64(id)))
+	return nil
+}
@@ -0,0 +1,88 @@
+package delivery
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"rim/pkg/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// unmatchedRoute - метка запросов, не совпавших ни с одним маршрутом (чтобы произвольные пути не раздували метрики)
+const unmatchedRoute = "unmatched"
+
+// Handler собирает метрики запросов и отдает их Prometheus
+type Handler struct {
+	registry *metrics.Registry
+	token    func() string // Токен доступа к /metrics; пустой - доступ открыт
+	logger   *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для метрик
+func NewHandler(registry *metrics.Registry, token func() string, logger *slog.Logger) *Handler {
+	return &Handler{
+		registry: registry,
+		token:    token,
+		logger:   logger,
+	}
+}
+
+// Middleware учитывает длительность и статус каждого запроса с меткой шаблона маршрута.
+// Должен подключаться первым, чтобы учитывать и запросы, отклоненные другими middleware.
+func (h *Handler) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			// Ответ для ошибки сформирует обработчик ошибок Fiber уже после middleware
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		route := c.Route()
+		path := route.Path
+		if route.Method == "USE" || status == fiber.StatusNotFound && route.Path == "/" {
+			path = unmatchedRoute
+		}
+		// Метод копируется: Fiber переиспользует буфер запроса после его завершения
+		h.registry.Observe(strings.Clone(c.Method()), path, status, time.Since(start))
+		return err
+	}
+}
+
+// Metrics отдает метрики в текстовом формате Prometheus
+// @Summary Метрики Prometheus
+// @Description Число запросов, доля ошибок 5xx и гистограмма длительности по шаблонам маршрутов.
+// @Description Если задан METRICS_TOKEN, требуется заголовок Authorization: Bearer <токен>.
+// @Tags system
+// @Produce plain
+// @Success 200 {string} string
+// @Failure 401 {object} map[string]string
+// @Router /metrics [get]
+func (h *Handler) Metrics(c *fiber.Ctx) error {
+	if token := h.token(); token != "" {
+		if subtle.ConstantTimeCompare([]byte(c.Get(fiber.HeaderAuthorization)), []byte("Bearer "+token)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized",
+			})
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+	if err := h.registry.WritePrometheus(c); err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to write metrics", slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+	return nil
+}
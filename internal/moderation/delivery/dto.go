@@ -0,0 +1,27 @@
+package delivery
+
+// FieldChangeResponse описывает изменение одного поля контакта
+type FieldChangeResponse struct {
+	Field string `json:"field"`
+	Group string `json:"group"` // Группа полей, по которой включена модерация
+	Old   string `json:"old"`   // Значение на момент подачи заявки
+	New   string `json:"new"`
+}
+
+// ChangeRequestResponse представляет заявку на изменение контакта
+type ChangeRequestResponse struct {
+	ID            uint                  `json:"id"`
+	ContactID     uint                  `json:"contact_id"`
+	UserID        uint                  `json:"user_id"`
+	Status        string                `json:"status"`
+	Diff          []FieldChangeResponse `json:"diff"`
+	ReviewedBy    *uint                 `json:"reviewed_by,omitempty"`
+	ReviewComment string                `json:"review_comment,omitempty"`
+	CreatedAt     string                `json:"created_at"`
+	ReviewedAt    string                `json:"reviewed_at,omitempty"`
+}
+
+// ReviewRequest представляет решение администратора по заявке
+type ReviewRequest struct {
+	Comment string `json:"comment" validate:"max=500"`
+}
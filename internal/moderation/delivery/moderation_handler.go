@@ -0,0 +1,208 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	"rim/internal/moderation/usecase"
+	"rim/pkg/requestctx"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler обрабатывает HTTP запросы рассмотрения заявок на изменение контактов
+type Handler struct {
+	moderationUseCase usecase.UseCase
+	logger            *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для заявок на изменение
+func NewHandler(moderationUC usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		moderationUseCase: moderationUC,
+		logger:            logger,
+	}
+}
+
+// GetChangeRequests возвращает заявки на изменение контактов
+// @Summary Получить заявки на изменение контактов
+// @Description Возвращает заявки с изменениями полей (было/стало), начиная с новых
+// @Tags moderation
+// @Produce json
+// @Param status query string false "Статус: pending, approved или rejected"
+// @Success 200 {array} ChangeRequestResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /change-requests [get]
+func (h *Handler) GetChangeRequests(c *fiber.Ctx) error {
+	requests, err := h.moderationUseCase.ListRequests(c.Context(), c.Query("status"))
+	if err != nil {
+		return h.moderationError(c, err)
+	}
+	loc := requestctx.Location(c)
+	resp := make([]ChangeRequestResponse, len(requests))
+	for i := range requests {
+		resp[i] = toChangeRequestResponse(&requests[i], loc)
+	}
+	return c.JSON(resp)
+}
+
+// GetChangeRequest возвращает заявку на изменение контакта
+// @Summary Получить заявку на изменение контакта
+// @Tags moderation
+// @Produce json
+// @Param id path int true "ID заявки"
+// @Success 200 {object} ChangeRequestResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /change-requests/{id} [get]
+func (h *Handler) GetChangeRequest(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid change request ID",
+		})
+	}
+	request, err := h.moderationUseCase.GetRequest(c.Context(), uint(id))
+	if err != nil {
+		return h.moderationError(c, err)
+	}
+	return c.JSON(toChangeRequestResponse(request, requestctx.Location(c)))
+}
+
+// ApproveChangeRequest одобряет заявку и применяет изменения к контакту
+// @Summary Одобрить заявку на изменение контакта
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заявки"
+// @Param review body ReviewRequest false "Комментарий"
+// @Success 200 {object} ChangeRequestResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Заявка уже рассмотрена или телефон/email заняты"
+// @Failure 500 {object} map[string]string
+// @Router /change-requests/{id}/approve [post]
+func (h *Handler) ApproveChangeRequest(c *fiber.Ctx) error {
+	return h.review(c, h.moderationUseCase.Approve)
+}
+
+// RejectChangeRequest отклоняет заявку
+// @Summary Отклонить заявку на изменение контакта
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заявки"
+// @Param review body ReviewRequest false "Причина отказа"
+// @Success 200 {object} ChangeRequestResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Заявка уже рассмотрена"
+// @Failure 500 {object} map[string]string
+// @Router /change-requests/{id}/reject [post]
+func (h *Handler) RejectChangeRequest(c *fiber.Ctx) error {
+	return h.review(c, h.moderationUseCase.Reject)
+}
+
+type reviewFunc func(ctx context.Context, id, reviewerID uint, comment string) (*domain.ChangeRequest, error)
+
+func (h *Handler) review(c *fiber.Ctx, decide reviewFunc) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid change request ID",
+		})
+	}
+	reviewerID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	var req ReviewRequest
+	if len(c.Body()) > 0 {
+		parsed, ok, err := validation.BindAndValidate[ReviewRequest](c)
+		if !ok {
+			return err
+		}
+		req = parsed
+	}
+
+	request, err := decide(c.Context(), uint(id), reviewerID, req.Comment)
+	if err != nil {
+		return h.moderationError(c, err)
+	}
+	return c.JSON(toChangeRequestResponse(request, requestctx.Location(c)))
+}
+
+// moderationError преобразует ошибки usecase в HTTP-ответ
+func (h *Handler) moderationError(c *fiber.Ctx, err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrInvalidStatus),
+		errors.Is(err, contactUseCase.ErrContactNameEmpty),
+		errors.Is(err, contactUseCase.ErrContactPhoneEmpty),
+		errors.Is(err, contactUseCase.ErrContactEmailEmpty):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrRequestNotFound), errors.Is(err, contactUseCase.ErrContactNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrRequestNotPending),
+		errors.Is(err, contactUseCase.ErrContactPhoneExists),
+		errors.Is(err, contactUseCase.ErrContactEmailExists):
+		status = http.StatusConflict
+	default:
+		h.logger.ErrorContext(c.Context(), "Change request operation failed", slog.Any("error", err))
+		return c.Status(status).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+func toChangeRequestResponse(request *domain.ChangeRequest, loc *time.Location) ChangeRequestResponse {
+	changes := request.ChangeMap()
+	previous := request.PreviousMap()
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	diff := make([]FieldChangeResponse, len(fields))
+	for i, field := range fields {
+		diff[i] = FieldChangeResponse{
+			Field: field,
+			Group: domain.ContactFieldGroup(field),
+			Old:   previous[field],
+			New:   changes[field],
+		}
+	}
+
+	resp := ChangeRequestResponse{
+		ID:            request.ID,
+		ContactID:     request.ContactID,
+		UserID:        request.UserID,
+		Status:        request.Status,
+		Diff:          diff,
+		ReviewedBy:    request.ReviewedBy,
+		ReviewComment: request.ReviewComment,
+		CreatedAt:     timeutil.Format(request.CreatedAt, loc),
+	}
+	if request.ReviewedAt != nil {
+		resp.ReviewedAt = timeutil.Format(*request.ReviewedAt, loc)
+	}
+	return resp
+}
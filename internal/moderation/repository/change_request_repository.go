@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/tenant"
+
+	"gorm.io/gorm"
+)
+
+// Repository определяет интерфейс для хранения заявок на изменение контактов.
+// Выборки ограничены заявками по контактам организации из контекста (pkg/tenant).
+type Repository interface {
+	Create(ctx context.Context, request *domain.ChangeRequest) error
+	GetByID(ctx context.Context, id uint) (*domain.ChangeRequest, error)
+	GetAll(ctx context.Context, status string) ([]domain.ChangeRequest, error)
+	GetPendingByContact(ctx context.Context, contactID uint) (*domain.ChangeRequest, error)
+	Update(ctx context.Context, request *domain.ChangeRequest) error
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр sqliteRepository для заявок на изменение.
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// inOrganization ограничивает запрос заявками по контактам организации из ctx.
+func (r *sqliteRepository) inOrganization(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if tenant.FromContext(ctx) == 0 {
+			return db
+		}
+		return db.Where("contact_id IN (?)", r.db.Model(&domain.Contact{}).Select("contacts.id").Scopes(tenant.Scope(ctx, "contacts")))
+	}
+}
+
+func (r *sqliteRepository) Create(ctx context.Context, request *domain.ChangeRequest) error {
+	if err := r.db.WithContext(ctx).Create(request).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error creating change request in DB", slog.Uint64("contactID", uint64(request.ContactID)), slog.Any("error", err))
+		return err
+	}
+	r.logger.InfoContext(ctx, "Successfully created change request in DB", slog.Uint64("requestID", uint64(request.ID)))
+	return nil
+}
+
+func (r *sqliteRepository) GetByID(ctx context.Context, id uint) (*domain.ChangeRequest, error) {
+	var request domain.ChangeRequest
+	if err := r.db.WithContext(ctx).Scopes(r.inOrganization(ctx)).First(&request, id).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.logger.ErrorContext(ctx, "Error getting change request from DB", slog.Uint64("requestID", uint64(id)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetAll возвращает заявки, начиная с новых; если status пустой - в любом статусе.
+func (r *sqliteRepository) GetAll(ctx context.Context, status string) ([]domain.ChangeRequest, error) {
+	var requests []domain.ChangeRequest
+	query := r.db.WithContext(ctx).Scopes(r.inOrganization(ctx)).Order("created_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&requests).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting change requests from DB", slog.String("status", status), slog.Any("error", err))
+		return nil, err
+	}
+	return requests, nil
+}
+
+func (r *sqliteRepository) GetPendingByContact(ctx context.Context, contactID uint) (*domain.ChangeRequest, error) {
+	var request domain.ChangeRequest
+	err := r.db.WithContext(ctx).
+		Where("contact_id = ? AND status = ?", contactID, domain.ChangeRequestPending).
+		First(&request).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.logger.ErrorContext(ctx, "Error getting pending change request from DB", slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *sqliteRepository) Update(ctx context.Context, request *domain.ChangeRequest) error {
+	if err := r.db.WithContext(ctx).Save(request).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error updating change request in DB", slog.Uint64("requestID", uint64(request.ID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
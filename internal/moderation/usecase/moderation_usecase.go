@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	"rim/internal/moderation/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRequestNotFound   = errors.New("change request not found")
+	ErrRequestNotPending = errors.New("change request is already reviewed")
+	ErrInvalidStatus     = errors.New("invalid change request status")
+)
+
+// UseCase определяет интерфейс для рассмотрения заявок на изменение контактов.
+type UseCase interface {
+	ListRequests(ctx context.Context, status string) ([]domain.ChangeRequest, error)
+	GetRequest(ctx context.Context, id uint) (*domain.ChangeRequest, error)
+	// Approve применяет изменения заявки к контакту
+	Approve(ctx context.Context, id, reviewerID uint, comment string) (*domain.ChangeRequest, error)
+	Reject(ctx context.Context, id, reviewerID uint, comment string) (*domain.ChangeRequest, error)
+}
+
+type moderationUseCase struct {
+	requestRepo    repository.Repository
+	contactUseCase contactUseCase.UseCase
+	logger         *slog.Logger
+}
+
+// NewModerationUseCase создает новый экземпляр moderationUseCase.
+func NewModerationUseCase(requestRepo repository.Repository, cu contactUseCase.UseCase, logger *slog.Logger) UseCase {
+	return &moderationUseCase{
+		requestRepo:    requestRepo,
+		contactUseCase: cu,
+		logger:         logger,
+	}
+}
+
+func (uc *moderationUseCase) ListRequests(ctx context.Context, status string) ([]domain.ChangeRequest, error) {
+	switch status {
+	case "", domain.ChangeRequestPending, domain.ChangeRequestApproved, domain.ChangeRequestRejected:
+	default:
+		return nil, ErrInvalidStatus
+	}
+	return uc.requestRepo.GetAll(ctx, status)
+}
+
+func (uc *moderationUseCase) GetRequest(ctx context.Context, id uint) (*domain.ChangeRequest, error) {
+	request, err := uc.requestRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRequestNotFound
+		}
+		return nil, err
+	}
+	return request, nil
+}
+
+func (uc *moderationUseCase) Approve(ctx context.Context, id, reviewerID uint, comment string) (*domain.ChangeRequest, error) {
+	request, err := uc.pendingRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Проверки уникальности телефона и email повторяются при применении, так как значения могли быть заняты после подачи заявки
+	// Автором изменения считается подавший заявку; рассмотревший записывается в саму заявку
+	data := toUpdateData(request.ChangeMap())
+	data.UpdatedBy = &request.UserID
+	if _, err := uc.contactUseCase.UpdateContact(ctx, request.ContactID, data); err != nil {
+		uc.logger.WarnContext(ctx, "Failed to apply change request", slog.Uint64("requestID", uint64(id)), slog.Any("error", err))
+		return nil, err
+	}
+
+	if err := uc.review(ctx, request, domain.ChangeRequestApproved, reviewerID, comment); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (uc *moderationUseCase) Reject(ctx context.Context, id, reviewerID uint, comment string) (*domain.ChangeRequest, error) {
+	request, err := uc.pendingRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.review(ctx, request, domain.ChangeRequestRejected, reviewerID, comment); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (uc *moderationUseCase) pendingRequest(ctx context.Context, id uint) (*domain.ChangeRequest, error) {
+	request, err := uc.GetRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != domain.ChangeRequestPending {
+		return nil, ErrRequestNotPending
+	}
+	return request, nil
+}
+
+func (uc *moderationUseCase) review(ctx context.Context, request *domain.ChangeRequest, status string, reviewerID uint, comment string) error {
+	now := time.Now()
+	request.Status = status
+	request.ReviewedBy = &reviewerID
+	request.ReviewComment = comment
+	request.ReviewedAt = &now
+	if err := uc.requestRepo.Update(ctx, request); err != nil {
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Change request reviewed", slog.Uint64("requestID", uint64(request.ID)), slog.String("status", status), slog.Uint64("reviewer_id", uint64(reviewerID)))
+	return nil
+}
+
+// toUpdateData переводит изменения заявки в данные обновления контакта.
+func toUpdateData(changes map[string]string) contactUseCase.UpdateContactData {
+	var data contactUseCase.UpdateContactData
+	for field, value := range changes {
+		v := value
+		switch field {
+		case "name":
+			data.Name = &v
+		case "phone":
+			data.Phone = &v
+		case "email":
+			data.Email = &v
+		case "transport":
+			data.Transport = &v
+		case "printer":
+			data.Printer = &v
+		case "allergies":
+			data.Allergies = &v
+		case "vk":
+			data.VK = &v
+		case "telegram":
+			data.Telegram = &v
+		case "telegram_id":
+			if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+				data.TelegramID = &id
+			}
+		}
+	}
+	return data
+}
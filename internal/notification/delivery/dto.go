@@ -0,0 +1,59 @@
+package delivery
+
+import (
+	"rim/internal/domain"
+	"rim/pkg/validation"
+)
+
+// channels в настройках уведомлений проверяются по domain.NotifyChannels
+func init() {
+	validation.RegisterEnum("notify_channel", domain.NotifyChannels...)
+}
+
+// PushKeyResponse содержит открытый ключ VAPID для pushManager.subscribe
+type PushKeyResponse struct {
+	PublicKey string `json:"public_key"` // base64url, передается как applicationServerKey
+}
+
+// PushSubscriptionRequest - подписка браузера в формате PushSubscription.toJSON()
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" validate:"required,url,max=2048"`
+	Keys     struct {
+		P256dh string `json:"p256dh" validate:"required"`
+		Auth   string `json:"auth" validate:"required"`
+	} `json:"keys"`
+}
+
+// DeletePushSubscriptionRequest определяет подписку, которую нужно удалить
+type DeletePushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" validate:"required"`
+}
+
+// PushSubscriptionResponse описывает подписку браузера пользователя
+type PushSubscriptionResponse struct {
+	ID        uint   `json:"id"`
+	Endpoint  string `json:"endpoint"`
+	UserAgent string `json:"user_agent"`
+	CreatedAt string `json:"created_at"` // RFC3339 в часовом поясе пользователя
+}
+
+// NotificationChannelsRequest задает каналы уведомлений; пустой список отключает уведомления
+type NotificationChannelsRequest struct {
+	Channels []string `json:"channels" validate:"dive,enum=notify_channel"`
+}
+
+// NotificationChannelsResponse описывает выбор каналов пользователя
+type NotificationChannelsResponse struct {
+	Channels  []string `json:"channels"`  // Каналы, в которые пользователь получает уведомления
+	Available []string `json:"available"` // Все выбираемые каналы
+}
+
+// WeeklyDigestRequest включает или отключает еженедельную сводку
+type WeeklyDigestRequest struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+// WeeklyDigestResponse описывает настройку еженедельной сводки пользователя
+type WeeklyDigestResponse struct {
+	Enabled bool `json:"enabled"`
+}
@@ -0,0 +1,236 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/internal/notification/usecase"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
+	"rim/pkg/webpush"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler отвечает за подписки Web Push и выбор каналов уведомлений текущего пользователя.
+type Handler struct {
+	notificationUseCase usecase.UseCase
+	logger              *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для уведомлений.
+func NewHandler(notificationUseCase usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		notificationUseCase: notificationUseCase,
+		logger:              logger,
+	}
+}
+
+// GetPushKey возвращает открытый ключ VAPID.
+// @Summary Ключ Web Push
+// @Description Открытый ключ VAPID для pushManager.subscribe({applicationServerKey}).
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} PushKeyResponse
+// @Failure 404 {object} groupDelivery.ErrorResponse "Web Push не настроен"
+// @Router /notifications/push/key [get]
+func (h *Handler) GetPushKey(c *fiber.Ctx) error {
+	key, err := h.notificationUseCase.PushPublicKey()
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	return c.JSON(PushKeyResponse{PublicKey: key})
+}
+
+// GetPushSubscriptions возвращает подписки Web Push текущего пользователя.
+// @Summary Мои подписки Web Push
+// @Tags notifications
+// @Produce json
+// @Success 200 {array} PushSubscriptionResponse
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Router /notifications/push/subscriptions [get]
+func (h *Handler) GetPushSubscriptions(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*domain.User)
+	if !ok || user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "Unauthorized"})
+	}
+	subs, err := h.notificationUseCase.GetPushSubscriptions(c.Context(), user.ID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	loc := timeutil.LocationOrUTC(user.Timezone)
+	resp := make([]PushSubscriptionResponse, len(subs))
+	for i, sub := range subs {
+		resp[i] = PushSubscriptionResponse{
+			ID:        sub.ID,
+			Endpoint:  sub.Endpoint,
+			UserAgent: sub.UserAgent,
+			CreatedAt: timeutil.Format(sub.CreatedAt, loc),
+		}
+	}
+	return c.JSON(resp)
+}
+
+// SubscribePush сохраняет подписку Web Push браузера.
+// @Summary Подписать браузер на Web Push
+// @Description Принимает результат PushSubscription.toJSON(). Повторная подписка с тем же endpoint обновляет ключи.
+// @Description Принимаются только адреса известных сервисов доставки браузеров.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param subscription body PushSubscriptionRequest true "Подписка браузера"
+// @Success 201
+// @Failure 400 {object} groupDelivery.ErrorResponse "Неверная подписка"
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Web Push не настроен"
+// @Router /notifications/push/subscriptions [post]
+func (h *Handler) SubscribePush(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*domain.User)
+	if !ok || user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "Unauthorized"})
+	}
+	req, ok, err := validation.BindAndValidate[PushSubscriptionRequest](c)
+	if !ok {
+		return err
+	}
+
+	sub := webpush.Subscription{Endpoint: req.Endpoint, P256dh: req.Keys.P256dh, Auth: req.Keys.Auth}
+	if err := h.notificationUseCase.SubscribePush(c.Context(), user.ID, sub, c.Get(fiber.HeaderUserAgent)); err != nil {
+		return h.handleError(c, err)
+	}
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+// UnsubscribePush удаляет подписку Web Push браузера.
+// @Summary Отписать браузер от Web Push
+// @Tags notifications
+// @Accept json
+// @Param subscription body DeletePushSubscriptionRequest true "Адрес подписки"
+// @Success 204
+// @Failure 400 {object} groupDelivery.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Подписка не найдена"
+// @Router /notifications/push/subscriptions [delete]
+func (h *Handler) UnsubscribePush(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*domain.User)
+	if !ok || user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "Unauthorized"})
+	}
+	req, ok, err := validation.BindAndValidate[DeletePushSubscriptionRequest](c)
+	if !ok {
+		return err
+	}
+	if err := h.notificationUseCase.UnsubscribePush(c.Context(), user.ID, req.Endpoint); err != nil {
+		return h.handleError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetNotificationChannels возвращает каналы уведомлений текущего пользователя.
+// @Summary Мои каналы уведомлений
+// @Description По умолчанию уведомления приходят во все каналы: в Telegram, на email и в подписанные браузеры.
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} NotificationChannelsResponse
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Router /notifications/channels [get]
+func (h *Handler) GetNotificationChannels(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*domain.User)
+	if !ok || user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "Unauthorized"})
+	}
+	channels := user.NotificationChannelList()
+	if user.NotificationChannels == "" {
+		channels = domain.NotifyChannels
+	}
+	return c.JSON(NotificationChannelsResponse{Channels: nonNil(channels), Available: domain.NotifyChannels})
+}
+
+// SetNotificationChannels задает каналы уведомлений текущего пользователя.
+// @Summary Выбрать каналы уведомлений
+// @Description Например, ["push", "email"] отключает сообщения бота. Пустой список отключает все уведомления.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param channels body NotificationChannelsRequest true "Каналы: telegram, email, push"
+// @Success 200 {object} NotificationChannelsResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Неизвестный канал"
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Router /notifications/channels [put]
+func (h *Handler) SetNotificationChannels(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*domain.User)
+	if !ok || user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "Unauthorized"})
+	}
+	req, ok, err := validation.BindAndValidate[NotificationChannelsRequest](c)
+	if !ok {
+		return err
+	}
+	channels, err := h.notificationUseCase.SetNotificationChannels(c.Context(), user.ID, req.Channels)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	return c.JSON(NotificationChannelsResponse{Channels: nonNil(channels), Available: domain.NotifyChannels})
+}
+
+// GetWeeklyDigest возвращает настройку еженедельной сводки текущего пользователя.
+// @Summary Настройка еженедельной сводки
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} WeeklyDigestResponse
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Router /notifications/digest [get]
+func (h *Handler) GetWeeklyDigest(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*domain.User)
+	if !ok || user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "Unauthorized"})
+	}
+	return c.JSON(WeeklyDigestResponse{Enabled: user.WeeklyDigest})
+}
+
+// SetWeeklyDigest включает или отключает еженедельную сводку текущего пользователя.
+// @Summary Включить или отключить еженедельную сводку
+// @Description Сводка новых, измененных и удаленных контактов приходит раз в неделю в выбранные каналы уведомлений.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param digest body WeeklyDigestRequest true "Настройка сводки"
+// @Success 200 {object} WeeklyDigestResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Router /notifications/digest [put]
+func (h *Handler) SetWeeklyDigest(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*domain.User)
+	if !ok || user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "Unauthorized"})
+	}
+	req, ok, err := validation.BindAndValidate[WeeklyDigestRequest](c)
+	if !ok {
+		return err
+	}
+	if err := h.notificationUseCase.SetWeeklyDigest(c.Context(), user.ID, *req.Enabled); err != nil {
+		return h.handleError(c, err)
+	}
+	return c.JSON(WeeklyDigestResponse{Enabled: *req.Enabled})
+}
+
+// handleError преобразует ошибку usecase в HTTP-ответ.
+func (h *Handler) handleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrPushDisabled), errors.Is(err, usecase.ErrSubscriptionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, webpush.ErrInvalidSubscription), errors.Is(err, usecase.ErrUnknownNotifyChannel):
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	}
+	h.logger.ErrorContext(c.Context(), "Notification settings operation failed", slog.Any("error", err))
+	return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+}
+
+func nonNil(channels []string) []string {
+	if channels == nil {
+		return []string{}
+	}
+	return channels
+}
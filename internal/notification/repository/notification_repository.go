@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository определяет интерфейс для подписок Web Push и выбора каналов уведомлений
+type Repository interface {
+	// GetUserByContactID возвращает активного пользователя контакта (получателя уведомления)
+	GetUserByContactID(ctx context.Context, contactID uint) (*domain.User, error)
+	UpdateNotificationChannels(ctx context.Context, userID uint, channels string) error
+	UpdateWeeklyDigest(ctx context.Context, userID uint, enabled bool) error
+
+	// SavePushSubscription создает подписку или обновляет ключи и владельца подписки с тем же адресом
+	SavePushSubscription(ctx context.Context, sub *domain.PushSubscription) error
+	GetPushSubscriptions(ctx context.Context, userID uint) ([]domain.PushSubscription, error)
+	// DeletePushSubscription удаляет подписку пользователя по адресу
+	DeletePushSubscription(ctx context.Context, userID uint, endpoint string) error
+	// DeletePushSubscriptionByEndpoint удаляет подписку, которую сервис доставки больше не принимает
+	DeletePushSubscriptionByEndpoint(ctx context.Context, endpoint string) error
+}
+
+type notificationRepository struct {
+	*repository.BaseRepository[domain.PushSubscription]
+}
+
+// NewSQLiteRepository создает новый экземпляр репозитория уведомлений
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &notificationRepository{
+		BaseRepository: repository.NewBaseRepository[domain.PushSubscription](db, logger),
+	}
+}
+
+func (r *notificationRepository) GetUserByContactID(ctx context.Context, contactID uint) (*domain.User, error) {
+	var user domain.User
+	err := r.DB().WithContext(ctx).Where("contact_id = ? AND is_active = ?", contactID, true).First(&user).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.Logger().ErrorContext(ctx, "Failed to get user by contact ID", slog.Uint64("contact_id", uint64(contactID)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *notificationRepository) UpdateNotificationChannels(ctx context.Context, userID uint, channels string) error {
+	err := r.DB().WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).UpdateColumn("notification_channels", channels).Error
+	if err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to update notification channels", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+	}
+	return err
+}
+
+func (r *notificationRepository) UpdateWeeklyDigest(ctx context.Context, userID uint, enabled bool) error {
+	err := r.DB().WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).UpdateColumn("weekly_digest", enabled).Error
+	if err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to update weekly digest preference", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+	}
+	return err
+}
+
+func (r *notificationRepository) SavePushSubscription(ctx context.Context, sub *domain.PushSubscription) error {
+	// Браузер сохраняет адрес подписки при смене пользователя, поэтому подписка переходит к новому владельцу
+	err := r.DB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "endpoint"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "p256dh", "auth", "user_agent"}),
+	}).Create(sub).Error
+	if err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to save push subscription", slog.Uint64("user_id", uint64(sub.UserID)), slog.Any("error", err))
+	}
+	return err
+}
+
+func (r *notificationRepository) GetPushSubscriptions(ctx context.Context, userID uint) ([]domain.PushSubscription, error) {
+	var subs []domain.PushSubscription
+	if err := r.DB().WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&subs).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to get push subscriptions", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (r *notificationRepository) DeletePushSubscription(ctx context.Context, userID uint, endpoint string) error {
+	result := r.DB().WithContext(ctx).Where("user_id = ? AND endpoint = ?", userID, endpoint).Delete(&domain.PushSubscription{})
+	if result.Error != nil {
+		r.Logger().ErrorContext(ctx, "Failed to delete push subscription", slog.Uint64("user_id", uint64(userID)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepository) DeletePushSubscriptionByEndpoint(ctx context.Context, endpoint string) error {
+	if err := r.DB().WithContext(ctx).Where("endpoint = ?", endpoint).Delete(&domain.PushSubscription{}).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to delete expired push subscription", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"context"
+
+	"rim/internal/domain"
+	"rim/pkg/eventbus"
+)
+
+// SubscribeEvents подписывает uc на доменные события контактов. Об изменении контакта администраторы
+// узнают только при смене телефона или email: переименования в дайджест не попадают.
+func SubscribeEvents(bus *eventbus.Bus, uc UseCase) {
+	eventbus.On(bus, func(ctx context.Context, e domain.ContactCreatedEvent) error {
+		return uc.ContactCreated(ctx, e.Contact)
+	})
+	eventbus.On(bus, func(ctx context.Context, e domain.ContactDeletedEvent) error {
+		return uc.ContactDeleted(ctx, e.Contact)
+	})
+	eventbus.On(bus, func(ctx context.Context, e domain.ContactChangedEvent) error {
+		if e.Field != "phone" && e.Field != "email" {
+			return nil
+		}
+		return uc.ContactChanged(ctx, e.Contact, e.Field, e.OldValue, e.NewValue)
+	})
+}
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	contactRepo "rim/internal/contact/repository"
+	"rim/internal/domain"
+	notificationRepo "rim/internal/notification/repository"
+	outboxUseCase "rim/internal/outbox/usecase"
+	"rim/pkg/notify"
+	"rim/pkg/tenant"
+	"rim/pkg/webpush"
+)
+
+// Типы событий изменения контактов
+const (
+	EventContactCreated = "created"
+	EventContactDeleted = "deleted"
+	EventContactChanged = "changed" // Изменен телефон или email
+)
+
+// TopicContactEvents - тема outbox, в которую записываются события изменения контактов для дайджеста
+const TopicContactEvents = "notification.contact_event"
+
+// maxDigestLines - сколько событий перечисляется в одном дайджесте, остальные только считаются
+const maxDigestLines = 50
+
+// ContactEvent описывает изменение контакта, о котором уведомляются администраторы
+type ContactEvent struct {
+	Type      string `json:"type"`
+	ContactID uint   `json:"contact_id"`
+	Name      string `json:"name"`
+	Field     string `json:"field,omitempty"` // Для EventContactChanged: phone или email
+	OldValue  string `json:"old_value,omitempty"`
+	NewValue  string `json:"new_value,omitempty"`
+}
+
+// UseCase определяет интерфейс уведомлений администраторов об изменениях контактов.
+// События накапливаются в outbox и отправляются дайджестом, чтобы массовые операции (импорт) не рассылали
+// по сообщению на каждый контакт.
+type UseCase interface {
+	// ContactCreated, ContactDeleted и ContactChanged записывают событие в outbox. Вызываются в транзакции
+	// изменения контакта: ошибка записи события должна откатить изменение.
+	ContactCreated(ctx context.Context, contact *domain.Contact) error
+	ContactDeleted(ctx context.Context, contact *domain.Contact) error
+	ContactChanged(ctx context.Context, contact *domain.Contact, field, oldValue, newValue string) error
+
+	// NotifyAdmins и NotifyContact отправляют сообщение сразу, минуя дайджест.
+	// Отправка выполняется в фоне и не задерживает запрос.
+	NotifyAdmins(ctx context.Context, subject, text string)
+	NotifyContact(ctx context.Context, contact *domain.Contact, subject, text string)
+
+	// PushPublicKey возвращает открытый ключ VAPID для подписки браузера или ErrPushDisabled
+	PushPublicKey() (string, error)
+	// SubscribePush сохраняет подписку Web Push браузера пользователя
+	SubscribePush(ctx context.Context, userID uint, sub webpush.Subscription, userAgent string) error
+	// UnsubscribePush удаляет подписку Web Push пользователя по адресу
+	UnsubscribePush(ctx context.Context, userID uint, endpoint string) error
+	// GetPushSubscriptions возвращает подписки Web Push пользователя
+	GetPushSubscriptions(ctx context.Context, userID uint) ([]domain.PushSubscription, error)
+	// SetNotificationChannels задает каналы, в которые пользователь получает уведомления
+	SetNotificationChannels(ctx context.Context, userID uint, channels []string) ([]string, error)
+	// SetWeeklyDigest включает или отключает еженедельную сводку изменений справочника
+	SetWeeklyDigest(ctx context.Context, userID uint, enabled bool) error
+}
+
+type notificationUseCase struct {
+	contactRepo   contactRepo.Repository
+	repo          notificationRepo.Repository
+	channels      []notify.Channel
+	adminGroupID  uint   // 0 - уведомления отключены
+	pushPublicKey string // Пустой - Web Push не настроен
+	outbox        outboxUseCase.UseCase
+	logger        *slog.Logger
+}
+
+// NewNotificationUseCase создает новый экземпляр notificationUseCase.
+// Получатели - контакты группы adminGroupID; каждому уведомление отправляется во все выбранные им каналы.
+// pushPublicKey - открытый ключ VAPID, если настроен канал Web Push.
+// Дайджест отправляет обработчик темы TopicContactEvents в ou с периодичностью его Run.
+func NewNotificationUseCase(cr contactRepo.Repository, repo notificationRepo.Repository, channels []notify.Channel, adminGroupID uint, pushPublicKey string, ou outboxUseCase.UseCase, logger *slog.Logger) UseCase {
+	uc := &notificationUseCase{
+		contactRepo:   cr,
+		repo:          repo,
+		channels:      channels,
+		adminGroupID:  adminGroupID,
+		pushPublicKey: pushPublicKey,
+		outbox:        ou,
+		logger:        logger,
+	}
+	if adminGroupID != 0 {
+		ou.Subscribe(TopicContactEvents, uc.sendDigest)
+	}
+	return uc
+}
+
+func (uc *notificationUseCase) ContactCreated(ctx context.Context, contact *domain.Contact) error {
+	return uc.publish(ctx, ContactEvent{Type: EventContactCreated, ContactID: contact.ID, Name: contact.Name})
+}
+
+func (uc *notificationUseCase) ContactDeleted(ctx context.Context, contact *domain.Contact) error {
+	return uc.publish(ctx, ContactEvent{Type: EventContactDeleted, ContactID: contact.ID, Name: contact.Name})
+}
+
+func (uc *notificationUseCase) ContactChanged(ctx context.Context, contact *domain.Contact, field, oldValue, newValue string) error {
+	return uc.publish(ctx, ContactEvent{Type: EventContactChanged, ContactID: contact.ID, Name: contact.Name, Field: field, OldValue: oldValue, NewValue: newValue})
+}
+
+func (uc *notificationUseCase) publish(ctx context.Context, event ContactEvent) error {
+	if uc.adminGroupID == 0 {
+		return nil
+	}
+	return uc.outbox.Publish(ctx, TopicContactEvents, event)
+}
+
+// sendDigest - обработчик outbox: отправляет накопленные события одним дайджестом.
+// Если процесс остановится после отправки, но до удаления событий, дайджест придет повторно.
+func (uc *notificationUseCase) sendDigest(ctx context.Context, outboxEvents []domain.OutboxEvent) error {
+	events := make([]ContactEvent, 0, len(outboxEvents))
+	for _, outboxEvent := range outboxEvents {
+		var event ContactEvent
+		if err := json.Unmarshal([]byte(outboxEvent.Payload), &event); err != nil {
+			// Испорченное событие не должно блокировать остальные
+			uc.logger.ErrorContext(ctx, "Failed to parse contact event from outbox", slog.Uint64("event_id", uint64(outboxEvent.ID)), slog.Any("error", err))
+			continue
+		}
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	recipients, err := uc.contactRepo.GetAll(ctx, contactRepo.ListFilter{GroupID: uc.adminGroupID})
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to get notification recipients", slog.Uint64("groupID", uint64(uc.adminGroupID)), slog.Any("error", err))
+		return err
+	}
+
+	subject, text := digest(events)
+	for i := range recipients {
+		uc.send(ctx, &recipients[i], subject, text)
+	}
+	uc.logger.InfoContext(ctx, "Contact change digest sent", slog.Int("events", len(events)), slog.Int("recipients", len(recipients)))
+	return nil
+}
+
+func (uc *notificationUseCase) NotifyAdmins(ctx context.Context, subject, text string) {
+	if uc.adminGroupID == 0 {
+		return
+	}
+	// Группа получателей задана конфигурацией развертывания, а не организацией запроса
+	ctx = tenant.Unscoped(context.WithoutCancel(ctx))
+	go func() {
+		recipients, err := uc.contactRepo.GetAll(ctx, contactRepo.ListFilter{GroupID: uc.adminGroupID})
+		if err != nil {
+			uc.logger.ErrorContext(ctx, "Failed to get notification recipients", slog.Uint64("groupID", uint64(uc.adminGroupID)), slog.Any("error", err))
+			return
+		}
+		for i := range recipients {
+			uc.send(ctx, &recipients[i], subject, text)
+		}
+	}()
+}
+
+func (uc *notificationUseCase) NotifyContact(ctx context.Context, contact *domain.Contact, subject, text string) {
+	ctx = context.WithoutCancel(ctx)
+	go uc.send(ctx, contact, subject, text)
+}
+
+// send отправляет сообщение контакту во все каналы, выбранные его пользователем.
+// Контакт без пользователя получает сообщения во все каналы, кроме Web Push.
+func (uc *notificationUseCase) send(ctx context.Context, contact *domain.Contact, subject, text string) {
+	to := notify.Recipient{TelegramID: contact.TelegramID, Email: contact.Email}
+	user, err := uc.repo.GetUserByContactID(ctx, contact.ID)
+	if err == nil {
+		to.UserID = user.ID
+	}
+	for _, ch := range uc.channels {
+		// Канал лога не выбирается пользователем и работает, только если другие каналы не настроены
+		if user != nil && slices.Contains(domain.NotifyChannels, ch.Name()) && !user.WantsNotifications(ch.Name()) {
+			continue
+		}
+		// Ошибки каналов логируются в самих каналах; недоставка одному получателю не мешает остальным
+		_ = ch.Send(ctx, to, subject, text)
+	}
+}
+
+// digest формирует тему и текст уведомления о событиях
+func digest(events []ContactEvent) (string, string) {
+	if len(events) == 1 {
+		return "RIM: изменение контакта", describe(events[0])
+	}
+	lines := make([]string, 0, maxDigestLines+1)
+	for i, event := range events {
+		if i == maxDigestLines {
+			lines = append(lines, fmt.Sprintf("...и еще %d", len(events)-maxDigestLines))
+			break
+		}
+		lines = append(lines, "• "+describe(event))
+	}
+	return fmt.Sprintf("RIM: изменения контактов (%d)", len(events)), strings.Join(lines, "\n")
+}
+
+func describe(event ContactEvent) string {
+	name := fmt.Sprintf("%s (#%d)", event.Name, event.ContactID)
+	switch event.Type {
+	case EventContactCreated:
+		return "Создан контакт " + name
+	case EventContactDeleted:
+		return "Удален контакт " + name
+	default:
+		return fmt.Sprintf("%s: %s изменен с %q на %q", name, event.Field, event.OldValue, event.NewValue)
+	}
+}
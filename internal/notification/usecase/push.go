@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+
+	"rim/internal/domain"
+	notificationRepo "rim/internal/notification/repository"
+	"rim/pkg/notify"
+	"rim/pkg/webpush"
+
+	"gorm.io/gorm"
+)
+
+// maxPushSubscriptions - число подписок на пользователя; при превышении удаляется самая старая
+const maxPushSubscriptions = 10
+
+var (
+	ErrPushDisabled         = errors.New("web push is not configured")
+	ErrSubscriptionNotFound = errors.New("push subscription not found")
+	ErrUnknownNotifyChannel = errors.New("unknown notification channel")
+)
+
+func (uc *notificationUseCase) PushPublicKey() (string, error) {
+	if uc.pushPublicKey == "" {
+		return "", ErrPushDisabled
+	}
+	return uc.pushPublicKey, nil
+}
+
+func (uc *notificationUseCase) SubscribePush(ctx context.Context, userID uint, sub webpush.Subscription, userAgent string) error {
+	if uc.pushPublicKey == "" {
+		return ErrPushDisabled
+	}
+	if err := sub.Validate(); err != nil {
+		return err
+	}
+	if err := uc.repo.SavePushSubscription(ctx, &domain.PushSubscription{
+		UserID:    userID,
+		Endpoint:  sub.Endpoint,
+		P256dh:    sub.P256dh,
+		Auth:      sub.Auth,
+		UserAgent: userAgent,
+	}); err != nil {
+		return err
+	}
+
+	subs, err := uc.repo.GetPushSubscriptions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(subs)-maxPushSubscriptions; i++ {
+		_ = uc.repo.DeletePushSubscription(ctx, userID, subs[i].Endpoint)
+	}
+	uc.logger.InfoContext(ctx, "Push subscription saved", slog.Uint64("user_id", uint64(userID)))
+	return nil
+}
+
+func (uc *notificationUseCase) UnsubscribePush(ctx context.Context, userID uint, endpoint string) error {
+	if err := uc.repo.DeletePushSubscription(ctx, userID, endpoint); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSubscriptionNotFound
+		}
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Push subscription removed", slog.Uint64("user_id", uint64(userID)))
+	return nil
+}
+
+func (uc *notificationUseCase) GetPushSubscriptions(ctx context.Context, userID uint) ([]domain.PushSubscription, error) {
+	return uc.repo.GetPushSubscriptions(ctx, userID)
+}
+
+func (uc *notificationUseCase) SetNotificationChannels(ctx context.Context, userID uint, channels []string) ([]string, error) {
+	var selected []string
+	for _, channel := range channels {
+		if !slices.Contains(domain.NotifyChannels, channel) {
+			return nil, ErrUnknownNotifyChannel
+		}
+		if !slices.Contains(selected, channel) {
+			selected = append(selected, channel)
+		}
+	}
+	user := &domain.User{ID: userID}
+	user.SetNotificationChannels(selected)
+	if err := uc.repo.UpdateNotificationChannels(ctx, userID, user.NotificationChannels); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Notification channels updated", slog.Uint64("user_id", uint64(userID)), slog.Any("channels", selected))
+	return selected, nil
+}
+
+func (uc *notificationUseCase) SetWeeklyDigest(ctx context.Context, userID uint, enabled bool) error {
+	if err := uc.repo.UpdateWeeklyDigest(ctx, userID, enabled); err != nil {
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Weekly digest preference updated", slog.Uint64("user_id", uint64(userID)), slog.Bool("enabled", enabled))
+	return nil
+}
+
+// pushStore предоставляет каналу Web Push подписки из репозитория
+type pushStore struct {
+	repo notificationRepo.Repository
+}
+
+// NewPushStore создает хранилище подписок для notify.NewPushChannel
+func NewPushStore(repo notificationRepo.Repository) notify.PushSubscriptions {
+	return &pushStore{repo: repo}
+}
+
+func (s *pushStore) PushSubscriptions(ctx context.Context, userID uint) ([]webpush.Subscription, error) {
+	subs, err := s.repo.GetPushSubscriptions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]webpush.Subscription, len(subs))
+	for i, sub := range subs {
+		result[i] = webpush.Subscription{Endpoint: sub.Endpoint, P256dh: sub.P256dh, Auth: sub.Auth}
+	}
+	return result, nil
+}
+
+func (s *pushStore) RemovePushSubscription(ctx context.Context, endpoint string) error {
+	return s.repo.DeletePushSubscriptionByEndpoint(ctx, endpoint)
+}
@@ -0,0 +1,46 @@
+package delivery
+
+import (
+	"rim/internal/domain"
+	"rim/pkg/validation"
+)
+
+// Роли участника организации для тега enum=org_role
+func init() {
+	validation.RegisterEnum("org_role", domain.OrgRoleMember, domain.OrgRoleAdmin)
+}
+
+// OrganizationRequest определяет структуру запроса на создание или переименование организации.
+type OrganizationRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+// OrganizationResponse определяет структуру организации в ответе.
+type OrganizationResponse struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"` // RFC3339 со смещением
+}
+
+// MembershipResponse описывает организацию, доступную текущему пользователю.
+type MembershipResponse struct {
+	OrganizationID uint   `json:"organization_id"`
+	Name           string `json:"name"`
+	Role           string `json:"role"`    // member или admin
+	Current        bool   `json:"current"` // Организация текущего запроса
+}
+
+// SetMemberRequest определяет роль пользователя в организации.
+type SetMemberRequest struct {
+	Role string `json:"role" validate:"required,enum=org_role"`
+}
+
+// MemberResponse описывает явного участника организации.
+type MemberResponse struct {
+	OrganizationID uint   `json:"organization_id"`
+	UserID         uint   `json:"user_id"`
+	ContactID      *uint  `json:"contact_id,omitempty"`
+	Name           string `json:"name"` // Имя контакта пользователя
+	Role           string `json:"role"`
+	CreatedAt      string `json:"created_at"` // RFC3339 со смещением
+}
@@ -0,0 +1,333 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/internal/organization/usecase"
+	"rim/pkg/requestctx"
+	"rim/pkg/tenant"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// OrganizationHeader - заголовок с ID организации, в которой выполняется запрос.
+// Без заголовка используется первая организация пользователя (организация его контакта).
+const OrganizationHeader = "X-Organization-ID"
+
+// ErrInvalidOrganizationHeader возвращается, если заголовок X-Organization-ID не является ID.
+var ErrInvalidOrganizationHeader = errors.New("invalid " + OrganizationHeader + " header")
+
+// SuperAdminChecker проверяет, является ли пользователь суперадминистратором развертывания.
+type SuperAdminChecker func(ctx context.Context, userID uint) (bool, error)
+
+// Handler отвечает за обработку HTTP-запросов организаций.
+type Handler struct {
+	orgUseCase   usecase.UseCase
+	isSuperAdmin SuperAdminChecker
+	logger       *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler.
+// Суперадминистраторы могут работать в любой организации без членства в ней.
+func NewHandler(orgUC usecase.UseCase, isSuperAdmin SuperAdminChecker, logger *slog.Logger) *Handler {
+	return &Handler{
+		orgUseCase:   orgUC,
+		isSuperAdmin: isSuperAdmin,
+		logger:       logger,
+	}
+}
+
+// CurrentOrganization возвращает ID организации текущего запроса.
+func CurrentOrganization(c *fiber.Ctx) uint {
+	orgID, _ := c.Locals(tenant.Key).(uint)
+	return orgID
+}
+
+// CurrentRole возвращает роль пользователя в организации текущего запроса.
+func CurrentRole(c *fiber.Ctx) string {
+	role, _ := c.Locals("org_role").(string)
+	return role
+}
+
+// DefaultOrganization - middleware, выбирающий организацию для запросов без авторизации.
+// Middleware авторизации затем уточняют организацию по членству пользователя.
+func (h *Handler) DefaultOrganization() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := h.Resolve(c, nil); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		return c.Next()
+	}
+}
+
+// Resolve выбирает организацию запроса по заголовку X-Organization-ID и членству пользователя
+// и сохраняет ее в Locals: tenant.Key - ID организации, "org_role" - роль пользователя в ней.
+func (h *Handler) Resolve(c *fiber.Ctx, user *domain.User) error {
+	var requestedID uint
+	if header := c.Get(OrganizationHeader); header != "" {
+		id, err := strconv.ParseUint(header, 10, 32)
+		if err != nil || id == 0 {
+			return ErrInvalidOrganizationHeader
+		}
+		requestedID = uint(id)
+	}
+
+	member, err := h.orgUseCase.ResolveOrganization(c.Context(), user, requestedID)
+	if errors.Is(err, usecase.ErrNotOrganizationMember) && requestedID != 0 && user != nil && !user.IsServiceAccount() {
+		isSuperAdmin, checkErr := h.isSuperAdmin(c.Context(), user.ID)
+		if checkErr != nil {
+			return checkErr
+		}
+		if isSuperAdmin {
+			member, err = &domain.OrganizationMember{OrganizationID: requestedID, UserID: user.ID, Role: domain.OrgRoleAdmin}, nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	c.Locals(tenant.Key, member.OrganizationID)
+	c.Locals("org_role", member.Role)
+	return nil
+}
+
+// GetMyOrganizations возвращает организации текущего пользователя.
+// @Summary Мои организации
+// @Description Возвращает организации, в которых может работать пользователь, и его роль в каждой.
+// @Description Организация выбирается заголовком X-Organization-ID; без него - первая из списка.
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} MembershipResponse
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /organizations/mine [get]
+func (h *Handler) GetMyOrganizations(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*domain.User)
+	if !ok || user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "Unauthorized"})
+	}
+	memberships, err := h.orgUseCase.GetUserOrganizations(c.Context(), user)
+	if err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to get user organizations", slog.Uint64("user_id", uint64(user.ID)), slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	current := CurrentOrganization(c)
+	resp := make([]MembershipResponse, len(memberships))
+	for i, m := range memberships {
+		resp[i] = MembershipResponse{OrganizationID: m.OrganizationID, Role: m.Role, Current: m.OrganizationID == current}
+		if m.Organization != nil {
+			resp[i].Name = m.Organization.Name
+		}
+	}
+	return c.JSON(resp)
+}
+
+// GetOrganizations возвращает все организации развертывания.
+// @Summary Получить список организаций
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} OrganizationResponse
+// @Failure 403 {object} groupDelivery.ErrorResponse "Требуются права суперадминистратора"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /organizations [get]
+func (h *Handler) GetOrganizations(c *fiber.Ctx) error {
+	orgs, err := h.orgUseCase.GetOrganizations(c.Context())
+	if err != nil {
+		return h.organizationError(c, err)
+	}
+	loc := requestctx.Location(c)
+	resp := make([]OrganizationResponse, len(orgs))
+	for i := range orgs {
+		resp[i] = toOrganizationResponse(&orgs[i], loc)
+	}
+	return c.JSON(resp)
+}
+
+// CreateOrganization создает организацию.
+// @Summary Создать организацию
+// @Description Создает пустую организацию. Участников добавляют через /organizations/current/members,
+// @Description а контакты и группы создаются в организации, выбранной заголовком X-Organization-ID.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param organization body OrganizationRequest true "Название организации"
+// @Success 201 {object} OrganizationResponse
+// @Failure 400 {object} validation.Response "Ошибка валидации"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Организация уже существует"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /organizations [post]
+func (h *Handler) CreateOrganization(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[OrganizationRequest](c)
+	if !ok {
+		return err
+	}
+
+	org, err := h.orgUseCase.CreateOrganization(c.Context(), req.Name)
+	if err != nil {
+		return h.organizationError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(toOrganizationResponse(org, requestctx.Location(c)))
+}
+
+// UpdateOrganization переименовывает организацию.
+// @Summary Переименовать организацию
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "ID организации"
+// @Param organization body OrganizationRequest true "Новое название"
+// @Success 200 {object} OrganizationResponse
+// @Failure 400 {object} validation.Response "Ошибка валидации"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Организация не найдена"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Организация с таким названием уже существует"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /organizations/{id} [put]
+func (h *Handler) UpdateOrganization(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid organization ID format"})
+	}
+	req, ok, err := validation.BindAndValidate[OrganizationRequest](c)
+	if !ok {
+		return err
+	}
+
+	org, err := h.orgUseCase.UpdateOrganization(c.Context(), uint(id), req.Name)
+	if err != nil {
+		return h.organizationError(c, err)
+	}
+	return c.JSON(toOrganizationResponse(org, requestctx.Location(c)))
+}
+
+// GetMembers возвращает явных участников текущей организации.
+// @Summary Участники организации
+// @Description Возвращает пользователей, добавленных в текущую организацию явно.
+// @Description Пользователи, чей контакт относится к организации, состоят в ней без явной записи.
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} MemberResponse
+// @Failure 403 {object} groupDelivery.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /organizations/current/members [get]
+func (h *Handler) GetMembers(c *fiber.Ctx) error {
+	members, err := h.orgUseCase.GetMembers(c.Context(), CurrentOrganization(c))
+	if err != nil {
+		return h.organizationError(c, err)
+	}
+	loc := requestctx.Location(c)
+	resp := make([]MemberResponse, len(members))
+	for i := range members {
+		resp[i] = toMemberResponse(&members[i], loc)
+	}
+	return c.JSON(resp)
+}
+
+// SetMember добавляет пользователя в текущую организацию или меняет его роль.
+// @Summary Добавить участника организации
+// @Description Роль admin дает права администратора в пределах организации.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param user_id path int true "ID пользователя"
+// @Param member body SetMemberRequest true "Роль в организации"
+// @Success 200 {object} MemberResponse
+// @Failure 400 {object} validation.Response "Ошибка валидации"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /organizations/current/members/{user_id} [put]
+func (h *Handler) SetMember(c *fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Params("user_id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid user ID format"})
+	}
+	req, ok, err := validation.BindAndValidate[SetMemberRequest](c)
+	if !ok {
+		return err
+	}
+
+	member, err := h.orgUseCase.SetMember(c.Context(), CurrentOrganization(c), uint(userID), req.Role)
+	if err != nil {
+		return h.organizationError(c, err)
+	}
+	return c.JSON(toMemberResponse(member, requestctx.Location(c)))
+}
+
+// RemoveMember удаляет явное членство пользователя в текущей организации.
+// @Summary Удалить участника организации
+// @Tags organizations
+// @Param user_id path int true "ID пользователя"
+// @Success 204 "Участник удален"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Участник не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /organizations/current/members/{user_id} [delete]
+func (h *Handler) RemoveMember(c *fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Params("user_id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid user ID format"})
+	}
+	if err := h.orgUseCase.RemoveMember(c.Context(), CurrentOrganization(c), uint(userID)); err != nil {
+		return h.organizationError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// organizationError преобразует ошибки usecase в HTTP-ответ.
+func (h *Handler) organizationError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrOrganizationNameEmpty), errors.Is(err, usecase.ErrInvalidOrgRole):
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrOrganizationNotFound), errors.Is(err, usecase.ErrMemberUserNotFound), errors.Is(err, usecase.ErrMemberNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrOrganizationNameExists):
+		return c.Status(fiber.StatusConflict).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	}
+	h.logger.ErrorContext(c.Context(), "Organization operation failed", slog.Any("error", err))
+	return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+}
+
+func toOrganizationResponse(org *domain.Organization, loc *time.Location) OrganizationResponse {
+	return OrganizationResponse{
+		ID:        org.ID,
+		Name:      org.Name,
+		CreatedAt: timeutil.Format(org.CreatedAt, loc),
+	}
+}
+
+func toMemberResponse(member *domain.OrganizationMember, loc *time.Location) MemberResponse {
+	resp := MemberResponse{
+		OrganizationID: member.OrganizationID,
+		UserID:         member.UserID,
+		Role:           member.Role,
+		CreatedAt:      timeutil.Format(member.CreatedAt, loc),
+	}
+	if member.User != nil {
+		resp.ContactID = member.User.ContactID
+		resp.Name = member.User.Name
+		if member.User.Contact != nil {
+			resp.Name = member.User.Contact.Name
+		}
+	}
+	return resp
+}
+
+// RequireDefaultOrganization пропускает только запросы в организации по умолчанию.
+// Используется для данных всего развертывания, еще не разделенных по организациям:
+// импорт, токены дашбордов, сервисные аккаунты.
+func (h *Handler) RequireDefaultOrganization() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if CurrentOrganization(c) != domain.DefaultOrganizationID {
+			return c.Status(fiber.StatusForbidden).JSON(groupDelivery.ErrorResponse{Message: "Available only in the default organization"})
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository определяет интерфейс для операций с организациями и их участниками.
+type Repository interface {
+	Create(ctx context.Context, org *domain.Organization) (*domain.Organization, error)
+	GetByID(ctx context.Context, id uint) (*domain.Organization, error)
+	GetByName(ctx context.Context, name string) (*domain.Organization, error)
+	GetAll(ctx context.Context) ([]domain.Organization, error)
+	Update(ctx context.Context, org *domain.Organization) error
+
+	// Явное членство пользователей
+	SetMember(ctx context.Context, member *domain.OrganizationMember) error
+	RemoveMember(ctx context.Context, orgID, userID uint) error
+	GetMembers(ctx context.Context, orgID uint) ([]domain.OrganizationMember, error)
+	GetUserMemberships(ctx context.Context, userID uint) ([]domain.OrganizationMember, error)
+	// ContactOrganization возвращает организацию контакта пользователя; 0, если контакта нет
+	ContactOrganization(ctx context.Context, user *domain.User) (uint, error)
+	UserExists(ctx context.Context, userID uint) (bool, error)
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр sqliteRepository для организаций.
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *sqliteRepository) Create(ctx context.Context, org *domain.Organization) (*domain.Organization, error) {
+	if err := r.db.WithContext(ctx).Create(org).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error creating organization in DB", slog.String("name", org.Name), slog.Any("error", err))
+		return nil, err
+	}
+	r.logger.InfoContext(ctx, "Successfully created organization in DB", slog.Uint64("organizationID", uint64(org.ID)), slog.String("name", org.Name))
+	return org, nil
+}
+
+func (r *sqliteRepository) GetByID(ctx context.Context, id uint) (*domain.Organization, error) {
+	var org domain.Organization
+	if err := r.db.WithContext(ctx).First(&org, id).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.logger.ErrorContext(ctx, "Error getting organization by ID from DB", slog.Uint64("organizationID", uint64(id)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *sqliteRepository) GetByName(ctx context.Context, name string) (*domain.Organization, error) {
+	var org domain.Organization
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&org).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.logger.ErrorContext(ctx, "Error getting organization by name from DB", slog.String("name", name), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *sqliteRepository) GetAll(ctx context.Context) ([]domain.Organization, error) {
+	var orgs []domain.Organization
+	if err := r.db.WithContext(ctx).Order("id").Find(&orgs).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting organizations from DB", slog.Any("error", err))
+		return nil, err
+	}
+	return orgs, nil
+}
+
+func (r *sqliteRepository) Update(ctx context.Context, org *domain.Organization) error {
+	if err := r.db.WithContext(ctx).Model(org).Select("Name", "UpdatedAt").Updates(org).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error updating organization in DB", slog.Uint64("organizationID", uint64(org.ID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// SetMember добавляет пользователя в организацию или меняет его роль и загружает пользователя с контактом.
+func (r *sqliteRepository) SetMember(ctx context.Context, member *domain.OrganizationMember) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(member).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error saving organization member in DB", slog.Uint64("organizationID", uint64(member.OrganizationID)), slog.Uint64("userID", uint64(member.UserID)), slog.Any("error", err))
+		return err
+	}
+	if err := r.db.WithContext(ctx).Preload("User.Contact").
+		Where("organization_id = ? AND user_id = ?", member.OrganizationID, member.UserID).
+		First(member).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error reloading organization member from DB", slog.Uint64("organizationID", uint64(member.OrganizationID)), slog.Uint64("userID", uint64(member.UserID)), slog.Any("error", err))
+		return err
+	}
+	r.logger.InfoContext(ctx, "Successfully saved organization member in DB", slog.Uint64("organizationID", uint64(member.OrganizationID)), slog.Uint64("userID", uint64(member.UserID)), slog.String("role", member.Role))
+	return nil
+}
+
+// RemoveMember удаляет явное членство; возвращает gorm.ErrRecordNotFound, если его не было.
+func (r *sqliteRepository) RemoveMember(ctx context.Context, orgID, userID uint) error {
+	result := r.db.WithContext(ctx).Where("organization_id = ? AND user_id = ?", orgID, userID).Delete(&domain.OrganizationMember{})
+	if result.Error != nil {
+		r.logger.ErrorContext(ctx, "Error deleting organization member from DB", slog.Uint64("organizationID", uint64(orgID)), slog.Uint64("userID", uint64(userID)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	r.logger.InfoContext(ctx, "Successfully deleted organization member from DB", slog.Uint64("organizationID", uint64(orgID)), slog.Uint64("userID", uint64(userID)))
+	return nil
+}
+
+// GetMembers возвращает явных участников организации с пользователями и их контактами.
+func (r *sqliteRepository) GetMembers(ctx context.Context, orgID uint) ([]domain.OrganizationMember, error) {
+	var members []domain.OrganizationMember
+	if err := r.db.WithContext(ctx).Preload("User.Contact").Where("organization_id = ?", orgID).Order("user_id").Find(&members).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting organization members from DB", slog.Uint64("organizationID", uint64(orgID)), slog.Any("error", err))
+		return nil, err
+	}
+	return members, nil
+}
+
+// GetUserMemberships возвращает явные членства пользователя вместе с организациями.
+func (r *sqliteRepository) GetUserMemberships(ctx context.Context, userID uint) ([]domain.OrganizationMember, error) {
+	var members []domain.OrganizationMember
+	if err := r.db.WithContext(ctx).Preload("Organization").Where("user_id = ?", userID).Order("organization_id").Find(&members).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting user organization memberships from DB", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+		return nil, err
+	}
+	return members, nil
+}
+
+// ContactOrganization ищет контакт пользователя по contact_id, а для старых пользователей - по Telegram ID.
+func (r *sqliteRepository) ContactOrganization(ctx context.Context, user *domain.User) (uint, error) {
+	query := r.db.WithContext(ctx).Model(&domain.Contact{})
+	switch {
+	case user.ContactID != nil:
+		query = query.Where("id = ?", *user.ContactID)
+	case user.TelegramID != 0:
+		query = query.Where("telegram_id = ?", user.TelegramID)
+	default:
+		return 0, nil
+	}
+
+	var orgIDs []uint
+	if err := query.Limit(1).Pluck("organization_id", &orgIDs).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting user contact organization from DB", slog.Uint64("userID", uint64(user.ID)), slog.Any("error", err))
+		return 0, err
+	}
+	if len(orgIDs) == 0 {
+		return 0, nil
+	}
+	return orgIDs[0], nil
+}
+
+func (r *sqliteRepository) UserExists(ctx context.Context, userID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error checking user existence in DB", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"rim/internal/domain"
+	"rim/internal/organization/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrganizationNameEmpty  = errors.New("organization name cannot be empty")
+	ErrOrganizationNotFound   = errors.New("organization not found")
+	ErrOrganizationNameExists = errors.New("organization with this name already exists")
+	ErrNotOrganizationMember  = errors.New("user is not a member of the organization")
+	ErrInvalidOrgRole         = errors.New("invalid organization role")
+	ErrMemberUserNotFound     = errors.New("user not found")
+	ErrMemberNotFound         = errors.New("organization member not found")
+)
+
+// UseCase определяет интерфейс для управления организациями и членством в них.
+type UseCase interface {
+	CreateOrganization(ctx context.Context, name string) (*domain.Organization, error)
+	GetOrganizations(ctx context.Context) ([]domain.Organization, error)
+	UpdateOrganization(ctx context.Context, id uint, newName string) (*domain.Organization, error)
+
+	// GetUserOrganizations возвращает организации пользователя: сначала организацию его контакта,
+	// затем явные членства. Пользователь без контакта и членств состоит в организации по умолчанию.
+	GetUserOrganizations(ctx context.Context, user *domain.User) ([]domain.OrganizationMember, error)
+	// ResolveOrganization выбирает организацию запроса. requestedID = 0 - первая организация пользователя.
+	// Гостям (user = nil) доступна любая организация: неавторизованным маршрутам данные контактов не отдаются.
+	ResolveOrganization(ctx context.Context, user *domain.User, requestedID uint) (*domain.OrganizationMember, error)
+
+	SetMember(ctx context.Context, orgID, userID uint, role string) (*domain.OrganizationMember, error)
+	RemoveMember(ctx context.Context, orgID, userID uint) error
+	GetMembers(ctx context.Context, orgID uint) ([]domain.OrganizationMember, error)
+}
+
+type organizationUseCase struct {
+	orgRepo repository.Repository
+	logger  *slog.Logger
+}
+
+// NewOrganizationUseCase создает новый экземпляр organizationUseCase.
+func NewOrganizationUseCase(orgRepo repository.Repository, logger *slog.Logger) UseCase {
+	return &organizationUseCase{
+		orgRepo: orgRepo,
+		logger:  logger,
+	}
+}
+
+func (uc *organizationUseCase) CreateOrganization(ctx context.Context, name string) (*domain.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrOrganizationNameEmpty
+	}
+	if err := uc.ensureNameFree(ctx, name, 0); err != nil {
+		return nil, err
+	}
+
+	org, err := uc.orgRepo.Create(ctx, &domain.Organization{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Organization created successfully", slog.Uint64("id", uint64(org.ID)), slog.String("name", org.Name))
+	return org, nil
+}
+
+func (uc *organizationUseCase) GetOrganizations(ctx context.Context) ([]domain.Organization, error) {
+	return uc.orgRepo.GetAll(ctx)
+}
+
+func (uc *organizationUseCase) UpdateOrganization(ctx context.Context, id uint, newName string) (*domain.Organization, error) {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return nil, ErrOrganizationNameEmpty
+	}
+	org, err := uc.getOrganization(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if org.Name == newName {
+		return org, nil
+	}
+	if err := uc.ensureNameFree(ctx, newName, id); err != nil {
+		return nil, err
+	}
+
+	org.Name = newName
+	if err := uc.orgRepo.Update(ctx, org); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Organization renamed successfully", slog.Uint64("id", uint64(id)), slog.String("name", newName))
+	return org, nil
+}
+
+func (uc *organizationUseCase) GetUserOrganizations(ctx context.Context, user *domain.User) ([]domain.OrganizationMember, error) {
+	memberships, err := uc.orgRepo.GetUserMemberships(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	homeID, err := uc.orgRepo.ContactOrganization(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if homeID == 0 && len(memberships) == 0 {
+		homeID = domain.DefaultOrganizationID
+	}
+	if homeID == 0 {
+		return memberships, nil
+	}
+
+	// Явная запись может повысить роль в организации контакта
+	for i, m := range memberships {
+		if m.OrganizationID == homeID {
+			return append(append([]domain.OrganizationMember{m}, memberships[:i]...), memberships[i+1:]...), nil
+		}
+	}
+	home, err := uc.getOrganization(ctx, homeID)
+	if err != nil {
+		return nil, err
+	}
+	homeMembership := domain.OrganizationMember{
+		OrganizationID: homeID,
+		UserID:         user.ID,
+		Role:           domain.OrgRoleMember,
+		Organization:   home,
+	}
+	return append([]domain.OrganizationMember{homeMembership}, memberships...), nil
+}
+
+func (uc *organizationUseCase) ResolveOrganization(ctx context.Context, user *domain.User, requestedID uint) (*domain.OrganizationMember, error) {
+	if user == nil {
+		if requestedID == 0 {
+			requestedID = domain.DefaultOrganizationID
+		}
+		return &domain.OrganizationMember{OrganizationID: requestedID, Role: domain.OrgRoleMember}, nil
+	}
+
+	memberships, err := uc.GetUserOrganizations(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if len(memberships) == 0 {
+		return nil, ErrNotOrganizationMember
+	}
+	if requestedID == 0 {
+		return &memberships[0], nil
+	}
+	for i := range memberships {
+		if memberships[i].OrganizationID == requestedID {
+			return &memberships[i], nil
+		}
+	}
+	return nil, ErrNotOrganizationMember
+}
+
+func (uc *organizationUseCase) SetMember(ctx context.Context, orgID, userID uint, role string) (*domain.OrganizationMember, error) {
+	if !domain.IsValidOrgRole(role) {
+		return nil, ErrInvalidOrgRole
+	}
+	if _, err := uc.getOrganization(ctx, orgID); err != nil {
+		return nil, err
+	}
+	exists, err := uc.orgRepo.UserExists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrMemberUserNotFound
+	}
+
+	member := &domain.OrganizationMember{OrganizationID: orgID, UserID: userID, Role: role}
+	if err := uc.orgRepo.SetMember(ctx, member); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Organization member saved", slog.Uint64("organizationID", uint64(orgID)), slog.Uint64("userID", uint64(userID)), slog.String("role", role))
+	return member, nil
+}
+
+func (uc *organizationUseCase) RemoveMember(ctx context.Context, orgID, userID uint) error {
+	if err := uc.orgRepo.RemoveMember(ctx, orgID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMemberNotFound
+		}
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Organization member removed", slog.Uint64("organizationID", uint64(orgID)), slog.Uint64("userID", uint64(userID)))
+	return nil
+}
+
+func (uc *organizationUseCase) GetMembers(ctx context.Context, orgID uint) ([]domain.OrganizationMember, error) {
+	if _, err := uc.getOrganization(ctx, orgID); err != nil {
+		return nil, err
+	}
+	return uc.orgRepo.GetMembers(ctx, orgID)
+}
+
+// getOrganization загружает организацию, преобразуя отсутствие записи в ErrOrganizationNotFound.
+func (uc *organizationUseCase) getOrganization(ctx context.Context, id uint) (*domain.Organization, error) {
+	org, err := uc.orgRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return org, nil
+}
+
+// ensureNameFree проверяет, что название не занято другой организацией (кроме exceptID).
+func (uc *organizationUseCase) ensureNameFree(ctx context.Context, name string, exceptID uint) error {
+	existing, err := uc.orgRepo.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != exceptID {
+		return ErrOrganizationNameExists
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+// Repository определяет интерфейс хранения исходящих событий.
+// События общие для всего развертывания и не ограничиваются организацией.
+type Repository interface {
+	// Create сохраняет событие; в транзакции из ctx - вместе с изменением, которое его вызвало
+	Create(ctx context.Context, event *domain.OutboxEvent) error
+	// GetPending возвращает до limit необработанных событий темы в порядке записи.
+	// События, обработка которых не удалась maxAttempts раз, не возвращаются и остаются в таблице для разбора.
+	GetPending(ctx context.Context, topic string, maxAttempts, limit int) ([]domain.OutboxEvent, error)
+	// Delete удаляет обработанные события
+	Delete(ctx context.Context, ids []uint) error
+	// MarkFailed увеличивает число попыток обработки событий и сохраняет причину сбоя
+	MarkFailed(ctx context.Context, ids []uint, reason string) error
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр репозитория исходящих событий
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{db: db, logger: logger}
+}
+
+func (r *sqliteRepository) Create(ctx context.Context, event *domain.OutboxEvent) error {
+	if err := transaction.DB(ctx, r.db).Create(event).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error creating outbox event in DB", slog.String("topic", event.Topic), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) GetPending(ctx context.Context, topic string, maxAttempts, limit int) ([]domain.OutboxEvent, error) {
+	var events []domain.OutboxEvent
+	if err := transaction.DB(ctx, r.db).Where("topic = ? AND attempts < ?", topic, maxAttempts).Order("id").Limit(limit).Find(&events).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting pending outbox events from DB", slog.String("topic", topic), slog.Any("error", err))
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *sqliteRepository) Delete(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := transaction.DB(ctx, r.db).Delete(&domain.OutboxEvent{}, ids).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error deleting outbox events from DB", slog.Int("count", len(ids)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) MarkFailed(ctx context.Context, ids []uint, reason string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	err := transaction.DB(ctx, r.db).Model(&domain.OutboxEvent{}).Where("id IN ?", ids).
+		Updates(map[string]any{"attempts": gorm.Expr("attempts + 1"), "last_error": reason}).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error marking outbox events as failed", slog.Int("count", len(ids)), slog.Any("error", err))
+	}
+	return err
+}
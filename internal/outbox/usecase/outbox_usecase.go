@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"rim/internal/domain"
+	"rim/internal/outbox/repository"
+	"rim/pkg/tenant"
+)
+
+const (
+	// relayBatchSize - сколько событий темы передается обработчику за один раз
+	relayBatchSize = 500
+	// maxAttempts - после стольких неудачных попыток событие больше не передается обработчику
+	maxAttempts = 10
+)
+
+// Handler обрабатывает пачку событий темы в порядке записи. Ошибка оставляет всю пачку
+// для повторной попытки, поэтому обработчик должен допускать повторную доставку.
+type Handler func(ctx context.Context, events []domain.OutboxEvent) error
+
+// UseCase определяет интерфейс исходящих событий (outbox): событие записывается в одной транзакции
+// с изменением данных и передается подписчику фоновой задачей после фиксации
+type UseCase interface {
+	// Publish сохраняет событие topic с payload в формате JSON. Вызывается с ctx транзакции изменения:
+	// при ее откате событие тоже отменяется, а ошибка записи события откатывает изменение.
+	Publish(ctx context.Context, topic string, payload any) error
+	// Subscribe задает обработчик событий темы; вызывается при запуске, до Run
+	Subscribe(topic string, handler Handler)
+	// Relay передает накопленные события обработчикам их тем
+	Relay(ctx context.Context) error
+	// Run вызывает Relay каждые interval до отмены ctx
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type outboxUseCase struct {
+	repo   repository.Repository
+	logger *slog.Logger
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewOutboxUseCase создает новый экземпляр UseCase исходящих событий
+func NewOutboxUseCase(repo repository.Repository, logger *slog.Logger) UseCase {
+	return &outboxUseCase{repo: repo, logger: logger, handlers: map[string]Handler{}}
+}
+
+func (uc *outboxUseCase) Publish(ctx context.Context, topic string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal outbox payload for %s: %w", topic, err)
+	}
+	return uc.repo.Create(ctx, &domain.OutboxEvent{Topic: topic, Payload: string(data)})
+}
+
+func (uc *outboxUseCase) Subscribe(topic string, handler Handler) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.handlers[topic] = handler
+}
+
+func (uc *outboxUseCase) Relay(ctx context.Context) error {
+	// События общие для развертывания, обработчики сами выбирают организацию получателей
+	ctx = tenant.Unscoped(ctx)
+
+	uc.mu.RLock()
+	topics := make([]string, 0, len(uc.handlers))
+	for topic := range uc.handlers {
+		topics = append(topics, topic)
+	}
+	uc.mu.RUnlock()
+	slices.Sort(topics)
+
+	var firstErr error
+	for _, topic := range topics {
+		if err := uc.relayTopic(ctx, topic); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// relayTopic передает обработчику темы накопленные события пачками, пока они не закончатся или обработка не завершится ошибкой
+func (uc *outboxUseCase) relayTopic(ctx context.Context, topic string) error {
+	uc.mu.RLock()
+	handler := uc.handlers[topic]
+	uc.mu.RUnlock()
+
+	for {
+		events, err := uc.repo.GetPending(ctx, topic, maxAttempts, relayBatchSize)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		ids := make([]uint, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+		}
+
+		if err := handler(ctx, events); err != nil {
+			uc.logger.ErrorContext(ctx, "Outbox handler failed, events will be retried", slog.String("topic", topic), slog.Int("events", len(events)), slog.Any("error", err))
+			if markErr := uc.repo.MarkFailed(ctx, ids, err.Error()); markErr != nil {
+				return markErr
+			}
+			for _, event := range events {
+				if event.Attempts+1 >= maxAttempts {
+					uc.logger.ErrorContext(ctx, "Outbox event exhausted delivery attempts", slog.String("topic", topic), slog.Uint64("event_id", uint64(event.ID)))
+				}
+			}
+			return err
+		}
+		// Повторная доставка возможна, если процесс остановится до удаления: обработчики к ней готовы
+		if err := uc.repo.Delete(ctx, ids); err != nil {
+			return err
+		}
+		if len(events) < relayBatchSize {
+			return nil
+		}
+	}
+}
+
+func (uc *outboxUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = uc.Relay(ctx)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package delivery
+
+// RuleRequest определяет структуру запроса на создание или изменение правила политики
+type RuleRequest struct {
+	Role     string `json:"role" validate:"required,max=50"`
+	Resource string `json:"resource" validate:"required,max=100"`
+	Action   string `json:"action" validate:"required,max=50"`
+	Allow    bool   `json:"allow"`
+}
+
+// RuleResponse определяет структуру ответа с правилом политики
+type RuleResponse struct {
+	ID        uint   `json:"id"`
+	Role      string `json:"role"`
+	Resource  string `json:"resource"`
+	Action    string `json:"action"`
+	Allow     bool   `json:"allow"`
+	UpdatedAt string `json:"updated_at"`
+}
@@ -0,0 +1,268 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rim/internal/domain"
+	"rim/internal/policy/usecase"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RoleResolver определяет роль текущего пользователя (guest, user или admin)
+type RoleResolver func(c *fiber.Ctx) (string, error)
+
+// Handler обрабатывает HTTP запросы для правил политики доступа
+type Handler struct {
+	policyUseCase usecase.UseCase
+	resolveRole   RoleResolver
+	logger        *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для политики доступа
+func NewHandler(policyUseCase usecase.UseCase, resolveRole RoleResolver, logger *slog.Logger) *Handler {
+	return &Handler{
+		policyUseCase: policyUseCase,
+		resolveRole:   resolveRole,
+		logger:        logger,
+	}
+}
+
+// Authorize middleware проверяет, разрешено ли роли текущего пользователя действие над ресурсом.
+// Сохраняет роль в c.Locals("role") для фильтрации полей в обработчиках.
+func (h *Handler) Authorize(resource, action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, err := h.resolveRole(c)
+		if err != nil {
+			h.logger.ErrorContext(c.Context(), "Failed to resolve user role", slog.Any("error", err))
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Internal server error",
+			})
+		}
+
+		allowed, err := h.policyUseCase.IsAllowed(c.Context(), role, resource, action)
+		if err != nil {
+			h.logger.ErrorContext(c.Context(), "Failed to evaluate policy", slog.String("role", role), slog.String("resource", resource), slog.String("action", action), slog.Any("error", err))
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Internal server error",
+			})
+		}
+
+		if !allowed {
+			h.logger.WarnContext(c.Context(), "Access denied by policy", slog.String("role", role), slog.String("resource", resource), slog.String("action", action))
+			if role == domain.RoleGuest {
+				return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Authentication required",
+				})
+			}
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"error": "Access denied",
+			})
+		}
+
+		c.Locals("role", role)
+		return c.Next()
+	}
+}
+
+// ScopeResolver возвращает группы, которыми текущий пользователь управляет как модератор
+type ScopeResolver func(c *fiber.Ctx) (domain.GroupScope, error)
+
+// AuthorizeScoped работает как Authorize, но при отказе политики пропускает модераторов групп.
+// Группы модератора сохраняются в c.Locals("group_scope"); проверять, что объект запроса входит
+// в эти группы, должны usecase. Если доступ выдан политикой, group_scope не устанавливается.
+func (h *Handler) AuthorizeScoped(resource, action string, resolveScope ScopeResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, err := h.resolveRole(c)
+		if err != nil {
+			h.logger.ErrorContext(c.Context(), "Failed to resolve user role", slog.Any("error", err))
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Internal server error",
+			})
+		}
+
+		allowed, err := h.policyUseCase.IsAllowed(c.Context(), role, resource, action)
+		if err != nil {
+			h.logger.ErrorContext(c.Context(), "Failed to evaluate policy", slog.String("role", role), slog.String("resource", resource), slog.String("action", action), slog.Any("error", err))
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Internal server error",
+			})
+		}
+		if allowed {
+			c.Locals("role", role)
+			return c.Next()
+		}
+
+		if role == domain.RoleGuest {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Authentication required",
+			})
+		}
+		scope, err := resolveScope(c)
+		if err != nil {
+			h.logger.ErrorContext(c.Context(), "Failed to resolve moderated groups", slog.Any("error", err))
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Internal server error",
+			})
+		}
+		if len(scope) == 0 {
+			h.logger.WarnContext(c.Context(), "Access denied by policy", slog.String("role", role), slog.String("resource", resource), slog.String("action", action))
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"error": "Access denied",
+			})
+		}
+
+		c.Locals("role", role)
+		c.Locals("group_scope", scope)
+		return c.Next()
+	}
+}
+
+// GetAllRules возвращает все правила политики доступа
+// @Summary Получить правила политики доступа
+// @Description Возвращает все правила доступа к маршрутам и полям
+// @Tags policies
+// @Produce json
+// @Success 200 {array} RuleResponse
+// @Failure 500 {object} map[string]string
+// @Router /policies [get]
+func (h *Handler) GetAllRules(c *fiber.Ctx) error {
+	rules, err := h.policyUseCase.GetAllRules(c.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to get policy rules", slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	resp := make([]RuleResponse, len(rules))
+	for i, rule := range rules {
+		resp[i] = toRuleResponse(&rule)
+	}
+	return c.JSON(resp)
+}
+
+// CreateRule создает правило политики доступа
+// @Summary Создать правило политики доступа
+// @Description Создает правило доступа (только для суперадминистраторов)
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Param rule body RuleRequest true "Правило"
+// @Success 201 {object} RuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /policies [post]
+func (h *Handler) CreateRule(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[RuleRequest](c)
+	if !ok {
+		return err
+	}
+
+	rule, err := h.policyUseCase.CreateRule(c.Context(), toRuleData(req))
+	if err != nil {
+		return h.handleRuleError(c, err)
+	}
+	return c.Status(http.StatusCreated).JSON(toRuleResponse(rule))
+}
+
+// UpdateRule изменяет правило политики доступа
+// @Summary Изменить правило политики доступа
+// @Description Изменяет правило доступа (только для суперадминистраторов)
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Param id path int true "ID правила"
+// @Param rule body RuleRequest true "Правило"
+// @Success 200 {object} RuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /policies/{id} [put]
+func (h *Handler) UpdateRule(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid rule ID format",
+		})
+	}
+
+	req, ok, err := validation.BindAndValidate[RuleRequest](c)
+	if !ok {
+		return err
+	}
+
+	rule, err := h.policyUseCase.UpdateRule(c.Context(), uint(id), toRuleData(req))
+	if err != nil {
+		return h.handleRuleError(c, err)
+	}
+	return c.JSON(toRuleResponse(rule))
+}
+
+// DeleteRule удаляет правило политики доступа
+// @Summary Удалить правило политики доступа
+// @Description Удаляет правило доступа (только для суперадминистраторов)
+// @Tags policies
+// @Param id path int true "ID правила"
+// @Success 204 "Правило удалено"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /policies/{id} [delete]
+func (h *Handler) DeleteRule(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid rule ID format",
+		})
+	}
+
+	if err := h.policyUseCase.DeleteRule(c.Context(), uint(id)); err != nil {
+		return h.handleRuleError(c, err)
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// handleRuleError преобразует ошибки usecase в HTTP ответы
+func (h *Handler) handleRuleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrRuleInvalid):
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrRuleNotFound):
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrRuleExists):
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	h.logger.ErrorContext(c.Context(), "Failed to modify policy rule", slog.Any("error", err))
+	return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Internal server error",
+	})
+}
+
+func toRuleData(req RuleRequest) usecase.RuleData {
+	return usecase.RuleData{
+		Role:     req.Role,
+		Resource: req.Resource,
+		Action:   req.Action,
+		Allow:    req.Allow,
+	}
+}
+
+func toRuleResponse(rule *domain.PolicyRule) RuleResponse {
+	return RuleResponse{
+		ID:        rule.ID,
+		Role:      rule.Role,
+		Resource:  rule.Resource,
+		Action:    rule.Action,
+		Allow:     rule.Allow,
+		UpdatedAt: timeutil.Format(rule.UpdatedAt, time.UTC),
+	}
+}
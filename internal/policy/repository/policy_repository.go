@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/repository"
+
+	"gorm.io/gorm"
+)
+
+// Repository определяет интерфейс для операций с правилами политики доступа
+type Repository interface {
+	Create(ctx context.Context, rule *domain.PolicyRule) (*domain.PolicyRule, error)
+	GetByID(ctx context.Context, id uint) (*domain.PolicyRule, error)
+	GetAll(ctx context.Context) ([]domain.PolicyRule, error)
+	Update(ctx context.Context, rule *domain.PolicyRule) (*domain.PolicyRule, error)
+	Delete(ctx context.Context, id uint) error
+	Count(ctx context.Context) (int64, error)
+}
+
+type policyRepository struct {
+	*repository.BaseRepository[domain.PolicyRule]
+}
+
+// NewSQLiteRepository создает новый экземпляр репозитория правил политики доступа
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &policyRepository{
+		BaseRepository: repository.NewBaseRepository[domain.PolicyRule](db, logger),
+	}
+}
+
+// Delete удаляет правило. Правила не используют мягкое удаление.
+func (r *policyRepository) Delete(ctx context.Context, id uint) error {
+	result := r.DB().WithContext(ctx).Delete(&domain.PolicyRule{}, id)
+	if result.Error != nil {
+		r.Logger().ErrorContext(ctx, "Failed to delete policy rule", slog.Uint64("id", uint64(id)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		r.Logger().WarnContext(ctx, "Policy rule not found for deletion", slog.Uint64("id", uint64(id)))
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Count возвращает количество правил
+func (r *policyRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.DB().WithContext(ctx).Model(&domain.PolicyRule{}).Count(&count).Error; err != nil {
+		r.Logger().ErrorContext(ctx, "Failed to count policy rules", slog.Any("error", err))
+		return 0, err
+	}
+	return count, nil
+}
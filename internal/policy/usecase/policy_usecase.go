@@ -0,0 +1,297 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"rim/internal/domain"
+	"rim/internal/policy/repository"
+
+	"gorm.io/gorm"
+)
+
+// Ресурсы, используемые в правилах
+const (
+	ResourceContacts = "contacts"
+	ResourceSystem   = "system"
+	ResourcePolicies = "policies"
+	ResourceTokens   = "tokens"
+	ResourceSkills   = "skills"
+	// ResourceChangeRequests - заявки пользователей на изменение своих контактов
+	ResourceChangeRequests = "change_requests"
+	// ResourceLocations - справочник местоположений (город, кампус, корпус, аудитория)
+	ResourceLocations = "locations"
+	// ResourceImports - пакеты импорта контактов
+	ResourceImports = "imports"
+	// ResourceExports - выгрузка контактов во внешние системы
+	ResourceExports = "exports"
+	// ResourceUsers - учетные записи пользователей (список, неактивные)
+	ResourceUsers = "users"
+	// ResourceServiceAccounts - сервисные аккаунты ботов и интеграций
+	ResourceServiceAccounts = "service_accounts"
+	// ResourceEmergencyContacts - экстренные контакты (ICE); правил по умолчанию нет, доступ есть только у администраторов
+	ResourceEmergencyContacts = "emergency_contacts"
+	// ResourceShortLinks - короткие ссылки /l/{code}; переход по ссылке доступен всем
+	ResourceShortLinks = "short_links"
+	// ResourceContactFields - дополнительные поля контактов организации; значения полей читаются по правилу contact.custom_fields
+	ResourceContactFields = "contact_fields"
+	// ResourceTags - метки контактов; метки конкретного контакта читаются по правилу contact.tags
+	ResourceTags = "tags"
+	// ContactFieldsPrefix - префикс ресурсов полей контакта: contact.phone, contact.email и т.д.
+	ContactFieldsPrefix = "contact."
+)
+
+// Действия, используемые в правилах
+const (
+	ActionList         = "list"
+	ActionRead         = "read"
+	ActionCreate       = "create"
+	ActionUpdate       = "update"
+	ActionDelete       = "delete"
+	ActionRestore      = "restore"
+	ActionManageGroups = "manage_groups"
+	ActionManage       = "manage"
+	ActionMerge        = "merge"   // Поиск и объединение дубликатов контактов
+	ActionHistory      = "history" // Просмотр истории изменений контакта
+)
+
+var (
+	ErrRuleNotFound = errors.New("policy rule not found")
+	ErrRuleInvalid  = errors.New("policy rule role, resource and action are required")
+	ErrRuleExists   = errors.New("policy rule for this role, resource and action already exists")
+)
+
+// RuleData определяет данные для создания или изменения правила
+type RuleData struct {
+	Role     string
+	Resource string
+	Action   string
+	Allow    bool
+}
+
+// UseCase определяет интерфейс для политики доступа
+type UseCase interface {
+	// IsAllowed проверяет, разрешено ли роли выполнять действие над ресурсом
+	IsAllowed(ctx context.Context, role, resource, action string) (bool, error)
+	// AllowedFields возвращает поля сущности, которые роль может читать
+	AllowedFields(ctx context.Context, role, entityPrefix string, fields []string) (map[string]bool, error)
+
+	GetAllRules(ctx context.Context) ([]domain.PolicyRule, error)
+	CreateRule(ctx context.Context, data RuleData) (*domain.PolicyRule, error)
+	UpdateRule(ctx context.Context, id uint, data RuleData) (*domain.PolicyRule, error)
+	DeleteRule(ctx context.Context, id uint) error
+	// SeedDefaultRules создает правила по умолчанию, если таблица правил пуста
+	SeedDefaultRules(ctx context.Context) error
+}
+
+type policyUseCase struct {
+	policyRepo repository.Repository
+	logger     *slog.Logger
+
+	mu    sync.RWMutex
+	rules []domain.PolicyRule // Кэш правил, сбрасывается при изменениях
+}
+
+// NewPolicyUseCase создает новый экземпляр UseCase политики доступа
+func NewPolicyUseCase(policyRepo repository.Repository, logger *slog.Logger) UseCase {
+	return &policyUseCase{
+		policyRepo: policyRepo,
+		logger:     logger,
+	}
+}
+
+// defaultRules повторяют поведение до появления политик:
+// администратор может все, пользователь читает контакты, гость видит только имена.
+var defaultRules = []RuleData{
+	{Role: domain.RoleAdmin, Resource: domain.PolicyWildcard, Action: domain.PolicyWildcard, Allow: true},
+	{Role: domain.RoleUser, Resource: ResourceContacts, Action: ActionList, Allow: true},
+	{Role: domain.RoleUser, Resource: ResourceContacts, Action: ActionRead, Allow: true},
+	{Role: domain.RoleUser, Resource: ContactFieldsPrefix + "*", Action: ActionRead, Allow: true},
+	{Role: domain.RoleUser, Resource: ResourceSkills, Action: ActionList, Allow: true},
+	{Role: domain.RoleUser, Resource: ResourceLocations, Action: ActionList, Allow: true},
+	{Role: domain.RoleUser, Resource: ResourceContactFields, Action: ActionList, Allow: true},
+	{Role: domain.RoleUser, Resource: ResourceTags, Action: ActionList, Allow: true},
+	{Role: domain.RoleGuest, Resource: ResourceContacts, Action: ActionList, Allow: true},
+	{Role: domain.RoleGuest, Resource: ContactFieldsPrefix + "name", Action: ActionRead, Allow: true},
+}
+
+func (uc *policyUseCase) IsAllowed(ctx context.Context, role, resource, action string) (bool, error) {
+	rules, err := uc.loadRules(ctx)
+	if err != nil {
+		return false, err
+	}
+	return evaluate(rules, role, resource, action), nil
+}
+
+func (uc *policyUseCase) AllowedFields(ctx context.Context, role, entityPrefix string, fields []string) (map[string]bool, error) {
+	rules, err := uc.loadRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allowed := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		allowed[field] = evaluate(rules, role, entityPrefix+field, ActionRead)
+	}
+	return allowed, nil
+}
+
+func (uc *policyUseCase) GetAllRules(ctx context.Context) ([]domain.PolicyRule, error) {
+	rules, err := uc.policyRepo.GetAll(ctx)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to get policy rules", slog.Any("error", err))
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (uc *policyUseCase) CreateRule(ctx context.Context, data RuleData) (*domain.PolicyRule, error) {
+	data, err := uc.normalize(ctx, data, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := uc.policyRepo.Create(ctx, &domain.PolicyRule{
+		Role:     data.Role,
+		Resource: data.Resource,
+		Action:   data.Action,
+		Allow:    data.Allow,
+	})
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to create policy rule", slog.Any("error", err))
+		return nil, err
+	}
+	uc.invalidate()
+
+	uc.logger.InfoContext(ctx, "Policy rule created", slog.Uint64("id", uint64(rule.ID)), slog.String("role", rule.Role), slog.String("resource", rule.Resource), slog.String("action", rule.Action), slog.Bool("allow", rule.Allow))
+	return rule, nil
+}
+
+func (uc *policyUseCase) UpdateRule(ctx context.Context, id uint, data RuleData) (*domain.PolicyRule, error) {
+	rule, err := uc.policyRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRuleNotFound
+		}
+		return nil, err
+	}
+
+	data, err = uc.normalize(ctx, data, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Role = data.Role
+	rule.Resource = data.Resource
+	rule.Action = data.Action
+	rule.Allow = data.Allow
+	rule, err = uc.policyRepo.Update(ctx, rule)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to update policy rule", slog.Uint64("id", uint64(id)), slog.Any("error", err))
+		return nil, err
+	}
+	uc.invalidate()
+
+	uc.logger.InfoContext(ctx, "Policy rule updated", slog.Uint64("id", uint64(id)))
+	return rule, nil
+}
+
+func (uc *policyUseCase) DeleteRule(ctx context.Context, id uint) error {
+	if err := uc.policyRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRuleNotFound
+		}
+		uc.logger.ErrorContext(ctx, "Failed to delete policy rule", slog.Uint64("id", uint64(id)), slog.Any("error", err))
+		return err
+	}
+	uc.invalidate()
+
+	uc.logger.InfoContext(ctx, "Policy rule deleted", slog.Uint64("id", uint64(id)))
+	return nil
+}
+
+func (uc *policyUseCase) SeedDefaultRules(ctx context.Context) error {
+	count, err := uc.policyRepo.Count(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	for _, data := range defaultRules {
+		if _, err := uc.CreateRule(ctx, data); err != nil {
+			return err
+		}
+	}
+	uc.logger.InfoContext(ctx, "Default policy rules created", slog.Int("count", len(defaultRules)))
+	return nil
+}
+
+// normalize проверяет данные правила и отсутствие другого правила с той же ролью, ресурсом и действием
+func (uc *policyUseCase) normalize(ctx context.Context, data RuleData, id uint) (RuleData, error) {
+	data.Role = strings.TrimSpace(data.Role)
+	data.Resource = strings.TrimSpace(data.Resource)
+	data.Action = strings.TrimSpace(data.Action)
+	if data.Role == "" || data.Resource == "" || data.Action == "" {
+		return data, ErrRuleInvalid
+	}
+
+	rules, err := uc.loadRules(ctx)
+	if err != nil {
+		return data, err
+	}
+	for _, rule := range rules {
+		if rule.ID != id && rule.Role == data.Role && rule.Resource == data.Resource && rule.Action == data.Action {
+			return data, ErrRuleExists
+		}
+	}
+	return data, nil
+}
+
+// loadRules возвращает правила из кэша, загружая их из БД при необходимости
+func (uc *policyUseCase) loadRules(ctx context.Context) ([]domain.PolicyRule, error) {
+	uc.mu.RLock()
+	rules := uc.rules
+	uc.mu.RUnlock()
+	if rules != nil {
+		return rules, nil
+	}
+
+	rules, err := uc.policyRepo.GetAll(ctx)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to load policy rules", slog.Any("error", err))
+		return nil, err
+	}
+	if rules == nil {
+		rules = []domain.PolicyRule{}
+	}
+
+	uc.mu.Lock()
+	uc.rules = rules
+	uc.mu.Unlock()
+	return rules, nil
+}
+
+func (uc *policyUseCase) invalidate() {
+	uc.mu.Lock()
+	uc.rules = nil
+	uc.mu.Unlock()
+}
+
+// evaluate применяет правила: запрет имеет приоритет, при отсутствии подходящих правил доступ запрещен
+func evaluate(rules []domain.PolicyRule, role, resource, action string) bool {
+	allowed := false
+	for _, rule := range rules {
+		if !rule.Matches(role, resource, action) {
+			continue
+		}
+		if !rule.Allow {
+			return false
+		}
+		allowed = true
+	}
+	return allowed
+}
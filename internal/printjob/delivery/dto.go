@@ -0,0 +1,39 @@
+package delivery
+
+import (
+	"rim/internal/domain"
+	"rim/pkg/validation"
+)
+
+// Статусы заявки, которые можно передать в ChangePrintJobStatusRequest
+func init() {
+	validation.RegisterEnum("print_job_status", domain.PrintJobPending, domain.PrintJobAccepted, domain.PrintJobPrinted, domain.PrintJobHandedOver, domain.PrintJobCancelled)
+}
+
+// PrintJobResponse - заявка на печать
+type PrintJobResponse struct {
+	ID            uint   `json:"id"`
+	FileName      string `json:"file_name"`
+	ContentType   string `json:"content_type"`
+	FileSize      int64  `json:"file_size"`
+	Color         bool   `json:"color"`
+	Copies        int    `json:"copies"`
+	Deadline      string `json:"deadline,omitempty"`
+	Comment       string `json:"comment,omitempty"`
+	Status        string `json:"status"`
+	ContactID     uint   `json:"contact_id"`
+	ContactName   string `json:"contact_name,omitempty"`
+	AssigneeID    *uint  `json:"assignee_id,omitempty"`
+	AssigneeName  string `json:"assignee_name,omitempty"`
+	AcceptedAt    string `json:"accepted_at,omitempty"`
+	PrintedAt     string `json:"printed_at,omitempty"`
+	HandedOverAt  string `json:"handed_over_at,omitempty"`
+	CancelledAt   string `json:"cancelled_at,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	FileAvailable bool   `json:"file_available"` // Файл удаляется после передачи или отмены заявки
+}
+
+// ChangePrintJobStatusRequest - новый статус заявки
+type ChangePrintJobStatusRequest struct {
+	Status string `json:"status" validate:"required,enum=print_job_status"`
+}
@@ -0,0 +1,292 @@
+package delivery
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/internal/printjob/usecase"
+	"rim/pkg/requestctx"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
+)
+
+// maxFileSize - наибольший размер файла заявки на печать
+const maxFileSize = 20 << 20
+
+// Handler отвечает за HTTP-запросы заявок на печать.
+type Handler struct {
+	printJobUseCase usecase.UseCase
+	logger          *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для заявок на печать.
+func NewHandler(pu usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		printJobUseCase: pu,
+		logger:          logger,
+	}
+}
+
+// SubmitPrintJob создает заявку на печать.
+// @Summary Отправить файл на печать
+// @Description Создает заявку от имени контакта текущего пользователя. Заявка видна владельцам принтеров, которые могут ее выполнить
+// @Description (поле контакта printer: цветную печать выполняют только цветные принтеры), и они получают уведомление.
+// @Description Принимаются PDF, PNG и JPEG до 20 МБ.
+// @Tags print-jobs
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Файл для печати"
+// @Param color formData bool false "Цветная печать"
+// @Param copies formData int false "Число копий (по умолчанию 1)"
+// @Param deadline formData string false "Срок в формате RFC 3339"
+// @Param comment formData string false "Комментарий для владельца принтера"
+// @Success 201 {object} PrintJobResponse "Заявка создана"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректные данные или у пользователя нет контакта"
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Нет владельцев подходящих принтеров"
+// @Failure 413 {object} groupDelivery.ErrorResponse "Файл слишком большой"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /print-jobs [post]
+func (h *Handler) SubmitPrintJob(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*domain.User)
+	if !ok || user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "Authentication required"})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "File is required"})
+	}
+	if fileHeader.Size > maxFileSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(groupDelivery.ErrorResponse{Message: "File is too large"})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Cannot read file"})
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, maxFileSize))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Cannot read file"})
+	}
+
+	data := usecase.SubmitData{
+		FileName: fileHeader.Filename,
+		Content:  content,
+		Copies:   1,
+		Comment:  c.FormValue("comment"),
+	}
+	if raw := c.FormValue("color"); raw != "" {
+		if data.Color, err = strconv.ParseBool(raw); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid color value"})
+		}
+	}
+	if raw := c.FormValue("copies"); raw != "" {
+		if data.Copies, err = strconv.Atoi(raw); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid copies value"})
+		}
+	}
+	if raw := c.FormValue("deadline"); raw != "" {
+		deadline, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid deadline format, expected RFC 3339"})
+		}
+		data.Deadline = &deadline
+	}
+
+	job, err := h.printJobUseCase.Submit(c.Context(), user, data)
+	if err != nil {
+		return h.printJobError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(toPrintJobResponse(job, requestctx.Location(c)))
+}
+
+// GetMyPrintJobs возвращает заявки текущего пользователя.
+// @Summary Свои заявки на печать
+// @Tags print-jobs
+// @Produce json
+// @Success 200 {array} PrintJobResponse
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /print-jobs/mine [get]
+func (h *Handler) GetMyPrintJobs(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "Authentication required"})
+	}
+	jobs, err := h.printJobUseCase.GetUserJobs(c.Context(), userID)
+	if err != nil {
+		return h.printJobError(c, err)
+	}
+	return c.JSON(toPrintJobResponses(jobs, requestctx.Location(c)))
+}
+
+// GetPrintQueue возвращает очередь заявок для принтера текущего пользователя.
+// @Summary Очередь печати владельца принтера
+// @Description Возвращает ожидающие заявки, которые может выполнить принтер контакта текущего пользователя, и принятые им незавершенные заявки.
+// @Description Заявки с ближайшим сроком идут первыми.
+// @Tags print-jobs
+// @Produce json
+// @Success 200 {array} PrintJobResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "У пользователя нет контакта"
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 403 {object} groupDelivery.ErrorResponse "У контакта нет принтера"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /print-jobs/queue [get]
+func (h *Handler) GetPrintQueue(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*domain.User)
+	if !ok || user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "Authentication required"})
+	}
+	jobs, err := h.printJobUseCase.GetQueue(c.Context(), user)
+	if err != nil {
+		return h.printJobError(c, err)
+	}
+	return c.JSON(toPrintJobResponses(jobs, requestctx.Location(c)))
+}
+
+// ChangePrintJobStatus меняет статус заявки на печать.
+// @Summary Изменить статус заявки на печать
+// @Description accepted - принять заявку (владелец подходящего принтера), pending - вернуть принятую заявку в очередь,
+// @Description printed и handed_over - отметить напечатанной и переданной (принявший заявку), cancelled - отменить до печати (отправитель).
+// @Description Отправитель получает уведомления о принятии и печати, исполнитель - об отмене.
+// @Tags print-jobs
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заявки"
+// @Param request body ChangePrintJobStatusRequest true "Новый статус"
+// @Success 200 {object} PrintJobResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректные данные"
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Пользователь не может выполнить этот переход"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Заявка не найдена"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Переход из текущего статуса недопустим или статус уже изменен"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /print-jobs/{id}/status [put]
+func (h *Handler) ChangePrintJobStatus(c *fiber.Ctx) error {
+	jobID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid print job ID format"})
+	}
+	user, ok := c.Locals("user").(*domain.User)
+	if !ok || user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "Authentication required"})
+	}
+
+	req, ok, err := validation.BindAndValidate[ChangePrintJobStatusRequest](c)
+	if !ok {
+		return err
+	}
+
+	job, err := h.printJobUseCase.ChangeStatus(c.Context(), user, uint(jobID), req.Status)
+	if err != nil {
+		return h.printJobError(c, err)
+	}
+	return c.JSON(toPrintJobResponse(job, requestctx.Location(c)))
+}
+
+// GetPrintJobFile отдает файл заявки на печать.
+// @Summary Файл заявки на печать
+// @Description Доступен отправителю, принявшему заявку и, пока заявка ожидает, владельцам подходящих принтеров. После передачи или отмены файл удаляется.
+// @Tags print-jobs
+// @Produce application/pdf,image/png,image/jpeg
+// @Param id path int true "ID заявки"
+// @Success 200 {file} binary "Файл"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Заявка не найдена или недоступна"
+// @Failure 410 {object} groupDelivery.ErrorResponse "Файл уже удален"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /print-jobs/{id}/file [get]
+func (h *Handler) GetPrintJobFile(c *fiber.Ctx) error {
+	jobID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid print job ID format"})
+	}
+	user, ok := c.Locals("user").(*domain.User)
+	if !ok || user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(groupDelivery.ErrorResponse{Message: "Authentication required"})
+	}
+
+	job, data, err := h.printJobUseCase.GetFile(c.Context(), user, uint(jobID))
+	if err != nil {
+		return h.printJobError(c, err)
+	}
+	c.Set(fiber.HeaderContentType, job.ContentType)
+	c.Set(fiber.HeaderContentDisposition, "attachment; filename*=UTF-8''"+url.PathEscape(job.FileName))
+	return c.Send(data)
+}
+
+// printJobError преобразует ошибку UseCase в HTTP-ответ
+func (h *Handler) printJobError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrNoLinkedContact), errors.Is(err, usecase.ErrUnsupportedFile),
+		errors.Is(err, usecase.ErrInvalidCopies), errors.Is(err, usecase.ErrInvalidDeadline),
+		errors.Is(err, usecase.ErrCommentTooLong), errors.Is(err, usecase.ErrInvalidStatus):
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrNotPrinterOwner), errors.Is(err, usecase.ErrNotRequester):
+		return c.Status(fiber.StatusForbidden).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrPrintJobNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrNoPrinterOwners), errors.Is(err, usecase.ErrStatusTransition), errors.Is(err, usecase.ErrStatusChanged):
+		return c.Status(fiber.StatusConflict).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrFileNotAvailable):
+		return c.Status(fiber.StatusGone).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	}
+	h.logger.ErrorContext(c.Context(), "Print job request failed", slog.Any("error", err))
+	return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+}
+
+func toPrintJobResponse(job *domain.PrintJob, loc *time.Location) PrintJobResponse {
+	resp := PrintJobResponse{
+		ID:            job.ID,
+		FileName:      job.FileName,
+		ContentType:   job.ContentType,
+		FileSize:      job.FileSize,
+		Color:         job.Color,
+		Copies:        job.Copies,
+		Comment:       job.Comment,
+		Status:        job.Status,
+		ContactID:     job.ContactID,
+		AssigneeID:    job.AssigneeID,
+		CreatedAt:     timeutil.Format(job.CreatedAt, loc),
+		FileAvailable: job.Status != domain.PrintJobHandedOver && job.Status != domain.PrintJobCancelled,
+	}
+	if job.Contact != nil {
+		resp.ContactName = job.Contact.Name
+	}
+	if job.Assignee != nil {
+		resp.AssigneeName = job.Assignee.Name
+	}
+	for _, t := range []struct {
+		value *time.Time
+		dst   *string
+	}{
+		{job.Deadline, &resp.Deadline},
+		{job.AcceptedAt, &resp.AcceptedAt},
+		{job.PrintedAt, &resp.PrintedAt},
+		{job.HandedOverAt, &resp.HandedOverAt},
+		{job.CancelledAt, &resp.CancelledAt},
+	} {
+		if t.value != nil {
+			*t.dst = timeutil.Format(*t.value, loc)
+		}
+	}
+	return resp
+}
+
+func toPrintJobResponses(jobs []domain.PrintJob, loc *time.Location) []PrintJobResponse {
+	resp := make([]PrintJobResponse, len(jobs))
+	for i := range jobs {
+		resp[i] = toPrintJobResponse(&jobs[i], loc)
+	}
+	return resp
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"rim/internal/domain"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPrintJobNotFound = errors.New("print job not found")
+	// ErrStatusChanged - статус заявки изменился между чтением и обновлением (например, ее уже принял другой владелец принтера)
+	ErrStatusChanged = errors.New("print job status has changed")
+)
+
+// Repository определяет интерфейс хранения заявок на печать
+type Repository interface {
+	Create(ctx context.Context, job *domain.PrintJob) error
+	// GetByID возвращает заявку организации запроса с именами отправителя и исполнителя
+	GetByID(ctx context.Context, id uint) (*domain.PrintJob, error)
+	// ListByUser возвращает заявки пользователя, начиная с новых
+	ListByUser(ctx context.Context, userID uint) ([]domain.PrintJob, error)
+	// ListQueue возвращает очередь владельца принтера: ожидающие заявки, которые принтер может выполнить
+	// (цветные - только если color), и незавершенные заявки, принятые контактом assigneeID.
+	// Сначала идут заявки с ближайшим сроком.
+	ListQueue(ctx context.Context, color bool, assigneeID uint) ([]domain.PrintJob, error)
+	// UpdateStatus меняет статус заявки с from на job.Status вместе с исполнителем и временем этапов.
+	// Если статус заявки уже не from, возвращает ErrStatusChanged.
+	UpdateStatus(ctx context.Context, job *domain.PrintJob, from string) error
+	// GetPrinterOwners возвращает активные незаблокированные контакты организации запроса с принтерами из списка printers
+	GetPrinterOwners(ctx context.Context, printers []string) ([]domain.Contact, error)
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр репозитория заявок на печать
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{db: db, logger: logger}
+}
+
+// withNames подгружает только имена отправителя и исполнителя: остальные поля контакта в заявке не нужны
+func withNames(db *gorm.DB) *gorm.DB {
+	names := func(db *gorm.DB) *gorm.DB { return db.Unscoped().Select("id", "name") }
+	return db.Preload("Contact", names).Preload("Assignee", names)
+}
+
+func (r *sqliteRepository) Create(ctx context.Context, job *domain.PrintJob) error {
+	if orgID := tenant.FromContext(ctx); orgID != 0 && job.OrganizationID == 0 {
+		job.OrganizationID = orgID
+	}
+	if err := transaction.DB(ctx, r.db).Create(job).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error creating print job in DB", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) GetByID(ctx context.Context, id uint) (*domain.PrintJob, error) {
+	var job domain.PrintJob
+	err := withNames(transaction.DB(ctx, r.db)).Scopes(tenant.Scope(ctx, "print_jobs")).First(&job, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPrintJobNotFound
+		}
+		r.logger.ErrorContext(ctx, "Error getting print job from DB", slog.Uint64("id", uint64(id)), slog.Any("error", err))
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *sqliteRepository) ListByUser(ctx context.Context, userID uint) ([]domain.PrintJob, error) {
+	var jobs []domain.PrintJob
+	err := withNames(transaction.DB(ctx, r.db)).Scopes(tenant.Scope(ctx, "print_jobs")).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Find(&jobs).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error getting user print jobs from DB", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *sqliteRepository) ListQueue(ctx context.Context, color bool, assigneeID uint) ([]domain.PrintJob, error) {
+	var jobs []domain.PrintJob
+	err := withNames(transaction.DB(ctx, r.db)).Scopes(tenant.Scope(ctx, "print_jobs")).
+		Where("(status = ? AND (color = ? OR ?)) OR (assignee_id = ? AND status IN ?)",
+			domain.PrintJobPending, false, color, assigneeID, []string{domain.PrintJobAccepted, domain.PrintJobPrinted}).
+		Order("deadline IS NULL, deadline, created_at, id").
+		Find(&jobs).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error getting print queue from DB", slog.Uint64("assigneeID", uint64(assigneeID)), slog.Any("error", err))
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *sqliteRepository) UpdateStatus(ctx context.Context, job *domain.PrintJob, from string) error {
+	result := transaction.DB(ctx, r.db).Model(&domain.PrintJob{}).
+		Where("id = ? AND status = ?", job.ID, from).
+		Select("Status", "AssigneeID", "AcceptedAt", "PrintedAt", "HandedOverAt", "CancelledAt", "UpdatedAt").
+		Updates(job)
+	if result.Error != nil {
+		r.logger.ErrorContext(ctx, "Error updating print job status in DB", slog.Uint64("id", uint64(job.ID)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStatusChanged
+	}
+	return nil
+}
+
+func (r *sqliteRepository) GetPrinterOwners(ctx context.Context, printers []string) ([]domain.Contact, error) {
+	var contacts []domain.Contact
+	err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "contacts")).
+		Where("printer IN ? AND status = ? AND blocked = ?", printers, domain.ContactStatusActive, false).
+		Find(&contacts).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error getting printer owners from DB", slog.Any("error", err))
+		return nil, err
+	}
+	return contacts, nil
+}
@@ -0,0 +1,377 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	notificationUseCase "rim/internal/notification/usecase"
+	"rim/internal/printjob/repository"
+	"rim/pkg/timeutil"
+)
+
+const (
+	// MaxCopies - наибольшее число копий в одной заявке
+	MaxCopies = 50
+	// maxCommentLength - наибольшая длина комментария к заявке в символах
+	maxCommentLength = 500
+)
+
+var (
+	ErrPrintJobNotFound = repository.ErrPrintJobNotFound
+	ErrNoLinkedContact  = errors.New("user has no linked contact")
+	ErrUnsupportedFile  = errors.New("only PDF, PNG and JPEG files can be printed")
+	ErrInvalidCopies    = fmt.Errorf("copies must be between 1 and %d", MaxCopies)
+	ErrInvalidDeadline  = errors.New("deadline must be in the future")
+	ErrCommentTooLong   = fmt.Errorf("comment must be at most %d characters", maxCommentLength)
+	ErrNoPrinterOwners  = errors.New("nobody owns a printer for this job")
+	ErrNotPrinterOwner  = errors.New("contact has no printer for this job")
+	ErrNotRequester     = errors.New("only the requester can cancel a print job")
+	ErrInvalidStatus    = errors.New("invalid print job status")
+	ErrStatusTransition = errors.New("print job status transition is not allowed")
+	ErrStatusChanged    = repository.ErrStatusChanged
+	ErrFileNotAvailable = errors.New("print job file is no longer available")
+)
+
+// printableTypes - типы файлов, которые принимаются на печать (определяются по содержимому)
+var printableTypes = []string{"application/pdf", "image/png", "image/jpeg"}
+
+// SubmitData - данные новой заявки на печать
+type SubmitData struct {
+	FileName string
+	Content  []byte
+	Color    bool
+	Copies   int
+	Deadline *time.Time
+	Comment  string
+}
+
+// UseCase определяет интерфейс очереди заявок на печать. Заявка направляется владельцам принтеров,
+// которые могут ее выполнить (Contact.Printer), и проходит статусы pending -> accepted -> printed -> handed_over;
+// отправитель может отменить заявку, пока она не напечатана.
+type UseCase interface {
+	// Submit создает заявку от имени контакта пользователя и уведомляет владельцев подходящих принтеров.
+	// Если подходящих принтеров нет, возвращает ErrNoPrinterOwners.
+	Submit(ctx context.Context, user *domain.User, data SubmitData) (*domain.PrintJob, error)
+	// GetUserJobs возвращает заявки пользователя, начиная с новых
+	GetUserJobs(ctx context.Context, userID uint) ([]domain.PrintJob, error)
+	// GetQueue возвращает очередь владельца принтера: ожидающие заявки, которые его принтер может выполнить,
+	// и принятые им незавершенные заявки
+	GetQueue(ctx context.Context, user *domain.User) ([]domain.PrintJob, error)
+	// ChangeStatus переводит заявку в статус status. Принять заявку может владелец подходящего принтера,
+	// вернуть в очередь, отметить напечатанной и переданной - принявший ее, отменить - отправитель.
+	ChangeStatus(ctx context.Context, user *domain.User, jobID uint, status string) (*domain.PrintJob, error)
+	// GetFile возвращает заявку и ее файл отправителю, исполнителю или, пока заявка ожидает, владельцу подходящего принтера
+	GetFile(ctx context.Context, user *domain.User, jobID uint) (*domain.PrintJob, []byte, error)
+}
+
+type printJobUseCase struct {
+	repo           repository.Repository
+	contactUseCase contactUseCase.UseCase
+	notifier       notificationUseCase.UseCase
+	dir            string
+	logger         *slog.Logger
+}
+
+// NewPrintJobUseCase создает новый экземпляр UseCase для заявок на печать.
+// Файлы заявок хранятся в каталоге dir, который создается при первой записи.
+func NewPrintJobUseCase(repo repository.Repository, cu contactUseCase.UseCase, notifier notificationUseCase.UseCase, dir string, logger *slog.Logger) UseCase {
+	return &printJobUseCase{
+		repo:           repo,
+		contactUseCase: cu,
+		notifier:       notifier,
+		dir:            dir,
+		logger:         logger,
+	}
+}
+
+func (uc *printJobUseCase) Submit(ctx context.Context, user *domain.User, data SubmitData) (*domain.PrintJob, error) {
+	if user.ContactID == nil {
+		return nil, ErrNoLinkedContact
+	}
+	contentType := http.DetectContentType(data.Content)
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	if !slices.Contains(printableTypes, contentType) {
+		return nil, ErrUnsupportedFile
+	}
+	if data.Copies < 1 || data.Copies > MaxCopies {
+		return nil, ErrInvalidCopies
+	}
+	if data.Deadline != nil && !data.Deadline.After(timeutil.Now()) {
+		return nil, ErrInvalidDeadline
+	}
+	data.Comment = strings.TrimSpace(data.Comment)
+	if utf8.RuneCountInString(data.Comment) > maxCommentLength {
+		return nil, ErrCommentTooLong
+	}
+
+	job := &domain.PrintJob{
+		UserID:      user.ID,
+		ContactID:   *user.ContactID,
+		FileName:    fileName(data.FileName),
+		ContentType: contentType,
+		FileSize:    int64(len(data.Content)),
+		Color:       data.Color,
+		Copies:      data.Copies,
+		Deadline:    data.Deadline,
+		Comment:     data.Comment,
+		Status:      domain.PrintJobPending,
+	}
+	owners, err := uc.printerOwners(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	if len(owners) == 0 {
+		return nil, ErrNoPrinterOwners
+	}
+
+	// Файл записывается до создания заявки, чтобы в очереди не появилась заявка без файла
+	if err := os.MkdirAll(uc.dir, 0o755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(uc.dir, "upload_*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data.Content); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), uc.path(job.ID)); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to store print job file", slog.Uint64("jobID", uint64(job.ID)), slog.Any("error", err))
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Print job submitted", slog.Uint64("jobID", uint64(job.ID)), slog.Uint64("userID", uint64(user.ID)), slog.Int("printerOwners", len(owners)))
+
+	created, err := uc.repo.GetByID(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	text := fmt.Sprintf("%s просит напечатать %q (%s, копий: %d)", contactName(created.Contact), created.FileName, printKind(created.Color), created.Copies)
+	for i := range owners {
+		uc.notifier.NotifyContact(ctx, &owners[i], "RIM: новая заявка на печать", text)
+	}
+	return created, nil
+}
+
+func (uc *printJobUseCase) GetUserJobs(ctx context.Context, userID uint) ([]domain.PrintJob, error) {
+	return uc.repo.ListByUser(ctx, userID)
+}
+
+func (uc *printJobUseCase) GetQueue(ctx context.Context, user *domain.User) ([]domain.PrintJob, error) {
+	contact, err := uc.userContact(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if !(domain.PrintJob{}).PrinterFits(contact.Printer) {
+		return nil, ErrNotPrinterOwner
+	}
+	return uc.repo.ListQueue(ctx, contact.Printer == domain.PrinterColor, contact.ID)
+}
+
+func (uc *printJobUseCase) ChangeStatus(ctx context.Context, user *domain.User, jobID uint, status string) (*domain.PrintJob, error) {
+	job, err := uc.repo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	from := job.Status
+	now := timeutil.Now()
+
+	switch status {
+	case domain.PrintJobAccepted:
+		if from != domain.PrintJobPending {
+			return nil, ErrStatusTransition
+		}
+		contact, err := uc.userContact(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		if !job.PrinterFits(contact.Printer) {
+			return nil, ErrNotPrinterOwner
+		}
+		job.AssigneeID, job.AcceptedAt = &contact.ID, &now
+	case domain.PrintJobPending:
+		// Принявший заявку может вернуть ее в очередь, например если принтер сломался
+		if from != domain.PrintJobAccepted {
+			return nil, ErrStatusTransition
+		}
+		if !isAssignee(user, job) {
+			return nil, ErrNotPrinterOwner
+		}
+		job.AssigneeID, job.AcceptedAt = nil, nil
+	case domain.PrintJobPrinted, domain.PrintJobHandedOver:
+		if status == domain.PrintJobPrinted && from != domain.PrintJobAccepted || status == domain.PrintJobHandedOver && from != domain.PrintJobPrinted {
+			return nil, ErrStatusTransition
+		}
+		if !isAssignee(user, job) {
+			return nil, ErrNotPrinterOwner
+		}
+		if status == domain.PrintJobPrinted {
+			job.PrintedAt = &now
+		} else {
+			job.HandedOverAt = &now
+		}
+	case domain.PrintJobCancelled:
+		if from != domain.PrintJobPending && from != domain.PrintJobAccepted {
+			return nil, ErrStatusTransition
+		}
+		if job.UserID != user.ID {
+			return nil, ErrNotRequester
+		}
+		job.CancelledAt = &now
+	default:
+		return nil, ErrInvalidStatus
+	}
+
+	job.Status = status
+	if err := uc.repo.UpdateStatus(ctx, job, from); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Print job status changed", slog.Uint64("jobID", uint64(job.ID)), slog.String("from", from), slog.String("to", status), slog.Uint64("userID", uint64(user.ID)))
+
+	// Файл больше не нужен: выполненные и отмененные заявки хранятся без него
+	if status == domain.PrintJobHandedOver || status == domain.PrintJobCancelled {
+		if err := os.Remove(uc.path(job.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			uc.logger.WarnContext(ctx, "Failed to remove print job file", slog.Uint64("jobID", uint64(job.ID)), slog.Any("error", err))
+		}
+	}
+
+	updated, err := uc.repo.GetByID(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	uc.notifyStatus(ctx, updated, from)
+	return updated, nil
+}
+
+func (uc *printJobUseCase) GetFile(ctx context.Context, user *domain.User, jobID uint) (*domain.PrintJob, []byte, error) {
+	job, err := uc.repo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.UserID != user.ID && !isAssignee(user, job) {
+		if job.Status != domain.PrintJobPending {
+			return nil, nil, ErrPrintJobNotFound
+		}
+		contact, err := uc.userContact(ctx, user)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !job.PrinterFits(contact.Printer) {
+			return nil, nil, ErrPrintJobNotFound
+		}
+	}
+
+	data, err := os.ReadFile(uc.path(job.ID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, ErrFileNotAvailable
+		}
+		return nil, nil, err
+	}
+	return job, data, nil
+}
+
+// notifyStatus сообщает отправителю о ходе заявки, а исполнителю - об отмене принятой им заявки
+func (uc *printJobUseCase) notifyStatus(ctx context.Context, job *domain.PrintJob, from string) {
+	var to uint
+	var text string
+	switch job.Status {
+	case domain.PrintJobAccepted:
+		to, text = job.ContactID, fmt.Sprintf("%s принял(а) заявку на печать %q", contactName(job.Assignee), job.FileName)
+	case domain.PrintJobPrinted:
+		to, text = job.ContactID, fmt.Sprintf("Файл %q напечатан, его можно забрать у %s", job.FileName, contactName(job.Assignee))
+	case domain.PrintJobCancelled:
+		if from != domain.PrintJobAccepted || job.AssigneeID == nil {
+			return
+		}
+		to, text = *job.AssigneeID, fmt.Sprintf("%s отменил(а) заявку на печать %q", contactName(job.Contact), job.FileName)
+	default:
+		return
+	}
+
+	contact, err := uc.contactUseCase.GetContactByID(ctx, to)
+	if err != nil {
+		uc.logger.WarnContext(ctx, "Failed to get contact for print job notification", slog.Uint64("contactID", uint64(to)), slog.Any("error", err))
+		return
+	}
+	uc.notifier.NotifyContact(ctx, contact, "RIM: заявка на печать", text)
+}
+
+// printerOwners возвращает контакты, чьи принтеры могут выполнить заявку, кроме самого отправителя
+func (uc *printJobUseCase) printerOwners(ctx context.Context, job *domain.PrintJob) ([]domain.Contact, error) {
+	printers := []string{domain.PrinterColor}
+	if !job.Color {
+		printers = append(printers, domain.PrinterPlain)
+	}
+	contacts, err := uc.repo.GetPrinterOwners(ctx, printers)
+	if err != nil {
+		return nil, err
+	}
+	owners := contacts[:0]
+	for _, ct := range contacts {
+		if ct.ID != job.ContactID {
+			owners = append(owners, ct)
+		}
+	}
+	return owners, nil
+}
+
+// userContact возвращает контакт пользователя
+func (uc *printJobUseCase) userContact(ctx context.Context, user *domain.User) (*domain.Contact, error) {
+	if user.ContactID == nil {
+		return nil, ErrNoLinkedContact
+	}
+	return uc.contactUseCase.GetContactByID(ctx, *user.ContactID)
+}
+
+func (uc *printJobUseCase) path(jobID uint) string {
+	return filepath.Join(uc.dir, fmt.Sprintf("job_%d", jobID))
+}
+
+func isAssignee(user *domain.User, job *domain.PrintJob) bool {
+	return user.ContactID != nil && job.AssigneeID != nil && *user.ContactID == *job.AssigneeID
+}
+
+// fileName оставляет от имени загруженного файла только последний элемент пути
+func fileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(strings.TrimSpace(name), "\\", "/"))
+	if name == "." || name == "/" || name == "" {
+		return "file"
+	}
+	return name
+}
+
+func printKind(color bool) string {
+	if color {
+		return "цветная печать"
+	}
+	return "черно-белая печать"
+}
+
+func contactName(contact *domain.Contact) string {
+	if contact == nil || contact.Name == "" {
+		return "Участник"
+	}
+	return contact.Name
+}
@@ -0,0 +1,105 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	contactUseCase "rim/internal/contact/usecase"
+	groupDelivery "rim/internal/group/delivery"
+	groupUseCase "rim/internal/group/usecase"
+	"rim/internal/printout/usecase"
+	"rim/pkg/requestctx"
+)
+
+// Handler отвечает за HTTP-запросы печатных форм в PDF.
+type Handler struct {
+	printoutUseCase usecase.UseCase
+	logger          *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для печатных форм.
+func NewHandler(pu usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		printoutUseCase: pu,
+		logger:          logger,
+	}
+}
+
+// GetContactCard возвращает печатную карточку контакта.
+// @Summary Карточка контакта в PDF
+// @Description Возвращает карточку контакта для бейджа (A6): имя, фото из Telegram, телефон, группы и QR-код с визиткой vCard.
+// @Description Поля, скрытые от роли политикой доступа, не печатаются.
+// @Tags contacts
+// @Produce application/pdf
+// @Param id path int true "ID контакта"
+// @Success 200 {file} binary "Карточка в PDF"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 503 {object} groupDelivery.ErrorResponse "Не настроен шрифт PDF (PDF_FONT_PATH)"
+// @Router /contacts/{id}/pdf [get]
+func (h *Handler) GetContactCard(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+
+	data, err := h.printoutUseCase.ContactCard(c.Context(), uint(contactID), requestctx.Role(c))
+	if err != nil {
+		if errors.Is(err, contactUseCase.ErrContactNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		return h.handleError(c, err, "Failed to generate contact card", slog.Uint64("id", contactID))
+	}
+	return sendPDF(c, data, fmt.Sprintf("contact-%d.pdf", contactID))
+}
+
+// GetGroupRoster возвращает печатный список участников группы.
+// @Summary Список группы в PDF
+// @Description Возвращает список участников группы (A4) по алфавиту: фото, имя, телефон и QR-код с визиткой vCard.
+// @Description Используется как бумажная резервная копия списка. Поля, скрытые от роли политикой доступа, не печатаются.
+// @Tags groups
+// @Produce application/pdf
+// @Param id path int true "ID группы"
+// @Success 200 {file} binary "Список в PDF"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Группа не найдена"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 503 {object} groupDelivery.ErrorResponse "Не настроен шрифт PDF (PDF_FONT_PATH)"
+// @Router /groups/{id}/roster.pdf [get]
+func (h *Handler) GetGroupRoster(c *fiber.Ctx) error {
+	groupID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid group ID format"})
+	}
+
+	data, err := h.printoutUseCase.GroupRoster(c.Context(), uint(groupID), requestctx.Role(c))
+	if err != nil {
+		if errors.Is(err, groupUseCase.ErrGroupNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+		}
+		return h.handleError(c, err, "Failed to generate group roster", slog.Uint64("id", groupID))
+	}
+	return sendPDF(c, data, fmt.Sprintf("group-%d-roster.pdf", groupID))
+}
+
+// handleError отвечает на ошибки формирования PDF, общие для всех форм
+func (h *Handler) handleError(c *fiber.Ctx, err error, msg string, attrs ...any) error {
+	if errors.Is(err, usecase.ErrFontUnavailable) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	}
+	h.logger.ErrorContext(c.Context(), msg, append(attrs, slog.Any("error", err))...)
+	return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+}
+
+// sendPDF отдает документ для просмотра в браузере с именем файла для сохранения
+func sendPDF(c *fiber.Ctx, data []byte, filename string) error {
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="%s"`, filename))
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.Send(data)
+}
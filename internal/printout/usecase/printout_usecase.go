@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	authUseCase "rim/internal/auth/usecase"
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	groupUseCase "rim/internal/group/usecase"
+	"rim/pkg/pdf"
+	"rim/pkg/photocache"
+	"rim/pkg/qrcode"
+	"rim/pkg/timeutil"
+)
+
+var ErrFontUnavailable = errors.New("pdf font is not configured")
+
+// rosterBatchSize - сколько участников группы читается из БД за один запрос
+const rosterBatchSize = 500
+
+// Разметка карточки контакта: альбомная страница A6
+const (
+	cardWidth  = pdf.A6Height
+	cardHeight = pdf.A6Width
+	cardMargin = 24
+	cardPhoto  = 96
+	cardQR     = 110
+)
+
+// Разметка списка группы: страница A4, строка на участника
+const (
+	rosterMargin = 36
+	rosterTop    = 84 // Начало строк под заголовком
+	rosterRow    = 64
+	rosterPhoto  = 56
+)
+
+// UseCase формирует печатные формы: карточки контактов для бейджей и списки групп на бумаге
+type UseCase interface {
+	// ContactCard возвращает PDF-карточку контакта с именем, фото, телефоном и QR-кодом vCard.
+	// Поля, скрытые от роли role политикой доступа, не печатаются.
+	ContactCard(ctx context.Context, contactID uint, role string) ([]byte, error)
+	// GroupRoster возвращает PDF-список участников группы по алфавиту с фото, телефонами и QR-кодами
+	GroupRoster(ctx context.Context, groupID uint, role string) ([]byte, error)
+}
+
+type printoutUseCase struct {
+	contactUseCase contactUseCase.UseCase
+	groupUseCase   groupUseCase.UseCase
+	authUseCase    authUseCase.UseCase
+	photoCache     *photocache.Cache
+	font           *pdf.Font // nil, если шрифт не удалось загрузить
+	logger         *slog.Logger
+}
+
+// NewPrintoutUseCase создает новый экземпляр UseCase для печатных форм.
+// font - шрифт с кириллицей; без него формы недоступны (ErrFontUnavailable).
+func NewPrintoutUseCase(cu contactUseCase.UseCase, gu groupUseCase.UseCase, au authUseCase.UseCase, photoCache *photocache.Cache, font *pdf.Font, logger *slog.Logger) UseCase {
+	return &printoutUseCase{
+		contactUseCase: cu,
+		groupUseCase:   gu,
+		authUseCase:    au,
+		photoCache:     photoCache,
+		font:           font,
+		logger:         logger,
+	}
+}
+
+func (uc *printoutUseCase) ContactCard(ctx context.Context, contactID uint, role string) ([]byte, error) {
+	if uc.font == nil {
+		return nil, ErrFontUnavailable
+	}
+	contact, err := uc.contactUseCase.GetContactByID(ctx, contactID)
+	if err != nil {
+		return nil, err
+	}
+	contacts := []domain.Contact{*contact}
+	if err := uc.contactUseCase.FilterFieldsForRole(ctx, role, contacts); err != nil {
+		return nil, err
+	}
+	contact = &contacts[0]
+
+	doc := pdf.New(uc.font)
+	page := doc.AddPage(cardWidth, cardHeight)
+
+	uc.drawPhoto(ctx, doc, page, contact, cardMargin, cardMargin, cardPhoto)
+
+	textX := float64(cardMargin + cardPhoto + 16)
+	textWidth := cardWidth - textX - cardMargin
+	page.Text(textX, cardMargin+22, 20, fit(uc.font, contact.Name, 20, textWidth))
+	if contact.Phone != "" {
+		page.Text(textX, cardMargin+48, 14, fit(uc.font, contact.Phone, 14, textWidth))
+	}
+	if len(contact.Groups) > 0 {
+		names := make([]string, len(contact.Groups))
+		for i, g := range contact.Groups {
+			names[i] = g.Name
+		}
+		page.Text(textX, cardMargin+70, 10, fit(uc.font, strings.Join(names, ", "), 10, textWidth))
+	}
+
+	uc.drawQR(ctx, page, contact, cardWidth-cardMargin-cardQR, cardHeight-cardMargin-cardQR, cardQR)
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Contact card generated", slog.Uint64("contactID", uint64(contactID)))
+	return buf.Bytes(), nil
+}
+
+func (uc *printoutUseCase) GroupRoster(ctx context.Context, groupID uint, role string) ([]byte, error) {
+	if uc.font == nil {
+		return nil, ErrFontUnavailable
+	}
+	group, err := uc.groupUseCase.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	var members []domain.Contact
+	err = uc.contactUseCase.EachContact(ctx, contactUseCase.ContactFilter{GroupID: groupID}, rosterBatchSize, func(batch []domain.Contact) error {
+		members = append(members, batch...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.contactUseCase.FilterFieldsForRole(ctx, role, members); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return strings.ToLower(members[i].Name) < strings.ToLower(members[j].Name)
+	})
+
+	doc := pdf.New(uc.font)
+	available := pdf.A4Height - rosterTop - rosterMargin // Высота страницы под строками
+	perPage := int(available / rosterRow)
+	pages := max(1, (len(members)+perPage-1)/perPage)
+	date := timeutil.Now().Format("02.01.2006")
+	textWidth := pdf.A4Width - 2*rosterMargin - 2*rosterPhoto - 32
+
+	for p := 0; p < pages; p++ {
+		page := doc.AddPage(pdf.A4Width, pdf.A4Height)
+		page.Text(rosterMargin, rosterMargin+16, 16, fit(uc.font, group.Name, 16, pdf.A4Width-2*rosterMargin))
+		subtitle := fmt.Sprintf("Участников: %d · %s", len(members), date)
+		if pages > 1 {
+			subtitle += fmt.Sprintf(" · стр. %d из %d", p+1, pages)
+		}
+		page.Text(rosterMargin, rosterMargin+34, 10, subtitle)
+		page.Line(rosterMargin, rosterTop-6, pdf.A4Width-rosterMargin, rosterTop-6, 1, 0)
+
+		end := min(len(members), (p+1)*perPage)
+		for i := p * perPage; i < end; i++ {
+			member := &members[i]
+			y := float64(rosterTop + (i-p*perPage)*rosterRow)
+			uc.drawPhoto(ctx, doc, page, member, rosterMargin, y+4, rosterPhoto)
+
+			textX := float64(rosterMargin + rosterPhoto + 16)
+			page.Text(textX, y+26, 12, fit(uc.font, strconv.Itoa(i+1)+". "+member.Name, 12, textWidth))
+			if member.Phone != "" {
+				page.Text(textX, y+44, 11, fit(uc.font, member.Phone, 11, textWidth))
+			}
+			uc.drawQR(ctx, page, member, pdf.A4Width-rosterMargin-rosterPhoto, y+4, rosterPhoto)
+			page.Line(rosterMargin, y+rosterRow, pdf.A4Width-rosterMargin, y+rosterRow, 0.5, 0.8)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Group roster generated", slog.Uint64("groupID", uint64(groupID)), slog.Int("members", len(members)))
+	return buf.Bytes(), nil
+}
+
+// drawPhoto выводит фото пользователя контакта в квадрат со стороной size,
+// а если фото нет или его не удалось загрузить - рамку с инициалами
+func (uc *printoutUseCase) drawPhoto(ctx context.Context, doc *pdf.Document, page *pdf.Page, contact *domain.Contact, x, y, size float64) {
+	if img := uc.loadPhoto(ctx, doc, contact.ID); img != nil {
+		w, h := img.Size()
+		scale := size / float64(max(w, h))
+		drawW, drawH := float64(w)*scale, float64(h)*scale
+		page.Image(img, x+(size-drawW)/2, y+(size-drawH)/2, drawW, drawH)
+		return
+	}
+
+	page.FillRect(x, y, size, size, 0.9)
+	text := initials(contact.Name)
+	fontSize := size * 0.38
+	page.Text(x+(size-uc.font.Width(text, fontSize))/2, y+size/2+fontSize*0.36, fontSize, text)
+}
+
+// loadPhoto добавляет в документ фото Telegram пользователя, связанного с контактом; nil, если фото нет
+func (uc *printoutUseCase) loadPhoto(ctx context.Context, doc *pdf.Document, contactID uint) *pdf.Image {
+	userID, photoURL, err := uc.authUseCase.GetContactPhotoURL(ctx, contactID)
+	if err != nil {
+		if !errors.Is(err, authUseCase.ErrUserNotFound) {
+			uc.logger.WarnContext(ctx, "Failed to get contact photo URL", slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		}
+		return nil
+	}
+	if photoURL == "" {
+		return nil
+	}
+	// Ключ кэша совпадает с GET /users/{id}/photo, чтобы фото не загружалось повторно
+	data, _, err := uc.photoCache.Get(ctx, fmt.Sprintf("user_%d", userID), photoURL)
+	if err != nil {
+		uc.logger.WarnContext(ctx, "Failed to load contact photo", slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return nil
+	}
+	img, err := doc.AddImage(data)
+	if err != nil {
+		uc.logger.WarnContext(ctx, "Failed to embed contact photo", slog.Uint64("contactID", uint64(contactID)), slog.Any("error", err))
+		return nil
+	}
+	return img
+}
+
+// drawQR выводит QR-код с визиткой vCard контакта в квадрат со стороной size, включая свободную зону
+func (uc *printoutUseCase) drawQR(ctx context.Context, page *pdf.Page, contact *domain.Contact, x, y, size float64) {
+	code, err := qrcode.Encode([]byte(vCard(contact)))
+	if err != nil {
+		uc.logger.WarnContext(ctx, "Contact does not fit into a QR code", slog.Uint64("contactID", uint64(contact.ID)), slog.Any("error", err))
+		return
+	}
+	const quietZone = 4 // Свободная зона вокруг кода в модулях
+	module := size / float64(code.Size+2*quietZone)
+	rects := make([][4]float64, 0, code.Size*code.Size/2)
+	for row := 0; row < code.Size; row++ {
+		for col := 0; col < code.Size; col++ {
+			if code.Black(col, row) {
+				rects = append(rects, [4]float64{
+					x + float64(col+quietZone)*module,
+					y + float64(row+quietZone)*module,
+					module, module,
+				})
+			}
+		}
+	}
+	page.FillRects(rects)
+}
+
+// vCard возвращает визитку контакта для QR-кода: при сканировании телефон предлагает сохранить контакт
+func vCard(contact *domain.Contact) string {
+	escape := strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`)
+	name := escape.Replace(contact.Name)
+	var b strings.Builder
+	b.WriteString("BEGIN:VCARD\nVERSION:3.0\n")
+	b.WriteString("N:" + name + "\nFN:" + name + "\n")
+	if contact.Phone != "" {
+		b.WriteString("TEL:" + escape.Replace(contact.Phone) + "\n")
+	}
+	b.WriteString("END:VCARD")
+	return b.String()
+}
+
+// initials возвращает первые буквы первых двух слов имени
+func initials(name string) string {
+	var result []rune
+	for _, word := range strings.Fields(name) {
+		r, _ := utf8.DecodeRuneInString(word)
+		result = append(result, r)
+		if len(result) == 2 {
+			break
+		}
+	}
+	return strings.ToUpper(string(result))
+}
+
+// fit обрезает строку с многоточием, чтобы она помещалась в ширину width при кегле size
+func fit(font *pdf.Font, s string, size, width float64) string {
+	if font.Width(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && font.Width(string(runes)+"…", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,29 @@
+package delivery
+
+// ShortLinkResponse - короткая ссылка
+type ShortLinkResponse struct {
+	ID            uint   `json:"id"`
+	Code          string `json:"code"`
+	ShortURL      string `json:"short_url"`
+	TargetURL     string `json:"target_url"`
+	Purpose       string `json:"purpose"`
+	ExpiresAt     string `json:"expires_at,omitempty"`
+	Expired       bool   `json:"expired"`
+	Clicks        int64  `json:"clicks"`
+	LastClickedAt string `json:"last_clicked_at,omitempty"`
+	CreatedBy     *uint  `json:"created_by,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// CreateShortLinkRequest - новая короткая ссылка
+type CreateShortLinkRequest struct {
+	TargetURL string  `json:"target_url" validate:"required,max=2048"`
+	Code      string  `json:"code,omitempty" validate:"omitempty,max=32"` // Пусто - код генерируется
+	ExpiresAt *string `json:"expires_at,omitempty"`                       // RFC 3339; пусто - бессрочная
+}
+
+// UpdateShortLinkRequest - новые адрес и срок ссылки
+type UpdateShortLinkRequest struct {
+	TargetURL string  `json:"target_url" validate:"required,max=2048"`
+	ExpiresAt *string `json:"expires_at,omitempty"` // RFC 3339; пусто - бессрочная, прошедшая дата закрывает ссылку
+}
@@ -0,0 +1,225 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/internal/shortlink/usecase"
+	"rim/pkg/requestctx"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
+)
+
+// errInvalidExpiresAt - срок ссылки передан не в формате RFC 3339
+var errInvalidExpiresAt = errors.New("invalid expires_at format, expected RFC 3339")
+
+// Handler отвечает за HTTP-запросы коротких ссылок.
+type Handler struct {
+	shortLinkUseCase usecase.UseCase
+	portalURL        string
+	logger           *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для коротких ссылок.
+// portalURL - адрес портала, от которого строятся ссылки /l/{code}.
+func NewHandler(su usecase.UseCase, portalURL string, logger *slog.Logger) *Handler {
+	return &Handler{
+		shortLinkUseCase: su,
+		portalURL:        portalURL,
+		logger:           logger,
+	}
+}
+
+// Redirect перенаправляет по короткой ссылке.
+// @Summary Переход по короткой ссылке
+// @Description Перенаправляет на адрес ссылки и засчитывает переход. Код не зависит от регистра.
+// @Tags short-links
+// @Param code path string true "Код ссылки"
+// @Success 302 "Перенаправление на адрес ссылки"
+// @Failure 404 {string} string "Ссылка не найдена"
+// @Failure 410 {string} string "Срок ссылки истек"
+// @Router /l/{code} [get]
+func (h *Handler) Redirect(c *fiber.Ctx) error {
+	link, err := h.shortLinkUseCase.Resolve(c.Context(), c.Params("code"))
+	if err != nil {
+		// Ссылку открывают в браузере, поэтому ошибки отдаются текстом
+		switch {
+		case errors.Is(err, usecase.ErrLinkNotFound):
+			return c.Status(fiber.StatusNotFound).SendString("Ссылка не найдена")
+		case errors.Is(err, usecase.ErrLinkExpired):
+			return c.Status(fiber.StatusGone).SendString("Срок действия ссылки истек")
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to resolve short link", slog.String("code", c.Params("code")), slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).SendString("Внутренняя ошибка сервера")
+	}
+	// Адрес может смениться или закрыться, поэтому переход не кэшируется
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Redirect(link.TargetURL, fiber.StatusFound)
+}
+
+// GetShortLinks возвращает короткие ссылки организации.
+// @Summary Список коротких ссылок
+// @Description Возвращает ссылки организации со статистикой переходов, начиная с новых. Включает ссылки, созданные ботом.
+// @Tags short-links
+// @Produce json
+// @Success 200 {array} ShortLinkResponse
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Доступ запрещен"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /short-links [get]
+func (h *Handler) GetShortLinks(c *fiber.Ctx) error {
+	links, err := h.shortLinkUseCase.List(c.Context())
+	if err != nil {
+		return h.shortLinkError(c, err)
+	}
+	loc, now := requestctx.Location(c), timeutil.Now()
+	resp := make([]ShortLinkResponse, len(links))
+	for i := range links {
+		resp[i] = h.toResponse(&links[i], loc, now)
+	}
+	return c.JSON(resp)
+}
+
+// CreateShortLink создает короткую ссылку.
+// @Summary Создать короткую ссылку
+// @Description Код из 3-32 латинских букв, цифр и дефисов можно задать вручную, иначе генерируется код из 7 символов без похожих друг на друга.
+// @Tags short-links
+// @Accept json
+// @Produce json
+// @Param request body CreateShortLinkRequest true "Данные ссылки"
+// @Success 201 {object} ShortLinkResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректные данные"
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Доступ запрещен"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Код уже занят"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /short-links [post]
+func (h *Handler) CreateShortLink(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[CreateShortLinkRequest](c)
+	if !ok {
+		return err
+	}
+	expiresAt, err := parseExpiresAt(req.ExpiresAt)
+	if err != nil {
+		return h.shortLinkError(c, err)
+	}
+
+	data := usecase.CreateData{TargetURL: req.TargetURL, Code: req.Code, ExpiresAt: expiresAt, Purpose: domain.ShortLinkManual, CreatedBy: requestctx.ActorID(c)}
+	link, err := h.shortLinkUseCase.Create(c.Context(), data)
+	if err != nil {
+		return h.shortLinkError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(h.toResponse(link, requestctx.Location(c), timeutil.Now()))
+}
+
+// UpdateShortLink меняет адрес и срок короткой ссылки.
+// @Summary Изменить короткую ссылку
+// @Description Код не меняется, чтобы не сломать уже розданные ссылки. Прошедший срок закрывает ссылку, сохраняя статистику.
+// @Tags short-links
+// @Accept json
+// @Produce json
+// @Param id path int true "ID ссылки"
+// @Param request body UpdateShortLinkRequest true "Новые данные"
+// @Success 200 {object} ShortLinkResponse
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректные данные"
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Ссылка не найдена"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /short-links/{id} [put]
+func (h *Handler) UpdateShortLink(c *fiber.Ctx) error {
+	linkID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid short link ID format"})
+	}
+	req, ok, err := validation.BindAndValidate[UpdateShortLinkRequest](c)
+	if !ok {
+		return err
+	}
+	expiresAt, err := parseExpiresAt(req.ExpiresAt)
+	if err != nil {
+		return h.shortLinkError(c, err)
+	}
+
+	link, err := h.shortLinkUseCase.Update(c.Context(), uint(linkID), req.TargetURL, expiresAt)
+	if err != nil {
+		return h.shortLinkError(c, err)
+	}
+	return c.JSON(h.toResponse(link, requestctx.Location(c), timeutil.Now()))
+}
+
+// DeleteShortLink удаляет короткую ссылку.
+// @Summary Удалить короткую ссылку
+// @Tags short-links
+// @Param id path int true "ID ссылки"
+// @Success 204 "Ссылка удалена"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 401 {object} groupDelivery.ErrorResponse "Требуется авторизация"
+// @Failure 403 {object} groupDelivery.ErrorResponse "Доступ запрещен"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Ссылка не найдена"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /short-links/{id} [delete]
+func (h *Handler) DeleteShortLink(c *fiber.Ctx) error {
+	linkID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid short link ID format"})
+	}
+	if err := h.shortLinkUseCase.Delete(c.Context(), uint(linkID)); err != nil {
+		return h.shortLinkError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// shortLinkError преобразует ошибку UseCase в HTTP-ответ
+func (h *Handler) shortLinkError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidURL), errors.Is(err, usecase.ErrInvalidCode),
+		errors.Is(err, usecase.ErrInvalidTTL), errors.Is(err, errInvalidExpiresAt):
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrLinkNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrCodeTaken):
+		return c.Status(fiber.StatusConflict).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	}
+	h.logger.ErrorContext(c.Context(), "Short link request failed", slog.Any("error", err))
+	return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+}
+
+func (h *Handler) toResponse(link *domain.ShortLink, loc *time.Location, now time.Time) ShortLinkResponse {
+	resp := ShortLinkResponse{
+		ID:        link.ID,
+		Code:      link.Code,
+		ShortURL:  h.portalURL + "/l/" + link.Code,
+		TargetURL: link.TargetURL,
+		Purpose:   link.Purpose,
+		Expired:   link.Expired(now),
+		Clicks:    link.Clicks,
+		CreatedBy: link.CreatedBy,
+		CreatedAt: timeutil.Format(link.CreatedAt, loc),
+	}
+	if link.ExpiresAt != nil {
+		resp.ExpiresAt = timeutil.Format(*link.ExpiresAt, loc)
+	}
+	if link.LastClickedAt != nil {
+		resp.LastClickedAt = timeutil.Format(*link.LastClickedAt, loc)
+	}
+	return resp
+}
+
+// parseExpiresAt разбирает срок ссылки; пустое значение - бессрочная ссылка
+func parseExpiresAt(raw *string) (*time.Time, error) {
+	if raw == nil || *raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, *raw)
+	if err != nil {
+		return nil, errInvalidExpiresAt
+	}
+	return &t, nil
+}
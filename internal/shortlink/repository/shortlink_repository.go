@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLinkNotFound = errors.New("short link not found")
+	ErrCodeTaken    = errors.New("short link code is already taken")
+)
+
+// Repository определяет интерфейс хранения коротких ссылок
+type Repository interface {
+	// Create сохраняет ссылку; если код занят, возвращает ErrCodeTaken
+	Create(ctx context.Context, link *domain.ShortLink) error
+	// GetByCode ищет ссылку по коду среди всех организаций: переход по ссылке выполняется без входа
+	GetByCode(ctx context.Context, code string) (*domain.ShortLink, error)
+	// GetByID возвращает ссылку организации запроса
+	GetByID(ctx context.Context, id uint) (*domain.ShortLink, error)
+	// List возвращает ссылки организации запроса, начиная с новых
+	List(ctx context.Context) ([]domain.ShortLink, error)
+	// Update сохраняет адрес и срок ссылки
+	Update(ctx context.Context, link *domain.ShortLink) error
+	Delete(ctx context.Context, id uint) error
+	// RegisterClick увеличивает счетчик переходов
+	RegisterClick(ctx context.Context, id uint, at time.Time) error
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр репозитория коротких ссылок
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{db: db, logger: logger}
+}
+
+func (r *sqliteRepository) Create(ctx context.Context, link *domain.ShortLink) error {
+	if orgID := tenant.FromContext(ctx); orgID != 0 && link.OrganizationID == 0 {
+		link.OrganizationID = orgID
+	}
+	if err := transaction.DB(ctx, r.db).Create(link).Error; err != nil {
+		// Единственный уникальный индекс таблицы - по коду (сообщения SQLite и Postgres)
+		if msg := strings.ToLower(err.Error()); strings.Contains(msg, "unique constraint") || strings.Contains(msg, "duplicate key") {
+			return ErrCodeTaken
+		}
+		r.logger.ErrorContext(ctx, "Error creating short link in DB", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) GetByCode(ctx context.Context, code string) (*domain.ShortLink, error) {
+	var link domain.ShortLink
+	if err := transaction.DB(ctx, r.db).Where("code = ?", code).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLinkNotFound
+		}
+		r.logger.ErrorContext(ctx, "Error getting short link by code from DB", slog.Any("error", err))
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *sqliteRepository) GetByID(ctx context.Context, id uint) (*domain.ShortLink, error) {
+	var link domain.ShortLink
+	if err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "short_links")).First(&link, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLinkNotFound
+		}
+		r.logger.ErrorContext(ctx, "Error getting short link from DB", slog.Uint64("id", uint64(id)), slog.Any("error", err))
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *sqliteRepository) List(ctx context.Context) ([]domain.ShortLink, error) {
+	var links []domain.ShortLink
+	if err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "short_links")).Order("created_at DESC, id DESC").Find(&links).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting short links from DB", slog.Any("error", err))
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *sqliteRepository) Update(ctx context.Context, link *domain.ShortLink) error {
+	if err := transaction.DB(ctx, r.db).Model(link).Select("TargetURL", "ExpiresAt", "UpdatedAt").Updates(link).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error updating short link in DB", slog.Uint64("id", uint64(link.ID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) Delete(ctx context.Context, id uint) error {
+	result := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "short_links")).Delete(&domain.ShortLink{}, id)
+	if result.Error != nil {
+		r.logger.ErrorContext(ctx, "Error deleting short link from DB", slog.Uint64("id", uint64(id)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLinkNotFound
+	}
+	return nil
+}
+
+func (r *sqliteRepository) RegisterClick(ctx context.Context, id uint, at time.Time) error {
+	// UpdateColumns не меняет updated_at: переход не считается изменением ссылки
+	err := transaction.DB(ctx, r.db).Model(&domain.ShortLink{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"clicks": gorm.Expr("clicks + 1"), "last_clicked_at": at}).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error registering short link click in DB", slog.Uint64("id", uint64(id)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"rim/internal/domain"
+	"rim/internal/shortlink/repository"
+	"rim/pkg/timeutil"
+)
+
+const (
+	// codeLength - длина сгенерированного кода
+	codeLength = 7
+	// codeAlphabet - символы сгенерированных кодов без похожих друг на друга (0/o, 1/l/i)
+	codeAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+	// generateAttempts - сколько раз генерируется новый код, если случайный уже занят
+	generateAttempts = 5
+)
+
+var (
+	ErrLinkNotFound = repository.ErrLinkNotFound
+	ErrCodeTaken    = repository.ErrCodeTaken
+	ErrLinkExpired  = errors.New("short link has expired")
+	ErrInvalidURL   = errors.New("target must be an absolute http or https URL")
+	ErrInvalidCode  = errors.New("code must be 3-32 characters: latin letters, digits and hyphens")
+	ErrInvalidTTL   = errors.New("expiration time must be in the future")
+)
+
+// customCodePattern - допустимый код, заданный вручную (после приведения к нижнему регистру)
+var customCodePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{2,31}$`)
+
+// CreateData - данные новой короткой ссылки
+type CreateData struct {
+	TargetURL string
+	Code      string // Пусто - код генерируется
+	ExpiresAt *time.Time
+	Purpose   string
+	CreatedBy *uint
+}
+
+// UseCase определяет интерфейс коротких ссылок /l/{code}
+type UseCase interface {
+	// Create создает ссылку на абсолютный http(s)-адрес
+	Create(ctx context.Context, data CreateData) (*domain.ShortLink, error)
+	// Resolve возвращает ссылку по коду и засчитывает переход. Если срок истек, возвращает ErrLinkExpired.
+	Resolve(ctx context.Context, code string) (*domain.ShortLink, error)
+	// List возвращает ссылки организации
+	List(ctx context.Context) ([]domain.ShortLink, error)
+	// Update меняет адрес и срок ссылки; код не меняется, чтобы не сломать уже розданные ссылки
+	Update(ctx context.Context, id uint, targetURL string, expiresAt *time.Time) (*domain.ShortLink, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type shortLinkUseCase struct {
+	repo   repository.Repository
+	logger *slog.Logger
+}
+
+// NewShortLinkUseCase создает новый экземпляр UseCase для коротких ссылок
+func NewShortLinkUseCase(repo repository.Repository, logger *slog.Logger) UseCase {
+	return &shortLinkUseCase{repo: repo, logger: logger}
+}
+
+func (uc *shortLinkUseCase) Create(ctx context.Context, data CreateData) (*domain.ShortLink, error) {
+	if err := validateTarget(data.TargetURL); err != nil {
+		return nil, err
+	}
+	if data.ExpiresAt != nil && !data.ExpiresAt.After(timeutil.Now()) {
+		return nil, ErrInvalidTTL
+	}
+	if data.Purpose == "" {
+		data.Purpose = domain.ShortLinkManual
+	}
+	link := &domain.ShortLink{
+		TargetURL: strings.TrimSpace(data.TargetURL),
+		Purpose:   data.Purpose,
+		ExpiresAt: data.ExpiresAt,
+		CreatedBy: data.CreatedBy,
+	}
+
+	if data.Code != "" {
+		link.Code = strings.ToLower(strings.TrimSpace(data.Code))
+		if !customCodePattern.MatchString(link.Code) {
+			return nil, ErrInvalidCode
+		}
+		if err := uc.repo.Create(ctx, link); err != nil {
+			return nil, err
+		}
+		return link, nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		code, err := generateCode()
+		if err != nil {
+			return nil, err
+		}
+		link.ID, link.Code = 0, code
+		err = uc.repo.Create(ctx, link)
+		if err == nil {
+			return link, nil
+		}
+		if !errors.Is(err, ErrCodeTaken) || attempt == generateAttempts {
+			return nil, err
+		}
+	}
+}
+
+func (uc *shortLinkUseCase) Resolve(ctx context.Context, code string) (*domain.ShortLink, error) {
+	link, err := uc.repo.GetByCode(ctx, strings.ToLower(code))
+	if err != nil {
+		return nil, err
+	}
+	now := timeutil.Now()
+	if link.Expired(now) {
+		return nil, ErrLinkExpired
+	}
+	// Ошибка счетчика не должна мешать переходу
+	if err := uc.repo.RegisterClick(ctx, link.ID, now); err != nil {
+		uc.logger.WarnContext(ctx, "Failed to count short link click", slog.Uint64("linkID", uint64(link.ID)), slog.Any("error", err))
+	}
+	return link, nil
+}
+
+func (uc *shortLinkUseCase) List(ctx context.Context) ([]domain.ShortLink, error) {
+	return uc.repo.List(ctx)
+}
+
+func (uc *shortLinkUseCase) Update(ctx context.Context, id uint, targetURL string, expiresAt *time.Time) (*domain.ShortLink, error) {
+	if err := validateTarget(targetURL); err != nil {
+		return nil, err
+	}
+	link, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Прошедший срок допустим: так ссылку можно закрыть, не удаляя ее статистику
+	link.TargetURL = strings.TrimSpace(targetURL)
+	link.ExpiresAt = expiresAt
+	if err := uc.repo.Update(ctx, link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (uc *shortLinkUseCase) Delete(ctx context.Context, id uint) error {
+	return uc.repo.Delete(ctx, id)
+}
+
+// validateTarget проверяет, что ссылка ведет на абсолютный http(s)-адрес
+func validateTarget(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// generateCode возвращает случайный код из codeAlphabet
+func generateCode() (string, error) {
+	max := big.NewInt(int64(len(codeAlphabet)))
+	code := make([]byte, codeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = codeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
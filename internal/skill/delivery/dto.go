@@ -0,0 +1,12 @@
+package delivery
+
+// SkillRequest определяет структуру запроса на создание или переименование навыка.
+type SkillRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=50"`
+}
+
+// SkillResponse определяет структуру навыка в ответе.
+type SkillResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,143 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/internal/skill/usecase"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler отвечает за обработку HTTP-запросов справочника навыков.
+type Handler struct {
+	skillUseCase usecase.UseCase
+	logger       *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler.
+func NewHandler(skillUC usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		skillUseCase: skillUC,
+		logger:       logger,
+	}
+}
+
+// GetAllSkills возвращает справочник навыков.
+// @Summary Получить список навыков
+// @Description Возвращает все навыки справочника в алфавитном порядке.
+// @Tags skills
+// @Produce json
+// @Success 200 {array} SkillResponse
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /skills [get]
+func (h *Handler) GetAllSkills(c *fiber.Ctx) error {
+	skills, err := h.skillUseCase.GetAllSkills(c.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to get skills", slog.Any("error", err))
+		return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	resp := make([]SkillResponse, len(skills))
+	for i := range skills {
+		resp[i] = ToSkillResponse(&skills[i])
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// CreateSkill добавляет навык в справочник.
+// @Summary Создать навык
+// @Description Добавляет навык в справочник. Название приводится к нижнему регистру.
+// @Tags skills
+// @Accept json
+// @Produce json
+// @Param skill body SkillRequest true "Название навыка"
+// @Success 201 {object} SkillResponse
+// @Failure 400 {object} validation.Response "Ошибка валидации"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Навык уже существует"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /skills [post]
+func (h *Handler) CreateSkill(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[SkillRequest](c)
+	if !ok {
+		return err
+	}
+
+	skill, err := h.skillUseCase.CreateSkill(c.Context(), req.Name)
+	if err != nil {
+		return h.skillError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(ToSkillResponse(skill))
+}
+
+// UpdateSkill переименовывает навык.
+// @Summary Переименовать навык
+// @Tags skills
+// @Accept json
+// @Produce json
+// @Param id path int true "ID навыка"
+// @Param skill body SkillRequest true "Новое название"
+// @Success 200 {object} SkillResponse
+// @Failure 400 {object} validation.Response "Ошибка валидации"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Навык не найден"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Навык с таким названием уже существует"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /skills/{id} [put]
+func (h *Handler) UpdateSkill(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid skill ID format"})
+	}
+	req, ok, err := validation.BindAndValidate[SkillRequest](c)
+	if !ok {
+		return err
+	}
+
+	skill, err := h.skillUseCase.UpdateSkill(c.Context(), uint(id), req.Name)
+	if err != nil {
+		return h.skillError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(ToSkillResponse(skill))
+}
+
+// DeleteSkill удаляет навык из справочника и у всех контактов.
+// @Summary Удалить навык
+// @Tags skills
+// @Param id path int true "ID навыка"
+// @Success 204 "Навык удален"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Навык не найден"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /skills/{id} [delete]
+func (h *Handler) DeleteSkill(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid skill ID format"})
+	}
+	if err := h.skillUseCase.DeleteSkill(c.Context(), uint(id)); err != nil {
+		return h.skillError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// skillError преобразует ошибки usecase в HTTP-ответ.
+func (h *Handler) skillError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrSkillNameEmpty):
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrSkillNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, usecase.ErrSkillNameExists):
+		return c.Status(fiber.StatusConflict).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+	}
+	h.logger.ErrorContext(c.Context(), "Skill operation failed", slog.Any("error", err))
+	return c.Status(fiber.StatusInternalServerError).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+}
+
+// ToSkillResponse преобразует domain.Skill в SkillResponse.
+func ToSkillResponse(skill *domain.Skill) SkillResponse {
+	return SkillResponse{ID: skill.ID, Name: skill.Name}
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"rim/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// Repository определяет интерфейс для операций с данными справочника навыков.
+type Repository interface {
+	Create(ctx context.Context, skill *domain.Skill) (*domain.Skill, error)
+	GetByID(ctx context.Context, id uint) (*domain.Skill, error)
+	GetByName(ctx context.Context, name string) (*domain.Skill, error)
+	GetAll(ctx context.Context) ([]domain.Skill, error)
+	Update(ctx context.Context, skill *domain.Skill) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр sqliteRepository для навыков.
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *sqliteRepository) Create(ctx context.Context, skill *domain.Skill) (*domain.Skill, error) {
+	if err := r.db.WithContext(ctx).Create(skill).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error creating skill in DB", slog.Any("error", err), slog.String("skillName", skill.Name))
+		return nil, err
+	}
+	r.logger.InfoContext(ctx, "Successfully created skill in DB", slog.Uint64("skillID", uint64(skill.ID)), slog.String("skillName", skill.Name))
+	return skill, nil
+}
+
+func (r *sqliteRepository) GetByID(ctx context.Context, id uint) (*domain.Skill, error) {
+	var skill domain.Skill
+	if err := r.db.WithContext(ctx).First(&skill, id).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.logger.ErrorContext(ctx, "Error getting skill by ID from DB", slog.Uint64("skillID", uint64(id)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &skill, nil
+}
+
+func (r *sqliteRepository) GetByName(ctx context.Context, name string) (*domain.Skill, error) {
+	var skill domain.Skill
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&skill).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.logger.ErrorContext(ctx, "Error getting skill by name from DB", slog.String("skillName", name), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &skill, nil
+}
+
+func (r *sqliteRepository) GetAll(ctx context.Context) ([]domain.Skill, error) {
+	var skills []domain.Skill
+	if err := r.db.WithContext(ctx).Order("name").Find(&skills).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting all skills from DB", slog.Any("error", err))
+		return nil, err
+	}
+	return skills, nil
+}
+
+func (r *sqliteRepository) Update(ctx context.Context, skill *domain.Skill) error {
+	result := r.db.WithContext(ctx).Model(skill).Update("name", skill.Name)
+	if result.Error != nil {
+		r.logger.ErrorContext(ctx, "Error updating skill in DB", slog.Uint64("skillID", uint64(skill.ID)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	r.logger.InfoContext(ctx, "Successfully updated skill in DB", slog.Uint64("skillID", uint64(skill.ID)))
+	return nil
+}
+
+// Delete удаляет навык из справочника вместе с его назначениями контактам.
+func (r *sqliteRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM contact_skills WHERE skill_id = ?", id).Error; err != nil {
+			r.logger.ErrorContext(ctx, "Error deleting skill assignments from DB", slog.Uint64("skillID", uint64(id)), slog.Any("error", err))
+			return err
+		}
+		result := tx.Delete(&domain.Skill{}, id)
+		if result.Error != nil {
+			r.logger.ErrorContext(ctx, "Error deleting skill from DB", slog.Uint64("skillID", uint64(id)), slog.Any("error", result.Error))
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		r.logger.InfoContext(ctx, "Successfully deleted skill from DB", slog.Uint64("skillID", uint64(id)))
+		return nil
+	})
+}
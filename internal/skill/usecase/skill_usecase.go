@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"rim/internal/domain"
+	"rim/internal/skill/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSkillNameEmpty  = errors.New("skill name cannot be empty")
+	ErrSkillNotFound   = errors.New("skill not found")
+	ErrSkillNameExists = errors.New("skill with this name already exists")
+)
+
+// UseCase определяет интерфейс для управления справочником навыков.
+type UseCase interface {
+	CreateSkill(ctx context.Context, name string) (*domain.Skill, error)
+	GetAllSkills(ctx context.Context) ([]domain.Skill, error)
+	UpdateSkill(ctx context.Context, id uint, newName string) (*domain.Skill, error)
+	DeleteSkill(ctx context.Context, id uint) error
+}
+
+type skillUseCase struct {
+	skillRepo repository.Repository
+	logger    *slog.Logger
+}
+
+// NewSkillUseCase создает новый экземпляр skillUseCase.
+func NewSkillUseCase(skillRepo repository.Repository, logger *slog.Logger) UseCase {
+	return &skillUseCase{
+		skillRepo: skillRepo,
+		logger:    logger,
+	}
+}
+
+// NormalizeName приводит название навыка к виду, в котором оно хранится в справочнике.
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func (uc *skillUseCase) CreateSkill(ctx context.Context, name string) (*domain.Skill, error) {
+	name = NormalizeName(name)
+	if name == "" {
+		return nil, ErrSkillNameEmpty
+	}
+	if err := uc.ensureNameFree(ctx, name, 0); err != nil {
+		return nil, err
+	}
+
+	skill, err := uc.skillRepo.Create(ctx, &domain.Skill{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Skill created successfully", slog.Uint64("id", uint64(skill.ID)), slog.String("name", skill.Name))
+	return skill, nil
+}
+
+func (uc *skillUseCase) GetAllSkills(ctx context.Context) ([]domain.Skill, error) {
+	return uc.skillRepo.GetAll(ctx)
+}
+
+func (uc *skillUseCase) UpdateSkill(ctx context.Context, id uint, newName string) (*domain.Skill, error) {
+	newName = NormalizeName(newName)
+	if newName == "" {
+		return nil, ErrSkillNameEmpty
+	}
+	skill, err := uc.skillRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSkillNotFound
+		}
+		return nil, err
+	}
+	if skill.Name == newName {
+		return skill, nil
+	}
+	if err := uc.ensureNameFree(ctx, newName, id); err != nil {
+		return nil, err
+	}
+
+	skill.Name = newName
+	if err := uc.skillRepo.Update(ctx, skill); err != nil {
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Skill updated successfully", slog.Uint64("id", uint64(id)), slog.String("name", newName))
+	return skill, nil
+}
+
+func (uc *skillUseCase) DeleteSkill(ctx context.Context, id uint) error {
+	if err := uc.skillRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSkillNotFound
+		}
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Skill deleted successfully", slog.Uint64("id", uint64(id)))
+	return nil
+}
+
+// ensureNameFree проверяет, что название не занято другим навыком.
+func (uc *skillUseCase) ensureNameFree(ctx context.Context, name string, exceptID uint) error {
+	existing, err := uc.skillRepo.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != exceptID {
+		return ErrSkillNameExists
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	systemUseCase "rim/internal/system/usecase"
+	"rim/pkg/requestctx"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// overloadRetryAfter - через сколько секунд клиенту предлагается повторить отклоненный при перегрузке запрос.
+// Запросы API выполняются доли секунды, поэтому места освобождаются быстро.
+const overloadRetryAfter = "2"
+
+// ConcurrencyLimitsResponse представляет лимиты одновременных запросов и текущую загрузку экземпляра сервера
+type ConcurrencyLimitsResponse struct {
+	Limits   map[string]int `json:"limits"`
+	Defaults map[string]int `json:"defaults"`  // Значения по умолчанию
+	InFlight map[string]int `json:"in_flight"` // Выполняемые сейчас запросы этого экземпляра по группам
+}
+
+// ConcurrencyLimitsRequest представляет запрос на изменение лимитов; не переданные группы не меняются
+type ConcurrencyLimitsRequest struct {
+	Limits map[string]int `json:"limits"`
+}
+
+// ConcurrencyLimit ограничивает число одновременно выполняемых запросов: всех запросов API (группа global)
+// и каждой группы маршрутов, которая определяется по префиксу пути, как в RateLimit. Когда мест нет, запрос
+// сразу получает 503 с Retry-After, а не ждет в очереди: SQLite выполняет записи по одной, и очередь из
+// импорта и массового чтения справочника только увеличивала бы задержку остальных запросов.
+func (h *Handler) ConcurrencyLimit(groups map[string]string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		group := routeGroup(c.Path(), groups)
+
+		if !h.inFlight.Acquire(systemUseCase.ConcurrencyGroupGlobal, h.systemUseCase.GetConcurrencyLimit(c.Context(), systemUseCase.ConcurrencyGroupGlobal)) {
+			return h.overloaded(c, systemUseCase.ConcurrencyGroupGlobal)
+		}
+		defer h.inFlight.Release(systemUseCase.ConcurrencyGroupGlobal)
+
+		if !h.inFlight.Acquire(group, h.systemUseCase.GetConcurrencyLimit(c.Context(), group)) {
+			return h.overloaded(c, group)
+		}
+		defer h.inFlight.Release(group)
+
+		return c.Next()
+	}
+}
+
+// overloaded отклоняет запрос, для которого нет места в группе group
+func (h *Handler) overloaded(c *fiber.Ctx, group string) error {
+	h.logger.WarnContext(c.Context(), "Concurrency limit reached, request shed", slog.String("group", group), slog.String("path", c.Path()))
+	c.Set(fiber.HeaderRetryAfter, overloadRetryAfter)
+	return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{
+		"error": "Server is busy, please retry later",
+	})
+}
+
+// GetConcurrencyLimits обрабатывает запрос на получение лимитов одновременных запросов
+// @Summary Получить лимиты одновременных запросов
+// @Description Возвращает лимиты global и групп маршрутов (auth, contacts, exports, imports, default)
+// @Description и число запросов, которые этот экземпляр сервера выполняет сейчас
+// @Tags system
+// @Produce json
+// @Success 200 {object} ConcurrencyLimitsResponse
+// @Failure 500 {object} map[string]string
+// @Router /system/concurrency-limits [get]
+func (h *Handler) GetConcurrencyLimits(c *fiber.Ctx) error {
+	limits, err := h.systemUseCase.GetConcurrencyLimits(c.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to get concurrency limits", slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.JSON(ConcurrencyLimitsResponse{
+		Limits:   limits,
+		Defaults: systemUseCase.DefaultConcurrencyLimits,
+		InFlight: h.inFlight.Active(),
+	})
+}
+
+// SetConcurrencyLimits обрабатывает запрос на изменение лимитов одновременных запросов
+// @Summary Установить лимиты одновременных запросов
+// @Description Меняет лимиты переданных групп без перезапуска сервера (только для администраторов).
+// @Description Лимиты действуют на каждый экземпляр сервера отдельно; 0 снимает ограничение.
+// @Tags system
+// @Accept json
+// @Produce json
+// @Param concurrency_limits body ConcurrencyLimitsRequest true "Лимиты групп маршрутов"
+// @Success 200 {object} ConcurrencyLimitsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /system/concurrency-limits [put]
+func (h *Handler) SetConcurrencyLimits(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[ConcurrencyLimitsRequest](c)
+	if !ok {
+		return err
+	}
+
+	limits, err := h.systemUseCase.SetConcurrencyLimits(c.Context(), req.Limits, requestctx.ActorID(c))
+	if err != nil {
+		if errors.Is(err, systemUseCase.ErrUnknownConcurrencyGroup) || errors.Is(err, systemUseCase.ErrInvalidConcurrencyLimit) {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to set concurrency limits", slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.JSON(ConcurrencyLimitsResponse{
+		Limits:   limits,
+		Defaults: systemUseCase.DefaultConcurrencyLimits,
+		InFlight: h.inFlight.Active(),
+	})
+}
@@ -0,0 +1,138 @@
+package delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	systemUseCase "rim/internal/system/usecase"
+	"rim/pkg/requestctx"
+	"rim/pkg/timeutil"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxCSPReportSize - отчеты больше этого размера отбрасываются без разбора
+const maxCSPReportSize = 16 << 10
+
+// cspReport - отчет о нарушении в формате report-uri (application/csp-report)
+type cspReport struct {
+	Report struct {
+		DocumentURI        string `json:"document-uri"`
+		BlockedURI         string `json:"blocked-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+		Disposition        string `json:"disposition"`
+	} `json:"csp-report"`
+}
+
+// reportingAPIReport - отчет Reporting API (application/reports+json), который отправляется по report-to
+type reportingAPIReport struct {
+	Type string `json:"type"`
+	Body struct {
+		DocumentURL        string `json:"documentURL"`
+		BlockedURL         string `json:"blockedURL"`
+		EffectiveDirective string `json:"effectiveDirective"`
+		SourceFile         string `json:"sourceFile"`
+		LineNumber         int    `json:"lineNumber"`
+		Disposition        string `json:"disposition"`
+	} `json:"body"`
+}
+
+// CSPViolationResponse представляет нарушение Content-Security-Policy
+type CSPViolationResponse struct {
+	DocumentURI        string `json:"document_uri"`
+	BlockedURI         string `json:"blocked_uri"`
+	EffectiveDirective string `json:"effective_directive"`
+	SourceFile         string `json:"source_file,omitempty"`
+	LineNumber         int    `json:"line_number,omitempty"`
+	Disposition        string `json:"disposition"` // enforce - заблокировано, report - только сообщено
+	ReceivedAt         string `json:"received_at"`
+}
+
+// ReportCSPViolation принимает отчеты браузеров о нарушениях Content-Security-Policy
+// @Summary Принять отчет о нарушении CSP
+// @Description Адрес report-uri/report-to заголовка Content-Security-Policy. Принимает application/csp-report
+// @Description и application/reports+json; некорректные отчеты молча отбрасываются.
+// @Tags system
+// @Accept json
+// @Success 204
+// @Router /csp-report [post]
+func (h *Handler) ReportCSPViolation(c *fiber.Ctx) error {
+	body := bytes.TrimSpace(c.Body())
+	if len(body) == 0 || len(body) > maxCSPReportSize {
+		return c.SendStatus(http.StatusNoContent)
+	}
+
+	now := timeutil.Now()
+	if body[0] == '[' {
+		var reports []reportingAPIReport
+		if err := json.Unmarshal(body, &reports); err != nil {
+			return c.SendStatus(http.StatusNoContent)
+		}
+		for _, report := range reports {
+			if report.Type != "csp-violation" {
+				continue
+			}
+			h.systemUseCase.RecordCSPViolation(c.Context(), systemUseCase.CSPViolation{
+				DocumentURI:        report.Body.DocumentURL,
+				BlockedURI:         report.Body.BlockedURL,
+				EffectiveDirective: report.Body.EffectiveDirective,
+				SourceFile:         report.Body.SourceFile,
+				LineNumber:         report.Body.LineNumber,
+				Disposition:        report.Body.Disposition,
+				ReceivedAt:         now,
+			})
+		}
+		return c.SendStatus(http.StatusNoContent)
+	}
+
+	var report cspReport
+	if err := json.Unmarshal(body, &report); err != nil || report.Report.DocumentURI == "" {
+		return c.SendStatus(http.StatusNoContent)
+	}
+	directive := report.Report.EffectiveDirective
+	if directive == "" {
+		directive = report.Report.ViolatedDirective
+	}
+	h.systemUseCase.RecordCSPViolation(c.Context(), systemUseCase.CSPViolation{
+		DocumentURI:        report.Report.DocumentURI,
+		BlockedURI:         report.Report.BlockedURI,
+		EffectiveDirective: directive,
+		SourceFile:         report.Report.SourceFile,
+		LineNumber:         report.Report.LineNumber,
+		Disposition:        report.Report.Disposition,
+		ReceivedAt:         now,
+	})
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// GetCSPViolations возвращает последние нарушения Content-Security-Policy
+// @Summary Получить последние нарушения CSP
+// @Description Последние нарушения, о которых сообщили браузеры этому экземпляру сервера, начиная с самого свежего.
+// @Description Все отчеты также пишутся в журнал. Помогает проверить политику в режиме report_only перед включением.
+// @Tags system
+// @Produce json
+// @Success 200 {array} CSPViolationResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /system/csp-reports [get]
+func (h *Handler) GetCSPViolations(c *fiber.Ctx) error {
+	violations := h.systemUseCase.GetCSPViolations()
+	loc := requestctx.Location(c)
+	resp := make([]CSPViolationResponse, len(violations))
+	for i, v := range violations {
+		resp[i] = CSPViolationResponse{
+			DocumentURI:        v.DocumentURI,
+			BlockedURI:         v.BlockedURI,
+			EffectiveDirective: v.EffectiveDirective,
+			SourceFile:         v.SourceFile,
+			LineNumber:         v.LineNumber,
+			Disposition:        v.Disposition,
+			ReceivedAt:         timeutil.Format(v.ReceivedAt, loc),
+		}
+	}
+	return c.JSON(resp)
+}
@@ -0,0 +1,95 @@
+package delivery
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"rim/pkg/timeutil"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// gcPausesShown - сколько последних пауз сборщика мусора возвращается в /debug/runtime
+const gcPausesShown = 10
+
+// startedAt - время запуска процесса для расчета uptime
+var startedAt = time.Now()
+
+// RuntimeStatsResponse представляет состояние рантайма Go: память, горутины и сборщик мусора
+type RuntimeStatsResponse struct {
+	GoVersion     string      `json:"go_version"`
+	GOMAXPROCS    int         `json:"gomaxprocs"`
+	UptimeSeconds float64     `json:"uptime_seconds"`
+	Goroutines    int         `json:"goroutines"`
+	Memory        MemoryStats `json:"memory"`
+	GC            GCStats     `json:"gc"`
+}
+
+// MemoryStats представляет использование памяти в байтах
+type MemoryStats struct {
+	HeapAllocBytes  uint64 `json:"heap_alloc_bytes"` // Занято живыми и еще не собранными объектами
+	HeapInuseBytes  uint64 `json:"heap_inuse_bytes"`
+	HeapIdleBytes   uint64 `json:"heap_idle_bytes"`
+	HeapObjects     uint64 `json:"heap_objects"`
+	TotalAllocBytes uint64 `json:"total_alloc_bytes"` // Выделено за все время работы
+	SysBytes        uint64 `json:"sys_bytes"`         // Получено от ОС
+	StackInuseBytes uint64 `json:"stack_inuse_bytes"`
+}
+
+// GCStats представляет работу сборщика мусора
+type GCStats struct {
+	NumGC          int64     `json:"num_gc"`
+	LastGC         string    `json:"last_gc,omitempty"`
+	PauseTotalMs   float64   `json:"pause_total_ms"`
+	RecentPausesMs []float64 `json:"recent_pauses_ms"` // Последние паузы, начиная с самой свежей
+	CPUFraction    float64   `json:"cpu_fraction"`     // Доля процессорного времени на сборку с запуска
+	NextGCBytes    uint64    `json:"next_gc_bytes"`    // Размер кучи, при котором начнется следующая сборка
+}
+
+// GetRuntimeStats возвращает состояние рантайма Go
+// @Summary Состояние рантайма
+// @Description Память, число горутин и паузы сборщика мусора процесса. Профили CPU и кучи - в /debug/pprof/, счетчики expvar - в /debug/vars.
+// @Tags system
+// @Produce json
+// @Success 200 {object} RuntimeStatsResponse
+// @Failure 403 {object} map[string]string
+// @Router /debug/runtime [get]
+func (h *Handler) GetRuntimeStats(c *fiber.Ctx) error {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	var gc debug.GCStats
+	debug.ReadGCStats(&gc)
+
+	resp := RuntimeStatsResponse{
+		GoVersion:     runtime.Version(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		UptimeSeconds: time.Since(startedAt).Round(time.Second).Seconds(),
+		Goroutines:    runtime.NumGoroutine(),
+		Memory: MemoryStats{
+			HeapAllocBytes:  mem.HeapAlloc,
+			HeapInuseBytes:  mem.HeapInuse,
+			HeapIdleBytes:   mem.HeapIdle,
+			HeapObjects:     mem.HeapObjects,
+			TotalAllocBytes: mem.TotalAlloc,
+			SysBytes:        mem.Sys,
+			StackInuseBytes: mem.StackInuse,
+		},
+		GC: GCStats{
+			NumGC:          gc.NumGC,
+			PauseTotalMs:   milliseconds(gc.PauseTotal),
+			RecentPausesMs: make([]float64, 0, gcPausesShown),
+			CPUFraction:    mem.GCCPUFraction,
+			NextGCBytes:    mem.NextGC,
+		},
+	}
+	if !gc.LastGC.IsZero() {
+		resp.GC.LastGC = timeutil.Format(gc.LastGC, time.UTC)
+	}
+	for i := 0; i < len(gc.Pause) && i < gcPausesShown; i++ {
+		resp.GC.RecentPausesMs = append(resp.GC.RecentPausesMs, milliseconds(gc.Pause[i]))
+	}
+	// Значения меняются с каждым запросом и не должны кешироваться прокси
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(resp)
+}
@@ -0,0 +1,63 @@
+package delivery
+
+import (
+	"math"
+	"time"
+
+	"rim/pkg/health"
+	"rim/pkg/timeutil"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// StatusResponse представляет состояние сервиса и его внешних зависимостей
+type StatusResponse struct {
+	Status       string             `json:"status"` // up, degraded, down или unknown
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+// DependencyStatus представляет состояние зависимости за последние 24 часа
+type DependencyStatus struct {
+	Name             string  `json:"name"`   // sqlite, redis, telegram, smtp
+	Status           string  `json:"status"` // up, down или unknown
+	LastCheckedAt    string  `json:"last_checked_at,omitempty"`
+	LatencyMs        float64 `json:"latency_ms"`         // Последней проверки
+	AvgLatencyMs24h  float64 `json:"avg_latency_ms_24h"` // Средняя по успешным проверкам
+	UptimePercent24h float64 `json:"uptime_percent_24h"`
+	Checks24h        int     `json:"checks_24h"`
+}
+
+// GetStatus возвращает состояние внешних зависимостей
+// @Summary Состояние сервиса
+// @Description Доступность и задержка Redis, SQLite, Telegram API и SMTP за последние 24 часа для виджета состояния.
+// @Description Проверяются только настроенные зависимости. Ответ публичный и не содержит текстов ошибок и адресов.
+// @Tags system
+// @Produce json
+// @Success 200 {object} StatusResponse
+// @Router /system/status [get]
+func (h *Handler) GetStatus(c *fiber.Ctx) error {
+	statuses := h.monitor.Status(c.Context(), timeutil.Now())
+	resp := StatusResponse{Status: health.Overall(statuses), Dependencies: make([]DependencyStatus, len(statuses))}
+	for i, s := range statuses {
+		dep := DependencyStatus{
+			Name:             s.Name,
+			Status:           s.Status,
+			LatencyMs:        milliseconds(s.Latency),
+			AvgLatencyMs24h:  milliseconds(s.AvgLatency),
+			UptimePercent24h: math.Round(s.Uptime*10000) / 100,
+			Checks24h:        s.Checks,
+		}
+		if s.LastCheckedAt != nil {
+			dep.LastCheckedAt = timeutil.Format(*s.LastCheckedAt, time.UTC)
+		}
+		resp.Dependencies[i] = dep
+	}
+	// Виджет опрашивает состояние часто; проверки все равно выполняются по расписанию
+	c.Set(fiber.HeaderCacheControl, "public, max-age=30")
+	return c.JSON(resp)
+}
+
+// milliseconds округляет длительность до сотых долей миллисекунды
+func milliseconds(d time.Duration) float64 {
+	return math.Round(float64(d.Microseconds())/10) / 100
+}
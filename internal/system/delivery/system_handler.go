@@ -1,11 +1,25 @@
 package delivery
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"log/slog"
 
+	"rim/internal/domain"
 	systemUseCase "rim/internal/system/usecase"
+	"rim/pkg/apperror"
+	"rim/pkg/health"
+	"rim/pkg/inflight"
+	"rim/pkg/ratelimit"
+	"rim/pkg/requestctx"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,13 +27,22 @@ import (
 // Handler обрабатывает HTTP запросы для системных настроек
 type Handler struct {
 	systemUseCase systemUseCase.UseCase
+	monitor       *health.Monitor
+	limiter       *ratelimit.Limiter
+	inFlight      *inflight.Limiter
+	sessionCookie string // Имя cookie сессии: запрос с ним не считается анонимным
 	logger        *slog.Logger
 }
 
-// NewHandler создает новый экземпляр Handler для системных настроек
-func NewHandler(systemUseCase systemUseCase.UseCase, logger *slog.Logger) *Handler {
+// NewHandler создает новый экземпляр Handler для системных настроек.
+// monitor - проверки внешних зависимостей для /system/status.
+func NewHandler(systemUseCase systemUseCase.UseCase, monitor *health.Monitor, sessionCookie string, logger *slog.Logger) *Handler {
 	return &Handler{
 		systemUseCase: systemUseCase,
+		monitor:       monitor,
+		limiter:       ratelimit.New(),
+		inFlight:      inflight.New(),
+		sessionCookie: sessionCookie,
 		logger:        logger,
 	}
 }
@@ -34,6 +57,73 @@ type DebugModeRequest struct {
 	Enabled bool `json:"enabled"`
 }
 
+// ContactFieldRulesResponse представляет допустимые значения полей контакта и ограничения длины имени
+type ContactFieldRulesResponse struct {
+	Transport     []string `json:"transport"`
+	Printer       []string `json:"printer"`
+	NameMinLength int      `json:"name_min_length"`
+	NameMaxLength int      `json:"name_max_length"`
+}
+
+// MaxSessionsResponse представляет лимит одновременных сессий пользователя
+type MaxSessionsResponse struct {
+	Limit int `json:"limit"` // 0 - без ограничений
+}
+
+// MaxSessionsRequest представляет запрос на изменение лимита одновременных сессий
+type MaxSessionsRequest struct {
+	Limit int `json:"limit"`
+}
+
+// AdminDeviceApprovalResponse представляет состояние подтверждения новых устройств администраторов
+type AdminDeviceApprovalResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// AdminDeviceApprovalRequest представляет запрос на изменение подтверждения новых устройств администраторов
+type AdminDeviceApprovalRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// ModeratedFieldGroupsResponse представляет группы полей контакта, правки которых требуют одобрения
+type ModeratedFieldGroupsResponse struct {
+	Groups    []string            `json:"groups"`
+	Available map[string][]string `json:"available"` // Все группы и входящие в них поля
+}
+
+// ModeratedFieldGroupsRequest представляет запрос на изменение модерируемых групп полей
+type ModeratedFieldGroupsRequest struct {
+	Groups []string `json:"groups"`
+}
+
+// RateLimitsResponse представляет лимиты частоты запросов групп маршрутов
+type RateLimitsResponse struct {
+	Limits   map[string]domain.RateLimit `json:"limits"`
+	Defaults map[string]domain.RateLimit `json:"defaults"` // Значения по умолчанию
+}
+
+// RateLimitsRequest представляет запрос на изменение лимитов; не переданные группы не меняются
+type RateLimitsRequest struct {
+	Limits map[string]domain.RateLimit `json:"limits"`
+}
+
+// SettingResponse описывает системную настройку в административном API
+type SettingResponse struct {
+	Key         string      `json:"key"`
+	Type        string      `json:"type"` // bool, int, string_list или rate_limits
+	Description string      `json:"description"`
+	Global      bool        `json:"global"` // Общая для всех организаций
+	Value       interface{} `json:"value"`
+	Default     interface{} `json:"default"`
+	UpdatedAt   string      `json:"updated_at,omitempty"` // RFC3339 со смещением; пусто, если настройка не менялась
+	UpdatedBy   *uint       `json:"updated_by,omitempty"`
+}
+
+// UpdateSettingRequest представляет новое значение настройки; тип value зависит от настройки
+type UpdateSettingRequest struct {
+	Value json.RawMessage `json:"value" swaggertype:"object"`
+}
+
 // GetDebugMode обрабатывает запрос на получение состояния отладочного режима
 // @Summary Получить состояние отладочного режима
 // @Description Возвращает текущее состояние отладочного режима системы
@@ -56,6 +146,23 @@ func (h *Handler) GetDebugMode(c *fiber.Ctx) error {
 	})
 }
 
+// GetContactFieldRules обрабатывает запрос правил проверки полей контакта
+// @Summary Получить правила полей контакта
+// @Description Возвращает допустимые значения transport и printer и ограничения длины имени для форм контакта и профиля. Правила меняются в настройке contact_field_rules
+// @Tags system
+// @Produce json
+// @Success 200 {object} ContactFieldRulesResponse
+// @Router /system/contact-field-rules [get]
+func (h *Handler) GetContactFieldRules(c *fiber.Ctx) error {
+	rules := h.systemUseCase.CurrentContactFieldRules(c.Context())
+	return c.JSON(ContactFieldRulesResponse{
+		Transport:     rules.Transport,
+		Printer:       rules.Printer,
+		NameMinLength: rules.NameMinLength,
+		NameMaxLength: rules.NameMaxLength,
+	})
+}
+
 // SetDebugMode обрабатывает запрос на изменение состояния отладочного режима
 // @Summary Установить состояние отладочного режима
 // @Description Изменяет состояние отладочного режима системы (только для администраторов)
@@ -70,14 +177,12 @@ func (h *Handler) GetDebugMode(c *fiber.Ctx) error {
 // @Failure 500 {object} map[string]string
 // @Router /system/debug-mode [put]
 func (h *Handler) SetDebugMode(c *fiber.Ctx) error {
-	var req DebugModeRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, ok, err := validation.BindAndValidate[DebugModeRequest](c)
+	if !ok {
+		return err
 	}
 
-	if err := h.systemUseCase.SetDebugMode(c.Context(), req.Enabled); err != nil {
+	if err := h.systemUseCase.SetDebugMode(c.Context(), req.Enabled, requestctx.ActorID(c)); err != nil {
 		h.logger.ErrorContext(c.Context(), "Failed to set debug mode", slog.Bool("enabled", req.Enabled), slog.Any("error", err))
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Internal server error",
@@ -88,3 +193,416 @@ func (h *Handler) SetDebugMode(c *fiber.Ctx) error {
 		Enabled: req.Enabled,
 	})
 }
+
+// GetMaxSessions обрабатывает запрос на получение лимита одновременных сессий пользователя
+// @Summary Получить лимит сессий
+// @Description Возвращает максимальное число одновременных сессий одного пользователя (0 - без ограничений)
+// @Tags system
+// @Produce json
+// @Success 200 {object} MaxSessionsResponse
+// @Failure 500 {object} map[string]string
+// @Router /system/max-sessions [get]
+func (h *Handler) GetMaxSessions(c *fiber.Ctx) error {
+	limit, err := h.systemUseCase.GetMaxSessionsPerUser(c.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to get max sessions per user", slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.JSON(MaxSessionsResponse{
+		Limit: limit,
+	})
+}
+
+// SetMaxSessions обрабатывает запрос на изменение лимита одновременных сессий пользователя
+// @Summary Установить лимит сессий
+// @Description Изменяет максимальное число одновременных сессий одного пользователя (только для администраторов).
+// @Description При превышении лимита при входе завершается самая старая сессия.
+// @Tags system
+// @Accept json
+// @Produce json
+// @Param max_sessions body MaxSessionsRequest true "Новый лимит (0 - без ограничений)"
+// @Success 200 {object} MaxSessionsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /system/max-sessions [put]
+func (h *Handler) SetMaxSessions(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[MaxSessionsRequest](c)
+	if !ok {
+		return err
+	}
+
+	if err := h.systemUseCase.SetMaxSessionsPerUser(c.Context(), req.Limit, requestctx.ActorID(c)); err != nil {
+		if err == systemUseCase.ErrInvalidSessionsLimit {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to set max sessions per user", slog.Int("limit", req.Limit), slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.JSON(MaxSessionsResponse{
+		Limit: req.Limit,
+	})
+}
+
+// GetAdminDeviceApproval обрабатывает запрос на получение настройки подтверждения устройств администраторов
+// @Summary Получить настройку подтверждения устройств
+// @Description Возвращает, требуется ли подтверждение входа администраторов с новых устройств
+// @Tags system
+// @Produce json
+// @Success 200 {object} AdminDeviceApprovalResponse
+// @Failure 500 {object} map[string]string
+// @Router /system/admin-device-approval [get]
+func (h *Handler) GetAdminDeviceApproval(c *fiber.Ctx) error {
+	enabled, err := h.systemUseCase.GetAdminDeviceApproval(c.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to get admin device approval", slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.JSON(AdminDeviceApprovalResponse{
+		Enabled: enabled,
+	})
+}
+
+// SetAdminDeviceApproval обрабатывает запрос на изменение настройки подтверждения устройств администраторов
+// @Summary Установить настройку подтверждения устройств
+// @Description Включает или выключает подтверждение входа администраторов с новых устройств (только для администраторов).
+// @Description Новое устройство подтверждается в /auth/devices с уже доверенного устройства, после чего вход повторяется.
+// @Tags system
+// @Accept json
+// @Produce json
+// @Param admin_device_approval body AdminDeviceApprovalRequest true "Новое состояние"
+// @Success 200 {object} AdminDeviceApprovalResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /system/admin-device-approval [put]
+func (h *Handler) SetAdminDeviceApproval(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[AdminDeviceApprovalRequest](c)
+	if !ok {
+		return err
+	}
+
+	if err := h.systemUseCase.SetAdminDeviceApproval(c.Context(), req.Enabled, requestctx.ActorID(c)); err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to set admin device approval", slog.Bool("enabled", req.Enabled), slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.JSON(AdminDeviceApprovalResponse{
+		Enabled: req.Enabled,
+	})
+}
+
+// GetModeratedFieldGroups обрабатывает запрос на получение модерируемых групп полей контакта
+// @Summary Получить модерируемые группы полей
+// @Description Возвращает группы полей контакта, самостоятельные правки которых создают заявку на одобрение
+// @Tags system
+// @Produce json
+// @Success 200 {object} ModeratedFieldGroupsResponse
+// @Failure 500 {object} map[string]string
+// @Router /system/moderated-field-groups [get]
+func (h *Handler) GetModeratedFieldGroups(c *fiber.Ctx) error {
+	groups, err := h.systemUseCase.GetModeratedFieldGroups(c.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to get moderated field groups", slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.JSON(ModeratedFieldGroupsResponse{
+		Groups:    groups,
+		Available: domain.ContactFieldGroups,
+	})
+}
+
+// SetModeratedFieldGroups обрабатывает запрос на изменение модерируемых групп полей контакта
+// @Summary Установить модерируемые группы полей
+// @Description Задает группы полей (profile, contacts, logistics, health), правки которых пользователем требуют одобрения администратора.
+// @Description Пустой список отключает модерацию.
+// @Tags system
+// @Accept json
+// @Produce json
+// @Param moderated_field_groups body ModeratedFieldGroupsRequest true "Группы полей"
+// @Success 200 {object} ModeratedFieldGroupsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /system/moderated-field-groups [put]
+func (h *Handler) SetModeratedFieldGroups(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[ModeratedFieldGroupsRequest](c)
+	if !ok {
+		return err
+	}
+
+	if err := h.systemUseCase.SetModeratedFieldGroups(c.Context(), req.Groups, requestctx.ActorID(c)); err != nil {
+		if errors.Is(err, systemUseCase.ErrUnknownFieldGroup) {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to set moderated field groups", slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return h.GetModeratedFieldGroups(c)
+}
+
+// GetRateLimits обрабатывает запрос на получение лимитов частоты запросов
+// @Summary Получить лимиты частоты запросов
+// @Description Возвращает лимиты групп маршрутов (auth, contacts, exports, imports, default) для одного IP-адреса,
+// @Description а также отдельные лимиты анонимного справочника: anonymous_directory и suspected_bots для запросов, похожих на ботов
+// @Tags system
+// @Produce json
+// @Success 200 {object} RateLimitsResponse
+// @Failure 500 {object} map[string]string
+// @Router /system/rate-limits [get]
+func (h *Handler) GetRateLimits(c *fiber.Ctx) error {
+	limits, err := h.systemUseCase.GetRateLimits(c.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to get rate limits", slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.JSON(RateLimitsResponse{
+		Limits:   limits,
+		Defaults: systemUseCase.DefaultRateLimits,
+	})
+}
+
+// SetRateLimits обрабатывает запрос на изменение лимитов частоты запросов
+// @Summary Установить лимиты частоты запросов
+// @Description Меняет лимиты переданных групп маршрутов без перезапуска сервера (только для администраторов).
+// @Description requests_per_minute = 0 снимает ограничение, burst - сколько запросов подряд допускается без пауз.
+// @Tags system
+// @Accept json
+// @Produce json
+// @Param rate_limits body RateLimitsRequest true "Лимиты групп маршрутов"
+// @Success 200 {object} RateLimitsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /system/rate-limits [put]
+func (h *Handler) SetRateLimits(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[RateLimitsRequest](c)
+	if !ok {
+		return err
+	}
+
+	limits, err := h.systemUseCase.SetRateLimits(c.Context(), req.Limits, requestctx.ActorID(c))
+	if err != nil {
+		if errors.Is(err, systemUseCase.ErrUnknownRateLimitGroup) || errors.Is(err, systemUseCase.ErrInvalidRateLimit) {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to set rate limits", slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.JSON(RateLimitsResponse{
+		Limits:   limits,
+		Defaults: systemUseCase.DefaultRateLimits,
+	})
+}
+
+// RateLimit ограничивает частоту запросов с одного IP-адреса. Группа маршрута определяется
+// по самому длинному префиксу пути из groups, остальные маршруты относятся к группе default.
+// Лимиты читаются из системных настроек, поэтому их изменение применяется без перезапуска.
+func (h *Handler) RateLimit(groups map[string]string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		group := routeGroup(c.Path(), groups)
+
+		// Анонимные запросы справочника ограничивает AnonymousDirectoryRateLimit, чтобы они не расходовали
+		// лимит пользователей с того же адреса
+		if group == systemUseCase.RateLimitGroupContacts && h.isAnonymousDirectoryRequest(c) {
+			return c.Next()
+		}
+
+		limit := h.systemUseCase.GetRateLimit(c.Context(), group)
+		allowed, retryAfter := h.limiter.Allow(group+":"+c.IP(), limit.RequestsPerMinute, limit.Burst)
+		if !allowed {
+			h.logger.WarnContext(c.Context(), "Rate limit exceeded", slog.String("group", group), slog.String("ip", c.IP()))
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			return c.Status(http.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "Too many requests",
+			})
+		}
+		return c.Next()
+	}
+}
+
+// routeGroup возвращает группу маршрута с самым длинным префиксом пути из groups или группу default
+func routeGroup(path string, groups map[string]string) string {
+	group, matched := systemUseCase.RateLimitGroupDefault, 0
+	for prefix, g := range groups {
+		if len(prefix) > matched && strings.HasPrefix(path, prefix) {
+			group, matched = g, len(prefix)
+		}
+	}
+	return group
+}
+
+// AnonymousDirectoryRateLimit ограничивает анонимные запросы справочника (GET /contacts без входа) отдельно
+// от запросов пользователей: группа anonymous_directory, а для запросов, похожих на ботов, - более строгая suspected_bots.
+// Должен стоять после middleware авторизации. Если лимит исчерпан, запрос без параметров получает throttled -
+// закэшированный список имен, остальные - 429.
+func (h *Handler) AnonymousDirectoryRateLimit(throttled fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if user, ok := c.Locals("user").(*domain.User); ok && user != nil {
+			return c.Next()
+		}
+
+		group := systemUseCase.RateLimitGroupAnonymousDirectory
+		if isSuspectedBot(c) {
+			group = systemUseCase.RateLimitGroupSuspectedBots
+		}
+		limit := h.systemUseCase.GetRateLimit(c.Context(), group)
+		allowed, retryAfter := h.limiter.Allow(group+":"+c.IP(), limit.RequestsPerMinute, limit.Burst)
+		if allowed {
+			return c.Next()
+		}
+
+		h.logger.WarnContext(c.Context(), "Rate limit exceeded", slog.String("group", group), slog.String("ip", c.IP()), slog.String("user_agent", c.Get(fiber.HeaderUserAgent)))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		if throttled != nil && len(c.Request().URI().QueryString()) == 0 {
+			return throttled(c)
+		}
+		return c.Status(http.StatusTooManyRequests).JSON(fiber.Map{
+			"error": "Too many requests",
+		})
+	}
+}
+
+// isAnonymousDirectoryRequest определяет запрос списка контактов без учетных данных.
+// Поддельные учетные данные не помогают обойти анонимный лимит: AnonymousDirectoryRateLimit проверяет итог авторизации.
+func (h *Handler) isAnonymousDirectoryRequest(c *fiber.Ctx) bool {
+	path := strings.TrimSuffix(c.Path(), "/")
+	return c.Method() == fiber.MethodGet && path == "/api/v1/contacts" &&
+		c.Cookies(h.sessionCookie) == "" && c.Get(fiber.HeaderAuthorization) == "" && c.Get("X-API-Key") == ""
+}
+
+// botUserAgentMarkers - фрагменты User-Agent поисковых роботов, HTTP-библиотек и автоматизированных браузеров
+var botUserAgentMarkers = []string{
+	"bot", "crawl", "spider", "slurp", "scrapy", "curl", "wget", "python", "go-http-client", "java/", "okhttp",
+	"httpclient", "libwww", "axios", "node-fetch", "headless", "phantomjs", "selenium", "puppeteer",
+}
+
+// isSuspectedBot оценивает запрос по заголовкам: браузеры передают User-Agent вида "Mozilla/5.0 ..." и Accept-Language,
+// а скрипты и роботы обычно нет или называют себя сами
+func isSuspectedBot(c *fiber.Ctx) bool {
+	ua := strings.ToLower(c.Get(fiber.HeaderUserAgent))
+	if !strings.HasPrefix(ua, "mozilla/") || c.Get(fiber.HeaderAcceptLanguage) == "" {
+		return true
+	}
+	for _, marker := range botUserAgentMarkers {
+		if strings.Contains(ua, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSettings обрабатывает запрос на получение всех системных настроек
+// @Summary Получить системные настройки
+// @Description Возвращает все настройки времени выполнения с типами, значениями по умолчанию,
+// @Description временем и автором последнего изменения (только для администраторов)
+// @Tags system
+// @Produce json
+// @Success 200 {array} SettingResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /system/settings [get]
+func (h *Handler) GetSettings(c *fiber.Ctx) error {
+	settings, err := h.systemUseCase.ListSettings(c.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Context(), "Failed to list system settings", slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	loc := requestctx.Location(c)
+	resp := make([]SettingResponse, len(settings))
+	for i := range settings {
+		resp[i] = toSettingResponse(&settings[i], loc)
+	}
+	return c.JSON(resp)
+}
+
+// UpdateSetting обрабатывает запрос на изменение системной настройки
+// @Summary Изменить системную настройку
+// @Description Меняет настройку по ключу (только для администраторов). Тип value зависит от настройки:
+// @Description bool, int, список строк или объект лимитов. Общие настройки меняются только в организации по умолчанию.
+// @Tags system
+// @Accept json
+// @Produce json
+// @Param key path string true "Ключ настройки"
+// @Param setting body UpdateSettingRequest true "Новое значение"
+// @Success 200 {object} SettingResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /system/settings/{key} [put]
+func (h *Handler) UpdateSetting(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[UpdateSettingRequest](c)
+	if !ok {
+		return err
+	}
+
+	key := c.Params("key")
+	setting, err := h.systemUseCase.UpdateSetting(c.Context(), key, req.Value, requestctx.ActorID(c))
+	if err != nil {
+		// Ошибки проверки значения - доменные ошибки apperror: статус определяется их видом
+		if status := apperror.HTTPStatus(err); status != http.StatusInternalServerError {
+			return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to update system setting", slog.String("key", key), slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.JSON(toSettingResponse(setting, requestctx.Location(c)))
+}
+
+func toSettingResponse(setting *systemUseCase.SettingInfo, loc *time.Location) SettingResponse {
+	resp := SettingResponse{
+		Key:         setting.Key,
+		Type:        setting.Type,
+		Description: setting.Description,
+		Global:      setting.Global,
+		Value:       setting.Value,
+		Default:     setting.Default,
+		UpdatedBy:   setting.UpdatedBy,
+	}
+	if setting.UpdatedAt != nil {
+		resp.UpdatedAt = timeutil.Format(*setting.UpdatedAt, loc)
+	}
+	return resp
+}
@@ -5,14 +5,17 @@ import (
 	"log/slog"
 
 	"rim/internal/domain"
+	"rim/pkg/tenant"
 
 	"gorm.io/gorm"
 )
 
-// Repository определяет интерфейс для операций с системными настройками
+// Repository определяет интерфейс для операций с системными настройками.
+// Настройки относятся к организации из контекста (pkg/tenant), без нее - к организации по умолчанию.
 type Repository interface {
 	GetSetting(ctx context.Context, key string) (*domain.SystemSetting, error)
-	SetSetting(ctx context.Context, key, value string) error
+	// SetSetting сохраняет значение настройки; updatedBy - пользователь, изменивший ее (nil - система)
+	SetSetting(ctx context.Context, key, value string, updatedBy *uint) error
 }
 
 type sqliteRepository struct {
@@ -30,7 +33,7 @@ func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
 
 func (r *sqliteRepository) GetSetting(ctx context.Context, key string) (*domain.SystemSetting, error) {
 	var setting domain.SystemSetting
-	if err := r.db.WithContext(ctx).Where("key = ?", key).First(&setting).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("organization_id = ? AND key = ?", settingOrganization(ctx), key).First(&setting).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.InfoContext(ctx, "System setting not found", slog.String("key", key))
 			return nil, err
@@ -41,16 +44,20 @@ func (r *sqliteRepository) GetSetting(ctx context.Context, key string) (*domain.
 	return &setting, nil
 }
 
-func (r *sqliteRepository) SetSetting(ctx context.Context, key, value string) error {
+func (r *sqliteRepository) SetSetting(ctx context.Context, key, value string, updatedBy *uint) error {
+	orgID := settingOrganization(ctx)
 	setting := &domain.SystemSetting{
-		Key:   key,
-		Value: value,
+		OrganizationID: orgID,
+		Key:            key,
+		Value:          value,
+		UpdatedBy:      updatedBy,
 	}
 
 	// Используем OnConflict для обновления существующего значения
 	if err := r.db.WithContext(ctx).
-		Where("key = ?", key).
-		Assign(domain.SystemSetting{Value: value}).
+		Where("organization_id = ? AND key = ?", orgID, key).
+		// Карта, а не структура: иначе nil в updated_by не перезаписал бы прежнего автора
+		Assign(map[string]interface{}{"value": value, "updated_by": updatedBy}).
 		FirstOrCreate(setting).Error; err != nil {
 		r.logger.ErrorContext(ctx, "Error setting system setting", slog.String("key", key), slog.String("value", value), slog.Any("error", err))
 		return err
@@ -59,3 +66,11 @@ func (r *sqliteRepository) SetSetting(ctx context.Context, key, value string) er
 	r.logger.InfoContext(ctx, "Successfully set system setting", slog.String("key", key), slog.String("value", value))
 	return nil
 }
+
+// settingOrganization возвращает организацию, к которой относятся настройки запроса.
+func settingOrganization(ctx context.Context) uint {
+	if orgID := tenant.FromContext(ctx); orgID != 0 {
+		return orgID
+	}
+	return domain.DefaultOrganizationID
+}
@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/tenant"
+
+	"gorm.io/gorm"
+)
+
+// ConcurrencyLimitsKey - лимиты одновременно выполняемых запросов в JSON {группа: число}; хранится в организации по умолчанию
+const ConcurrencyLimitsKey = "concurrency_limits"
+
+// ConcurrencyGroupGlobal - все запросы API экземпляра сервера; остальные группы совпадают с группами лимитов частоты
+const ConcurrencyGroupGlobal = "global"
+
+// concurrencyLimitsCacheTTL - как долго лимиты читаются из памяти; проверяются на каждый запрос API
+const concurrencyLimitsCacheTTL = 10 * time.Second
+
+// DefaultConcurrencyLimits используются для групп, не заданных в настройке concurrency_limits; 0 - без ограничений.
+// Импорт и выгрузки держат транзакции SQLite дольше остальных запросов, поэтому ограничены сильнее.
+var DefaultConcurrencyLimits = map[string]int{
+	ConcurrencyGroupGlobal: 256,
+	RateLimitGroupAuth:     32,
+	RateLimitGroupContacts: 64,
+	RateLimitGroupExports:  4,
+	RateLimitGroupImports:  2,
+	RateLimitGroupDefault:  0,
+}
+
+var (
+	ErrUnknownConcurrencyGroup = apperror.Invalid("unknown_concurrency_group", "unknown concurrency limit route group")
+	ErrInvalidConcurrencyLimit = apperror.Invalid("invalid_concurrency_limit", "concurrency limit cannot be negative")
+)
+
+func (uc *systemUseCase) GetConcurrencyLimits(ctx context.Context) (map[string]int, error) {
+	stored, err := uc.storedConcurrencyLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make(map[string]int, len(DefaultConcurrencyLimits))
+	for group, limit := range DefaultConcurrencyLimits {
+		limits[group] = limit
+	}
+	for group, limit := range stored {
+		if _, ok := limits[group]; ok {
+			limits[group] = limit
+		}
+	}
+	return limits, nil
+}
+
+func (uc *systemUseCase) SetConcurrencyLimits(ctx context.Context, limits map[string]int, updatedBy *uint) (map[string]int, error) {
+	for group, limit := range limits {
+		if _, ok := DefaultConcurrencyLimits[group]; !ok {
+			return nil, ErrUnknownConcurrencyGroup
+		}
+		if limit < 0 {
+			return nil, ErrInvalidConcurrencyLimit
+		}
+	}
+
+	stored, err := uc.storedConcurrencyLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for group, limit := range limits {
+		stored[group] = limit
+	}
+
+	value, err := json.Marshal(stored)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.systemRepo.SetSetting(tenant.With(ctx, domain.DefaultOrganizationID), ConcurrencyLimitsKey, string(value), updatedBy); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to set concurrency limits setting", slog.String("limits", string(value)), slog.Any("error", err))
+		return nil, err
+	}
+
+	current, err := uc.GetConcurrencyLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.concurrencyLimitsMu.Lock()
+	uc.concurrencyLimits = current
+	uc.concurrencyLimitsLoadedAt = time.Now()
+	uc.concurrencyLimitsMu.Unlock()
+
+	uc.logger.InfoContext(ctx, "Concurrency limits setting updated", slog.String("limits", string(value)))
+	return current, nil
+}
+
+// storedConcurrencyLimits читает лимиты, заданные в настройке concurrency_limits
+func (uc *systemUseCase) storedConcurrencyLimits(ctx context.Context) (map[string]int, error) {
+	stored := map[string]int{}
+	setting, err := uc.systemRepo.GetSetting(tenant.With(ctx, domain.DefaultOrganizationID), ConcurrencyLimitsKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return stored, nil
+		}
+		uc.logger.ErrorContext(ctx, "Failed to get concurrency limits setting", slog.Any("error", err))
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(setting.Value), &stored); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to parse concurrency limits value", slog.String("value", setting.Value), slog.Any("error", err))
+		return nil, err
+	}
+	return stored, nil
+}
+
+func (uc *systemUseCase) GetConcurrencyLimit(ctx context.Context, group string) int {
+	uc.concurrencyLimitsMu.Lock()
+	defer uc.concurrencyLimitsMu.Unlock()
+
+	if uc.concurrencyLimits == nil || time.Since(uc.concurrencyLimitsLoadedAt) > concurrencyLimitsCacheTTL {
+		limits, err := uc.GetConcurrencyLimits(ctx)
+		if err != nil {
+			// Перегруженная БД - как раз тот случай, когда лимиты нужны: продолжаем с прежними
+			limits = uc.concurrencyLimits
+			if limits == nil {
+				limits = DefaultConcurrencyLimits
+			}
+		}
+		uc.concurrencyLimits = limits
+		uc.concurrencyLimitsLoadedAt = time.Now()
+	}
+
+	if limit, ok := uc.concurrencyLimits[group]; ok {
+		return limit
+	}
+	return uc.concurrencyLimits[RateLimitGroupDefault]
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/tenant"
+	"rim/pkg/validation"
+
+	"gorm.io/gorm"
+)
+
+// ContactFieldRulesKey - правила проверки полей контакта в JSON; хранится в организации по умолчанию
+const ContactFieldRulesKey = "contact_field_rules"
+
+// Имена правил в тегах validate: enum=transport, enum=printer, length=contact_name
+const (
+	EnumTransport     = "transport"
+	EnumPrinter       = "printer"
+	LengthContactName = "contact_name"
+)
+
+const (
+	// maxContactFieldOptions - максимум допустимых значений одного поля
+	maxContactFieldOptions = 50
+	// maxContactFieldOptionLength - максимальная длина допустимого значения в символах
+	maxContactFieldOptionLength = 100
+	// maxContactNameLength - предел длины имени, который можно задать в правилах
+	maxContactNameLength = 255
+	// contactFieldRulesCacheTTL - как долго правила читаются из памяти, а не из БД
+	contactFieldRulesCacheTTL = 10 * time.Second
+)
+
+// DefaultContactFieldRules действуют, пока настройка contact_field_rules не задана
+var DefaultContactFieldRules = domain.ContactFieldRules{
+	Transport:     []string{"есть машина", "есть права", "нет ничего"},
+	Printer:       []string{domain.PrinterColor, domain.PrinterPlain, domain.PrinterNone},
+	NameMinLength: 2,
+	NameMaxLength: 100,
+}
+
+var ErrInvalidContactFieldRules = apperror.Invalid("invalid_contact_field_rules", "invalid contact field rules")
+
+func (uc *systemUseCase) GetContactFieldRules(ctx context.Context) (domain.ContactFieldRules, error) {
+	setting, err := uc.systemRepo.GetSetting(tenant.With(ctx, domain.DefaultOrganizationID), ContactFieldRulesKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return cloneContactFieldRules(DefaultContactFieldRules), nil
+		}
+		uc.logger.ErrorContext(ctx, "Failed to get contact field rules setting", slog.Any("error", err))
+		return domain.ContactFieldRules{}, err
+	}
+	var rules domain.ContactFieldRules
+	if err := json.Unmarshal([]byte(setting.Value), &rules); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to parse contact field rules value", slog.String("value", setting.Value), slog.Any("error", err))
+		return domain.ContactFieldRules{}, err
+	}
+	return rules, nil
+}
+
+func (uc *systemUseCase) SetContactFieldRules(ctx context.Context, rules domain.ContactFieldRules, updatedBy *uint) error {
+	var err error
+	if rules.Transport, err = normalizeFieldOptions(rules.Transport); err != nil {
+		return err
+	}
+	if rules.Printer, err = normalizeFieldOptions(rules.Printer); err != nil {
+		return err
+	}
+	// По этим значениям заявки на печать направляются владельцам принтеров, поэтому их нельзя убрать
+	for _, printer := range []string{domain.PrinterColor, domain.PrinterPlain, domain.PrinterNone} {
+		if !slices.Contains(rules.Printer, printer) {
+			return fmt.Errorf("%w: printer options must include %s", ErrInvalidContactFieldRules, printer)
+		}
+	}
+	if rules.NameMinLength < 1 || rules.NameMaxLength < rules.NameMinLength || rules.NameMaxLength > maxContactNameLength {
+		return fmt.Errorf("%w: name lengths must satisfy 1 <= name_min_length <= name_max_length <= 255", ErrInvalidContactFieldRules)
+	}
+
+	value, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	if err := uc.systemRepo.SetSetting(tenant.With(ctx, domain.DefaultOrganizationID), ContactFieldRulesKey, string(value), updatedBy); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to set contact field rules setting", slog.String("rules", string(value)), slog.Any("error", err))
+		return err
+	}
+
+	uc.contactFieldRulesMu.Lock()
+	uc.contactFieldRules = &rules
+	uc.contactFieldRulesLoadedAt = time.Now()
+	uc.contactFieldRulesMu.Unlock()
+
+	uc.logger.InfoContext(ctx, "Contact field rules setting updated", slog.String("rules", string(value)))
+	return nil
+}
+
+func (uc *systemUseCase) CurrentContactFieldRules(ctx context.Context) domain.ContactFieldRules {
+	uc.contactFieldRulesMu.Lock()
+	defer uc.contactFieldRulesMu.Unlock()
+
+	if uc.contactFieldRules == nil || time.Since(uc.contactFieldRulesLoadedAt) > contactFieldRulesCacheTTL {
+		rules, err := uc.GetContactFieldRules(ctx)
+		if err != nil {
+			// При недоступной БД продолжаем с прежними правилами или правилами по умолчанию
+			rules = cloneContactFieldRules(DefaultContactFieldRules)
+			if uc.contactFieldRules != nil {
+				rules = *uc.contactFieldRules
+			}
+		}
+		uc.contactFieldRules = &rules
+		uc.contactFieldRulesLoadedAt = time.Now()
+	}
+	return *uc.contactFieldRules
+}
+
+// normalizeFieldOptions убирает пробелы по краям значений и проверяет, что список не пуст и без повторов
+func normalizeFieldOptions(options []string) ([]string, error) {
+	if len(options) == 0 || len(options) > maxContactFieldOptions {
+		return nil, fmt.Errorf("%w: each field must have from 1 to 50 options", ErrInvalidContactFieldRules)
+	}
+	normalized := make([]string, 0, len(options))
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" || utf8.RuneCountInString(option) > maxContactFieldOptionLength {
+			return nil, fmt.Errorf("%w: options must be non-empty and at most 100 characters long", ErrInvalidContactFieldRules)
+		}
+		if slices.Contains(normalized, option) {
+			return nil, fmt.Errorf("%w: duplicate option %s", ErrInvalidContactFieldRules, option)
+		}
+		normalized = append(normalized, option)
+	}
+	return normalized, nil
+}
+
+func cloneContactFieldRules(rules domain.ContactFieldRules) domain.ContactFieldRules {
+	rules.Transport = slices.Clone(rules.Transport)
+	rules.Printer = slices.Clone(rules.Printer)
+	return rules
+}
+
+// validationRules передает правила полей контакта в pkg/validation для тегов enum и length
+type validationRules struct {
+	uc UseCase
+}
+
+// NewValidationRules создает источник правил для validation.SetRules из настройки contact_field_rules
+func NewValidationRules(uc UseCase) validation.Rules {
+	return validationRules{uc: uc}
+}
+
+func (r validationRules) Enum(name string) []string {
+	rules := r.uc.CurrentContactFieldRules(context.Background())
+	switch name {
+	case EnumTransport:
+		return rules.Transport
+	case EnumPrinter:
+		return rules.Printer
+	}
+	return nil
+}
+
+func (r validationRules) Length(name string) (int, int, bool) {
+	if name != LengthContactName {
+		return 0, 0, false
+	}
+	rules := r.uc.CurrentContactFieldRules(context.Background())
+	return rules.NameMinLength, rules.NameMaxLength, true
+}
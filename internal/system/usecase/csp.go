@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/tenant"
+
+	"gorm.io/gorm"
+)
+
+// ContentSecurityPolicyKey - источники Content-Security-Policy в JSON; хранится в организации по умолчанию
+const ContentSecurityPolicyKey = "content_security_policy"
+
+const (
+	// cspCacheTTL - как долго политика читается из памяти, а не из БД
+	cspCacheTTL = 10 * time.Second
+	// maxCSPSources - максимум источников в одной директиве
+	maxCSPSources = 50
+	// cspViolationsKept - сколько последних нарушений хранится для /system/csp-reports
+	cspViolationsKept = 100
+)
+
+// DefaultContentSecurityPolicy используется, пока настройка content_security_policy не задана
+var DefaultContentSecurityPolicy = domain.ContentSecurityPolicy{
+	ScriptSrc:  []string{"'self'", "'unsafe-inline'", "'unsafe-eval'", "https://telegram.org"},
+	ConnectSrc: []string{"'self'"},
+	ImgSrc:     []string{"'self'", "data:", "https:"},
+}
+
+var ErrInvalidCSPSource = apperror.Invalid("invalid_csp_source", "invalid content security policy source")
+
+// CSPViolation - нарушение Content-Security-Policy, о котором сообщил браузер
+type CSPViolation struct {
+	DocumentURI        string
+	BlockedURI         string
+	EffectiveDirective string
+	SourceFile         string
+	LineNumber         int
+	Disposition        string // enforce или report
+	ReceivedAt         time.Time
+}
+
+func (uc *systemUseCase) GetContentSecurityPolicy(ctx context.Context) (domain.ContentSecurityPolicy, error) {
+	setting, err := uc.systemRepo.GetSetting(tenant.With(ctx, domain.DefaultOrganizationID), ContentSecurityPolicyKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return DefaultContentSecurityPolicy, nil
+		}
+		uc.logger.ErrorContext(ctx, "Failed to get content security policy setting", slog.Any("error", err))
+		return domain.ContentSecurityPolicy{}, err
+	}
+	var policy domain.ContentSecurityPolicy
+	if err := json.Unmarshal([]byte(setting.Value), &policy); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to parse content security policy value", slog.String("value", setting.Value), slog.Any("error", err))
+		return domain.ContentSecurityPolicy{}, err
+	}
+	return policy, nil
+}
+
+func (uc *systemUseCase) SetContentSecurityPolicy(ctx context.Context, policy domain.ContentSecurityPolicy, updatedBy *uint) error {
+	for _, sources := range [][]string{policy.ScriptSrc, policy.ConnectSrc, policy.ImgSrc} {
+		if err := validateCSPSources(sources); err != nil {
+			return err
+		}
+	}
+	value, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	if err := uc.systemRepo.SetSetting(tenant.With(ctx, domain.DefaultOrganizationID), ContentSecurityPolicyKey, string(value), updatedBy); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to set content security policy setting", slog.String("policy", string(value)), slog.Any("error", err))
+		return err
+	}
+
+	uc.cspMu.Lock()
+	uc.csp = &policy
+	uc.cspLoadedAt = time.Now()
+	uc.cspMu.Unlock()
+
+	uc.logger.InfoContext(ctx, "Content security policy setting updated", slog.String("policy", string(value)))
+	return nil
+}
+
+func (uc *systemUseCase) CurrentContentSecurityPolicy(ctx context.Context) domain.ContentSecurityPolicy {
+	uc.cspMu.Lock()
+	defer uc.cspMu.Unlock()
+
+	if uc.csp == nil || time.Since(uc.cspLoadedAt) > cspCacheTTL {
+		policy, err := uc.GetContentSecurityPolicy(ctx)
+		if err != nil {
+			// При недоступной БД продолжаем с прежней политикой или политикой по умолчанию
+			policy = DefaultContentSecurityPolicy
+			if uc.csp != nil {
+				policy = *uc.csp
+			}
+		}
+		uc.csp = &policy
+		uc.cspLoadedAt = time.Now()
+	}
+	return *uc.csp
+}
+
+func (uc *systemUseCase) RecordCSPViolation(ctx context.Context, violation CSPViolation) {
+	uc.logger.WarnContext(ctx, "Content security policy violation",
+		slog.String("document_uri", violation.DocumentURI),
+		slog.String("blocked_uri", violation.BlockedURI),
+		slog.String("directive", violation.EffectiveDirective),
+		slog.String("source_file", violation.SourceFile),
+		slog.Int("line", violation.LineNumber),
+		slog.String("disposition", violation.Disposition))
+
+	uc.cspMu.Lock()
+	defer uc.cspMu.Unlock()
+	uc.cspViolations = append(uc.cspViolations, violation)
+	if len(uc.cspViolations) > cspViolationsKept {
+		uc.cspViolations = uc.cspViolations[len(uc.cspViolations)-cspViolationsKept:]
+	}
+}
+
+func (uc *systemUseCase) GetCSPViolations() []CSPViolation {
+	uc.cspMu.Lock()
+	defer uc.cspMu.Unlock()
+
+	violations := make([]CSPViolation, len(uc.cspViolations))
+	for i, violation := range uc.cspViolations {
+		violations[len(violations)-1-i] = violation
+	}
+	return violations
+}
+
+// validateCSPSources проверяет, что источники можно подставить в заголовок без изменения его структуры
+func validateCSPSources(sources []string) error {
+	if len(sources) > maxCSPSources {
+		return ErrInvalidCSPSource
+	}
+	for _, source := range sources {
+		if source == "" || strings.ContainsAny(source, " ;,\t\r\n") {
+			return ErrInvalidCSPSource
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/tenant"
+
+	"gorm.io/gorm"
+)
+
+// SecurityHeadersKey - переопределения заголовков безопасности в JSON; хранится в организации по умолчанию
+const SecurityHeadersKey = "security_headers"
+
+const (
+	// maxHSTSMaxAgeSeconds - два года, больший срок браузеры не требуют даже для preload-списка
+	maxHSTSMaxAgeSeconds = 2 * 365 * 24 * 60 * 60
+	// maxPermissionsPolicyEntries - максимум записей Permissions-Policy
+	maxPermissionsPolicyEntries = 50
+)
+
+// permissionsPolicyEntry - запись Permissions-Policy: функция и список источников в скобках или *
+var permissionsPolicyEntry = regexp.MustCompile(`^[a-z][a-z0-9-]*=(\*|\([^,;\r\n]*\))$`)
+
+var ErrInvalidSecurityHeader = apperror.Invalid("invalid_security_header", "invalid security header value")
+
+func (uc *systemUseCase) GetSecurityHeaders(ctx context.Context) (domain.SecurityHeaders, error) {
+	setting, err := uc.systemRepo.GetSetting(tenant.With(ctx, domain.DefaultOrganizationID), SecurityHeadersKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.SecurityHeaders{}, nil
+		}
+		uc.logger.ErrorContext(ctx, "Failed to get security headers setting", slog.Any("error", err))
+		return domain.SecurityHeaders{}, err
+	}
+	var headers domain.SecurityHeaders
+	if err := json.Unmarshal([]byte(setting.Value), &headers); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to parse security headers value", slog.String("value", setting.Value), slog.Any("error", err))
+		return domain.SecurityHeaders{}, err
+	}
+	return headers, nil
+}
+
+func (uc *systemUseCase) SetSecurityHeaders(ctx context.Context, headers domain.SecurityHeaders, updatedBy *uint) error {
+	if headers.FrameOptions != nil {
+		value := strings.ToUpper(strings.TrimSpace(*headers.FrameOptions))
+		if value != "DENY" && value != "SAMEORIGIN" && value != "" {
+			return ErrInvalidSecurityHeader
+		}
+		headers.FrameOptions = &value
+	}
+	if headers.HSTSMaxAgeSeconds != nil && (*headers.HSTSMaxAgeSeconds < 0 || *headers.HSTSMaxAgeSeconds > maxHSTSMaxAgeSeconds) {
+		return ErrInvalidSecurityHeader
+	}
+	if len(headers.PermissionsPolicy) > maxPermissionsPolicyEntries {
+		return ErrInvalidSecurityHeader
+	}
+	for i, entry := range headers.PermissionsPolicy {
+		entry = strings.TrimSpace(entry)
+		if !permissionsPolicyEntry.MatchString(entry) {
+			return ErrInvalidSecurityHeader
+		}
+		headers.PermissionsPolicy[i] = entry
+	}
+
+	value, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+	if err := uc.systemRepo.SetSetting(tenant.With(ctx, domain.DefaultOrganizationID), SecurityHeadersKey, string(value), updatedBy); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to set security headers setting", slog.String("headers", string(value)), slog.Any("error", err))
+		return err
+	}
+
+	uc.cspMu.Lock()
+	uc.securityHeaders = &headers
+	uc.securityHeadersLoadedAt = time.Now()
+	uc.cspMu.Unlock()
+
+	uc.logger.InfoContext(ctx, "Security headers setting updated", slog.String("headers", string(value)))
+	return nil
+}
+
+func (uc *systemUseCase) CurrentSecurityHeaders(ctx context.Context) domain.SecurityHeaders {
+	uc.cspMu.Lock()
+	defer uc.cspMu.Unlock()
+
+	if uc.securityHeaders == nil || time.Since(uc.securityHeadersLoadedAt) > cspCacheTTL {
+		headers, err := uc.GetSecurityHeaders(ctx)
+		if err != nil {
+			// При недоступной БД продолжаем с прежними значениями или значениями профиля
+			if uc.securityHeaders != nil {
+				headers = *uc.securityHeaders
+			}
+		}
+		uc.securityHeaders = &headers
+		uc.securityHeadersLoadedAt = time.Now()
+	}
+	return *uc.securityHeaders
+}
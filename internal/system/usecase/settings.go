@@ -0,0 +1,311 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/tenant"
+
+	"gorm.io/gorm"
+)
+
+// Типы значений настроек в административном API
+const (
+	SettingTypeBool       = "bool"
+	SettingTypeInt        = "int"
+	SettingTypeStringList = "string_list"
+	SettingTypeRateLimits = "rate_limits" // Объект {группа: {requests_per_minute, burst}}
+	SettingTypeCSP        = "csp"         // Объект {script_src, connect_src, img_src, report_only}
+	// Объект {frame_options, hsts_max_age_seconds, permissions_policy}; null - значение профиля окружения
+	SettingTypeSecurityHeaders = "security_headers"
+	// Объект {transport, printer, name_min_length, name_max_length}
+	SettingTypeContactFieldRules = "contact_field_rules"
+	SettingTypeTermsOfService    = "terms_of_service" // Объект {version, url}
+	// Объект {группа: число одновременных запросов}
+	SettingTypeConcurrencyLimits = "concurrency_limits"
+)
+
+var (
+	ErrInvalidSettingValue = apperror.Invalid("invalid_setting_value", "invalid setting value")
+	ErrGlobalSetting       = apperror.Forbidden("global_setting", "setting is shared by all organizations and can be changed only in the default organization")
+)
+
+// SettingInfo описывает настройку для административного интерфейса
+type SettingInfo struct {
+	Key         string
+	Type        string
+	Description string
+	Global      bool        // Общая для всех организаций, хранится в организации по умолчанию
+	Value       interface{} // Текущее значение с учетом значения по умолчанию
+	Default     interface{}
+	UpdatedAt   *time.Time // nil - настройка не менялась и действует значение по умолчанию
+	UpdatedBy   *uint
+}
+
+// settingDefinition связывает ключ настройки с ее типизированными методами UseCase
+type settingDefinition struct {
+	key          string
+	typ          string
+	description  string
+	global       bool
+	defaultValue interface{}
+	get          func(uc *systemUseCase, ctx context.Context) (interface{}, error)
+	// set разбирает JSON-значение и сохраняет его через типизированный метод с его проверками
+	set func(uc *systemUseCase, ctx context.Context, raw json.RawMessage, updatedBy *uint) error
+}
+
+// settingDefinitions - все настройки, доступные в /system/settings, в порядке вывода.
+// Новая настройка добавляется сюда вместе со своими методами Get/Set.
+var settingDefinitions = []settingDefinition{
+	{
+		key:          DebugModeKey,
+		typ:          SettingTypeBool,
+		description:  "Отладочный режим",
+		defaultValue: false,
+		get: func(uc *systemUseCase, ctx context.Context) (interface{}, error) {
+			return uc.GetDebugMode(ctx)
+		},
+		set: func(uc *systemUseCase, ctx context.Context, raw json.RawMessage, updatedBy *uint) error {
+			var enabled bool
+			if err := decodeSettingValue(raw, &enabled); err != nil {
+				return err
+			}
+			return uc.SetDebugMode(ctx, enabled, updatedBy)
+		},
+	},
+	{
+		key:          MaxSessionsPerUserKey,
+		typ:          SettingTypeInt,
+		description:  "Максимальное число одновременных сессий пользователя, 0 - без ограничений",
+		defaultValue: DefaultMaxSessionsPerUser,
+		get: func(uc *systemUseCase, ctx context.Context) (interface{}, error) {
+			return uc.GetMaxSessionsPerUser(ctx)
+		},
+		set: func(uc *systemUseCase, ctx context.Context, raw json.RawMessage, updatedBy *uint) error {
+			var limit int
+			if err := decodeSettingValue(raw, &limit); err != nil {
+				return err
+			}
+			return uc.SetMaxSessionsPerUser(ctx, limit, updatedBy)
+		},
+	},
+	{
+		key:          AdminDeviceApprovalKey,
+		typ:          SettingTypeBool,
+		description:  "Подтверждение входа администраторов с новых устройств",
+		defaultValue: false,
+		get: func(uc *systemUseCase, ctx context.Context) (interface{}, error) {
+			return uc.GetAdminDeviceApproval(ctx)
+		},
+		set: func(uc *systemUseCase, ctx context.Context, raw json.RawMessage, updatedBy *uint) error {
+			var enabled bool
+			if err := decodeSettingValue(raw, &enabled); err != nil {
+				return err
+			}
+			return uc.SetAdminDeviceApproval(ctx, enabled, updatedBy)
+		},
+	},
+	{
+		key:          ModeratedFieldGroupsKey,
+		typ:          SettingTypeStringList,
+		description:  "Группы полей контакта, самостоятельные правки которых требуют одобрения",
+		defaultValue: []string{},
+		get: func(uc *systemUseCase, ctx context.Context) (interface{}, error) {
+			return uc.GetModeratedFieldGroups(ctx)
+		},
+		set: func(uc *systemUseCase, ctx context.Context, raw json.RawMessage, updatedBy *uint) error {
+			var groups []string
+			if err := decodeSettingValue(raw, &groups); err != nil {
+				return err
+			}
+			return uc.SetModeratedFieldGroups(ctx, groups, updatedBy)
+		},
+	},
+	{
+		key:          RateLimitsKey,
+		typ:          SettingTypeRateLimits,
+		description:  "Лимиты частоты запросов групп маршрутов для одного IP-адреса; переданные группы заменяются, остальные не меняются",
+		global:       true,
+		defaultValue: DefaultRateLimits,
+		get: func(uc *systemUseCase, ctx context.Context) (interface{}, error) {
+			return uc.GetRateLimits(ctx)
+		},
+		set: func(uc *systemUseCase, ctx context.Context, raw json.RawMessage, updatedBy *uint) error {
+			var limits map[string]domain.RateLimit
+			if err := decodeSettingValue(raw, &limits); err != nil {
+				return err
+			}
+			_, err := uc.SetRateLimits(ctx, limits, updatedBy)
+			return err
+		},
+	},
+	{
+		key:          ConcurrencyLimitsKey,
+		typ:          SettingTypeConcurrencyLimits,
+		description:  "Лимиты одновременно выполняемых запросов экземпляра сервера: global - все запросы API, остальные - группы маршрутов; 0 - без ограничений",
+		global:       true,
+		defaultValue: DefaultConcurrencyLimits,
+		get: func(uc *systemUseCase, ctx context.Context) (interface{}, error) {
+			return uc.GetConcurrencyLimits(ctx)
+		},
+		set: func(uc *systemUseCase, ctx context.Context, raw json.RawMessage, updatedBy *uint) error {
+			var limits map[string]int
+			if err := decodeSettingValue(raw, &limits); err != nil {
+				return err
+			}
+			_, err := uc.SetConcurrencyLimits(ctx, limits, updatedBy)
+			return err
+		},
+	},
+	{
+		key:          ContentSecurityPolicyKey,
+		typ:          SettingTypeCSP,
+		description:  "Источники Content-Security-Policy для скриптов, запросов и изображений; report_only только сообщает о нарушениях",
+		global:       true,
+		defaultValue: DefaultContentSecurityPolicy,
+		get: func(uc *systemUseCase, ctx context.Context) (interface{}, error) {
+			return uc.GetContentSecurityPolicy(ctx)
+		},
+		set: func(uc *systemUseCase, ctx context.Context, raw json.RawMessage, updatedBy *uint) error {
+			var policy domain.ContentSecurityPolicy
+			if err := decodeSettingValue(raw, &policy); err != nil {
+				return err
+			}
+			return uc.SetContentSecurityPolicy(ctx, policy, updatedBy)
+		},
+	},
+	{
+		key:          SecurityHeadersKey,
+		typ:          SettingTypeSecurityHeaders,
+		description:  "Переопределения заголовков X-Frame-Options, Strict-Transport-Security и Permissions-Policy; null - значение профиля окружения",
+		global:       true,
+		defaultValue: domain.SecurityHeaders{},
+		get: func(uc *systemUseCase, ctx context.Context) (interface{}, error) {
+			return uc.GetSecurityHeaders(ctx)
+		},
+		set: func(uc *systemUseCase, ctx context.Context, raw json.RawMessage, updatedBy *uint) error {
+			var headers domain.SecurityHeaders
+			if err := decodeSettingValue(raw, &headers); err != nil {
+				return err
+			}
+			return uc.SetSecurityHeaders(ctx, headers, updatedBy)
+		},
+	},
+	{
+		key:          ContactFieldRulesKey,
+		typ:          SettingTypeContactFieldRules,
+		description:  "Допустимые значения полей transport и printer и ограничения длины имени контакта",
+		global:       true,
+		defaultValue: DefaultContactFieldRules,
+		get: func(uc *systemUseCase, ctx context.Context) (interface{}, error) {
+			return uc.GetContactFieldRules(ctx)
+		},
+		set: func(uc *systemUseCase, ctx context.Context, raw json.RawMessage, updatedBy *uint) error {
+			var rules domain.ContactFieldRules
+			if err := decodeSettingValue(raw, &rules); err != nil {
+				return err
+			}
+			return uc.SetContactFieldRules(ctx, rules, updatedBy)
+		},
+	},
+	{
+		key:          TermsOfServiceKey,
+		typ:          SettingTypeTermsOfService,
+		description:  "Версия и адрес пользовательского соглашения; после смены версии пользователи принимают его заново, пустая версия - соглашение не требуется",
+		global:       true,
+		defaultValue: domain.TermsOfService{},
+		get: func(uc *systemUseCase, ctx context.Context) (interface{}, error) {
+			return uc.GetTermsOfService(ctx)
+		},
+		set: func(uc *systemUseCase, ctx context.Context, raw json.RawMessage, updatedBy *uint) error {
+			var terms domain.TermsOfService
+			if err := decodeSettingValue(raw, &terms); err != nil {
+				return err
+			}
+			return uc.SetTermsOfService(ctx, terms, updatedBy)
+		},
+	},
+}
+
+func (uc *systemUseCase) ListSettings(ctx context.Context) ([]SettingInfo, error) {
+	settings := make([]SettingInfo, 0, len(settingDefinitions))
+	for i := range settingDefinitions {
+		info, err := uc.settingInfo(ctx, &settingDefinitions[i])
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, *info)
+	}
+	return settings, nil
+}
+
+func (uc *systemUseCase) UpdateSetting(ctx context.Context, key string, value json.RawMessage, updatedBy *uint) (*SettingInfo, error) {
+	def := findSettingDefinition(key)
+	if def == nil {
+		return nil, ErrSettingNotFound
+	}
+	if orgID := tenant.FromContext(ctx); def.global && orgID != 0 && orgID != domain.DefaultOrganizationID {
+		return nil, ErrGlobalSetting
+	}
+	if err := def.set(uc, ctx, value, updatedBy); err != nil {
+		return nil, err
+	}
+	return uc.settingInfo(ctx, def)
+}
+
+// settingInfo собирает текущее значение настройки и сведения о последнем изменении.
+func (uc *systemUseCase) settingInfo(ctx context.Context, def *settingDefinition) (*SettingInfo, error) {
+	value, err := def.get(uc, ctx)
+	if err != nil {
+		return nil, err
+	}
+	info := &SettingInfo{
+		Key:         def.key,
+		Type:        def.typ,
+		Description: def.description,
+		Global:      def.global,
+		Value:       value,
+		Default:     def.defaultValue,
+	}
+
+	storeCtx := ctx
+	if def.global {
+		storeCtx = tenant.With(ctx, domain.DefaultOrganizationID)
+	}
+	setting, err := uc.systemRepo.GetSetting(storeCtx, def.key)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return info, nil
+		}
+		return nil, err
+	}
+	info.UpdatedAt = &setting.UpdatedAt
+	info.UpdatedBy = setting.UpdatedBy
+	return info, nil
+}
+
+// findSettingDefinition возвращает описание настройки по ключу или nil.
+func findSettingDefinition(key string) *settingDefinition {
+	for i := range settingDefinitions {
+		if settingDefinitions[i].key == key {
+			return &settingDefinitions[i]
+		}
+	}
+	return nil
+}
+
+// decodeSettingValue разбирает JSON-значение настройки, отклоняя null и значения другого типа.
+func decodeSettingValue(raw json.RawMessage, dst interface{}) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return fmt.Errorf("%w: value is required", ErrInvalidSettingValue)
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSettingValue, err)
+	}
+	return nil
+}
@@ -2,9 +2,18 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"log/slog"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/tenant"
 
 	systemRepo "rim/internal/system/repository"
 
@@ -12,22 +21,149 @@ import (
 )
 
 const (
-	DebugModeKey = "debug_mode"
+	DebugModeKey           = "debug_mode"
+	MaxSessionsPerUserKey  = "max_sessions_per_user"
+	AdminDeviceApprovalKey = "admin_device_approval"
+	// ModeratedFieldGroupsKey - группы полей контакта (через запятую), правки которых пользователем требуют одобрения
+	ModeratedFieldGroupsKey = "moderated_contact_field_groups"
+	// RateLimitsKey - лимиты частоты запросов групп маршрутов в JSON; хранится в организации по умолчанию
+	RateLimitsKey = "rate_limits"
+
+	// DefaultMaxSessionsPerUser используется, если настройка max_sessions_per_user не задана
+	DefaultMaxSessionsPerUser = 5
+
+	// rateLimitsCacheTTL - как долго лимиты читаются из памяти, а не из БД
+	rateLimitsCacheTTL = 10 * time.Second
+)
+
+// Группы маршрутов с отдельными лимитами частоты запросов
+const (
+	RateLimitGroupAuth     = "auth"     // Вход и выдача кодов
+	RateLimitGroupContacts = "contacts" // Контакты
+	RateLimitGroupExports  = "exports"  // Выгрузки
+	RateLimitGroupImports  = "imports"  // Импорт
+	RateLimitGroupDefault  = "default"  // Остальные маршруты API
+	// RateLimitGroupAnonymousDirectory - GET /contacts без входа; считается отдельно от запросов пользователей
+	RateLimitGroupAnonymousDirectory = "anonymous_directory"
+	// RateLimitGroupSuspectedBots - анонимные запросы справочника, похожие по User-Agent на ботов и скрипты
+	RateLimitGroupSuspectedBots = "suspected_bots"
 )
 
+// DefaultRateLimits используются для групп, лимиты которых не заданы в настройке rate_limits
+var DefaultRateLimits = map[string]domain.RateLimit{
+	RateLimitGroupAuth:     {RequestsPerMinute: 60, Burst: 20},
+	RateLimitGroupContacts: {RequestsPerMinute: 300, Burst: 60},
+	RateLimitGroupExports:  {RequestsPerMinute: 10, Burst: 3},
+	RateLimitGroupImports:  {RequestsPerMinute: 10, Burst: 3},
+	RateLimitGroupDefault:  {RequestsPerMinute: 600, Burst: 100},
+
+	RateLimitGroupAnonymousDirectory: {RequestsPerMinute: 30, Burst: 10},
+	RateLimitGroupSuspectedBots:      {RequestsPerMinute: 6, Burst: 2},
+}
+
 var (
-	ErrSettingNotFound = errors.New("setting not found")
+	ErrSettingNotFound       = apperror.NotFound("setting_not_found", "setting not found")
+	ErrInvalidSessionsLimit  = apperror.Invalid("invalid_sessions_limit", "sessions limit cannot be negative")
+	ErrUnknownFieldGroup     = apperror.Invalid("unknown_field_group", "unknown contact field group")
+	ErrUnknownRateLimitGroup = apperror.Invalid("unknown_rate_limit_group", "unknown rate limit route group")
+	ErrInvalidRateLimit      = apperror.Invalid("invalid_rate_limit", "rate limit and burst cannot be negative")
 )
 
 // UseCase определяет интерфейс для системной бизнес-логики
 type UseCase interface {
 	GetDebugMode(ctx context.Context) (bool, error)
-	SetDebugMode(ctx context.Context, enabled bool) error
+	SetDebugMode(ctx context.Context, enabled bool, updatedBy *uint) error
+	// GetMaxSessionsPerUser возвращает лимит одновременных сессий пользователя (0 - без ограничений)
+	GetMaxSessionsPerUser(ctx context.Context) (int, error)
+	SetMaxSessionsPerUser(ctx context.Context, limit int, updatedBy *uint) error
+	// GetAdminDeviceApproval возвращает, требуется ли подтверждение новых устройств администраторов
+	GetAdminDeviceApproval(ctx context.Context) (bool, error)
+	SetAdminDeviceApproval(ctx context.Context, enabled bool, updatedBy *uint) error
+	// GetModeratedFieldGroups возвращает группы полей контакта, самостоятельные правки которых модерируются
+	GetModeratedFieldGroups(ctx context.Context) ([]string, error)
+	SetModeratedFieldGroups(ctx context.Context, groups []string, updatedBy *uint) error
+	// GetRateLimits возвращает лимиты всех групп маршрутов с учетом значений по умолчанию
+	GetRateLimits(ctx context.Context) (map[string]domain.RateLimit, error)
+	// SetRateLimits меняет лимиты переданных групп, остальные группы не затрагиваются
+	SetRateLimits(ctx context.Context, limits map[string]domain.RateLimit, updatedBy *uint) (map[string]domain.RateLimit, error)
+	// GetRateLimit возвращает лимит группы маршрутов. Значения кэшируются на rateLimitsCacheTTL,
+	// чтобы не обращаться к БД на каждый запрос.
+	GetRateLimit(ctx context.Context, group string) domain.RateLimit
+	// GetConcurrencyLimits возвращает лимиты одновременных запросов экземпляра сервера: global и групп маршрутов
+	GetConcurrencyLimits(ctx context.Context) (map[string]int, error)
+	// SetConcurrencyLimits меняет лимиты переданных групп, остальные группы не затрагиваются
+	SetConcurrencyLimits(ctx context.Context, limits map[string]int, updatedBy *uint) (map[string]int, error)
+	// GetConcurrencyLimit возвращает лимит группы с кэшированием на concurrencyLimitsCacheTTL; 0 - без ограничений
+	GetConcurrencyLimit(ctx context.Context, group string) int
+
+	// GetContentSecurityPolicy возвращает источники Content-Security-Policy из настройки или значения по умолчанию
+	GetContentSecurityPolicy(ctx context.Context) (domain.ContentSecurityPolicy, error)
+	// SetContentSecurityPolicy заменяет источники Content-Security-Policy; изменение применяется без перезапуска
+	SetContentSecurityPolicy(ctx context.Context, policy domain.ContentSecurityPolicy, updatedBy *uint) error
+	// CurrentContentSecurityPolicy возвращает политику для заголовков ответа. Значение кэшируется на cspCacheTTL;
+	// при ошибке БД используется последняя прочитанная политика.
+	CurrentContentSecurityPolicy(ctx context.Context) domain.ContentSecurityPolicy
+	// RecordCSPViolation записывает в журнал нарушение политики, о котором сообщил браузер,
+	// и сохраняет его среди последних cspViolationsKept нарушений
+	RecordCSPViolation(ctx context.Context, violation CSPViolation)
+	// GetCSPViolations возвращает последние нарушения политики этого экземпляра сервера, начиная с самого свежего
+	GetCSPViolations() []CSPViolation
+
+	// GetSecurityHeaders возвращает переопределения заголовков безопасности; пустые поля - значения профиля окружения
+	GetSecurityHeaders(ctx context.Context) (domain.SecurityHeaders, error)
+	// SetSecurityHeaders заменяет переопределения заголовков безопасности; изменение применяется без перезапуска
+	SetSecurityHeaders(ctx context.Context, headers domain.SecurityHeaders, updatedBy *uint) error
+	// CurrentSecurityHeaders возвращает переопределения для заголовков ответа с кэшированием на cspCacheTTL
+	CurrentSecurityHeaders(ctx context.Context) domain.SecurityHeaders
+
+	// GetContactFieldRules возвращает правила проверки полей контакта из настройки или значения по умолчанию
+	GetContactFieldRules(ctx context.Context) (domain.ContactFieldRules, error)
+	// SetContactFieldRules заменяет правила проверки полей контакта; изменение применяется без перезапуска
+	SetContactFieldRules(ctx context.Context, rules domain.ContactFieldRules, updatedBy *uint) error
+	// CurrentContactFieldRules возвращает правила для проверки запросов с кэшированием на contactFieldRulesCacheTTL;
+	// при ошибке БД используются последние прочитанные правила
+	CurrentContactFieldRules(ctx context.Context) domain.ContactFieldRules
+
+	// GetTermsOfService возвращает действующую версию пользовательского соглашения; пустая версия - соглашение не требуется
+	GetTermsOfService(ctx context.Context) (domain.TermsOfService, error)
+	// SetTermsOfService меняет версию соглашения; пользователи, не принявшие новую версию, не получат доступ к API
+	SetTermsOfService(ctx context.Context, terms domain.TermsOfService, updatedBy *uint) error
+	// CurrentTermsOfService возвращает версию соглашения для проверки запросов с кэшированием на termsCacheTTL
+	CurrentTermsOfService(ctx context.Context) domain.TermsOfService
+
+	// ListSettings возвращает все настройки с типами, значениями по умолчанию и автором последнего изменения
+	ListSettings(ctx context.Context) ([]SettingInfo, error)
+	// UpdateSetting меняет настройку key; value - JSON-значение типа настройки
+	UpdateSetting(ctx context.Context, key string, value json.RawMessage, updatedBy *uint) (*SettingInfo, error)
 }
 
 type systemUseCase struct {
 	systemRepo systemRepo.Repository
 	logger     *slog.Logger
+
+	rateLimitsMu       sync.Mutex
+	rateLimits         map[string]domain.RateLimit
+	rateLimitsLoadedAt time.Time
+
+	concurrencyLimitsMu       sync.Mutex
+	concurrencyLimits         map[string]int
+	concurrencyLimitsLoadedAt time.Time
+
+	// cspMu защищает кэш заголовков безопасности: политики CSP, переопределений заголовков и нарушений
+	cspMu                   sync.Mutex
+	csp                     *domain.ContentSecurityPolicy
+	cspLoadedAt             time.Time
+	cspViolations           []CSPViolation // Последние нарушения, начиная с самого старого
+	securityHeaders         *domain.SecurityHeaders
+	securityHeadersLoadedAt time.Time
+
+	contactFieldRulesMu       sync.Mutex
+	contactFieldRules         *domain.ContactFieldRules
+	contactFieldRulesLoadedAt time.Time
+
+	termsMu       sync.Mutex
+	terms         *domain.TermsOfService
+	termsLoadedAt time.Time
 }
 
 // NewSystemUseCase создает новый экземпляр системного UseCase
@@ -58,9 +194,9 @@ func (uc *systemUseCase) GetDebugMode(ctx context.Context) (bool, error) {
 	return debugMode, nil
 }
 
-func (uc *systemUseCase) SetDebugMode(ctx context.Context, enabled bool) error {
+func (uc *systemUseCase) SetDebugMode(ctx context.Context, enabled bool, updatedBy *uint) error {
 	value := strconv.FormatBool(enabled)
-	if err := uc.systemRepo.SetSetting(ctx, DebugModeKey, value); err != nil {
+	if err := uc.systemRepo.SetSetting(ctx, DebugModeKey, value, updatedBy); err != nil {
 		uc.logger.ErrorContext(ctx, "Failed to set debug mode setting", slog.Bool("enabled", enabled), slog.Any("error", err))
 		return err
 	}
@@ -68,3 +204,210 @@ func (uc *systemUseCase) SetDebugMode(ctx context.Context, enabled bool) error {
 	uc.logger.InfoContext(ctx, "Debug mode setting updated", slog.Bool("enabled", enabled))
 	return nil
 }
+
+func (uc *systemUseCase) GetMaxSessionsPerUser(ctx context.Context) (int, error) {
+	setting, err := uc.systemRepo.GetSetting(ctx, MaxSessionsPerUserKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return DefaultMaxSessionsPerUser, nil
+		}
+		uc.logger.ErrorContext(ctx, "Failed to get max sessions per user setting", slog.Any("error", err))
+		return 0, err
+	}
+
+	limit, err := strconv.Atoi(setting.Value)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to parse max sessions per user value", slog.String("value", setting.Value), slog.Any("error", err))
+		return 0, err
+	}
+
+	return limit, nil
+}
+
+func (uc *systemUseCase) SetMaxSessionsPerUser(ctx context.Context, limit int, updatedBy *uint) error {
+	if limit < 0 {
+		return ErrInvalidSessionsLimit
+	}
+
+	if err := uc.systemRepo.SetSetting(ctx, MaxSessionsPerUserKey, strconv.Itoa(limit), updatedBy); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to set max sessions per user setting", slog.Int("limit", limit), slog.Any("error", err))
+		return err
+	}
+
+	uc.logger.InfoContext(ctx, "Max sessions per user setting updated", slog.Int("limit", limit))
+	return nil
+}
+
+func (uc *systemUseCase) GetAdminDeviceApproval(ctx context.Context) (bool, error) {
+	setting, err := uc.systemRepo.GetSetting(ctx, AdminDeviceApprovalKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		uc.logger.ErrorContext(ctx, "Failed to get admin device approval setting", slog.Any("error", err))
+		return false, err
+	}
+
+	enabled, err := strconv.ParseBool(setting.Value)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to parse admin device approval value", slog.String("value", setting.Value), slog.Any("error", err))
+		return false, err
+	}
+
+	return enabled, nil
+}
+
+func (uc *systemUseCase) SetAdminDeviceApproval(ctx context.Context, enabled bool, updatedBy *uint) error {
+	if err := uc.systemRepo.SetSetting(ctx, AdminDeviceApprovalKey, strconv.FormatBool(enabled), updatedBy); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to set admin device approval setting", slog.Bool("enabled", enabled), slog.Any("error", err))
+		return err
+	}
+
+	uc.logger.InfoContext(ctx, "Admin device approval setting updated", slog.Bool("enabled", enabled))
+	return nil
+}
+
+func (uc *systemUseCase) GetModeratedFieldGroups(ctx context.Context) ([]string, error) {
+	setting, err := uc.systemRepo.GetSetting(ctx, ModeratedFieldGroupsKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return []string{}, nil
+		}
+		uc.logger.ErrorContext(ctx, "Failed to get moderated field groups setting", slog.Any("error", err))
+		return nil, err
+	}
+
+	groups := []string{}
+	for _, group := range strings.Split(setting.Value, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+func (uc *systemUseCase) SetModeratedFieldGroups(ctx context.Context, groups []string, updatedBy *uint) error {
+	unique := make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		if _, ok := domain.ContactFieldGroups[group]; !ok {
+			return ErrUnknownFieldGroup
+		}
+		unique[group] = struct{}{}
+	}
+	normalized := make([]string, 0, len(unique))
+	for group := range unique {
+		normalized = append(normalized, group)
+	}
+	sort.Strings(normalized)
+
+	value := strings.Join(normalized, ",")
+	if err := uc.systemRepo.SetSetting(ctx, ModeratedFieldGroupsKey, value, updatedBy); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to set moderated field groups setting", slog.String("groups", value), slog.Any("error", err))
+		return err
+	}
+
+	uc.logger.InfoContext(ctx, "Moderated field groups setting updated", slog.String("groups", value))
+	return nil
+}
+
+func (uc *systemUseCase) GetRateLimits(ctx context.Context) (map[string]domain.RateLimit, error) {
+	stored, err := uc.storedRateLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make(map[string]domain.RateLimit, len(DefaultRateLimits))
+	for group, limit := range DefaultRateLimits {
+		limits[group] = limit
+	}
+	for group, limit := range stored {
+		if _, ok := limits[group]; ok {
+			limits[group] = limit
+		}
+	}
+	return limits, nil
+}
+
+func (uc *systemUseCase) SetRateLimits(ctx context.Context, limits map[string]domain.RateLimit, updatedBy *uint) (map[string]domain.RateLimit, error) {
+	for group, limit := range limits {
+		if _, ok := DefaultRateLimits[group]; !ok {
+			return nil, ErrUnknownRateLimitGroup
+		}
+		if limit.RequestsPerMinute < 0 || limit.Burst < 0 {
+			return nil, ErrInvalidRateLimit
+		}
+	}
+
+	// Сохраняются только явно заданные лимиты, остальные группы следуют значениям по умолчанию
+	stored, err := uc.storedRateLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for group, limit := range limits {
+		stored[group] = limit
+	}
+
+	value, err := json.Marshal(stored)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.systemRepo.SetSetting(tenant.With(ctx, domain.DefaultOrganizationID), RateLimitsKey, string(value), updatedBy); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to set rate limits setting", slog.String("limits", string(value)), slog.Any("error", err))
+		return nil, err
+	}
+
+	current, err := uc.GetRateLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.rateLimitsMu.Lock()
+	uc.rateLimits = current
+	uc.rateLimitsLoadedAt = time.Now()
+	uc.rateLimitsMu.Unlock()
+
+	uc.logger.InfoContext(ctx, "Rate limits setting updated", slog.String("limits", string(value)))
+	return current, nil
+}
+
+// storedRateLimits читает лимиты, заданные в настройке rate_limits. Лимиты защищают все развертывание,
+// поэтому хранятся в организации по умолчанию.
+func (uc *systemUseCase) storedRateLimits(ctx context.Context) (map[string]domain.RateLimit, error) {
+	stored := map[string]domain.RateLimit{}
+	setting, err := uc.systemRepo.GetSetting(tenant.With(ctx, domain.DefaultOrganizationID), RateLimitsKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return stored, nil
+		}
+		uc.logger.ErrorContext(ctx, "Failed to get rate limits setting", slog.Any("error", err))
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(setting.Value), &stored); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to parse rate limits value", slog.String("value", setting.Value), slog.Any("error", err))
+		return nil, err
+	}
+	return stored, nil
+}
+
+func (uc *systemUseCase) GetRateLimit(ctx context.Context, group string) domain.RateLimit {
+	uc.rateLimitsMu.Lock()
+	defer uc.rateLimitsMu.Unlock()
+
+	if uc.rateLimits == nil || time.Since(uc.rateLimitsLoadedAt) > rateLimitsCacheTTL {
+		limits, err := uc.GetRateLimits(ctx)
+		if err != nil {
+			// При недоступной БД продолжаем с прежними лимитами или значениями по умолчанию
+			limits = uc.rateLimits
+			if limits == nil {
+				limits = DefaultRateLimits
+			}
+		}
+		uc.rateLimits = limits
+		uc.rateLimitsLoadedAt = time.Now()
+	}
+
+	if limit, ok := uc.rateLimits[group]; ok {
+		return limit
+	}
+	return uc.rateLimits[RateLimitGroupDefault]
+}
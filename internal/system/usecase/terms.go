@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/apperror"
+	"rim/pkg/tenant"
+
+	"gorm.io/gorm"
+)
+
+// TermsOfServiceKey - действующая версия пользовательского соглашения в JSON; хранится в организации по умолчанию
+const TermsOfServiceKey = "terms_of_service"
+
+const (
+	// maxTermsVersionLength - максимальная длина версии соглашения
+	maxTermsVersionLength = 50
+	// termsCacheTTL - как долго версия соглашения читается из памяти; проверяется на каждый запрос с сессией
+	termsCacheTTL = 10 * time.Second
+)
+
+var ErrInvalidTermsOfService = apperror.Invalid("invalid_terms_of_service", "invalid terms of service")
+
+func (uc *systemUseCase) GetTermsOfService(ctx context.Context) (domain.TermsOfService, error) {
+	setting, err := uc.systemRepo.GetSetting(tenant.With(ctx, domain.DefaultOrganizationID), TermsOfServiceKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.TermsOfService{}, nil
+		}
+		uc.logger.ErrorContext(ctx, "Failed to get terms of service setting", slog.Any("error", err))
+		return domain.TermsOfService{}, err
+	}
+	var terms domain.TermsOfService
+	if err := json.Unmarshal([]byte(setting.Value), &terms); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to parse terms of service value", slog.String("value", setting.Value), slog.Any("error", err))
+		return domain.TermsOfService{}, err
+	}
+	return terms, nil
+}
+
+func (uc *systemUseCase) SetTermsOfService(ctx context.Context, terms domain.TermsOfService, updatedBy *uint) error {
+	terms.Version = strings.TrimSpace(terms.Version)
+	terms.URL = strings.TrimSpace(terms.URL)
+	if len(terms.Version) > maxTermsVersionLength {
+		return fmt.Errorf("%w: version must be at most %d characters long", ErrInvalidTermsOfService, maxTermsVersionLength)
+	}
+	if terms.URL != "" {
+		u, err := url.Parse(terms.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidTermsOfService)
+		}
+	}
+
+	value, err := json.Marshal(terms)
+	if err != nil {
+		return err
+	}
+	if err := uc.systemRepo.SetSetting(tenant.With(ctx, domain.DefaultOrganizationID), TermsOfServiceKey, string(value), updatedBy); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to set terms of service setting", slog.String("version", terms.Version), slog.Any("error", err))
+		return err
+	}
+
+	uc.termsMu.Lock()
+	uc.terms = &terms
+	uc.termsLoadedAt = time.Now()
+	uc.termsMu.Unlock()
+
+	uc.logger.InfoContext(ctx, "Terms of service setting updated", slog.String("version", terms.Version), slog.String("url", terms.URL))
+	return nil
+}
+
+func (uc *systemUseCase) CurrentTermsOfService(ctx context.Context) domain.TermsOfService {
+	uc.termsMu.Lock()
+	defer uc.termsMu.Unlock()
+
+	if uc.terms == nil || time.Since(uc.termsLoadedAt) > termsCacheTTL {
+		terms, err := uc.GetTermsOfService(ctx)
+		if err != nil && uc.terms != nil {
+			// При недоступной БД продолжаем с прежней версией, чтобы не требовать принятия заново
+			terms = *uc.terms
+		}
+		uc.terms = &terms
+		uc.termsLoadedAt = time.Now()
+	}
+	return *uc.terms
+}
@@ -0,0 +1,12 @@
+package delivery
+
+// TagRequest определяет структуру запроса на создание или переименование метки.
+type TagRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=50"`
+}
+
+// TagResponse определяет структуру метки в ответе.
+type TagResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
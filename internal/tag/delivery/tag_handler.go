@@ -0,0 +1,184 @@
+package delivery
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"rim/internal/domain"
+	groupDelivery "rim/internal/group/delivery"
+	"rim/internal/tag/usecase"
+	"rim/pkg/apperror"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler отвечает за обработку HTTP-запросов меток контактов.
+type Handler struct {
+	tagUseCase usecase.UseCase
+	logger     *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler.
+func NewHandler(tagUC usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		tagUseCase: tagUC,
+		logger:     logger,
+	}
+}
+
+// GetAllTags возвращает метки организации.
+// @Summary Получить список меток
+// @Description Возвращает метки организации в алфавитном порядке. Контакты с меткой: GET /contacts?tag={name}.
+// @Tags tags
+// @Produce json
+// @Success 200 {array} TagResponse
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /tags [get]
+func (h *Handler) GetAllTags(c *fiber.Ctx) error {
+	tags, err := h.tagUseCase.GetAllTags(c.Context())
+	if err != nil {
+		return h.tagError(c, err)
+	}
+	resp := make([]TagResponse, len(tags))
+	for i := range tags {
+		resp[i] = ToTagResponse(&tags[i])
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// CreateTag создает метку.
+// @Summary Создать метку
+// @Description Название хранится как введено, без лишних пробелов; регистр сохраняется.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag body TagRequest true "Название метки"
+// @Success 201 {object} TagResponse
+// @Failure 400 {object} validation.Response "Ошибка валидации"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Метка уже существует"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /tags [post]
+func (h *Handler) CreateTag(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[TagRequest](c)
+	if !ok {
+		return err
+	}
+
+	tag, err := h.tagUseCase.CreateTag(c.Context(), req.Name)
+	if err != nil {
+		return h.tagError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(ToTagResponse(tag))
+}
+
+// UpdateTag переименовывает метку.
+// @Summary Переименовать метку
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "ID метки"
+// @Param tag body TagRequest true "Новое название"
+// @Success 200 {object} TagResponse
+// @Failure 400 {object} validation.Response "Ошибка валидации"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Метка не найдена"
+// @Failure 409 {object} groupDelivery.ErrorResponse "Метка с таким названием уже существует"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /tags/{id} [put]
+func (h *Handler) UpdateTag(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid tag ID format"})
+	}
+	req, ok, err := validation.BindAndValidate[TagRequest](c)
+	if !ok {
+		return err
+	}
+
+	tag, err := h.tagUseCase.UpdateTag(c.Context(), uint(id), req.Name)
+	if err != nil {
+		return h.tagError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(ToTagResponse(tag))
+}
+
+// DeleteTag удаляет метку и снимает ее со всех контактов.
+// @Summary Удалить метку
+// @Tags tags
+// @Param id path int true "ID метки"
+// @Success 204 "Метка удалена"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Метка не найдена"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /tags/{id} [delete]
+func (h *Handler) DeleteTag(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid tag ID format"})
+	}
+	if err := h.tagUseCase.DeleteTag(c.Context(), uint(id)); err != nil {
+		return h.tagError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// AddContactTag ставит метку контакту.
+// @Summary Поставить метку контакту
+// @Description Повторная установка той же метки не считается ошибкой.
+// @Tags contacts
+// @Param id path int true "ID контакта"
+// @Param tag_id path int true "ID метки"
+// @Success 204 "Метка поставлена"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт или метка не найдены"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/tags/{tag_id} [post]
+func (h *Handler) AddContactTag(c *fiber.Ctx) error {
+	return h.changeContactTag(c, h.tagUseCase.AddContactTag)
+}
+
+// RemoveContactTag снимает метку с контакта.
+// @Summary Снять метку с контакта
+// @Tags contacts
+// @Param id path int true "ID контакта"
+// @Param tag_id path int true "ID метки"
+// @Success 204 "Метка снята"
+// @Failure 400 {object} groupDelivery.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} groupDelivery.ErrorResponse "Контакт или метка не найдены"
+// @Failure 500 {object} groupDelivery.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /contacts/{id}/tags/{tag_id} [delete]
+func (h *Handler) RemoveContactTag(c *fiber.Ctx) error {
+	return h.changeContactTag(c, h.tagUseCase.RemoveContactTag)
+}
+
+func (h *Handler) changeContactTag(c *fiber.Ctx, change func(ctx context.Context, contactID, tagID uint) error) error {
+	contactID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid contact ID format"})
+	}
+	tagID, err := strconv.ParseUint(c.Params("tag_id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(groupDelivery.ErrorResponse{Message: "Invalid tag ID format"})
+	}
+
+	if err := change(c.Context(), uint(contactID), uint(tagID)); err != nil {
+		return h.tagError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// tagError преобразует ошибки usecase в HTTP-ответ по их виду (pkg/apperror)
+func (h *Handler) tagError(c *fiber.Ctx, err error) error {
+	status := apperror.HTTPStatus(err)
+	if status == fiber.StatusInternalServerError {
+		h.logger.ErrorContext(c.Context(), "Tag operation failed", slog.Any("error", err))
+		return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: "Internal server error"})
+	}
+	return c.Status(status).JSON(groupDelivery.ErrorResponse{Message: err.Error()})
+}
+
+// ToTagResponse преобразует domain.Tag в TagResponse.
+func ToTagResponse(tag *domain.Tag) TagResponse {
+	return TagResponse{ID: tag.ID, Name: tag.Name}
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"rim/internal/domain"
+	"rim/pkg/tenant"
+	"rim/pkg/transaction"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNameTaken - в организации уже есть метка с таким названием
+var ErrNameTaken = errors.New("tag name is already taken")
+
+// Repository определяет интерфейс хранения меток и их назначений контактам.
+type Repository interface {
+	Create(ctx context.Context, tag *domain.Tag) error
+	GetByID(ctx context.Context, id uint) (*domain.Tag, error)
+	// GetAll возвращает метки организации в алфавитном порядке
+	GetAll(ctx context.Context) ([]domain.Tag, error)
+	Update(ctx context.Context, tag *domain.Tag) error
+	// Delete удаляет метку и снимает ее со всех контактов
+	Delete(ctx context.Context, id uint) error
+	// AddToContact ставит метку контакту; повторная установка не считается ошибкой
+	AddToContact(ctx context.Context, contactID, tagID uint) error
+	RemoveFromContact(ctx context.Context, contactID, tagID uint) error
+}
+
+type sqliteRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository создает новый экземпляр sqliteRepository для меток.
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &sqliteRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// contactTag - строка таблицы contact_tags, которую создает связь Contact.Tags
+type contactTag struct {
+	ContactID uint
+	TagID     uint
+}
+
+func (r *sqliteRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	if orgID := tenant.FromContext(ctx); orgID != 0 && tag.OrganizationID == 0 {
+		tag.OrganizationID = orgID
+	}
+	if err := transaction.DB(ctx, r.db).Create(tag).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrNameTaken
+		}
+		r.logger.ErrorContext(ctx, "Error creating tag in DB", slog.String("tagName", tag.Name), slog.Any("error", err))
+		return err
+	}
+	r.logger.InfoContext(ctx, "Successfully created tag in DB", slog.Uint64("tagID", uint64(tag.ID)), slog.String("tagName", tag.Name))
+	return nil
+}
+
+func (r *sqliteRepository) GetByID(ctx context.Context, id uint) (*domain.Tag, error) {
+	var tag domain.Tag
+	if err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "tags")).First(&tag, id).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.ErrorContext(ctx, "Error getting tag by ID from DB", slog.Uint64("tagID", uint64(id)), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *sqliteRepository) GetAll(ctx context.Context) ([]domain.Tag, error) {
+	var tags []domain.Tag
+	if err := transaction.DB(ctx, r.db).Scopes(tenant.Scope(ctx, "tags")).Order("name").Find(&tags).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error getting all tags from DB", slog.Any("error", err))
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *sqliteRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	if err := transaction.DB(ctx, r.db).Model(tag).Update("name", tag.Name).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrNameTaken
+		}
+		r.logger.ErrorContext(ctx, "Error updating tag in DB", slog.Uint64("tagID", uint64(tag.ID)), slog.Any("error", err))
+		return err
+	}
+	r.logger.InfoContext(ctx, "Successfully updated tag in DB", slog.Uint64("tagID", uint64(tag.ID)))
+	return nil
+}
+
+func (r *sqliteRepository) Delete(ctx context.Context, id uint) error {
+	return transaction.Run(ctx, r.db, func(ctx context.Context) error {
+		tx := transaction.DB(ctx, r.db)
+		result := tx.Scopes(tenant.Scope(ctx, "tags")).Delete(&domain.Tag{}, id)
+		if result.Error != nil {
+			r.logger.ErrorContext(ctx, "Error deleting tag from DB", slog.Uint64("tagID", uint64(id)), slog.Any("error", result.Error))
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Where("tag_id = ?", id).Delete(&contactTag{}).Error; err != nil {
+			r.logger.ErrorContext(ctx, "Error deleting tag assignments from DB", slog.Uint64("tagID", uint64(id)), slog.Any("error", err))
+			return err
+		}
+		r.logger.InfoContext(ctx, "Successfully deleted tag from DB", slog.Uint64("tagID", uint64(id)))
+		return nil
+	})
+}
+
+func (r *sqliteRepository) AddToContact(ctx context.Context, contactID, tagID uint) error {
+	err := transaction.DB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&contactTag{ContactID: contactID, TagID: tagID}).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error adding tag to contact in DB", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("tagID", uint64(tagID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqliteRepository) RemoveFromContact(ctx context.Context, contactID, tagID uint) error {
+	if err := transaction.DB(ctx, r.db).Where("contact_id = ? AND tag_id = ?", contactID, tagID).Delete(&contactTag{}).Error; err != nil {
+		r.logger.ErrorContext(ctx, "Error removing tag from contact in DB", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("tagID", uint64(tagID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// isUniqueViolation распознает нарушение уникального индекса по организации и названию (сообщения SQLite и Postgres)
+func isUniqueViolation(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unique constraint") || strings.Contains(msg, "duplicate key")
+}
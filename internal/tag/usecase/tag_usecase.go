@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	contactRepo "rim/internal/contact/repository"
+	contactUseCase "rim/internal/contact/usecase"
+	"rim/internal/domain"
+	"rim/internal/tag/repository"
+	"rim/pkg/apperror"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTagNameEmpty  = apperror.Invalid("tag_name_empty", "tag name cannot be empty")
+	ErrTagNotFound   = apperror.NotFound("tag_not_found", "tag not found")
+	ErrTagNameExists = apperror.Conflict("tag_name_exists", "tag with this name already exists")
+)
+
+// UseCase определяет интерфейс управления метками контактов.
+type UseCase interface {
+	GetAllTags(ctx context.Context) ([]domain.Tag, error)
+	CreateTag(ctx context.Context, name string) (*domain.Tag, error)
+	UpdateTag(ctx context.Context, id uint, newName string) (*domain.Tag, error)
+	// DeleteTag удаляет метку и снимает ее со всех контактов
+	DeleteTag(ctx context.Context, id uint) error
+	AddContactTag(ctx context.Context, contactID, tagID uint) error
+	RemoveContactTag(ctx context.Context, contactID, tagID uint) error
+}
+
+type tagUseCase struct {
+	tagRepo     repository.Repository
+	contactRepo contactRepo.Repository
+	logger      *slog.Logger
+}
+
+// NewTagUseCase создает новый экземпляр tagUseCase.
+func NewTagUseCase(tagRepo repository.Repository, cr contactRepo.Repository, logger *slog.Logger) UseCase {
+	return &tagUseCase{
+		tagRepo:     tagRepo,
+		contactRepo: cr,
+		logger:      logger,
+	}
+}
+
+// NormalizeName приводит название метки к виду, в котором оно хранится: без пробелов по краям и повторов внутри.
+// Регистр сохраняется, чтобы метки вроде "English" выводились как их назвали.
+func NormalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+func (uc *tagUseCase) GetAllTags(ctx context.Context) ([]domain.Tag, error) {
+	return uc.tagRepo.GetAll(ctx)
+}
+
+func (uc *tagUseCase) CreateTag(ctx context.Context, name string) (*domain.Tag, error) {
+	name = NormalizeName(name)
+	if name == "" {
+		return nil, ErrTagNameEmpty
+	}
+	tag := &domain.Tag{Name: name}
+	if err := uc.tagRepo.Create(ctx, tag); err != nil {
+		if errors.Is(err, repository.ErrNameTaken) {
+			return nil, ErrTagNameExists
+		}
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Tag created successfully", slog.Uint64("id", uint64(tag.ID)), slog.String("name", tag.Name))
+	return tag, nil
+}
+
+func (uc *tagUseCase) UpdateTag(ctx context.Context, id uint, newName string) (*domain.Tag, error) {
+	newName = NormalizeName(newName)
+	if newName == "" {
+		return nil, ErrTagNameEmpty
+	}
+	tag, err := uc.getTag(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tag.Name == newName {
+		return tag, nil
+	}
+
+	tag.Name = newName
+	if err := uc.tagRepo.Update(ctx, tag); err != nil {
+		if errors.Is(err, repository.ErrNameTaken) {
+			return nil, ErrTagNameExists
+		}
+		return nil, err
+	}
+	uc.logger.InfoContext(ctx, "Tag updated successfully", slog.Uint64("id", uint64(id)), slog.String("name", newName))
+	return tag, nil
+}
+
+func (uc *tagUseCase) DeleteTag(ctx context.Context, id uint) error {
+	if err := uc.tagRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTagNotFound
+		}
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Tag deleted successfully", slog.Uint64("id", uint64(id)))
+	return nil
+}
+
+func (uc *tagUseCase) AddContactTag(ctx context.Context, contactID, tagID uint) error {
+	if err := uc.checkAssignment(ctx, contactID, tagID); err != nil {
+		return err
+	}
+	if err := uc.tagRepo.AddToContact(ctx, contactID, tagID); err != nil {
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Tag added to contact", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("tagID", uint64(tagID)))
+	return nil
+}
+
+func (uc *tagUseCase) RemoveContactTag(ctx context.Context, contactID, tagID uint) error {
+	if err := uc.checkAssignment(ctx, contactID, tagID); err != nil {
+		return err
+	}
+	if err := uc.tagRepo.RemoveFromContact(ctx, contactID, tagID); err != nil {
+		return err
+	}
+	uc.logger.InfoContext(ctx, "Tag removed from contact", slog.Uint64("contactID", uint64(contactID)), slog.Uint64("tagID", uint64(tagID)))
+	return nil
+}
+
+// checkAssignment проверяет, что контакт и метка есть в организации запроса:
+// иначе метку можно было бы поставить контакту другой организации
+func (uc *tagUseCase) checkAssignment(ctx context.Context, contactID, tagID uint) error {
+	if _, err := uc.contactRepo.GetByID(ctx, contactID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return contactUseCase.ErrContactNotFound
+		}
+		return err
+	}
+	_, err := uc.getTag(ctx, tagID)
+	return err
+}
+
+func (uc *tagUseCase) getTag(ctx context.Context, id uint) (*domain.Tag, error) {
+	tag, err := uc.tagRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+	return tag, nil
+}
@@ -0,0 +1,38 @@
+package delivery
+
+import (
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/validation"
+)
+
+// Области действия, которые можно выдать токену
+func init() {
+	validation.RegisterEnum("token_scope", domain.ScopeContactsRead, domain.ScopeGroupsRead, domain.ScopeDirectoryRead)
+}
+
+// CreateTokenRequest определяет структуру запроса на выпуск токена доступа
+type CreateTokenRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,enum=token_scope"`
+	GroupID   *uint      `json:"group_id,omitempty"`   // Ограничить токен контактами одной группы
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // RFC3339; без срока действия, если не задан
+}
+
+// TokenResponse определяет структуру ответа с токеном доступа (без открытого значения)
+type TokenResponse struct {
+	ID         uint     `json:"id"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	GroupID    *uint    `json:"group_id,omitempty"`
+	ExpiresAt  string   `json:"expires_at,omitempty"`
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// CreateTokenResponse содержит открытое значение токена. Оно возвращается только один раз.
+type CreateTokenResponse struct {
+	TokenResponse
+	Token string `json:"token"`
+}
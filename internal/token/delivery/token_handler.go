@@ -0,0 +1,187 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rim/internal/domain"
+	"rim/internal/token/usecase"
+	"rim/pkg/timeutil"
+	"rim/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler обрабатывает HTTP запросы для токенов доступа
+type Handler struct {
+	tokenUseCase usecase.UseCase
+	logger       *slog.Logger
+}
+
+// NewHandler создает новый экземпляр Handler для токенов доступа
+func NewHandler(tokenUseCase usecase.UseCase, logger *slog.Logger) *Handler {
+	return &Handler{
+		tokenUseCase: tokenUseCase,
+		logger:       logger,
+	}
+}
+
+// RequireScope middleware пропускает только запросы с действующим токеном доступа, которому выдана область scope.
+// Токен передается в заголовке "Authorization: Bearer rim_..." или "X-API-Token".
+// Сохраняет токен в c.Locals("api_token") и роль dashboard в c.Locals("role").
+func (h *Handler) RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		raw := c.Get("X-API-Token")
+		if raw == "" {
+			raw = strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		}
+		if raw == "" {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"error": "API token required",
+			})
+		}
+
+		token, err := h.tokenUseCase.Authenticate(c.Context(), raw, scope)
+		if err != nil {
+			switch {
+			case errors.Is(err, usecase.ErrTokenInvalid), errors.Is(err, usecase.ErrTokenExpired):
+				return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Invalid or expired API token",
+				})
+			case errors.Is(err, usecase.ErrScopeDenied):
+				return c.Status(http.StatusForbidden).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			default:
+				h.logger.ErrorContext(c.Context(), "Failed to authenticate API token", slog.Any("error", err))
+				return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Internal server error",
+				})
+			}
+		}
+
+		c.Locals("api_token", token)
+		c.Locals("role", domain.RoleDashboard)
+		return c.Next()
+	}
+}
+
+// CreateToken выпускает токен доступа
+// @Summary Выпустить токен доступа
+// @Description Создает токен только для чтения (например, для экрана-дашборда). Открытое значение токена возвращается один раз.
+// @Description Токен с областью directory:read работает только в публичном справочнике /api/public/v1 и не сочетается с другими областями.
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param token body CreateTokenRequest true "Параметры токена"
+// @Success 201 {object} CreateTokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tokens [post]
+func (h *Handler) CreateToken(c *fiber.Ctx) error {
+	req, ok, err := validation.BindAndValidate[CreateTokenRequest](c)
+	if !ok {
+		return err
+	}
+
+	userID, _ := c.Locals("user_id").(uint)
+	token, raw, err := h.tokenUseCase.CreateToken(c.Context(), usecase.CreateTokenData{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		GroupID:   req.GroupID,
+		ExpiresAt: req.ExpiresAt,
+		CreatedBy: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrTokenNameEmpty), errors.Is(err, usecase.ErrInvalidScope), errors.Is(err, usecase.ErrInvalidExpiry), errors.Is(err, usecase.ErrPublicScopeMixed):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, usecase.ErrTokenGroupMissing):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to create API token", slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(CreateTokenResponse{
+		TokenResponse: toTokenResponse(token),
+		Token:         raw,
+	})
+}
+
+// GetAllTokens возвращает выпущенные токены доступа
+// @Summary Получить токены доступа
+// @Description Возвращает выпущенные токены без их открытых значений
+// @Tags tokens
+// @Produce json
+// @Success 200 {array} TokenResponse
+// @Failure 500 {object} map[string]string
+// @Router /tokens [get]
+func (h *Handler) GetAllTokens(c *fiber.Ctx) error {
+	tokens, err := h.tokenUseCase.GetAllTokens(c.Context())
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	resp := make([]TokenResponse, len(tokens))
+	for i := range tokens {
+		resp[i] = toTokenResponse(&tokens[i])
+	}
+	return c.JSON(resp)
+}
+
+// RevokeToken отзывает токен доступа
+// @Summary Отозвать токен доступа
+// @Description Удаляет токен; запросы с ним сразу перестают проходить
+// @Tags tokens
+// @Param id path int true "ID токена"
+// @Success 204 "Токен отозван"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tokens/{id} [delete]
+func (h *Handler) RevokeToken(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid token ID format",
+		})
+	}
+
+	if err := h.tokenUseCase.RevokeToken(c.Context(), uint(id)); err != nil {
+		if errors.Is(err, usecase.ErrTokenNotFound) {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		h.logger.ErrorContext(c.Context(), "Failed to revoke API token", slog.Uint64("id", id), slog.Any("error", err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func toTokenResponse(token *domain.APIToken) TokenResponse {
+	resp := TokenResponse{
+		ID:        token.ID,
+		Name:      token.Name,
+		Scopes:    token.ScopeList(),
+		GroupID:   token.GroupID,
+		CreatedAt: timeutil.Format(token.CreatedAt, time.UTC),
+	}
+	if token.ExpiresAt != nil {
+		resp.ExpiresAt = timeutil.Format(*token.ExpiresAt, time.UTC)
+	}
+	if token.LastUsedAt != nil {
+		resp.LastUsedAt = timeutil.Format(*token.LastUsedAt, time.UTC)
+	}
+	return resp
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/repository"
+
+	"gorm.io/gorm"
+)
+
+// Repository определяет интерфейс для операций с токенами доступа
+type Repository interface {
+	Create(ctx context.Context, token *domain.APIToken) (*domain.APIToken, error)
+	GetAll(ctx context.Context) ([]domain.APIToken, error)
+	GetByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error)
+	Delete(ctx context.Context, id uint) error
+	TouchLastUsed(ctx context.Context, id uint, usedAt time.Time) error
+}
+
+type tokenRepository struct {
+	*repository.BaseRepository[domain.APIToken]
+}
+
+// NewSQLiteRepository создает новый экземпляр репозитория токенов доступа
+func NewSQLiteRepository(db *gorm.DB, logger *slog.Logger) Repository {
+	return &tokenRepository{
+		BaseRepository: repository.NewBaseRepository[domain.APIToken](db, logger),
+	}
+}
+
+// GetByHash получает токен по хешу
+func (r *tokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error) {
+	var token domain.APIToken
+	if err := r.DB().WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			r.Logger().ErrorContext(ctx, "Failed to get API token by hash", slog.Any("error", err))
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Delete удаляет токен. Токены не используют мягкое удаление.
+func (r *tokenRepository) Delete(ctx context.Context, id uint) error {
+	result := r.DB().WithContext(ctx).Delete(&domain.APIToken{}, id)
+	if result.Error != nil {
+		r.Logger().ErrorContext(ctx, "Failed to delete API token", slog.Uint64("id", uint64(id)), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed сохраняет время последнего использования токена
+func (r *tokenRepository) TouchLastUsed(ctx context.Context, id uint, usedAt time.Time) error {
+	err := r.DB().WithContext(ctx).Model(&domain.APIToken{}).Where("id = ?", id).UpdateColumn("last_used_at", usedAt).Error
+	if err != nil {
+		r.Logger().WarnContext(ctx, "Failed to update API token last used time", slog.Uint64("id", uint64(id)), slog.Any("error", err))
+	}
+	return err
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"rim/internal/domain"
+	groupRepo "rim/internal/group/repository"
+	"rim/internal/token/repository"
+	"rim/pkg/timeutil"
+
+	"gorm.io/gorm"
+)
+
+// TokenPrefix отличает токены доступа от токенов сессий
+const TokenPrefix = "rim_"
+
+var (
+	ErrTokenNotFound     = errors.New("token not found")
+	ErrTokenInvalid      = errors.New("invalid token")
+	ErrTokenExpired      = errors.New("token expired")
+	ErrScopeDenied       = errors.New("token scope does not allow this action")
+	ErrTokenNameEmpty    = errors.New("token name cannot be empty")
+	ErrInvalidScope      = errors.New("invalid token scope")
+	ErrTokenGroupMissing = errors.New("group not found")
+	ErrInvalidExpiry     = errors.New("token expiry must be in the future")
+	ErrPublicScopeMixed  = errors.New("directory:read cannot be combined with other scopes")
+)
+
+// supportedScopes - области, которые можно выдать токену
+var supportedScopes = map[string]struct{}{
+	domain.ScopeContactsRead:  {},
+	domain.ScopeGroupsRead:    {},
+	domain.ScopeDirectoryRead: {},
+}
+
+// CreateTokenData определяет данные для выпуска токена
+type CreateTokenData struct {
+	Name      string
+	Scopes    []string
+	GroupID   *uint
+	ExpiresAt *time.Time
+	CreatedBy uint
+}
+
+// UseCase определяет интерфейс бизнес-логики токенов доступа
+type UseCase interface {
+	// CreateToken выпускает токен и возвращает его открытое значение, которое больше нигде не сохраняется
+	CreateToken(ctx context.Context, data CreateTokenData) (*domain.APIToken, string, error)
+	GetAllTokens(ctx context.Context) ([]domain.APIToken, error)
+	RevokeToken(ctx context.Context, id uint) error
+	// Authenticate проверяет токен и наличие у него области scope
+	Authenticate(ctx context.Context, rawToken, scope string) (*domain.APIToken, error)
+}
+
+type tokenUseCase struct {
+	tokenRepo repository.Repository
+	groupRepo groupRepo.Repository
+	logger    *slog.Logger
+}
+
+// NewTokenUseCase создает новый экземпляр UseCase токенов доступа
+func NewTokenUseCase(tokenRepo repository.Repository, gr groupRepo.Repository, logger *slog.Logger) UseCase {
+	return &tokenUseCase{
+		tokenRepo: tokenRepo,
+		groupRepo: gr,
+		logger:    logger,
+	}
+}
+
+func (uc *tokenUseCase) CreateToken(ctx context.Context, data CreateTokenData) (*domain.APIToken, string, error) {
+	name := strings.TrimSpace(data.Name)
+	if name == "" {
+		return nil, "", ErrTokenNameEmpty
+	}
+	if len(data.Scopes) == 0 {
+		return nil, "", ErrInvalidScope
+	}
+	for _, scope := range data.Scopes {
+		if _, ok := supportedScopes[scope]; !ok {
+			return nil, "", ErrInvalidScope
+		}
+		// Публичный токен виден посетителям сайта и не должен открывать основной API
+		if scope == domain.ScopeDirectoryRead && len(data.Scopes) > 1 {
+			return nil, "", ErrPublicScopeMixed
+		}
+	}
+	if data.ExpiresAt != nil && !data.ExpiresAt.After(timeutil.Now()) {
+		return nil, "", ErrInvalidExpiry
+	}
+	if data.GroupID != nil {
+		if _, err := uc.groupRepo.GetByID(ctx, *data.GroupID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, "", ErrTokenGroupMissing
+			}
+			return nil, "", err
+		}
+	}
+
+	raw, err := generateToken()
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to generate API token", slog.Any("error", err))
+		return nil, "", err
+	}
+
+	var expiresAt *time.Time
+	if data.ExpiresAt != nil {
+		utc := data.ExpiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	token, err := uc.tokenRepo.Create(ctx, &domain.APIToken{
+		Name:      name,
+		TokenHash: hashToken(raw),
+		Scopes:    strings.Join(data.Scopes, ","),
+		GroupID:   data.GroupID,
+		CreatedBy: data.CreatedBy,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to save API token", slog.Any("error", err))
+		return nil, "", err
+	}
+
+	uc.logger.InfoContext(ctx, "API token created", slog.Uint64("id", uint64(token.ID)), slog.String("scopes", token.Scopes), slog.Uint64("created_by", uint64(data.CreatedBy)))
+	return token, raw, nil
+}
+
+func (uc *tokenUseCase) GetAllTokens(ctx context.Context) ([]domain.APIToken, error) {
+	tokens, err := uc.tokenRepo.GetAll(ctx)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to get API tokens", slog.Any("error", err))
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (uc *tokenUseCase) RevokeToken(ctx context.Context, id uint) error {
+	if err := uc.tokenRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTokenNotFound
+		}
+		return err
+	}
+	uc.logger.InfoContext(ctx, "API token revoked", slog.Uint64("id", uint64(id)))
+	return nil
+}
+
+func (uc *tokenUseCase) Authenticate(ctx context.Context, rawToken, scope string) (*domain.APIToken, error) {
+	if !strings.HasPrefix(rawToken, TokenPrefix) {
+		return nil, ErrTokenInvalid
+	}
+
+	token, err := uc.tokenRepo.GetByHash(ctx, hashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTokenInvalid
+		}
+		return nil, err
+	}
+
+	now := timeutil.Now()
+	if token.ExpiresAt != nil && now.After(*token.ExpiresAt) {
+		return nil, ErrTokenExpired
+	}
+	if !token.HasScope(scope) {
+		uc.logger.WarnContext(ctx, "API token used outside of its scope", slog.Uint64("id", uint64(token.ID)), slog.String("scope", scope))
+		return nil, ErrScopeDenied
+	}
+
+	_ = uc.tokenRepo.TouchLastUsed(ctx, token.ID, now)
+	return token, nil
+}
+
+// generateToken генерирует случайный токен с префиксом TokenPrefix
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return TokenPrefix + hex.EncodeToString(buf), nil
+}
+
+// hashToken возвращает SHA-256 хеш токена для хранения в БД
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
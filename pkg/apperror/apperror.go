@@ -0,0 +1,131 @@
+// Package apperror описывает доменные ошибки с видом, машиночитаемым кодом и исходной причиной.
+// Вид определяет HTTP-статус ответа, поэтому обработчикам не нужно перечислять ошибки модуля по одной.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Kind - вид ошибки
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindInvalid
+	KindForbidden
+	KindUnauthorized
+	KindUnavailable
+)
+
+// Error - доменная ошибка. Значения, объявленные в пакетах usecase, служат образцами для errors.Is:
+// ошибка совпадает с образцом, если совпадают коды, даже если к ней добавлена причина через Wrap.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	cause   error
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is сравнивает ошибки по коду
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap возвращает копию ошибки с причиной cause; образец не изменяется
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.cause = cause
+	return &wrapped
+}
+
+func newError(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// Internal - сбой, о котором клиенту сообщается без подробностей; причина добавляется через Wrap для журнала
+func Internal(code, message string) *Error {
+	return newError(KindInternal, code, message)
+}
+
+// NotFound - запрошенный объект не существует
+func NotFound(code, message string) *Error {
+	return newError(KindNotFound, code, message)
+}
+
+// Conflict - операция противоречит текущему состоянию, например нарушает уникальность
+func Conflict(code, message string) *Error {
+	return newError(KindConflict, code, message)
+}
+
+// Invalid - некорректные входные данные
+func Invalid(code, message string) *Error {
+	return newError(KindInvalid, code, message)
+}
+
+// Forbidden - у пользователя нет прав на операцию
+func Forbidden(code, message string) *Error {
+	return newError(KindForbidden, code, message)
+}
+
+// Unauthorized - пользователь не аутентифицирован или сессия недействительна
+func Unauthorized(code, message string) *Error {
+	return newError(KindUnauthorized, code, message)
+}
+
+// Unavailable - зависимость временно недоступна; клиент может повторить запрос позже
+func Unavailable(code, message string) *Error {
+	return newError(KindUnavailable, code, message)
+}
+
+// KindOf возвращает вид первой доменной ошибки в цепочке err; KindInternal, если ее нет
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// CodeOf возвращает код первой доменной ошибки в цепочке err; пустая строка, если ее нет
+func CodeOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// HTTPStatus возвращает HTTP-статус для ошибки err; 500 для ошибок без вида
+func HTTPStatus(err error) int {
+	switch KindOf(err) {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindInvalid:
+		return http.StatusBadRequest
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,100 @@
+// Package circuitbreaker размыкает вызовы недоступной зависимости: после нескольких сбоев подряд
+// вызовы сразу завершаются ошибкой ErrOpen, а не ждут таймаута, пока зависимость не восстановится.
+package circuitbreaker
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrOpen возвращается вместо вызова, пока цепь разомкнута
+var ErrOpen = errors.New("circuit breaker is open")
+
+// Breaker - автомат с тремя состояниями. Замкнут: вызовы выполняются, сбои подряд считаются.
+// Разомкнут после threshold сбоев подряд: вызовы отклоняются в течение cooldown.
+// Затем пропускается один пробный вызов: успех замыкает цепь, сбой снова размыкает ее.
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	isFailure func(error) bool
+	logger    *slog.Logger
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time // Нулевое - цепь замкнута
+	probing  bool      // Пробный вызов выполняется
+}
+
+// New создает замкнутый Breaker. isFailure определяет, какие ошибки вызова считаются сбоем зависимости
+// (ошибки вроде "не найдено" - обычный ответ); nil - любая ошибка. name используется в логе.
+func New(name string, threshold int, cooldown time.Duration, isFailure func(error) bool, logger *slog.Logger) *Breaker {
+	if isFailure == nil {
+		isFailure = func(err error) bool { return err != nil }
+	}
+	return &Breaker{
+		name:      name,
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+		isFailure: isFailure,
+		logger:    logger,
+	}
+}
+
+// Do выполняет fn, если цепь замкнута или настало время пробного вызова; иначе возвращает ErrOpen
+func (b *Breaker) Do(fn func() error) error {
+	if !b.allow() {
+		return ErrOpen
+	}
+	err := fn()
+	b.record(err != nil && b.isFailure(err))
+	return err
+}
+
+// Open сообщает, разомкнута ли цепь сейчас
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.openedAt.IsZero()
+}
+
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return true
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *Breaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasOpen := !b.openedAt.IsZero()
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		if wasOpen {
+			b.openedAt = time.Time{}
+			b.logger.Info("Circuit breaker closed", slog.String("name", b.name))
+		}
+		return
+	}
+
+	b.failures++
+	if wasOpen {
+		// Пробный вызов не удался: ждем следующий cooldown
+		b.openedAt = time.Now()
+		return
+	}
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		b.logger.Warn("Circuit breaker opened", slog.String("name", b.name), slog.Int("failures", b.failures), slog.Duration("cooldown", b.cooldown))
+	}
+}
@@ -0,0 +1,213 @@
+// Package crypto шифрует чувствительные поля на уровне приложения (AES-256-GCM)
+// и строит детерминированные индексы для поиска по зашифрованным значениям.
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// KeySize - длина ключа AES-256 в байтах
+const KeySize = 32
+
+// prefix отличает зашифрованные значения от открытого текста, записанного до включения шифрования.
+// Формат: enc:<ID ключа>:<base64(nonce || шифртекст)>.
+const prefix = "enc:"
+
+var (
+	ErrInvalidKey     = errors.New("encryption key must be 32 bytes encoded in base64")
+	ErrUnknownKey     = errors.New("value is encrypted with an unknown key")
+	ErrMalformedValue = errors.New("malformed encrypted value")
+)
+
+type key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// Cipher шифрует значения текущим ключом и расшифровывает текущим или одним из предыдущих.
+// Без ключа шифрование отключено: значения хранятся открыто, индексы строятся без секрета.
+type Cipher struct {
+	current  *key
+	keys     map[string]*key
+	indexKey []byte
+}
+
+// ParseKey декодирует ключ из base64. Пустая строка - ключ не задан.
+func ParseKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	return raw, nil
+}
+
+// GenerateKey создает случайный ключ в base64 для ENCRYPTION_KEY.
+func GenerateKey() (string, error) {
+	raw := make([]byte, KeySize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// NewCipher создает Cipher с текущим ключом current и предыдущими ключами previous,
+// которые нужны только для расшифровки значений до ротации. nil current отключает шифрование:
+// новые значения пишутся открыто, а предыдущие ключи позволяют расшифровать существующие.
+func NewCipher(current []byte, previous ...[]byte) (*Cipher, error) {
+	c := &Cipher{keys: map[string]*key{}}
+	for _, raw := range previous {
+		k, err := newKey(raw)
+		if err != nil {
+			return nil, err
+		}
+		c.keys[k.id] = k
+	}
+	if current == nil {
+		return c, nil
+	}
+	k, err := newKey(current)
+	if err != nil {
+		return nil, err
+	}
+	c.keys[k.id] = k
+	c.current = k
+
+	mac := hmac.New(sha256.New, current)
+	mac.Write([]byte("rim blind index"))
+	c.indexKey = mac.Sum(nil)
+	return c, nil
+}
+
+// NewCipherFromBase64 создает Cipher из ключей в base64 (ENCRYPTION_KEY и ENCRYPTION_PREVIOUS_KEYS).
+func NewCipherFromBase64(current string, previous []string) (*Cipher, error) {
+	currentKey, err := ParseKey(current)
+	if err != nil {
+		return nil, err
+	}
+	previousKeys := make([][]byte, 0, len(previous))
+	for _, encoded := range previous {
+		k, err := ParseKey(encoded)
+		if err != nil {
+			return nil, err
+		}
+		previousKeys = append(previousKeys, k)
+	}
+	return NewCipher(currentKey, previousKeys...)
+}
+
+func newKey(raw []byte) (*key, error) {
+	if len(raw) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(raw)
+	return &key{id: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
+
+// Enabled сообщает, задан ли ключ шифрования.
+func (c *Cipher) Enabled() bool {
+	return c.current != nil
+}
+
+// Encrypt шифрует значение текущим ключом. Пустая строка не шифруется.
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	if !c.Enabled() || plaintext == "" {
+		return plaintext, nil
+	}
+	nonce := make([]byte, c.current.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.current.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + c.current.id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt расшифровывает значение. Значения без префикса enc: возвращаются как есть.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, nil
+	}
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", ErrMalformedValue
+	}
+	k, ok := c.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < k.aead.NonceSize() {
+		return "", ErrMalformedValue
+	}
+	nonceSize := k.aead.NonceSize()
+	plaintext, err := k.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", ErrMalformedValue
+	}
+	return string(plaintext), nil
+}
+
+// IsCurrent сообщает, записано ли значение в том виде, в котором его записал бы Encrypt:
+// зашифровано текущим ключом, а при отключенном шифровании - открыто.
+func (c *Cipher) IsCurrent(value string) bool {
+	if value == "" {
+		return true
+	}
+	if !c.Enabled() {
+		return !strings.HasPrefix(value, prefix)
+	}
+	return strings.HasPrefix(value, prefix+c.current.id+":")
+}
+
+// BlindIndex возвращает детерминированный HMAC значения для поиска и уникальных индексов.
+// Индекс зависит от текущего ключа, поэтому после ротации пересчитывается.
+func (c *Cipher) BlindIndex(value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WordIndex возвращает индексы (BlindIndex) слов текста через пробел, без повторов. По нему зашифрованный
+// текст ищется по целым словам: слово запроса, приведенное Words, совпадает с одним из индексов.
+func (c *Cipher) WordIndex(text string) string {
+	var hashes []string
+	for _, word := range Words(text) {
+		if hash := c.BlindIndex(word); !slices.Contains(hashes, hash) {
+			hashes = append(hashes, hash)
+		}
+	}
+	return strings.Join(hashes, " ")
+}
+
+// Words разбивает текст на слова для WordIndex: буквы и цифры в нижнем регистре, ё заменяется на е
+func Words(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
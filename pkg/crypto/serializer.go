@@ -0,0 +1,54 @@
+package crypto
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName - имя сериализатора GORM для строковых полей: `gorm:"serializer:encrypted"`
+const SerializerName = "encrypted"
+
+// Serializer прозрачно шифрует строковое поле при записи и расшифровывает при чтении.
+// Условия WHERE по таким полям не работают: для поиска используется BlindIndex.
+type Serializer struct {
+	cipher *Cipher
+}
+
+// RegisterSerializer регистрирует сериализатор encrypted в GORM.
+// Должен вызываться до первого обращения к моделям с такими полями.
+func RegisterSerializer(c *Cipher) {
+	schema.RegisterSerializer(SerializerName, Serializer{cipher: c})
+}
+
+// Scan расшифровывает значение из БД.
+func (s Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("unsupported type %T for encrypted field %s", dbValue, field.Name)
+	}
+
+	plaintext, err := s.cipher.Decrypt(value)
+	if err != nil {
+		return fmt.Errorf("decrypt field %s: %w", field.Name, err)
+	}
+	field.ReflectValueOf(ctx, dst).SetString(plaintext)
+	return nil
+}
+
+// Value шифрует значение перед записью в БД.
+func (s Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %T for encrypted field %s", fieldValue, field.Name)
+	}
+	return s.cipher.Encrypt(plaintext)
+}
@@ -0,0 +1,331 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+
+	"rim/pkg/crypto"
+
+	"gorm.io/gorm"
+)
+
+// anonymizeBatchSize - сколько строк читается за один запрос при обезличивании
+const anonymizeBatchSize = 200
+
+// fakeTelegramIDBase - начало диапазона поддельных Telegram ID; настоящие ID на порядки меньше,
+// поэтому новые значения не пересекаются с еще не переписанными
+const fakeTelegramIDBase = 1_000_000_000_000
+
+var (
+	fakeMaleNames   = []string{"Алексей", "Дмитрий", "Иван", "Сергей", "Павел", "Андрей"}
+	fakeFemaleNames = []string{"Мария", "Анна", "Екатерина", "Ольга", "Наталья", "Елена"}
+	fakeLastNames   = []string{"Иванов", "Смирнов", "Кузнецов", "Попов", "Соколов", "Лебедев", "Новиков", "Морозов", "Волков", "Федоров"} // Женская форма - с окончанием "а"
+	fakeAllergies   = []string{"Орехи", "Лактоза", "Глютен", "Цитрусовые", "Пыльца", "Мед"}
+)
+
+// AnonymizeReport - сколько записей переписано и удалено при обезличивании
+type AnonymizeReport struct {
+	Contacts       int
+	Users          int
+	ChangeRequests int
+	ImportRows     int
+	// Удаленные сессии, коды входа, устройства и подписки Web Push
+	Deleted map[string]int64
+}
+
+// Write выводит отчет об обезличивании в w.
+func (r *AnonymizeReport) Write(w io.Writer) {
+	fmt.Fprintf(w, "Contacts anonymized: %d\n", r.Contacts)
+	fmt.Fprintf(w, "Users anonymized: %d\n", r.Users)
+	fmt.Fprintf(w, "Change requests anonymized: %d\n", r.ChangeRequests)
+	fmt.Fprintf(w, "Import rows anonymized: %d\n", r.ImportRows)
+	tables := make([]string, 0, len(r.Deleted))
+	for table := range r.Deleted {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		fmt.Fprintf(w, "Rows deleted from %s: %d\n", table, r.Deleted[table])
+	}
+}
+
+// anonymizer сопоставляет исходным значениям поддельные. Одинаковые значения получают одинаковую замену,
+// поэтому контакт, его пользователь, заявки на изменение и строки импорта остаются согласованными.
+type anonymizer struct {
+	names, phones, emails, telegrams, vks, allergies map[string]string
+	telegramIDs                                      map[int64]int64
+}
+
+func newAnonymizer() *anonymizer {
+	return &anonymizer{
+		names:       map[string]string{},
+		phones:      map[string]string{},
+		emails:      map[string]string{},
+		telegrams:   map[string]string{},
+		vks:         map[string]string{},
+		allergies:   map[string]string{},
+		telegramIDs: map[int64]int64{},
+	}
+}
+
+// fake возвращает замену original из values, создавая новую через generate(n), где n - номер замены с 1.
+// Пустые значения остаются пустыми.
+func fake(values map[string]string, original string, generate func(n int) string) string {
+	if original == "" {
+		return ""
+	}
+	if replacement, ok := values[original]; ok {
+		return replacement
+	}
+	replacement := generate(len(values) + 1)
+	values[original] = replacement
+	return replacement
+}
+
+func (a *anonymizer) name(original string) string {
+	return fake(a.names, original, func(n int) string {
+		first, last := fakeMaleNames[(n/2)%len(fakeMaleNames)], fakeLastNames[(n/2/len(fakeMaleNames))%len(fakeLastNames)]
+		if n%2 == 1 {
+			first, last = fakeFemaleNames[(n/2)%len(fakeFemaleNames)], last+"а"
+		}
+		if n >= 2*len(fakeMaleNames)*len(fakeLastNames) {
+			return fmt.Sprintf("%s %s %d", first, last, n)
+		}
+		return first + " " + last
+	})
+}
+
+// phone возвращает номер +7000XXXXXXX: код 000 не выдается операторам, но проходит проверку e164
+func (a *anonymizer) phone(original string) string {
+	return fake(a.phones, original, func(n int) string { return fmt.Sprintf("+7000%07d", n) })
+}
+
+func (a *anonymizer) email(original string) string {
+	return fake(a.emails, original, func(n int) string { return fmt.Sprintf("contact%d@example.com", n) })
+}
+
+func (a *anonymizer) telegram(original string) string {
+	return fake(a.telegrams, original, func(n int) string { return fmt.Sprintf("@anon_user%d", n) })
+}
+
+func (a *anonymizer) vk(original string) string {
+	return fake(a.vks, original, func(n int) string { return fmt.Sprintf("https://vk.com/anon%d", n) })
+}
+
+func (a *anonymizer) allergy(original string) string {
+	return fake(a.allergies, original, func(n int) string { return fakeAllergies[(n-1)%len(fakeAllergies)] })
+}
+
+func (a *anonymizer) telegramID(original int64) int64 {
+	if original == 0 {
+		return 0
+	}
+	if replacement, ok := a.telegramIDs[original]; ok {
+		return replacement
+	}
+	replacement := fakeTelegramIDBase + int64(len(a.telegramIDs)+1)
+	a.telegramIDs[original] = replacement
+	return replacement
+}
+
+// fields переписывает персональные данные в JSON-объекте "поле -> значение" заявок и строк импорта
+func (a *anonymizer) fields(values map[string]string) {
+	for field, value := range values {
+		switch field {
+		case "name":
+			values[field] = a.name(value)
+		case "phone":
+			values[field] = a.phone(value)
+		case "email":
+			values[field] = a.email(value)
+		case "telegram":
+			values[field] = a.telegram(value)
+		case "vk":
+			values[field] = a.vk(value)
+		case "allergies":
+			values[field] = a.allergy(value)
+		}
+	}
+}
+
+// anonymizedContactRow - столбцы контакта с персональными данными в том виде, в котором они хранятся в БД
+type anonymizedContactRow struct {
+	ID         uint
+	Name       string
+	Phone      string
+	Email      string
+	Allergies  string
+	VK         string `gorm:"column:vk"`
+	Telegram   string
+	TelegramID int64
+}
+
+type anonymizedUserRow struct {
+	ID         uint
+	TelegramID int64
+}
+
+// fieldMapRow - строка заявки на изменение (changes, previous) или строки импорта (data)
+type fieldMapRow struct {
+	ID       uint
+	Changes  string
+	Previous string
+	Data     string
+}
+
+func (r fieldMapRow) column(name string) string {
+	switch name {
+	case "changes":
+		return r.Changes
+	case "previous":
+		return r.Previous
+	default:
+		return r.Data
+	}
+}
+
+// Anonymize необратимо заменяет персональные данные в БД согласованными поддельными значениями, чтобы копию
+// рабочей базы можно было использовать для разработки и демонстраций. Переписываются имена, телефоны, email,
+// Telegram, VK и аллергии контактов (включая удаленные), Telegram ID и фото пользователей, значения полей
+// в заявках на изменение и строках импорта. Сессии, коды входа, устройства и подписки Web Push удаляются.
+// Все изменения выполняются в одной транзакции.
+func Anonymize(db *gorm.DB, cipher *crypto.Cipher, logger *slog.Logger) (*AnonymizeReport, error) {
+	report := &AnonymizeReport{Deleted: map[string]int64{}}
+	a := newAnonymizer()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var contacts []anonymizedContactRow
+		err := tx.Table("contacts").Select("id, name, phone, email, allergies, vk, telegram, telegram_id").Order("id").
+			FindInBatches(&contacts, anonymizeBatchSize, func(_ *gorm.DB, _ int) error {
+				for _, row := range contacts {
+					changes, err := anonymizeContact(a, cipher, row)
+					if err != nil {
+						logger.Error("Failed to anonymize contact", slog.Uint64("contactID", uint64(row.ID)), slog.Any("error", err))
+						return err
+					}
+					if err := tx.Table("contacts").Where("id = ?", row.ID).UpdateColumns(changes).Error; err != nil {
+						return err
+					}
+					report.Contacts++
+				}
+				return nil
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		var users []anonymizedUserRow
+		err = tx.Table("users").Select("id, telegram_id").Order("id").
+			FindInBatches(&users, anonymizeBatchSize, func(_ *gorm.DB, _ int) error {
+				for _, row := range users {
+					changes := map[string]interface{}{"telegram_id": a.telegramID(row.TelegramID), "photo_url": ""}
+					if err := tx.Table("users").Where("id = ?", row.ID).UpdateColumns(changes).Error; err != nil {
+						return err
+					}
+					report.Users++
+				}
+				return nil
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		if report.ChangeRequests, err = anonymizeFieldMaps(tx, a, "contact_change_requests", "changes", "previous"); err != nil {
+			return err
+		}
+		if report.ImportRows, err = anonymizeFieldMaps(tx, a, "import_rows", "data"); err != nil {
+			return err
+		}
+
+		for _, table := range []string{"user_sessions", "login_codes", "user_devices", "push_subscriptions"} {
+			if !tx.Migrator().HasTable(table) {
+				continue
+			}
+			result := tx.Exec("DELETE FROM " + table)
+			if result.Error != nil {
+				return result.Error
+			}
+			report.Deleted[table] = result.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Anonymization failed, database left unchanged", slog.Any("error", err))
+		return nil, err
+	}
+	return report, nil
+}
+
+// anonymizeContact возвращает новые значения столбцов контакта; телефон и аллергии шифруются текущим ключом
+func anonymizeContact(a *anonymizer, cipher *crypto.Cipher, row anonymizedContactRow) (map[string]interface{}, error) {
+	phone, err := cipher.Decrypt(row.Phone)
+	if err != nil {
+		return nil, err
+	}
+	allergies, err := cipher.Decrypt(row.Allergies)
+	if err != nil {
+		return nil, err
+	}
+
+	fakePhone, fakeAllergies := a.phone(phone), a.allergy(allergies)
+	changes := map[string]interface{}{
+		"name":        a.name(row.Name),
+		"email":       a.email(row.Email),
+		"vk":          a.vk(row.VK),
+		"telegram":    a.telegram(row.Telegram),
+		"telegram_id": a.telegramID(row.TelegramID),
+		"phone_hash":  cipher.BlindIndex(fakePhone),
+
+		"allergy_index": cipher.WordIndex(fakeAllergies),
+	}
+	if changes["phone"], err = cipher.Encrypt(fakePhone); err != nil {
+		return nil, err
+	}
+	if changes["allergies"], err = cipher.Encrypt(fakeAllergies); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// anonymizeFieldMaps переписывает JSON-объекты "поле -> значение" в столбцах columns таблицы table.
+// Возвращает число обработанных строк; отсутствующая таблица пропускается.
+func anonymizeFieldMaps(tx *gorm.DB, a *anonymizer, table string, columns ...string) (int, error) {
+	if !tx.Migrator().HasTable(table) {
+		return 0, nil
+	}
+	processed := 0
+	var rows []fieldMapRow
+	err := tx.Table(table).Select(append([]string{"id"}, columns...)).Order("id").
+		FindInBatches(&rows, anonymizeBatchSize, func(_ *gorm.DB, _ int) error {
+			for _, row := range rows {
+				changes := map[string]interface{}{}
+				for _, column := range columns {
+					values := map[string]string{}
+					if raw := row.column(column); raw != "" {
+						_ = json.Unmarshal([]byte(raw), &values)
+					}
+					if len(values) == 0 {
+						continue
+					}
+					a.fields(values)
+					encoded, err := json.Marshal(values)
+					if err != nil {
+						return err
+					}
+					changes[column] = string(encoded)
+				}
+				processed++
+				if len(changes) == 0 {
+					continue
+				}
+				if err := tx.Table(table).Where("id = ?", row.ID).UpdateColumns(changes).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	return processed, err
+}
@@ -0,0 +1,218 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"rim/pkg/crypto"
+
+	"gorm.io/gorm"
+)
+
+// ErrDatabaseCorrupted возвращается, если PRAGMA integrity_check нашел повреждения файла БД
+var ErrDatabaseCorrupted = errors.New("database integrity check failed")
+
+// OrphanMembership - строка contact_groups, ссылающаяся на несуществующий контакт или группу
+type OrphanMembership struct {
+	GroupID   uint
+	ContactID uint
+}
+
+// OrphanUserContact - пользователь, привязанный к несуществующему контакту
+type OrphanUserContact struct {
+	UserID    uint
+	ContactID uint
+}
+
+// DuplicatePhone - неудаленные контакты с одинаковым телефоном после нормализации
+type DuplicatePhone struct {
+	Phone      string // Нормализованный телефон, видны только последние 4 цифры
+	ContactIDs []uint
+}
+
+// IntegrityReport - результат проверки целостности БД
+type IntegrityReport struct {
+	IntegrityErrors       []string // Сообщения PRAGMA integrity_check
+	OrphanMemberships     []OrphanMembership
+	OrphanUserContacts    []OrphanUserContact
+	DuplicatePhones       []DuplicatePhone
+	UndecryptableContacts []uint // Контакты, телефон которых не расшифровывается текущими ключами
+	Fixed                 bool   // Висячие ссылки удалены
+}
+
+// OK сообщает, что проблем не найдено (или найденные висячие ссылки исправлены).
+func (r *IntegrityReport) OK() bool {
+	orphansLeft := !r.Fixed && (len(r.OrphanMemberships) > 0 || len(r.OrphanUserContacts) > 0)
+	return len(r.IntegrityErrors) == 0 && !orphansLeft && len(r.DuplicatePhones) == 0 && len(r.UndecryptableContacts) == 0
+}
+
+// Write выводит отчет в текстовом виде.
+func (r *IntegrityReport) Write(w io.Writer) {
+	if len(r.IntegrityErrors) == 0 {
+		fmt.Fprintln(w, "PRAGMA integrity_check: ok")
+	} else {
+		fmt.Fprintf(w, "PRAGMA integrity_check: %d problem(s)\n", len(r.IntegrityErrors))
+		for _, msg := range r.IntegrityErrors {
+			fmt.Fprintf(w, "  %s\n", msg)
+		}
+	}
+
+	fixed := ""
+	if r.Fixed {
+		fixed = " (removed)"
+	}
+	fmt.Fprintf(w, "Orphan contact_groups rows: %d%s\n", len(r.OrphanMemberships), fixed)
+	for _, m := range r.OrphanMemberships {
+		fmt.Fprintf(w, "  group_id=%d contact_id=%d\n", m.GroupID, m.ContactID)
+	}
+
+	if r.Fixed {
+		fixed = " (unlinked)"
+	}
+	fmt.Fprintf(w, "Users linked to missing contacts: %d%s\n", len(r.OrphanUserContacts), fixed)
+	for _, u := range r.OrphanUserContacts {
+		fmt.Fprintf(w, "  user_id=%d contact_id=%d\n", u.UserID, u.ContactID)
+	}
+
+	fmt.Fprintf(w, "Duplicate phones among active contacts: %d\n", len(r.DuplicatePhones))
+	for _, d := range r.DuplicatePhones {
+		fmt.Fprintf(w, "  %s: contact_ids=%v\n", d.Phone, d.ContactIDs)
+	}
+
+	if len(r.UndecryptableContacts) > 0 {
+		fmt.Fprintf(w, "Contacts with phones encrypted by an unknown key: %v\n", r.UndecryptableContacts)
+	}
+}
+
+// CheckIntegrity проверяет файл БД и ссылочную целостность contact_groups и users.contact_id,
+// ищет дубликаты телефонов среди неудаленных контактов. Если fix = true, висячие строки
+// contact_groups удаляются, а пользователи отвязываются от несуществующих контактов.
+// Дубликаты телефонов только выводятся: какой контакт оставить, решает администратор.
+// Проверки таблиц, которых еще нет (первый запуск), пропускаются.
+func CheckIntegrity(db *gorm.DB, cipher *crypto.Cipher, fix bool, logger *slog.Logger) (*IntegrityReport, error) {
+	report := &IntegrityReport{}
+
+	var messages []string
+	if err := db.Raw("PRAGMA integrity_check").Scan(&messages).Error; err != nil {
+		logger.Error("Failed to run integrity check", slog.Any("error", err))
+		return nil, err
+	}
+	for _, msg := range messages {
+		if msg != "ok" {
+			report.IntegrityErrors = append(report.IntegrityErrors, msg)
+		}
+	}
+
+	migrator := db.Migrator()
+	hasContacts := migrator.HasTable("contacts")
+
+	if hasContacts && migrator.HasTable("contact_groups") && migrator.HasTable("groups") {
+		if err := db.Table("contact_groups").Select("group_id, contact_id").
+			Where("contact_id NOT IN (SELECT id FROM contacts) OR group_id NOT IN (SELECT id FROM groups)").
+			Order("group_id, contact_id").
+			Scan(&report.OrphanMemberships).Error; err != nil {
+			logger.Error("Failed to find orphan contact_groups rows", slog.Any("error", err))
+			return nil, err
+		}
+	}
+
+	if hasContacts && migrator.HasTable("users") {
+		if err := db.Table("users").Select("id AS user_id, contact_id").
+			Where("contact_id IS NOT NULL AND contact_id NOT IN (SELECT id FROM contacts)").
+			Order("id").
+			Scan(&report.OrphanUserContacts).Error; err != nil {
+			logger.Error("Failed to find users linked to missing contacts", slog.Any("error", err))
+			return nil, err
+		}
+	}
+
+	if hasContacts {
+		if err := findDuplicatePhones(db, cipher, report); err != nil {
+			logger.Error("Failed to find duplicate phones", slog.Any("error", err))
+			return nil, err
+		}
+	}
+
+	if fix && (len(report.OrphanMemberships) > 0 || len(report.OrphanUserContacts) > 0) {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("DELETE FROM contact_groups WHERE contact_id NOT IN (SELECT id FROM contacts) OR group_id NOT IN (SELECT id FROM groups)").Error; err != nil {
+				return err
+			}
+			return tx.Exec("UPDATE users SET contact_id = NULL WHERE contact_id IS NOT NULL AND contact_id NOT IN (SELECT id FROM contacts)").Error
+		})
+		if err != nil {
+			logger.Error("Failed to fix orphan references", slog.Any("error", err))
+			return nil, err
+		}
+		report.Fixed = true
+		logger.Info("Orphan references fixed",
+			slog.Int("contactGroups", len(report.OrphanMemberships)), slog.Int("users", len(report.OrphanUserContacts)))
+	}
+
+	return report, nil
+}
+
+// findDuplicatePhones группирует неудаленные контакты по нормализованному телефону.
+// Телефоны зашифрованы, поэтому сравниваются после расшифровки в памяти.
+func findDuplicatePhones(db *gorm.DB, cipher *crypto.Cipher, report *IntegrityReport) error {
+	var rows []struct {
+		ID    uint
+		Phone string
+	}
+	if err := db.Table("contacts").Select("id, phone").Where("deleted_at IS NULL").Order("id").Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	byPhone := map[string][]uint{}
+	for _, row := range rows {
+		phone, err := cipher.Decrypt(row.Phone)
+		if err != nil {
+			report.UndecryptableContacts = append(report.UndecryptableContacts, row.ID)
+			continue
+		}
+		if normalized := normalizePhone(phone); normalized != "" {
+			byPhone[normalized] = append(byPhone[normalized], row.ID)
+		}
+	}
+
+	for phone, ids := range byPhone {
+		if len(ids) > 1 {
+			report.DuplicatePhones = append(report.DuplicatePhones, DuplicatePhone{Phone: maskPhone(phone), ContactIDs: ids})
+		}
+	}
+	sort.Slice(report.DuplicatePhones, func(i, j int) bool {
+		return report.DuplicatePhones[i].ContactIDs[0] < report.DuplicatePhones[j].ContactIDs[0]
+	})
+	return nil
+}
+
+// normalizePhone оставляет в телефоне только цифры и приводит российские номера к виду 7XXXXXXXXXX:
+// "8 (999) 000-11-22", "+7 999 000 11 22" и "9990001122" считаются одним номером.
+func normalizePhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	normalized := digits.String()
+	switch {
+	case len(normalized) == 11 && normalized[0] == '8':
+		normalized = "7" + normalized[1:]
+	case len(normalized) == 10 && normalized[0] == '9':
+		normalized = "7" + normalized
+	}
+	return normalized
+}
+
+// maskPhone скрывает все цифры телефона, кроме последних четырех, чтобы отчет не раскрывал персональные данные.
+func maskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return phone
+	}
+	return strings.Repeat("*", len(phone)-4) + phone[len(phone)-4:]
+}
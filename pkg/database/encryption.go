@@ -0,0 +1,143 @@
+package database
+
+import (
+	"log/slog"
+
+	"rim/pkg/crypto"
+
+	"gorm.io/gorm"
+)
+
+// encryptionBatchSize - сколько контактов перешифровывается в одной транзакции
+const encryptionBatchSize = 200
+
+// encryptedContactRow - зашифрованные столбцы контакта в том виде, в котором они хранятся в БД.
+// Чтение через Table("contacts") обходит сериализатор encrypted.
+type encryptedContactRow struct {
+	ID             uint
+	Phone          string
+	Allergies      string
+	EmergencyPhone string
+	PhoneHash      string
+	AllergyIndex   string
+}
+
+// EncryptContacts приводит телефоны, аллергии и телефоны для экстренной связи всех контактов, включая удаленные,
+// к текущему ключу cipher: шифрует открытые значения, перешифровывает значения предыдущих ключей
+// и пересчитывает phone_hash и allergy_index.
+// Возвращает число обновленных контактов. Повторный запуск не меняет уже обработанные записи.
+func EncryptContacts(db *gorm.DB, cipher *crypto.Cipher, logger *slog.Logger) (int, error) {
+	updated := 0
+	var rows []encryptedContactRow
+	err := db.Table("contacts").Select("id, phone, allergies, emergency_phone, phone_hash, allergy_index").Order("id").
+		FindInBatches(&rows, encryptionBatchSize, func(_ *gorm.DB, _ int) error {
+			return db.Transaction(func(tx *gorm.DB) error {
+				for _, row := range rows {
+					changes, err := reencryptContact(cipher, row)
+					if err != nil {
+						logger.Error("Failed to decrypt contact", slog.Uint64("contactID", uint64(row.ID)), slog.Any("error", err))
+						return err
+					}
+					if len(changes) == 0 {
+						continue
+					}
+					if err := tx.Table("contacts").Where("id = ?", row.ID).UpdateColumns(changes).Error; err != nil {
+						logger.Error("Failed to re-encrypt contact", slog.Uint64("contactID", uint64(row.ID)), slog.Any("error", err))
+						return err
+					}
+					updated++
+				}
+				return nil
+			})
+		}).Error
+	if err != nil {
+		return updated, err
+	}
+	if updated > 0 {
+		logger.Info("Contacts re-encrypted with the current key", slog.Int("count", updated), slog.Bool("encryptionEnabled", cipher.Enabled()))
+	}
+	return updated, nil
+}
+
+// encryptedHistoryRow - зашифрованный столбец записи истории изменений контакта в том виде, в котором он хранится в БД
+type encryptedHistoryRow struct {
+	ID      uint
+	Changes string
+}
+
+// EncryptContactHistory приводит изменения в истории контактов (прежние и новые телефоны и аллергии) к текущему
+// ключу cipher, как EncryptContacts. Возвращает число обновленных записей.
+func EncryptContactHistory(db *gorm.DB, cipher *crypto.Cipher, logger *slog.Logger) (int, error) {
+	updated := 0
+	var rows []encryptedHistoryRow
+	err := db.Table("contact_histories").Select("id, changes").Order("id").
+		FindInBatches(&rows, encryptionBatchSize, func(_ *gorm.DB, _ int) error {
+			return db.Transaction(func(tx *gorm.DB) error {
+				for _, row := range rows {
+					changes := map[string]interface{}{}
+					if _, err := reencryptColumn(cipher, changes, "changes", row.Changes); err != nil {
+						logger.Error("Failed to decrypt contact history", slog.Uint64("historyID", uint64(row.ID)), slog.Any("error", err))
+						return err
+					}
+					if len(changes) == 0 {
+						continue
+					}
+					if err := tx.Table("contact_histories").Where("id = ?", row.ID).UpdateColumns(changes).Error; err != nil {
+						logger.Error("Failed to re-encrypt contact history", slog.Uint64("historyID", uint64(row.ID)), slog.Any("error", err))
+						return err
+					}
+					updated++
+				}
+				return nil
+			})
+		}).Error
+	if err != nil {
+		return updated, err
+	}
+	if updated > 0 {
+		logger.Info("Contact history re-encrypted with the current key", slog.Int("count", updated), slog.Bool("encryptionEnabled", cipher.Enabled()))
+	}
+	return updated, nil
+}
+
+// reencryptContact возвращает столбцы контакта, которые нужно перезаписать, или пустой набор.
+func reencryptContact(cipher *crypto.Cipher, row encryptedContactRow) (map[string]interface{}, error) {
+	changes := map[string]interface{}{}
+
+	phone, err := reencryptColumn(cipher, changes, "phone", row.Phone)
+	if err != nil {
+		return nil, err
+	}
+	if hash := cipher.BlindIndex(phone); hash != row.PhoneHash {
+		changes["phone_hash"] = hash
+	}
+
+	allergies, err := reencryptColumn(cipher, changes, "allergies", row.Allergies)
+	if err != nil {
+		return nil, err
+	}
+	// Индекс слов появился позже шифрования, поэтому заполняется и у контактов, не требующих перешифрования
+	if index := cipher.WordIndex(allergies); index != row.AllergyIndex {
+		changes["allergy_index"] = index
+	}
+
+	if _, err := reencryptColumn(cipher, changes, "emergency_phone", row.EmergencyPhone); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// reencryptColumn расшифровывает значение столбца column и, если оно зашифровано не текущим ключом,
+// добавляет в changes значение, зашифрованное текущим. Возвращает открытое значение.
+func reencryptColumn(cipher *crypto.Cipher, changes map[string]interface{}, column, value string) (string, error) {
+	plain, err := cipher.Decrypt(value)
+	if err != nil {
+		return "", err
+	}
+	if !cipher.IsCurrent(value) {
+		if changes[column], err = cipher.Encrypt(plain); err != nil {
+			return "", err
+		}
+	}
+	return plain, nil
+}
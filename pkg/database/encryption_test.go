@@ -0,0 +1,169 @@
+package database
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"rim/pkg/crypto"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newContactsDB создает БД только с зашифрованными столбцами контактов
+func newContactsDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "rim.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	err = db.Exec(`CREATE TABLE contacts (
+		id INTEGER PRIMARY KEY,
+		phone TEXT NOT NULL,
+		allergies TEXT,
+		emergency_phone TEXT,
+		phone_hash TEXT,
+		allergy_index TEXT
+	)`).Error
+	if err != nil {
+		t.Fatalf("create contacts: %v", err)
+	}
+	return db
+}
+
+func newTestCipher(t *testing.T, current []byte, previous ...[]byte) *crypto.Cipher {
+	t.Helper()
+	cipher, err := crypto.NewCipher(current, previous...)
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	return cipher
+}
+
+func mustEncrypt(t *testing.T, cipher *crypto.Cipher, value string) string {
+	t.Helper()
+	encrypted, err := cipher.Encrypt(value)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	return encrypted
+}
+
+func TestEncryptContactsRotatesKey(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, crypto.KeySize)
+	newKey := bytes.Repeat([]byte{2}, crypto.KeySize)
+	oldCipher := newTestCipher(t, oldKey)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	db := newContactsDB(t)
+	rows := []encryptedContactRow{
+		{
+			ID:             1,
+			Phone:          mustEncrypt(t, oldCipher, "+79990001122"),
+			Allergies:      mustEncrypt(t, oldCipher, "орехи"),
+			EmergencyPhone: mustEncrypt(t, oldCipher, "+79990005566"),
+			PhoneHash:      oldCipher.BlindIndex("+79990001122"),
+			AllergyIndex:   oldCipher.WordIndex("орехи"),
+		},
+		// Телефон для экстренной связи не заполнен
+		{ID: 2, Phone: mustEncrypt(t, oldCipher, "+79990003344"), PhoneHash: oldCipher.BlindIndex("+79990003344")},
+	}
+	if err := db.Table("contacts").Create(&rows).Error; err != nil {
+		t.Fatalf("insert contacts: %v", err)
+	}
+
+	// Ротация: прежний ключ остается только для расшифровки
+	rotated := newTestCipher(t, newKey, oldKey)
+	updated, err := EncryptContacts(db, rotated, logger)
+	if err != nil {
+		t.Fatalf("EncryptContacts: %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("updated = %d, want 2", updated)
+	}
+
+	// После удаления прежнего ключа все значения должны читаться новым
+	current := newTestCipher(t, newKey)
+	var stored []encryptedContactRow
+	if err := db.Table("contacts").Order("id").Find(&stored).Error; err != nil {
+		t.Fatalf("read contacts: %v", err)
+	}
+	want := []struct{ phone, allergies, emergencyPhone string }{
+		{"+79990001122", "орехи", "+79990005566"},
+		{"+79990003344", "", ""},
+	}
+	for i, row := range stored {
+		for column, pair := range map[string][2]string{
+			"phone":           {row.Phone, want[i].phone},
+			"allergies":       {row.Allergies, want[i].allergies},
+			"emergency_phone": {row.EmergencyPhone, want[i].emergencyPhone},
+		} {
+			if !current.IsCurrent(pair[0]) {
+				t.Errorf("contact %d: %s is not encrypted with the current key", row.ID, column)
+			}
+			plain, err := current.Decrypt(pair[0])
+			if err != nil {
+				t.Errorf("contact %d: decrypt %s: %v", row.ID, column, err)
+				continue
+			}
+			if plain != pair[1] {
+				t.Errorf("contact %d: %s = %q, want %q", row.ID, column, plain, pair[1])
+			}
+		}
+		if row.PhoneHash != current.BlindIndex(want[i].phone) {
+			t.Errorf("contact %d: phone_hash is not recomputed with the current key", row.ID)
+		}
+		if row.AllergyIndex != current.WordIndex(want[i].allergies) {
+			t.Errorf("contact %d: allergy_index is not recomputed with the current key", row.ID)
+		}
+	}
+
+	// Повторный запуск ничего не меняет
+	if updated, err := EncryptContacts(db, rotated, logger); err != nil || updated != 0 {
+		t.Errorf("second EncryptContacts = %d, %v; want 0, nil", updated, err)
+	}
+}
+
+func TestEncryptContactHistoryRotatesKey(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, crypto.KeySize)
+	newKey := bytes.Repeat([]byte{2}, crypto.KeySize)
+	oldCipher := newTestCipher(t, oldKey)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	db := newContactsDB(t)
+	if err := db.Exec(`CREATE TABLE contact_histories (id INTEGER PRIMARY KEY, changes TEXT NOT NULL DEFAULT '')`).Error; err != nil {
+		t.Fatalf("create contact_histories: %v", err)
+	}
+	const changes = `[{"field":"phone","old":"+79990001122","new":"+79990003344"}]`
+	rows := []encryptedHistoryRow{
+		{ID: 1, Changes: mustEncrypt(t, oldCipher, changes)},
+		{ID: 2}, // Удаление контакта: изменений нет
+	}
+	if err := db.Table("contact_histories").Create(&rows).Error; err != nil {
+		t.Fatalf("insert history: %v", err)
+	}
+
+	updated, err := EncryptContactHistory(db, newTestCipher(t, newKey, oldKey), logger)
+	if err != nil {
+		t.Fatalf("EncryptContactHistory: %v", err)
+	}
+	if updated != 1 {
+		t.Errorf("updated = %d, want 1", updated)
+	}
+
+	current := newTestCipher(t, newKey)
+	var stored encryptedHistoryRow
+	if err := db.Table("contact_histories").Where("id = ?", 1).Take(&stored).Error; err != nil {
+		t.Fatalf("read history: %v", err)
+	}
+	if !current.IsCurrent(stored.Changes) {
+		t.Fatal("changes are not encrypted with the current key")
+	}
+	if plain, err := current.Decrypt(stored.Changes); err != nil || plain != changes {
+		t.Errorf("changes = %q, %v; want %q", plain, err, changes)
+	}
+}
@@ -12,9 +12,15 @@ import (
 // NewRedisClient создает и настраивает нового клиента Redis.
 func NewRedisClient(cfg *config.Config, logger *slog.Logger) (*redis.Client, error) {
 	rdb := redis.NewClient(&redis.Options{
-		Addr:     cfg.RedisAddr,
-		Password: cfg.RedisPassword,
-		DB:       cfg.RedisDB,
+		Addr: cfg.RedisAddr,
+		// Пароль читается при каждом подключении, чтобы новые соединения использовали ротированный секрет
+		CredentialsProvider: func() (string, string) {
+			return "", cfg.RedisPassword.Get()
+		},
+		DB: cfg.RedisDB,
+		// Соединение с недоступным Redis не должно занимать запрос дольше таймаута операции
+		DialTimeout:           cfg.RedisTimeout,
+		ContextTimeoutEnabled: true,
 	})
 
 	// Проверяем соединение
@@ -2,32 +2,172 @@ package database
 
 import (
 	"log/slog"
+	"reflect"
+	"strings"
 
 	"rim/internal/config"
 	"rim/internal/domain"
+	"rim/pkg/crypto"
+	"rim/pkg/timeutil"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-// NewSQLiteConnection устанавливает соединение с базой данных SQLite.
-// Также выполняет автоматическую миграцию для моделей Contact и Group.
-func NewSQLiteConnection(cfg *config.Config, logger *slog.Logger) (*gorm.DB, error) {
-	db, err := gorm.Open(sqlite.Open(cfg.SQLitePath), &gorm.Config{})
+// OpenSQLite устанавливает соединение с базой данных SQLite без миграций.
+// cipher шифрует поля моделей с тегом serializer:encrypted.
+func OpenSQLite(cfg *config.Config, cipher *crypto.Cipher, logger *slog.Logger) (*gorm.DB, error) {
+	// Сериализатор должен быть зарегистрирован до разбора схем моделей
+	crypto.RegisterSerializer(cipher)
+
+	// Все временные метки GORM (CreatedAt, UpdatedAt, DeletedAt) сохраняются в UTC
+	db, err := gorm.Open(sqlite.Open(cfg.SQLitePath), &gorm.Config{NowFunc: timeutil.Now})
 	if err != nil {
 		logger.Error("Failed to connect to SQLite", slog.String("path", cfg.SQLitePath), slog.Any("error", err))
 		return nil, err
 	}
 
 	logger.Info("Successfully connected to SQLite", slog.String("path", cfg.SQLitePath))
+	return db, nil
+}
+
+// NewSQLiteConnection устанавливает соединение с базой данных SQLite.
+// Перед автоматической миграцией моделей проверяет целостность БД: при повреждении файла
+// миграция не выполняется, остальные проблемы только логируются (исправляются командой rim check -fix).
+func NewSQLiteConnection(cfg *config.Config, cipher *crypto.Cipher, logger *slog.Logger) (*gorm.DB, error) {
+	db, err := OpenSQLite(cfg, cipher, logger)
+	if err != nil {
+		return nil, err
+	}
 
-	// Выполняем автомиграцию для моделей Contact, Group, User и SystemSetting
-	err = db.AutoMigrate(&domain.Contact{}, &domain.Group{}, &domain.User{}, &domain.SystemSetting{})
+	report, err := CheckIntegrity(db, cipher, false, logger)
+	if err != nil {
+		return nil, err
+	}
+	if len(report.IntegrityErrors) > 0 {
+		logger.Error("Database file is corrupted, migrations skipped", slog.Any("problems", report.IntegrityErrors))
+		return nil, ErrDatabaseCorrupted
+	}
+	if !report.OK() {
+		logger.Warn("Database integrity problems found, run \"rim check\" for details",
+			slog.Int("orphanContactGroups", len(report.OrphanMemberships)),
+			slog.Int("orphanUserContacts", len(report.OrphanUserContacts)),
+			slog.Int("duplicatePhones", len(report.DuplicatePhones)),
+			slog.Int("undecryptableContacts", len(report.UndecryptableContacts)))
+	}
+
+	// Старые полные уникальные индексы заменяются частичными индексами из domain.Contact и domain.User
+	if err := dropLegacyIndexes(db, logger); err != nil {
+		return nil, err
+	}
+
+	// Таблица связи contact_groups хранит срок членства, поэтому описана моделью domain.ContactGroup
+	if err := db.SetupJoinTable(&domain.Contact{}, "Groups", &domain.ContactGroup{}); err != nil {
+		logger.Error("Failed to set up contact_groups join table", slog.Any("error", err))
+		return nil, err
+	}
+	if err := db.SetupJoinTable(&domain.Group{}, "Contacts", &domain.ContactGroup{}); err != nil {
+		logger.Error("Failed to set up contact_groups join table", slog.Any("error", err))
+		return nil, err
+	}
+
+	// Выполняем автомиграцию моделей.
+	// Таблица user_sessions используется только при SESSION_STORE=sqlite.
+	models := []interface{}{
+		&domain.Contact{},
+		&domain.Group{},
+		&domain.User{},
+		&domain.UserSession{},
+		&domain.SystemSetting{},
+		&domain.PolicyRule{},
+		&domain.LoginCode{},
+		&domain.UserDevice{},
+		&domain.APIToken{},
+		&domain.ContactRelation{},
+		&domain.Skill{},
+		&domain.LocationOption{},
+		&domain.ChangeRequest{},
+		&domain.ImportBatch{},
+		&domain.ImportRow{},
+		&domain.ExportTemplate{},
+		&domain.GroupJoinRequest{},
+		&domain.GroupModerator{},
+		&domain.Organization{},
+		&domain.OrganizationMember{},
+		&domain.PushSubscription{},
+		&domain.UsageCounter{},
+		&domain.GroupMembershipEvent{},
+		&domain.AuditEvent{},
+		&domain.PrintJob{},
+		&domain.ShortLink{},
+		&domain.HRSyncLink{},
+		&domain.HRSyncRun{},
+		&domain.HRSyncRunItem{},
+		&domain.TermsAcceptance{},
+		&domain.OutboxEvent{},
+		&domain.ContactFieldDefinition{},
+		&domain.ContactFieldValue{},
+		&domain.Tag{},
+		&domain.ContactHistory{},
+		&domain.FavoriteContact{},
+		&domain.EmailVerification{},
+	}
+	err = db.AutoMigrate(models...)
 	if err != nil {
 		logger.Error("Failed to migrate database schema", slog.Any("error", err))
 		return nil, err
 	}
-	logger.Info("Database schema migrated successfully for Contact, Group, User and SystemSetting models")
+	names := make([]string, len(models))
+	for i, model := range models {
+		names[i] = reflect.TypeOf(model).Elem().Name()
+	}
+	logger.Info("Database schema migrated successfully", slog.String("models", strings.Join(names, ", ")))
+
+	// Данные, созданные до появления организаций, относятся к организации по умолчанию
+	defaultOrg := domain.Organization{ID: domain.DefaultOrganizationID}
+	if err := db.Attrs(domain.Organization{Name: "Основная организация"}).FirstOrCreate(&defaultOrg).Error; err != nil {
+		logger.Error("Failed to create default organization", slog.Any("error", err))
+		return nil, err
+	}
 
 	return db, nil
 }
+
+// legacyIndex описывает индекс, созданный предыдущими версиями схемы
+type legacyIndex struct {
+	model interface{}
+	name  string
+}
+
+// legacyIndexes - уникальные индексы, замененные новыми:
+// индексы контактов учитывали мягко удаленные записи, индексы Telegram ID не допускали
+// нескольких пользователей и контактов без Telegram (telegram_id = 0),
+// имена групп и ключи настроек стали уникальными в пределах организации,
+// телефон контакта после включения шифрования уникален по индексу phone_hash,
+// email контакта может быть пустым у черновиков и уникален только среди непустых.
+var legacyIndexes = []legacyIndex{
+	{model: &domain.Contact{}, name: "idx_contacts_phone"},
+	{model: &domain.Contact{}, name: "idx_contacts_phone_active"},
+	{model: &domain.Contact{}, name: "idx_contacts_email"},
+	{model: &domain.Contact{}, name: "idx_contacts_email_active"},
+	{model: &domain.Contact{}, name: "idx_contacts_telegram_id"},
+	{model: &domain.User{}, name: "idx_users_telegram_id"},
+	{model: &domain.Group{}, name: "idx_groups_name"},
+	{model: &domain.SystemSetting{}, name: "idx_system_settings_key"},
+}
+
+// dropLegacyIndexes удаляет индексы из legacyIndexes, если они существуют.
+func dropLegacyIndexes(db *gorm.DB, logger *slog.Logger) error {
+	migrator := db.Migrator()
+	for _, idx := range legacyIndexes {
+		if !migrator.HasTable(idx.model) || !migrator.HasIndex(idx.model, idx.name) {
+			continue
+		}
+		if err := migrator.DropIndex(idx.model, idx.name); err != nil {
+			logger.Error("Failed to drop legacy index", slog.String("index", idx.name), slog.Any("error", err))
+			return err
+		}
+		logger.Info("Dropped legacy index", slog.String("index", idx.name))
+	}
+	return nil
+}
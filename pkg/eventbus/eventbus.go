@@ -0,0 +1,71 @@
+// Package eventbus - шина доменных событий внутри процесса. UseCase публикуют факты (контакт создан,
+// пользователь вошел), а журнал аудита, уведомления и кэши подписываются на них, не требуя правок в издателях.
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Event - доменное событие; EventName однозначно определяет тип события
+type Event interface {
+	EventName() string
+}
+
+// Handler обрабатывает событие. Ошибка возвращается издателю и откатывает его транзакцию.
+type Handler func(ctx context.Context, event Event) error
+
+// Publisher публикует события; UseCase зависят от него, а не от Bus
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Bus вызывает обработчики синхронно, в ctx издателя: внутри его транзакции, если она есть.
+// Обработчик, которому нужно только зафиксированное изменение, откладывает работу через transaction.AfterCommit.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// New создает шину без подписчиков
+func New() *Bus {
+	return &Bus{handlers: map[string][]Handler{}}
+}
+
+// Subscribe добавляет обработчик событий с именем name. Подписки задаются при запуске;
+// обработчики события вызываются в порядке подписки.
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// Publish передает событие всем его обработчикам и возвращает их ошибки. Ошибка одного обработчика
+// не мешает вызвать остальные: издатель откатит транзакцию, и их изменения тоже отменятся.
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	handlers := b.handlers[event.EventName()]
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s handler: %w", event.EventName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// On подписывает типизированный обработчик на события типа E
+func On[E Event](b *Bus, handler func(ctx context.Context, event E) error) {
+	var zero E
+	b.Subscribe(zero.EventName(), func(ctx context.Context, event Event) error {
+		typed, ok := event.(E)
+		if !ok {
+			return fmt.Errorf("unexpected event type %T for %s", event, zero.EventName())
+		}
+		return handler(ctx, typed)
+	})
+}
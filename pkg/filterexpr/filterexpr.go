@@ -0,0 +1,433 @@
+// Package filterexpr разбирает выражения фильтра списков вида
+//
+//	transport eq 'есть машина' and (group.name eq 'Логистика' or not status eq 'alumni')
+//
+// Операторы сравнения: eq, ne, gt, ge, lt, le, contains, startswith, in ('a', 'b').
+// Логические: and, or, not и скобки; and связывает сильнее or. Значения: строки в одинарных
+// кавычках (кавычка внутри удваивается), числа, true, false, null; время - строка RFC3339 или ГГГГ-ММ-ДД.
+// Допустимые поля и их типы задает вызывающий код, поэтому выражение нельзя направить на произвольный столбец.
+package filterexpr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ограничения размера выражения: фильтр приходит из query-строки и превращается в SQL
+const (
+	MaxLength      = 2000
+	MaxConditions  = 32
+	maxNesting     = 16
+	maxInListItems = 100
+)
+
+// ErrInvalid - выражение не разобрано или не соответствует полям; конкретная причина в тексте ошибки
+var ErrInvalid = errors.New("invalid filter expression")
+
+// Operator - оператор сравнения
+type Operator string
+
+const (
+	Eq         Operator = "eq"
+	Ne         Operator = "ne"
+	Gt         Operator = "gt"
+	Ge         Operator = "ge"
+	Lt         Operator = "lt"
+	Le         Operator = "le"
+	Contains   Operator = "contains"
+	StartsWith Operator = "startswith"
+	In         Operator = "in"
+)
+
+// Type - тип значений поля
+type Type int
+
+const (
+	String Type = iota
+	Number
+	Bool
+	Time
+)
+
+func (t Type) String() string {
+	return [...]string{"string", "number", "boolean", "time"}[t]
+}
+
+// Field - поле, по которому разрешено фильтровать
+type Field struct {
+	Name string
+	Type Type
+}
+
+// Expr - узел выражения: *And, *Or, *Not или *Comparison
+type Expr interface {
+	expr()
+}
+
+// And - обе части должны выполняться
+type And struct{ Left, Right Expr }
+
+// Or - должна выполняться хотя бы одна часть
+type Or struct{ Left, Right Expr }
+
+// Not - отрицание
+type Not struct{ Expr Expr }
+
+// Comparison - сравнение поля со значением.
+// Value: string, int64, float64, bool, time.Time или nil; для In - []interface{} из них.
+type Comparison struct {
+	Field string
+	Op    Operator
+	Value interface{}
+}
+
+func (*And) expr()        {}
+func (*Or) expr()         {}
+func (*Not) expr()        {}
+func (*Comparison) expr() {}
+
+// Fields возвращает имена полей, встречающихся в выражении, без повторов.
+func Fields(e Expr) []string {
+	var names []string
+	seen := map[string]bool{}
+	Walk(e, func(c *Comparison) {
+		if !seen[c.Field] {
+			seen[c.Field] = true
+			names = append(names, c.Field)
+		}
+	})
+	return names
+}
+
+// Walk вызывает fn для каждого сравнения выражения.
+func Walk(e Expr, fn func(c *Comparison)) {
+	switch e := e.(type) {
+	case *And:
+		Walk(e.Left, fn)
+		Walk(e.Right, fn)
+	case *Or:
+		Walk(e.Left, fn)
+		Walk(e.Right, fn)
+	case *Not:
+		Walk(e.Expr, fn)
+	case *Comparison:
+		fn(e)
+	}
+}
+
+// Parse разбирает выражение и проверяет поля, операторы и типы значений по списку fields.
+func Parse(input string, fields []Field) (Expr, error) {
+	if len(input) > MaxLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrInvalid, MaxLength)
+	}
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, fields: make(map[string]Type, len(fields))}
+	for _, f := range fields {
+		p.fields[f.Name] = f.Type
+	}
+	e, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok)
+	}
+	return e, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenNumber
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{} // Значение строки или числа
+	pos   int         // Позиция в символах от 1
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return strconv.Quote(t.text)
+	}
+	return "'" + t.text + "'"
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	pos := 0 // Номер символа (руны) для сообщений об ошибках
+	for i := 0; i < len(input); {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		pos++
+		start, startPos := i, pos
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(' || r == ')' || r == ',':
+			kind := map[rune]tokenKind{'(': tokenLParen, ')': tokenRParen, ',': tokenComma}[r]
+			tokens = append(tokens, token{kind: kind, text: string(r), pos: startPos})
+			i += size
+		case r == '\'':
+			var b strings.Builder
+			i += size
+			closed := false
+			for i < len(input) {
+				r, size = utf8.DecodeRuneInString(input[i:])
+				i += size
+				pos++
+				if r == '\'' {
+					// Удвоенная кавычка - кавычка внутри строки
+					if i < len(input) && input[i] == '\'' {
+						b.WriteRune('\'')
+						i++
+						pos++
+						continue
+					}
+					closed = true
+					break
+				}
+				b.WriteRune(r)
+			}
+			if !closed {
+				return nil, fmt.Errorf("%w: unterminated string at position %d", ErrInvalid, startPos)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: b.String(), value: b.String(), pos: startPos})
+		case r == '-' || r >= '0' && r <= '9':
+			i += size
+			for i < len(input) && strings.ContainsRune("0123456789.eE+-", rune(input[i])) {
+				i++
+				pos++
+			}
+			text := input[start:i]
+			var value interface{}
+			if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+				value = n
+			} else if f, err := strconv.ParseFloat(text, 64); err == nil {
+				value = f
+			} else {
+				return nil, fmt.Errorf("%w: invalid number %q at position %d", ErrInvalid, text, startPos)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: value, pos: startPos})
+		case r == '_' || unicode.IsLetter(r):
+			i += size
+			for i < len(input) {
+				r, size = utf8.DecodeRuneInString(input[i:])
+				if r != '_' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: input[start:i], pos: startPos})
+		default:
+			return nil, fmt.Errorf("%w: unexpected character %q at position %d", ErrInvalid, r, startPos)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: pos + 1}), nil
+}
+
+type parser struct {
+	tokens     []token
+	pos        int
+	fields     map[string]Type
+	conditions int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) keyword(word string) bool {
+	tok := p.peek()
+	if tok.kind == tokenWord && strings.EqualFold(tok.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at position %d", ErrInvalid, fmt.Sprintf(format, args...), tok.pos)
+}
+
+func (p *parser) parseOr(depth int) (Expr, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd(depth int) (Expr, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary(depth int) (Expr, error) {
+	if depth > maxNesting {
+		return nil, p.errorf(p.peek(), "nested deeper than %d levels", maxNesting)
+	}
+	if p.keyword("not") {
+		e, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Expr: e}, nil
+	}
+	if p.peek().kind == tokenLParen {
+		p.next()
+		e, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != tokenRParen {
+			return nil, p.errorf(tok, "expected ')', found %s", tok)
+		}
+		return e, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	fieldTok := p.next()
+	if fieldTok.kind != tokenWord {
+		return nil, p.errorf(fieldTok, "expected field name, found %s", fieldTok)
+	}
+	fieldType, ok := p.fields[fieldTok.text]
+	if !ok {
+		return nil, p.errorf(fieldTok, "unknown field %q", fieldTok.text)
+	}
+	if p.conditions++; p.conditions > MaxConditions {
+		return nil, p.errorf(fieldTok, "more than %d conditions", MaxConditions)
+	}
+
+	opTok := p.next()
+	op := Operator(strings.ToLower(opTok.text))
+	switch op {
+	case Eq, Ne, Gt, Ge, Lt, Le, Contains, StartsWith, In:
+	default:
+		return nil, p.errorf(opTok, "expected operator, found %s", opTok)
+	}
+	if opTok.kind != tokenWord {
+		return nil, p.errorf(opTok, "expected operator, found %s", opTok)
+	}
+	switch {
+	case (op == Contains || op == StartsWith) && fieldType != String:
+		return nil, p.errorf(opTok, "operator %s requires a string field, %s is %s", op, fieldTok.text, fieldType)
+	case (op == Gt || op == Ge || op == Lt || op == Le) && fieldType == Bool:
+		return nil, p.errorf(opTok, "operator %s cannot be used with boolean field %s", op, fieldTok.text)
+	}
+
+	if op == In {
+		if tok := p.next(); tok.kind != tokenLParen {
+			return nil, p.errorf(tok, "expected '(' after in, found %s", tok)
+		}
+		var items []interface{}
+		for {
+			value, err := p.parseValue(fieldTok.text, fieldType, false)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+			if len(items) > maxInListItems {
+				return nil, p.errorf(fieldTok, "more than %d values in list", maxInListItems)
+			}
+			tok := p.next()
+			if tok.kind == tokenRParen {
+				break
+			}
+			if tok.kind != tokenComma {
+				return nil, p.errorf(tok, "expected ',' or ')', found %s", tok)
+			}
+		}
+		return &Comparison{Field: fieldTok.text, Op: op, Value: items}, nil
+	}
+
+	value, err := p.parseValue(fieldTok.text, fieldType, op == Eq || op == Ne)
+	if err != nil {
+		return nil, err
+	}
+	return &Comparison{Field: fieldTok.text, Op: op, Value: value}, nil
+}
+
+// parseValue читает значение и приводит его к типу поля.
+func (p *parser) parseValue(field string, fieldType Type, nullable bool) (interface{}, error) {
+	tok := p.next()
+	if tok.kind == tokenWord {
+		switch strings.ToLower(tok.text) {
+		case "null":
+			if !nullable {
+				return nil, p.errorf(tok, "null can only be compared with eq or ne")
+			}
+			return nil, nil
+		case "true", "false":
+			if fieldType == Bool {
+				return strings.EqualFold(tok.text, "true"), nil
+			}
+		}
+	}
+
+	switch fieldType {
+	case String:
+		if tok.kind == tokenString {
+			return tok.value, nil
+		}
+	case Number:
+		if tok.kind == tokenNumber {
+			return tok.value, nil
+		}
+	case Time:
+		if tok.kind == tokenString {
+			if t, err := time.Parse(time.RFC3339, tok.text); err == nil {
+				return t.UTC(), nil
+			}
+			if t, err := time.Parse(time.DateOnly, tok.text); err == nil {
+				return t, nil
+			}
+			return nil, p.errorf(tok, "field %s expects time as RFC3339 or YYYY-MM-DD, found %s", field, tok)
+		}
+	}
+	return nil, p.errorf(tok, "field %s expects a %s value, found %s", field, fieldType, tok)
+}
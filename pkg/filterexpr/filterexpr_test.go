@@ -0,0 +1,256 @@
+package filterexpr
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testFields = []Field{
+	{Name: "name", Type: String},
+	{Name: "status", Type: String},
+	{Name: "group.name", Type: String},
+	{Name: "age", Type: Number},
+	{Name: "active", Type: Bool},
+	{Name: "created_at", Type: Time},
+}
+
+func TestTokenize(t *testing.T) {
+	tokens, err := tokenize("name eq 'Д''Артаньян' and (age ge -1.5e2, x_1)")
+	if err != nil {
+		t.Fatalf("tokenize: %v", err)
+	}
+	want := []token{
+		{kind: tokenWord, text: "name", pos: 1},
+		{kind: tokenWord, text: "eq", pos: 6},
+		{kind: tokenString, text: "Д'Артаньян", value: "Д'Артаньян", pos: 9},
+		{kind: tokenWord, text: "and", pos: 23},
+		{kind: tokenLParen, text: "(", pos: 27},
+		{kind: tokenWord, text: "age", pos: 28},
+		{kind: tokenWord, text: "ge", pos: 32},
+		{kind: tokenNumber, text: "-1.5e2", value: -150.0, pos: 35},
+		{kind: tokenComma, text: ",", pos: 41},
+		{kind: tokenWord, text: "x_1", pos: 43},
+		{kind: tokenRParen, text: ")", pos: 46},
+		{kind: tokenEOF, pos: 47},
+	}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Errorf("tokens = %+v\nwant %+v", tokens, want)
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "unterminated string", input: "name eq 'abc", want: "unterminated string at position 9"},
+		{name: "invalid number", input: "age eq 1e", want: `invalid number "1e" at position 8`},
+		{name: "unexpected character", input: "age eq 1 ; drop", want: "unexpected character ';' at position 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tokenize(tt.input)
+			if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	cmp := func(field string, op Operator, value interface{}) *Comparison {
+		return &Comparison{Field: field, Op: op, Value: value}
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  Expr
+	}{
+		{
+			name:  "and binds tighter than or",
+			input: "name eq 'a' or status eq 'b' and age gt 3",
+			want:  &Or{Left: cmp("name", Eq, "a"), Right: &And{Left: cmp("status", Eq, "b"), Right: cmp("age", Gt, int64(3))}},
+		},
+		{
+			name:  "parentheses",
+			input: "(name eq 'a' or status eq 'b') and age gt 3",
+			want:  &And{Left: &Or{Left: cmp("name", Eq, "a"), Right: cmp("status", Eq, "b")}, Right: cmp("age", Gt, int64(3))},
+		},
+		{
+			name:  "not applies to one comparison",
+			input: "not status eq 'alumni' and active eq true",
+			want:  &And{Left: &Not{Expr: cmp("status", Eq, "alumni")}, Right: cmp("active", Eq, true)},
+		},
+		{
+			name:  "keywords are case insensitive",
+			input: "name EQ 'a' OR NOT active Eq TRUE",
+			want:  &Or{Left: cmp("name", Eq, "a"), Right: &Not{Expr: cmp("active", Eq, true)}},
+		},
+		{
+			name:  "left-associative chain",
+			input: "age gt 1 and age lt 9 and name startswith 'А'",
+			want:  &And{Left: &And{Left: cmp("age", Gt, int64(1)), Right: cmp("age", Lt, int64(9))}, Right: cmp("name", StartsWith, "А")},
+		},
+		{
+			name:  "null with eq and ne",
+			input: "group.name eq null or group.name ne NULL",
+			want:  &Or{Left: cmp("group.name", Eq, nil), Right: cmp("group.name", Ne, nil)},
+		},
+		{
+			name:  "in list",
+			input: "status in ('active', 'on_leave')",
+			want:  cmp("status", In, []interface{}{"active", "on_leave"}),
+		},
+		{
+			name:  "float and negative numbers",
+			input: "age le -2.5",
+			want:  cmp("age", Le, -2.5),
+		},
+		{
+			name:  "time as date and RFC3339",
+			input: "created_at ge '2024-03-01' and created_at lt '2024-03-02T10:00:00+03:00'",
+			want: &And{
+				Left:  cmp("created_at", Ge, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+				Right: cmp("created_at", Lt, time.Date(2024, 3, 2, 7, 0, 0, 0, time.UTC)),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input, testFields)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expression = %#v\nwant %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "empty", input: "", want: "expected field name, found end of expression at position 1"},
+		{name: "unknown field", input: "password eq 'x'", want: `unknown field "password" at position 1`},
+		{name: "field names are case sensitive", input: "NAME eq 'x'", want: `unknown field "NAME"`},
+		{name: "field as column expression", input: "name eq 'a' or 1 eq 1", want: "expected field name, found '1' at position 16"},
+		{name: "unknown operator", input: "name like 'a'", want: "expected operator, found 'like' at position 6"},
+		{name: "operator as string", input: "name 'eq' 'a'", want: `expected operator, found "eq" at position 6`},
+		{name: "contains on number", input: "age contains 1", want: "operator contains requires a string field, age is number"},
+		{name: "ordering on boolean", input: "active gt true", want: "operator gt cannot be used with boolean field active"},
+		{name: "wrong value type", input: "age eq '3'", want: `field age expects a number value, found "3" at position 8`},
+		{name: "boolean for string field", input: "name eq true", want: "field name expects a string value, found 'true'"},
+		{name: "invalid time", input: "created_at gt 'вчера'", want: "field created_at expects time as RFC3339 or YYYY-MM-DD"},
+		{name: "null with ordering", input: "age gt null", want: "null can only be compared with eq or ne"},
+		{name: "null in list", input: "status in ('a', null)", want: "null can only be compared with eq or ne"},
+		{name: "in without list", input: "status in 'a'", want: "expected '(' after in"},
+		{name: "unclosed list", input: "status in ('a' 'b')", want: "expected ',' or ')', found \"b\""},
+		{name: "unclosed parenthesis", input: "(name eq 'a'", want: "expected ')', found end of expression"},
+		{name: "trailing tokens", input: "name eq 'a' name eq 'b'", want: "unexpected 'name' at position 13"},
+		{name: "dangling and", input: "name eq 'a' and", want: "expected field name, found end of expression"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input, testFields)
+			if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	conditions := func(n int) string {
+		parts := make([]string, n)
+		for i := range parts {
+			parts[i] = "age eq 1"
+		}
+		return strings.Join(parts, " or ")
+	}
+	list := func(n int) string {
+		items := make([]string, n)
+		for i := range items {
+			items[i] = "'s'"
+		}
+		return "status in (" + strings.Join(items, ",") + ")"
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  string // Пусто - выражение в пределах ограничения
+	}{
+		{name: "length at limit", input: "name eq '" + strings.Repeat("a", MaxLength-10) + "'"},
+		{name: "length over limit", input: "name eq '" + strings.Repeat("a", MaxLength) + "'", want: "longer than 2000 characters"},
+		{name: "conditions at limit", input: conditions(MaxConditions)},
+		{name: "conditions over limit", input: conditions(MaxConditions + 1), want: "more than 32 conditions"},
+		{name: "parentheses at limit", input: strings.Repeat("(", maxNesting) + "age eq 1" + strings.Repeat(")", maxNesting)},
+		{name: "parentheses over limit", input: strings.Repeat("(", maxNesting+1) + "age eq 1" + strings.Repeat(")", maxNesting+1), want: "nested deeper than 16 levels"},
+		{name: "not chain over limit", input: strings.Repeat("not ", maxNesting+1) + "age eq 1", want: "nested deeper than 16 levels"},
+		{name: "list at limit", input: list(maxInListItems)},
+		{name: "list over limit", input: list(maxInListItems + 1), want: "more than 100 values in list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input, testFields)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Parse: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	e, err := Parse("name eq 'a' and (age gt 1 or not name ne 'b') and group.name in ('x')", testFields)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got, want := Fields(e), []string{"name", "age", "group.name"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+}
+
+func TestSQLBuilder(t *testing.T) {
+	e, err := Parse("not (name contains '50%_off' or group.name eq null) and status in ('a', 'b') and age ge 18", testFields)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	b := SQLBuilder{
+		Columns: map[string]string{"name": "contacts.name", "status": "contacts.status", "age": "contacts.age"},
+		Custom: map[string]func(c *Comparison) (string, []interface{}){
+			"group.name": func(c *Comparison) (string, []interface{}) {
+				sql, args := Condition("groups.name", c)
+				return "EXISTS (SELECT 1 FROM groups WHERE " + sql + ")", args
+			},
+		},
+	}
+	sql, args, err := b.Build(e)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	wantSQL := `((NOT ((contacts.name LIKE ? ESCAPE '\' OR EXISTS (SELECT 1 FROM groups WHERE groups.name IS NULL))) AND contacts.status IN ?) AND contacts.age >= ?)`
+	if sql != wantSQL {
+		t.Errorf("sql = %s\nwant %s", sql, wantSQL)
+	}
+	wantArgs := []interface{}{`%50\%\_off%`, []interface{}{"a", "b"}, int64(18)}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %#v, want %#v", args, wantArgs)
+	}
+
+	if _, _, err := (SQLBuilder{}).Build(e); err == nil || !strings.Contains(err.Error(), `no column for field "name"`) {
+		t.Errorf("Build without columns: error = %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package filterexpr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SQLBuilder переводит выражение в условие WHERE с параметрами "?".
+type SQLBuilder struct {
+	// Columns - выражение столбца для поля
+	Columns map[string]string
+	// Custom - условие для полей, которые не сводятся к столбцу, например связей многие-ко-многим
+	Custom map[string]func(c *Comparison) (string, []interface{})
+}
+
+// Build возвращает условие и его параметры. Поле без столбца и без Custom - ошибка настройки вызывающего кода.
+func (b SQLBuilder) Build(e Expr) (string, []interface{}, error) {
+	switch e := e.(type) {
+	case *And:
+		return b.binary("AND", e.Left, e.Right)
+	case *Or:
+		return b.binary("OR", e.Left, e.Right)
+	case *Not:
+		sql, args, err := b.Build(e.Expr)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + sql + ")", args, nil
+	case *Comparison:
+		if custom, ok := b.Custom[e.Field]; ok {
+			sql, args := custom(e)
+			return sql, args, nil
+		}
+		column, ok := b.Columns[e.Field]
+		if !ok {
+			return "", nil, fmt.Errorf("filterexpr: no column for field %q", e.Field)
+		}
+		sql, args := Condition(column, e)
+		return sql, args, nil
+	}
+	return "", nil, fmt.Errorf("filterexpr: unexpected expression %T", e)
+}
+
+func (b SQLBuilder) binary(op string, left, right Expr) (string, []interface{}, error) {
+	leftSQL, leftArgs, err := b.Build(left)
+	if err != nil {
+		return "", nil, err
+	}
+	rightSQL, rightArgs, err := b.Build(right)
+	if err != nil {
+		return "", nil, err
+	}
+	return "(" + leftSQL + " " + op + " " + rightSQL + ")", append(leftArgs, rightArgs...), nil
+}
+
+// Condition возвращает условие сравнения столбца column со значением c.
+// contains и startswith используют LIKE: в SQLite он не различает регистр только для латиницы.
+func Condition(column string, c *Comparison) (string, []interface{}) {
+	switch c.Op {
+	case Eq:
+		if c.Value == nil {
+			return column + " IS NULL", nil
+		}
+		return column + " = ?", []interface{}{c.Value}
+	case Ne:
+		if c.Value == nil {
+			return column + " IS NOT NULL", nil
+		}
+		return column + " <> ?", []interface{}{c.Value}
+	case Gt:
+		return column + " > ?", []interface{}{c.Value}
+	case Ge:
+		return column + " >= ?", []interface{}{c.Value}
+	case Lt:
+		return column + " < ?", []interface{}{c.Value}
+	case Le:
+		return column + " <= ?", []interface{}{c.Value}
+	case Contains:
+		return column + ` LIKE ? ESCAPE '\'`, []interface{}{"%" + escapeLike(c.Value.(string)) + "%"}
+	case StartsWith:
+		return column + ` LIKE ? ESCAPE '\'`, []interface{}{escapeLike(c.Value.(string)) + "%"}
+	case In:
+		return column + " IN ?", []interface{}{c.Value}
+	}
+	return "1 = 0", nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
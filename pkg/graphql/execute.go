@@ -0,0 +1,747 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Request - запрос GraphQL в формате HTTP-транспорта
+type Request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// Response - ответ GraphQL. Если запрос отклонен до выполнения, data в ответе отсутствует.
+type Response struct {
+	Data     interface{}
+	Errors   []*Error
+	executed bool
+}
+
+// Executed сообщает, дошел ли запрос до выполнения. Невыполненный запрос не прошел разбор или проверку.
+func (r *Response) Executed() bool {
+	return r.executed
+}
+
+// MarshalJSON выводит data (в том числе null) только для выполненного запроса.
+func (r *Response) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Data   *json.RawMessage `json:"data,omitempty"`
+		Errors []*Error         `json:"errors,omitempty"`
+	}{Errors: r.Errors}
+	if r.executed {
+		data, err := json.Marshal(r.Data)
+		if err != nil {
+			return nil, err
+		}
+		raw := json.RawMessage(data)
+		out.Data = &raw
+	}
+	return json.Marshal(out)
+}
+
+// Error - ошибка запроса или вычисления поля
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Служебные значения при выполнении: failed - поле не вычислено из-за ошибки резолвера,
+// invalid - null в поле NonNull, который делает null ближайший допускающий его родитель.
+type resolverFailed struct{}
+type nullPropagation struct{}
+
+var (
+	failed  interface{} = resolverFailed{}
+	invalid interface{} = nullPropagation{}
+)
+
+// Execute разбирает, проверяет и выполняет запрос.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return requestError(err)
+	}
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return requestError(err)
+	}
+	if op.Type != "query" {
+		return requestError(&Error{Message: fmt.Sprintf("%s operations are not supported", op.Type), Locations: []Location{op.Location}})
+	}
+	vars, err := s.coerceVariables(op, req.Variables)
+	if err != nil {
+		return requestError(err)
+	}
+
+	e := &executor{ctx: ctx, schema: s, doc: doc, vars: vars}
+	if errs := e.validate(op); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	data := e.executeObjects(s.Query, op.SelectionSet, []interface{}{nil}, [][]interface{}{nil})
+	resp := &Response{Errors: e.errors, executed: true}
+	if data[0] != invalid {
+		resp.Data = data[0]
+	}
+	return resp
+}
+
+func requestError(err error) *Response {
+	if gqlErr, ok := err.(*Error); ok {
+		return &Response{Errors: []*Error{gqlErr}}
+	}
+	return &Response{Errors: []*Error{{Message: err.Error()}}}
+}
+
+func selectOperation(doc *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, &Error{Message: "operationName is required for a document with several operations"}
+		}
+		return doc.Operations[0], nil
+	}
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("unknown operation %q", name)}
+}
+
+// coerceVariables проверяет переданные переменные по их объявлениям. Возвращаются исходные значения:
+// к типу аргумента их приводит coerceArgs, и ParseValue не вызывается для уже приведенного значения.
+func (s *Schema) coerceVariables(op *Operation, provided map[string]interface{}) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	for _, def := range op.Variables {
+		typ, err := s.typeFromRef(def.Type)
+		if err != nil {
+			return nil, &Error{Message: fmt.Sprintf("variable $%s: %v", def.Name, err), Locations: []Location{op.Location}}
+		}
+		value, ok := provided[def.Name]
+		if !ok {
+			if def.HasDefault {
+				value, ok = def.Default, true
+			} else if _, nonNull := typ.(*NonNull); nonNull {
+				return nil, &Error{Message: fmt.Sprintf("variable $%s of type %s is required", def.Name, typ), Locations: []Location{op.Location}}
+			}
+		}
+		if !ok {
+			continue
+		}
+		if _, err := coerceInput(typ, value); err != nil {
+			return nil, &Error{Message: fmt.Sprintf("variable $%s: %v", def.Name, err), Locations: []Location{op.Location}}
+		}
+		vars[def.Name] = value
+	}
+	return vars, nil
+}
+
+func (s *Schema) typeFromRef(ref *TypeRef) (Type, error) {
+	var t Type
+	if ref.Elem != nil {
+		elem, err := s.typeFromRef(ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		t = NewList(elem)
+	} else {
+		named, ok := s.types[ref.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %s", ref.Name)
+		}
+		if _, ok := named.(*Scalar); !ok {
+			return nil, fmt.Errorf("type %s cannot be used as input", ref.Name)
+		}
+		t = named
+	}
+	if ref.NonNull {
+		t = NewNonNull(t)
+	}
+	return t, nil
+}
+
+// coerceInput приводит значение аргумента или переменной к типу t.
+func coerceInput(t Type, value interface{}) (interface{}, error) {
+	switch t := t.(type) {
+	case *NonNull:
+		if value == nil {
+			return nil, fmt.Errorf("expected non-null %s", t.OfType)
+		}
+		return coerceInput(t.OfType, value)
+	case *List:
+		if value == nil {
+			return nil, nil
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			// Одиночное значение на месте списка считается списком из одного элемента
+			items = []interface{}{value}
+		}
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			coerced, err := coerceInput(t.OfType, item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = coerced
+		}
+		return out, nil
+	case *Scalar:
+		if value == nil {
+			return nil, nil
+		}
+		return t.ParseValue(value)
+	}
+	return nil, fmt.Errorf("type %s cannot be used as input", t)
+}
+
+// substitute подставляет значения переменных в литерал.
+func substitute(value interface{}, vars map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case Variable:
+		return vars[string(v)]
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = substitute(item, vars)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = substitute(item, vars)
+		}
+		return out
+	}
+	return value
+}
+
+type executor struct {
+	ctx    context.Context
+	schema *Schema
+	doc    *Document
+	vars   map[string]interface{}
+	errors []*Error
+}
+
+// fieldGroup - поля выборки с одним ключом ответа
+type fieldGroup struct {
+	key   string
+	nodes []*FieldNode
+}
+
+// collectFields раскрывает фрагменты и директивы набора выборки и группирует поля по ключу ответа.
+func (e *executor) collectFields(obj *Object, set []Selection) ([]*fieldGroup, error) {
+	var groups []*fieldGroup
+	index := map[string]*fieldGroup{}
+	visited := map[string]bool{}
+
+	var collect func(set []Selection) error
+	collect = func(set []Selection) error {
+		for _, sel := range set {
+			switch sel := sel.(type) {
+			case *FieldNode:
+				include, err := e.shouldInclude(sel.Directives)
+				if err != nil || !include {
+					return err
+				}
+				key := sel.ResponseKey()
+				group, ok := index[key]
+				if !ok {
+					group = &fieldGroup{key: key}
+					index[key] = group
+					groups = append(groups, group)
+				} else if group.nodes[0].Name != sel.Name {
+					return &Error{Message: fmt.Sprintf("fields %q and %q conflict because they have the same response name %q", group.nodes[0].Name, sel.Name, key), Locations: []Location{sel.Location}}
+				}
+				group.nodes = append(group.nodes, sel)
+			case *FragmentSpread:
+				include, err := e.shouldInclude(sel.Directives)
+				if err != nil || !include || visited[sel.Name] {
+					return err
+				}
+				visited[sel.Name] = true
+				fragment, ok := e.doc.Fragments[sel.Name]
+				if !ok {
+					return &Error{Message: fmt.Sprintf("unknown fragment %q", sel.Name), Locations: []Location{sel.Location}}
+				}
+				if fragment.TypeCondition != obj.Name {
+					return &Error{Message: fmt.Sprintf("fragment %q on %s cannot be spread on type %s", sel.Name, fragment.TypeCondition, obj.Name), Locations: []Location{sel.Location}}
+				}
+				if err := collect(fragment.SelectionSet); err != nil {
+					return err
+				}
+			case *InlineFragment:
+				include, err := e.shouldInclude(sel.Directives)
+				if err != nil || !include {
+					return err
+				}
+				if sel.TypeCondition != "" && sel.TypeCondition != obj.Name {
+					return &Error{Message: fmt.Sprintf("inline fragment on %s cannot be spread on type %s", sel.TypeCondition, obj.Name)}
+				}
+				if err := collect(sel.SelectionSet); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return groups, collect(set)
+}
+
+// shouldInclude вычисляет директивы @skip и @include.
+func (e *executor) shouldInclude(directives []*Directive) (bool, error) {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			return false, &Error{Message: fmt.Sprintf("unknown directive @%s", d.Name)}
+		}
+		if len(d.Arguments) != 1 || d.Arguments[0].Name != "if" {
+			return false, &Error{Message: fmt.Sprintf("directive @%s requires the single argument \"if\"", d.Name)}
+		}
+		value, err := coerceInput(NewNonNull(Boolean), substitute(d.Arguments[0].Value, e.vars))
+		if err != nil {
+			return false, &Error{Message: fmt.Sprintf("directive @%s: %v", d.Name, err)}
+		}
+		if value.(bool) == (d.Name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// coerceArgs собирает аргументы поля с учетом переменных и значений по умолчанию.
+func (e *executor) coerceArgs(def *Field, node *FieldNode) (map[string]interface{}, error) {
+	args := map[string]interface{}{}
+	for _, argNode := range node.Arguments {
+		found := false
+		for _, arg := range def.Args {
+			found = found || arg.Name == argNode.Name
+		}
+		if !found {
+			return nil, &Error{Message: fmt.Sprintf("unknown argument %q on field %q", argNode.Name, def.Name), Locations: []Location{node.Location}}
+		}
+	}
+	for _, arg := range def.Args {
+		var argNode *ArgumentNode
+		for _, n := range node.Arguments {
+			if n.Name == arg.Name {
+				argNode = n
+			}
+		}
+		provided := argNode != nil
+		if provided {
+			if v, isVar := argNode.Value.(Variable); isVar {
+				_, provided = e.vars[string(v)]
+			}
+		}
+		if !provided {
+			if arg.Default != nil {
+				args[arg.Name] = arg.Default
+			} else if _, nonNull := arg.Type.(*NonNull); nonNull {
+				return nil, &Error{Message: fmt.Sprintf("argument %q of type %s is required on field %q", arg.Name, arg.Type, def.Name), Locations: []Location{node.Location}}
+			}
+			continue
+		}
+		value, err := coerceInput(arg.Type, substitute(argNode.Value, e.vars))
+		if err != nil {
+			return nil, &Error{Message: fmt.Sprintf("argument %q on field %q: %v", arg.Name, def.Name, err), Locations: []Location{node.Location}}
+		}
+		args[arg.Name] = value
+	}
+	return args, nil
+}
+
+// validate проверяет выборку операции по схеме до выполнения.
+func (e *executor) validate(op *Operation) []*Error {
+	var errs []*Error
+	if err := e.checkFragmentCycles(); err != nil {
+		return []*Error{err}
+	}
+
+	var walk func(obj *Object, set []Selection, depth int)
+	walk = func(obj *Object, set []Selection, depth int) {
+		if e.schema.MaxDepth > 0 && depth > e.schema.MaxDepth {
+			errs = append(errs, &Error{Message: fmt.Sprintf("query is nested deeper than %d levels", e.schema.MaxDepth)})
+			return
+		}
+		groups, err := e.collectFields(obj, set)
+		if err != nil {
+			errs = append(errs, err.(*Error))
+			return
+		}
+		for _, group := range groups {
+			var subset []Selection
+			for _, node := range group.nodes {
+				subset = append(subset, node.SelectionSet...)
+			}
+			node := group.nodes[0]
+			if node.Name == "__typename" {
+				if len(node.Arguments) > 0 || len(subset) > 0 {
+					errs = append(errs, &Error{Message: "field \"__typename\" has no arguments and subfields", Locations: []Location{node.Location}})
+				}
+				continue
+			}
+			def := obj.Field(node.Name)
+			if def == nil {
+				errs = append(errs, &Error{Message: fmt.Sprintf("cannot query field %q on type %q", node.Name, obj.Name), Locations: []Location{node.Location}})
+				continue
+			}
+			for _, n := range group.nodes {
+				if _, err := e.coerceArgs(def, n); err != nil {
+					errs = append(errs, err.(*Error))
+				}
+			}
+			child, isObject := namedType(def.Type).(*Object)
+			switch {
+			case isObject && len(subset) == 0:
+				errs = append(errs, &Error{Message: fmt.Sprintf("field %q of type %s must have a selection of subfields", node.Name, def.Type), Locations: []Location{node.Location}})
+			case !isObject && len(subset) > 0:
+				errs = append(errs, &Error{Message: fmt.Sprintf("field %q of type %s must not have a selection of subfields", node.Name, def.Type), Locations: []Location{node.Location}})
+			case isObject:
+				walk(child, subset, depth+1)
+			}
+		}
+	}
+	walk(e.schema.Query, op.SelectionSet, 1)
+	return errs
+}
+
+// checkFragmentCycles отклоняет фрагменты, прямо или через вложенные поля ссылающиеся на себя,
+// и цепочки вложенных фрагментов длиннее maxNesting.
+func (e *executor) checkFragmentCycles() *Error {
+	visiting := map[string]bool{}
+	heights := map[string]int{} // Длина самой длинной цепочки фрагментов, начинающейся с проверенного фрагмента
+	var visit func(name string) (int, *Error)
+	var visitSet func(set []Selection) (int, *Error)
+	visitSet = func(set []Selection) (int, *Error) {
+		height := 0
+		for _, sel := range set {
+			var h int
+			var err *Error
+			switch sel := sel.(type) {
+			case *FieldNode:
+				h, err = visitSet(sel.SelectionSet)
+			case *InlineFragment:
+				h, err = visitSet(sel.SelectionSet)
+			case *FragmentSpread:
+				h, err = visit(sel.Name)
+			}
+			if err != nil {
+				return 0, err
+			}
+			height = max(height, h)
+		}
+		return height, nil
+	}
+	visit = func(name string) (int, *Error) {
+		if visiting[name] {
+			return 0, &Error{Message: fmt.Sprintf("fragment %q references itself", name)}
+		}
+		height, checked := heights[name]
+		fragment, ok := e.doc.Fragments[name]
+		if !ok {
+			return 0, nil // Неизвестный фрагмент сообщается при сборе полей
+		}
+		// Цепочка - обходимые фрагменты и самая длинная цепочка от этого; обход не глубже maxNesting+1 фрагмента
+		if len(visiting)+max(height, 1) > maxNesting {
+			return 0, &Error{Message: fmt.Sprintf("fragments are nested deeper than %d levels", maxNesting)}
+		}
+		if checked {
+			return height, nil
+		}
+		visiting[name] = true
+		height, err := visitSet(fragment.SelectionSet)
+		if err != nil {
+			return 0, err
+		}
+		delete(visiting, name)
+		heights[name] = height + 1
+		return height + 1, nil
+	}
+	for name := range e.doc.Fragments {
+		if _, err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// executeObjects вычисляет набор выборки сразу для всех объектов sources одного типа.
+// Поле с Batch вычисляется одним вызовом на все объекты, вложенные объекты - одним уровнем ниже.
+func (e *executor) executeObjects(obj *Object, set []Selection, sources []interface{}, paths [][]interface{}) []interface{} {
+	groups, _ := e.collectFields(obj, set) // Ошибки сбора полей отклонены при проверке
+	results := make([]*orderedMap, len(sources))
+	for i := range results {
+		results[i] = &orderedMap{values: map[string]interface{}{}}
+	}
+
+	for _, group := range groups {
+		node := group.nodes[0]
+		fieldPaths := make([][]interface{}, len(sources))
+		for i := range sources {
+			fieldPaths[i] = appendPath(paths[i], group.key)
+		}
+
+		var values []interface{}
+		if node.Name == "__typename" {
+			values = make([]interface{}, len(sources))
+			for i := range values {
+				values[i] = obj.Name
+			}
+		} else {
+			def := obj.Field(node.Name)
+			args, _ := e.coerceArgs(def, node)
+			values = e.resolve(def, args, sources, fieldPaths, node)
+			values = e.complete(def.Type, group.nodes, values, fieldPaths)
+		}
+		for i, result := range results {
+			result.set(group.key, values[i])
+		}
+	}
+
+	out := make([]interface{}, len(sources))
+	for i, result := range results {
+		out[i] = result
+		for _, value := range result.values {
+			if value == invalid {
+				out[i] = invalid
+				break
+			}
+		}
+	}
+	return out
+}
+
+// resolve вызывает резолвер поля; при ошибке значение поля помечается failed.
+func (e *executor) resolve(def *Field, args map[string]interface{}, sources []interface{}, paths [][]interface{}, node *FieldNode) []interface{} {
+	values := make([]interface{}, len(sources))
+	if def.Batch != nil {
+		batch, err := safeBatch(def, BatchParams{Context: e.ctx, Sources: sources, Args: args})
+		if err == nil && len(batch) != len(sources) {
+			err = fmt.Errorf("field %q resolved %d values for %d objects", def.Name, len(batch), len(sources))
+		}
+		if err != nil {
+			for i := range values {
+				values[i] = failed
+				e.addError(node, paths[i], err)
+			}
+			return values
+		}
+		return batch
+	}
+
+	for i, source := range sources {
+		value, err := safeResolve(def, ResolveParams{Context: e.ctx, Source: source, Args: args})
+		if err != nil {
+			value = failed
+			e.addError(node, paths[i], err)
+		}
+		values[i] = value
+	}
+	return values
+}
+
+func safeResolve(def *Field, p ResolveParams) (value interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("internal error resolving field %q: %v", def.Name, r)
+		}
+	}()
+	return def.Resolve(p)
+}
+
+func safeBatch(def *Field, p BatchParams) (values []interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("internal error resolving field %q: %v", def.Name, r)
+		}
+	}()
+	return def.Batch(p)
+}
+
+// complete приводит значения резолвера к типу поля и выполняет вложенные выборки.
+// Null в поле NonNull становится invalid; допускающее null поле превращает invalid потомка в null.
+func (e *executor) complete(t Type, nodes []*FieldNode, values []interface{}, paths [][]interface{}) []interface{} {
+	if nn, ok := t.(*NonNull); ok {
+		out := e.completeValues(nn.OfType, nodes, values, paths)
+		for i, value := range out {
+			if value == nil {
+				if values[i] != failed {
+					e.addError(nodes[0], paths[i], fmt.Errorf("cannot return null for non-nullable field"))
+				}
+				out[i] = invalid
+			}
+		}
+		return out
+	}
+	out := e.completeValues(t, nodes, values, paths)
+	for i, value := range out {
+		if value == invalid {
+			out[i] = nil
+		}
+	}
+	return out
+}
+
+func (e *executor) completeValues(t Type, nodes []*FieldNode, values []interface{}, paths [][]interface{}) []interface{} {
+	out := make([]interface{}, len(values))
+	switch t := t.(type) {
+	case *Scalar:
+		for i, value := range values {
+			if value == failed || isNil(value) {
+				continue
+			}
+			serialized, err := t.Serialize(value)
+			if err != nil {
+				e.addError(nodes[0], paths[i], err)
+				continue
+			}
+			out[i] = serialized
+		}
+
+	case *List:
+		var items []interface{}
+		var itemPaths [][]interface{}
+		spans := make([][2]int, len(values))
+		for i, value := range values {
+			spans[i] = [2]int{-1, -1}
+			if value == failed || isNil(value) {
+				continue
+			}
+			rv := reflect.ValueOf(value)
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				e.addError(nodes[0], paths[i], fmt.Errorf("expected a list, found %T", value))
+				continue
+			}
+			spans[i][0] = len(items)
+			for j := 0; j < rv.Len(); j++ {
+				item := rv.Index(j)
+				// Элементы-структуры передаются резолверам указателями
+				if item.Kind() == reflect.Struct && item.CanAddr() {
+					item = item.Addr()
+				}
+				items = append(items, item.Interface())
+				itemPaths = append(itemPaths, appendPath(paths[i], j))
+			}
+			spans[i][1] = len(items)
+		}
+		completed := e.complete(t.OfType, nodes, items, itemPaths)
+		for i, span := range spans {
+			if span[0] < 0 {
+				continue
+			}
+			list := append([]interface{}{}, completed[span[0]:span[1]]...)
+			out[i] = list
+			for _, item := range list {
+				if item == invalid {
+					out[i] = invalid
+					break
+				}
+			}
+		}
+
+	case *Object:
+		var subset []Selection
+		for _, node := range nodes {
+			subset = append(subset, node.SelectionSet...)
+		}
+		var sources []interface{}
+		var sourcePaths [][]interface{}
+		var positions []int
+		for i, value := range values {
+			if value == failed || isNil(value) {
+				continue
+			}
+			sources = append(sources, value)
+			sourcePaths = append(sourcePaths, paths[i])
+			positions = append(positions, i)
+		}
+		if len(sources) > 0 {
+			for j, result := range e.executeObjects(t, subset, sources, sourcePaths) {
+				out[positions[j]] = result
+			}
+		}
+	}
+	return out
+}
+
+func (e *executor) addError(node *FieldNode, path []interface{}, err error) {
+	e.errors = append(e.errors, &Error{Message: err.Error(), Locations: []Location{node.Location}, Path: path})
+}
+
+func appendPath(path []interface{}, elem interface{}) []interface{} {
+	out := make([]interface{}, len(path)+1)
+	copy(out, path)
+	out[len(path)] = elem
+	return out
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// orderedMap - объект ответа, сохраняющий порядок полей запроса
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Get возвращает значение поля ответа по ключу.
+func (m *orderedMap) Get(key string) interface{} {
+	return m.values[key]
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		b.Write(name)
+		b.WriteByte(':')
+		value, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// String возвращает текст ошибок через "; " для журналов.
+func (r *Response) String() string {
+	messages := make([]string, len(r.Errors))
+	for i, err := range r.Errors {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,374 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testContact struct {
+	ID      uint
+	Name    string
+	Friends []uint
+}
+
+var testContacts = []testContact{
+	{ID: 1, Name: "Анна", Friends: []uint{2, 3}},
+	{ID: 2, Name: "Борис", Friends: []uint{1}},
+	{ID: 3, Name: "Вера"},
+}
+
+func findTestContact(id uint) *testContact {
+	for i := range testContacts {
+		if testContacts[i].ID == id {
+			return &testContacts[i]
+		}
+	}
+	return nil
+}
+
+// newTestSchema собирает схему справочника; friendBatches считает пакетные вызовы поля friends
+func newTestSchema(t *testing.T, maxDepth int, friendBatches *int) *Schema {
+	t.Helper()
+	contact := &Object{Name: "Contact"}
+	contact.Fields = []*Field{
+		{Name: "id", Type: NewNonNull(ID), Resolve: func(p ResolveParams) (interface{}, error) {
+			return p.Source.(*testContact).ID, nil
+		}},
+		{Name: "name", Type: NewNonNull(String), Resolve: func(p ResolveParams) (interface{}, error) {
+			return p.Source.(*testContact).Name, nil
+		}},
+		{Name: "friends", Type: NewNonNull(NewList(NewNonNull(contact))), Batch: func(p BatchParams) ([]interface{}, error) {
+			*friendBatches++
+			out := make([]interface{}, len(p.Sources))
+			for i, source := range p.Sources {
+				friends := []*testContact{}
+				for _, id := range source.(*testContact).Friends {
+					friends = append(friends, findTestContact(id))
+				}
+				out[i] = friends
+			}
+			return out, nil
+		}},
+		{Name: "mismatch", Type: String, Batch: func(p BatchParams) ([]interface{}, error) {
+			return make([]interface{}, len(p.Sources)-1), nil
+		}},
+	}
+
+	query := &Object{Name: "Query", Fields: []*Field{
+		{Name: "contacts", Type: NewNonNull(NewList(NewNonNull(contact))), Args: []*Argument{{Name: "first", Type: Int, Default: 10}},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				return testContacts[:min(p.Args["first"].(int), len(testContacts))], nil
+			}},
+		{Name: "contact", Type: contact, Args: []*Argument{{Name: "id", Type: NewNonNull(ID)}},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				var id uint
+				if _, err := fmt.Sscan(p.Args["id"].(string), &id); err != nil {
+					return nil, errors.New("invalid ID")
+				}
+				return findTestContact(id), nil
+			}},
+		{Name: "hello", Type: NewNonNull(String), Args: []*Argument{{Name: "name", Type: String, Default: "world"}, {Name: "times", Type: NewList(NewNonNull(Int))}},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				greeting := "hello, " + p.Args["name"].(string)
+				if times, ok := p.Args["times"].([]interface{}); ok {
+					greeting += fmt.Sprint(times)
+				}
+				return greeting, nil
+			}},
+		{Name: "failing", Type: String, Resolve: func(ResolveParams) (interface{}, error) {
+			return nil, errors.New("storage is down")
+		}},
+		{Name: "broken", Type: NewNonNull(String), Resolve: func(ResolveParams) (interface{}, error) {
+			return nil, nil
+		}},
+		{Name: "panics", Type: String, Resolve: func(ResolveParams) (interface{}, error) {
+			panic("nil map")
+		}},
+	}}
+
+	schema, err := NewSchema(query)
+	if err != nil {
+		t.Fatalf("NewSchema: %v", err)
+	}
+	schema.MaxDepth = maxDepth
+	return schema
+}
+
+func execute(t *testing.T, schema *Schema, req Request) (*Response, string) {
+	t.Helper()
+	resp := schema.Execute(context.Background(), req)
+	body, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return resp, string(body)
+}
+
+func TestExecute(t *testing.T) {
+	var batches int
+	schema := newTestSchema(t, 0, &batches)
+
+	resp, body := execute(t, schema, Request{
+		Query: `query Directory($first: Int, $withFriends: Boolean!) {
+			people: contacts(first: $first) {
+				__typename
+				...Card
+				friends @include(if: $withFriends) { name friends { id } }
+				... on Contact { name }
+				... @skip(if: true) { id }
+			}
+			hello(times: [1, 2])
+			greeting: hello(name: "Анна")
+		}
+		fragment Card on Contact { id name }
+		query Other { hello }`,
+		Variables:     map[string]interface{}{"first": float64(2), "withFriends": true},
+		OperationName: "Directory",
+	})
+	if !resp.Executed() || len(resp.Errors) > 0 {
+		t.Fatalf("response = %s", body)
+	}
+
+	want := `{"data":{"people":[` +
+		`{"__typename":"Contact","id":"1","name":"Анна","friends":[{"name":"Борис","friends":[{"id":"1"}]},{"name":"Вера","friends":[]}]},` +
+		`{"__typename":"Contact","id":"2","name":"Борис","friends":[{"name":"Анна","friends":[{"id":"2"},{"id":"3"}]}]}],` +
+		`"hello":"hello, world[1 2]","greeting":"hello, Анна"}}`
+	if body != want {
+		t.Errorf("body =\n%s\nwant\n%s", body, want)
+	}
+	// friends вычисляется одним вызовом на уровень ответа, а не на каждый контакт
+	if batches != 2 {
+		t.Errorf("friends batches = %d, want 2", batches)
+	}
+}
+
+func TestExecuteVariables(t *testing.T) {
+	schema := newTestSchema(t, 0, new(int))
+	tests := []struct {
+		name string
+		req  Request
+		want string
+	}{
+		{
+			name: "default variable",
+			req:  Request{Query: `query ($id: ID = 3) { contact(id: $id) { name } }`},
+			want: `{"data":{"contact":{"name":"Вера"}}}`,
+		},
+		{
+			name: "integer ID from JSON",
+			req:  Request{Query: `query ($id: ID!) { contact(id: $id) { name } }`, Variables: map[string]interface{}{"id": float64(2)}},
+			want: `{"data":{"contact":{"name":"Борис"}}}`,
+		},
+		{
+			name: "missing optional variable uses argument default",
+			req:  Request{Query: `query ($name: String) { hello(name: $name) }`},
+			want: `{"data":{"hello":"hello, world"}}`,
+		},
+		{
+			name: "single value as list",
+			req:  Request{Query: `query ($times: [Int!]) { hello(times: $times) }`, Variables: map[string]interface{}{"times": float64(7)}},
+			want: `{"data":{"hello":"hello, world[7]"}}`,
+		},
+		{
+			name: "null object",
+			req:  Request{Query: `{ contact(id: 42) { name } }`},
+			want: `{"data":{"contact":null}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, body := execute(t, schema, tt.req); body != tt.want {
+				t.Errorf("body = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteFieldErrors(t *testing.T) {
+	schema := newTestSchema(t, 0, new(int))
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "resolver error",
+			query: `{ failing hello }`,
+			want:  `{"data":{"failing":null,"hello":"hello, world"},"errors":[{"message":"storage is down","locations":[{"line":1,"column":3}],"path":["failing"]}]}`,
+		},
+		{
+			name:  "resolver error in argument",
+			query: `{ a: contact(id: "x") { id } b: contact(id: 1) { id } }`,
+			want:  `{"data":{"a":null,"b":{"id":"1"}},"errors":[{"message":"invalid ID","locations":[{"line":1,"column":3}],"path":["a"]}]}`,
+		},
+		{
+			name:  "null in non-null field nulls the parent",
+			query: `{ broken hello }`,
+			want:  `{"data":null,"errors":[{"message":"cannot return null for non-nullable field","locations":[{"line":1,"column":3}],"path":["broken"]}]}`,
+		},
+		{
+			name:  "panic is recovered",
+			query: `{ panics }`,
+			want:  `{"data":{"panics":null},"errors":[{"message":"internal error resolving field \"panics\": nil map","locations":[{"line":1,"column":3}],"path":["panics"]}]}`,
+		},
+		{
+			name:  "batch of wrong length",
+			query: `{ contacts(first: 2) { mismatch } }`,
+			want: `{"data":{"contacts":[{"mismatch":null},{"mismatch":null}]},"errors":[` +
+				`{"message":"field \"mismatch\" resolved 1 values for 2 objects","locations":[{"line":1,"column":24}],"path":["contacts",0,"mismatch"]},` +
+				`{"message":"field \"mismatch\" resolved 1 values for 2 objects","locations":[{"line":1,"column":24}],"path":["contacts",1,"mismatch"]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := execute(t, schema, Request{Query: tt.query})
+			if !resp.Executed() {
+				t.Fatalf("field errors must not reject the request: %s", body)
+			}
+			if body != tt.want {
+				t.Errorf("body =\n%s\nwant\n%s", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteRejectsInvalidRequests(t *testing.T) {
+	schema := newTestSchema(t, 0, new(int))
+	tests := []struct {
+		name string
+		req  Request
+		want string
+	}{
+		{name: "syntax error", req: Request{Query: `{ contacts {`}, want: "Syntax error: expected name, found end of document"},
+		{name: "mutation", req: Request{Query: `mutation { hello }`}, want: "mutation operations are not supported"},
+		{name: "several operations without name", req: Request{Query: `query A { hello } query B { hello }`}, want: "operationName is required for a document with several operations"},
+		{name: "unknown operation", req: Request{Query: `query A { hello }`, OperationName: "B"}, want: `unknown operation "B"`},
+		{name: "unknown field", req: Request{Query: `{ contacts { email } }`}, want: `cannot query field "email" on type "Contact"`},
+		{name: "object without subfields", req: Request{Query: `{ contacts }`}, want: `field "contacts" of type [Contact!]! must have a selection of subfields`},
+		{name: "scalar with subfields", req: Request{Query: `{ hello { length } }`}, want: `field "hello" of type String! must not have a selection of subfields`},
+		{name: "typename with subfields", req: Request{Query: `{ __typename { a } }`}, want: `field "__typename" has no arguments and subfields`},
+		{name: "unknown argument", req: Request{Query: `{ hello(lang: "ru") }`}, want: `unknown argument "lang" on field "hello"`},
+		{name: "missing required argument", req: Request{Query: `{ contact { id } }`}, want: `argument "id" of type ID! is required on field "contact"`},
+		{name: "argument of wrong type", req: Request{Query: `{ contacts(first: "two") { id } }`}, want: `argument "first" on field "contacts": expected Int, found two`},
+		{name: "missing required variable", req: Request{Query: `query ($id: ID!) { contact(id: $id) { id } }`}, want: "variable $id of type ID! is required"},
+		{name: "variable of wrong type", req: Request{Query: `query ($n: Int) { contacts(first: $n) { id } }`, Variables: map[string]interface{}{"n": 1.5}}, want: "variable $n: expected Int, found 1.5"},
+		{name: "variable of object type", req: Request{Query: `query ($c: Contact) { hello }`}, want: "variable $c: type Contact cannot be used as input"},
+		{name: "variable of unknown type", req: Request{Query: `query ($c: Date) { hello }`}, want: "variable $c: unknown type Date"},
+		{name: "conflicting aliases", req: Request{Query: `{ a: hello a: failing }`}, want: `fields "hello" and "failing" conflict because they have the same response name "a"`},
+		{name: "unknown fragment", req: Request{Query: `{ ...Missing }`}, want: `unknown fragment "Missing"`},
+		{name: "fragment on another type", req: Request{Query: `{ ...Card } fragment Card on Contact { id }`}, want: `fragment "Card" on Contact cannot be spread on type Query`},
+		{name: "inline fragment on another type", req: Request{Query: `{ ... on Contact { id } }`}, want: "inline fragment on Contact cannot be spread on type Query"},
+		{name: "unknown directive", req: Request{Query: `{ hello @deprecated }`}, want: "unknown directive @deprecated"},
+		{name: "directive without if", req: Request{Query: `{ hello @include }`}, want: `directive @include requires the single argument "if"`},
+		{name: "directive with null", req: Request{Query: `{ hello @skip(if: null) }`}, want: "directive @skip: expected non-null Boolean"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := execute(t, schema, tt.req)
+			if resp.Executed() {
+				t.Fatalf("request was executed: %s", body)
+			}
+			if len(resp.Errors) != 1 || resp.Errors[0].Message != tt.want {
+				t.Fatalf("errors = %s, want %q", body, tt.want)
+			}
+			if strings.Contains(body, `"data"`) {
+				t.Errorf("rejected request has data: %s", body)
+			}
+		})
+	}
+}
+
+func TestExecuteMaxDepth(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxDepth int
+		query    string
+		ok       bool
+	}{
+		{name: "at limit", maxDepth: 3, query: `{ contacts { friends { id } } }`, ok: true},
+		{name: "scalar leaves do not count", maxDepth: 2, query: `{ contacts { id __typename } hello }`, ok: true},
+		{name: "over limit", maxDepth: 3, query: `{ contacts { friends { friends { id } } } }`},
+		{name: "over limit through fragments", maxDepth: 3, query: `{ contacts { ...F } } fragment F on Contact { friends { ...G } } fragment G on Contact { friends { id } }`},
+		{name: "over limit through inline fragments", maxDepth: 3, query: `{ contacts { ... { friends { ... on Contact { friends { id } } } } } }`},
+		{name: "over limit in skipped branch", maxDepth: 2, query: `{ contacts { friends @skip(if: true) { id } } }`, ok: true},
+		{name: "unlimited", maxDepth: 0, query: `{ contacts { friends { friends { friends { friends { id } } } } } }`, ok: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := execute(t, newTestSchema(t, tt.maxDepth, new(int)), Request{Query: tt.query})
+			if tt.ok {
+				if !resp.Executed() || len(resp.Errors) > 0 {
+					t.Fatalf("response = %s", body)
+				}
+				return
+			}
+			if resp.Executed() {
+				t.Fatalf("deep query was executed: %s", body)
+			}
+			if want := fmt.Sprintf("query is nested deeper than %d levels", tt.maxDepth); len(resp.Errors) != 1 || resp.Errors[0].Message != want {
+				t.Fatalf("errors = %s, want %q", body, want)
+			}
+		})
+	}
+}
+
+func TestExecuteRejectsFragmentCycles(t *testing.T) {
+	// Цепочка из n фрагментов F1 -> F2 -> ... -> Fn на одном уровне выборки
+	chain := func(n int) string {
+		var b strings.Builder
+		b.WriteString(`{ contacts { ...F1 } }`)
+		for i := 1; i < n; i++ {
+			fmt.Fprintf(&b, " fragment F%d on Contact { id ...F%d }", i, i+1)
+		}
+		fmt.Fprintf(&b, " fragment F%d on Contact { name }", n)
+		return b.String()
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "self reference", query: `{ contacts { ...F } } fragment F on Contact { id ...F }`, want: `fragment "F" references itself`},
+		{name: "mutual references", query: `{ contacts { ...A } } fragment A on Contact { ...B } fragment B on Contact { id ...A }`},
+		{name: "through nested field", query: `{ contacts { ...F } } fragment F on Contact { friends { ...F } }`, want: `fragment "F" references itself`},
+		{name: "through inline fragment", query: `{ contacts { ...F } } fragment F on Contact { ... @include(if: true) { ...F } }`, want: `fragment "F" references itself`},
+		{name: "unused cycle", query: `{ hello } fragment F on Contact { ...F }`, want: `fragment "F" references itself`},
+		{name: "chain over limit", query: chain(maxNesting + 1), want: "fragments are nested deeper than 64 levels"},
+		// Хвост цепочки может быть проверен раньше ее начала: длина учитывается и для уже проверенных фрагментов
+		{name: "chain over limit with shared tail", query: chain(maxNesting+1) + ` fragment Tail on Contact { ...F2 }`, want: "fragments are nested deeper than 64 levels"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Фрагменты обходятся в порядке map: повторы проверяют результат при разном порядке
+			for i := 0; i < 20; i++ {
+				resp, body := execute(t, newTestSchema(t, 0, new(int)), Request{Query: tt.query})
+				if resp.Executed() || len(resp.Errors) != 1 {
+					t.Fatalf("response = %s, want a single error", body)
+				}
+				msg := resp.Errors[0].Message
+				if tt.want == "" {
+					if msg != `fragment "A" references itself` && msg != `fragment "B" references itself` {
+						t.Fatalf("error = %q, want a cycle through A and B", msg)
+					}
+					continue
+				}
+				if msg != tt.want {
+					t.Fatalf("error = %q, want %q", msg, tt.want)
+				}
+			}
+		})
+	}
+
+	resp, body := execute(t, newTestSchema(t, 0, new(int)), Request{Query: chain(maxNesting)})
+	if !resp.Executed() || len(resp.Errors) > 0 {
+		t.Fatalf("chain of %d fragments: %s", maxNesting, body)
+	}
+	if !strings.HasPrefix(body, `{"data":{"contacts":[{"id":"1","name":"Анна"}`) {
+		t.Errorf("body = %s", body)
+	}
+}
@@ -0,0 +1,797 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Document - разобранный запрос GraphQL
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation - операция документа (поддерживаются только query)
+type Operation struct {
+	Type         string // query, mutation или subscription
+	Name         string
+	Variables    []*VariableDefinition
+	SelectionSet []Selection
+	Location     Location
+}
+
+// VariableDefinition - объявление переменной операции: $name: Type = default
+type VariableDefinition struct {
+	Name       string
+	Type       *TypeRef
+	Default    interface{}
+	HasDefault bool
+}
+
+// TypeRef - ссылка на тип в объявлении переменной: Name, [Elem] или с ! (NonNull)
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef // Для списка
+	NonNull bool
+}
+
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Selection - поле, фрагмент или встроенный фрагмент в наборе выборки
+type Selection interface {
+	selection()
+}
+
+// FieldNode - выбранное поле: alias: name(args) @directives { selection }
+type FieldNode struct {
+	Alias        string
+	Name         string
+	Arguments    []*ArgumentNode
+	Directives   []*Directive
+	SelectionSet []Selection
+	Location     Location
+}
+
+// ResponseKey - имя поля в ответе: псевдоним, если задан, иначе имя
+func (f *FieldNode) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// FragmentSpread - ссылка на именованный фрагмент: ...Name
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Location   Location
+}
+
+// InlineFragment - встроенный фрагмент: ... on Type { selection }
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+}
+
+func (*FieldNode) selection()      {}
+func (*FragmentSpread) selection() {}
+func (*InlineFragment) selection() {}
+
+// Fragment - именованный фрагмент документа
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	SelectionSet  []Selection
+	Location      Location
+}
+
+// ArgumentNode - аргумент поля или директивы
+type ArgumentNode struct {
+	Name  string
+	Value interface{}
+}
+
+// Directive - директива поля или фрагмента (@include, @skip)
+type Directive struct {
+	Name      string
+	Arguments []*ArgumentNode
+}
+
+// Значения в документе хранятся как nil, bool, int64, float64, string, []interface{},
+// map[string]interface{}, а также Variable и EnumValue.
+
+// Variable - ссылка на переменную запроса: $name
+type Variable string
+
+// EnumValue - значение перечисления без кавычек
+type EnumValue string
+
+// Location - строка и столбец в тексте запроса (с 1)
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Виды лексем
+const (
+	tokenEOF = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  int
+	value string
+	loc   Location
+}
+
+type lexer struct {
+	src  string
+	pos  int
+	line int
+	col  int
+}
+
+// next возвращает следующую лексему, пропуская пробелы, запятые и комментарии.
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		ch := l.src[l.pos]
+		switch {
+		case ch == '\n':
+			l.pos++
+			l.line++
+			l.col = 1
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == ',':
+			l.pos++
+			l.col++
+		case ch == '#':
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			if end < 0 {
+				end = len(l.src) - l.pos
+			}
+			l.col += utf8.RuneCountInString(l.src[l.pos : l.pos+end])
+			l.pos += end
+		case strings.HasPrefix(l.src[l.pos:], "\ufeff"):
+			l.pos += len("\ufeff")
+		default:
+			return l.lex()
+		}
+	}
+	return token{kind: tokenEOF, loc: Location{l.line, l.col}}, nil
+}
+
+func (l *lexer) lex() (token, error) {
+	loc := Location{l.line, l.col}
+	start := l.pos
+	ch := l.src[l.pos]
+
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.advance(3)
+		return token{kind: tokenPunct, value: "...", loc: loc}, nil
+	case strings.ContainsRune("!$()[]{}:=@|&", rune(ch)):
+		l.advance(1)
+		return token{kind: tokenPunct, value: string(ch), loc: loc}, nil
+	case ch == '_' || isLetter(ch):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.advance(1)
+		}
+		return token{kind: tokenName, value: l.src[start:l.pos], loc: loc}, nil
+	case ch == '-' || isDigit(ch):
+		return l.lexNumber(loc)
+	case ch == '"':
+		return l.lexString(loc)
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return token{}, syntaxError(loc, "unexpected character %q", r)
+}
+
+func (l *lexer) lexNumber(loc Location) (token, error) {
+	start := l.pos
+	kind := tokenInt
+	if l.src[l.pos] == '-' {
+		l.advance(1)
+	}
+	digits := func() int {
+		n := 0
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.advance(1)
+			n++
+		}
+		return n
+	}
+	if digits() == 0 {
+		return token{}, syntaxError(loc, "invalid number")
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = tokenFloat
+		l.advance(1)
+		if digits() == 0 {
+			return token{}, syntaxError(loc, "invalid number")
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = tokenFloat
+		l.advance(1)
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.advance(1)
+		}
+		if digits() == 0 {
+			return token{}, syntaxError(loc, "invalid number")
+		}
+	}
+	return token{kind: kind, value: l.src[start:l.pos], loc: loc}, nil
+}
+
+func (l *lexer) lexString(loc Location) (token, error) {
+	if strings.HasPrefix(l.src[l.pos:], `"""`) {
+		l.advance(3)
+		end := strings.Index(l.src[l.pos:], `"""`)
+		if end < 0 {
+			return token{}, syntaxError(loc, "unterminated string")
+		}
+		value := l.src[l.pos : l.pos+end]
+		for _, r := range value {
+			if r == '\n' {
+				l.line++
+				l.col = 1
+			} else {
+				l.col++
+			}
+		}
+		l.pos += end + 3
+		l.col += 3
+		return token{kind: tokenString, value: blockStringValue(value), loc: loc}, nil
+	}
+
+	l.advance(1)
+	var b strings.Builder
+	for {
+		if l.pos >= len(l.src) || l.src[l.pos] == '\n' {
+			return token{}, syntaxError(loc, "unterminated string")
+		}
+		ch := l.src[l.pos]
+		if ch == '"' {
+			l.advance(1)
+			return token{kind: tokenString, value: b.String(), loc: loc}, nil
+		}
+		if ch != '\\' {
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			b.WriteRune(r)
+			l.pos += size
+			l.col++
+			continue
+		}
+		if l.pos+1 >= len(l.src) {
+			return token{}, syntaxError(loc, "unterminated string")
+		}
+		esc := l.src[l.pos+1]
+		l.advance(2)
+		switch esc {
+		case '"', '\\', '/':
+			b.WriteByte(esc)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if l.pos+4 > len(l.src) {
+				return token{}, syntaxError(loc, "invalid unicode escape")
+			}
+			code, err := strconv.ParseUint(l.src[l.pos:l.pos+4], 16, 32)
+			if err != nil {
+				return token{}, syntaxError(loc, "invalid unicode escape")
+			}
+			b.WriteRune(rune(code))
+			l.advance(4)
+		default:
+			return token{}, syntaxError(loc, "invalid escape sequence \\%c", esc)
+		}
+	}
+}
+
+func (l *lexer) advance(n int) {
+	l.pos += n
+	l.col += n
+}
+
+// blockStringValue убирает общий отступ и пустые крайние строки блочной строки.
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// maxNesting - наибольшая вложенность наборов выборки, списков и объектов в тексте запроса и цепочек фрагментов.
+// Ограничивает глубину рекурсии разбора и проверки: без него документ из нескольких мегабайт скобок
+// переполняет стек до того, как выборку проверит Schema.MaxDepth.
+const maxNesting = 64
+
+type parser struct {
+	lex   *lexer
+	tok   token
+	depth int
+}
+
+// Parse разбирает текст запроса GraphQL.
+func Parse(query string) (*Document, error) {
+	p := &parser{lex: &lexer{src: query, line: 1, col: 1}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Fragments: map[string]*Fragment{}}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek("{"):
+			loc := p.tok.loc
+			set, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, &Operation{Type: "query", SelectionSet: set, Location: loc})
+		case p.tok.kind == tokenName && (p.tok.value == "query" || p.tok.value == "mutation" || p.tok.value == "subscription"):
+			op, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case p.tok.kind == tokenName && p.tok.value == "fragment":
+			fragment, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := doc.Fragments[fragment.Name]; exists {
+				return nil, syntaxError(fragment.Location, "fragment %q is defined more than once", fragment.Name)
+			}
+			doc.Fragments[fragment.Name] = fragment
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return nil, syntaxError(p.tok.loc, "document has no operations")
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == punct
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.peek(punct) {
+		return syntaxError(p.tok.loc, "expected %q, found %s", punct, p.describe())
+	}
+	return p.advance()
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", syntaxError(p.tok.loc, "expected name, found %s", p.describe())
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) describe() string {
+	if p.tok.kind == tokenEOF {
+		return "end of document"
+	}
+	return strconv.Quote(p.tok.value)
+}
+
+func (p *parser) unexpected() error {
+	return syntaxError(p.tok.loc, "unexpected %s", p.describe())
+}
+
+// enter отмечает вход во вложенную конструкцию; парный вызов leave - при выходе из нее
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > maxNesting {
+		return syntaxError(p.tok.loc, "document is nested deeper than %d levels", maxNesting)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) parseOperation() (*Operation, error) {
+	op := &Operation{Type: p.tok.value, Location: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName {
+		op.Name = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek("(") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		for !p.peek(")") {
+			def, err := p.parseVariableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			op.Variables = append(op.Variables, def)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	set, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.SelectionSet = set
+	return op, nil
+}
+
+func (p *parser) parseVariableDefinition() (*VariableDefinition, error) {
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	typ, err := p.parseTypeRef()
+	if err != nil {
+		return nil, err
+	}
+	def := &VariableDefinition{Name: name, Type: typ}
+	if p.peek("=") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if def.Default, err = p.parseValue(true); err != nil {
+			return nil, err
+		}
+		def.HasDefault = true
+	}
+	return def, nil
+}
+
+func (p *parser) parseTypeRef() (*TypeRef, error) {
+	defer p.leave()
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	var typ *TypeRef
+	if p.peek("[") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		elem, err := p.parseTypeRef()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		typ = &TypeRef{Elem: elem}
+	} else {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		typ = &TypeRef{Name: name}
+	}
+	if p.peek("!") {
+		typ.NonNull = true
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return typ, nil
+}
+
+func (p *parser) parseFragment() (*Fragment, error) {
+	fragment := &Fragment{Location: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	var err error
+	if fragment.Name, err = p.expectName(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenName || p.tok.value != "on" {
+		return nil, syntaxError(p.tok.loc, "expected \"on\", found %s", p.describe())
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if fragment.TypeCondition, err = p.expectName(); err != nil {
+		return nil, err
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if fragment.SelectionSet, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return fragment, nil
+}
+
+func (p *parser) parseSelectionSet() ([]Selection, error) {
+	defer p.leave()
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var set []Selection
+	for !p.peek("}") {
+		selection, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, selection)
+	}
+	if len(set) == 0 {
+		return nil, syntaxError(p.tok.loc, "selection set is empty")
+	}
+	return set, p.advance()
+}
+
+func (p *parser) parseSelection() (Selection, error) {
+	if p.peek("...") {
+		loc := p.tok.loc
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokenName && p.tok.value != "on" {
+			spread := &FragmentSpread{Name: p.tok.value, Location: loc}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			var err error
+			spread.Directives, err = p.parseDirectives()
+			return spread, err
+		}
+		inline := &InlineFragment{}
+		if p.tok.kind == tokenName {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			var err error
+			if inline.TypeCondition, err = p.expectName(); err != nil {
+				return nil, err
+			}
+		}
+		var err error
+		if inline.Directives, err = p.parseDirectives(); err != nil {
+			return nil, err
+		}
+		inline.SelectionSet, err = p.parseSelectionSet()
+		return inline, err
+	}
+
+	field := &FieldNode{Location: p.tok.loc}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	field.Name = name
+	if p.peek(":") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		field.Alias = name
+		if field.Name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	if field.Arguments, err = p.parseArguments(); err != nil {
+		return nil, err
+	}
+	if field.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		if field.SelectionSet, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+func (p *parser) parseArguments() ([]*ArgumentNode, error) {
+	if !p.peek("(") {
+		return nil, nil
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	var args []*ArgumentNode
+	for !p.peek(")") {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue(false)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, &ArgumentNode{Name: name, Value: value})
+	}
+	return args, p.advance()
+}
+
+func (p *parser) parseDirectives() ([]*Directive, error) {
+	var directives []*Directive
+	for p.peek("@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, &Directive{Name: name, Arguments: args})
+	}
+	return directives, nil
+}
+
+// parseValue разбирает значение; в значениях по умолчанию (constant) переменные запрещены.
+func (p *parser) parseValue(constant bool) (interface{}, error) {
+	defer p.leave()
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	tok := p.tok
+	switch {
+	case p.peek("$"):
+		if constant {
+			return nil, syntaxError(tok.loc, "variables are not allowed in default values")
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		return Variable(name), err
+	case p.peek("["):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		list := []interface{}{}
+		for !p.peek("]") {
+			item, err := p.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, p.advance()
+	case p.peek("{"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		object := map[string]interface{}{}
+		for !p.peek("}") {
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if object[name], err = p.parseValue(constant); err != nil {
+				return nil, err
+			}
+		}
+		return object, p.advance()
+	case tok.kind == tokenInt:
+		n, err := strconv.ParseInt(tok.value, 10, 64)
+		if err != nil {
+			return nil, syntaxError(tok.loc, "integer %s is out of range", tok.value)
+		}
+		return n, p.advance()
+	case tok.kind == tokenFloat:
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, syntaxError(tok.loc, "invalid float %s", tok.value)
+		}
+		return f, p.advance()
+	case tok.kind == tokenString:
+		return tok.value, p.advance()
+	case tok.kind == tokenName:
+		var value interface{}
+		switch tok.value {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			value = EnumValue(tok.value)
+		}
+		return value, p.advance()
+	}
+	return nil, p.unexpected()
+}
+
+// syntaxError создает ошибку разбора с позицией в тексте запроса.
+func syntaxError(loc Location, format string, args ...interface{}) *Error {
+	return &Error{Message: "Syntax error: " + fmt.Sprintf(format, args...), Locations: []Location{loc}}
+}
@@ -0,0 +1,220 @@
+package graphql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`
+		# Комментарии, запятые и BOM игнорируются
+		query Contacts($first: Int = 10, $ids: [ID!]!, $active: Boolean) @cached {
+			list: contacts(first: $first, ids: $ids, filter: {name: "Анна", tags: ["a", B]}) {
+				id,
+				...ContactFields @include(if: $active)
+				... on Contact { phone }
+				... @skip(if: false) { email }
+			}
+		}
+
+		fragment ContactFields on Contact {
+			name
+			score(min: -1.5e2, max: 3, exact: null, flag: true)
+		}
+	`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(doc.Operations) != 1 {
+		t.Fatalf("operations = %d, want 1", len(doc.Operations))
+	}
+
+	op := doc.Operations[0]
+	if op.Type != "query" || op.Name != "Contacts" || op.Location != (Location{Line: 3, Column: 3}) {
+		t.Errorf("operation = %s %s at %v", op.Type, op.Name, op.Location)
+	}
+	wantVars := []*VariableDefinition{
+		{Name: "first", Type: &TypeRef{Name: "Int"}, Default: int64(10), HasDefault: true},
+		{Name: "ids", Type: &TypeRef{Elem: &TypeRef{Name: "ID", NonNull: true}, NonNull: true}},
+		{Name: "active", Type: &TypeRef{Name: "Boolean"}},
+	}
+	if !reflect.DeepEqual(op.Variables, wantVars) {
+		t.Errorf("variables = %+v, want %+v", op.Variables, wantVars)
+	}
+	if got := op.Variables[1].Type.String(); got != "[ID!]!" {
+		t.Errorf("type ref = %s, want [ID!]!", got)
+	}
+
+	list := op.SelectionSet[0].(*FieldNode)
+	if list.Alias != "list" || list.Name != "contacts" || list.ResponseKey() != "list" {
+		t.Errorf("field = %s: %s", list.Alias, list.Name)
+	}
+	wantArgs := []*ArgumentNode{
+		{Name: "first", Value: Variable("first")},
+		{Name: "ids", Value: Variable("ids")},
+		{Name: "filter", Value: map[string]interface{}{"name": "Анна", "tags": []interface{}{"a", EnumValue("B")}}},
+	}
+	if !reflect.DeepEqual(list.Arguments, wantArgs) {
+		t.Errorf("arguments = %+v, want %+v", list.Arguments, wantArgs)
+	}
+	if len(list.SelectionSet) != 4 {
+		t.Fatalf("selections = %d, want 4", len(list.SelectionSet))
+	}
+	wantSpread := &FragmentSpread{
+		Name:       "ContactFields",
+		Directives: []*Directive{{Name: "include", Arguments: []*ArgumentNode{{Name: "if", Value: Variable("active")}}}},
+		Location:   Location{Line: 6, Column: 5},
+	}
+	if spread := list.SelectionSet[1]; !reflect.DeepEqual(spread, wantSpread) {
+		t.Errorf("spread = %+v, want %+v", spread, wantSpread)
+	}
+	if inline := list.SelectionSet[2].(*InlineFragment); inline.TypeCondition != "Contact" || inline.SelectionSet[0].(*FieldNode).Name != "phone" {
+		t.Errorf("inline fragment = %+v", inline)
+	}
+	if inline := list.SelectionSet[3].(*InlineFragment); inline.TypeCondition != "" || inline.Directives[0].Name != "skip" || inline.Directives[0].Arguments[0].Value != false {
+		t.Errorf("inline fragment without type = %+v", inline)
+	}
+
+	fragment := doc.Fragments["ContactFields"]
+	if fragment == nil || fragment.TypeCondition != "Contact" || fragment.Location != (Location{Line: 12, Column: 3}) {
+		t.Fatalf("fragment = %+v", fragment)
+	}
+	score := fragment.SelectionSet[1].(*FieldNode)
+	wantScore := []*ArgumentNode{
+		{Name: "min", Value: -150.0},
+		{Name: "max", Value: int64(3)},
+		{Name: "exact", Value: nil},
+		{Name: "flag", Value: true},
+	}
+	if !reflect.DeepEqual(score.Arguments, wantScore) {
+		t.Errorf("literal arguments = %+v, want %+v", score.Arguments, wantScore)
+	}
+}
+
+func TestParseShorthandAndSeveralOperations(t *testing.T) {
+	doc, err := Parse("\ufeff{ a } query B { b } mutation C { c }")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var got []string
+	for _, op := range doc.Operations {
+		got = append(got, op.Type+" "+op.Name)
+	}
+	if want := []string{"query ", "query B", "mutation C"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("operations = %q, want %q", got, want)
+	}
+}
+
+func TestParseStrings(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "escapes", value: `"a\"b\\c\/d\b\f\n\r\t"`, want: "a\"b\\c/d\b\f\n\r\t"},
+		{name: "unicode escape", value: `"\u0410\u043d\u043d\u0430"`, want: "Анна"},
+		{name: "utf-8", value: `"Петрова"`, want: "Петрова"},
+		{name: "block string", value: "\"\"\"\n    Первая строка\n      отступ\n\n    последняя\n  \"\"\"", want: "Первая строка\n  отступ\n\nпоследняя"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse("{ a(s: " + tt.value + ") }")
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := doc.Operations[0].SelectionSet[0].(*FieldNode).Arguments[0].Value; got != tt.want {
+				t.Errorf("value = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLocationsAfterBlockString(t *testing.T) {
+	doc, err := Parse("{\n  a(s: \"\"\"x\ny\"\"\") b\n}")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	b := doc.Operations[0].SelectionSet[1].(*FieldNode)
+	if want := (Location{Line: 3, Column: 7}); b.Location != want {
+		t.Errorf("location = %v, want %v", b.Location, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+		loc   Location
+	}{
+		{name: "empty document", query: " # только комментарий", want: "Syntax error: document has no operations", loc: Location{1, 22}},
+		{name: "fragments only", query: "fragment F on Q { a }", want: "Syntax error: document has no operations", loc: Location{1, 22}},
+		{name: "empty selection", query: "{ a { } }", want: "Syntax error: selection set is empty", loc: Location{1, 7}},
+		{name: "unexpected end", query: "{ a", want: "Syntax error: expected name, found end of document", loc: Location{1, 4}},
+		{name: "unknown token", query: "{ a } b", want: `Syntax error: unexpected "b"`, loc: Location{1, 7}},
+		{name: "unexpected character", query: "{ a % }", want: `Syntax error: unexpected character '%'`, loc: Location{1, 5}},
+		{name: "unterminated string", query: "{ a(s: \"abc\n\") }", want: "Syntax error: unterminated string", loc: Location{1, 8}},
+		{name: "unterminated block string", query: `{ a(s: """abc) }`, want: "Syntax error: unterminated string", loc: Location{1, 8}},
+		{name: "invalid escape", query: `{ a(s: "\x") }`, want: `Syntax error: invalid escape sequence \x`, loc: Location{1, 8}},
+		{name: "invalid unicode escape", query: `{ a(s: "\u04") }`, want: "Syntax error: invalid unicode escape", loc: Location{1, 8}},
+		{name: "invalid number", query: "{ a(n: -x) }", want: "Syntax error: invalid number", loc: Location{1, 8}},
+		{name: "invalid exponent", query: "{ a(n: 1e) }", want: "Syntax error: invalid number", loc: Location{1, 8}},
+		{name: "integer out of range", query: "{ a(n: 9223372036854775808) }", want: "Syntax error: integer 9223372036854775808 is out of range", loc: Location{1, 8}},
+		{name: "variable in default", query: "query ($a: Int = $b) { a }", want: "Syntax error: variables are not allowed in default values", loc: Location{1, 18}},
+		{name: "missing argument value", query: "{ a(n:) }", want: `Syntax error: unexpected ")"`, loc: Location{1, 7}},
+		{name: "missing type condition", query: "{ a } fragment F { a }", want: `Syntax error: expected "on", found "{"`, loc: Location{1, 18}},
+		{name: "duplicate fragment", query: "{ a } fragment F on Q { a } fragment F on Q { b }", want: `Syntax error: fragment "F" is defined more than once`, loc: Location{1, 29}},
+		{name: "unclosed list type", query: "query ($a: [Int) { a }", want: `Syntax error: expected "]", found ")"`, loc: Location{1, 16}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			gqlErr, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("error = %v, want *Error", err)
+			}
+			if gqlErr.Message != tt.want {
+				t.Errorf("message = %q, want %q", gqlErr.Message, tt.want)
+			}
+			if want := []Location{tt.loc}; !reflect.DeepEqual(gqlErr.Locations, want) {
+				t.Errorf("locations = %v, want %v", gqlErr.Locations, want)
+			}
+		})
+	}
+}
+
+func TestParseNestingLimit(t *testing.T) {
+	// Вложенные наборы выборки: операция и maxNesting-1 полей с выборкой
+	nested := func(levels int) string {
+		return "{" + strings.Repeat("a{", levels-1) + "b" + strings.Repeat("}", levels)
+	}
+	tests := []struct {
+		name  string
+		query string
+		ok    bool
+	}{
+		{name: "selection sets at limit", query: nested(maxNesting), ok: true},
+		{name: "selection sets over limit", query: nested(maxNesting + 1)},
+		{name: "list values at limit", query: "{ a(v: " + strings.Repeat("[", maxNesting-1) + strings.Repeat("]", maxNesting-1) + ") }", ok: true},
+		{name: "list values over limit", query: "{ a(v: " + strings.Repeat("[", maxNesting) + strings.Repeat("]", maxNesting) + ") }"},
+		{name: "object values over limit", query: "{ a(v: " + strings.Repeat("{a:", maxNesting) + "1" + strings.Repeat("}", maxNesting) + ") }"},
+		{name: "list types over limit", query: "query ($v: " + strings.Repeat("[", maxNesting) + "Int" + strings.Repeat("]", maxNesting) + ") { a }"},
+		// Без ограничения такой документ переполняет стек и завершает процесс
+		{name: "megabytes of braces", query: nested(1 << 20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("Parse: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "document is nested deeper than 64 levels") {
+				t.Fatalf("error = %v, want nesting limit", err)
+			}
+		})
+	}
+}
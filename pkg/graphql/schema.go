@@ -0,0 +1,360 @@
+// Package graphql - минимальная реализация GraphQL для чтения данных: разбор запросов,
+// проверка по схеме и выполнение с пакетной загрузкой вложенных полей.
+// Поддерживаются query с переменными, псевдонимами, фрагментами и директивами @include/@skip;
+// мутации, подписки и интроспекция (кроме __typename) не поддерживаются, схема доступна как SDL.
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Type - тип GraphQL: *Scalar, *Object, *List или *NonNull
+type Type interface {
+	String() string
+}
+
+// Scalar - скалярный тип
+type Scalar struct {
+	Name        string
+	Description string
+	// Serialize переводит значение резолвера в значение ответа
+	Serialize func(value interface{}) (interface{}, error)
+	// ParseValue переводит значение аргумента (литерал запроса или переменную из JSON) во внутреннее
+	ParseValue func(value interface{}) (interface{}, error)
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+// Object - объектный тип с полями
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+func (o *Object) String() string { return o.Name }
+
+// Field возвращает поле по имени или nil.
+func (o *Object) Field(name string) *Field {
+	for _, f := range o.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// List - список значений типа OfType
+type List struct {
+	OfType Type
+}
+
+func (l *List) String() string { return "[" + l.OfType.String() + "]" }
+
+// NonNull - значение типа OfType, не допускающее null
+type NonNull struct {
+	OfType Type
+}
+
+func (n *NonNull) String() string { return n.OfType.String() + "!" }
+
+// NewList возвращает тип [t].
+func NewList(t Type) *List { return &List{OfType: t} }
+
+// NewNonNull возвращает тип t!.
+func NewNonNull(t Type) *NonNull { return &NonNull{OfType: t} }
+
+// Field - поле объектного типа. Задается Resolve или Batch.
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Argument
+	// Resolve вычисляет поле для одного объекта
+	Resolve func(p ResolveParams) (interface{}, error)
+	// Batch вычисляет поле сразу для всех объектов одного уровня ответа и возвращает значения
+	// в том же порядке. Используется вместо Resolve, чтобы загружать вложенные данные одним запросом.
+	Batch func(p BatchParams) ([]interface{}, error)
+}
+
+// Argument - аргумент поля
+type Argument struct {
+	Name        string
+	Description string
+	Type        Type
+	Default     interface{} // Используется, если аргумент не передан; nil - без значения по умолчанию
+}
+
+// ResolveParams - параметры вычисления поля для одного объекта
+type ResolveParams struct {
+	Context context.Context
+	Source  interface{} // Родительский объект; для корневых полей - nil
+	Args    map[string]interface{}
+}
+
+// BatchParams - параметры пакетного вычисления поля
+type BatchParams struct {
+	Context context.Context
+	Sources []interface{}
+	Args    map[string]interface{}
+}
+
+// Schema - схема с корневым типом Query
+type Schema struct {
+	Query *Object
+	// MaxDepth - наибольшая вложенность выборки; 0 - без ограничения
+	MaxDepth int
+	types    map[string]Type
+}
+
+// NewSchema проверяет схему и собирает ее типы.
+func NewSchema(query *Object) (*Schema, error) {
+	s := &Schema{Query: query, types: map[string]Type{}}
+	for _, scalar := range []*Scalar{Int, Float, String, Boolean, ID} {
+		s.types[scalar.Name] = scalar
+	}
+	if err := s.collect(query); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Schema) collect(t Type) error {
+	named := namedType(t)
+	if existing, ok := s.types[named.String()]; ok {
+		if existing != named {
+			return fmt.Errorf("graphql: type %s is defined more than once", named)
+		}
+		return nil
+	}
+	s.types[named.String()] = named
+
+	obj, ok := named.(*Object)
+	if !ok {
+		return nil
+	}
+	for _, f := range obj.Fields {
+		if (f.Resolve == nil) == (f.Batch == nil) {
+			return fmt.Errorf("graphql: field %s.%s must have exactly one of Resolve and Batch", obj.Name, f.Name)
+		}
+		if err := s.collect(f.Type); err != nil {
+			return err
+		}
+		for _, arg := range f.Args {
+			if _, ok := namedType(arg.Type).(*Scalar); !ok {
+				return fmt.Errorf("graphql: argument %s.%s(%s) must be a scalar or a list of scalars", obj.Name, f.Name, arg.Name)
+			}
+			if err := s.collect(arg.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// namedType снимает обертки List и NonNull.
+func namedType(t Type) Type {
+	for {
+		switch w := t.(type) {
+		case *List:
+			t = w.OfType
+		case *NonNull:
+			t = w.OfType
+		default:
+			return t
+		}
+	}
+}
+
+// SDL возвращает схему на языке определения схем GraphQL.
+func (s *Schema) SDL() string {
+	names := make([]string, 0, len(s.types))
+	for name, t := range s.types {
+		if t == s.Query {
+			continue
+		}
+		if scalar, ok := t.(*Scalar); ok && isBuiltinScalar(scalar) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	writeType := func(t Type) {
+		switch t := t.(type) {
+		case *Scalar:
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "scalar %s\n\n", t.Name)
+		case *Object:
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "type %s {\n", t.Name)
+			for _, f := range t.Fields {
+				writeDescription(&b, "  ", f.Description)
+				b.WriteString("  " + f.Name)
+				if len(f.Args) > 0 {
+					args := make([]string, len(f.Args))
+					for i, arg := range f.Args {
+						args[i] = arg.Name + ": " + arg.Type.String()
+						if arg.Default != nil {
+							args[i] += " = " + formatDefault(arg.Default)
+						}
+					}
+					b.WriteString("(" + strings.Join(args, ", ") + ")")
+				}
+				b.WriteString(": " + f.Type.String() + "\n")
+			}
+			b.WriteString("}\n\n")
+		}
+	}
+	writeType(s.Query)
+	for _, name := range names {
+		writeType(s.types[name])
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description != "" {
+		fmt.Fprintf(b, "%s%s\n", indent, strconv.Quote(description))
+	}
+}
+
+func formatDefault(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(value)
+}
+
+func isBuiltinScalar(s *Scalar) bool {
+	return s == Int || s == Float || s == String || s == Boolean || s == ID
+}
+
+// Встроенные скалярные типы
+var (
+	Int = &Scalar{
+		Name:       "Int",
+		Serialize:  serializeInt,
+		ParseValue: parseInt,
+	}
+	Float = &Scalar{
+		Name: "Float",
+		Serialize: func(value interface{}) (interface{}, error) {
+			switch v := value.(type) {
+			case float64:
+				return v, nil
+			case float32:
+				return float64(v), nil
+			}
+			return serializeInt(value)
+		},
+		ParseValue: func(value interface{}) (interface{}, error) {
+			switch v := value.(type) {
+			case float64:
+				return v, nil
+			case int64:
+				return float64(v), nil
+			}
+			return nil, fmt.Errorf("expected Float, found %v", value)
+		},
+	}
+	String = &Scalar{
+		Name: "String",
+		Serialize: func(value interface{}) (interface{}, error) {
+			switch v := value.(type) {
+			case string:
+				return v, nil
+			case fmt.Stringer:
+				return v.String(), nil
+			}
+			return nil, fmt.Errorf("cannot serialize %T as String", value)
+		},
+		ParseValue: func(value interface{}) (interface{}, error) {
+			if s, ok := value.(string); ok {
+				return s, nil
+			}
+			return nil, fmt.Errorf("expected String, found %v", value)
+		},
+	}
+	Boolean = &Scalar{
+		Name: "Boolean",
+		Serialize: func(value interface{}) (interface{}, error) {
+			if b, ok := value.(bool); ok {
+				return b, nil
+			}
+			return nil, fmt.Errorf("cannot serialize %T as Boolean", value)
+		},
+		ParseValue: func(value interface{}) (interface{}, error) {
+			if b, ok := value.(bool); ok {
+				return b, nil
+			}
+			return nil, fmt.Errorf("expected Boolean, found %v", value)
+		},
+	}
+	// ID сериализуется строкой; в аргументах принимает строку или целое число и возвращает строку
+	ID = &Scalar{
+		Name: "ID",
+		Serialize: func(value interface{}) (interface{}, error) {
+			if s, ok := value.(string); ok {
+				return s, nil
+			}
+			n, err := serializeInt(value)
+			if err != nil {
+				return nil, fmt.Errorf("cannot serialize %T as ID", value)
+			}
+			return strconv.FormatInt(n.(int64), 10), nil
+		},
+		ParseValue: func(value interface{}) (interface{}, error) {
+			if s, ok := value.(string); ok {
+				return s, nil
+			}
+			n, err := parseInt(value)
+			if err != nil {
+				return nil, fmt.Errorf("expected ID, found %v", value)
+			}
+			return strconv.Itoa(n.(int)), nil
+		},
+	}
+)
+
+func serializeInt(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		if v > math.MaxInt64 {
+			return nil, fmt.Errorf("%d is out of range", v)
+		}
+		return int64(v), nil
+	}
+	return nil, fmt.Errorf("cannot serialize %T as Int", value)
+}
+
+// parseInt принимает целые литералы запроса (int64) и числа из JSON переменных (float64 без дробной части).
+func parseInt(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case int64:
+		if v >= math.MinInt32 && v <= math.MaxInt32 {
+			return int(v), nil
+		}
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt32 && v <= math.MaxInt32 {
+			return int(v), nil
+		}
+	}
+	return nil, fmt.Errorf("expected Int, found %v", value)
+}
@@ -0,0 +1,203 @@
+// Package health периодически проверяет внешние зависимости (Redis, SQLite, Telegram API, SMTP)
+// и хранит результаты проверок за последние сутки для страницы состояния. Результат последней проверки
+// хранится и в памяти: по нему маршруты, зависящие от недоступной зависимости, сразу отвечают 503.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Window - период, за который хранятся проверки и считается доступность
+const Window = 24 * time.Hour
+
+// probeTimeout - проверка дольше считается неудачной
+const probeTimeout = 5 * time.Second
+
+// Состояния зависимости и сервиса в целом
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDegraded = "degraded" // Только для сервиса в целом: часть зависимостей недоступна
+	StatusUnknown  = "unknown"  // Проверок еще не было
+)
+
+// Check - проверка одной зависимости. Probe возвращает ошибку, если зависимость недоступна.
+type Check struct {
+	Name  string
+	Probe func(ctx context.Context) error
+}
+
+// Sample - результат одной проверки
+type Sample struct {
+	At      time.Time
+	OK      bool
+	Latency time.Duration
+}
+
+// Store хранит результаты проверок. Записи старше Window удаляются.
+type Store interface {
+	Record(ctx context.Context, name string, sample Sample) error
+	// Samples возвращает проверки зависимости не ранее since в порядке времени
+	Samples(ctx context.Context, name string, since time.Time) ([]Sample, error)
+}
+
+// DependencyStatus - сводка по зависимости за Window
+type DependencyStatus struct {
+	Name          string
+	Status        string
+	LastCheckedAt *time.Time
+	Latency       time.Duration // Последней проверки
+	AvgLatency    time.Duration // Средняя по успешным проверкам
+	Uptime        float64       // Доля успешных проверок, 0..1
+	Checks        int
+}
+
+// Monitor выполняет проверки по расписанию и сводит их результаты
+type Monitor struct {
+	checks []Check
+	store  Store
+	logger *slog.Logger
+
+	// last - последняя проверка каждой зависимости; не зависит от доступности store (он может быть в Redis)
+	mu   sync.RWMutex
+	last map[string]Sample
+}
+
+// NewMonitor создает Monitor для проверок checks с хранилищем store
+func NewMonitor(checks []Check, store Store, logger *slog.Logger) *Monitor {
+	return &Monitor{
+		checks: checks,
+		store:  store,
+		logger: logger,
+		last:   make(map[string]Sample, len(checks)),
+	}
+}
+
+// Run проверяет зависимости сразу и затем каждые interval до отмены ctx
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	m.CheckAll(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.CheckAll(ctx)
+		}
+	}
+}
+
+// CheckAll выполняет все проверки параллельно и сохраняет результаты
+func (m *Monitor) CheckAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, check := range m.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			sample := probe(ctx, check)
+			m.mu.Lock()
+			m.last[check.Name] = sample
+			m.mu.Unlock()
+			if !sample.OK {
+				m.logger.WarnContext(ctx, "Dependency health check failed", slog.String("dependency", check.Name), slog.Duration("latency", sample.Latency))
+			}
+			if err := m.store.Record(ctx, check.Name, sample); err != nil {
+				m.logger.ErrorContext(ctx, "Failed to record health check", slog.String("dependency", check.Name), slog.Any("error", err))
+			}
+		}(check)
+	}
+	wg.Wait()
+}
+
+func probe(ctx context.Context, check Check) Sample {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	start := time.Now()
+	err := check.Probe(ctx)
+	return Sample{At: start, OK: err == nil, Latency: time.Since(start)}
+}
+
+// Available сообщает, прошла ли последняя проверка зависимости name. До первой проверки
+// и для зависимостей без проверки возвращает true, чтобы не отклонять запросы при запуске.
+func (m *Monitor) Available(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sample, ok := m.last[name]
+	return !ok || sample.OK
+}
+
+// Status возвращает сводку по каждой зависимости за последние сутки в порядке регистрации проверок.
+// Если история недоступна (хранилище в упавшем Redis), сводка строится по последней проверке.
+func (m *Monitor) Status(ctx context.Context, now time.Time) []DependencyStatus {
+	statuses := make([]DependencyStatus, 0, len(m.checks))
+	for _, check := range m.checks {
+		samples, err := m.store.Samples(ctx, check.Name, now.Add(-Window))
+		if err != nil {
+			m.logger.WarnContext(ctx, "Failed to read health check history, using last check", slog.String("dependency", check.Name), slog.Any("error", err))
+			samples = nil
+			m.mu.RLock()
+			if sample, ok := m.last[check.Name]; ok {
+				samples = []Sample{sample}
+			}
+			m.mu.RUnlock()
+		}
+		statuses = append(statuses, summarize(check.Name, samples))
+	}
+	return statuses
+}
+
+// Overall сводит состояния зависимостей в состояние сервиса
+func Overall(statuses []DependencyStatus) string {
+	up, down := 0, 0
+	for _, s := range statuses {
+		switch s.Status {
+		case StatusUp:
+			up++
+		case StatusDown:
+			down++
+		}
+	}
+	switch {
+	case down == 0 && up == 0:
+		return StatusUnknown
+	case down == 0:
+		return StatusUp
+	case up == 0:
+		return StatusDown
+	}
+	return StatusDegraded
+}
+
+func summarize(name string, samples []Sample) DependencyStatus {
+	status := DependencyStatus{Name: name, Status: StatusUnknown, Checks: len(samples)}
+	if len(samples) == 0 {
+		return status
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].At.Before(samples[j].At) })
+
+	var ok int
+	var total time.Duration
+	for _, s := range samples {
+		if s.OK {
+			ok++
+			total += s.Latency
+		}
+	}
+	last := samples[len(samples)-1]
+	status.Status = StatusDown
+	if last.OK {
+		status.Status = StatusUp
+	}
+	status.LastCheckedAt = &last.At
+	status.Latency = last.Latency
+	status.Uptime = float64(ok) / float64(len(samples))
+	if ok > 0 {
+		status.AvgLatency = total / time.Duration(ok)
+	}
+	return status
+}
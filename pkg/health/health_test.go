@@ -0,0 +1,102 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// toggle - проверка, результат которой задает тест
+type toggle struct {
+	err error
+}
+
+func (t *toggle) check(name string) Check {
+	return Check{Name: name, Probe: func(context.Context) error { return t.err }}
+}
+
+// failingStore - хранилище истории, недоступное как упавший Redis
+type failingStore struct{}
+
+func (failingStore) Record(context.Context, string, Sample) error { return errors.New("redis is down") }
+
+func (failingStore) Samples(context.Context, string, time.Time) ([]Sample, error) {
+	return nil, errors.New("redis is down")
+}
+
+func newTestMonitor(store Store, checks ...Check) *Monitor {
+	return NewMonitor(checks, store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestMonitorAvailable(t *testing.T) {
+	redis := &toggle{}
+	m := newTestMonitor(NewMemoryStore(), redis.check(DependencyRedis))
+	ctx := context.Background()
+
+	if !m.Available(DependencyRedis) {
+		t.Error("dependency unavailable before first check")
+	}
+
+	redis.err = errors.New("connection refused")
+	m.CheckAll(ctx)
+	if m.Available(DependencyRedis) {
+		t.Error("dependency available after failed check")
+	}
+
+	redis.err = nil
+	m.CheckAll(ctx)
+	if !m.Available(DependencyRedis) {
+		t.Error("dependency unavailable after successful check")
+	}
+
+	if !m.Available(DependencySMTP) {
+		t.Error("dependency without check reported unavailable")
+	}
+}
+
+func TestMonitorStatus(t *testing.T) {
+	sqlite, redis := &toggle{}, &toggle{err: errors.New("connection refused")}
+	m := newTestMonitor(NewMemoryStore(), sqlite.check(DependencySQLite), redis.check(DependencyRedis))
+	ctx := context.Background()
+
+	if got := Overall(m.Status(ctx, time.Now())); got != StatusUnknown {
+		t.Errorf("overall before checks = %s, want %s", got, StatusUnknown)
+	}
+
+	m.CheckAll(ctx)
+	statuses := m.Status(ctx, time.Now())
+	if statuses[0].Status != StatusUp || statuses[1].Status != StatusDown {
+		t.Errorf("statuses = %s, %s; want up, down", statuses[0].Status, statuses[1].Status)
+	}
+	if got := Overall(statuses); got != StatusDegraded {
+		t.Errorf("overall = %s, want %s", got, StatusDegraded)
+	}
+
+	redis.err = nil
+	m.CheckAll(ctx)
+	statuses = m.Status(ctx, time.Now())
+	if statuses[1].Status != StatusUp || statuses[1].Checks != 2 || statuses[1].Uptime != 0.5 {
+		t.Errorf("redis = %s, %d checks, uptime %v; want up, 2 checks, uptime 0.5", statuses[1].Status, statuses[1].Checks, statuses[1].Uptime)
+	}
+}
+
+func TestMonitorStatusWithoutHistory(t *testing.T) {
+	redis := &toggle{err: errors.New("connection refused")}
+	m := newTestMonitor(failingStore{}, redis.check(DependencyRedis))
+	ctx := context.Background()
+
+	m.CheckAll(ctx)
+	statuses := m.Status(ctx, time.Now())
+	if len(statuses) != 1 || statuses[0].Status != StatusDown || statuses[0].Checks != 1 {
+		t.Fatalf("statuses = %+v, want redis down by last check", statuses)
+	}
+
+	redis.err = nil
+	m.CheckAll(ctx)
+	if got := m.Status(ctx, time.Now())[0].Status; got != StatusUp {
+		t.Errorf("status after recovery = %s, want %s", got, StatusUp)
+	}
+}
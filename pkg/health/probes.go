@@ -0,0 +1,80 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Имена проверяемых зависимостей
+const (
+	DependencySQLite   = "sqlite"
+	DependencyRedis    = "redis"
+	DependencyTelegram = "telegram"
+	DependencySMTP     = "smtp"
+)
+
+// SQLiteCheck проверяет, что база отвечает на запрос
+func SQLiteCheck(db *gorm.DB) Check {
+	return Check{Name: DependencySQLite, Probe: func(ctx context.Context) error {
+		return db.WithContext(ctx).Exec("SELECT 1").Error
+	}}
+}
+
+// RedisCheck проверяет Redis командой PING
+func RedisCheck(client *redis.Client) Check {
+	return Check{Name: DependencyRedis, Probe: func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}}
+}
+
+// TelegramCheck проверяет Telegram Bot API методом getMe. Токен читается при каждой проверке.
+func TelegramCheck(botToken func() string) Check {
+	client := &http.Client{}
+	return Check{Name: DependencyTelegram, Probe: func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.telegram.org/bot"+botToken()+"/getMe", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			// Ошибка содержит URL с токеном бота, поэтому не возвращается
+			return fmt.Errorf("telegram request failed")
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("telegram returned status %d", resp.StatusCode)
+		}
+		return nil
+	}}
+}
+
+// SMTPCheck подключается к SMTP-серверу и ждет приветствия 220, не отправляя писем
+func SMTPCheck(addr string) Check {
+	return Check{Name: DependencySMTP, Probe: func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
+		}
+		greeting, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(greeting, "220") {
+			return fmt.Errorf("unexpected SMTP greeting %q", strings.TrimSpace(greeting))
+		}
+		_, _ = conn.Write([]byte("QUIT\r\n"))
+		return nil
+	}}
+}
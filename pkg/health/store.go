@@ -0,0 +1,107 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix - префикс ключей Redis с проверками; на каждую зависимость - отсортированное множество по времени
+const redisKeyPrefix = "rim:health:"
+
+// redisStore хранит проверки в Redis, чтобы статистика переживала перезапуск и была общей для экземпляров
+type redisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore создает Store в Redis
+func NewRedisStore(client *redis.Client) Store {
+	return &redisStore{client: client}
+}
+
+func (s *redisStore) Record(ctx context.Context, name string, sample Sample) error {
+	key := redisKeyPrefix + name
+	at := sample.At.UnixMilli()
+	ok := 0
+	if sample.OK {
+		ok = 1
+	}
+	member := fmt.Sprintf("%d|%d|%d", at, ok, sample.Latency.Microseconds())
+
+	pipe := s.client.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(at), Member: member})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(sample.At.Add(-Window).UnixMilli(), 10))
+	pipe.Expire(ctx, key, Window+time.Hour)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *redisStore) Samples(ctx context.Context, name string, since time.Time) ([]Sample, error) {
+	members, err := s.client.ZRangeByScore(ctx, redisKeyPrefix+name, &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]Sample, 0, len(members))
+	for _, member := range members {
+		parts := strings.Split(member, "|")
+		if len(parts) != 3 {
+			continue
+		}
+		at, err1 := strconv.ParseInt(parts[0], 10, 64)
+		latency, err2 := strconv.ParseInt(parts[2], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		samples = append(samples, Sample{
+			At:      time.UnixMilli(at),
+			OK:      parts[1] == "1",
+			Latency: time.Duration(latency) * time.Microsecond,
+		})
+	}
+	return samples, nil
+}
+
+// memoryStore хранит проверки в памяти процесса; используется, если Redis не настроен
+type memoryStore struct {
+	mu      sync.Mutex
+	samples map[string][]Sample
+}
+
+// NewMemoryStore создает Store в памяти. Статистика теряется при перезапуске.
+func NewMemoryStore() Store {
+	return &memoryStore{samples: make(map[string][]Sample)}
+}
+
+func (s *memoryStore) Record(ctx context.Context, name string, sample Sample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cutoff := sample.At.Add(-Window)
+	kept := s.samples[name][:0]
+	for _, existing := range s.samples[name] {
+		if !existing.At.Before(cutoff) {
+			kept = append(kept, existing)
+		}
+	}
+	s.samples[name] = append(kept, sample)
+	return nil
+}
+
+func (s *memoryStore) Samples(ctx context.Context, name string, since time.Time) ([]Sample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []Sample
+	for _, sample := range s.samples[name] {
+		if !sample.At.Before(since) {
+			result = append(result, sample)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,51 @@
+// Package inflight ограничивает число одновременно выполняемых запросов. В отличие от ratelimit,
+// считаются не запросы в минуту одного клиента, а занятые обработкой запросы всех клиентов.
+package inflight
+
+import "sync"
+
+// Limiter считает выполняемые запросы по группам в памяти процесса.
+// Лимит передается при каждом вызове Acquire, поэтому его можно менять на лету.
+type Limiter struct {
+	mu     sync.Mutex
+	active map[string]int
+}
+
+// New создает Limiter без выполняемых запросов.
+func New() *Limiter {
+	return &Limiter{active: map[string]int{}}
+}
+
+// Acquire занимает место в группе key, если в ней выполняется меньше limit запросов; limit <= 0 снимает
+// ограничение, но запрос все равно учитывается. Занятое место освобождается вызовом Release.
+func (l *Limiter) Acquire(key string, limit int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if limit > 0 && l.active[key] >= limit {
+		return false
+	}
+	l.active[key]++
+	return true
+}
+
+// Release освобождает место, занятое Acquire.
+func (l *Limiter) Release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[key] <= 1 {
+		delete(l.active, key)
+		return
+	}
+	l.active[key]--
+}
+
+// Active возвращает число выполняемых запросов каждой группы; группы без запросов не включаются.
+func (l *Limiter) Active() map[string]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	active := make(map[string]int, len(l.active))
+	for key, n := range l.active {
+		active[key] = n
+	}
+	return active
+}
@@ -0,0 +1,182 @@
+// Package leader выбирает экземпляр сервера, который выполняет задачи по расписанию. Во время выкладки
+// старый и новый процессы работают одновременно, и без выбора рассылки и синхронизации выполнялись бы дважды.
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// campaignInterval - как часто экземпляр без лидерства пытается захватить блокировку.
+// Определяет, сколько задачи простаивают при передаче лидерства новому процессу.
+const campaignInterval = time.Second
+
+// Job - фоновая задача; выполняется, пока не отменен ctx
+type Job func(ctx context.Context)
+
+// Elector владеет блокировкой в Redis (ключ со значением id экземпляра и TTL), пока выполняет задачи.
+// Лидер продлевает ключ каждую треть TTL. При остановке он дожидается завершения задач и удаляет ключ,
+// поэтому следующий экземпляр подхватывает задачи через campaignInterval, а не по истечении TTL.
+// Если лидер завис или потерял Redis, ключ истекает, и лидером становится другой экземпляр.
+type Elector struct {
+	client *redis.Client
+	key    string
+	id     string
+	ttl    time.Duration
+	logger *slog.Logger
+}
+
+// New создает Elector с блокировкой key. client == nil - экземпляр единственный (нет общего Redis),
+// и задачи выполняются без блокировки.
+func New(client *redis.Client, key string, ttl time.Duration, logger *slog.Logger) *Elector {
+	hostname, _ := os.Hostname()
+	return &Elector{
+		client: client,
+		key:    key,
+		id:     fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), uuid.NewString()),
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+// Run выполняет jobs, пока экземпляр - лидер, и возвращается после отмены ctx,
+// когда задачи завершены, а блокировка освобождена.
+func (e *Elector) Run(ctx context.Context, jobs ...Job) {
+	if e.client == nil {
+		runJobs(ctx, jobs)
+		return
+	}
+
+	ticker := time.NewTicker(campaignInterval)
+	defer ticker.Stop()
+	for {
+		acquired, err := e.client.SetNX(ctx, e.key, e.id, e.ttl).Result()
+		if err != nil && ctx.Err() == nil {
+			e.logger.WarnContext(ctx, "Failed to acquire scheduler lock", slog.String("key", e.key), slog.Any("error", err))
+		}
+		if acquired {
+			e.lead(ctx, jobs)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead выполняет задачи, продлевая блокировку, до отмены ctx или потери блокировки
+func (e *Elector) lead(ctx context.Context, jobs []Job) {
+	e.logger.InfoContext(ctx, "Scheduler leadership acquired", slog.String("key", e.key), slog.String("id", e.id))
+
+	jobsCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		runJobs(jobsCtx, jobs)
+		close(done)
+	}()
+
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	renewedAt := time.Now()
+	for held := true; held; {
+		select {
+		case <-ctx.Done():
+			held = false
+		case <-ticker.C:
+			owned, err := e.renew(ctx)
+			switch {
+			case err != nil && ctx.Err() != nil:
+				held = false
+			case err != nil:
+				// Пока ключ не истек, он принадлежит этому экземпляру; после - его может захватить другой
+				e.logger.WarnContext(ctx, "Failed to renew scheduler lock", slog.String("key", e.key), slog.Any("error", err))
+				held = time.Since(renewedAt) < e.ttl
+			case !owned:
+				held = false
+			default:
+				renewedAt = time.Now()
+			}
+			if !held && ctx.Err() == nil {
+				e.logger.WarnContext(ctx, "Scheduler leadership lost, stopping scheduled jobs", slog.String("key", e.key))
+			}
+		}
+	}
+
+	// Блокировка освобождается только после задач, чтобы новый лидер не запустил их параллельно
+	cancel()
+	<-done
+	releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), e.ttl)
+	defer cancelRelease()
+	if err := e.release(releaseCtx); err != nil {
+		e.logger.WarnContext(ctx, "Failed to release scheduler lock, it expires by TTL", slog.String("key", e.key), slog.Any("error", err))
+		return
+	}
+	e.logger.InfoContext(ctx, "Scheduler leadership released", slog.String("key", e.key))
+}
+
+// renew продлевает блокировку, если она все еще принадлежит экземпляру. false - блокировку захватил другой.
+func (e *Elector) renew(ctx context.Context) (bool, error) {
+	owned := false
+	err := e.client.Watch(ctx, func(tx *redis.Tx) error {
+		if ok, err := e.owns(ctx, tx); err != nil || !ok {
+			return err
+		}
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.PExpire(ctx, e.key, e.ttl)
+			return nil
+		})
+		owned = err == nil
+		return err
+	}, e.key)
+	if errors.Is(err, redis.TxFailedErr) {
+		return false, nil
+	}
+	return owned, err
+}
+
+// release удаляет ключ блокировки, если он принадлежит экземпляру
+func (e *Elector) release(ctx context.Context) error {
+	err := e.client.Watch(ctx, func(tx *redis.Tx) error {
+		if ok, err := e.owns(ctx, tx); err != nil || !ok {
+			return err
+		}
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, e.key)
+			return nil
+		})
+		return err
+	}, e.key)
+	if errors.Is(err, redis.TxFailedErr) {
+		return nil
+	}
+	return err
+}
+
+func (e *Elector) owns(ctx context.Context, tx *redis.Tx) (bool, error) {
+	owner, err := tx.Get(ctx, e.key).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	return owner == e.id, err
+}
+
+func runJobs(ctx context.Context, jobs []Job) {
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			job(ctx)
+		}(job)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,54 @@
+// Package listener открывает сокет HTTP-сервера так, чтобы новая версия могла начать принимать соединения
+// до остановки старой: сокет передает systemd (активация сокетом) или оба процесса слушают порт с SO_REUSEPORT.
+package listener
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/valyala/fasthttp/reuseport"
+)
+
+// listenFDsStart - первый дескриптор, переданный systemd (sd_listen_fds)
+const listenFDsStart = 3
+
+// Listen возвращает сокет, унаследованный от systemd, если он передан процессу, иначе открывает addr.
+// reusePort - открыть сокет с SO_REUSEPORT, чтобы на том же порту мог запуститься следующий процесс;
+// network - "tcp4" или "tcp6", как в fiber.Config.Network. inherited сообщает, что сокет унаследован.
+func Listen(network, addr string, reusePort bool) (ln net.Listener, inherited bool, err error) {
+	if ln, err := inheritedListener(); ln != nil || err != nil {
+		return ln, true, err
+	}
+	if reusePort {
+		ln, err = reuseport.Listen(network, addr)
+		return ln, false, err
+	}
+	ln, err = net.Listen(network, addr)
+	return ln, false, err
+}
+
+// inheritedListener возвращает первый сокет из LISTEN_FDS, если переменные адресованы этому процессу
+func inheritedListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return nil, nil
+	}
+	// Дочерние процессы не должны считать сокет своим
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	file := os.NewFile(listenFDsStart, "listen_fd_3")
+	defer file.Close()
+	ln, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("inherited socket: %w", err)
+	}
+	return ln, nil
+}
@@ -0,0 +1,86 @@
+package mailer
+
+import (
+	"context"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"regexp"
+	"strings"
+)
+
+// Sender отправляет письма на один адрес
+type Sender interface {
+	Send(ctx context.Context, to, subject, text string) error
+}
+
+// logSender пишет письма в лог вместо отправки. Используется, если SMTP-сервер не настроен.
+// Значения параметров token в ссылках маскируются: по ссылке из письма подтверждается email, а лог читают не только получатели.
+type logSender struct {
+	logger *slog.Logger
+}
+
+// NewLogSender создает Sender, который только логирует письма (для разработки)
+func NewLogSender(logger *slog.Logger) Sender {
+	return &logSender{logger: logger}
+}
+
+// tokenParam находит значения параметров token в ссылках, которые маскируются в логе
+var tokenParam = regexp.MustCompile(`([?&]token=)[^&\s]+`)
+
+func (s *logSender) Send(ctx context.Context, to, subject, text string) error {
+	s.logger.InfoContext(ctx, "SMTP server is not configured, email logged instead of sending", slog.String("to", to), slog.String("subject", subject), slog.String("text", redact(text)))
+	return nil
+}
+
+// redact заменяет значения параметров token в тексте на *
+func redact(text string) string {
+	return tokenParam.ReplaceAllString(text, "${1}*")
+}
+
+// smtpSender отправляет письма в виде text/plain в UTF-8 через SMTP-сервер
+type smtpSender struct {
+	addr     string
+	username string
+	password func() string // Читается при каждой отправке, чтобы учитывать ротацию пароля
+	from     string
+	logger   *slog.Logger
+}
+
+// NewSMTPSender создает Sender для SMTP-сервера. addr - "host:port";
+// если username не пустой, используется PLAIN-аутентификация.
+func NewSMTPSender(addr, username string, password func() string, from string, logger *slog.Logger) Sender {
+	return &smtpSender{
+		addr:     addr,
+		username: username,
+		password: password,
+		from:     from,
+		logger:   logger,
+	}
+}
+
+func (s *smtpSender) Send(ctx context.Context, to, subject, text string) error {
+	var auth smtp.Auth
+	if s.username != "" {
+		host, _, err := net.SplitHostPort(s.addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", s.username, s.password(), host)
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + s.from + "\r\n")
+	msg.WriteString("To: " + to + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+
+	if err := smtp.SendMail(s.addr, auth, s.from, []string{to}, []byte(msg.String())); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to send email", slog.String("to", to), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
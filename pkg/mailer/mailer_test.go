@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogSenderRedactsToken(t *testing.T) {
+	var buf bytes.Buffer
+	sender := NewLogSender(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	text := "Перейдите по ссылке:\nhttps://rim.example/api/v1/auth/email/verify?token=c2VjcmV0LXRva2Vu\n\nСсылка действует 48 часа."
+	if err := sender.Send(context.Background(), "anna@example.com", "Подтверждение email в RIM", text); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "c2VjcmV0LXRva2Vu") {
+		t.Fatalf("log contains verification token: %s", out)
+	}
+	if !strings.Contains(out, "verify?token=*") || !strings.Contains(out, "48 часа") {
+		t.Errorf("log does not contain masked text: %s", out)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "https://a.example/x?token=abc", want: "https://a.example/x?token=*"},
+		{text: "https://a.example/x?id=1&token=abc&lang=ru", want: "https://a.example/x?id=1&token=*&lang=ru"},
+		{text: "две ссылки: /a?token=abc\n/b?token=def", want: "две ссылки: /a?token=*\n/b?token=*"},
+	}
+	for _, tt := range tests {
+		if got := redact(tt.text); got != tt.want {
+			t.Errorf("redact(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AlertThresholds - пороги SLO, проверяемые для каждого маршрута за интервал оценки
+type AlertThresholds struct {
+	P95       time.Duration // Порог p95 длительности, 0 - не проверяется
+	ErrorRate float64       // Порог доли ответов 5xx (0..1), 0 - не проверяется
+	// MinRequests - минимальное число запросов маршрута за интервал, при котором он оценивается.
+	// Защищает от тревог по единичным медленным запросам.
+	MinRequests uint64
+}
+
+// Alerter периодически сравнивает метрики маршрутов с порогами и отправляет сообщение,
+// когда маршрут начинает или перестает нарушать SLO. Повторные сообщения о том же нарушении не отправляются.
+type Alerter struct {
+	registry   *Registry
+	thresholds AlertThresholds
+	send       func(ctx context.Context, text string) error
+	logger     *slog.Logger
+
+	previous map[RouteKey]RouteStats
+	firing   map[RouteKey]bool
+}
+
+// NewAlerter создает Alerter. send доставляет текст тревоги, например в чат администраторов Telegram.
+func NewAlerter(registry *Registry, thresholds AlertThresholds, send func(ctx context.Context, text string) error, logger *slog.Logger) *Alerter {
+	return &Alerter{
+		registry:   registry,
+		thresholds: thresholds,
+		send:       send,
+		logger:     logger,
+		previous:   registry.Snapshot(),
+		firing:     map[RouteKey]bool{},
+	}
+}
+
+// Run оценивает метрики каждые interval до отмены ctx.
+func (a *Alerter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.Evaluate(ctx)
+		}
+	}
+}
+
+// Evaluate оценивает запросы, пришедшие с предыдущего вызова, и отправляет изменения состояния тревог.
+func (a *Alerter) Evaluate(ctx context.Context) {
+	current := a.registry.Snapshot()
+	var fired, resolved []string
+
+	for key, stats := range current {
+		window := diffStats(stats, a.previous[key])
+		if window.Count < a.thresholds.MinRequests || window.Count == 0 {
+			// Маршрут без нагрузки не считается ни нарушающим, ни восстановившимся
+			continue
+		}
+
+		var problems []string
+		p95 := a.registry.Quantile(window.Buckets, 0.95)
+		if a.thresholds.P95 > 0 && p95 > a.thresholds.P95 {
+			problems = append(problems, fmt.Sprintf("p95 %s > %s", p95, a.thresholds.P95))
+		}
+		errorRate := float64(window.Errors) / float64(window.Count)
+		if a.thresholds.ErrorRate > 0 && errorRate > a.thresholds.ErrorRate {
+			problems = append(problems, fmt.Sprintf("5xx %.1f%% > %.1f%%", errorRate*100, a.thresholds.ErrorRate*100))
+		}
+
+		route := key.Method + " " + key.Route
+		switch {
+		case len(problems) > 0 && !a.firing[key]:
+			a.firing[key] = true
+			fired = append(fired, fmt.Sprintf("%s: %s (%d запросов)", route, strings.Join(problems, ", "), window.Count))
+		case len(problems) == 0 && a.firing[key]:
+			delete(a.firing, key)
+			resolved = append(resolved, fmt.Sprintf("%s: p95 %s, 5xx %.1f%%", route, p95, errorRate*100))
+		}
+	}
+	a.previous = current
+
+	if len(fired) == 0 && len(resolved) == 0 {
+		return
+	}
+	sort.Strings(fired)
+	sort.Strings(resolved)
+
+	var text strings.Builder
+	if len(fired) > 0 {
+		text.WriteString("Нарушение SLO:\n" + strings.Join(fired, "\n"))
+	}
+	if len(resolved) > 0 {
+		if text.Len() > 0 {
+			text.WriteString("\n\n")
+		}
+		text.WriteString("Восстановлено:\n" + strings.Join(resolved, "\n"))
+	}
+
+	a.logger.WarnContext(ctx, "SLO alert state changed", slog.Int("fired", len(fired)), slog.Int("resolved", len(resolved)))
+	if err := a.send(ctx, text.String()); err != nil {
+		a.logger.ErrorContext(ctx, "Failed to send SLO alert", slog.Any("error", err))
+	}
+}
+
+// diffStats возвращает статистику запросов между двумя снимками.
+func diffStats(current, previous RouteStats) RouteStats {
+	window := RouteStats{
+		Count:   current.Count - previous.Count,
+		Errors:  current.Errors - previous.Errors,
+		Buckets: make([]uint64, len(current.Buckets)),
+	}
+	for i := range current.Buckets {
+		window.Buckets[i] = current.Buckets[i]
+		if i < len(previous.Buckets) {
+			window.Buckets[i] -= previous.Buckets[i]
+		}
+	}
+	return window
+}
@@ -0,0 +1,164 @@
+// Package metrics собирает метрики HTTP-запросов по шаблонам маршрутов
+// и отдает их в текстовом формате Prometheus.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets - верхние границы корзин гистограммы длительности запросов в секундах
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// RouteKey идентифицирует маршрут: метод и шаблон пути, например GET /api/v1/contacts/:id
+type RouteKey struct {
+	Method string
+	Route  string
+}
+
+// RouteStats - накопленная статистика маршрута с момента запуска
+type RouteStats struct {
+	Count    uint64
+	Errors   uint64   // Ответы 5xx
+	Sum      float64  // Суммарная длительность в секундах
+	Buckets  []uint64 // Число запросов в каждой корзине DefaultBuckets (не накопительно); последняя - +Inf
+	Statuses map[int]uint64
+}
+
+// Registry хранит метрики в памяти процесса
+type Registry struct {
+	mu      sync.Mutex
+	buckets []float64
+	routes  map[RouteKey]*RouteStats
+}
+
+// NewRegistry создает пустой Registry с корзинами DefaultBuckets.
+func NewRegistry() *Registry {
+	return &Registry{buckets: DefaultBuckets, routes: map[RouteKey]*RouteStats{}}
+}
+
+// Observe учитывает завершенный запрос.
+func (r *Registry) Observe(method, route string, status int, duration time.Duration) {
+	seconds := duration.Seconds()
+	key := RouteKey{Method: method, Route: route}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats, ok := r.routes[key]
+	if !ok {
+		stats = &RouteStats{Buckets: make([]uint64, len(r.buckets)+1), Statuses: map[int]uint64{}}
+		r.routes[key] = stats
+	}
+	stats.Count++
+	stats.Sum += seconds
+	stats.Statuses[status]++
+	if status >= 500 {
+		stats.Errors++
+	}
+	i := sort.SearchFloat64s(r.buckets, seconds)
+	stats.Buckets[i]++
+}
+
+// Snapshot возвращает копию статистики всех маршрутов.
+func (r *Registry) Snapshot() map[RouteKey]RouteStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	snapshot := make(map[RouteKey]RouteStats, len(r.routes))
+	for key, stats := range r.routes {
+		copied := *stats
+		copied.Buckets = append([]uint64(nil), stats.Buckets...)
+		copied.Statuses = make(map[int]uint64, len(stats.Statuses))
+		for status, count := range stats.Statuses {
+			copied.Statuses[status] = count
+		}
+		snapshot[key] = copied
+	}
+	return snapshot
+}
+
+// Quantile оценивает квантиль q длительности по корзинам: возвращает верхнюю границу корзины,
+// в которую попадает q-я доля запросов. Для корзины +Inf возвращается последняя конечная граница.
+func (r *Registry) Quantile(buckets []uint64, q float64) time.Duration {
+	var total uint64
+	for _, count := range buckets {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+	target := q * float64(total)
+	var cumulative uint64
+	for i, count := range buckets {
+		cumulative += count
+		if float64(cumulative) >= target {
+			if i >= len(r.buckets) {
+				i = len(r.buckets) - 1
+			}
+			return time.Duration(r.buckets[i] * float64(time.Second))
+		}
+	}
+	return time.Duration(r.buckets[len(r.buckets)-1] * float64(time.Second))
+}
+
+// WritePrometheus выводит метрики в текстовом формате Prometheus:
+// rim_http_requests_total, rim_http_request_errors_total и гистограмму rim_http_request_duration_seconds.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	snapshot := r.Snapshot()
+	keys := make([]RouteKey, 0, len(snapshot))
+	for key := range snapshot {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Route != keys[j].Route {
+			return keys[i].Route < keys[j].Route
+		}
+		return keys[i].Method < keys[j].Method
+	})
+
+	var b strings.Builder
+	b.WriteString("# HELP rim_http_requests_total Number of HTTP requests by route template and status.\n")
+	b.WriteString("# TYPE rim_http_requests_total counter\n")
+	for _, key := range keys {
+		statuses := make([]int, 0, len(snapshot[key].Statuses))
+		for status := range snapshot[key].Statuses {
+			statuses = append(statuses, status)
+		}
+		sort.Ints(statuses)
+		for _, status := range statuses {
+			fmt.Fprintf(&b, "rim_http_requests_total{%s,status=\"%d\"} %d\n", labels(key), status, snapshot[key].Statuses[status])
+		}
+	}
+
+	b.WriteString("# HELP rim_http_request_errors_total Number of HTTP requests that ended with a 5xx status.\n")
+	b.WriteString("# TYPE rim_http_request_errors_total counter\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "rim_http_request_errors_total{%s} %d\n", labels(key), snapshot[key].Errors)
+	}
+
+	b.WriteString("# HELP rim_http_request_duration_seconds HTTP request latency by route template.\n")
+	b.WriteString("# TYPE rim_http_request_duration_seconds histogram\n")
+	for _, key := range keys {
+		stats := snapshot[key]
+		var cumulative uint64
+		for i, bound := range r.buckets {
+			cumulative += stats.Buckets[i]
+			fmt.Fprintf(&b, "rim_http_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels(key), strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&b, "rim_http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels(key), stats.Count)
+		fmt.Fprintf(&b, "rim_http_request_duration_seconds_sum{%s} %s\n", labels(key), strconv.FormatFloat(stats.Sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "rim_http_request_duration_seconds_count{%s} %d\n", labels(key), stats.Count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// labels форматирует метки маршрута, экранируя значения по правилам формата Prometheus.
+func labels(key RouteKey) string {
+	return fmt.Sprintf("method=%q,route=%q", key.Method, key.Route)
+}
@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"rim/pkg/mailer"
+)
+
+// Recipient - адресат уведомления. Канал пропускает адресата без нужного ему поля.
+type Recipient struct {
+	TelegramID int64
+	Email      string
+	UserID     uint // Пользователь контакта, 0 - контакт не входил в систему (для Web Push)
+}
+
+// Channel доставляет уведомления одним способом (Telegram, email, Web Push, лог)
+type Channel interface {
+	// Name возвращает название канала, по которому пользователь выбирает каналы уведомлений
+	Name() string
+	Send(ctx context.Context, to Recipient, subject, text string) error
+}
+
+// logChannel пишет уведомления в лог. Используется, если ни один канал не настроен.
+type logChannel struct {
+	logger *slog.Logger
+}
+
+// NewLogChannel создает Channel, который только логирует уведомления (для разработки)
+func NewLogChannel(logger *slog.Logger) Channel {
+	return &logChannel{logger: logger}
+}
+
+func (c *logChannel) Name() string {
+	return "log"
+}
+
+func (c *logChannel) Send(ctx context.Context, to Recipient, subject, text string) error {
+	c.logger.InfoContext(ctx, "Notification channels are not configured, notification logged instead of sending",
+		slog.Int64("telegram_id", to.TelegramID), slog.String("email", to.Email), slog.String("subject", subject), slog.String("text", text))
+	return nil
+}
+
+// telegramChannel отправляет сообщения от имени бота через Telegram Bot API
+type telegramChannel struct {
+	token  func() string // Читается при каждой отправке, чтобы учитывать ротацию токена
+	client *http.Client
+	logger *slog.Logger
+}
+
+// NewTelegramChannel создает Channel для Telegram. Бот может писать только пользователям, которые его запустили.
+func NewTelegramChannel(botToken func() string, logger *slog.Logger) Channel {
+	return &telegramChannel{
+		token:  botToken,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: logger,
+	}
+}
+
+func (c *telegramChannel) Name() string {
+	return "telegram"
+}
+
+func (c *telegramChannel) Send(ctx context.Context, to Recipient, subject, text string) error {
+	if to.TelegramID == 0 {
+		return nil
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id": to.TelegramID,
+		"text":    subject + "\n\n" + text,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.telegram.org/bot"+c.token()+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// Не логируем err целиком: URL запроса содержит токен бота
+		c.logger.ErrorContext(ctx, "Failed to send Telegram notification", slog.Int64("telegram_id", to.TelegramID))
+		return fmt.Errorf("telegram request failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		c.logger.ErrorContext(ctx, "Telegram returned error status", slog.Int64("telegram_id", to.TelegramID), slog.Int("status", resp.StatusCode))
+		return fmt.Errorf("telegram returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// emailChannel отправляет уведомления письмами (pkg/mailer)
+type emailChannel struct {
+	sender mailer.Sender
+}
+
+// NewEmailChannel создает Channel для email. addr - "host:port" SMTP-сервера;
+// если username не пустой, используется PLAIN-аутентификация.
+func NewEmailChannel(addr, username string, password func() string, from string, logger *slog.Logger) Channel {
+	return &emailChannel{sender: mailer.NewSMTPSender(addr, username, password, from, logger)}
+}
+
+func (c *emailChannel) Name() string {
+	return "email"
+}
+
+func (c *emailChannel) Send(ctx context.Context, to Recipient, subject, text string) error {
+	if to.Email == "" {
+		return nil
+	}
+	return c.sender.Send(ctx, to.Email, subject, text)
+}
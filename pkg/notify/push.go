@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"rim/pkg/webpush"
+)
+
+// pushTTL - сколько сервис доставки хранит уведомление для недоступного браузера
+const pushTTL = 24 * time.Hour
+
+// maxPushTextLength - длина текста в уведомлении; полный текст дайджеста не помещается в Web Push
+const maxPushTextLength = 1000
+
+// PushSubscriptions - хранилище подписок Web Push пользователей
+type PushSubscriptions interface {
+	PushSubscriptions(ctx context.Context, userID uint) ([]webpush.Subscription, error)
+	// RemovePushSubscription удаляет подписку, которую сервис доставки больше не принимает
+	RemovePushSubscription(ctx context.Context, endpoint string) error
+}
+
+// pushChannel отправляет уведомления во все браузеры, подписанные пользователем
+type pushChannel struct {
+	sender *webpush.Sender
+	store  PushSubscriptions
+	logger *slog.Logger
+}
+
+// NewPushChannel создает Channel для Web Push. Контакты без пользователя пропускаются.
+func NewPushChannel(sender *webpush.Sender, store PushSubscriptions, logger *slog.Logger) Channel {
+	return &pushChannel{
+		sender: sender,
+		store:  store,
+		logger: logger,
+	}
+}
+
+// pushPayload - содержимое уведомления; service worker PWA показывает его через showNotification
+type pushPayload struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+func (c *pushChannel) Name() string {
+	return "push"
+}
+
+func (c *pushChannel) Send(ctx context.Context, to Recipient, subject, text string) error {
+	if to.UserID == 0 {
+		return nil
+	}
+	subs, err := c.store.PushSubscriptions(ctx, to.UserID)
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+	if runes := []rune(text); len(runes) > maxPushTextLength {
+		text = string(runes[:maxPushTextLength]) + "…"
+	}
+	payload, err := json.Marshal(pushPayload{Title: subject, Body: text})
+	if err != nil {
+		return err
+	}
+
+	var sendErr error
+	for _, sub := range subs {
+		err := c.sender.Send(ctx, sub, payload, pushTTL)
+		switch {
+		case err == nil:
+		case errors.Is(err, webpush.ErrSubscriptionGone):
+			c.logger.InfoContext(ctx, "Push subscription expired, removing", slog.Uint64("user_id", uint64(to.UserID)))
+			_ = c.store.RemovePushSubscription(ctx, sub.Endpoint)
+		default:
+			c.logger.ErrorContext(ctx, "Failed to send push notification", slog.Uint64("user_id", uint64(to.UserID)), slog.Any("error", err))
+			sendErr = err
+		}
+	}
+	return sendErr
+}
@@ -0,0 +1,385 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidFont = errors.New("invalid truetype font")
+
+// Font - шрифт TrueType для встраивания в документ. В файл попадают только использованные глифы.
+type Font struct {
+	name       string
+	tables     map[string][]byte
+	unitsPerEm int
+	ascent     int
+	descent    int
+	bbox       [4]int
+	advances   []uint16 // Ширины глифов в единицах шрифта
+	cmap       map[rune]uint16
+	locaLong   bool
+	numGlyphs  int
+}
+
+// LoadFont читает шрифт TrueType (.ttf) из файла.
+func LoadFont(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return ParseFont(name, data)
+}
+
+// ParseFont разбирает шрифт TrueType; name используется как имя шрифта в документе.
+func ParseFont(name string, data []byte) (*Font, error) {
+	if len(data) < 12 {
+		return nil, ErrInvalidFont
+	}
+	f := &Font{name: sanitizeName(name), tables: make(map[string][]byte)}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		rec := 12 + 16*i
+		if rec+16 > len(data) {
+			return nil, ErrInvalidFont
+		}
+		tag := string(data[rec : rec+4])
+		offset := int(binary.BigEndian.Uint32(data[rec+8:]))
+		length := int(binary.BigEndian.Uint32(data[rec+12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, ErrInvalidFont
+		}
+		f.tables[tag] = data[offset : offset+length]
+	}
+
+	head, hhea, maxp, hmtx := f.tables["head"], f.tables["hhea"], f.tables["maxp"], f.tables["hmtx"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 || f.tables["glyf"] == nil || f.tables["loca"] == nil {
+		return nil, ErrInvalidFont // Шрифты CFF (.otf) не поддерживаются
+	}
+	f.unitsPerEm = int(binary.BigEndian.Uint16(head[18:]))
+	if f.unitsPerEm == 0 {
+		return nil, ErrInvalidFont
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+2*i:])))
+	}
+	f.locaLong = binary.BigEndian.Uint16(head[50:]) == 1
+	f.ascent = int(int16(binary.BigEndian.Uint16(hhea[4:])))
+	f.descent = int(int16(binary.BigEndian.Uint16(hhea[6:])))
+	f.numGlyphs = int(binary.BigEndian.Uint16(maxp[4:]))
+
+	numMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	if numMetrics == 0 || len(hmtx) < 4*numMetrics {
+		return nil, ErrInvalidFont
+	}
+	f.advances = make([]uint16, f.numGlyphs)
+	for g := range f.advances {
+		// Глифы после numberOfHMetrics имеют ширину последней записи
+		f.advances[g] = binary.BigEndian.Uint16(hmtx[4*min(g, numMetrics-1):])
+	}
+
+	cmap, err := parseCmap(f.tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	f.cmap = cmap
+	return f, nil
+}
+
+// Width возвращает ширину строки s в пунктах при размере шрифта size.
+func (f *Font) Width(s string, size float64) float64 {
+	total := 0
+	for _, r := range s {
+		total += int(f.advances[f.glyph(r)])
+	}
+	return float64(total) * size / float64(f.unitsPerEm)
+}
+
+// glyph возвращает глиф символа; отсутствующие символы выводятся глифом 0 (.notdef)
+func (f *Font) glyph(r rune) uint16 {
+	g := f.cmap[r]
+	if int(g) >= f.numGlyphs {
+		return 0
+	}
+	return g
+}
+
+// scale переводит единицы шрифта в тысячные доли кегля, принятые в PDF
+func (f *Font) scale(v int) int {
+	return v * 1000 / f.unitsPerEm
+}
+
+// parseCmap читает таблицу символов Unicode (формат 12 или 4)
+func parseCmap(table []byte) (map[rune]uint16, error) {
+	if len(table) < 4 {
+		return nil, ErrInvalidFont
+	}
+	var format4, format12 []byte
+	numTables := int(binary.BigEndian.Uint16(table[2:]))
+	for i := 0; i < numTables; i++ {
+		rec := 4 + 8*i
+		if rec+8 > len(table) {
+			return nil, ErrInvalidFont
+		}
+		platform := binary.BigEndian.Uint16(table[rec:])
+		encoding := binary.BigEndian.Uint16(table[rec+2:])
+		offset := int(binary.BigEndian.Uint32(table[rec+4:]))
+		if offset+4 > len(table) {
+			continue
+		}
+		sub := table[offset:]
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode {
+			continue
+		}
+		switch binary.BigEndian.Uint16(sub) {
+		case 4:
+			format4 = sub
+		case 12:
+			format12 = sub
+		}
+	}
+
+	result := make(map[rune]uint16)
+	switch {
+	case format12 != nil && len(format12) >= 16:
+		numGroups := int(binary.BigEndian.Uint32(format12[12:]))
+		if 16+12*numGroups > len(format12) {
+			return nil, ErrInvalidFont
+		}
+		for i := 0; i < numGroups; i++ {
+			group := format12[16+12*i:]
+			start := binary.BigEndian.Uint32(group)
+			end := binary.BigEndian.Uint32(group[4:])
+			glyph := binary.BigEndian.Uint32(group[8:])
+			for c := start; c <= end && c <= 0x10FFFF; c++ {
+				result[rune(c)] = uint16(glyph + c - start)
+			}
+		}
+	case format4 != nil && len(format4) >= 14:
+		segCount := int(binary.BigEndian.Uint16(format4[6:])) / 2
+		endCodes := 14
+		startCodes := endCodes + 2*segCount + 2
+		idDeltas := startCodes + 2*segCount
+		idRangeOffsets := idDeltas + 2*segCount
+		if idRangeOffsets+2*segCount > len(format4) {
+			return nil, ErrInvalidFont
+		}
+		for i := 0; i < segCount; i++ {
+			end := int(binary.BigEndian.Uint16(format4[endCodes+2*i:]))
+			start := int(binary.BigEndian.Uint16(format4[startCodes+2*i:]))
+			delta := int(binary.BigEndian.Uint16(format4[idDeltas+2*i:]))
+			rangeOffset := int(binary.BigEndian.Uint16(format4[idRangeOffsets+2*i:]))
+			for c := start; c <= end && c != 0xFFFF; c++ {
+				glyph := 0
+				if rangeOffset == 0 {
+					glyph = (c + delta) & 0xFFFF
+				} else {
+					addr := idRangeOffsets + 2*i + rangeOffset + 2*(c-start)
+					if addr+2 > len(format4) {
+						continue
+					}
+					if glyph = int(binary.BigEndian.Uint16(format4[addr:])); glyph != 0 {
+						glyph = (glyph + delta) & 0xFFFF
+					}
+				}
+				if glyph != 0 {
+					result[rune(c)] = uint16(glyph)
+				}
+			}
+		}
+	default:
+		return nil, ErrInvalidFont
+	}
+	return result, nil
+}
+
+// glyphData возвращает описание глифа из таблицы glyf
+func (f *Font) glyphData(g uint16) []byte {
+	loca, glyf := f.tables["loca"], f.tables["glyf"]
+	var start, end int
+	if f.locaLong {
+		if 4*int(g)+8 > len(loca) {
+			return nil
+		}
+		start = int(binary.BigEndian.Uint32(loca[4*int(g):]))
+		end = int(binary.BigEndian.Uint32(loca[4*int(g)+4:]))
+	} else {
+		if 2*int(g)+4 > len(loca) {
+			return nil
+		}
+		start = 2 * int(binary.BigEndian.Uint16(loca[2*int(g):]))
+		end = 2 * int(binary.BigEndian.Uint16(loca[2*int(g)+2:]))
+	}
+	if start > end || end > len(glyf) {
+		return nil
+	}
+	return glyf[start:end]
+}
+
+// Флаги компонентов составного глифа и размер заголовка глифа
+const (
+	argsAreWords    = 0x0001
+	haveScale       = 0x0008
+	moreComponents  = 0x0020
+	haveXYScale     = 0x0040
+	haveTwoByTwo    = 0x0080
+	compositeHeader = 10
+)
+
+// components возвращает глифы, из которых собран составной глиф
+func components(data []byte) []uint16 {
+	if len(data) < compositeHeader || int16(binary.BigEndian.Uint16(data)) >= 0 {
+		return nil
+	}
+	var result []uint16
+	for pos := compositeHeader; pos+4 <= len(data); {
+		flags := binary.BigEndian.Uint16(data[pos:])
+		result = append(result, binary.BigEndian.Uint16(data[pos+2:]))
+		pos += 4
+		if flags&argsAreWords != 0 {
+			pos += 4
+		} else {
+			pos += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			pos += 2
+		case flags&haveXYScale != 0:
+			pos += 4
+		case flags&haveTwoByTwo != 0:
+			pos += 8
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+	return result
+}
+
+// subset собирает файл шрифта, в котором описаны только глифы used (и их компоненты);
+// остальные глифы пустые, поэтому номера глифов не меняются
+func (f *Font) subset(used map[uint16]rune) []byte {
+	keep := map[uint16]bool{0: true}
+	queue := make([]uint16, 0, len(used))
+	for g := range used {
+		queue = append(queue, g)
+	}
+	for len(queue) > 0 {
+		g := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if keep[g] && g != 0 {
+			continue
+		}
+		keep[g] = true
+		for _, c := range components(f.glyphData(g)) {
+			if !keep[c] {
+				queue = append(queue, c)
+			}
+		}
+	}
+
+	var glyf bytes.Buffer
+	loca := make([]byte, 4*(f.numGlyphs+1))
+	for g := 0; g < f.numGlyphs; g++ {
+		binary.BigEndian.PutUint32(loca[4*g:], uint32(glyf.Len()))
+		if keep[uint16(g)] {
+			glyf.Write(f.glyphData(uint16(g)))
+			for glyf.Len()%4 != 0 {
+				glyf.WriteByte(0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(loca[4*f.numGlyphs:], uint32(glyf.Len()))
+
+	head := append([]byte(nil), f.tables["head"]...)
+	binary.BigEndian.PutUint32(head[8:], 0)  // checkSumAdjustment
+	binary.BigEndian.PutUint16(head[50:], 1) // loca в длинном формате
+
+	tables := map[string][]byte{
+		"head": head,
+		"hhea": f.tables["hhea"],
+		"maxp": f.tables["maxp"],
+		"hmtx": f.tables["hmtx"],
+		"loca": loca,
+		"glyf": glyf.Bytes(),
+	}
+	// Таблицы хинтинга нужны программам глифов
+	for _, tag := range []string{"cvt ", "fpgm", "prep"} {
+		if t, ok := f.tables[tag]; ok {
+			tables[tag] = t
+		}
+	}
+	return buildFont(tables)
+}
+
+// buildFont собирает файл TrueType из таблиц
+func buildFont(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	numTables := len(tags)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= numTables {
+		entrySelector++
+	}
+	searchRange := 16 << entrySelector
+
+	var out bytes.Buffer
+	header := make([]byte, 12+16*numTables)
+	binary.BigEndian.PutUint32(header, 0x00010000)
+	binary.BigEndian.PutUint16(header[4:], uint16(numTables))
+	binary.BigEndian.PutUint16(header[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(header[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(header[10:], uint16(numTables*16-searchRange))
+
+	offset := len(header)
+	var body bytes.Buffer
+	for i, tag := range tags {
+		data := tables[tag]
+		rec := header[12+16*i:]
+		copy(rec, tag)
+		binary.BigEndian.PutUint32(rec[4:], tableChecksum(data))
+		binary.BigEndian.PutUint32(rec[8:], uint32(offset+body.Len()))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(data)))
+		body.Write(data)
+		for body.Len()%4 != 0 {
+			body.WriteByte(0)
+		}
+	}
+	out.Write(header)
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+func tableChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+// sanitizeName оставляет в имени шрифта только допустимые в PDF символы
+func sanitizeName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r < 128 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "Font"
+	}
+	return b.String()
+}
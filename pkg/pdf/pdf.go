@@ -0,0 +1,336 @@
+// Package pdf - минимальный генератор PDF для печатных форм: текст шрифтом TrueType,
+// прямоугольники и изображения JPEG. Координаты задаются в пунктах от левого верхнего угла страницы.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // Форматы фото, которые перекодируются в JPEG
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Размеры страниц в пунктах
+const (
+	MM       = 72 / 25.4
+	A4Width  = 210 * MM
+	A4Height = 297 * MM
+	A6Width  = 105 * MM
+	A6Height = 148 * MM
+)
+
+// jpegQuality - качество перекодирования фото в JPEG
+const jpegQuality = 85
+
+var ErrUnsupportedImage = errors.New("unsupported image format")
+
+// Document собирает страницы и пишет их в формате PDF 1.4
+type Document struct {
+	font   *Font
+	pages  []*Page
+	images []*Image
+	used   map[uint16]rune // Глифы шрифта, встретившиеся в тексте, и их символы для поиска и копирования
+}
+
+// Page - страница документа; команды рисования копятся в потоке содержимого
+type Page struct {
+	doc     *Document
+	width   float64
+	height  float64
+	content bytes.Buffer
+}
+
+// Image - изображение, добавленное в документ; может выводиться на нескольких страницах
+type Image struct {
+	index  int
+	data   []byte
+	width  int
+	height int
+	gray   bool
+}
+
+// New создает документ, текст которого выводится шрифтом font (обязателен).
+func New(font *Font) *Document {
+	return &Document{font: font, used: make(map[uint16]rune)}
+}
+
+// Font возвращает шрифт документа, например для расчета ширины текста.
+func (d *Document) Font() *Font {
+	return d.font
+}
+
+// AddPage добавляет страницу размером width x height пунктов.
+func (d *Document) AddPage(width, height float64) *Page {
+	p := &Page{doc: d, width: width, height: height}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// AddImage добавляет изображение. JPEG встраивается как есть, PNG и GIF перекодируются в JPEG.
+func (d *Document) AddImage(data []byte) (*Image, error) {
+	img := &Image{index: len(d.images) + 1}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err == nil && (cfg.ColorModel == color.YCbCrModel || cfg.ColorModel == color.GrayModel) {
+		img.data, img.width, img.height = data, cfg.Width, cfg.Height
+		img.gray = cfg.ColorModel == color.GrayModel
+	} else {
+		// CMYK JPEG и другие форматы приводятся к RGB JPEG
+		decoded, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrUnsupportedImage
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, decoded, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		bounds := decoded.Bounds()
+		img.data, img.width, img.height = buf.Bytes(), bounds.Dx(), bounds.Dy()
+	}
+	d.images = append(d.images, img)
+	return img, nil
+}
+
+// Size возвращает размер изображения в пикселях.
+func (img *Image) Size() (int, int) {
+	return img.width, img.height
+}
+
+// Text выводит строку s черным цветом кеглем size; y - базовая линия текста.
+func (p *Page) Text(x, y, size float64, s string) {
+	font := p.doc.font
+	var hex strings.Builder
+	for _, r := range s {
+		g := font.glyph(r)
+		if _, ok := p.doc.used[g]; !ok {
+			p.doc.used[g] = r
+		}
+		fmt.Fprintf(&hex, "%04X", g)
+	}
+	fmt.Fprintf(&p.content, "BT 0 g /F1 %s Tf %s %s Td <%s> Tj ET\n", num(size), num(x), num(p.height-y), hex.String())
+}
+
+// FillRect закрашивает прямоугольник оттенком серого gray (0 - черный, 1 - белый).
+func (p *Page) FillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(&p.content, "%s g %s %s %s %s re f\n", num(gray), num(x), num(p.height-y-h), num(w), num(h))
+}
+
+// FillRects закрашивает черным набор прямоугольников одной командой; для QR-кодов и таблиц.
+func (p *Page) FillRects(rects [][4]float64) {
+	if len(rects) == 0 {
+		return
+	}
+	p.content.WriteString("0 g\n")
+	for _, r := range rects {
+		fmt.Fprintf(&p.content, "%s %s %s %s re\n", num(r[0]), num(p.height-r[1]-r[3]), num(r[2]), num(r[3]))
+	}
+	p.content.WriteString("f\n")
+}
+
+// StrokeRect обводит прямоугольник линией толщины width оттенка серого gray.
+func (p *Page) StrokeRect(x, y, w, h, width, gray float64) {
+	fmt.Fprintf(&p.content, "%s G %s w %s %s %s %s re S\n", num(gray), num(width), num(x), num(p.height-y-h), num(w), num(h))
+}
+
+// Line проводит линию толщины width оттенка серого gray.
+func (p *Page) Line(x1, y1, x2, y2, width, gray float64) {
+	fmt.Fprintf(&p.content, "%s G %s w %s %s m %s %s l S\n", num(gray), num(width), num(x1), num(p.height-y1), num(x2), num(p.height-y2))
+}
+
+// Image выводит изображение в прямоугольник (x, y, w, h).
+func (p *Page) Image(img *Image, x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /Im%d Do Q\n", num(w), num(h), num(x), num(p.height-y-h), img.index)
+}
+
+// WriteTo пишет документ в w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	out := &pdfWriter{}
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Номера объектов: 1 - каталог, 2 - дерево страниц, 3 - общие ресурсы, затем страницы, изображения и шрифт
+	const catalogID, pagesID, resourcesID = 1, 2, 3
+	nextID := 4
+	pageIDs := make([]int, len(d.pages))
+	for i := range d.pages {
+		pageIDs[i] = nextID
+		nextID += 2 // Страница и поток содержимого
+	}
+	imageIDs := make([]int, len(d.images))
+	for i := range d.images {
+		imageIDs[i] = nextID
+		nextID++
+	}
+	fontID := nextID
+
+	out.object(catalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+
+	kids := make([]string, len(pageIDs))
+	for i, id := range pageIDs {
+		kids[i] = fmt.Sprintf("%d 0 R", id)
+	}
+	out.object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+
+	var resources strings.Builder
+	resources.WriteString("<< /ProcSet [/PDF /Text /ImageB /ImageC]")
+	if len(d.used) > 0 {
+		fmt.Fprintf(&resources, " /Font << /F1 %d 0 R >>", fontID)
+	}
+	if len(d.images) > 0 {
+		resources.WriteString(" /XObject <<")
+		for i, img := range d.images {
+			fmt.Fprintf(&resources, " /Im%d %d 0 R", img.index, imageIDs[i])
+		}
+		resources.WriteString(" >>")
+	}
+	resources.WriteString(" >>")
+	out.object(resourcesID, resources.String())
+
+	for i, p := range d.pages {
+		out.object(pageIDs[i], fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %d 0 R /Contents %d 0 R >>",
+			pagesID, num(p.width), num(p.height), resourcesID, pageIDs[i]+1))
+		if err := out.stream(pageIDs[i]+1, "", p.content.Bytes(), true); err != nil {
+			return out.n, err
+		}
+	}
+
+	for i, img := range d.images {
+		colorSpace := "/DeviceRGB"
+		if img.gray {
+			colorSpace = "/DeviceGray"
+		}
+		dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8 /Filter /DCTDecode",
+			img.width, img.height, colorSpace)
+		if err := out.stream(imageIDs[i], dict, img.data, false); err != nil {
+			return out.n, err
+		}
+	}
+
+	if len(d.used) > 0 {
+		if err := d.writeFont(out, fontID); err != nil {
+			return out.n, err
+		}
+	}
+
+	return out.finish(w, catalogID)
+}
+
+// writeFont пишет составной шрифт Type0 с кодировкой Identity-H: коды в тексте - номера глифов
+func (d *Document) writeFont(out *pdfWriter, id int) error {
+	f := d.font
+	cidID, descriptorID, fileID, toUnicodeID := id+1, id+2, id+3, id+4
+	baseFont := "RIMSUB+" + f.name // Префикс подмножества шрифта
+
+	glyphs := make([]int, 0, len(d.used))
+	for g := range d.used {
+		glyphs = append(glyphs, int(g))
+	}
+	sort.Ints(glyphs)
+
+	out.object(id, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		baseFont, cidID, toUnicodeID))
+
+	var widths strings.Builder
+	for _, g := range glyphs {
+		fmt.Fprintf(&widths, "%d [%d] ", g, f.scale(int(f.advances[g])))
+	}
+	out.object(cidID, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /W [%s] /CIDToGIDMap /Identity >>",
+		baseFont, descriptorID, strings.TrimSpace(widths.String())))
+
+	out.object(descriptorID, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		baseFont, f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]), f.scale(f.ascent), f.scale(f.descent), f.scale(f.ascent), fileID))
+
+	file := f.subset(d.used)
+	if err := out.stream(fileID, fmt.Sprintf("/Length1 %d", len(file)), file, true); err != nil {
+		return err
+	}
+
+	var cmap strings.Builder
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	cmap.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	cmap.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	cmap.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for start := 0; start < len(glyphs); start += 100 {
+		chunk := glyphs[start:min(start+100, len(glyphs))]
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", len(chunk))
+		for _, g := range chunk {
+			fmt.Fprintf(&cmap, "<%04X> <", g)
+			for _, unit := range utf16.Encode([]rune{d.used[uint16(g)]}) {
+				fmt.Fprintf(&cmap, "%04X", unit)
+			}
+			cmap.WriteString(">\n")
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return out.stream(toUnicodeID, "", []byte(cmap.String()), true)
+}
+
+// pdfWriter накапливает тело документа и смещения объектов для таблицы xref
+type pdfWriter struct {
+	bytes.Buffer
+	offsets map[int]int
+	n       int64
+}
+
+func (w *pdfWriter) object(id int, body string) {
+	if w.offsets == nil {
+		w.offsets = make(map[int]int)
+	}
+	w.offsets[id] = w.Len()
+	fmt.Fprintf(w, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream пишет объект-поток; dict - дополнительные записи словаря потока
+func (w *pdfWriter) stream(id int, dict string, data []byte, compress bool) error {
+	if compress {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+		dict = strings.TrimSpace(dict + " /Filter /FlateDecode")
+	}
+	if w.offsets == nil {
+		w.offsets = make(map[int]int)
+	}
+	w.offsets[id] = w.Len()
+	fmt.Fprintf(w, "%d 0 obj\n<< %s /Length %d >>\nstream\n", id, dict, len(data))
+	w.Write(data)
+	w.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+// finish дописывает таблицу xref и трейлер и передает документ в dst
+func (w *pdfWriter) finish(dst io.Writer, rootID int) (int64, error) {
+	size := 0
+	for id := range w.offsets {
+		size = max(size, id)
+	}
+	xref := w.Len()
+	fmt.Fprintf(w, "xref\n0 %d\n0000000000 65535 f \n", size+1)
+	for id := 1; id <= size; id++ {
+		fmt.Fprintf(w, "%010d 00000 n \n", w.offsets[id])
+	}
+	fmt.Fprintf(w, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", size+1, rootID, xref)
+	n, err := w.Buffer.WriteTo(dst)
+	w.n += n
+	return w.n, err
+}
+
+// num форматирует число для PDF без экспоненты и лишних нулей
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
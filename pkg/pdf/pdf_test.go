@@ -0,0 +1,359 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Глифы тестового шрифта: 0 - .notdef, затем печатные ASCII и кириллица А-я
+const (
+	asciiFirst, asciiLast       = 0x20, 0x7E
+	cyrillicFirst, cyrillicLast = 0x410, 0x44F
+	testGlyphs                  = 1 + (asciiLast - asciiFirst + 1) + (cyrillicLast - cyrillicFirst + 1)
+)
+
+// testGlyph - описание простого глифа: один контур, bbox и байт-метка с номером глифа
+func testGlyph(g int) []byte {
+	data := make([]byte, 12)
+	binary.BigEndian.PutUint16(data, 1)
+	binary.BigEndian.PutUint16(data[6:], 500)
+	binary.BigEndian.PutUint16(data[8:], 700)
+	binary.BigEndian.PutUint16(data[10:], uint16(g))
+	return data
+}
+
+// newTestFont собирает минимальный шрифт TrueType с таблицей символов формата 12
+func newTestFont(t *testing.T) *Font {
+	t.Helper()
+
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint32(head, 0x00010000)
+	binary.BigEndian.PutUint16(head[18:], 1000) // unitsPerEm
+	for i, v := range []int16{-50, -200, 950, 900} {
+		binary.BigEndian.PutUint16(head[36+2*i:], uint16(v))
+	}
+	binary.BigEndian.PutUint16(head[50:], 1) // loca в длинном формате
+
+	hhea := make([]byte, 36)
+	binary.BigEndian.PutUint16(hhea[4:], 800)
+	binary.BigEndian.PutUint16(hhea[6:], uint16(0x10000-200))
+	binary.BigEndian.PutUint16(hhea[34:], 2) // Ширины: .notdef и общая для остальных глифов
+
+	maxp := make([]byte, 6)
+	binary.BigEndian.PutUint32(maxp, 0x00005000)
+	binary.BigEndian.PutUint16(maxp[4:], testGlyphs)
+
+	hmtx := make([]byte, 8)
+	binary.BigEndian.PutUint16(hmtx, 500)
+	binary.BigEndian.PutUint16(hmtx[4:], 600)
+
+	var glyf bytes.Buffer
+	loca := make([]byte, 4*(testGlyphs+1))
+	for g := 0; g < testGlyphs; g++ {
+		binary.BigEndian.PutUint32(loca[4*g:], uint32(glyf.Len()))
+		glyf.Write(testGlyph(g))
+	}
+	binary.BigEndian.PutUint32(loca[4*testGlyphs:], uint32(glyf.Len()))
+
+	groups := [][3]uint32{
+		{asciiFirst, asciiLast, 1},
+		{cyrillicFirst, cyrillicLast, 1 + asciiLast - asciiFirst + 1},
+	}
+	cmap := make([]byte, 12+16+12*len(groups))
+	binary.BigEndian.PutUint16(cmap[2:], 1)
+	binary.BigEndian.PutUint16(cmap[4:], 3)
+	binary.BigEndian.PutUint16(cmap[6:], 10)
+	binary.BigEndian.PutUint32(cmap[8:], 12)
+	sub := cmap[12:]
+	binary.BigEndian.PutUint16(sub, 12)
+	binary.BigEndian.PutUint32(sub[4:], uint32(len(sub)))
+	binary.BigEndian.PutUint32(sub[12:], uint32(len(groups)))
+	for i, g := range groups {
+		binary.BigEndian.PutUint32(sub[16+12*i:], g[0])
+		binary.BigEndian.PutUint32(sub[20+12*i:], g[1])
+		binary.BigEndian.PutUint32(sub[24+12*i:], g[2])
+	}
+
+	font, err := ParseFont("Test Sans", buildFont(map[string][]byte{
+		"head": head, "hhea": hhea, "maxp": maxp, "hmtx": hmtx, "loca": loca, "glyf": glyf.Bytes(), "cmap": cmap,
+	}))
+	if err != nil {
+		t.Fatalf("ParseFont: %v", err)
+	}
+	return font
+}
+
+// newTestDocument собирает документ из двух страниц с текстом, фигурами и двумя изображениями
+func newTestDocument(t *testing.T) *Document {
+	t.Helper()
+	doc := New(newTestFont(t))
+
+	gray := image.NewGray(image.Rect(0, 0, 4, 3))
+	var grayJPEG bytes.Buffer
+	if err := jpeg.Encode(&grayJPEG, gray, nil); err != nil {
+		t.Fatal(err)
+	}
+	photo, err := doc.AddImage(grayJPEG.Bytes())
+	if err != nil {
+		t.Fatalf("AddImage(jpeg): %v", err)
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, 2, 5))
+	rgba.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var rgbaPNG bytes.Buffer
+	if err := png.Encode(&rgbaPNG, rgba); err != nil {
+		t.Fatal(err)
+	}
+	logo, err := doc.AddImage(rgbaPNG.Bytes())
+	if err != nil {
+		t.Fatalf("AddImage(png): %v", err)
+	}
+
+	first := doc.AddPage(A4Width, A4Height)
+	first.Text(20, 30, 12, "Анна Петрова")
+	first.FillRect(10, 10, 100, 20, 0.9)
+	first.StrokeRect(10, 10, 100, 20, 0.5, 0)
+	first.Line(0, 50, A4Width, 50, 1, 0.5)
+	first.Image(photo, 20, 60, 40, 30)
+
+	second := doc.AddPage(A6Width, A6Height)
+	second.Text(10, 20, 10, "QR")
+	second.FillRects([][4]float64{{0, 0, 1, 1}, {2, 0, 1, 1}})
+	second.Image(photo, 0, 0, 10, 10)
+	second.Image(logo, 10, 0, 10, 25)
+	return doc
+}
+
+func TestWriteToXref(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      func(t *testing.T) *Document
+		wantSize int
+	}{
+		// Каталог, дерево страниц и ресурсы без страниц и шрифта
+		{name: "empty", doc: func(t *testing.T) *Document { return New(newTestFont(t)) }, wantSize: 4},
+		{name: "blank page", doc: func(t *testing.T) *Document {
+			doc := New(newTestFont(t))
+			doc.AddPage(A6Width, A6Height)
+			return doc
+		}, wantSize: 6},
+		// 3 общих объекта, 2 страницы с потоками, 2 изображения и 5 объектов шрифта
+		{name: "pages, images and text", doc: newTestDocument, wantSize: 15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := tt.doc(t).WriteTo(&buf)
+			if err != nil {
+				t.Fatalf("WriteTo: %v", err)
+			}
+			if n != int64(buf.Len()) {
+				t.Fatalf("WriteTo returned %d, wrote %d bytes", n, buf.Len())
+			}
+			out := buf.Bytes()
+			if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) {
+				t.Fatalf("missing header: %q", out[:min(len(out), 16)])
+			}
+
+			offsets, size := checkXref(t, out)
+			if size != tt.wantSize {
+				t.Fatalf("trailer /Size = %d, want %d", size, tt.wantSize)
+			}
+			for id := 1; id < size; id++ {
+				checkStream(t, out, id, offsets[id])
+			}
+		})
+	}
+}
+
+// checkXref разбирает startxref, таблицу xref и трейлер и проверяет, что каждая запись указывает
+// на начало своего объекта. Возвращает смещения объектов по номерам и /Size трейлера.
+func checkXref(t *testing.T, out []byte) (map[int]int, int) {
+	t.Helper()
+
+	tail := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(out)
+	if tail == nil {
+		t.Fatalf("missing startxref at the end: %q", out[max(0, len(out)-40):])
+	}
+	xref, _ := strconv.Atoi(string(tail[1]))
+	if xref <= 0 || xref >= len(out) {
+		t.Fatalf("startxref %d is out of range", xref)
+	}
+
+	section := string(out[xref:])
+	header := regexp.MustCompile(`^xref\n0 (\d+)\n`).FindStringSubmatch(section)
+	if header == nil {
+		t.Fatalf("startxref %d does not point at the xref table: %q", xref, section[:min(len(section), 20)])
+	}
+	count, _ := strconv.Atoi(header[1])
+	entries := section[len(header[0]):]
+	// Каждая запись занимает ровно 20 байт, включая пробел и перевод строки в конце
+	if len(entries) < 20*count {
+		t.Fatalf("xref table is truncated")
+	}
+	if entries[:20] != "0000000000 65535 f \n" {
+		t.Fatalf("xref entry 0 = %q", entries[:20])
+	}
+
+	entry := regexp.MustCompile(`^(\d{10}) 00000 n \n$`)
+	offsets := make(map[int]int, count)
+	for id := 1; id < count; id++ {
+		m := entry.FindStringSubmatch(entries[20*id : 20*id+20])
+		if m == nil {
+			t.Fatalf("xref entry %d = %q", id, entries[20*id:20*id+20])
+		}
+		offset, _ := strconv.Atoi(m[1])
+		want := fmt.Sprintf("%d 0 obj\n", id)
+		if offset >= xref || !bytes.HasPrefix(out[offset:], []byte(want)) {
+			t.Fatalf("xref entry %d points at %q, want %q", id, out[offset:min(offset+len(want), len(out))], want)
+		}
+		offsets[id] = offset
+	}
+
+	trailer := regexp.MustCompile(`^trailer\n<< /Size (\d+) /Root 1 0 R >>\n`).FindStringSubmatch(entries[20*count:])
+	if trailer == nil {
+		t.Fatalf("missing trailer after xref: %q", entries[20*count:])
+	}
+	size, _ := strconv.Atoi(trailer[1])
+	if size != count {
+		t.Fatalf("trailer /Size %d, xref has %d entries", size, count)
+	}
+	if objects := regexp.MustCompile(`(?m)^\d+ 0 obj$`).FindAll(out, -1); len(objects) != count-1 {
+		t.Fatalf("document has %d objects, xref lists %d", len(objects), count-1)
+	}
+	return offsets, size
+}
+
+// checkStream проверяет, что /Length объекта-потока совпадает с длиной данных между stream и endstream
+func checkStream(t *testing.T, out []byte, id, offset int) {
+	t.Helper()
+	obj := out[offset:]
+	end := bytes.Index(obj, []byte("\nendobj\n"))
+	if end < 0 {
+		t.Fatalf("object %d has no endobj", id)
+	}
+	m := regexp.MustCompile(`^\d+ 0 obj\n<<[^\n]* /Length (\d+) >>\nstream\n`).FindSubmatch(obj)
+	if m == nil {
+		return
+	}
+	length, _ := strconv.Atoi(string(m[1]))
+	if got := len(obj[len(m[0]):end]) - len("\nendstream"); got != length {
+		t.Fatalf("object %d: /Length %d, stream has %d bytes", id, length, got)
+	}
+}
+
+func TestWriteToContent(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := newTestDocument(t).WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"<< /Type /Pages /Kids [4 0 R 6 0 R] /Count 2 >>",
+		"/Font << /F1 10 0 R >> /XObject << /Im1 8 0 R /Im2 9 0 R >>",
+		"/MediaBox [0 0 595.28 841.89]",
+		"/MediaBox [0 0 297.64 419.53]",
+		"/Width 4 /Height 3 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /DCTDecode",
+		"/Width 2 /Height 5 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode",
+		"/BaseFont /RIMSUB+TestSans /Encoding /Identity-H",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("document has no %q", want)
+		}
+	}
+
+	// "Анна Петрова": А - глиф 0x60, н - 0x8D, пробел - 1 и т.д.; y отсчитывается от низа страницы
+	content := inflateObject(t, buf.Bytes(), 5)
+	for _, want := range []string{
+		"BT 0 g /F1 12 Tf 20 811.89 Td <0060008D008D00800001006F008500920090008E00820080> Tj ET",
+		"0.9 g 10 811.89 100 20 re f",
+		"0 G 0.5 w 10 811.89 100 20 re S",
+		"0.5 G 1 w 0 791.89 m 595.28 791.89 l S",
+		"q 40 0 0 30 20 751.89 cm /Im1 Do Q",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("first page content has no %q:\n%s", want, content)
+		}
+	}
+
+	toUnicode := inflateObject(t, buf.Bytes(), 14)
+	for _, want := range []string{"12 beginbfchar", "<0060> <0410>", "<0032> <0051>"} {
+		if !strings.Contains(toUnicode, want) {
+			t.Errorf("ToUnicode has no %q:\n%s", want, toUnicode)
+		}
+	}
+}
+
+func TestFontSubset(t *testing.T) {
+	font := newTestFont(t)
+	doc := New(font)
+	doc.AddPage(A6Width, A6Height).Text(0, 10, 10, "Анна QR")
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	// Встроенный шрифт без таблицы cmap: глифы выбираются по номерам (/CIDToGIDMap /Identity)
+	file := []byte(inflateObject(t, buf.Bytes(), 9))
+	subset := &Font{tables: make(map[string][]byte), locaLong: true, numGlyphs: testGlyphs}
+	for i := 0; i < int(binary.BigEndian.Uint16(file[4:])); i++ {
+		rec := file[12+16*i:]
+		offset, length := binary.BigEndian.Uint32(rec[8:]), binary.BigEndian.Uint32(rec[12:])
+		subset.tables[string(rec[:4])] = file[offset : offset+length]
+	}
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "loca", "glyf"} {
+		if subset.tables[tag] == nil {
+			t.Fatalf("subset has no %q table", tag)
+		}
+	}
+	if got := binary.BigEndian.Uint16(subset.tables["head"][50:]); got != 1 {
+		t.Fatalf("subset indexToLocFormat = %d, want long loca", got)
+	}
+
+	// Номера глифов сохраняются: использованные и .notdef описаны, остальные пустые
+	used := map[uint16]bool{0: true}
+	for _, r := range "Анна QR" {
+		used[font.glyph(r)] = true
+	}
+	for g := 0; g < testGlyphs; g++ {
+		data := subset.glyphData(uint16(g))
+		if used[uint16(g)] != (len(data) > 0) {
+			t.Fatalf("glyph %d: used %v, data %d bytes", g, used[uint16(g)], len(data))
+		}
+		if len(data) > 0 && !bytes.Equal(data, testGlyph(g)) {
+			t.Fatalf("glyph %d data changed: %v", g, data)
+		}
+	}
+}
+
+// inflateObject распаковывает поток FlateDecode объекта id
+func inflateObject(t *testing.T, out []byte, id int) string {
+	t.Helper()
+	m := regexp.MustCompile(fmt.Sprintf(`(?m)^%d 0 obj\n<<[^\n]*/Filter /FlateDecode /Length (\d+) >>\nstream\n`, id)).FindSubmatchIndex(out)
+	if m == nil {
+		t.Fatalf("object %d is not a FlateDecode stream", id)
+	}
+	length, _ := strconv.Atoi(string(out[m[2]:m[3]]))
+	zr, err := zlib.NewReader(bytes.NewReader(out[m[1] : m[1]+length]))
+	if err != nil {
+		t.Fatalf("object %d: %v", id, err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("object %d: %v", id, err)
+	}
+	return string(data)
+}
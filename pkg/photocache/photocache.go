@@ -0,0 +1,190 @@
+package photocache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxPhotoSize ограничивает размер загружаемого изображения
+const maxPhotoSize = 5 << 20
+
+// allowedHostSuffixes - домены Telegram, с которых разрешено загружать фото (защита от SSRF)
+var allowedHostSuffixes = []string{"t.me", "telegram.org", "telesco.pe"}
+
+var (
+	ErrSourceNotAllowed = errors.New("photo source host is not allowed")
+	ErrNotAnImage       = errors.New("photo source did not return an image")
+	// ErrSourceUnavailable - источник не ответил; адрес в ошибку не попадает, так как может содержать токен
+	ErrSourceUnavailable = errors.New("photo source is unavailable")
+	ErrPhotoNotFound     = errors.New("photo is not cached")
+)
+
+// Cache хранит копии фото профилей на диске и обновляет их по истечении ttl
+type Cache struct {
+	dir    string
+	ttl    time.Duration
+	client *http.Client
+	logger *slog.Logger
+}
+
+// New создает кэш фото в каталоге dir. Каталог создается при первой записи.
+func New(dir string, ttl time.Duration, logger *slog.Logger) *Cache {
+	return &Cache{
+		dir:    dir,
+		ttl:    ttl,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: logger,
+	}
+}
+
+// Get возвращает фото владельца owner, загруженное с sourceURL.
+// Копия в кэше используется, пока не старше ttl; при смене sourceURL фото загружается заново.
+func (c *Cache) Get(ctx context.Context, owner, sourceURL string) ([]byte, string, error) {
+	path := c.path(owner, sourceURL)
+
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < c.ttl {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return data, http.DetectContentType(data), nil
+		}
+		c.logger.WarnContext(ctx, "Failed to read cached photo", slog.String("path", path), slog.Any("error", err))
+	}
+
+	data, err := c.fetch(ctx, sourceURL)
+	if err != nil {
+		// Если источник недоступен, отдаем устаревшую копию
+		if stale, readErr := os.ReadFile(path); readErr == nil {
+			c.logger.WarnContext(ctx, "Serving stale cached photo", slog.String("owner", owner), slog.Any("error", err))
+			return stale, http.DetectContentType(stale), nil
+		}
+		return nil, "", err
+	}
+
+	c.store(ctx, owner, path, data)
+	return data, http.DetectContentType(data), nil
+}
+
+// Lookup возвращает сохраненную через Fetch версию version фото владельца owner, не обращаясь к источнику.
+// Если такой версии нет, возвращается ErrPhotoNotFound.
+func (c *Cache) Lookup(owner, version string) ([]byte, string, error) {
+	data, err := os.ReadFile(c.path(owner, version))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, "", ErrPhotoNotFound
+		}
+		return nil, "", err
+	}
+	return data, http.DetectContentType(data), nil
+}
+
+// Fetch загружает фото владельца owner с sourceURL и сохраняет его как версию version (например, file_unique_id
+// Telegram), удаляя прежние версии. В отличие от Get, адрес не входит в ключ и может меняться между загрузками.
+func (c *Cache) Fetch(ctx context.Context, owner, version, sourceURL string) error {
+	data, err := c.fetch(ctx, sourceURL)
+	if err != nil {
+		return err
+	}
+	return c.store(ctx, owner, c.path(owner, version), data)
+}
+
+// Remove удаляет все сохраненные фото владельца owner
+func (c *Cache) Remove(owner string) error {
+	paths, err := filepath.Glob(filepath.Join(c.dir, owner+"_*"))
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cache) fetch(ctx context.Context, sourceURL string) ([]byte, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil || u.Scheme != "https" || !hostAllowed(u.Hostname()) {
+		return nil, ErrSourceNotAllowed
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrSourceUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("photo source returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPhotoSize))
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(http.DetectContentType(data), "image/") {
+		return nil, ErrNotAnImage
+	}
+	return data, nil
+}
+
+// store сохраняет фото и удаляет копии, загруженные с прежних адресов того же владельца.
+// Ошибка записывается в журнал: Get отдает загруженное фото и без сохраненной копии.
+func (c *Cache) store(ctx context.Context, owner, path string, data []byte) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		c.logger.WarnContext(ctx, "Failed to create photo cache directory", slog.String("dir", c.dir), slog.Any("error", err))
+		return err
+	}
+
+	old, _ := filepath.Glob(filepath.Join(c.dir, owner+"_*"))
+	for _, p := range old {
+		if p != path {
+			_ = os.Remove(p)
+		}
+	}
+
+	// Пишем во временный файл и переименовываем, чтобы параллельные запросы не читали недописанное фото
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		c.logger.WarnContext(ctx, "Failed to write cached photo", slog.String("path", path), slog.Any("error", err))
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		c.logger.WarnContext(ctx, "Failed to move cached photo", slog.String("path", path), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// path возвращает файл фото владельца owner; key - адрес источника для Get или версия для Fetch
+func (c *Cache) path(owner, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, owner+"_"+hex.EncodeToString(sum[:8]))
+}
+
+func hostAllowed(host string) bool {
+	for _, suffix := range allowedHostSuffixes {
+		if host == suffix || strings.HasSuffix(host, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package photocache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// png - заголовок PNG, по которому http.DetectContentType узнает изображение
+var png = []byte("\x89PNG\r\n\x1a\n0000")
+
+func TestLookupReturnsStoredVersion(t *testing.T) {
+	c := New(t.TempDir(), time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	if _, _, err := c.Lookup("contact_1", "v1"); !errors.Is(err, ErrPhotoNotFound) {
+		t.Fatalf("Lookup before store: error = %v, want ErrPhotoNotFound", err)
+	}
+	if err := c.store(ctx, "contact_1", c.path("contact_1", "v1"), png); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	data, contentType, err := c.Lookup("contact_1", "v1")
+	if err != nil || string(data) != string(png) || contentType != "image/png" {
+		t.Fatalf("Lookup = %q, %q, %v", data, contentType, err)
+	}
+
+	// Новая версия заменяет прежнюю, фото других владельцев не затрагиваются
+	if err := c.store(ctx, "contact_10", c.path("contact_10", "v1"), png); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if err := c.store(ctx, "contact_1", c.path("contact_1", "v2"), png); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if _, _, err := c.Lookup("contact_1", "v1"); !errors.Is(err, ErrPhotoNotFound) {
+		t.Errorf("Lookup replaced version: error = %v, want ErrPhotoNotFound", err)
+	}
+	if _, _, err := c.Lookup("contact_10", "v1"); err != nil {
+		t.Errorf("Lookup other owner: %v", err)
+	}
+}
+
+func TestRemoveDeletesOnlyOwnerPhotos(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+	for _, owner := range []string{"contact_1", "contact_10"} {
+		if err := c.store(ctx, owner, c.path(owner, "v1"), png); err != nil {
+			t.Fatalf("store: %v", err)
+		}
+	}
+
+	if err := c.Remove("contact_1"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := c.Remove("contact_1"); err != nil {
+		t.Fatalf("Remove without photos: %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != 1 || filepath.Base(files[0]) != filepath.Base(c.path("contact_10", "v1")) {
+		t.Errorf("files = %v, want only the photo of contact_10", files)
+	}
+	if _, err := os.Stat(c.path("contact_1", "v1")); !os.IsNotExist(err) {
+		t.Errorf("removed photo still exists: %v", err)
+	}
+}
+
+func TestFetchRejectsForeignHosts(t *testing.T) {
+	c := New(t.TempDir(), time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, source := range []string{"http://api.telegram.org/file/x", "https://example.com/a.png", "https://telegram.org.example.com/a.png"} {
+		if err := c.Fetch(context.Background(), "contact_1", "v1", source); !errors.Is(err, ErrSourceNotAllowed) {
+			t.Errorf("Fetch(%s): error = %v, want ErrSourceNotAllowed", source, err)
+		}
+	}
+}
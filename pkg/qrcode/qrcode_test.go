@@ -0,0 +1,465 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// go test ./pkg/qrcode -update перезаписывает эталонные матрицы в testdata
+var update = flag.Bool("update", false, "rewrite golden QR matrices in testdata")
+
+// goldenPayloads - данные эталонных матриц: версия 1, версия 3 и визитка версии 8 (с информацией о версии)
+var goldenPayloads = []struct {
+	name    string
+	data    string
+	version int
+}{
+	{name: "short", data: "rim", version: 1},
+	{name: "url", data: "https://rim.example.com/contacts/42", version: 3},
+	{
+		name: "vcard",
+		data: "BEGIN:VCARD\r\nVERSION:3.0\r\nN:Петрова;Анна;;;\r\nFN:Анна Петрова\r\n" +
+			"TEL;TYPE=CELL:+79161234567\r\nEMAIL:anna@example.com\r\nEND:VCARD\r\n",
+		version: 8,
+	},
+}
+
+// Информация о формате уровня M по маскам и информация о версии 7-10 (ISO/IEC 18004, приложения C и D)
+var (
+	formatInfoM = [8]int{
+		0b101010000010010, 0b101000100100101, 0b101111001111100, 0b101101101001011,
+		0b100010111111001, 0b100000011001110, 0b100111110010111, 0b100101010100000,
+	}
+	versionInfo = map[int]int{7: 0x07C94, 8: 0x085BC, 9: 0x09A99, 10: 0x0A4D3}
+)
+
+// Блоки уровня M по версиям: число байт данных в коротком блоке, число коротких и длинных блоков, байт коррекции в блоке
+var blocksM = [MaxVersion + 1]struct{ dataLen, short, long, ecc int }{
+	1: {16, 1, 0, 10}, 2: {28, 1, 0, 16}, 3: {44, 1, 0, 26}, 4: {32, 2, 0, 18}, 5: {43, 2, 0, 24},
+	6: {27, 4, 0, 16}, 7: {31, 4, 0, 18}, 8: {38, 2, 2, 22}, 9: {36, 3, 2, 22}, 10: {43, 4, 1, 26},
+}
+
+func TestAddECCKnownVector(t *testing.T) {
+	// "HELLO WORLD" в буквенно-цифровом режиме, версия 1-M: байты данных и кода Рида-Соломона из примера кодирования
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	got := addECC(data, 1)
+	if !bytes.Equal(got[:len(data)], data) || !bytes.Equal(got[len(data):], want) {
+		t.Fatalf("addECC = %v, want data followed by %v", got, want)
+	}
+}
+
+func TestEncodeGolden(t *testing.T) {
+	for _, tt := range goldenPayloads {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Encode([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if want := tt.version*4 + 17; code.Size != want {
+				t.Fatalf("size = %d, want %d (version %d)", code.Size, want, tt.version)
+			}
+
+			got := matrixString(code)
+			path := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("matrix differs from %s:\n%s", path, got)
+			}
+		})
+	}
+}
+
+// Эталонные матрицы сверяются независимым от кодировщика декодером: служебные узоры, информация о формате
+// и версии, синдромы Рида-Соломона каждого блока и исходные данные
+func TestEncodeDecodes(t *testing.T) {
+	payloads := []string{"", "a"}
+	for _, tt := range goldenPayloads {
+		payloads = append(payloads, tt.data)
+	}
+	// Наибольшая длина каждой версии и следующая за ней
+	for v := 1; v <= MaxVersion; v++ {
+		capacity := blocksM[v].short*blocksM[v].dataLen + blocksM[v].long*(blocksM[v].dataLen+1)
+		n := (8*capacity - 4 - countBits(v)) / 8
+		payloads = append(payloads, strings.Repeat("x", n), strings.Repeat("y", n+1))
+	}
+
+	for _, data := range payloads {
+		code, err := Encode([]byte(data))
+		if errors.Is(err, ErrTooLong) && len(data) > 213 {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Encode(%d bytes): %v", len(data), err)
+		}
+		got, err := decode(code)
+		if err != nil {
+			t.Fatalf("decode(%d bytes): %v", len(data), err)
+		}
+		if got != data {
+			t.Fatalf("decoded %q, want %q", got, data)
+		}
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(bytes.Repeat([]byte{'x'}, 213)); err != nil {
+		t.Fatalf("Encode(213 bytes): %v", err)
+	}
+	if _, err := Encode(bytes.Repeat([]byte{'x'}, 214)); !errors.Is(err, ErrTooLong) {
+		t.Fatalf("Encode(214 bytes) error = %v, want ErrTooLong", err)
+	}
+}
+
+func matrixString(c *Code) string {
+	var sb strings.Builder
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Black(x, y) {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// decode читает байтовый QR-код уровня M по правилам стандарта, не используя функции кодировщика
+func decode(c *Code) (string, error) {
+	version := (c.Size - 17) / 4
+	if version < 1 || version > MaxVersion || version*4+17 != c.Size {
+		return "", fmt.Errorf("unexpected size %d", c.Size)
+	}
+	function := functionModules(version)
+	if err := checkFunctionPatterns(c); err != nil {
+		return "", err
+	}
+
+	mask, err := readFormat(c)
+	if err != nil {
+		return "", err
+	}
+	if version >= 7 {
+		if err := checkVersionInfo(c, version); err != nil {
+			return "", err
+		}
+	}
+
+	// Данные читаются парами столбцов справа налево, змейкой снизу вверх и сверху вниз; столбец 6 пропускается
+	var bits []bool
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for _, x := range []int{right, right - 1} {
+				if !function[y*c.Size+x] {
+					bits = append(bits, c.Black(x, y) != masked(mask, x, y))
+				}
+			}
+		}
+	}
+	raw := make([]byte, len(bits)/8)
+	for i := range raw {
+		for j := 0; j < 8; j++ {
+			if bits[i*8+j] {
+				raw[i] |= 0x80 >> j
+			}
+		}
+	}
+
+	data, err := deinterleave(raw, version)
+	if err != nil {
+		return "", err
+	}
+	return readBytes(data, version)
+}
+
+// functionModules отмечает служебные модули версии: поисковые узоры с разделителями, синхронизацию,
+// выравнивающие узоры, информацию о формате и версии и темный модуль
+func functionModules(version int) []bool {
+	size := version*4 + 17
+	m := make([]bool, size*size)
+	set := func(x, y int) { m[y*size+x] = true }
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 9; x++ {
+			set(x, y)
+		}
+	}
+	for y := 0; y < 9; y++ {
+		for x := size - 8; x < size; x++ {
+			set(x, y)
+			set(y, x)
+		}
+	}
+	for i := 0; i < size; i++ {
+		set(6, i)
+		set(i, 6)
+	}
+	for _, center := range alignmentCenters(version) {
+		for dy := -2; dy <= 2; dy++ {
+			for dx := -2; dx <= 2; dx++ {
+				set(center[0]+dx, center[1]+dy)
+			}
+		}
+	}
+	if version >= 7 {
+		for i := 0; i < 6; i++ {
+			for j := size - 11; j < size-8; j++ {
+				set(i, j)
+				set(j, i)
+			}
+		}
+	}
+	return m
+}
+
+// alignmentCenters возвращает центры выравнивающих узоров версии по таблице стандарта (приложение E),
+// кроме пересекающихся с поисковыми узорами
+func alignmentCenters(version int) [][2]int {
+	positions := map[int][]int{
+		2: {6, 18}, 3: {6, 22}, 4: {6, 26}, 5: {6, 30}, 6: {6, 34},
+		7: {6, 22, 38}, 8: {6, 24, 42}, 9: {6, 26, 46}, 10: {6, 28, 50},
+	}[version]
+	last := version*4 + 17 - 7
+	var result [][2]int
+	for _, y := range positions {
+		for _, x := range positions {
+			if (x == 6 && y == 6) || (x == 6 && y == last) || (x == last && y == 6) {
+				continue
+			}
+			result = append(result, [2]int{x, y})
+		}
+	}
+	return result
+}
+
+// checkFunctionPatterns проверяет поисковые и выравнивающие узоры, линии синхронизации и темный модуль
+func checkFunctionPatterns(c *Code) error {
+	for _, corner := range [][2]int{{3, 3}, {c.Size - 4, 3}, {3, c.Size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := corner[0]+dx, corner[1]+dy
+				if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+					continue
+				}
+				dist := max(abs(dx), abs(dy))
+				if want := dist != 2 && dist != 4; c.Black(x, y) != want {
+					return fmt.Errorf("finder pattern at (%d,%d) broken at (%d,%d)", corner[0], corner[1], x, y)
+				}
+			}
+		}
+	}
+	for _, center := range alignmentCenters((c.Size - 17) / 4) {
+		for dy := -2; dy <= 2; dy++ {
+			for dx := -2; dx <= 2; dx++ {
+				if want := max(abs(dx), abs(dy)) != 1; c.Black(center[0]+dx, center[1]+dy) != want {
+					return fmt.Errorf("alignment pattern at (%d,%d) broken", center[0], center[1])
+				}
+			}
+		}
+	}
+	for i := 8; i < c.Size-8; i++ {
+		if c.Black(i, 6) != (i%2 == 0) || c.Black(6, i) != (i%2 == 0) {
+			return fmt.Errorf("timing pattern broken at %d", i)
+		}
+	}
+	if !c.Black(8, c.Size-8) {
+		return errors.New("dark module is missing")
+	}
+	return nil
+}
+
+// readFormat читает обе копии информации о формате и возвращает номер маски
+func readFormat(c *Code) (int, error) {
+	var first, second int
+	for i := 0; i < 15; i++ {
+		var x1, y1, x2, y2 int
+		switch {
+		case i < 6:
+			x1, y1 = 8, i
+		case i < 8:
+			x1, y1 = 8, i+1
+		case i == 8:
+			x1, y1 = 7, 8
+		default:
+			x1, y1 = 14-i, 8
+		}
+		if i < 8 {
+			x2, y2 = c.Size-1-i, 8
+		} else {
+			x2, y2 = 8, c.Size-15+i
+		}
+		if c.Black(x1, y1) {
+			first |= 1 << i
+		}
+		if c.Black(x2, y2) {
+			second |= 1 << i
+		}
+	}
+	if first != second {
+		return 0, fmt.Errorf("format copies differ: %015b and %015b", first, second)
+	}
+	for mask, info := range formatInfoM {
+		if info == first {
+			return mask, nil
+		}
+	}
+	return 0, fmt.Errorf("format %015b is not level M", first)
+}
+
+// checkVersionInfo сверяет обе копии информации о версии с таблицей стандарта
+func checkVersionInfo(c *Code, version int) error {
+	for i := 0; i < 18; i++ {
+		a, b := c.Size-11+i%3, i/3
+		want := versionInfo[version]>>i&1 == 1
+		if c.Black(a, b) != want || c.Black(b, a) != want {
+			return fmt.Errorf("version info bit %d mismatch", i)
+		}
+	}
+	return nil
+}
+
+// masked сообщает, инвертирует ли маска модуль в столбце x строки y
+func masked(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// deinterleave собирает блоки из перемеженных байт, проверяет их синдромы и возвращает байты данных
+func deinterleave(raw []byte, version int) ([]byte, error) {
+	b := blocksM[version]
+	count := b.short + b.long
+	if want := b.short*(b.dataLen+b.ecc) + b.long*(b.dataLen+1+b.ecc); len(raw) != want {
+		return nil, fmt.Errorf("read %d codewords, want %d", len(raw), want)
+	}
+	blocks := make([][]byte, count)
+	k := 0
+	for i := 0; i < b.dataLen+1; i++ {
+		for j := range blocks {
+			if i < b.dataLen || j >= b.short {
+				blocks[j] = append(blocks[j], raw[k])
+				k++
+			}
+		}
+	}
+	for i := 0; i < b.ecc; i++ {
+		for j := range blocks {
+			blocks[j] = append(blocks[j], raw[k])
+			k++
+		}
+	}
+
+	var data []byte
+	for j, block := range blocks {
+		// Кодовое слово делится на порождающий многочлен с корнями a^0..a^(ecc-1): все синдромы равны нулю
+		for i := 0; i < b.ecc; i++ {
+			root := byte(1)
+			for n := 0; n < i; n++ {
+				root = gfMulSlow(root, 2)
+			}
+			var s byte
+			for _, v := range block {
+				s = gfMulSlow(s, root) ^ v
+			}
+			if s != 0 {
+				return nil, fmt.Errorf("block %d: syndrome %d is %d", j, i, s)
+			}
+		}
+		data = append(data, block[:len(block)-b.ecc]...)
+	}
+	return data, nil
+}
+
+// gfMulSlow умножает в GF(256) по модулю x^8+x^4+x^3+x^2+1 сдвигами, без таблиц
+func gfMulSlow(x, y byte) byte {
+	var z byte
+	for y != 0 {
+		if y&1 != 0 {
+			z ^= x
+		}
+		hi := x & 0x80
+		x <<= 1
+		if hi != 0 {
+			x ^= 0x1D
+		}
+		y >>= 1
+	}
+	return z
+}
+
+// readBytes разбирает сегмент байтового режима и проверяет терминатор и байты-заполнители
+func readBytes(data []byte, version int) (string, error) {
+	pos := 0
+	read := func(n int) int {
+		v := 0
+		for i := 0; i < n; i++ {
+			v = v<<1 | int(data[pos>>3]>>(7-pos&7)&1)
+			pos++
+		}
+		return v
+	}
+	if mode := read(4); mode != 0x4 {
+		return "", fmt.Errorf("mode %04b, want byte mode", mode)
+	}
+	lengthBits := 8
+	if version >= 10 {
+		lengthBits = 16
+	}
+	n := read(lengthBits)
+	if (pos+8*n+7)/8 > len(data) {
+		return "", fmt.Errorf("length %d exceeds capacity", n)
+	}
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = byte(read(8))
+	}
+	if rest := 8*len(data) - pos; rest > 0 && read(min(4, rest)) != 0 {
+		return "", errors.New("terminator is not zero")
+	}
+	pos = (pos + 7) / 8 * 8
+	for pad := byte(0xEC); pos < 8*len(data); pad ^= 0xEC ^ 0x11 {
+		if b := byte(read(8)); b != pad {
+			return "", fmt.Errorf("pad byte %#x, want %#x", b, pad)
+		}
+	}
+	return string(out), nil
+}
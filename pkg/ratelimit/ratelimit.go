@@ -0,0 +1,79 @@
+// Package ratelimit ограничивает частоту запросов по алгоритму token bucket.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// idleTTL - через сколько после последнего запроса неиспользуемое ведро удаляется
+const idleTTL = 10 * time.Minute
+
+// bucket хранит доступные запросы одного клиента
+type bucket struct {
+	tokens   float64
+	updated  time.Time
+	lastSeen time.Time
+}
+
+// Limiter хранит ведра клиентов в памяти процесса.
+// Лимиты передаются при каждом вызове Allow, поэтому их можно менять на лету.
+type Limiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// New создает пустой Limiter.
+func New() *Limiter {
+	return &Limiter{buckets: map[string]*bucket{}, lastSweep: time.Now()}
+}
+
+// Allow расходует один запрос клиента key. Ведро вмещает burst запросов
+// и пополняется со скоростью perMinute запросов в минуту; perMinute <= 0 снимает ограничение.
+// Если запрос отклонен, возвращает время до появления следующего.
+func (l *Limiter) Allow(key string, perMinute, burst int) (bool, time.Duration) {
+	if perMinute <= 0 {
+		return true, 0
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	now := time.Now()
+	rate := float64(perMinute) / time.Minute.Seconds()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), updated: now}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	// Пополняем ведро за прошедшее время; уменьшение burst сразу ограничивает накопленный запас
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep удаляет ведра клиентов, не обращавшихся дольше idleTTL. Вызывается под l.mu.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleTTL {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,36 @@
+// Package requestctx читает из fiber.Ctx данные текущего запроса, которые сохраняют middleware
+// авторизации ("user", "user_id") и политики доступа ("role"), для обработчиков всех модулей.
+package requestctx
+
+import (
+	"time"
+
+	"rim/internal/domain"
+	"rim/pkg/timeutil"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Role возвращает роль, установленную middleware политики доступа, или guest.
+func Role(c *fiber.Ctx) string {
+	if role, ok := c.Locals("role").(string); ok && role != "" {
+		return role
+	}
+	return domain.RoleGuest
+}
+
+// ActorID возвращает ID пользователя, выполняющего запрос, или nil для анонимных запросов.
+func ActorID(c *fiber.Ctx) *uint {
+	if userID, ok := c.Locals("user_id").(uint); ok {
+		return &userID
+	}
+	return nil
+}
+
+// Location возвращает часовой пояс текущего пользователя для времени в ответах или UTC для анонимных запросов.
+func Location(c *fiber.Ctx) *time.Location {
+	if user, ok := c.Locals("user").(*domain.User); ok && user != nil {
+		return timeutil.LocationOrUTC(user.Timezone)
+	}
+	return time.UTC
+}
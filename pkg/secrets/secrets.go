@@ -0,0 +1,162 @@
+// Package secrets читает секреты (токены, пароли) из файлов Docker secrets, HashiCorp Vault
+// или переменных окружения и обновляет их при ротации без перезапуска.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Secret - значение секрета, которое может измениться при ротации.
+// Потребители должны вызывать Get при каждом использовании, а не сохранять значение.
+type Secret struct {
+	key  string
+	file string // Путь из <KEY>_FILE
+	env  string // Значение переменной <KEY> или значение по умолчанию
+
+	mu    sync.RWMutex
+	value string
+}
+
+// Get возвращает текущее значение секрета.
+func (s *Secret) Get() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.value
+}
+
+// set меняет значение и сообщает, изменилось ли оно.
+func (s *Secret) set(value string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.value == value {
+		return false
+	}
+	s.value = value
+	return true
+}
+
+// Loader находит и обновляет секреты. Источник секрета KEY выбирается в порядке:
+//  1. файл из переменной KEY_FILE (Docker secrets: /run/secrets/...);
+//  2. поле KEY (или key) секрета Vault, если задан VaultConfig;
+//  3. переменная окружения KEY.
+type Loader struct {
+	vault *vaultClient
+
+	mu        sync.Mutex
+	secrets   []*Secret
+	vaultData map[string]string
+}
+
+// NewLoader создает Loader. Если vault.Addr пуст, Vault не используется.
+// Секрет Vault читается сразу, чтобы ошибка конфигурации обнаружилась при запуске.
+func NewLoader(vault VaultConfig) (*Loader, error) {
+	l := &Loader{}
+	if vault.Addr == "" {
+		return l, nil
+	}
+	l.vault = newVaultClient(vault)
+	data, err := l.vault.read(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	l.vaultData = data
+	return l, nil
+}
+
+// Load находит секрет key. defaultValue используется, если секрет не найден ни в одном источнике.
+func (l *Loader) Load(key, defaultValue string) (*Secret, error) {
+	s := &Secret{key: key, file: os.Getenv(key + "_FILE"), env: defaultValue}
+	if value, ok := os.LookupEnv(key); ok {
+		s.env = value
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	value, err := l.resolve(s)
+	if err != nil {
+		return nil, err
+	}
+	s.value = value
+	l.secrets = append(l.secrets, s)
+	return s, nil
+}
+
+// Refresh перечитывает файлы и Vault и обновляет изменившиеся секреты.
+// Возвращает ключи обновленных секретов.
+func (l *Loader) Refresh(ctx context.Context) ([]string, error) {
+	var vaultData map[string]string
+	if l.vault != nil {
+		data, err := l.vault.read(ctx)
+		if err != nil {
+			return nil, err
+		}
+		vaultData = data
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.vault != nil {
+		l.vaultData = vaultData
+	}
+
+	// Ошибка чтения одного файла не мешает обновить остальные секреты
+	var rotated []string
+	var errs []error
+	for _, s := range l.secrets {
+		value, err := l.resolve(s)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if s.set(value) {
+			rotated = append(rotated, s.key)
+		}
+	}
+	return rotated, errors.Join(errs...)
+}
+
+// Watch вызывает Refresh каждые interval до отмены ctx. Значения секретов в лог не пишутся.
+func (l *Loader) Watch(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rotated, err := l.Refresh(ctx)
+			if err != nil {
+				logger.ErrorContext(ctx, "Failed to refresh secrets, keeping previous values", slog.Any("error", err))
+			}
+			if len(rotated) > 0 {
+				logger.InfoContext(ctx, "Secrets rotated", slog.Any("keys", rotated))
+			}
+		}
+	}
+}
+
+// resolve читает значение секрета из первого доступного источника. Вызывается под l.mu.
+func (l *Loader) resolve(s *Secret) (string, error) {
+	if s.file != "" {
+		data, err := os.ReadFile(s.file)
+		if err != nil {
+			return "", fmt.Errorf("read secret %s from file: %w", s.key, err)
+		}
+		// Редакторы и echo добавляют перевод строки в конце файла
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if value, ok := l.vaultData[s.key]; ok {
+		return value, nil
+	}
+	if value, ok := l.vaultData[strings.ToLower(s.key)]; ok {
+		return value, nil
+	}
+	return s.env, nil
+}
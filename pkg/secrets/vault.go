@@ -0,0 +1,93 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultConfig описывает секрет HashiCorp Vault, поля которого используются как секреты приложения.
+type VaultConfig struct {
+	Addr string // Адрес Vault, например https://vault.example.com:8200
+	// Token или TokenFile - токен доступа. Файл перечитывается при каждом обращении,
+	// поэтому подходит для токенов, обновляемых Vault Agent.
+	Token     string
+	TokenFile string
+	// Path - путь секрета относительно /v1/, например secret/data/rim (KV v2) или secret/rim (KV v1)
+	Path string
+}
+
+// vaultClient читает секрет Vault через HTTP API
+type vaultClient struct {
+	cfg    VaultConfig
+	client *http.Client
+}
+
+func newVaultClient(cfg VaultConfig) *vaultClient {
+	return &vaultClient{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// read возвращает строковые поля секрета. Поддерживаются хранилища KV версий 1 и 2.
+func (v *vaultClient) read(ctx context.Context) (map[string]string, error) {
+	token := v.cfg.Token
+	if v.cfg.TokenFile != "" {
+		data, err := os.ReadFile(v.cfg.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("read vault token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	url := strings.TrimRight(v.cfg.Addr, "/") + "/v1/" + strings.TrimLeft(v.cfg.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("vault returned status %d for %s", resp.StatusCode, v.cfg.Path)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode vault response: %w", err)
+	}
+
+	// В KV v2 поля лежат в data.data, рядом с data.metadata
+	fields := body.Data
+	if nested, ok := body.Data["data"]; ok {
+		if _, isV2 := body.Data["metadata"]; isV2 {
+			fields = nil
+			if err := json.Unmarshal(nested, &fields); err != nil {
+				return nil, fmt.Errorf("decode vault kv v2 data: %w", err)
+			}
+		}
+	}
+
+	result := make(map[string]string, len(fields))
+	for key, raw := range fields {
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			continue // Нестроковые поля не используются как секреты
+		}
+		result[key] = value
+	}
+	return result, nil
+}
@@ -0,0 +1,36 @@
+package securityheaders
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CookiePolicy - атрибуты httpOnly cookies, которые выставляет сервер: сессии и идентификатора устройства.
+// Все cookies строятся через Cookie и Expired, чтобы вход, выход и проверка сессии не расходились в атрибутах:
+// браузер не удалит cookie, если атрибуты удаления не совпадают с атрибутами установки.
+type CookiePolicy struct {
+	SessionName string // Имя cookie сессии
+	Secure      bool   // Только HTTPS; без него cookie работает на http://localhost
+	SameSite    string // Strict, Lax или None; None требует Secure и нужен для встраивания в чужие сайты
+	Domain      string // Пустое - cookie только для хоста ответа
+}
+
+// Cookie возвращает cookie name со значением value, действующий до expires
+func (p CookiePolicy) Cookie(name, value string, expires time.Time) *fiber.Cookie {
+	return &fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Expires:  expires,
+		Domain:   p.Domain,
+		Path:     "/",
+		HTTPOnly: true,
+		Secure:   p.Secure,
+		SameSite: p.SameSite,
+	}
+}
+
+// Expired возвращает cookie, который удаляет name в браузере
+func (p CookiePolicy) Expired(name string) *fiber.Cookie {
+	return p.Cookie(name, "", time.Now().Add(-time.Hour))
+}
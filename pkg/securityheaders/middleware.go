@@ -0,0 +1,94 @@
+package securityheaders
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"rim/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+// Settings - настраиваемые во время работы части заголовков. Методы вызываются на каждый запрос,
+// поэтому реализация должна кэшировать значения.
+type Settings interface {
+	// CurrentContentSecurityPolicy возвращает источники Content-Security-Policy
+	CurrentContentSecurityPolicy(ctx context.Context) domain.ContentSecurityPolicy
+	// CurrentSecurityHeaders возвращает переопределения заголовков профиля
+	CurrentSecurityHeaders(ctx context.Context) domain.SecurityHeaders
+}
+
+// New добавляет заголовки безопасности профиля с переопределениями из settings и Content-Security-Policy.
+// Нарушения политики браузер отправляет на reportURI.
+func New(profile Profile, settings Settings, reportURI string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		p := profile.WithOverrides(settings.CurrentSecurityHeaders(c.Context()))
+
+		// Защита от XSS
+		c.Set("X-Content-Type-Options", "nosniff")
+		c.Set("X-XSS-Protection", "1; mode=block")
+		if p.FrameOptions != "" {
+			c.Set("X-Frame-Options", p.FrameOptions)
+		}
+		if p.ReferrerPolicy != "" {
+			c.Set("Referrer-Policy", p.ReferrerPolicy)
+		}
+		if p.PermissionsPolicy != "" {
+			c.Set("Permissions-Policy", p.PermissionsPolicy)
+		}
+		if p.HSTSMaxAge > 0 {
+			hsts := "max-age=" + strconv.Itoa(int(p.HSTSMaxAge.Seconds()))
+			if p.HSTSIncludeSubdomains {
+				hsts += "; includeSubDomains"
+			}
+			c.Set("Strict-Transport-Security", hsts)
+		}
+
+		// Content Security Policy для защиты от XSS
+		csp := settings.CurrentContentSecurityPolicy(c.Context())
+		policy := "default-src 'self'; " +
+			"script-src " + cspSources(csp.ScriptSrc) + "; " +
+			"style-src 'self' 'unsafe-inline'; " +
+			"img-src " + cspSources(csp.ImgSrc) + "; " +
+			"connect-src " + cspSources(csp.ConnectSrc) + "; " +
+			"font-src 'self'; " +
+			"object-src 'none'; " +
+			"base-uri 'self'; " +
+			"report-uri " + reportURI + "; " +
+			"report-to csp"
+		c.Set("Reporting-Endpoints", `csp="`+reportURI+`"`)
+		if csp.ReportOnly {
+			c.Set("Content-Security-Policy-Report-Only", policy)
+		} else {
+			c.Set("Content-Security-Policy", policy)
+		}
+
+		return c.Next()
+	}
+}
+
+// CORS разрешает запросы с cookies от источников профиля. Без источников заголовки CORS не отправляются
+// и браузер допускает только запросы с того же источника. next пропускает маршруты со своими правилами CORS.
+func CORS(profile Profile, next func(c *fiber.Ctx) bool) fiber.Handler {
+	if len(profile.CORSAllowOrigins) == 0 {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	return cors.New(cors.Config{
+		Next:             next,
+		AllowOrigins:     strings.Join(profile.CORSAllowOrigins, ", "),
+		AllowMethods:     "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-CSRF-Token",
+		AllowCredentials: true, // Важно для cookies
+		MaxAge:           int(profile.CORSMaxAge.Seconds()),
+	})
+}
+
+// cspSources перечисляет источники директивы; пустой список запрещает все источники
+func cspSources(sources []string) string {
+	if len(sources) == 0 {
+		return "'none'"
+	}
+	return strings.Join(sources, " ")
+}
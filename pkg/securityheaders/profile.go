@@ -0,0 +1,86 @@
+// Package securityheaders задает заголовки безопасности ответов и правила CORS по профилю окружения.
+package securityheaders
+
+import (
+	"strings"
+	"time"
+
+	"rim/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Окружения (APP_ENV)
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// Profile - заголовки безопасности, правила CORS и атрибуты cookies окружения
+type Profile struct {
+	Env string
+	// HSTSMaxAge - срок Strict-Transport-Security; 0 - заголовок не отправляется
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	FrameOptions          string // DENY или SAMEORIGIN; пустое значение - заголовок не отправляется
+	ReferrerPolicy        string
+	PermissionsPolicy     string
+	// CORSAllowOrigins - источники, которым разрешены запросы с cookies; пустой список - только тот же источник
+	CORSAllowOrigins []string
+	// CORSMaxAge - сколько браузер кэширует ответ на preflight (Access-Control-Max-Age); 0 - не кэширует
+	CORSMaxAge time.Duration
+	Cookies    CookiePolicy
+}
+
+// Development - локальная разработка: без HSTS, фронтенд на localhost, preflight не кэшируется,
+// чтобы изменения правил CORS были видны сразу. Cookies без Secure, иначе браузер не сохранит их по HTTP.
+var Development = Profile{
+	Env:               EnvDevelopment,
+	FrameOptions:      "DENY",
+	ReferrerPolicy:    "strict-origin-when-cross-origin",
+	PermissionsPolicy: "geolocation=(), microphone=(), camera=()",
+	CORSAllowOrigins:  []string{"http://localhost", "http://localhost:80", "http://localhost.local", "http://localhost.local:80"},
+	Cookies:           CookiePolicy{SessionName: "session_token", SameSite: fiber.CookieSameSiteLaxMode},
+}
+
+// Production - рабочее развертывание за HTTPS: HSTS на год, фронтенд на том же источнике,
+// другие источники добавляются через CORS_ALLOW_ORIGINS
+var Production = Profile{
+	Env:                   EnvProduction,
+	HSTSMaxAge:            365 * 24 * time.Hour,
+	HSTSIncludeSubdomains: true,
+	FrameOptions:          "DENY",
+	ReferrerPolicy:        "strict-origin-when-cross-origin",
+	PermissionsPolicy:     "geolocation=(), microphone=(), camera=()",
+	CORSMaxAge:            10 * time.Minute,
+	Cookies:               CookiePolicy{SessionName: "session_token", Secure: true, SameSite: fiber.CookieSameSiteStrictMode},
+}
+
+// ForEnvironment возвращает копию профиля окружения env; false - окружение неизвестно.
+func ForEnvironment(env string) (Profile, bool) {
+	var profile Profile
+	switch strings.ToLower(env) {
+	case EnvDevelopment:
+		profile = Development
+	case EnvProduction:
+		profile = Production
+	default:
+		return Profile{}, false
+	}
+	profile.CORSAllowOrigins = append([]string(nil), profile.CORSAllowOrigins...)
+	return profile, true
+}
+
+// WithOverrides возвращает профиль с переопределениями из системных настроек; незаданные поля остаются из профиля.
+func (p Profile) WithOverrides(overrides domain.SecurityHeaders) Profile {
+	if overrides.FrameOptions != nil {
+		p.FrameOptions = *overrides.FrameOptions
+	}
+	if overrides.HSTSMaxAgeSeconds != nil {
+		p.HSTSMaxAge = time.Duration(*overrides.HSTSMaxAgeSeconds) * time.Second
+	}
+	if overrides.PermissionsPolicy != nil {
+		p.PermissionsPolicy = strings.Join(overrides.PermissionsPolicy, ", ")
+	}
+	return p
+}
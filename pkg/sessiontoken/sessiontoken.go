@@ -0,0 +1,60 @@
+// Package sessiontoken формирует и разбирает версионированные токены сессий вида "v1:<значение>".
+// Версия позволяет менять устройство токена (например, перейти на подписанные токены), не завершая
+// активные сессии: токены прежних версий принимаются и заменяются на текущие при очередном запросе.
+package sessiontoken
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Версии токенов
+const (
+	// VersionLegacy - токены без префикса, выданные до введения версий: случайный UUID
+	VersionLegacy = 0
+	// Version1 - "v1:" и случайный UUID
+	Version1 = 1
+	// Current - версия, в которой выдаются новые токены
+	Current = Version1
+)
+
+// ErrInvalid - токен неизвестной версии или с некорректным значением
+var ErrInvalid = errors.New("invalid session token")
+
+// New возвращает новый токен текущей версии.
+func New() string {
+	return "v" + strconv.Itoa(Current) + ":" + uuid.New().String()
+}
+
+// Version возвращает версию токена и проверяет, что значение соответствует ее формату.
+// Токены неизвестных версий отклоняются без обращения к хранилищу сессий.
+func Version(token string) (int, error) {
+	prefix, value, found := strings.Cut(token, ":")
+	if !found {
+		if uuid.Validate(token) != nil {
+			return 0, ErrInvalid
+		}
+		return VersionLegacy, nil
+	}
+	version, err := strconv.Atoi(strings.TrimPrefix(prefix, "v"))
+	if err != nil || !strings.HasPrefix(prefix, "v") {
+		return 0, ErrInvalid
+	}
+	switch version {
+	case Version1:
+		if uuid.Validate(value) != nil {
+			return 0, ErrInvalid
+		}
+		return version, nil
+	}
+	return 0, ErrInvalid
+}
+
+// IsCurrent сообщает, выдан ли токен в текущей версии; иначе его нужно заменить.
+func IsCurrent(token string) bool {
+	version, err := Version(token)
+	return err == nil && version == Current
+}
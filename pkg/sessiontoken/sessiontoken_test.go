@@ -0,0 +1,64 @@
+package sessiontoken
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	token := New()
+	if !strings.HasPrefix(token, "v1:") {
+		t.Fatalf("token = %q, want prefix v1:", token)
+	}
+	if version, err := Version(token); err != nil || version != Current {
+		t.Errorf("Version(New()) = %d, %v; want %d", version, err, Current)
+	}
+	if !IsCurrent(token) {
+		t.Error("new token is not current")
+	}
+	if New() == token {
+		t.Error("two tokens are equal")
+	}
+}
+
+func TestVersion(t *testing.T) {
+	const id = "0b6f7c8e-3f1a-4c2d-9e5b-7a1d2c3b4e5f"
+	tests := []struct {
+		name    string
+		token   string
+		version int
+		invalid bool
+	}{
+		{name: "legacy uuid", token: id, version: VersionLegacy},
+		{name: "version 1", token: "v1:" + id, version: Version1},
+		{name: "empty", token: "", invalid: true},
+		{name: "legacy not uuid", token: "session-token", invalid: true},
+		{name: "version 1 not uuid", token: "v1:abc", invalid: true},
+		{name: "version 1 empty value", token: "v1:", invalid: true},
+		{name: "unknown version", token: "v2:" + id, invalid: true},
+		{name: "version without v", token: "1:" + id, invalid: true},
+		{name: "version not a number", token: "vx:" + id, invalid: true},
+		{name: "extra separator", token: "v1:" + id + ":x", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := Version(tt.token)
+			if tt.invalid {
+				if !errors.Is(err, ErrInvalid) {
+					t.Fatalf("Version(%q) = %d, %v; want ErrInvalid", tt.token, version, err)
+				}
+				if IsCurrent(tt.token) {
+					t.Errorf("IsCurrent(%q) = true for an invalid token", tt.token)
+				}
+				return
+			}
+			if err != nil || version != tt.version {
+				t.Fatalf("Version(%q) = %d, %v; want %d", tt.token, version, err, tt.version)
+			}
+			if got := IsCurrent(tt.token); got != (tt.version == Current) {
+				t.Errorf("IsCurrent(%q) = %v", tt.token, got)
+			}
+		})
+	}
+}
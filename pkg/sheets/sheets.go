@@ -0,0 +1,218 @@
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	apiURL          = "https://sheets.googleapis.com/v4/spreadsheets"
+	defaultTokenURI = "https://oauth2.googleapis.com/token"
+	scope           = "https://www.googleapis.com/auth/spreadsheets"
+)
+
+// ErrInvalidCredentials возвращается, если файл ключа сервисного аккаунта не удалось разобрать
+var ErrInvalidCredentials = errors.New("invalid google service account credentials")
+
+// Writer записывает таблицу значений на лист Google Sheets
+type Writer interface {
+	// ReplaceValues очищает лист и записывает rows начиная с ячейки A1
+	ReplaceValues(ctx context.Context, spreadsheetID, sheet string, rows [][]string) error
+}
+
+// serviceAccountKey - нужные поля JSON-ключа сервисного аккаунта Google
+type serviceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// serviceAccountWriter обращается к Sheets API от имени сервисного аккаунта.
+// Таблица должна быть открыта на редактирование для client_email аккаунта.
+type serviceAccountWriter struct {
+	email    string
+	key      *rsa.PrivateKey
+	tokenURI string
+	client   *http.Client
+	logger   *slog.Logger
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewServiceAccountWriter создает Writer по JSON-ключу сервисного аккаунта Google
+func NewServiceAccountWriter(credentials []byte, logger *slog.Logger) (Writer, error) {
+	var sa serviceAccountKey
+	if err := json.Unmarshal(credentials, &sa); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, fmt.Errorf("%w: client_email and private_key are required", ErrInvalidCredentials)
+	}
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("%w: private_key is not PEM encoded", ErrInvalidCredentials)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: private_key is not an RSA key", ErrInvalidCredentials)
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = defaultTokenURI
+	}
+	return &serviceAccountWriter{
+		email:    sa.ClientEmail,
+		key:      key,
+		tokenURI: sa.TokenURI,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		logger:   logger,
+	}, nil
+}
+
+func (w *serviceAccountWriter) ReplaceValues(ctx context.Context, spreadsheetID, sheet string, rows [][]string) error {
+	token, err := w.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	sheetRange := "'" + strings.ReplaceAll(sheet, "'", "''") + "'"
+	base := apiURL + "/" + url.PathEscape(spreadsheetID) + "/values/"
+
+	if err := w.call(ctx, http.MethodPost, base+url.PathEscape(sheetRange)+":clear", token, struct{}{}); err != nil {
+		return err
+	}
+
+	values := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = make([]interface{}, len(row))
+		for j, cell := range row {
+			values[i][j] = cell
+		}
+	}
+	body := map[string]interface{}{
+		"range":          sheetRange + "!A1",
+		"majorDimension": "ROWS",
+		"values":         values,
+	}
+	return w.call(ctx, http.MethodPut, base+url.PathEscape(sheetRange+"!A1")+"?valueInputOption=RAW", token, body)
+}
+
+func (w *serviceAccountWriter) call(ctx context.Context, method, endpoint, token string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "Google Sheets request failed", slog.Any("error", err))
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		w.logger.ErrorContext(ctx, "Google Sheets returned error status", slog.Int("status", resp.StatusCode), slog.String("body", string(msg)))
+		return fmt.Errorf("google sheets returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// token возвращает кэшированный access token или получает новый по подписанному JWT
+func (w *serviceAccountWriter) token(ctx context.Context) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.accessToken != "" && time.Now().Before(w.expiresAt) {
+		return w.accessToken, nil
+	}
+
+	assertion, err := w.signedJWT(time.Now())
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "Google token request failed", slog.Any("error", err))
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		w.logger.ErrorContext(ctx, "Google token endpoint returned error status", slog.Int("status", resp.StatusCode))
+		return "", fmt.Errorf("google token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", err
+	}
+	w.accessToken = tokenResp.AccessToken
+	// Обновляем токен заранее, чтобы он не истек посреди выгрузки
+	w.expiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn)*time.Second - time.Minute)
+	return w.accessToken, nil
+}
+
+// signedJWT формирует JWT для обмена на access token (RS256)
+func (w *serviceAccountWriter) signedJWT(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   w.email,
+		"scope": scope,
+		"aud":   w.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, w.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,88 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// Sender отправляет SMS сообщения через шлюз
+type Sender interface {
+	Send(ctx context.Context, phone, text string) error
+}
+
+// logSender пишет сообщения в лог вместо отправки. Используется, если шлюз не настроен.
+// Цифры в тексте маскируются: сообщения содержат коды входа, а лог читают не только владельцы телефонов.
+type logSender struct {
+	logger *slog.Logger
+}
+
+// NewLogSender создает Sender, который только логирует сообщения (для разработки)
+func NewLogSender(logger *slog.Logger) Sender {
+	return &logSender{logger: logger}
+}
+
+// digits находит цифры, которые маскируются в логе
+var digits = regexp.MustCompile(`[0-9]`)
+
+func (s *logSender) Send(ctx context.Context, phone, text string) error {
+	s.logger.InfoContext(ctx, "SMS gateway is not configured, message logged instead of sending", slog.String("phone", phone), slog.String("text", redact(text)))
+	return nil
+}
+
+// redact заменяет цифры текста на *
+func redact(text string) string {
+	return digits.ReplaceAllLiteralString(text, "*")
+}
+
+// httpSender отправляет сообщения POST запросом с JSON {"phone": ..., "text": ...} на URL шлюза
+type httpSender struct {
+	url    string
+	token  func() string // Читается при каждой отправке, чтобы учитывать ротацию токена
+	client *http.Client
+	logger *slog.Logger
+}
+
+// NewHTTPSender создает Sender для HTTP шлюза. Если token возвращает непустое значение, оно передается в заголовке Authorization.
+func NewHTTPSender(url string, token func() string, logger *slog.Logger) Sender {
+	return &httpSender{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: logger,
+	}
+}
+
+func (s *httpSender) Send(ctx context.Context, phone, text string) error {
+	body, err := json.Marshal(map[string]string{"phone": phone, "text": text})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := s.token(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to send SMS", slog.Any("error", err))
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		s.logger.ErrorContext(ctx, "SMS gateway returned error status", slog.Int("status", resp.StatusCode))
+		return fmt.Errorf("sms gateway returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogSenderRedactsCode(t *testing.T) {
+	var buf bytes.Buffer
+	sender := NewLogSender(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	if err := sender.Send(context.Background(), "+79990001122", "Код входа в RIM: 123456. Никому его не сообщайте."); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "123456") {
+		t.Fatalf("log contains login code: %s", out)
+	}
+	if !strings.Contains(out, "Код входа в RIM: ******.") {
+		t.Errorf("log does not contain masked text: %s", out)
+	}
+}
@@ -0,0 +1,42 @@
+// Package tenant передает текущую организацию через context.Context до репозиториев.
+package tenant
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type contextKey struct{}
+
+// Key - ключ организации в контексте. Значение, сохраненное через fiber.Ctx.Locals(Key, id),
+// доступно репозиториям из c.Context(), так как fasthttp.RequestCtx.Value читает UserValue.
+var Key = contextKey{}
+
+// With возвращает контекст, ограниченный организацией id.
+func With(ctx context.Context, id uint) context.Context {
+	return context.WithValue(ctx, Key, id)
+}
+
+// Unscoped возвращает контекст без ограничения организацией: для поиска по глобально
+// уникальным полям (телефон, Telegram ID) и фоновых задач всего развертывания.
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, Key, uint(0))
+}
+
+// FromContext возвращает ID организации из контекста; 0 - организация не задана.
+func FromContext(ctx context.Context) uint {
+	id, _ := ctx.Value(Key).(uint)
+	return id
+}
+
+// Scope ограничивает запрос к таблице table организацией из ctx.
+// Без организации в контексте запрос не меняется.
+func Scope(ctx context.Context, table string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if id := FromContext(ctx); id != 0 {
+			return db.Where(table+".organization_id = ?", id)
+		}
+		return db
+	}
+}
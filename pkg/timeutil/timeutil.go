@@ -0,0 +1,44 @@
+package timeutil
+
+import (
+	"time"
+	_ "time/tzdata" // Встроенная база часовых поясов, чтобы не зависеть от tzdata в контейнере
+)
+
+// DefaultTimezone используется, если пользователь не выбрал часовой пояс.
+const DefaultTimezone = "UTC"
+
+// Now возвращает текущее время в UTC. Все временные метки хранятся в UTC.
+func Now() time.Time {
+	return time.Now().UTC()
+}
+
+// LoadLocation возвращает часовой пояс по имени IANA (например, "Europe/Moscow").
+// Пустое имя соответствует UTC.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
+
+// LocationOrUTC возвращает часовой пояс по имени или UTC, если имя пустое или некорректное.
+func LocationOrUTC(name string) *time.Location {
+	loc, err := LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Format форматирует время в RFC3339 с явным смещением в указанном часовом поясе.
+// Нулевое время форматируется как пустая строка.
+func Format(t time.Time, loc *time.Location) string {
+	if t.IsZero() {
+		return ""
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(time.RFC3339)
+}
@@ -0,0 +1,76 @@
+// Package transaction передает транзакцию GORM через context.Context до репозиториев,
+// чтобы вызовы нескольких UseCase выполнялись атомарно.
+package transaction
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type contextKey struct{}
+
+// state - транзакция и действия, отложенные до ее фиксации
+type state struct {
+	tx          *gorm.DB
+	afterCommit []func()
+}
+
+// Run выполняет fn в транзакции db: репозитории, получающие соединение через DB, работают внутри нее.
+// Если fn возвращает ошибку, транзакция откатывается. Вложенный вызов выполняется в транзакции внешнего.
+func Run(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(contextKey{}).(*state); ok {
+		return fn(ctx)
+	}
+
+	st := &state{}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		st.tx = tx
+		return fn(context.WithValue(ctx, contextKey{}, st))
+	})
+	if err != nil {
+		return err
+	}
+	for _, f := range st.afterCommit {
+		f()
+	}
+	return nil
+}
+
+// Manager запускает функцию в транзакции. Передается в UseCase, операции которых изменяют
+// данные нескольких репозиториев: без него частичный сбой оставляет записи несогласованными.
+type Manager interface {
+	Run(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type manager struct {
+	db *gorm.DB
+}
+
+// NewManager создает Manager для транзакций db
+func NewManager(db *gorm.DB) Manager {
+	return &manager{db: db}
+}
+
+func (m *manager) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return Run(ctx, m.db, fn)
+}
+
+// DB возвращает транзакцию из ctx, а вне транзакции - db с контекстом ctx.
+// Репозитории используют его вместо db.WithContext(ctx).
+func DB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if st, ok := ctx.Value(contextKey{}).(*state); ok {
+		return st.tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// AfterCommit откладывает fn до фиксации транзакции из ctx (при откате fn не вызывается).
+// Вне транзакции fn вызывается сразу. Используется для уведомлений о созданных записях.
+func AfterCommit(ctx context.Context, fn func()) {
+	if st, ok := ctx.Value(contextKey{}).(*state); ok {
+		st.afterCommit = append(st.afterCommit, fn)
+		return
+	}
+	fn()
+}
@@ -0,0 +1,34 @@
+package validation
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// BindAndValidate разбирает JSON-тело запроса в T и проверяет его общим validator из Default.
+// ok=false - тело не подошло, и клиенту уже отправлен ответ в формате Response: 415, если Content-Type
+// не application/json, 400 для некорректного JSON и нарушенных правил. Обработчик в этом случае
+// возвращает err - ошибку отправки ответа, обычно nil.
+//
+//	req, ok, err := validation.BindAndValidate[CreateRequest](c)
+//	if !ok {
+//		return err
+//	}
+func BindAndValidate[T any](c *fiber.Ctx) (req T, ok bool, err error) {
+	lang := c.AcceptsLanguages(Languages...)
+	if !c.Is("json") {
+		return req, false, c.Status(fiber.StatusUnsupportedMediaType).JSON(Response{
+			Message: pick(lang == LangEN, "Тело запроса должно быть в формате JSON (Content-Type: application/json)", "Request body must be JSON (Content-Type: application/json)"),
+			Errors:  []FieldError{},
+		})
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return req, false, c.Status(fiber.StatusBadRequest).JSON(Response{
+			Message: pick(lang == LangEN, "Некорректное тело запроса", "Invalid request body"),
+			Errors:  []FieldError{},
+		})
+	}
+	if err := Default().Struct(req); err != nil {
+		return req, false, c.Status(fiber.StatusBadRequest).JSON(NewResponse(err, lang))
+	}
+	return req, true, nil
+}
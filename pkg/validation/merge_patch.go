@@ -0,0 +1,71 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MIMEMergePatch - тип тела запроса JSON Merge Patch (RFC 7396)
+const MIMEMergePatch = "application/merge-patch+json"
+
+// BindMergePatch разбирает тело запроса JSON Merge Patch в T и проверяет переданные значения, как BindAndValidate.
+// Поля T, которые можно менять, - указатели: отсутствующее в теле поле остается nil (не меняется),
+// а поле со значением null получает указатель на нулевое значение (пустую строку, 0, пустой список),
+// которое обработчик передает в usecase как очистку поля. Принимается Content-Type
+// application/merge-patch+json или application/json.
+func BindMergePatch[T any](c *fiber.Ctx) (req T, ok bool, err error) {
+	lang := c.AcceptsLanguages(Languages...)
+	if !c.Is("json") && !strings.HasPrefix(strings.ToLower(c.Get(fiber.HeaderContentType)), MIMEMergePatch) {
+		return req, false, c.Status(fiber.StatusUnsupportedMediaType).JSON(Response{
+			Message: pick(lang == LangEN, "Тело запроса должно быть в формате JSON Merge Patch (Content-Type: application/merge-patch+json)", "Request body must be a JSON Merge Patch (Content-Type: application/merge-patch+json)"),
+			Errors:  []FieldError{},
+		})
+	}
+	// Патч - всегда объект: по его членам видно, какие поля переданы со значением null
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(c.Body(), &members); err != nil || members == nil {
+		return req, false, invalidBody(c, lang)
+	}
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return req, false, invalidBody(c, lang)
+	}
+	// Проверяются только переданные значения: null еще не заменен, и omitempty пропускает nil
+	if err := Default().Struct(req); err != nil {
+		return req, false, c.Status(fiber.StatusBadRequest).JSON(NewResponse(err, lang))
+	}
+	setNullMembers(reflect.ValueOf(&req).Elem(), members)
+	return req, true, nil
+}
+
+// setNullMembers заменяет nil у полей-указателей структуры v, переданных в патче со значением null,
+// на указатель на нулевое значение. Срез получает пустой срез, а не nil, чтобы отличаться от непереданного.
+func setNullMembers(v reflect.Value, members map[string]json.RawMessage) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || field.Type.Kind() != reflect.Pointer {
+			continue
+		}
+		if raw, ok := members[name]; !ok || !bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			continue
+		}
+		zero := reflect.New(field.Type.Elem())
+		if field.Type.Elem().Kind() == reflect.Slice {
+			zero.Elem().Set(reflect.MakeSlice(field.Type.Elem(), 0, 0))
+		}
+		v.Field(i).Set(zero)
+	}
+}
+
+// invalidBody отвечает 400 на тело запроса, которое не удалось разобрать
+func invalidBody(c *fiber.Ctx, lang string) error {
+	return c.Status(fiber.StatusBadRequest).JSON(Response{
+		Message: pick(lang == LangEN, "Некорректное тело запроса", "Invalid request body"),
+		Errors:  []FieldError{},
+	})
+}
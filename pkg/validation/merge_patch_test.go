@@ -0,0 +1,108 @@
+package validation
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type patchRequest struct {
+	Name  *string   `json:"name" validate:"omitempty,max=5"`
+	Age   *int      `json:"age"`
+	Tags  *[]string `json:"tags"`
+	Plain string    `json:"plain"`
+}
+
+// patch отправляет тело в обработчик с BindMergePatch и возвращает статус ответа и разобранный запрос
+func patch(t *testing.T, contentType, body string) (int, patchRequest, Response) {
+	t.Helper()
+	var got patchRequest
+	app := fiber.New()
+	app.Patch("/", func(c *fiber.Ctx) error {
+		req, ok, err := BindMergePatch[patchRequest](c)
+		if !ok {
+			return err
+		}
+		got = req
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	r := httptest.NewRequest(fiber.MethodPatch, "/", strings.NewReader(body))
+	r.Header.Set(fiber.HeaderContentType, contentType)
+	r.Header.Set(fiber.HeaderAcceptLanguage, LangEN)
+	resp, err := app.Test(r)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+	var res Response
+	if resp.StatusCode != fiber.StatusNoContent {
+		data, _ := io.ReadAll(resp.Body)
+		if err := json.Unmarshal(data, &res); err != nil {
+			t.Fatalf("response %s: %v", data, err)
+		}
+	}
+	return resp.StatusCode, got, res
+}
+
+func TestBindMergePatchMembers(t *testing.T) {
+	status, req, _ := patch(t, MIMEMergePatch, `{"name": "Аня", "tags": null, "plain": "x"}`)
+	if status != fiber.StatusNoContent {
+		t.Fatalf("status = %d, want 204", status)
+	}
+	if req.Name == nil || *req.Name != "Аня" {
+		t.Errorf("name = %v, want Аня", req.Name)
+	}
+	// Отсутствующее поле не меняется, null очищает поле
+	if req.Age != nil {
+		t.Errorf("age = %v, want nil", *req.Age)
+	}
+	if req.Tags == nil || *req.Tags == nil || len(*req.Tags) != 0 {
+		t.Errorf("tags = %#v, want empty slice", req.Tags)
+	}
+	if req.Plain != "x" {
+		t.Errorf("plain = %q, want x", req.Plain)
+	}
+
+	status, req, _ = patch(t, fiber.MIMEApplicationJSON, `{"name": null, "age": null}`)
+	if status != fiber.StatusNoContent {
+		t.Fatalf("status = %d, want 204", status)
+	}
+	if req.Name == nil || *req.Name != "" || req.Age == nil || *req.Age != 0 || req.Tags != nil {
+		t.Errorf("request = %+v, want cleared name and age", req)
+	}
+}
+
+func TestBindMergePatchErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		field       string
+	}{
+		{name: "form content type", contentType: fiber.MIMEApplicationForm, body: `{}`, status: fiber.StatusUnsupportedMediaType},
+		{name: "array body", contentType: MIMEMergePatch, body: `[]`, status: fiber.StatusBadRequest},
+		{name: "null body", contentType: MIMEMergePatch, body: `null`, status: fiber.StatusBadRequest},
+		{name: "invalid json", contentType: MIMEMergePatch, body: `{"name":`, status: fiber.StatusBadRequest},
+		{name: "wrong member type", contentType: MIMEMergePatch, body: `{"age": "3"}`, status: fiber.StatusBadRequest},
+		{name: "validation", contentType: MIMEMergePatch, body: `{"name": "Александра"}`, status: fiber.StatusBadRequest, field: "name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, res := patch(t, tt.contentType, tt.body)
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d", status, tt.status)
+			}
+			if res.Message == "" {
+				t.Error("response without message")
+			}
+			if tt.field != "" && (len(res.Errors) != 1 || res.Errors[0].Field != tt.field) {
+				t.Errorf("errors = %+v, want one error for %s", res.Errors, tt.field)
+			}
+		})
+	}
+}
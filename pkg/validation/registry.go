@@ -0,0 +1,87 @@
+package validation
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// customValidator - правило, зарегистрированное модулем или пакетом, с сообщениями об ошибке
+type customValidator struct {
+	fn validator.Func
+	ru string
+	en string
+}
+
+var (
+	registryMu sync.RWMutex
+	validators = map[string]customValidator{}
+	enums      = map[string][]string{}
+
+	defaultOnce      sync.Once
+	defaultValidator *validator.Validate
+	defaultBuilt     bool // После создания общего validator новые правила добавить нельзя
+)
+
+// Register добавляет правило tag с сообщениями об ошибке на русском и английском.
+// Вызывается при инициализации пакета, до первого вызова Default; повторная регистрация tag - ошибка программы.
+func Register(tag string, fn validator.Func, ru, en string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if defaultBuilt {
+		panic(fmt.Sprintf("validation: rule %q registered after the shared validator was created", tag))
+	}
+	if _, exists := validators[tag]; exists {
+		panic(fmt.Sprintf("validation: rule %q is already registered", tag))
+	}
+	validators[tag] = customValidator{fn: fn, ru: ru, en: en}
+}
+
+// RegisterEnum задает допустимые значения перечисления name для тега enum=name.
+// Значения фиксированы; перечисления, которые меняются во время работы, задаются через SetRules.
+func RegisterEnum(name string, values ...string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := enums[name]; exists {
+		panic(fmt.Sprintf("validation: enum %q is already registered", name))
+	}
+	enums[name] = slices.Clone(values)
+}
+
+// Default возвращает общий validator со всеми зарегистрированными правилами.
+// Validator безопасен для одновременного использования, поэтому обработчики не создают свои экземпляры.
+func Default() *validator.Validate {
+	defaultOnce.Do(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		defaultValidator = newValidator()
+		for tag, custom := range validators {
+			if err := defaultValidator.RegisterValidation(tag, custom.fn); err != nil {
+				panic(fmt.Sprintf("validation: register rule %q: %v", tag, err))
+			}
+		}
+		defaultBuilt = true
+	})
+	return defaultValidator
+}
+
+// registeredEnum возвращает значения перечисления, зарегистрированного через RegisterEnum
+func registeredEnum(name string) ([]string, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	values, ok := enums[name]
+	return values, ok
+}
+
+// registeredMessage возвращает сообщение зарегистрированного правила tag на языке клиента
+func registeredMessage(tag string, en bool) (string, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	custom, ok := validators[tag]
+	if !ok {
+		return "", false
+	}
+	return pick(en, custom.ru, custom.en), true
+}
@@ -0,0 +1,87 @@
+package validation
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Rules - правила проверки, которые меняются без выпуска новой версии, например настройками администратора.
+// Используются тегами enum=<имя> (значение из списка) и length=<имя> (длина строки в символах).
+type Rules interface {
+	// Enum возвращает допустимые значения перечисления name; nil - перечисление неизвестно
+	Enum(name string) []string
+	// Length возвращает границы длины строки для правила name; ok=false - правило неизвестно
+	Length(name string) (min, max int, ok bool)
+}
+
+var (
+	rulesMu sync.RWMutex
+	rules   Rules
+)
+
+// SetRules задает источник правил для тегов enum и length.
+// Пока источник не задан, не проходят проверку поля с тегом length и с перечислениями, не зарегистрированными через RegisterEnum.
+func SetRules(r Rules) {
+	rulesMu.Lock()
+	rules = r
+	rulesMu.Unlock()
+}
+
+func currentRules() Rules {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	return rules
+}
+
+// enumValues возвращает допустимые значения перечисления name: зарегистрированного через RegisterEnum
+// или из текущего источника правил
+func enumValues(name string) []string {
+	if values, ok := registeredEnum(name); ok {
+		return values
+	}
+	if r := currentRules(); r != nil {
+		return r.Enum(name)
+	}
+	return nil
+}
+
+// lengthBounds возвращает границы длины для правила name из текущего источника правил
+func lengthBounds(name string) (int, int, bool) {
+	if r := currentRules(); r != nil {
+		return r.Length(name)
+	}
+	return 0, 0, false
+}
+
+// registerRules добавляет в v теги enum и length
+func registerRules(v *validator.Validate) {
+	v.RegisterValidation("enum", func(fl validator.FieldLevel) bool {
+		return slices.Contains(enumValues(fl.Param()), fl.Field().String())
+	})
+	v.RegisterValidation("length", func(fl validator.FieldLevel) bool {
+		min, max, ok := lengthBounds(fl.Param())
+		n := utf8.RuneCountInString(fl.Field().String())
+		return ok && n >= min && n <= max
+	})
+}
+
+// enumMessage перечисляет текущие допустимые значения перечисления name
+func enumMessage(name string, en bool) string {
+	values := strings.Join(enumValues(name), ", ")
+	return pick(en, "допустимые значения: "+values, "must be one of: "+values)
+}
+
+// lengthRuleMessage описывает текущие границы длины для правила name
+func lengthRuleMessage(name string, en bool) string {
+	min, max, ok := lengthBounds(name)
+	if !ok {
+		return pick(en, "неизвестное правило длины "+name, "unknown length rule "+name)
+	}
+	from, to := strconv.Itoa(min), strconv.Itoa(max)
+	return pick(en, "длина от "+from+" до "+to+" символов", "must be from "+from+" to "+to+" characters long")
+}
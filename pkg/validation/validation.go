@@ -0,0 +1,161 @@
+// Package validation настраивает go-playground/validator и переводит его ошибки в ответы
+// с отдельной ошибкой на каждое поле и сообщением на языке клиента.
+//
+// Модули регистрируют свои правила (Register) и перечисления (RegisterEnum) один раз при инициализации
+// пакета и проверяют запросы общим validator из Default, поэтому одинаковые поля проверяются одинаково.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Поддерживаемые языки сообщений
+const (
+	LangRU = "ru"
+	LangEN = "en"
+)
+
+// Languages - языки сообщений в порядке предпочтения; первый используется по умолчанию.
+// Передается в fiber.Ctx.AcceptsLanguages для выбора языка по Accept-Language.
+var Languages = []string{LangRU, LangEN}
+
+// FieldError описывает нарушение правила проверки в одном поле запроса
+type FieldError struct {
+	Field   string `json:"field"`           // Путь поля в JSON, например "keys.auth" или "columns[0].key"
+	Rule    string `json:"rule"`            // Нарушенное правило: required, max, e164 и т.д.
+	Param   string `json:"param,omitempty"` // Параметр правила, например 100 для max=100
+	Message string `json:"message"`         // Сообщение на языке клиента
+}
+
+// Response - тело ответа 400 при ошибке проверки запроса
+type Response struct {
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors"`
+}
+
+// newValidator создает validator, который называет поля по тегу json, как их видит клиент,
+// и понимает теги enum и length. Обработчики используют общий экземпляр из Default.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	registerRules(v)
+	return v
+}
+
+// Fields переводит ошибку validator в ошибки отдельных полей на языке lang.
+// Для ошибок другого типа возвращает nil.
+func Fields(err error, lang string) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+	fields := make([]FieldError, len(validationErrors))
+	for i, fe := range validationErrors {
+		fields[i] = FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: message(fe, lang),
+		}
+	}
+	return fields
+}
+
+// NewResponse формирует тело ответа 400 для ошибки проверки err. Message перечисляет все поля,
+// чтобы клиенты, которые показывают только message, тоже видели причину.
+func NewResponse(err error, lang string) Response {
+	fields := Fields(err, lang)
+	if fields == nil {
+		return Response{Message: err.Error(), Errors: []FieldError{}}
+	}
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field.Field + ": " + field.Message
+	}
+	prefix := "Ошибка в полях запроса"
+	if lang == LangEN {
+		prefix = "Validation failed"
+	}
+	return Response{Message: prefix + ": " + strings.Join(parts, "; "), Errors: fields}
+}
+
+// fieldPath возвращает путь поля без имени корневой структуры
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
+
+// message возвращает текст ошибки правила fe на языке lang
+func message(fe validator.FieldError, lang string) string {
+	en := lang == LangEN
+	param := fe.Param()
+	switch fe.Tag() {
+	case "required":
+		return pick(en, "обязательное поле", "is required")
+	case "email":
+		return pick(en, "некорректный email", "must be a valid email address")
+	case "e164":
+		return pick(en, "телефон должен быть в формате +79991234567", "must be a phone number in E.164 format, e.g. +79991234567")
+	case "url":
+		return pick(en, "некорректный URL", "must be a valid URL")
+	case "alphanum":
+		return pick(en, "допустимы только латинские буквы и цифры", "must contain only letters and digits")
+	case "oneof":
+		values := strings.Join(strings.Fields(param), ", ")
+		return pick(en, "допустимые значения: "+values, "must be one of: "+values)
+	case "enum":
+		return enumMessage(param, en)
+	case "length":
+		return lengthRuleMessage(param, en)
+	case "min", "max":
+		return lengthMessage(fe.Tag() == "min", fe.Kind(), param, en)
+	}
+	if msg, ok := registeredMessage(fe.Tag(), en); ok {
+		return msg
+	}
+	return pick(en, fmt.Sprintf("не проходит проверку %s", fe.Tag()), fmt.Sprintf("failed the %s check", fe.Tag()))
+}
+
+// lengthMessage описывает ограничения min и max: для строк - длину, для списков - число элементов, для чисел - значение
+func lengthMessage(isMin bool, kind reflect.Kind, param string, en bool) string {
+	switch kind {
+	case reflect.String:
+		if isMin {
+			return pick(en, "не короче "+param+" символов", "must be at least "+param+" characters long")
+		}
+		return pick(en, "не длиннее "+param+" символов", "must be at most "+param+" characters long")
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if isMin {
+			return pick(en, "не меньше "+param+" элементов", "must contain at least "+param+" items")
+		}
+		return pick(en, "не больше "+param+" элементов", "must contain at most "+param+" items")
+	}
+	if isMin {
+		return pick(en, "не меньше "+param, "must be at least "+param)
+	}
+	return pick(en, "не больше "+param, "must be at most "+param)
+}
+
+func pick(en bool, ru, english string) string {
+	if en {
+		return english
+	}
+	return ru
+}
@@ -0,0 +1,35 @@
+package validation
+
+import (
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	// telegramUsername - имя пользователя Telegram без @: 5-32 символа, начинается с буквы
+	telegramUsername = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{4,31}$`)
+	// vkURL - ссылка на страницу ВКонтакте: https://vk.com/durov, https://m.vk.com/id1, https://vk.ru/club1
+	vkURL = regexp.MustCompile(`^https?://(m\.)?vk\.(com|ru)/[A-Za-z0-9_.]+/?$`)
+	// ruPhone - российский номер в формате E.164
+	ruPhone = regexp.MustCompile(`^\+7\d{10}$`)
+)
+
+// Правила полей контакта, общие для всех модулей
+func init() {
+	Register("telegram_username", matches(telegramUsername),
+		"имя пользователя Telegram без @: 5-32 латинские буквы, цифры или _, начиная с буквы",
+		"must be a Telegram username without @: 5-32 letters, digits or underscores, starting with a letter")
+	Register("vk_url", matches(vkURL),
+		"ссылка на страницу ВКонтакте, например https://vk.com/username",
+		"must be a VK profile link, e.g. https://vk.com/username")
+	Register("ru_phone", matches(ruPhone),
+		"российский номер в формате +79991234567",
+		"must be a Russian phone number in the format +79991234567")
+}
+
+func matches(re *regexp.Regexp) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return re.MatchString(fl.Field().String())
+	}
+}
@@ -0,0 +1,297 @@
+// Package webpush отправляет уведомления Web Push: подпись запросов VAPID (RFC 8292)
+// и шифрование содержимого aes128gcm (RFC 8291).
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recordSize - размер записи aes128gcm; сообщение шифруется одной записью
+const recordSize = 4096
+
+// MaxPayloadSize - наибольший размер содержимого: сервисы принимают тело до 4096 байт,
+// из которых 86 занимает заголовок, 16 - тег GCM и 1 - разделитель записи
+const MaxPayloadSize = recordSize - 16 - 1 - 86
+
+// vapidTokenTTL - срок действия подписи VAPID (сервисы принимают не более 24 часов)
+const vapidTokenTTL = 12 * time.Hour
+
+// pushServiceHosts - домены сервисов доставки браузеров. Адреса подписок на другие хосты не принимаются,
+// чтобы сервер нельзя было заставить отправлять запросы на произвольные адреса.
+var pushServiceHosts = []string{
+	"fcm.googleapis.com",
+	"android.googleapis.com",
+	"push.services.mozilla.com",
+	"notify.windows.com",
+	"push.apple.com",
+}
+
+var (
+	ErrInvalidKey          = errors.New("webpush: invalid key")
+	ErrInvalidSubscription = errors.New("webpush: invalid subscription")
+	ErrPayloadTooLarge     = errors.New("webpush: payload is too large")
+	// ErrSubscriptionGone - сервис доставки сообщил, что подписка больше не действует; ее нужно удалить
+	ErrSubscriptionGone = errors.New("webpush: subscription is gone")
+)
+
+// Subscription - подписка браузера (PushSubscription.toJSON()); ключи в base64url
+type Subscription struct {
+	Endpoint string
+	P256dh   string // Открытый ключ браузера P-256
+	Auth     string // Секрет аутентификации, 16 байт
+}
+
+// Validate проверяет адрес сервиса доставки и ключи подписки
+func (s Subscription) Validate() error {
+	u, err := url.Parse(s.Endpoint)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return fmt.Errorf("%w: endpoint must be an https URL", ErrInvalidSubscription)
+	}
+	if !allowedHost(u.Hostname()) {
+		return fmt.Errorf("%w: unknown push service %s", ErrInvalidSubscription, u.Hostname())
+	}
+	if _, err := parsePublicKey(s.P256dh); err != nil {
+		return fmt.Errorf("%w: invalid p256dh key", ErrInvalidSubscription)
+	}
+	if auth, err := decode(s.Auth); err != nil || len(auth) != 16 {
+		return fmt.Errorf("%w: invalid auth secret", ErrInvalidSubscription)
+	}
+	return nil
+}
+
+func allowedHost(host string) bool {
+	if net.ParseIP(host) != nil {
+		return false
+	}
+	for _, h := range pushServiceHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateKeys создает пару ключей VAPID: открытый (65 байт) и закрытый (32 байта) ключ в base64url
+func GenerateKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return encode(key.PublicKey().Bytes()), encode(key.Bytes()), nil
+}
+
+// Sender подписывает и отправляет уведомления в сервисы доставки браузеров
+type Sender struct {
+	publicKey string
+	key       *ecdsa.PrivateKey
+	subject   string
+	client    *http.Client
+}
+
+// NewSender создает Sender по паре ключей VAPID из GenerateKeys.
+// subject - контакт администратора для сервисов доставки: "mailto:..." или https-адрес.
+func NewSender(publicKey, privateKey, subject string) (*Sender, error) {
+	d, err := decode(privateKey)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	key, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	pub := key.PublicKey().Bytes()
+	if encode(pub) != strings.TrimRight(publicKey, "=") {
+		return nil, fmt.Errorf("%w: public key does not match private key", ErrInvalidKey)
+	}
+	if !strings.HasPrefix(subject, "mailto:") && !strings.HasPrefix(subject, "https://") {
+		return nil, fmt.Errorf("webpush: subject must be a mailto: or https: URL")
+	}
+	return &Sender{
+		publicKey: encode(pub),
+		key: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(pub[1:33]),
+				Y:     new(big.Int).SetBytes(pub[33:]),
+			},
+			D: new(big.Int).SetBytes(d),
+		},
+		subject: subject,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// PublicKey возвращает открытый ключ VAPID для pushManager.subscribe({applicationServerKey})
+func (s *Sender) PublicKey() string {
+	return s.publicKey
+}
+
+// Send шифрует payload для подписки и передает его сервису доставки.
+// ttl - сколько сервис хранит уведомление, если браузер недоступен.
+func (s *Sender) Send(ctx context.Context, sub Subscription, payload []byte, ttl time.Duration) error {
+	if len(payload) > MaxPayloadSize {
+		return ErrPayloadTooLarge
+	}
+	if err := sub.Validate(); err != nil {
+		return err
+	}
+	body, err := encrypt(sub, payload)
+	if err != nil {
+		return err
+	}
+	endpoint, _ := url.Parse(sub.Endpoint)
+	token, err := s.vapidToken(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	req.Header.Set("Authorization", "vapid t="+token+", k="+s.publicKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	case resp.StatusCode >= http.StatusBadRequest:
+		return fmt.Errorf("webpush: push service returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// vapidToken возвращает JWT ES256 для сервиса доставки audience
+func (s *Sender) vapidToken(audience string) (string, error) {
+	header := encode([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": audience,
+		"exp": time.Now().Add(vapidTokenTTL).Unix(),
+		"sub": s.subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + encode(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	r, sig, err := ecdsa.Sign(rand.Reader, s.key, digest[:])
+	if err != nil {
+		return "", err
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	sig.FillBytes(signature[32:])
+	return unsigned + "." + encode(signature), nil
+}
+
+// encrypt шифрует payload для подписки по RFC 8291 одной записью aes128gcm
+func encrypt(sub Subscription, payload []byte) ([]byte, error) {
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encryptWith(sub, payload, asPrivate, salt)
+}
+
+// encryptWith шифрует payload с заданными временным ключом сервера и солью
+func encryptWith(sub Subscription, payload []byte, asPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	uaPublic, err := parsePublicKey(sub.P256dh)
+	if err != nil {
+		return nil, ErrInvalidSubscription
+	}
+	authSecret, err := decode(sub.Auth)
+	if err != nil {
+		return nil, ErrInvalidSubscription
+	}
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, ErrInvalidSubscription
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic.Bytes()...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm := hkdf(authSecret, sharedSecret, keyInfo, 32)
+	cek := hkdf(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdf(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 - разделитель последней записи
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// hkdf вычисляет HKDF-SHA-256 длиной не более одного блока (32 байта)
+func hkdf(salt, secret, info []byte, length int) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(secret)
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write(info)
+	expand.Write([]byte{0x01})
+	return expand.Sum(nil)[:length]
+}
+
+func parsePublicKey(s string) (*ecdh.PublicKey, error) {
+	raw, err := decode(s)
+	if err != nil {
+		return nil, err
+	}
+	return ecdh.P256().NewPublicKey(raw)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decode принимает base64url с дополнением "=" и без него
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
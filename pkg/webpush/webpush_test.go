@@ -0,0 +1,245 @@
+package webpush
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Пример из RFC 8291, Appendix A
+const (
+	rfcPlaintext     = "When I grow up, I want to be a watermelon"
+	rfcASPrivateKey  = "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"
+	rfcASPublicKey   = "BP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A8"
+	rfcUAPrivateKey  = "q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"
+	rfcUAPublicKey   = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	rfcSalt          = "DGv6ra1nlYgDCS1FRnbzlw"
+	rfcAuthSecret    = "BTBZMqHH6r4Tts7J_aSIgg"
+	rfcEncryptedBody = "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+)
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := decode(s)
+	if err != nil {
+		t.Fatalf("decode %q: %v", s, err)
+	}
+	return b
+}
+
+func TestEncryptWithRFC8291Vector(t *testing.T) {
+	asPrivate, err := ecdh.P256().NewPrivateKey(mustDecode(t, rfcASPrivateKey))
+	if err != nil {
+		t.Fatalf("application server key: %v", err)
+	}
+	if got := encode(asPrivate.PublicKey().Bytes()); got != rfcASPublicKey {
+		t.Fatalf("application server public key = %s, want %s", got, rfcASPublicKey)
+	}
+	// Сервисы доставки принимают записи 4096 байт, а пример RFC использует тот же размер записи
+	sub := Subscription{Endpoint: "https://fcm.googleapis.com/fcm/send/test", P256dh: rfcUAPublicKey, Auth: rfcAuthSecret}
+
+	body, err := encryptWith(sub, []byte(rfcPlaintext), asPrivate, mustDecode(t, rfcSalt))
+	if err != nil {
+		t.Fatalf("encryptWith: %v", err)
+	}
+	if got := encode(body); got != rfcEncryptedBody {
+		t.Errorf("encrypted body = %s\nwant %s", got, rfcEncryptedBody)
+	}
+}
+
+func TestEncryptDecryptsWithSubscriptionKey(t *testing.T) {
+	sub := Subscription{Endpoint: "https://fcm.googleapis.com/fcm/send/test", P256dh: rfcUAPublicKey, Auth: rfcAuthSecret}
+	payload := []byte(`{"title":"Новое уведомление"}`)
+
+	first, err := encrypt(sub, payload)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	second, err := encrypt(sub, payload)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if string(first) == string(second) {
+		t.Error("encrypt reuses salt and key")
+	}
+	if got := decryptForTest(t, first); got != string(payload) {
+		t.Errorf("decrypted payload = %q, want %q", got, payload)
+	}
+}
+
+// decryptForTest расшифровывает тело aes128gcm закрытым ключом браузера из примера RFC 8291
+func decryptForTest(t *testing.T, body []byte) string {
+	t.Helper()
+	uaPrivate, err := ecdh.P256().NewPrivateKey(mustDecode(t, rfcUAPrivateKey))
+	if err != nil {
+		t.Fatalf("user agent key: %v", err)
+	}
+	salt, idLen := body[:16], int(body[20])
+	asPublic, err := ecdh.P256().NewPublicKey(body[21 : 21+idLen])
+	if err != nil {
+		t.Fatalf("application server key in header: %v", err)
+	}
+	sharedSecret, err := uaPrivate.ECDH(asPublic)
+	if err != nil {
+		t.Fatalf("ECDH: %v", err)
+	}
+	keyInfo := append([]byte("WebPush: info\x00"), uaPrivate.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublic.Bytes()...)
+	ikm := hkdf(mustDecode(t, rfcAuthSecret), sharedSecret, keyInfo, 32)
+	cek := hkdf(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdf(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	gcm := newGCMForTest(t, cek)
+	plaintext, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if plaintext[len(plaintext)-1] != 0x02 {
+		t.Fatalf("last record delimiter = %#x, want 0x02", plaintext[len(plaintext)-1])
+	}
+	return string(plaintext[:len(plaintext)-1])
+}
+
+func TestVAPIDToken(t *testing.T) {
+	publicKey, privateKey, err := GenerateKeys()
+	if err != nil {
+		t.Fatalf("GenerateKeys: %v", err)
+	}
+	sender, err := NewSender(publicKey, privateKey, "mailto:admin@example.com")
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+
+	token, err := sender.vapidToken("https://fcm.googleapis.com")
+	if err != nil {
+		t.Fatalf("vapidToken: %v", err)
+	}
+	claims := verifyVAPIDToken(t, token, sender.PublicKey())
+	if claims.Aud != "https://fcm.googleapis.com" {
+		t.Errorf("aud = %s, want https://fcm.googleapis.com", claims.Aud)
+	}
+	if claims.Sub != "mailto:admin@example.com" {
+		t.Errorf("sub = %s, want mailto:admin@example.com", claims.Sub)
+	}
+	// Сервисы доставки отклоняют подписи дольше 24 часов
+	if exp := time.Unix(claims.Exp, 0); exp.Before(time.Now()) || exp.After(time.Now().Add(24*time.Hour)) {
+		t.Errorf("exp = %s, want within 24 hours", exp)
+	}
+}
+
+type vapidClaims struct {
+	Aud string `json:"aud"`
+	Exp int64  `json:"exp"`
+	Sub string `json:"sub"`
+}
+
+// verifyVAPIDToken проверяет заголовок и подпись ES256 токена открытым ключом VAPID и возвращает его утверждения
+func verifyVAPIDToken(t *testing.T, token, publicKey string) vapidClaims {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token has %d parts, want 3", len(parts))
+	}
+
+	var header struct{ Typ, Alg string }
+	if err := json.Unmarshal(mustDecode(t, parts[0]), &header); err != nil {
+		t.Fatalf("header: %v", err)
+	}
+	if header.Alg != "ES256" || header.Typ != "JWT" {
+		t.Errorf("header = %+v, want ES256 JWT", header)
+	}
+
+	pub := mustDecode(t, publicKey)
+	key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(pub[1:33]), Y: new(big.Int).SetBytes(pub[33:])}
+	signature := mustDecode(t, parts[2])
+	if len(signature) != 64 {
+		t.Fatalf("signature length = %d, want 64", len(signature))
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !ecdsa.Verify(key, digest[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])) {
+		t.Fatal("signature does not verify with the VAPID public key")
+	}
+
+	var claims vapidClaims
+	if err := json.Unmarshal(mustDecode(t, parts[1]), &claims); err != nil {
+		t.Fatalf("claims: %v", err)
+	}
+	return claims
+}
+
+func TestSend(t *testing.T) {
+	var (
+		authorization, encoding, ttl string
+		body                         []byte
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization, encoding, ttl = r.Header.Get("Authorization"), r.Header.Get("Content-Encoding"), r.Header.Get("TTL")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	publicKey, privateKey, err := GenerateKeys()
+	if err != nil {
+		t.Fatalf("GenerateKeys: %v", err)
+	}
+	sender, err := NewSender(publicKey, privateKey, "https://rim.example.com")
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	// Запросы к сервису доставки направляются на тестовый сервер
+	client := server.Client()
+	transport := client.Transport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	client.Transport = transport
+	sender.client = client
+
+	sub := Subscription{Endpoint: "https://fcm.googleapis.com/fcm/send/abc", P256dh: rfcUAPublicKey, Auth: rfcAuthSecret}
+	if err := sender.Send(context.Background(), sub, []byte("hello"), time.Hour); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if encoding != "aes128gcm" || ttl != "3600" {
+		t.Errorf("Content-Encoding = %q, TTL = %q; want aes128gcm, 3600", encoding, ttl)
+	}
+	token, key, ok := strings.Cut(strings.TrimPrefix(authorization, "vapid t="), ", k=")
+	if !ok || key != sender.PublicKey() {
+		t.Fatalf("Authorization = %q, want vapid t=<token>, k=<public key>", authorization)
+	}
+	if claims := verifyVAPIDToken(t, token, key); claims.Aud != "https://fcm.googleapis.com" {
+		t.Errorf("aud = %s, want the push service origin", claims.Aud)
+	}
+	if got := decryptForTest(t, body); got != "hello" {
+		t.Errorf("delivered payload = %q, want hello", got)
+	}
+}
+
+func newGCMForTest(t *testing.T, key []byte) cipher.AEAD {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("aes: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("gcm: %v", err)
+	}
+	return gcm
+}